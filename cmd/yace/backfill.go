@@ -0,0 +1,160 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/prometheus/common/expfmt"
+	"github.com/urfave/cli/v2"
+
+	exporter "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/organizations"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/promutil"
+)
+
+// backfillOutputOpenMetrics is the only output format of backfill, the one
+// imported by promtool tsdb create-blocks-from openmetrics.
+const backfillOutputOpenMetrics = "openmetrics"
+
+// backfill exports the datapoints of a job of the config file from --start to --end
+// with their CloudWatch timestamps to stdout, as a file to backfill Prometheus with.
+func backfill(c *cli.Context) error {
+	logger = logging.NewLogger(logFormat, debug, "version", version)
+
+	if backfillOutput != backfillOutputOpenMetrics {
+		return fmt.Errorf("unsupported output %q, only %q is supported", backfillOutput, backfillOutputOpenMetrics)
+	}
+	start, err := time.Parse(time.RFC3339, backfillStart)
+	if err != nil {
+		return fmt.Errorf("invalid start: %w", err)
+	}
+	end, err := time.Parse(time.RFC3339, backfillEnd)
+	if err != nil {
+		return fmt.Errorf("invalid end: %w", err)
+	}
+
+	cfg := config.ScrapeConf{}
+	jobsCfg, err := cfg.Load(configFile, logger)
+	if err != nil {
+		return fmt.Errorf("Couldn't read %s: %w", configFile, err)
+	}
+	jobsCfg, err = backfillJobs(jobsCfg, backfillJob, start, end, time.Now())
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	roles, err := organizationRoles(ctx, jobsCfg)
+	if err != nil {
+		return err
+	}
+	jobsCfg = organizations.ExpandRoles(jobsCfg, roles)
+
+	featureFlags := c.StringSlice(enableFeatureFlag)
+	cache, err := newFactory(jobsCfg, featureFlags)
+	if err != nil {
+		return err
+	}
+	cache.Refresh()
+	defer cache.Clear()
+
+	metrics, err := exporter.CollectMetrics(ctx, logger, jobsCfg, cache, scrapeOptions(featureFlags)...)
+	if err != nil {
+		return err
+	}
+	logger.Info("Writing the datapoints", "job", backfillJob, "start", start, "end", end)
+	return writeOpenMetrics(os.Stdout, metrics.Metrics())
+}
+
+// backfillJobs returns the jobs of the config named job, the discovery jobs of the
+// namespace or service alias and the custom namespace jobs of the name, querying all
+// the datapoints from start to end with their CloudWatch timestamps. The static jobs
+// are not supported, their GetMetricStatistics requests returning at most 1440 datapoints.
+func backfillJobs(jobsCfg model.JobsConfig, job string, start time.Time, end time.Time, now time.Time) (model.JobsConfig, error) {
+	if !start.Before(end) {
+		return model.JobsConfig{}, fmt.Errorf("the start %s should be before the end %s", start.Format(time.RFC3339), end.Format(time.RFC3339))
+	}
+	if end.After(now) {
+		return model.JobsConfig{}, fmt.Errorf("the end %s should not be in the future", end.Format(time.RFC3339))
+	}
+	length := int64(end.Sub(start) / time.Second)
+	delay := int64(now.Sub(end) / time.Second)
+
+	backfillMetrics := func(metrics []*model.MetricConfig) []*model.MetricConfig {
+		backfilled := make([]*model.MetricConfig, 0, len(metrics))
+		for _, metric := range metrics {
+			m := *metric
+			m.Length, m.Delay = length, delay
+			m.AddHistoricalMetrics, m.AddCloudwatchTimestamp = aws.Bool(true), aws.Bool(true)
+			backfilled = append(backfilled, &m)
+		}
+		return backfilled
+	}
+
+	backfilled := model.JobsConfig{}
+	for _, discoveryJob := range jobsCfg.DiscoveryJobs {
+		if discoveryJob.Type != job && config.SupportedServices.GetService(discoveryJob.Type).Alias != job {
+			continue
+		}
+		discoveryJob.Metrics = backfillMetrics(discoveryJob.Metrics)
+		discoveryJob.Length, discoveryJob.Delay = length, delay
+		discoveryJob.AddHistoricalMetrics = aws.Bool(true)
+		backfilled.DiscoveryJobs = append(backfilled.DiscoveryJobs, discoveryJob)
+	}
+	for _, customNamespaceJob := range jobsCfg.CustomNamespaceJobs {
+		if customNamespaceJob.Name != job {
+			continue
+		}
+		customNamespaceJob.Metrics = backfillMetrics(customNamespaceJob.Metrics)
+		customNamespaceJob.Length, customNamespaceJob.Delay = length, delay
+		customNamespaceJob.AddHistoricalMetrics = aws.Bool(true)
+		backfilled.CustomNamespaceJobs = append(backfilled.CustomNamespaceJobs, customNamespaceJob)
+	}
+	if len(backfilled.DiscoveryJobs) == 0 && len(backfilled.CustomNamespaceJobs) == 0 {
+		return backfilled, fmt.Errorf("no discovery job of the namespace or alias %s, or custom namespace job named %s", job, job)
+	}
+	return backfilled, nil
+}
+
+// writeOpenMetrics writes the datapoints of the metrics in the OpenMetrics format, the
+// samples of each series sorted by timestamp as expected by promtool. The metrics without
+// a timestamp, e.g. the info metrics, are skipped.
+func writeOpenMetrics(w io.Writer, metrics []*promutil.PrometheusMetric) error {
+	datapoints := make([]*promutil.PrometheusMetric, 0, len(metrics))
+	series := make(map[*promutil.PrometheusMetric]string, len(metrics))
+	for _, metric := range metrics {
+		if !metric.IncludeTimestamp {
+			continue
+		}
+		datapoints = append(datapoints, metric)
+		labels := make([]string, 0, len(metric.Labels))
+		for name, value := range metric.Labels {
+			labels = append(labels, name+"="+value)
+		}
+		slices.Sort(labels)
+		series[metric] = strings.Join(labels, ",")
+	}
+	slices.SortFunc(datapoints, func(a, b *promutil.PrometheusMetric) int {
+		return cmp.Or(
+			cmp.Compare(*a.Name, *b.Name),
+			cmp.Compare(series[a], series[b]),
+			a.Timestamp.Compare(b.Timestamp),
+		)
+	})
+
+	enc := expfmt.NewEncoder(w, expfmt.FmtOpenMetrics_1_0_0)
+	if err := promutil.NewPrometheusCollector(datapoints).Encode(enc, nil, logger); err != nil {
+		return err
+	}
+	return enc.(expfmt.Closer).Close()
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/promutil"
+)
+
+func TestBackfillJobs(t *testing.T) {
+	now := time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)
+	start, end := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	jobsCfg := model.JobsConfig{
+		DiscoveryJobs: []model.DiscoveryJob{
+			{Type: "AWS/S3", Metrics: []*model.MetricConfig{{Name: "BucketSizeBytes", Period: 86400, Length: 172800}}},
+			{Type: "AWS/SQS", Metrics: []*model.MetricConfig{{Name: "NumberOfMessagesSent", Period: 300, Length: 300}}},
+		},
+		CustomNamespaceJobs: []model.CustomNamespaceJob{
+			{Name: "app", Namespace: "App", Metrics: []*model.MetricConfig{{Name: "Requests", Period: 60, Length: 60}}},
+		},
+	}
+
+	backfilled, err := backfillJobs(jobsCfg, "s3", start, end, now)
+	require.NoError(t, err)
+	require.Len(t, backfilled.DiscoveryJobs, 1)
+	require.Empty(t, backfilled.CustomNamespaceJobs)
+	job := backfilled.DiscoveryJobs[0]
+	require.Equal(t, "AWS/S3", job.Type)
+	require.Equal(t, int64(14*24*3600), job.Length)
+	require.Equal(t, int64(5*24*3600), job.Delay)
+	require.True(t, *job.AddHistoricalMetrics)
+	metric := job.Metrics[0]
+	require.Equal(t, int64(14*24*3600), metric.Length)
+	require.Equal(t, int64(5*24*3600), metric.Delay)
+	require.True(t, *metric.AddCloudwatchTimestamp)
+	// The metrics of the config are left untouched
+	require.Equal(t, int64(172800), jobsCfg.DiscoveryJobs[0].Metrics[0].Length)
+
+	backfilled, err = backfillJobs(jobsCfg, "app", start, end, now)
+	require.NoError(t, err)
+	require.Empty(t, backfilled.DiscoveryJobs)
+	require.Len(t, backfilled.CustomNamespaceJobs, 1)
+
+	_, err = backfillJobs(jobsCfg, "ec2", start, end, now)
+	require.ErrorContains(t, err, "no discovery job of the namespace or alias ec2")
+	_, err = backfillJobs(jobsCfg, "s3", end, start, now)
+	require.ErrorContains(t, err, "should be before the end")
+	_, err = backfillJobs(jobsCfg, "s3", start, now.Add(time.Hour), now)
+	require.ErrorContains(t, err, "should not be in the future")
+}
+
+func TestWriteOpenMetrics(t *testing.T) {
+	name := "aws_s3_bucket_size_bytes_average"
+	info := "aws_s3_info"
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	datapoint := func(bucket string, value float64, timestamp time.Time) *promutil.PrometheusMetric {
+		return &promutil.PrometheusMetric{Name: &name, Labels: map[string]string{"name": bucket}, Value: &value, IncludeTimestamp: true, Timestamp: timestamp}
+	}
+	zero := 0.0
+
+	var out bytes.Buffer
+	require.NoError(t, writeOpenMetrics(&out, []*promutil.PrometheusMetric{
+		datapoint("b", 3, day.Add(24*time.Hour)),
+		{Name: &info, Labels: map[string]string{"name": "a"}, Value: &zero},
+		datapoint("a", 2, day.Add(24*time.Hour)),
+		datapoint("b", 4, day),
+		datapoint("a", 1, day),
+	}))
+	require.Equal(t, `# HELP aws_s3_bucket_size_bytes_average Help is not implemented yet.
+# TYPE aws_s3_bucket_size_bytes_average gauge
+aws_s3_bucket_size_bytes_average{name="a"} 1.0 1.7040672e+09
+aws_s3_bucket_size_bytes_average{name="a"} 2.0 1.7041536e+09
+aws_s3_bucket_size_bytes_average{name="b"} 4.0 1.7040672e+09
+aws_s3_bucket_size_bytes_average{name="b"} 3.0 1.7041536e+09
+# EOF
+`, out.String())
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/budget"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/promutil"
+)
+
+// Reasons of the degraded mode.
+const (
+	degradedCost = "cost"
+	degradedAPI  = "api"
+)
+
+var degradedMode = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name: "yace_degraded_mode",
+	Help: "Whether the low priority jobs are scraped less often as the CloudWatch API cost or request budget is nearly spent.",
+})
+
+// degradation tracks the CloudWatch API budgets, the monthly cost of -budget.monthly-cost
+// and the shared request budget of -budget.dynamodb-table. When one of them is nearly
+// spent, it degrades the scrapes: the low priority class is only scraped every factor
+// scraping intervals, rather than the scrapes failing once the budget is spent.
+type degradation struct {
+	// monthlyCost is the budget of the cost of the CloudWatch API requests in a month, in USD, 0 when not limited
+	monthlyCost float64
+	// threshold is the share of monthlyCost whose spending degrades the scrapes
+	threshold float64
+	factor    int
+	// cost returns the cost of the CloudWatch API requests since the exporter started
+	cost func() float64
+	// delayed returns the requests delayed by the shared budget since the exporter started, nil without one
+	delayed func() int64
+
+	mu sync.Mutex
+	// month is the start of the month the cost is counted in, from monthStart
+	month       time.Time
+	monthStart  float64
+	lastDelayed int64
+	reason      string
+	// skipped is the number of intervals the low priority class was not scraped for
+	skipped int
+}
+
+func newDegradation(monthlyCost, threshold float64, factor int, delayed func() int64, now time.Time) *degradation {
+	return &degradation{
+		monthlyCost: monthlyCost,
+		threshold:   threshold,
+		factor:      factor,
+		cost:        cloudwatchCost,
+		delayed:     delayed,
+		month:       monthOf(now),
+	}
+}
+
+// lowPriorityDue updates the mode at a scraping interval, and returns whether the low
+// priority class is to be scraped at the interval.
+func (d *degradation) lowPriorityDue(logger logging.Logger, now time.Time) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	cost := d.cost()
+	if month := monthOf(now); !month.Equal(d.month) {
+		d.month, d.monthStart = month, cost
+	}
+	reason := ""
+	if d.monthlyCost > 0 && cost-d.monthStart >= d.threshold*d.monthlyCost {
+		reason = degradedCost
+	}
+	if d.delayed != nil {
+		// Requests delayed since the previous interval, the request budget is spent
+		delayed := d.delayed()
+		if delayed > d.lastDelayed && reason == "" {
+			reason = degradedAPI
+		}
+		d.lastDelayed = delayed
+	}
+
+	if reason != d.reason {
+		if reason != "" {
+			logger.Warn("CloudWatch API budget nearly spent, scraping the low priority jobs less often", "reason", reason, "month_cost", cost-d.monthStart, "interval_factor", d.factor)
+		} else {
+			logger.Info("CloudWatch API budget available again, leaving degraded mode")
+		}
+		d.reason = reason
+	}
+	if reason == "" {
+		degradedMode.Set(0)
+		d.skipped = 0
+		return true
+	}
+	degradedMode.Set(1)
+	d.skipped++
+	if d.skipped < d.factor {
+		return false
+	}
+	d.skipped = 0
+	return true
+}
+
+// monthOf returns the start of the UTC month of t.
+func monthOf(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// cloudwatchCost returns the cost of the CloudWatch API requests of the exporter since it
+// started, from the API counters.
+func cloudwatchCost() float64 {
+	requests := counterValue(promutil.CloudwatchAPICounter) - counterValue(promutil.CloudwatchGetMetricDataAPICounter)
+	return requests*pricePerRequest + counterValue(promutil.CloudwatchGetMetricDataAPIMetricsCounter)*pricePerMetricRequested
+}
+
+func counterValue(counter prometheus.Counter) float64 {
+	var metric dto.Metric
+	if err := counter.Write(&metric); err != nil {
+		return 0
+	}
+	return metric.GetCounter().GetValue()
+}
+
+// budgetDelayed returns the number of requests delayed by the shared budget since the exporter started.
+func budgetDelayed(b *budget.Budget) func() int64 {
+	return func() int64 {
+		var delayed int64
+		for _, usage := range b.Usage() {
+			delayed += usage.Delayed
+		}
+		return delayed
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+)
+
+func TestDegradationCost(t *testing.T) {
+	now := time.Date(2024, 1, 30, 12, 0, 0, 0, time.UTC)
+	cost := 0.0
+	d := newDegradation(10, 0.9, 3, nil, now)
+	d.cost = func() float64 { return cost }
+	logger := logging.NewNopLogger()
+
+	cost = 8.99
+	require.True(t, d.lowPriorityDue(logger, now))
+	require.Equal(t, 0.0, testutil.ToFloat64(degradedMode))
+
+	// 90% of the budget spent, the low priority class is scraped every 3 intervals
+	cost = 9
+	var due []bool
+	for i := 0; i < 6; i++ {
+		due = append(due, d.lowPriorityDue(logger, now))
+	}
+	require.Equal(t, []bool{false, false, true, false, false, true}, due)
+	require.Equal(t, 1.0, testutil.ToFloat64(degradedMode))
+
+	// The cost is counted again from the start of the next month
+	cost = 10
+	require.True(t, d.lowPriorityDue(logger, now.Add(48*time.Hour)))
+	require.Equal(t, 0.0, testutil.ToFloat64(degradedMode))
+}
+
+func TestDegradationAPI(t *testing.T) {
+	now := time.Date(2024, 1, 30, 12, 0, 0, 0, time.UTC)
+	var delayed int64
+	d := newDegradation(0, 0.9, 2, func() int64 { return delayed }, now)
+	d.cost = func() float64 { return 100 }
+	logger := logging.NewNopLogger()
+
+	require.True(t, d.lowPriorityDue(logger, now))
+
+	// Requests delayed by the shared budget since the previous interval
+	delayed = 5
+	require.False(t, d.lowPriorityDue(logger, now))
+	delayed = 7
+	require.True(t, d.lowPriorityDue(logger, now))
+	require.Equal(t, 1.0, testutil.ToFloat64(degradedMode))
+
+	// No requests delayed during the last interval
+	require.True(t, d.lowPriorityDue(logger, now))
+	require.Equal(t, 0.0, testutil.ToFloat64(degradedMode))
+}
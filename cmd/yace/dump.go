@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/budget"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/tagging"
+)
+
+// dumpHandlerInterval is the minimum interval between the state dumps written
+// by /debug/dump, so that requests cannot fill the dump directory.
+const dumpHandlerInterval = time.Minute
+
+// stateDump is a snapshot of the internal state of the exporter, for the postmortems
+// of scrape stalls.
+type stateDump struct {
+	Time             time.Time                 `json:"time"`
+	Version          string                    `json:"version"`
+	ScrapingInterval int                       `json:"scraping_interval_seconds"`
+	Ready            bool                      `json:"ready"`
+	Schedules        []classSchedule           `json:"schedules"`
+	CachedResources  []tagging.CachedResources `json:"cached_resources,omitempty"`
+	Budget           []budget.Usage            `json:"budget,omitempty"`
+	Jobs             []jobState                `json:"jobs"`
+}
+
+// stateDump returns a snapshot of the internal state of the scraper.
+func (s *scraper) stateDump(budgetUsage func() []budget.Usage) stateDump {
+	dump := stateDump{
+		Time:             time.Now(),
+		Version:          version,
+		ScrapingInterval: scrapingInterval,
+		Ready:            s.ready.Load(),
+		Schedules:        []classSchedule{},
+		Jobs:             s.jobHealth.snapshot(),
+	}
+	if classes := s.classes.Load(); classes != nil {
+		for _, class := range *classes {
+			dump.Schedules = append(dump.Schedules, class.scheduleSnapshot())
+		}
+	}
+	if s.resourceCache != nil {
+		dump.CachedResources = s.resourceCache.Snapshot()
+	}
+	if budgetUsage != nil {
+		dump.Budget = budgetUsage()
+	}
+	return dump
+}
+
+// writeStateDump writes the dump as JSON to a new file of dir, and returns its path.
+func writeStateDump(dump stateDump, dir string) (string, error) {
+	data, err := json.MarshalIndent(dump, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("yace-state-%s.json", dump.Time.UTC().Format("20060102T150405.000000000Z")))
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return "", fmt.Errorf("failed to write the state dump: %w", err)
+	}
+	return path, nil
+}
+
+// dumpState writes a snapshot of the internal state of the scraper to the dump directory.
+func (s *scraper) dumpState() (stateDump, string, error) {
+	var budgetUsage func() []budget.Usage
+	if b, ok := requestBudget.(*budget.Budget); ok {
+		budgetUsage = b.Usage
+	}
+	dump := s.stateDump(budgetUsage)
+	dir := dumpDir
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	path, err := writeStateDump(dump, dir)
+	return dump, path, err
+}
+
+// makeDumpHandler writes a state dump on POST requests, and serves it. Requests
+// within minInterval of the previous dump are rejected.
+func (s *scraper) makeDumpHandler(minInterval time.Duration) func(http.ResponseWriter, *http.Request) {
+	var mu sync.Mutex
+	var lastDump time.Time
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		mu.Lock()
+		if wait := minInterval - time.Since(lastDump); !lastDump.IsZero() && wait > 0 {
+			mu.Unlock()
+			w.Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+			http.Error(w, "a state dump was written less than "+minInterval.String()+" ago", http.StatusTooManyRequests)
+			return
+		}
+		lastDump = time.Now()
+		mu.Unlock()
+
+		dump, path, err := s.dumpState()
+		if err != nil {
+			logger.Error(err, "Couldn't write the state dump")
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		logger.Info("Wrote the state dump", "path", path)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(dump)
+	}
+}
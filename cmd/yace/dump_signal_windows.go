@@ -0,0 +1,6 @@
+package main
+
+import "os"
+
+// notifyDump does nothing, Windows has no SIGUSR1: the state is dumped with /debug/dump only.
+func notifyDump(_ chan<- os.Signal) {}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/budget"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/tagging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+func TestStateDump(t *testing.T) {
+	s := NewScraper(nil)
+	s.resourceCache = tagging.NewResourceCache(time.Hour, 0)
+	classes := priorityClasses(model.JobsConfig{
+		DiscoveryJobs: []model.DiscoveryJob{{Type: "AWS/RDS"}, {Type: "AWS/S3", Priority: model.PriorityLow}},
+	}, nil)
+	s.classes.Store(&classes)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	classes[0].started(start)
+	classes[0].completed(start.Add(time.Minute))
+	classes[1].started(start)
+	classes[1].overlapped()
+	s.jobHealth.observe([]model.JobOutcome{{Job: "AWS/RDS", Region: "eu-west-1", AccountID: "123456789012", Err: errors.New("ExpiredToken")}})
+
+	dump := s.stateDump(func() []budget.Usage { return []budget.Usage{{Budget: "default/eu-west-1", Requests: 1}} })
+	require.Equal(t, []classSchedule{
+		{Class: model.PriorityNormal, Jobs: 1, LastStart: start, LastEnd: start.Add(time.Minute), Scrapes: 1},
+		{Class: model.PriorityLow, Jobs: 1, Scraping: true, LastStart: start, Scrapes: 1, Overlapped: 1},
+	}, dump.Schedules)
+	require.Equal(t, []jobState{{Job: "AWS/RDS", Region: "eu-west-1", AccountID: "123456789012", LastError: "ExpiredToken"}}, dump.Jobs)
+	require.Empty(t, dump.CachedResources)
+	require.Len(t, dump.Budget, 1)
+
+	path, err := writeStateDump(dump, t.TempDir())
+	require.NoError(t, err)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var written stateDump
+	require.NoError(t, json.Unmarshal(data, &written))
+	require.Equal(t, dump.Schedules, written.Schedules)
+}
+
+func TestDumpHandler(t *testing.T) {
+	s := NewScraper(nil)
+	handler := s.makeDumpHandler(time.Hour)
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/debug/dump", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+	dumpDir = t.TempDir()
+	t.Cleanup(func() { dumpDir = "" })
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/debug/dump", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var dump stateDump
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &dump))
+	require.Equal(t, version, dump.Version)
+
+	files, err := os.ReadDir(dumpDir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	// The next dumps are rejected until the interval elapsed
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/debug/dump", nil))
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.Equal(t, "3600", rec.Header().Get("Retry-After"))
+	files, err = os.ReadDir(dumpDir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+}
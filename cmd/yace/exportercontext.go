@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	exporter "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/promutil"
+)
+
+const (
+	// imdsURL is the endpoint of the EC2 instance metadata service.
+	imdsURL = "http://169.254.169.254"
+	// ecsMetadataEnv is the environment variable of the ECS task metadata endpoint v4.
+	ecsMetadataEnv = "ECS_CONTAINER_METADATA_URI_V4"
+	// eksClusterTag is the instance tag of the nodes of the EKS managed node groups.
+	eksClusterTag = "eks:cluster-name"
+)
+
+// exporterContext describes where the exporter runs.
+type exporterContext struct {
+	Region           string
+	AvailabilityZone string
+	Cluster          string
+	// Instance is the ID of the ECS task or the EC2 instance
+	Instance string
+}
+
+// labels returns the labels of the exporter context set on the AWS metrics, the empty ones omitted.
+func (e exporterContext) labels() map[string]string {
+	labels := map[string]string{}
+	for name, value := range map[string]string{
+		"exporter_region":            e.Region,
+		"exporter_availability_zone": e.AvailabilityZone,
+		"exporter_cluster":           e.Cluster,
+		"exporter_instance":          e.Instance,
+	} {
+		if value != "" {
+			labels[name] = value
+		}
+	}
+	return labels
+}
+
+// lookupExporterContext returns the context of the exporter from the ECS task metadata
+// when running in an ECS task, the EC2 instance metadata (IMDSv2) otherwise.
+func lookupExporterContext(ctx context.Context, client *http.Client, imdsEndpoint string, ecsEndpoint string) (exporterContext, error) {
+	if ecsEndpoint != "" {
+		return ecsTaskContext(ctx, client, ecsEndpoint)
+	}
+	return ec2InstanceContext(ctx, client, imdsEndpoint)
+}
+
+func ecsTaskContext(ctx context.Context, client *http.Client, endpoint string) (exporterContext, error) {
+	var task struct {
+		Cluster          string `json:"Cluster"`
+		TaskARN          string `json:"TaskARN"`
+		AvailabilityZone string `json:"AvailabilityZone"`
+	}
+	body, err := metadataGet(ctx, client, endpoint+"/task", nil)
+	if err != nil {
+		return exporterContext{}, fmt.Errorf("failed to get the ECS task metadata: %w", err)
+	}
+	if err := json.Unmarshal(body, &task); err != nil {
+		return exporterContext{}, fmt.Errorf("failed to decode the ECS task metadata: %w", err)
+	}
+
+	// arn:aws:ecs:<region>:<account>:task/<cluster>/<id>
+	var region string
+	if parts := strings.Split(task.TaskARN, ":"); len(parts) >= 6 {
+		region = parts[3]
+	}
+	cluster := task.Cluster
+	if _, name, ok := strings.Cut(cluster, ":cluster/"); ok {
+		cluster = name
+	}
+	return exporterContext{
+		Region:           region,
+		AvailabilityZone: task.AvailabilityZone,
+		Cluster:          cluster,
+		Instance:         task.TaskARN[strings.LastIndex(task.TaskARN, "/")+1:],
+	}, nil
+}
+
+func ec2InstanceContext(ctx context.Context, client *http.Client, endpoint string) (exporterContext, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint+"/latest/api/token", nil)
+	if err != nil {
+		return exporterContext{}, err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "60")
+	token, err := metadataDo(client, req)
+	if err != nil {
+		return exporterContext{}, fmt.Errorf("failed to get an IMDSv2 token: %w", err)
+	}
+	header := http.Header{"X-aws-ec2-metadata-token": []string{string(token)}}
+
+	var identity struct {
+		Region           string `json:"region"`
+		AvailabilityZone string `json:"availabilityZone"`
+		InstanceID       string `json:"instanceId"`
+	}
+	body, err := metadataGet(ctx, client, endpoint+"/latest/dynamic/instance-identity/document", header)
+	if err != nil {
+		return exporterContext{}, fmt.Errorf("failed to get the EC2 instance identity document: %w", err)
+	}
+	if err := json.Unmarshal(body, &identity); err != nil {
+		return exporterContext{}, fmt.Errorf("failed to decode the EC2 instance identity document: %w", err)
+	}
+
+	// The tags are only in the instance metadata if enabled on the instance
+	cluster, _ := metadataGet(ctx, client, endpoint+"/latest/meta-data/tags/instance/"+eksClusterTag, header)
+	return exporterContext{
+		Region:           identity.Region,
+		AvailabilityZone: identity.AvailabilityZone,
+		Cluster:          string(cluster),
+		Instance:         identity.InstanceID,
+	}, nil
+}
+
+func metadataGet(ctx context.Context, client *http.Client, url string, header http.Header) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	return metadataDo(client, req)
+}
+
+func metadataDo(client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return body, nil
+}
+
+// setupExporterLabels looks up the context of the exporter if -labels.exporter-context
+// is set. The metrics are exported without the labels if it can't be found.
+func setupExporterLabels(ctx context.Context) {
+	if !exporterContextLabels {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	exporterCtx, err := lookupExporterContext(ctx, &http.Client{}, imdsURL, os.Getenv(ecsMetadataEnv))
+	if err != nil {
+		logger.Warn("Couldn't find where the exporter runs, exporting the metrics without the exporter context labels", "err", err)
+		return
+	}
+	exporterLabels = exporterCtx.labels()
+	logger.Info("Adding the exporter context labels to the metrics", "labels", exporterLabels)
+}
+
+// withExporterLabels adds the labels of the exporter context to the metrics of the scrapes.
+func withExporterLabels(labels map[string]string) exporter.OptionsFunc {
+	return exporter.WithHooks(exporter.Hooks{
+		OnMetricsBuilt: func(_ context.Context, metrics []*promutil.PrometheusMetric) []*promutil.PrometheusMetric {
+			addLabels(metrics, labels)
+			return metrics
+		},
+	})
+}
+
+func addLabels(metrics []*promutil.PrometheusMetric, labels map[string]string) {
+	for _, metric := range metrics {
+		if metric.Labels == nil {
+			metric.Labels = make(map[string]string, len(labels))
+		}
+		maps.Copy(metric.Labels, labels)
+	}
+}
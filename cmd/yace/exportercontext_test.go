@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/promutil"
+)
+
+func TestLookupExporterContextECS(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v4/abc/task", r.URL.Path)
+		_, _ = w.Write([]byte(`{
+			"Cluster": "arn:aws:ecs:eu-west-1:123456789012:cluster/monitoring",
+			"TaskARN": "arn:aws:ecs:eu-west-1:123456789012:task/monitoring/0123456789abcdef",
+			"AvailabilityZone": "eu-west-1b"
+		}`))
+	}))
+	defer srv.Close()
+
+	exporterCtx, err := lookupExporterContext(context.Background(), srv.Client(), "http://127.0.0.1:0", srv.URL+"/v4/abc")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"exporter_region":            "eu-west-1",
+		"exporter_availability_zone": "eu-west-1b",
+		"exporter_cluster":           "monitoring",
+		"exporter_instance":          "0123456789abcdef",
+	}, exporterCtx.labels())
+}
+
+func TestLookupExporterContextEC2(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/latest/api/token" {
+			require.Equal(t, http.MethodPut, r.Method)
+			_, _ = w.Write([]byte("token"))
+			return
+		}
+		if r.Header.Get("X-aws-ec2-metadata-token") != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/latest/dynamic/instance-identity/document":
+			_, _ = w.Write([]byte(`{"region": "us-east-1", "availabilityZone": "us-east-1a", "instanceId": "i-0123456789abcdef0"}`))
+		default:
+			// Tags not enabled in the instance metadata
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	exporterCtx, err := lookupExporterContext(context.Background(), srv.Client(), srv.URL, "")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"exporter_region":            "us-east-1",
+		"exporter_availability_zone": "us-east-1a",
+		"exporter_instance":          "i-0123456789abcdef0",
+	}, exporterCtx.labels())
+}
+
+func TestLookupExporterContextUnavailable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	_, err := lookupExporterContext(context.Background(), srv.Client(), srv.URL, "")
+	require.ErrorContains(t, err, "failed to get an IMDSv2 token")
+}
+
+func TestAddLabels(t *testing.T) {
+	name := "aws_sqs_number_of_messages_sent_sum"
+	metrics := []*promutil.PrometheusMetric{
+		{Name: &name, Labels: map[string]string{"name": "queue"}},
+		{Name: &name},
+	}
+	addLabels(metrics, map[string]string{"exporter_region": "eu-west-1"})
+	require.Equal(t, map[string]string{"name": "queue", "exporter_region": "eu-west-1"}, metrics[0].Labels)
+	require.Equal(t, map[string]string{"exporter_region": "eu-west-1"}, metrics[1].Labels)
+}
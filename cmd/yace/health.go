@@ -0,0 +1,162 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+type jobHealthKey struct {
+	job     string
+	region  string
+	roleArn string
+}
+
+type jobHealthState struct {
+	accountID      string
+	unhealthyAfter time.Duration
+	lastSuccess    time.Time
+	lastErr        error
+	// lastPaused is the last scrape the job was skipped in, being outside of its schedule
+	lastPaused time.Time
+}
+
+// jobHealth tracks the last successful scrape of the jobs in each region and with
+// each role, for the exporter to be unhealthy when a job with unhealthyAfter fails
+// for longer, e.g. because the credentials of its role expired.
+type jobHealth struct {
+	mu    sync.Mutex
+	now   func() time.Time
+	since time.Time
+	jobs  map[jobHealthKey]*jobHealthState
+}
+
+func newJobHealth() *jobHealth {
+	h := &jobHealth{now: time.Now}
+	h.reset()
+	return h
+}
+
+// reset forgets the tracked jobs, when the jobs config changes. The jobs which
+// never succeeded are unhealthy unhealthyAfter after the reset.
+func (h *jobHealth) reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.since = h.now()
+	h.jobs = map[jobHealthKey]*jobHealthState{}
+}
+
+func (h *jobHealth) observe(outcomes []model.JobOutcome) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := h.now()
+	for _, outcome := range outcomes {
+		key := jobHealthKey{job: outcome.Job, region: outcome.Region, roleArn: outcome.RoleArn}
+		state, ok := h.jobs[key]
+		if !ok {
+			state = &jobHealthState{}
+			h.jobs[key] = state
+		}
+		state.accountID = outcome.AccountID
+		state.unhealthyAfter = outcome.UnhealthyAfter
+		switch {
+		case outcome.Paused:
+			state.lastPaused = now
+		case outcome.Err == nil:
+			state.lastSuccess = now
+			state.lastErr = nil
+		default:
+			state.lastErr = outcome.Err
+		}
+	}
+}
+
+// unhealthy returns a description of the jobs which didn't succeed for longer than their
+// unhealthyAfter. The time a job is paused by its schedule does not count.
+func (h *jobHealth) unhealthy() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := h.now()
+	var failing []string
+	for key, state := range h.jobs {
+		if state.unhealthyAfter <= 0 {
+			continue
+		}
+		last := state.lastSuccess
+		if last.IsZero() {
+			last = h.since
+		}
+		if state.lastPaused.After(last) {
+			last = state.lastPaused
+		}
+		if age := now.Sub(last); age > state.unhealthyAfter {
+			line := fmt.Sprintf("job %s in %s of account %s: no successful scrape for %s", key.job, key.region, state.accountID, age.Truncate(time.Second))
+			if state.lastErr != nil {
+				line += ", last error: " + state.lastErr.Error()
+			}
+			failing = append(failing, line)
+		}
+	}
+	slices.Sort(failing)
+	return failing
+}
+
+// jobState is the last outcome of a job in a region with a role.
+type jobState struct {
+	Job            string    `json:"job"`
+	Region         string    `json:"region"`
+	RoleArn        string    `json:"role_arn,omitempty"`
+	AccountID      string    `json:"account_id"`
+	LastSuccess    time.Time `json:"last_success,omitempty"`
+	LastError      string    `json:"last_error,omitempty"`
+	LastPaused     time.Time `json:"last_paused,omitempty"`
+	UnhealthyAfter string    `json:"unhealthy_after,omitempty"`
+}
+
+// snapshot returns the last outcomes of the tracked jobs, sorted by job, region and role.
+func (h *jobHealth) snapshot() []jobState {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	states := make([]jobState, 0, len(h.jobs))
+	for key, state := range h.jobs {
+		js := jobState{
+			Job:         key.job,
+			Region:      key.region,
+			RoleArn:     key.roleArn,
+			AccountID:   state.accountID,
+			LastSuccess: state.lastSuccess,
+			LastPaused:  state.lastPaused,
+		}
+		if state.lastErr != nil {
+			js.LastError = state.lastErr.Error()
+		}
+		if state.unhealthyAfter > 0 {
+			js.UnhealthyAfter = state.unhealthyAfter.String()
+		}
+		states = append(states, js)
+	}
+	slices.SortFunc(states, func(a, b jobState) int {
+		return cmp.Or(cmp.Compare(a.Job, b.Job), cmp.Compare(a.Region, b.Region), cmp.Compare(a.RoleArn, b.RoleArn))
+	})
+	return states
+}
+
+// makeHealthHandler serves 200 when all the jobs with unhealthyAfter succeeded recently
+// enough, and 503 with the failing jobs otherwise.
+func (s *scraper) makeHealthHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		if failing := s.jobHealth.unhealthy(); len(failing) > 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("unhealthy\n" + strings.Join(failing, "\n") + "\n"))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+func TestJobHealth(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newJobHealth()
+	h.now = func() time.Time { return now }
+	h.reset()
+
+	h.observe([]model.JobOutcome{
+		{Job: "AWS/RDS", Region: "eu-west-1", RoleArn: "arn:aws:iam::123456789012:role/yace", AccountID: "123456789012", UnhealthyAfter: 15 * time.Minute},
+		{Job: "AWS/S3", Region: "eu-west-1", AccountID: "123456789012"},
+	})
+	require.Empty(t, h.unhealthy())
+
+	now = now.Add(10 * time.Minute)
+	h.observe([]model.JobOutcome{
+		{Job: "AWS/RDS", Region: "eu-west-1", RoleArn: "arn:aws:iam::123456789012:role/yace", AccountID: "123456789012", UnhealthyAfter: 15 * time.Minute, Err: errors.New("ExpiredToken")},
+		{Job: "AWS/S3", Region: "eu-west-1", AccountID: "123456789012", Err: errors.New("ExpiredToken")},
+	})
+	require.Empty(t, h.unhealthy(), "the job failing for less than unhealthyAfter should be healthy")
+
+	now = now.Add(6 * time.Minute)
+	require.Equal(t, []string{
+		"job AWS/RDS in eu-west-1 of account 123456789012: no successful scrape for 16m0s, last error: ExpiredToken",
+	}, h.unhealthy(), "only the jobs with unhealthyAfter should be checked")
+
+	s := NewScraper(nil)
+	s.jobHealth = h
+	rec := httptest.NewRecorder()
+	s.makeHealthHandler()(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	// The jobs which never succeeded are unhealthy unhealthyAfter after the reset
+	h.reset()
+	h.observe([]model.JobOutcome{{Job: "billing", Region: "us-east-1", UnhealthyAfter: time.Hour, Err: errors.New("AccessDenied")}})
+	require.Empty(t, h.unhealthy())
+	rec = httptest.NewRecorder()
+	s.makeHealthHandler()(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	now = now.Add(time.Hour + time.Second)
+	require.Len(t, h.unhealthy(), 1)
+
+	// The time a job is paused by its schedule does not count
+	h.observe([]model.JobOutcome{{Job: "billing", Region: "us-east-1", UnhealthyAfter: time.Hour, Paused: true}})
+	require.Empty(t, h.unhealthy())
+	now = now.Add(30 * time.Minute)
+	h.observe([]model.JobOutcome{{Job: "billing", Region: "us-east-1", UnhealthyAfter: time.Hour, Err: errors.New("AccessDenied")}})
+	require.Empty(t, h.unhealthy())
+	now = now.Add(31 * time.Minute)
+	require.Len(t, h.unhealthy(), 1)
+}
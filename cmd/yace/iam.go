@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/iamcheck"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+// checkPermissions simulates the policies of the roles of the jobs.
+func checkPermissions(ctx context.Context, jobsCfg model.JobsConfig) ([]iamcheck.Report, error) {
+	checker, err := iamcheck.NewChecker(jobsCfg.StsRegion, fips)
+	if err != nil {
+		return nil, err
+	}
+	return checker.Check(ctx, iamcheck.Requirements(jobsCfg)), nil
+}
+
+// logPermissions logs the permissions the roles of the jobs are missing or don't need.
+func logPermissions(ctx context.Context, jobsCfg model.JobsConfig) {
+	reports, err := checkPermissions(ctx, jobsCfg)
+	if err != nil {
+		logger.Error(err, "Couldn't check the IAM permissions")
+		return
+	}
+	for _, report := range reports {
+		switch {
+		case report.Error != "":
+			logger.Warn("Couldn't check the IAM permissions of the role", "role_arn", report.RoleArn, "err", report.Error)
+		case len(report.Missing) > 0:
+			logger.Warn("The role is missing IAM permissions needed by its jobs, see /debug/iam for a suggested policy",
+				"role_arn", report.RoleArn, "principal", report.Principal, "missing", strings.Join(report.Missing, ","))
+		default:
+			logger.Info("The role has the IAM permissions needed by its jobs", "role_arn", report.RoleArn, "principal", report.Principal)
+		}
+		if len(report.Unused) > 0 {
+			logger.Info("The role has IAM permissions its jobs don't need", "role_arn", report.RoleArn, "unused", strings.Join(report.Unused, ","))
+		}
+	}
+}
+
+// makeIAMHandler checks the IAM permissions of the roles of the running jobs, and
+// serves the reports as JSON.
+func (s *scraper) makeIAMHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		jobsCfg := s.jobsCfg.Load()
+		if jobsCfg == nil {
+			http.Error(w, "No jobs are running", http.StatusServiceUnavailable)
+			return
+		}
+		reports, err := checkPermissions(r.Context(), *jobsCfg)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(reports)
+	}
+}
@@ -0,0 +1,17 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIAMHandlerWithoutJobs(t *testing.T) {
+	s := NewScraper(nil)
+
+	rec := httptest.NewRecorder()
+	s.makeIAMHandler()(rec, httptest.NewRequest(http.MethodGet, "/debug/iam", nil))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"runtime"
+	"slices"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
+)
+
+var (
+	buildInfo = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "yace_build_info",
+		Help: "Version of the exporter, Go version it was built with and AWS SDK it uses, always 1.",
+	}, []string{"version", "goversion", "sdk"})
+	configHash = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "yace_config_hash",
+		Help: "SHA-256 of the config file of the running jobs, always 1.",
+	}, []string{"hash"})
+	configLastReloadSuccess = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "yace_config_last_reload_success_timestamp_seconds",
+		Help: "Timestamp of the last successful load of the config file.",
+	})
+
+	// infoMetrics are the metrics describing the running exporter, served along the scraped ones
+	infoMetrics = []prometheus.Collector{buildInfo, configHash, configLastReloadSuccess, degradedMode}
+)
+
+// setBuildInfo sets the build info of the exporter, with the AWS SDK of the feature flags.
+func setBuildInfo(featureFlags []string) {
+	sdk := "v1"
+	if slices.Contains(featureFlags, config.AwsSdkV2) {
+		sdk = "v2"
+	}
+	buildInfo.Reset()
+	buildInfo.WithLabelValues(version, runtime.Version(), sdk).Set(1)
+}
+
+// setConfigLoaded records the config of the running jobs, after it was loaded successfully.
+func setConfigLoaded(hash string) {
+	configHash.Reset()
+	configHash.WithLabelValues(hash).Set(1)
+	configLastReloadSuccess.Set(float64(time.Now().Unix()))
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
+)
+
+func TestInfoMetrics(t *testing.T) {
+	setBuildInfo([]string{config.AwsSdkV2})
+	require.Equal(t, 1.0, testutil.ToFloat64(buildInfo.WithLabelValues(version, runtime.Version(), "v2")))
+	setBuildInfo(nil)
+	require.Equal(t, 1, testutil.CollectAndCount(buildInfo))
+	require.Equal(t, 1.0, testutil.ToFloat64(buildInfo.WithLabelValues(version, runtime.Version(), "v1")))
+
+	setConfigLoaded("first")
+	setConfigLoaded("second")
+	require.NoError(t, testutil.CollectAndCompare(configHash, strings.NewReader(`
+# HELP yace_config_hash SHA-256 of the config file of the running jobs, always 1.
+# TYPE yace_config_hash gauge
+yace_config_hash{hash="second"} 1
+`)))
+	require.Positive(t, testutil.ToFloat64(configLastReloadSuccess))
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	unixAddressPrefix = "unix://"
+	// systemdListenFdsStart is the first file descriptor passed by systemd socket activation
+	systemdListenFdsStart = 3
+)
+
+// listen returns the listeners of the HTTP server: the sockets passed by systemd when
+// systemdSocket is set, a Unix domain socket for an address unix:///path, with the
+// permissions of socketMode, and a TCP socket otherwise.
+func listen(address string, systemdSocket bool, socketMode string) ([]net.Listener, error) {
+	if systemdSocket {
+		return systemdListeners()
+	}
+
+	path, ok := strings.CutPrefix(address, unixAddressPrefix)
+	if !ok {
+		listener, err := net.Listen("tcp", address)
+		if err != nil {
+			return nil, err
+		}
+		return []net.Listener{listener}, nil
+	}
+
+	mode, err := strconv.ParseUint(socketMode, 8, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid unix socket mode %q: %w", socketMode, err)
+	}
+	// A socket left by an exporter which didn't shut down cleanly prevents listening
+	if info, err := os.Stat(path); err == nil && info.Mode().Type() == os.ModeSocket {
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale unix socket %s: %w", path, err)
+		}
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, os.FileMode(mode)); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to set the mode of unix socket %s: %w", path, err)
+	}
+	return []net.Listener{listener}, nil
+}
+
+// systemdListeners returns the sockets passed by systemd socket activation, following
+// the LISTEN_PID and LISTEN_FDS protocol of sd_listen_fds.
+func systemdListeners() ([]net.Listener, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, errors.New("no socket passed by systemd, the exporter must be started by a systemd socket unit")
+	}
+	fds, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || fds < 1 {
+		return nil, errors.New("no socket passed by systemd, the exporter must be started by a systemd socket unit")
+	}
+	// The variables are meant for this process, not for its children
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	listeners := make([]net.Listener, 0, fds)
+	for fd := systemdListenFdsStart; fd < systemdListenFdsStart+fds; fd++ {
+		// net.FileListener duplicates the socket with close-on-exec, the passed one is closed
+		file := os.NewFile(uintptr(fd), "LISTEN_FD_"+strconv.Itoa(fd))
+		listener, err := net.FileListener(file)
+		file.Close()
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return nil, fmt.Errorf("failed to use the socket %d passed by systemd: %w", fd, err)
+		}
+		listeners = append(listeners, listener)
+	}
+	return listeners, nil
+}
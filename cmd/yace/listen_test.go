@@ -0,0 +1,67 @@
+package main
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenUnixSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "yace.sock")
+
+	listeners, err := listen("unix://"+path, false, "0600")
+	require.NoError(t, err)
+	require.Len(t, listeners, 1)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.ModeSocket, info.Mode().Type())
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	conn, err := net.Dial("unix", path)
+	require.NoError(t, err)
+	conn.Close()
+
+	// A stale socket, e.g. after a crash, is replaced
+	listeners[0].(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, listeners[0].Close())
+	listeners, err = listen("unix://"+path, false, "0660")
+	require.NoError(t, err)
+	require.NoError(t, listeners[0].Close())
+
+	_, err = os.Stat(path)
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestListenUnixSocketErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "yace.sock")
+
+	_, err := listen("unix://"+path, false, "rw")
+	require.ErrorContains(t, err, "invalid unix socket mode")
+
+	// A file which isn't a socket is never removed
+	require.NoError(t, os.WriteFile(path, []byte("data"), 0o600))
+	_, err = listen("unix://"+path, false, "0660")
+	require.Error(t, err)
+	_, err = os.Stat(path)
+	require.NoError(t, err)
+}
+
+func TestListenTCP(t *testing.T) {
+	listeners, err := listen("127.0.0.1:0", false, "0660")
+	require.NoError(t, err)
+	require.Len(t, listeners, 1)
+	require.Equal(t, "tcp", listeners[0].Addr().Network())
+	require.NoError(t, listeners[0].Close())
+}
+
+func TestListenSystemdSocketWithoutActivation(t *testing.T) {
+	t.Setenv("LISTEN_PID", "1")
+	t.Setenv("LISTEN_FDS", "1")
+
+	_, err := listen(":5000", true, "0660")
+	require.ErrorContains(t, err, "no socket passed by systemd")
+}
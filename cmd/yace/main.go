@@ -2,26 +2,46 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/pprof"
 	"os"
+	"os/signal"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/urfave/cli/v2"
-	"golang.org/x/sync/semaphore"
 
 	exporter "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/alarms"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/budget"
 	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/cloudwatch"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/fixtures"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/tagging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/useragent"
 	v1 "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/v1"
 	v2 "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/v2"
 	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/dogstatsd"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/emf"
 	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/organizations"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/pushgateway"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/trigger"
 )
 
 const (
-	enableFeatureFlag = "enable-feature"
-	htmlVersion       = `<html>
+	enableFeatureFlag   = "enable-feature"
+	dogstatsdTagMapping = "dogstatsd.tag-mapping"
+	emfKinesisStreamARN = "emf.kinesis-stream-arn"
+	triggerSNSTopicARN  = "trigger.sns-topic-arn"
+	alarmsSNSTopicARN   = "alertmanager.sns-topic-arn"
+	alarmsLabelMapping  = "alertmanager.label-mapping"
+	htmlVersion         = `<html>
 <head><title>Yet Another CloudWatch Exporter</title></head>
 <body>
 <h1>Thanks for using YACE :)</h1>
@@ -35,24 +55,56 @@ Version: %s
 
 var version = "custom-build"
 
-var sem = semaphore.NewWeighted(1)
-
 const (
 	defaultLogFormat = "json"
 )
 
 var (
-	addr                  string
-	configFile            string
-	debug                 bool
-	logFormat             string
-	fips                  bool
-	cloudwatchConcurrency cloudwatch.ConcurrencyConfig
-	tagConcurrency        int
-	scrapingInterval      int
-	metricsPerQuery       int
-	labelsSnakeCase       bool
-	profilingEnabled      bool
+	addr                     string
+	listenSystemdSocket      bool
+	listenUnixSocketMode     string
+	configFile               string
+	debug                    bool
+	logFormat                string
+	fips                     bool
+	awsTraceHeader           bool
+	cloudwatchConcurrency    cloudwatch.ConcurrencyConfig
+	tagConcurrency           int
+	schedulerConcurrency     int
+	budgetTable              string
+	budgetLimits             budget.Limits
+	requestBudget            cloudwatch.RequestBudget
+	budgetMonthlyCost        float64
+	degradedThreshold        float64
+	degradedIntervalFactor   int
+	exporterContextLabels    bool
+	exporterLabels           map[string]string
+	scrapingInterval         int
+	resourcesRefreshInterval int
+	resourcesMaxStaleness    int
+	resourcesCacheFile       string
+	drainTimeout             int
+	enableQuit               bool
+	enableDump               bool
+	dumpDir                  string
+	iamSelfCheck             bool
+	readyTimeout             int
+	metricsPerQuery          int
+	labelsSnakeCase          bool
+	profilingEnabled         bool
+	enableOpenMetrics        bool
+	recordDir                string
+	replayDir                string
+	dogstatsdAddress         string
+	pushgatewayURL           string
+	pushgatewayJob           string
+	backfillJob              string
+	backfillStart            string
+	backfillEnd              string
+	backfillOutput           string
+	triggerQueueURL          string
+	emfFirehoseAccessKey     string
+	alertmanagerURL          string
 
 	logger logging.Logger
 )
@@ -84,10 +136,22 @@ func NewYACEApp() *cli.App {
 		&cli.StringFlag{
 			Name:        "listen-address",
 			Value:       ":5000",
-			Usage:       "The address to listen on",
+			Usage:       "The address to listen on, or unix:///path/to/socket for a Unix domain socket",
 			Destination: &addr,
 			EnvVars:     []string{"listen-address"},
 		},
+		&cli.BoolFlag{
+			Name:        "listen.systemd-socket",
+			Value:       false,
+			Usage:       "Serve on the sockets passed by systemd socket activation instead of listen-address",
+			Destination: &listenSystemdSocket,
+		},
+		&cli.StringFlag{
+			Name:        "listen.unix-socket-mode",
+			Value:       "0660",
+			Usage:       "Permissions of the Unix domain socket of listen-address",
+			Destination: &listenUnixSocketMode,
+		},
 		&cli.StringFlag{
 			Name:        "config.file",
 			Value:       "config.yml",
@@ -123,6 +187,12 @@ func NewYACEApp() *cli.App {
 			Usage:       "Use FIPS compliant AWS API endpoints",
 			Destination: &fips,
 		},
+		&cli.BoolFlag{
+			Name:        "aws.trace-header",
+			Value:       false,
+			Usage:       "Add a unique X-Amzn-Trace-Id header to every AWS API request, the user agent yace/<version>/<job> being always set.",
+			Destination: &awsTraceHeader,
+		},
 		&cli.IntFlag{
 			Name:        "cloudwatch-concurrency",
 			Value:       exporter.DefaultCloudwatchConcurrency.SingleLimit,
@@ -153,6 +223,53 @@ func NewYACEApp() *cli.App {
 			Usage:       "Maximum number of concurrent requests to GetMetricStatistics CloudWatch API. Used if the -cloudwatch-concurrency.per-api-limit-enabled concurrency limiter is enabled.",
 			Destination: &cloudwatchConcurrency.GetMetricStatistics,
 		},
+		&cli.IntFlag{
+			Name:        "scheduler.cloudwatch-concurrency",
+			Value:       0,
+			Usage:       "Maximum number of concurrent requests to CloudWatch API of all the job priority classes, shared by weight between the classes waiting for one. By default the classes aren't limited together",
+			Destination: &schedulerConcurrency,
+		},
+		&cli.StringFlag{
+			Name:        "budget.dynamodb-table",
+			Usage:       "ARN of a DynamoDB table sharing the CloudWatch API request rate budget of each account and region between the exporters using it",
+			Destination: &budgetTable,
+		},
+		&cli.IntFlag{
+			Name:        "budget.list-metrics-limit",
+			Value:       budget.DefaultLimits.ListMetrics,
+			Usage:       "Requests per second to the ListMetrics CloudWatch API of an account and region shared by the exporters. Used if -budget.dynamodb-table is set",
+			Destination: &budgetLimits.ListMetrics,
+		},
+		&cli.IntFlag{
+			Name:        "budget.get-metric-data-limit",
+			Value:       budget.DefaultLimits.GetMetricData,
+			Usage:       "Requests per second to the GetMetricData CloudWatch API of an account and region shared by the exporters. Used if -budget.dynamodb-table is set",
+			Destination: &budgetLimits.GetMetricData,
+		},
+		&cli.IntFlag{
+			Name:        "budget.get-metric-statistics-limit",
+			Value:       budget.DefaultLimits.GetMetricStatistics,
+			Usage:       "Requests per second to the GetMetricStatistics CloudWatch API of an account and region shared by the exporters. Used if -budget.dynamodb-table is set",
+			Destination: &budgetLimits.GetMetricStatistics,
+		},
+		&cli.Float64Flag{
+			Name:        "budget.monthly-cost",
+			Value:       0,
+			Usage:       "Monthly budget of the cost of the CloudWatch API requests of the exporter, in USD. 0 doesn't limit it",
+			Destination: &budgetMonthlyCost,
+		},
+		&cli.Float64Flag{
+			Name:        "budget.degraded-threshold",
+			Value:       0.9,
+			Usage:       "Share of -budget.monthly-cost whose spending in a month scrapes the low priority jobs less often",
+			Destination: &degradedThreshold,
+		},
+		&cli.IntFlag{
+			Name:        "budget.degraded-interval-factor",
+			Value:       4,
+			Usage:       "Number of scraping intervals between the scrapes of the low priority jobs when the CloudWatch API budgets are nearly spent",
+			Destination: &degradedIntervalFactor,
+		},
 		&cli.IntFlag{
 			Name:        "tag-concurrency",
 			Value:       exporter.DefaultTaggingAPIConcurrency,
@@ -166,6 +283,58 @@ func NewYACEApp() *cli.App {
 			Destination: &scrapingInterval,
 			EnvVars:     []string{"scraping-interval"},
 		},
+		&cli.IntFlag{
+			Name:        "info-metrics-refresh-interval",
+			Value:       0,
+			Usage:       "Seconds to cache discovered resources for, which are used for info metrics and to associate metrics to resources. By default they are refreshed on every scrape",
+			Destination: &resourcesRefreshInterval,
+		},
+		&cli.IntFlag{
+			Name:        "tagging.max-staleness",
+			Value:       0,
+			Usage:       "Seconds to keep serving the discovered resources for when their discovery fails, e.g. when the tagging API of a region is degraded. By default the metrics of the failed discovery are not exported",
+			Destination: &resourcesMaxStaleness,
+		},
+		&cli.StringFlag{
+			Name:        "tagging.cache-file",
+			Usage:       "Path of a file the discovered resources are saved to on shutdown, and loaded from on startup. Used if -info-metrics-refresh-interval or -tagging.max-staleness is set",
+			Destination: &resourcesCacheFile,
+		},
+		&cli.IntFlag{
+			Name:        "ready.timeout",
+			Value:       300,
+			Usage:       "Seconds after which /-/ready reports the exporter ready even if the initial scrape of all the jobs didn't complete. 0 waits for the initial scrape",
+			Destination: &readyTimeout,
+		},
+		&cli.IntFlag{
+			Name:        "shutdown.drain-timeout",
+			Value:       20,
+			Usage:       "Seconds to wait on shutdown for the running scrapes to complete before cancelling them",
+			Destination: &drainTimeout,
+		},
+		&cli.BoolFlag{
+			Name:        "web.enable-quit",
+			Value:       false,
+			Usage:       "Enable the /-/quit endpoint, shutting the exporter down on POST requests",
+			Destination: &enableQuit,
+		},
+		&cli.BoolFlag{
+			Name:        "web.enable-dump",
+			Value:       false,
+			Usage:       "Enable the /debug/dump endpoint, writing a state dump on POST requests at most once a minute",
+			Destination: &enableDump,
+		},
+		&cli.StringFlag{
+			Name:        "debug.dump-dir",
+			Usage:       "Directory the state dumps of SIGUSR1 and /debug/dump are written to, the temporary directory by default",
+			Destination: &dumpDir,
+		},
+		&cli.BoolFlag{
+			Name:        "iam.self-check",
+			Value:       false,
+			Usage:       "Check the IAM permissions of the roles of the jobs with iam:SimulatePrincipalPolicy at startup and when the jobs change",
+			Destination: &iamSelfCheck,
+		},
 		&cli.IntFlag{
 			Name:        "metrics-per-query",
 			Value:       exporter.DefaultMetricsPerQuery,
@@ -179,12 +348,74 @@ func NewYACEApp() *cli.App {
 			Usage:       "Whether labels should be output in snake case instead of camel case",
 			Destination: &labelsSnakeCase,
 		},
+		&cli.BoolFlag{
+			Name:        "labels.exporter-context",
+			Value:       false,
+			Usage:       "Add the region, availability zone, cluster and instance where the exporter runs as exporter_* labels to the AWS metrics, from the ECS task metadata or the EC2 instance metadata.",
+			Destination: &exporterContextLabels,
+		},
 		&cli.BoolFlag{
 			Name:        "profiling.enabled",
 			Value:       false,
 			Usage:       "Enable pprof endpoints",
 			Destination: &profilingEnabled,
 		},
+		&cli.BoolFlag{
+			Name:        "openmetrics",
+			Value:       false,
+			Usage:       "Expose metrics in the OpenMetrics format to scrapers requesting it",
+			Destination: &enableOpenMetrics,
+		},
+		&cli.StringFlag{
+			Name:        "record",
+			Usage:       "Directory to record the responses of the AWS APIs to, as fixtures for --replay",
+			Destination: &recordDir,
+		},
+		&cli.StringFlag{
+			Name:        "replay",
+			Usage:       "Directory of fixtures recorded with --record to serve scrapes from, without calling AWS",
+			Destination: &replayDir,
+		},
+		&cli.StringFlag{
+			Name:        "dogstatsd.address",
+			Usage:       "Address of a DogStatsD server to also send the metrics to, e.g. udp://localhost:8125 or unix:///var/run/datadog/dsd.socket",
+			Destination: &dogstatsdAddress,
+		},
+		&cli.StringSliceFlag{
+			Name:  dogstatsdTagMapping,
+			Usage: "Comma-separated list of label=tag renaming the labels sent as DogStatsD tags. An empty tag drops the label",
+		},
+		&cli.StringFlag{
+			Name:        "trigger.sqs-queue-url",
+			Usage:       "URL of an SQS queue of EventBridge events triggering a scrape, and a refresh of the discovery of the resources they reference",
+			Destination: &triggerQueueURL,
+		},
+		&cli.StringSliceFlag{
+			Name:  triggerSNSTopicARN,
+			Usage: "Comma-separated list of ARNs of SNS topics of EventBridge events triggering a scrape, and a refresh of the discovery of the resources they reference, enabling the /trigger/sns endpoint they notify",
+		},
+		&cli.StringSliceFlag{
+			Name:  emfKinesisStreamARN,
+			Usage: "Comma-separated list of ARNs of Kinesis data streams of CloudWatch Embedded Metric Format logs to expose the metrics of",
+		},
+		&cli.StringFlag{
+			Name:        "emf.firehose-access-key",
+			Usage:       "Access key of Firehose delivery streams of CloudWatch Embedded Metric Format logs, enabling the /emf/firehose endpoint they deliver to",
+			Destination: &emfFirehoseAccessKey,
+		},
+		&cli.StringFlag{
+			Name:        "alertmanager.url",
+			Usage:       "URL of an Alertmanager to send the state changes of CloudWatch alarms to as alerts, enabling the /alarms/sns endpoint the SNS topics of the alarms notify",
+			Destination: &alertmanagerURL,
+		},
+		&cli.StringSliceFlag{
+			Name:  alarmsSNSTopicARN,
+			Usage: "Comma-separated list of ARNs of the SNS topics of CloudWatch alarms whose notifications /alarms/sns accepts",
+		},
+		&cli.StringSliceFlag{
+			Name:  alarmsLabelMapping,
+			Usage: "Comma-separated list of label=name renaming the labels of the alerts of the CloudWatch alarms. An empty name drops the label",
+		},
 		&cli.StringSliceFlag{
 			Name:  enableFeatureFlag,
 			Usage: "Comma-separated list of enabled features",
@@ -212,6 +443,42 @@ func NewYACEApp() *cli.App {
 				return nil
 			},
 		},
+		{
+			Name:  "dimensions-regexps",
+			Usage: "Prints the regexps extracting the dimensions from the resource ARNs for the discovery jobs of the config file, then exits. Useful for overriding them with dimensionsRegexps",
+			Flags: []cli.Flag{
+				&cli.StringFlag{Name: "config.file", Value: "config.yml", Usage: "Path to configuration file.", Destination: &configFile},
+			},
+			Action: printDimensionsRegexps,
+		},
+		{
+			Name:  "plan",
+			Usage: "Discovers the resources and lists the metrics of the jobs of the config file, then prints the CloudWatch API requests of a scrape and their monthly cost, without requesting any datapoint",
+			Flags: []cli.Flag{
+				&cli.StringFlag{Name: "config.file", Value: "config.yml", Usage: "Path to configuration file.", Destination: &configFile},
+			},
+			Action: printPlan,
+		},
+		{
+			Name:  "push",
+			Usage: "Scrapes the metrics once and pushes them to a Prometheus Pushgateway, grouped by region and account, then exits. Useful for batch jobs which cannot be scraped",
+			Flags: []cli.Flag{
+				&cli.StringFlag{Name: "pushgateway.url", Usage: "URL of the Pushgateway", Required: true, Destination: &pushgatewayURL},
+				&cli.StringFlag{Name: "pushgateway.job", Value: "yace", Usage: "Job label of the pushed metrics", Destination: &pushgatewayJob},
+			},
+			Action: pushMetrics,
+		},
+		{
+			Name:  "backfill",
+			Usage: "Exports the historical datapoints of a discovery or custom namespace job of the config file with their CloudWatch timestamps to stdout, then exits. Useful for backfilling Prometheus with promtool tsdb create-blocks-from openmetrics",
+			Flags: []cli.Flag{
+				&cli.StringFlag{Name: "job", Usage: "Namespace or service alias of the discovery jobs, or name of the custom namespace job, e.g. AWS/S3 or s3", Required: true, Destination: &backfillJob},
+				&cli.StringFlag{Name: "start", Usage: "Start of the datapoints, in RFC 3339 format, e.g. 2024-01-01T00:00:00Z", Required: true, Destination: &backfillStart},
+				&cli.StringFlag{Name: "end", Usage: "End of the datapoints, in RFC 3339 format, e.g. 2024-01-15T00:00:00Z", Required: true, Destination: &backfillEnd},
+				&cli.StringFlag{Name: "output", Value: backfillOutputOpenMetrics, Usage: "Format of the datapoints, only openmetrics is supported", Destination: &backfillOutput},
+			},
+			Action: backfill,
+		},
 		{
 			Name:    "version",
 			Aliases: []string{"v"},
@@ -245,22 +512,125 @@ func startScraper(c *cli.Context) error {
 		return fmt.Errorf("Couldn't read %s: %w", configFile, err)
 	}
 
+	if err := setupRequestBudget(); err != nil {
+		return err
+	}
+	setupExporterLabels(context.Background())
+
 	featureFlags := c.StringSlice(enableFeatureFlag)
 	s := NewScraper(featureFlags)
-	var cache cachingFactory = v1.NewFactory(logger, jobsCfg, fips)
-	for _, featureFlag := range featureFlags {
-		if featureFlag == config.AwsSdkV2 {
-			var err error
-			// Can't override cache while also creating err
-			cache, err = v2.NewFactory(logger, jobsCfg, fips)
-			if err != nil {
-				return fmt.Errorf("failed to construct aws sdk v2 client cache: %w", err)
+	if s.degradation, err = setupDegradation(); err != nil {
+		return err
+	}
+	if resourcesRefreshInterval > 0 || resourcesMaxStaleness > 0 {
+		s.resourceCache = tagging.NewResourceCache(time.Duration(resourcesRefreshInterval)*time.Second, time.Duration(resourcesMaxStaleness)*time.Second)
+		if resourcesCacheFile != "" {
+			if err := loadResourceCache(s.resourceCache, resourcesCacheFile); err != nil {
+				logger.Warn("Couldn't load the saved resources, discovering them again", "path", resourcesCacheFile, "err", err)
 			}
 		}
 	}
+	roles, err := organizationRoles(context.Background(), jobsCfg)
+	if err != nil {
+		return err
+	}
+	if dogstatsdAddress != "" {
+		tagMapping, err := parseTagMapping(c.StringSlice(dogstatsdTagMapping))
+		if err != nil {
+			return err
+		}
+		s.dogstatsd, err = dogstatsd.NewSink(dogstatsdAddress, tagMapping)
+		if err != nil {
+			return err
+		}
+		defer s.dogstatsd.Close()
+	}
+	triggerTopicARNs := c.StringSlice(triggerSNSTopicARN)
+	if triggerQueueURL != "" || len(triggerTopicARNs) > 0 {
+		s.triggers = make(chan trigger.Event)
+	}
+	if triggerQueueURL != "" {
+		listener, err := trigger.NewListener(logger, triggerQueueURL, fips)
+		if err != nil {
+			return err
+		}
+		go listener.Run(context.Background(), s.triggers)
+	}
+	emfStreamARNs := c.StringSlice(emfKinesisStreamARN)
+	if len(emfStreamARNs) > 0 || emfFirehoseAccessKey != "" {
+		s.emf = emf.NewStore(emf.DefaultSeriesTTL)
+	}
+	for _, streamARN := range emfStreamARNs {
+		consumer, err := emf.NewKinesisConsumer(logger, streamARN, fips, s.emf)
+		if err != nil {
+			return err
+		}
+		go consumer.Run(context.Background())
+	}
+	var alarmsHandler http.Handler
+	if alertmanagerURL != "" {
+		alarmsHandler, err = newAlarmsHandler(c.StringSlice(alarmsSNSTopicARN), c.StringSlice(alarmsLabelMapping))
+		if err != nil {
+			return err
+		}
+	}
 
-	ctx, cancelRunningScrape := context.WithCancel(context.Background())
-	go s.decoupled(ctx, logger, jobsCfg, cache)
+	// start replaces the running scrape with one of the jobs config, the discovery
+	// jobs with organizationAccounts scraping the accounts of the roles.
+	var (
+		mu                  sync.Mutex
+		cancelRunningScrape = func() {}
+		start               func(jobsCfg model.JobsConfig, roles []model.Role) error
+	)
+	start = func(jobsCfg model.JobsConfig, roles []model.Role) error {
+		expandedCfg := organizations.ExpandRoles(jobsCfg, roles)
+		cache, err := newFactory(expandedCfg, featureFlags)
+		if err != nil {
+			return err
+		}
+
+		cancelRunningScrape()
+		s.jobHealth.reset()
+		var ctx context.Context
+		ctx, cancelRunningScrape = context.WithCancel(context.Background())
+		go s.decoupled(ctx, logger, expandedCfg, cache)
+		s.jobsCfg.Store(&expandedCfg)
+		if iamSelfCheck {
+			go logPermissions(ctx, expandedCfg)
+		}
+
+		if jobsCfg.Organization != nil {
+			go watchOrganization(ctx, jobsCfg, roles, func(newRoles []model.Role) {
+				mu.Lock()
+				defer mu.Unlock()
+				// The config was reloaded meanwhile
+				if ctx.Err() != nil {
+					return
+				}
+				logger.Info("Organization accounts changed, reset clients cache", "accounts", len(newRoles))
+				if err := start(jobsCfg, newRoles); err != nil {
+					logger.Error(err, "Failed to construct the clients")
+				}
+			})
+		}
+		return nil
+	}
+
+	mu.Lock()
+	err = start(jobsCfg, roles)
+	mu.Unlock()
+	if err != nil {
+		return err
+	}
+	setBuildInfo(featureFlags)
+	setConfigLoaded(cfg.Hash())
+	if readyTimeout > 0 {
+		time.AfterFunc(time.Duration(readyTimeout)*time.Second, func() {
+			if !s.ready.Swap(true) {
+				logger.Warn("The initial scrape didn't complete within the ready timeout, reporting ready", "timeout", readyTimeout)
+			}
+		})
+	}
 
 	mux := http.NewServeMux()
 
@@ -273,6 +643,21 @@ func startScraper(c *cli.Context) error {
 	}
 
 	mux.HandleFunc("/metrics", s.makeHandler())
+	mux.HandleFunc("/debug/unmatched", s.makeUnmatchedHandler())
+	if enableDump {
+		mux.HandleFunc("/debug/dump", s.makeDumpHandler(dumpHandlerInterval))
+	}
+	mux.HandleFunc("/debug/iam", s.makeIAMHandler())
+	mux.HandleFunc("/debug/throttles", makeThrottlesHandler())
+	if emfFirehoseAccessKey != "" {
+		mux.Handle("/emf/firehose", emf.NewFirehoseHandler(logger, emfFirehoseAccessKey, s.emf))
+	}
+	if alarmsHandler != nil {
+		mux.Handle("/alarms/sns", alarmsHandler)
+	}
+	if len(triggerTopicARNs) > 0 {
+		mux.Handle("/trigger/sns", trigger.NewSNSHandler(logger, triggerTopicARNs, s.triggers))
+	}
 
 	mux.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) {
 		pprofLink := ""
@@ -283,10 +668,13 @@ func startScraper(c *cli.Context) error {
 		_, _ = w.Write([]byte(fmt.Sprintf(htmlVersion, version, pprofLink)))
 	})
 
-	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte("ok"))
-	})
+	mux.HandleFunc("/healthz", s.makeHealthHandler())
+	mux.HandleFunc("/-/ready", s.makeReadyHandler())
+
+	quit := make(chan struct{}, 1)
+	if enableQuit {
+		mux.HandleFunc("/-/quit", makeQuitHandler(quit))
+	}
 
 	mux.HandleFunc("/reload", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
@@ -302,28 +690,243 @@ func startScraper(c *cli.Context) error {
 			return
 		}
 
-		logger.Info("Reset clients cache")
-		cache = v1.NewFactory(logger, newJobsCfg, fips)
-		for _, featureFlag := range featureFlags {
-			if featureFlag == config.AwsSdkV2 {
-				logger.Info("Using aws sdk v2")
-				var err error
-				// Can't override cache while also creating err
-				cache, err = v2.NewFactory(logger, newJobsCfg, fips)
-				if err != nil {
-					logger.Error(err, "Failed to construct aws sdk v2 client cache", "path", configFile)
-					return
-				}
-			}
+		roles, err := organizationRoles(r.Context(), newJobsCfg)
+		if err != nil {
+			logger.Error(err, "Couldn't discover the organization accounts", "path", configFile)
+			return
 		}
 
-		cancelRunningScrape()
-		ctx, cancelRunningScrape = context.WithCancel(context.Background())
-		go s.decoupled(ctx, logger, newJobsCfg, cache)
+		logger.Info("Reset clients cache")
+		mu.Lock()
+		defer mu.Unlock()
+		if err := start(newJobsCfg, roles); err != nil {
+			logger.Error(err, "Failed to construct the clients", "path", configFile)
+			return
+		}
+		setConfigLoaded(newCfg.Hash())
 	})
 
 	logger.Info("Yace startup completed", "version", version, "feature_flags", strings.Join(featureFlags, ","))
 
-	srv := &http.Server{Addr: addr, Handler: mux}
-	return srv.ListenAndServe()
+	listeners, err := listen(addr, listenSystemdSocket, listenUnixSocketMode)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	srv := &http.Server{Handler: mux}
+	serveErr := make(chan error, len(listeners))
+	for _, listener := range listeners {
+		go func() {
+			serveErr <- srv.Serve(listener)
+		}()
+	}
+
+	dumps := make(chan os.Signal, 1)
+	notifyDump(dumps)
+	defer signal.Stop(dumps)
+	go func() {
+		for range dumps {
+			if _, path, err := s.dumpState(); err != nil {
+				logger.Error(err, "Couldn't write the state dump")
+			} else {
+				logger.Info("Wrote the state dump", "path", path)
+			}
+		}
+	}()
+
+	signals, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+	select {
+	case err := <-serveErr:
+		return err
+	case <-signals.Done():
+		logger.Info("Received a termination signal, shutting down")
+	case <-quit:
+		logger.Info("Received a quit request, shutting down")
+	}
+
+	// The metrics keep being served while the running scrapes complete
+	timeout := time.Duration(drainTimeout) * time.Second
+	if !s.drainScrapes(timeout) {
+		logger.Warn("The running scrapes didn't complete within the drain timeout, cancelling them", "timeout", timeout)
+	}
+	mu.Lock()
+	cancelRunningScrape()
+	mu.Unlock()
+
+	if s.resourceCache != nil && resourcesCacheFile != "" {
+		if err := saveResourceCache(s.resourceCache, resourcesCacheFile); err != nil {
+			logger.Error(err, "Couldn't save the discovered resources", "path", resourcesCacheFile)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return srv.Shutdown(ctx)
+}
+
+// newFactory creates the factory of the AWS clients, with
+// the sdk of the feature flags and the fixtures flags.
+func newFactory(jobsCfg model.JobsConfig, featureFlags []string) (cachingFactory, error) {
+	useragent.Configure(version, awsTraceHeader)
+	var cache cachingFactory = v1.NewFactory(logger, jobsCfg, fips)
+	for _, featureFlag := range featureFlags {
+		if featureFlag == config.AwsSdkV2 {
+			logger.Info("Using aws sdk v2")
+			var err error
+			// Can't override cache while also creating err
+			cache, err = v2.NewFactory(logger, jobsCfg, fips)
+			if err != nil {
+				return nil, fmt.Errorf("failed to construct aws sdk v2 client cache: %w", err)
+			}
+		}
+	}
+	return withFixtures(cache)
+}
+
+// pushMetrics scrapes the metrics once and pushes them to a Pushgateway,
+// for running the exporter as a batch job.
+func pushMetrics(c *cli.Context) error {
+	logger = logging.NewLogger(logFormat, debug, "version", version)
+
+	logger.Info("Parsing config")
+	cfg := config.ScrapeConf{}
+	jobsCfg, err := cfg.Load(configFile, logger)
+	if err != nil {
+		return fmt.Errorf("Couldn't read %s: %w", configFile, err)
+	}
+
+	ctx := context.Background()
+	roles, err := organizationRoles(ctx, jobsCfg)
+	if err != nil {
+		return err
+	}
+	jobsCfg = organizations.ExpandRoles(jobsCfg, roles)
+	if err := setupRequestBudget(); err != nil {
+		return err
+	}
+	setupExporterLabels(ctx)
+
+	featureFlags := c.StringSlice(enableFeatureFlag)
+	cache, err := newFactory(jobsCfg, featureFlags)
+	if err != nil {
+		return err
+	}
+	cache.Refresh()
+	defer cache.Clear()
+
+	metrics, err := exporter.CollectMetrics(ctx, logger, jobsCfg, cache, scrapeOptions(featureFlags)...)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Pushing metrics", "url", pushgatewayURL, "job", pushgatewayJob)
+	return pushgateway.Push(ctx, pushgatewayURL, pushgatewayJob, metrics.Metrics(), exporter.Metrics...)
+}
+
+// printDimensionsRegexps prints the dimensions regexps in use for
+// each namespace of the discovery jobs, overridden or built-in.
+func printDimensionsRegexps(_ *cli.Context) error {
+	logger = logging.NewLogger(logFormat, debug, "version", version)
+
+	cfg := config.ScrapeConf{}
+	jobsCfg, err := cfg.Load(configFile, logger)
+	if err != nil {
+		return fmt.Errorf("Couldn't read %s: %w", configFile, err)
+	}
+
+	printed := map[string]bool{}
+	for _, job := range jobsCfg.DiscoveryJobs {
+		if printed[job.Type] {
+			continue
+		}
+		printed[job.Type] = true
+		fmt.Println(job.Type)
+		for _, dr := range job.DimensionsRegexps {
+			fmt.Printf("  %s\n", dr.Regexp)
+		}
+	}
+	return nil
+}
+
+// setupRequestBudget sets up the CloudWatch API request budget shared through
+// the DynamoDB table of -budget.dynamodb-table, if set.
+func setupRequestBudget() error {
+	if budgetTable == "" {
+		return nil
+	}
+	store, err := budget.NewDynamoDBStore(budgetTable, fips)
+	if err != nil {
+		return err
+	}
+	requestBudget = budget.New(logger, store, budgetLimits)
+	return nil
+}
+
+// setupDegradation returns the degraded mode of the budgets of -budget.monthly-cost
+// and -budget.dynamodb-table, nil when neither is set.
+func setupDegradation() (*degradation, error) {
+	b, shared := requestBudget.(*budget.Budget)
+	if budgetMonthlyCost <= 0 && !shared {
+		return nil, nil
+	}
+	if degradedThreshold <= 0 || degradedThreshold > 1 {
+		return nil, fmt.Errorf("invalid -budget.degraded-threshold %v, should be between 0 and 1", degradedThreshold)
+	}
+	if degradedIntervalFactor < 1 {
+		return nil, fmt.Errorf("invalid -budget.degraded-interval-factor %d, should be at least 1", degradedIntervalFactor)
+	}
+	var delayed func() int64
+	if shared {
+		delayed = budgetDelayed(b)
+	}
+	return newDegradation(budgetMonthlyCost, degradedThreshold, degradedIntervalFactor, delayed, time.Now()), nil
+}
+
+// parseTagMapping parses the label=tag pairs of the DogStatsD tag mapping.
+func parseTagMapping(pairs []string) (map[string]string, error) {
+	mapping := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		label, tag, ok := strings.Cut(pair, "=")
+		if !ok || label == "" {
+			return nil, fmt.Errorf("invalid DogStatsD tag mapping %q, should be label=tag", pair)
+		}
+		mapping[label] = tag
+	}
+	return mapping, nil
+}
+
+// newAlarmsHandler returns the endpoint of the SNS topics of the CloudWatch alarms,
+// sending their state changes to the Alertmanager of -alertmanager.url.
+func newAlarmsHandler(topicARNs []string, labelPairs []string) (http.Handler, error) {
+	if len(topicARNs) == 0 {
+		return nil, fmt.Errorf("-alertmanager.url requires the ARNs of the SNS topics of the alarms with -%s", alarmsSNSTopicARN)
+	}
+	labelMapping := make(map[string]string, len(labelPairs))
+	for _, pair := range labelPairs {
+		label, name, ok := strings.Cut(pair, "=")
+		if !ok || label == "" {
+			return nil, fmt.Errorf("invalid alert label mapping %q, should be label=name", pair)
+		}
+		labelMapping[label] = name
+	}
+	bridge := alarms.NewBridge(logger, alertmanagerURL, labelMapping)
+	go bridge.Run(context.Background())
+	return alarms.NewSNSHandler(logger, topicARNs, bridge), nil
+}
+
+// withFixtures replaces the factory with one replaying fixtures, or wraps
+// it to record fixtures, when the --replay or --record flags are set.
+func withFixtures(factory cachingFactory) (cachingFactory, error) {
+	switch {
+	case recordDir != "" && replayDir != "":
+		return nil, errors.New("--record and --replay cannot be used together")
+	case replayDir != "":
+		logger.Info("Replaying fixtures", "dir", replayDir)
+		return fixtures.NewReplayFactory(replayDir)
+	case recordDir != "":
+		logger.Info("Recording fixtures", "dir", recordDir)
+		return fixtures.NewRecordingFactory(logger, factory, recordDir)
+	default:
+		return factory, nil
+	}
 }
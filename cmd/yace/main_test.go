@@ -28,3 +28,12 @@ func TestYACEApp_FeatureFlagsParsedCorrectly(t *testing.T) {
 
 	require.NoError(t, app.Run([]string{"yace"}), "error running test command")
 }
+
+func TestParseTagMapping(t *testing.T) {
+	mapping, err := parseTagMapping([]string{"dimension_QueueName=queue", "name="})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"dimension_QueueName": "queue", "name": ""}, mapping)
+
+	_, err = parseTagMapping([]string{"queue"})
+	require.ErrorContains(t, err, "should be label=tag")
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/organizations"
+)
+
+// organizationRoles returns the roles of the organization accounts
+// selected by the config, none without an organization block.
+func organizationRoles(ctx context.Context, jobsCfg model.JobsConfig) ([]model.Role, error) {
+	if jobsCfg.Organization == nil {
+		return nil, nil
+	}
+
+	discoverer, err := organizations.NewDiscoverer(logger, *jobsCfg.Organization, fips)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := discoverer.Roles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Discovered organization accounts", "accounts", len(roles))
+	return roles, nil
+}
+
+// watchOrganization re-evaluates the organization accounts every refresh interval
+// until the context is cancelled. When the accounts changed, it calls restart with
+// their roles and returns. The accounts are kept when they cannot be listed.
+func watchOrganization(ctx context.Context, jobsCfg model.JobsConfig, roles []model.Role, restart func([]model.Role)) {
+	ticker := time.NewTicker(jobsCfg.Organization.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		newRoles, err := organizationRoles(ctx, jobsCfg)
+		if err != nil {
+			logger.Error(err, "Failed to re-evaluate the organization accounts, keeping the previous ones")
+			continue
+		}
+		if !slices.Equal(roles, newRoles) {
+			restart(newRoles)
+			return
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	exporter "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/organizations"
+)
+
+// CloudWatch API prices in USD, without free tier. ListMetrics and GetMetricStatistics are
+// charged per request, GetMetricData per metric requested.
+const (
+	pricePerRequest         = 0.01 / 1000
+	pricePerMetricRequested = 0.01 / 1000
+)
+
+const month = 30 * 24 * time.Hour
+
+// printPlan prints the CloudWatch API usage of a scrape of the jobs of the config
+// file and its monthly cost at the scraping interval, without exporting anything.
+func printPlan(c *cli.Context) error {
+	logger = logging.NewLogger(logFormat, debug, "version", version)
+
+	cfg := config.ScrapeConf{}
+	jobsCfg, err := cfg.Load(configFile, logger)
+	if err != nil {
+		return fmt.Errorf("Couldn't read %s: %w", configFile, err)
+	}
+
+	ctx := context.Background()
+	roles, err := organizationRoles(ctx, jobsCfg)
+	if err != nil {
+		return err
+	}
+	jobsCfg = organizations.ExpandRoles(jobsCfg, roles)
+
+	featureFlags := c.StringSlice(enableFeatureFlag)
+	cache, err := newFactory(jobsCfg, featureFlags)
+	if err != nil {
+		return err
+	}
+	cache.Refresh()
+	defer cache.Clear()
+
+	plans, err := exporter.PlanScrape(ctx, logger, jobsCfg, cache, scrapeOptions(featureFlags)...)
+	if err != nil {
+		return err
+	}
+	return writePlan(os.Stdout, plans, time.Duration(scrapingInterval)*time.Second)
+}
+
+// writePlan writes the plans as a table, with the monthly cost of each job and the total.
+func writePlan(w io.Writer, plans []model.JobPlan, interval time.Duration) error {
+	slices.SortFunc(plans, func(a, b model.JobPlan) int {
+		return cmp.Or(
+			cmp.Compare(a.Job, b.Job),
+			cmp.Compare(a.Context.AccountID, b.Context.AccountID),
+			cmp.Compare(a.Context.Region, b.Context.Region),
+		)
+	})
+	scrapes := float64(month / interval)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "JOB\tNAMESPACE\tACCOUNT\tREGION\tRESOURCES\tLISTMETRICS\tGETMETRICDATA QUERIES\tGETMETRICDATA REQUESTS\tGETMETRICSTATISTICS\tMONTHLY COST")
+	var total model.JobPlan
+	for _, plan := range plans {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%d\t%d\t%d\t%d\t$%.2f\n",
+			plan.Job, plan.Namespace, plan.Context.AccountID, plan.Context.Region,
+			plan.Resources, plan.ListMetricsRequests, plan.GetMetricDataQueries, plan.GetMetricDataRequests, plan.GetMetricStatisticsRequests,
+			scrapes*scrapeCost(plan))
+		total.Resources += plan.Resources
+		total.ListMetricsRequests += plan.ListMetricsRequests
+		total.GetMetricDataQueries += plan.GetMetricDataQueries
+		total.GetMetricDataRequests += plan.GetMetricDataRequests
+		total.GetMetricStatisticsRequests += plan.GetMetricStatisticsRequests
+	}
+	fmt.Fprintf(tw, "TOTAL\t\t\t\t%d\t%d\t%d\t%d\t%d\t$%.2f\n",
+		total.Resources, total.ListMetricsRequests, total.GetMetricDataQueries, total.GetMetricDataRequests, total.GetMetricStatisticsRequests,
+		scrapes*scrapeCost(total))
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\nMonthly cost of %.0f scrapes, one every %s, at the CloudWatch API prices without free tier.\n", scrapes, interval)
+	return err
+}
+
+// scrapeCost returns the cost in USD of the CloudWatch API requests of a scrape.
+func scrapeCost(plan model.JobPlan) float64 {
+	return float64(plan.ListMetricsRequests+plan.GetMetricStatisticsRequests)*pricePerRequest +
+		float64(plan.GetMetricDataQueries)*pricePerMetricRequested
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+func TestWritePlan(t *testing.T) {
+	plans := []model.JobPlan{
+		{
+			Context:                     &model.ScrapeContext{AccountID: "111111111111", Region: "eu-west-1"},
+			Job:                         "static",
+			Namespace:                   "AWS/EC2",
+			GetMetricStatisticsRequests: 2,
+		},
+		{
+			Context:               &model.ScrapeContext{AccountID: "111111111111", Region: "eu-west-1"},
+			Job:                   "AWS/SQS",
+			Namespace:             "AWS/SQS",
+			Resources:             10,
+			ListMetricsRequests:   3,
+			GetMetricDataQueries:  30,
+			GetMetricDataRequests: 1,
+		},
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, writePlan(&out, plans, 5*time.Minute))
+	require.Equal(t, `JOB      NAMESPACE  ACCOUNT       REGION     RESOURCES  LISTMETRICS  GETMETRICDATA QUERIES  GETMETRICDATA REQUESTS  GETMETRICSTATISTICS  MONTHLY COST
+AWS/SQS  AWS/SQS    111111111111  eu-west-1  10         3            30                     1                       0                    $2.85
+static   AWS/EC2    111111111111  eu-west-1  0          0            0                      0                       2                    $0.17
+TOTAL                                        10         3            30                     1                       2                    $3.02
+
+Monthly cost of 8640 scrapes, one every 5m0s, at the CloudWatch API prices without free tier.
+`, out.String())
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/semaphore"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/cloudwatch"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+// priorityWeights are the shares of the CloudWatch API concurrency of the priority
+// classes when their requests are queued.
+var priorityWeights = map[string]int{
+	model.PriorityHigh:   4,
+	model.PriorityNormal: 2,
+	model.PriorityLow:    1,
+}
+
+// priorityClass is the set of jobs of a priority, scraped on its own so that it
+// isn't delayed by the scrapes of the other classes.
+type priorityClass struct {
+	name    string
+	jobsCfg model.JobsConfig
+	// sem prevents concurrent scrapes of the class
+	sem *semaphore.Weighted
+	// limiter is the share of the class of the CloudWatch API concurrency, nil when not limited
+	limiter cloudwatch.ConcurrencyLimiter
+
+	// mu guards the history of the scrapes of the class
+	mu       sync.Mutex
+	schedule classSchedule
+}
+
+// classSchedule is the history of the scrapes of a priority class.
+type classSchedule struct {
+	Class      string    `json:"class"`
+	Jobs       int       `json:"jobs"`
+	Scraping   bool      `json:"scraping"`
+	LastStart  time.Time `json:"last_start,omitempty"`
+	LastEnd    time.Time `json:"last_end,omitempty"`
+	Scrapes    int       `json:"scrapes"`
+	Overlapped int       `json:"overlapped"`
+}
+
+// started records the start of a scrape of the class.
+func (c *priorityClass) started(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.schedule.Scraping = true
+	c.schedule.LastStart = now
+	c.schedule.Scrapes++
+}
+
+// completed records the end of the running scrape of the class.
+func (c *priorityClass) completed(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.schedule.Scraping = false
+	c.schedule.LastEnd = now
+}
+
+// overlapped records a scrape not started because the previous one was still running.
+func (c *priorityClass) overlapped() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.schedule.Overlapped++
+}
+
+func (c *priorityClass) scheduleSnapshot() classSchedule {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	schedule := c.schedule
+	schedule.Class = c.name
+	schedule.Jobs = jobsCount(c.jobsCfg)
+	return schedule
+}
+
+// priorityClasses splits the jobs into their priority classes, from the highest to the
+// lowest, leaving out the classes without jobs. The classes share the limiter if not nil.
+func priorityClasses(jobsCfg model.JobsConfig, limiter *cloudwatch.PriorityLimiter) []*priorityClass {
+	var classes []*priorityClass
+	for _, name := range []string{model.PriorityHigh, model.PriorityNormal, model.PriorityLow} {
+		classCfg := jobsOfPriority(jobsCfg, name)
+		if jobsCount(classCfg) == 0 && (name != model.PriorityNormal || jobsCount(jobsCfg) > 0) {
+			continue
+		}
+		class := &priorityClass{name: name, jobsCfg: classCfg, sem: semaphore.NewWeighted(1)}
+		if limiter != nil {
+			class.limiter = limiter.Class(name)
+		}
+		classes = append(classes, class)
+	}
+	return classes
+}
+
+// jobsOfPriority returns the jobs of a priority. Only discovery, static and custom
+// namespace jobs have a priority, the other jobs are in the normal class.
+func jobsOfPriority(jobsCfg model.JobsConfig, priority string) model.JobsConfig {
+	classCfg := jobsCfg
+	if priority != model.PriorityNormal {
+		classCfg = model.JobsConfig{
+			StsRegion:    jobsCfg.StsRegion,
+			Hooks:        jobsCfg.Hooks,
+			Retries:      jobsCfg.Retries,
+			Organization: jobsCfg.Organization,
+			Partitions:   jobsCfg.Partitions,
+		}
+	}
+	classCfg.DiscoveryJobs = filterJobs(jobsCfg.DiscoveryJobs, func(job model.DiscoveryJob) bool { return isPriority(job.Priority, priority) })
+	classCfg.StaticJobs = filterJobs(jobsCfg.StaticJobs, func(job model.StaticJob) bool { return isPriority(job.Priority, priority) })
+	classCfg.CustomNamespaceJobs = filterJobs(jobsCfg.CustomNamespaceJobs, func(job model.CustomNamespaceJob) bool { return isPriority(job.Priority, priority) })
+	return classCfg
+}
+
+// isPriority returns whether a job priority is the given one, a job without priority being normal.
+func isPriority(jobPriority string, priority string) bool {
+	return cmp.Or(jobPriority, model.PriorityNormal) == priority
+}
+
+func filterJobs[T any](jobs []T, keep func(T) bool) []T {
+	return slices.DeleteFunc(slices.Clone(jobs), func(job T) bool { return !keep(job) })
+}
+
+func jobsCount(jobsCfg model.JobsConfig) int {
+	return len(jobsCfg.DiscoveryJobs) + len(jobsCfg.StaticJobs) + len(jobsCfg.CustomNamespaceJobs) +
+		len(jobsCfg.CustomNamespaceDiscoveryJobs) + len(jobsCfg.InventoryJobs) + len(jobsCfg.CostExplorerJobs) +
+		len(jobsCfg.ServiceQuotaJobs) + len(jobsCfg.TrustedAdvisorJobs) + len(jobsCfg.LogsInsightsJobs) +
+		len(jobsCfg.ContributorInsightsJobs)
+}
+
+// sharedCachingFactory refreshes the clients for the first of concurrent scrapes, and
+// clears them after the last one, so that a scrape doesn't clear the clients of another.
+type sharedCachingFactory struct {
+	cachingFactory
+	mu    sync.Mutex
+	users int
+}
+
+func (f *sharedCachingFactory) Refresh() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.users == 0 {
+		f.cachingFactory.Refresh()
+	}
+	f.users++
+}
+
+func (f *sharedCachingFactory) Clear() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.users--
+	if f.users == 0 {
+		f.cachingFactory.Clear()
+	}
+}
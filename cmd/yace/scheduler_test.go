@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+func TestPriorityClasses(t *testing.T) {
+	jobsCfg := model.JobsConfig{
+		StsRegion: "eu-west-1",
+		DiscoveryJobs: []model.DiscoveryJob{
+			{Type: "AWS/ApplicationELB", Priority: model.PriorityHigh},
+			{Type: "AWS/EC2", Priority: model.PriorityNormal},
+			{Type: "AWS/S3", Priority: model.PriorityLow},
+		},
+		StaticJobs:    []model.StaticJob{{Name: "static"}},
+		InventoryJobs: []model.InventoryJob{{}},
+	}
+
+	classes := priorityClasses(jobsCfg, nil)
+	require.Len(t, classes, 3)
+
+	require.Equal(t, model.PriorityHigh, classes[0].name)
+	require.Equal(t, "eu-west-1", classes[0].jobsCfg.StsRegion)
+	require.Equal(t, []model.DiscoveryJob{jobsCfg.DiscoveryJobs[0]}, classes[0].jobsCfg.DiscoveryJobs)
+	require.Empty(t, classes[0].jobsCfg.StaticJobs)
+	require.Empty(t, classes[0].jobsCfg.InventoryJobs)
+	require.Nil(t, classes[0].limiter)
+
+	// The jobs without priority and the other kinds of jobs are normal
+	require.Equal(t, model.PriorityNormal, classes[1].name)
+	require.Equal(t, []model.DiscoveryJob{jobsCfg.DiscoveryJobs[1]}, classes[1].jobsCfg.DiscoveryJobs)
+	require.Equal(t, jobsCfg.StaticJobs, classes[1].jobsCfg.StaticJobs)
+	require.Equal(t, jobsCfg.InventoryJobs, classes[1].jobsCfg.InventoryJobs)
+
+	require.Equal(t, model.PriorityLow, classes[2].name)
+	require.Equal(t, []model.DiscoveryJob{jobsCfg.DiscoveryJobs[2]}, classes[2].jobsCfg.DiscoveryJobs)
+
+	// The classes without jobs are left out, but for an empty config
+	classes = priorityClasses(model.JobsConfig{DiscoveryJobs: jobsCfg.DiscoveryJobs[:1]}, nil)
+	require.Len(t, classes, 1)
+	require.Equal(t, model.PriorityHigh, classes[0].name)
+
+	classes = priorityClasses(model.JobsConfig{}, nil)
+	require.Len(t, classes, 1)
+	require.Equal(t, model.PriorityNormal, classes[0].name)
+}
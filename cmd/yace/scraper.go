@@ -1,23 +1,85 @@
 package main
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/json"
+	"io"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/klauspost/compress/zstd"
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
 
 	exporter "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg"
 	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/cloudwatch"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/retries"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/tagging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/dogstatsd"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/emf"
 	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
 	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/promutil"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/trigger"
 )
 
 type scraper struct {
+	// registry holds the metrics of the exporter itself, and metrics the AWS metrics
 	registry     atomic.Pointer[prometheus.Registry]
+	metrics      atomic.Pointer[promutil.PrometheusCollector]
 	featureFlags []string
+
+	// dogstatsd is the optional sink the metrics are also sent to
+	dogstatsd *dogstatsd.Sink
+
+	// degradation scrapes the low priority jobs less often when the CloudWatch API budgets
+	// are nearly spent, nil without budget
+	degradation *degradation
+
+	// triggers receives the events of the optional trigger queue, starting a scrape
+	triggers chan trigger.Event
+
+	// emf holds the metrics of the optional EMF streams, served along the scraped ones
+	emf *emf.Store
+
+	// unmatched holds the resources and metrics of the last scrape which were not associated
+	unmatched atomic.Pointer[[]model.UnmatchedResult]
+
+	// jobHealth tracks the successful scrapes of the jobs, for the health endpoint
+	jobHealth *jobHealth
+
+	// ready is set once the initial scrape of all the jobs completed, or the ready timeout elapsed
+	ready atomic.Bool
+
+	// resourceCache caches the discovered resources between scrapes, nil when disabled
+	resourceCache *tagging.ResourceCache
+
+	// drain stops the scheduling of scrapes when closed, on shutdown. drainMu guards
+	// closing it against starting a scrape, which is tracked by running.
+	drain   chan struct{}
+	drainMu sync.Mutex
+	running sync.WaitGroup
+
+	// jobsCfg is the config of the running jobs, with the roles of the organization accounts
+	jobsCfg atomic.Pointer[model.JobsConfig]
+
+	// classes are the priority classes of the running jobs
+	classes atomic.Pointer[[]*priorityClass]
+
+	// classesMu guards the results of the last scrape of each priority class, merged into metrics and unmatched
+	classesMu        sync.Mutex
+	classesMetrics   map[string][]*promutil.PrometheusMetric
+	classesUnmatched map[string][]model.UnmatchedResult
 }
 
 type cachingFactory interface {
@@ -26,27 +88,223 @@ type cachingFactory interface {
 	Clear()
 }
 
+// resourceCachingFactory caches the discovered resources, so that they
+// are refreshed on their own interval instead of on every scrape.
+type resourceCachingFactory struct {
+	cachingFactory
+	resourceCache *tagging.ResourceCache
+}
+
+func (f resourceCachingFactory) GetTaggingClient(region string, role model.Role, concurrencyLimit int) tagging.Client {
+	return tagging.NewCachingClient(f.cachingFactory.GetTaggingClient(region, role, concurrencyLimit), f.resourceCache, role)
+}
+
 func NewScraper(featureFlags []string) *scraper { //nolint:revive
 	s := &scraper{
-		registry:     atomic.Pointer[prometheus.Registry]{},
-		featureFlags: featureFlags,
+		registry:         atomic.Pointer[prometheus.Registry]{},
+		featureFlags:     featureFlags,
+		classesMetrics:   map[string][]*promutil.PrometheusMetric{},
+		classesUnmatched: map[string][]model.UnmatchedResult{},
+		drain:            make(chan struct{}),
+		jobHealth:        newJobHealth(),
 	}
 	s.registry.Store(prometheus.NewRegistry())
+	s.metrics.Store(promutil.NewPrometheusCollector(nil))
 	return s
 }
 
+// makeHandler serves the metrics in the format negotiated with the Accept header:
+// the text format, the protobuf format, or OpenMetrics if enabled. The AWS metrics
+// are streamed one family at a time, compressed with zstd or gzip if accepted,
+// instead of building the whole response in memory.
 func (s *scraper) makeHandler() func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
-		handler := promhttp.HandlerFor(s.registry.Load(), promhttp.HandlerOpts{
-			DisableCompression: false,
+		families, err := s.registry.Load().Gather()
+		if err != nil {
+			http.Error(w, "An error has occurred while gathering metrics: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		format := expfmt.Negotiate(r.Header)
+		if enableOpenMetrics {
+			format = expfmt.NegotiateIncludingOpenMetrics(r.Header)
+		}
+		w.Header().Set("Content-Type", string(format))
+
+		out := io.Writer(w)
+		switch negotiateEncoding(r.Header) {
+		case encodingZstd:
+			zw, err := zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+			if err != nil {
+				http.Error(w, "An error has occurred while compressing metrics: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Encoding", encodingZstd)
+			defer zw.Close()
+			out = zw
+		case encodingGzip:
+			w.Header().Set("Content-Encoding", encodingGzip)
+			gz := gzip.NewWriter(w)
+			defer gz.Close()
+			out = gz
+		}
+
+		enc := expfmt.NewEncoder(out, format)
+		if format == expfmt.FmtOpenMetrics_1_0_0 || format == expfmt.FmtOpenMetrics_0_0_1 {
+			enc = createdEncoder{Encoder: enc, w: out}
+		}
+		if err := s.encode(enc, families); err != nil {
+			// The response is already partially written, the scraper will fail to parse it
+			logger.Error(err, "Error writing metrics")
+			return
+		}
+		if closer, ok := enc.(expfmt.Closer); ok {
+			if err := closer.Close(); err != nil {
+				logger.Error(err, "Error writing metrics")
+			}
+		}
+	}
+}
+
+// encode writes the families gathered by the registry, then the AWS and EMF
+// metrics merged in families by name. The AWS and EMF families named as a
+// registry family are skipped, as a name must be exposed once.
+func (s *scraper) encode(enc expfmt.Encoder, families []*dto.MetricFamily) error {
+	reserved := make(map[string]bool, len(families))
+	for _, family := range families {
+		reserved[family.GetName()] = true
+		if err := enc.Encode(family); err != nil {
+			return err
+		}
+	}
+	metrics := s.metrics.Load()
+	if s.emf != nil {
+		emfMetrics, err := s.emf.Metrics(labelsSnakeCase, logger)
+		if err != nil {
+			return err
+		}
+		metrics = promutil.NewPrometheusCollector(append(slices.Clip(metrics.Metrics()), emfMetrics...))
+	}
+	return metrics.Encode(enc, reserved, logger)
+}
+
+// createdEncoder writes the `_created` line of each counter with a created
+// timestamp after its sample, as the OpenMetrics encoder of prometheus/common
+// writes none. The other families are written by the wrapped encoder.
+type createdEncoder struct {
+	expfmt.Encoder
+	w io.Writer
+}
+
+func (e createdEncoder) Encode(family *dto.MetricFamily) error {
+	name, ok := strings.CutSuffix(family.GetName(), "_total")
+	if family.GetType() != dto.MetricType_COUNTER || !ok {
+		// Counters without the _total suffix are written with the unknown type
+		return e.Encoder.Encode(family)
+	}
+
+	var samples bytes.Buffer
+	if _, err := expfmt.MetricFamilyToOpenMetrics(&samples, family); err != nil {
+		return err
+	}
+	createdName := name + "_created"
+	metrics := family.GetMetric()
+	i := 0
+	for _, line := range strings.SplitAfter(samples.String(), "\n") {
+		if _, err := io.WriteString(e.w, line); err != nil {
+			return err
+		}
+		// Each counter is written on one line after the HELP and TYPE lines
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		metric := metrics[i]
+		i++
+		if metric.GetCounter().GetCreatedTimestamp() == nil {
+			continue
+		}
+		created := float64(metric.GetCounter().GetCreatedTimestamp().AsTime().UnixNano()) / 1e9
+		var buf bytes.Buffer
+		_, err := expfmt.MetricFamilyToOpenMetrics(&buf, &dto.MetricFamily{
+			Name:   &createdName,
+			Type:   dto.MetricType_GAUGE.Enum(),
+			Metric: []*dto.Metric{{Label: metric.GetLabel(), Gauge: &dto.Gauge{Value: &created}}},
 		})
-		handler.ServeHTTP(w, r)
+		if err != nil {
+			return err
+		}
+		// Skip the TYPE line of the gauge
+		_, sample, _ := strings.Cut(buf.String(), "\n")
+		if _, err := io.WriteString(e.w, sample); err != nil {
+			return err
+		}
 	}
+	return nil
+}
+
+// Close writes the # EOF line of the wrapped OpenMetrics encoder.
+func (e createdEncoder) Close() error {
+	if closer, ok := e.Encoder.(expfmt.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+const (
+	encodingZstd = "zstd"
+	encodingGzip = "gzip"
+)
+
+// negotiateEncoding returns the compression of the response accepted by the
+// Accept-Encoding header, zstd over gzip, or an empty string for none. The
+// encodings with a zero quality are not accepted.
+func negotiateEncoding(header http.Header) string {
+	accepted := map[string]bool{}
+	for _, part := range strings.Split(header.Get("Accept-Encoding"), ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+		accepted[strings.ToLower(strings.TrimSpace(coding))] = q > 0
+	}
+	for _, encoding := range []string{encodingZstd, encodingGzip} {
+		if accepted[encoding] {
+			return encoding
+		}
+	}
+	return ""
 }
 
 func (s *scraper) decoupled(ctx context.Context, logger logging.Logger, jobsCfg model.JobsConfig, cache cachingFactory) {
+	resourceCache := s.resourceCache
+	if resourceCache != nil {
+		cache = resourceCachingFactory{
+			cachingFactory: cache,
+			resourceCache:  resourceCache,
+		}
+	}
+
+	// The priority classes are scraped concurrently, sharing the clients
+	cache = &sharedCachingFactory{cachingFactory: cache}
+	var limiter *cloudwatch.PriorityLimiter
+	if schedulerConcurrency > 0 {
+		limiter = cloudwatch.NewPriorityLimiter(schedulerConcurrency, priorityWeights)
+	}
+	classes := priorityClasses(jobsCfg, limiter)
+	s.classes.Store(&classes)
+
 	logger.Debug("Starting scraping async")
-	s.scrape(ctx, logger, jobsCfg, cache)
+	var initialScrapes []<-chan struct{}
+	for _, class := range classes {
+		initialScrapes = append(initialScrapes, s.startScrape(ctx, logger.With("priority", class.name), class, cache))
+	}
+	for _, done := range initialScrapes {
+		<-done
+	}
+	s.ready.Store(true)
 
 	scrapingDuration := time.Duration(scrapingInterval) * time.Second
 	ticker := time.NewTicker(scrapingDuration)
@@ -56,22 +314,87 @@ func (s *scraper) decoupled(ctx context.Context, logger logging.Logger, jobsCfg
 		select {
 		case <-ctx.Done():
 			return
+		case <-s.drain:
+			return
 		case <-ticker.C:
 			logger.Debug("Starting scraping async")
-			go s.scrape(ctx, logger, jobsCfg, cache)
+			lowPriorityDue := s.degradation == nil || s.degradation.lowPriorityDue(logger, time.Now())
+			for _, class := range classes {
+				if class.name == model.PriorityLow && !lowPriorityDue {
+					logger.Debug("Skipping the scrape of the low priority jobs in degraded mode")
+					continue
+				}
+				s.startScrape(ctx, logger.With("priority", class.name), class, cache)
+			}
+		case event := <-s.triggers:
+			triggered := slices.DeleteFunc(slices.Clone(classes), func(class *priorityClass) bool {
+				return !triggersJobs(event, class.jobsCfg)
+			})
+			if len(triggered) == 0 {
+				continue
+			}
+			if resourceCache != nil {
+				for _, namespace := range event.Namespaces {
+					resourceCache.Invalidate(event.Region, namespace)
+				}
+			}
+			logger.Debug("Starting triggered scraping async", "region", event.Region, "namespaces", strings.Join(event.Namespaces, ","))
+			for _, class := range triggered {
+				s.startScrape(ctx, logger.With("priority", class.name), class, cache)
+			}
+		}
+	}
+}
+
+// startScrape starts a scrape of the class, unless the scrapes are drained. The
+// returned channel is closed once the scrape completed or was not started.
+func (s *scraper) startScrape(ctx context.Context, logger logging.Logger, class *priorityClass, cache cachingFactory) <-chan struct{} {
+	done := make(chan struct{})
+	s.drainMu.Lock()
+	defer s.drainMu.Unlock()
+	select {
+	case <-s.drain:
+		close(done)
+		return done
+	default:
+	}
+
+	s.running.Add(1)
+	go func() {
+		defer close(done)
+		defer s.running.Done()
+		s.scrape(ctx, logger, class, cache)
+	}()
+	return done
+}
+
+// triggersJobs returns whether a discovery job scrapes one
+// of the namespaces of the event in its region.
+func triggersJobs(event trigger.Event, jobsCfg model.JobsConfig) bool {
+	for _, job := range jobsCfg.DiscoveryJobs {
+		svc := config.SupportedServices.GetService(job.Type)
+		if svc == nil || !slices.Contains(event.Namespaces, svc.Namespace) {
+			continue
+		}
+		if event.Region == "" || slices.Contains(job.Regions, event.Region) {
+			return true
 		}
 	}
+	return false
 }
 
-func (s *scraper) scrape(ctx context.Context, logger logging.Logger, jobsCfg model.JobsConfig, cache cachingFactory) {
-	if !sem.TryAcquire(1) {
+func (s *scraper) scrape(ctx context.Context, logger logging.Logger, class *priorityClass, cache cachingFactory) {
+	if !class.sem.TryAcquire(1) {
 		// This shouldn't happen under normal use, users should adjust their configuration when this occurs.
 		// Let them know by logging a warning.
 		logger.Warn("Another scrape is already in process, will not start a new one. " +
 			"Adjust your configuration to ensure the previous scrape completes first.")
+		class.overlapped()
 		return
 	}
-	defer sem.Release(1)
+	defer class.sem.Release(1)
+	class.started(time.Now())
+	defer func() { class.completed(time.Now()) }()
 
 	newRegistry := prometheus.NewRegistry()
 	for _, metric := range exporter.Metrics {
@@ -79,6 +402,11 @@ func (s *scraper) scrape(ctx context.Context, logger logging.Logger, jobsCfg mod
 			logger.Warn("Could not register cloudwatch api metric")
 		}
 	}
+	for _, metric := range infoMetrics {
+		if err := newRegistry.Register(metric); err != nil {
+			logger.Warn("Could not register exporter info metric")
+		}
+	}
 
 	// since we have called refresh, we have loaded all the credentials
 	// into the clients and it is now safe to call concurrently. Defer the
@@ -86,31 +414,160 @@ func (s *scraper) scrape(ctx context.Context, logger logging.Logger, jobsCfg mod
 	cache.Refresh()
 	defer cache.Clear()
 
-	options := []exporter.OptionsFunc{
-		exporter.MetricsPerQuery(metricsPerQuery),
-		exporter.LabelsSnakeCase(labelsSnakeCase),
-		exporter.EnableFeatureFlag(s.featureFlags...),
-		exporter.TaggingAPIConcurrency(tagConcurrency),
+	var unmatched []model.UnmatchedResult
+	options := scrapeOptions(s.featureFlags)
+	options = append(options, exporter.WithHooks(exporter.Hooks{
+		OnUnmatched: func(_ context.Context, classUnmatched []model.UnmatchedResult) {
+			unmatched = classUnmatched
+		},
+		OnJobOutcomes: func(_ context.Context, outcomes []model.JobOutcome) {
+			s.jobHealth.observe(outcomes)
+		},
+	}))
+	if class.limiter != nil {
+		options = append(options, exporter.CloudWatchSharedLimiter(class.limiter))
 	}
-
-	if cloudwatchConcurrency.PerAPILimitEnabled {
-		options = append(options, exporter.CloudWatchPerAPILimitConcurrency(cloudwatchConcurrency.ListMetrics, cloudwatchConcurrency.GetMetricData, cloudwatchConcurrency.GetMetricStatistics))
-	} else {
-		options = append(options, exporter.CloudWatchAPIConcurrency(cloudwatchConcurrency.SingleLimit))
+	if s.dogstatsd != nil {
+		options = append(options, exporter.WithHooks(exporter.Hooks{
+			OnMetricsBuilt: func(_ context.Context, metrics []*promutil.PrometheusMetric) []*promutil.PrometheusMetric {
+				if err := s.dogstatsd.Send(metrics); err != nil {
+					logger.Error(err, "Failed to send metrics to DogStatsD")
+				}
+				return metrics
+			},
+		}))
 	}
 
-	err := exporter.UpdateMetrics(
+	metrics, err := exporter.CollectMetrics(
 		ctx,
 		logger,
-		jobsCfg,
-		newRegistry,
+		class.jobsCfg,
 		cache,
 		options...,
 	)
 	if err != nil {
 		logger.Error(err, "error updating metrics")
+		metrics = promutil.NewPrometheusCollector(nil)
 	}
 
+	s.store(class, metrics.Metrics(), unmatched)
 	s.registry.Store(newRegistry)
 	logger.Debug("Metrics scraped")
 }
+
+// store replaces the metrics and unmatched results of a priority class, and
+// merges them with the last ones of the other classes.
+func (s *scraper) store(class *priorityClass, metrics []*promutil.PrometheusMetric, unmatched []model.UnmatchedResult) {
+	s.classesMu.Lock()
+	defer s.classesMu.Unlock()
+	s.classesMetrics[class.name] = metrics
+	s.classesUnmatched[class.name] = unmatched
+
+	var allMetrics []*promutil.PrometheusMetric
+	var allUnmatched []model.UnmatchedResult
+	for _, name := range []string{model.PriorityHigh, model.PriorityNormal, model.PriorityLow} {
+		allMetrics = append(allMetrics, s.classesMetrics[name]...)
+		allUnmatched = append(allUnmatched, s.classesUnmatched[name]...)
+	}
+	// Jobs of different classes may export the same info metrics
+	allMetrics = promutil.EnsureLabelConsistencyAndRemoveDuplicates(allMetrics, nil)
+	s.metrics.Store(promutil.NewPrometheusCollector(allMetrics))
+	s.unmatched.Store(&allUnmatched)
+}
+
+// makeReadyHandler serves 200 once the exporter is ready, i.e. all the jobs were scraped
+// once and the metrics it serves are complete, and 503 before.
+func (s *scraper) makeReadyHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		if !s.ready.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("not ready"))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ready"))
+	}
+}
+
+// unmatchedJob is the JSON view of the unmatched resources and metrics of a discovery job.
+type unmatchedJob struct {
+	Namespace string            `json:"namespace"`
+	Region    string            `json:"region"`
+	AccountID string            `json:"account_id"`
+	Resources []string          `json:"resources"`
+	Metrics   []unmatchedMetric `json:"metrics"`
+}
+
+type unmatchedMetric struct {
+	Name       string            `json:"name"`
+	Dimensions map[string]string `json:"dimensions"`
+}
+
+// makeUnmatchedHandler serves the resources of the last scrape which matched no
+// metric, and the metrics which matched no resource, per discovery job.
+func (s *scraper) makeUnmatchedHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		jobs := []unmatchedJob{}
+		if unmatched := s.unmatched.Load(); unmatched != nil {
+			for _, result := range *unmatched {
+				job := unmatchedJob{
+					Namespace: result.Namespace,
+					Region:    result.Context.Region,
+					AccountID: result.Context.AccountID,
+					Resources: result.Resources,
+					Metrics:   make([]unmatchedMetric, 0, len(result.Metrics)),
+				}
+				if job.Resources == nil {
+					job.Resources = []string{}
+				}
+				for _, metric := range result.Metrics {
+					dimensions := make(map[string]string, len(metric.Dimensions))
+					for _, dimension := range metric.Dimensions {
+						dimensions[dimension.Name] = dimension.Value
+					}
+					job.Metrics = append(job.Metrics, unmatchedMetric{Name: metric.MetricName, Dimensions: dimensions})
+				}
+				jobs = append(jobs, job)
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(jobs); err != nil {
+			logger.Error(err, "Error writing unmatched resources")
+		}
+	}
+}
+
+// makeThrottlesHandler serves the retries of the AWS API requests of the last
+// hour by job, the jobs with the most retries first.
+func makeThrottlesHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(retries.Recent.Top(time.Now())); err != nil {
+			logger.Error(err, "Error writing the retries")
+		}
+	}
+}
+
+// scrapeOptions returns the exporter options of the command line flags.
+func scrapeOptions(featureFlags []string) []exporter.OptionsFunc {
+	options := []exporter.OptionsFunc{
+		exporter.MetricsPerQuery(metricsPerQuery),
+		exporter.LabelsSnakeCase(labelsSnakeCase),
+		exporter.EnableFeatureFlag(featureFlags...),
+		exporter.TaggingAPIConcurrency(tagConcurrency),
+	}
+
+	if cloudwatchConcurrency.PerAPILimitEnabled {
+		options = append(options, exporter.CloudWatchPerAPILimitConcurrency(cloudwatchConcurrency.ListMetrics, cloudwatchConcurrency.GetMetricData, cloudwatchConcurrency.GetMetricStatistics))
+	} else {
+		options = append(options, exporter.CloudWatchAPIConcurrency(cloudwatchConcurrency.SingleLimit))
+	}
+	if requestBudget != nil {
+		options = append(options, exporter.CloudWatchRequestBudget(requestBudget))
+	}
+	if len(exporterLabels) > 0 {
+		options = append(options, withExporterLabels(exporterLabels))
+	}
+	return options
+}
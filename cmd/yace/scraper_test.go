@@ -0,0 +1,222 @@
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/klauspost/compress/zstd"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/emf"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/promutil"
+)
+
+func TestScraperHandlerContentNegotiation(t *testing.T) {
+	s := NewScraper(nil)
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "yace_test_total", Help: "Test counter"})
+	counter.Inc()
+	s.registry.Load().MustRegister(counter)
+
+	for _, tc := range []struct {
+		name                string
+		openMetrics         bool
+		accept              string
+		expectedContentType string
+	}{
+		{
+			name:                "text by default",
+			expectedContentType: "text/plain; version=0.0.4; charset=utf-8",
+		},
+		{
+			name:                "protobuf",
+			accept:              "application/vnd.google.protobuf;proto=io.prometheus.client.MetricFamily;encoding=delimited",
+			expectedContentType: "application/vnd.google.protobuf; proto=io.prometheus.client.MetricFamily; encoding=delimited",
+		},
+		{
+			name:                "openmetrics when disabled",
+			accept:              "application/openmetrics-text;version=1.0.0",
+			expectedContentType: "text/plain; version=0.0.4; charset=utf-8",
+		},
+		{
+			name:                "openmetrics",
+			openMetrics:         true,
+			accept:              "application/openmetrics-text;version=1.0.0",
+			expectedContentType: "application/openmetrics-text; version=1.0.0; charset=utf-8",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			enableOpenMetrics = tc.openMetrics
+			t.Cleanup(func() { enableOpenMetrics = false })
+
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			rec := httptest.NewRecorder()
+			s.makeHandler()(rec, req)
+
+			require.Equal(t, http.StatusOK, rec.Code)
+			require.Equal(t, tc.expectedContentType, rec.Header().Get("Content-Type"))
+		})
+	}
+}
+
+func TestScraperHandlerProtobufCreatedTimestamps(t *testing.T) {
+	s := NewScraper(nil)
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "yace_test_total", Help: "Test counter"})
+	s.registry.Load().MustRegister(counter)
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Accept", "application/vnd.google.protobuf;proto=io.prometheus.client.MetricFamily;encoding=delimited")
+	rec := httptest.NewRecorder()
+	s.makeHandler()(rec, req)
+
+	family := &dto.MetricFamily{}
+	require.NoError(t, expfmt.NewDecoder(rec.Body, expfmt.FmtProtoDelim).Decode(family))
+	require.Equal(t, "yace_test_total", family.GetName())
+	require.NotNil(t, family.GetMetric()[0].GetCounter().GetCreatedTimestamp())
+}
+
+func TestScraperHandlerOpenMetricsCreatedTimestamps(t *testing.T) {
+	enableOpenMetrics = true
+	t.Cleanup(func() { enableOpenMetrics = false })
+
+	s := NewScraper(nil)
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "yace_test_total", Help: "Test counter"}, []string{"region"})
+	counter.WithLabelValues("eu-west-1").Add(2)
+	counter.WithLabelValues("us-east-1").Inc()
+	s.registry.Load().MustRegister(counter)
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Accept", "application/openmetrics-text; version=1.0.0")
+	rec := httptest.NewRecorder()
+	s.makeHandler()(rec, req)
+
+	lines := strings.Split(rec.Body.String(), "\n")
+	require.Len(t, lines, 8)
+	require.Equal(t, "# HELP yace_test Test counter", lines[0])
+	require.Equal(t, "# TYPE yace_test counter", lines[1])
+	require.Equal(t, `yace_test_total{region="eu-west-1"} 2.0`, lines[2])
+	require.Regexp(t, `^yace_test_created\{region="eu-west-1"\} \d+\.\d+(e\+\d+)?$`, lines[3])
+	require.Equal(t, `yace_test_total{region="us-east-1"} 1.0`, lines[4])
+	require.Regexp(t, `^yace_test_created\{region="us-east-1"\} `, lines[5])
+	require.Equal(t, "# EOF", lines[6])
+}
+
+func TestScraperHandlerStreamsMetrics(t *testing.T) {
+	s := NewScraper(nil)
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "yace_test_total", Help: "Test counter"})
+	s.registry.Load().MustRegister(counter)
+	s.metrics.Store(promutil.NewPrometheusCollector([]*promutil.PrometheusMetric{{
+		Name:   aws.String("aws_sqs_number_of_messages_sent_sum"),
+		Labels: map[string]string{"name": "orders"},
+		Value:  aws.Float64(1),
+	}}))
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	s.makeHandler()(rec, req)
+
+	require.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+	gz, err := gzip.NewReader(rec.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	require.Equal(t, `# HELP yace_test_total Test counter
+# TYPE yace_test_total counter
+yace_test_total 0
+# HELP aws_sqs_number_of_messages_sent_sum Help is not implemented yet.
+# TYPE aws_sqs_number_of_messages_sent_sum gauge
+aws_sqs_number_of_messages_sent_sum{name="orders"} 1
+`, string(body))
+}
+
+func TestScraperHandlerZstd(t *testing.T) {
+	s := NewScraper(nil)
+	s.metrics.Store(promutil.NewPrometheusCollector([]*promutil.PrometheusMetric{{
+		Name:   aws.String("aws_sqs_number_of_messages_sent_sum"),
+		Labels: map[string]string{"name": "orders"},
+		Value:  aws.Float64(1),
+	}}))
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Accept-Encoding", "gzip, zstd")
+	rec := httptest.NewRecorder()
+	s.makeHandler()(rec, req)
+
+	require.Equal(t, "zstd", rec.Header().Get("Content-Encoding"))
+	zr, err := zstd.NewReader(rec.Body)
+	require.NoError(t, err)
+	defer zr.Close()
+	body, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	require.Contains(t, string(body), `aws_sqs_number_of_messages_sent_sum{name="orders"} 1`)
+}
+
+func TestScraperHandlerMergesEMFMetrics(t *testing.T) {
+	s := NewScraper(nil)
+	s.metrics.Store(promutil.NewPrometheusCollector([]*promutil.PrometheusMetric{{
+		Name:   aws.String("aws_sqs_number_of_messages_sent_sum"),
+		Labels: map[string]string{"name": "orders"},
+		Value:  aws.Float64(1),
+	}}))
+	s.emf = emf.NewStore(emf.DefaultSeriesTTL)
+	s.emf.Add("eu-west-1", []emf.Sample{{
+		Namespace:  "AWS/SQS",
+		Metric:     "NumberOfMessagesSent",
+		Dimensions: []*model.Dimension{{Name: "QueueName", Value: "payments"}},
+		Values:     []float64{2},
+		Timestamp:  time.Now(),
+	}})
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	rec := httptest.NewRecorder()
+	s.makeHandler()(rec, req)
+
+	body := rec.Body.String()
+	require.Equal(t, 1, strings.Count(body, "# TYPE aws_sqs_number_of_messages_sent_sum gauge\n"))
+	require.Contains(t, body, `aws_sqs_number_of_messages_sent_sum{name="orders"} 1`)
+	require.Contains(t, body, `dimension_QueueName="payments"`)
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	for acceptEncoding, expected := range map[string]string{
+		"":                       "",
+		"identity":               "",
+		"gzip":                   "gzip",
+		"gzip, deflate, br":      "gzip",
+		"zstd":                   "zstd",
+		"gzip;q=1.0, zstd;q=0.5": "zstd",
+		"gzip, zstd;q=0":         "gzip",
+		"GZIP;q=0.8":             "gzip",
+		"gzip;q=0, zstd;q=0.000": "",
+	} {
+		header := http.Header{}
+		header.Set("Accept-Encoding", acceptEncoding)
+		require.Equal(t, expected, negotiateEncoding(header), acceptEncoding)
+	}
+}
+
+func TestScraperReadyHandler(t *testing.T) {
+	s := NewScraper(nil)
+
+	rec := httptest.NewRecorder()
+	s.makeReadyHandler()(rec, httptest.NewRequest(http.MethodGet, "/-/ready", nil))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	s.ready.Store(true)
+	rec = httptest.NewRecorder()
+	s.makeReadyHandler()(rec, httptest.NewRequest(http.MethodGet, "/-/ready", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+}
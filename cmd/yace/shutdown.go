@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/tagging"
+)
+
+// drainScrapes stops the scheduling of scrapes, and waits up to timeout for the
+// running ones to complete. It returns whether they did.
+func (s *scraper) drainScrapes(timeout time.Duration) bool {
+	s.drainMu.Lock()
+	select {
+	case <-s.drain:
+	default:
+		close(s.drain)
+	}
+	s.drainMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// makeQuitHandler requests the shutdown of the exporter on POST requests.
+func makeQuitHandler(quit chan<- struct{}) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		select {
+		case quit <- struct{}{}:
+		default:
+			// A shutdown is already requested
+		}
+		_, _ = w.Write([]byte("Requesting termination... Goodbye!"))
+	}
+}
+
+// loadResourceCache loads the resources saved to path into the cache. A missing
+// file is not an error, e.g. on the first start.
+func loadResourceCache(cache *tagging.ResourceCache, path string) error {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return cache.Load(f)
+}
+
+// saveResourceCache saves the resources of the cache to path, replacing
+// the file only once written.
+func saveResourceCache(cache *tagging.ResourceCache, path string) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err := cache.Save(f); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", f.Name(), err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
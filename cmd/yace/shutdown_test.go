@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/tagging"
+)
+
+func TestDrainScrapes(t *testing.T) {
+	s := NewScraper(nil)
+	s.running.Add(1)
+	require.False(t, s.drainScrapes(10*time.Millisecond), "a running scrape should not be drained")
+
+	// No scrape starts once drained
+	select {
+	case <-s.startScrape(context.Background(), nil, nil, nil):
+	case <-time.After(time.Second):
+		t.Fatal("a scrape was started after the drain")
+	}
+
+	s.running.Done()
+	require.True(t, s.drainScrapes(time.Second))
+}
+
+func TestQuitHandler(t *testing.T) {
+	quit := make(chan struct{}, 1)
+	handler := makeQuitHandler(quit)
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/-/quit", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	require.Empty(t, quit)
+
+	for i := 0; i < 2; i++ {
+		rec = httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodPost, "/-/quit", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+	}
+	require.Len(t, quit, 1)
+}
+
+func TestSaveLoadResourceCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "resources.json")
+	cache := tagging.NewResourceCache(time.Hour, 0)
+	require.NoError(t, loadResourceCache(cache, path), "a missing file should not be an error")
+	require.NoError(t, saveResourceCache(cache, path))
+	require.NoError(t, loadResourceCache(tagging.NewResourceCache(time.Hour, 0), path))
+	require.Error(t, saveResourceCache(cache, filepath.Join(t.TempDir(), "missing", "resources.json")))
+}
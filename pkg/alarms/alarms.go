@@ -0,0 +1,212 @@
+// Package alarms bridges CloudWatch alarms to a Prometheus Alertmanager, sending the
+// state changes of the alarms notified through SNS as alerts, for the alarms and the
+// Prometheus alerts to share one alert pipeline.
+package alarms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/arn"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/promutil"
+)
+
+const (
+	// ResendInterval is how often the alerts of the alarms in the ALARM state are sent
+	// again, for Alertmanager not to resolve them after its resolve_timeout.
+	ResendInterval = time.Minute
+
+	stateAlarm = "ALARM"
+	// stateChangeTimeFormat is the format of the StateChangeTime of the notifications.
+	stateChangeTimeFormat = "2006-01-02T15:04:05.000-0700"
+)
+
+// Alarm is the notification of a state change of a CloudWatch alarm, the message
+// published to the SNS topics of the actions of the alarm.
+type Alarm struct {
+	AlarmName        string `json:"AlarmName"`
+	AlarmDescription string `json:"AlarmDescription"`
+	AlarmArn         string `json:"AlarmArn"`
+	AWSAccountID     string `json:"AWSAccountId"`
+	NewStateValue    string `json:"NewStateValue"`
+	NewStateReason   string `json:"NewStateReason"`
+	StateChangeTime  string `json:"StateChangeTime"`
+	// Trigger is the metric of a metric alarm, missing for the composite alarms
+	Trigger *Trigger `json:"Trigger"`
+}
+
+// Trigger is the metric of a metric alarm.
+type Trigger struct {
+	MetricName string      `json:"MetricName"`
+	Namespace  string      `json:"Namespace"`
+	Dimensions []Dimension `json:"Dimensions"`
+}
+
+// Dimension is a dimension of the metric of a metric alarm.
+type Dimension struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// alert is an alert of the Alertmanager API v2.
+type alert struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       *time.Time        `json:"endsAt,omitempty"`
+	GeneratorURL string            `json:"generatorURL,omitempty"`
+}
+
+// Bridge sends the state changes of the alarms to Alertmanager.
+type Bridge struct {
+	logger       logging.Logger
+	url          string
+	labelMapping map[string]string
+	client       *http.Client
+
+	mu sync.Mutex
+	// firing are the alerts of the alarms in the ALARM state, by alarm ARN
+	firing map[string]alert
+}
+
+// NewBridge returns a Bridge to the Alertmanager of the URL. The labels of the alerts
+// are renamed by the label mapping, the labels mapped to an empty name being dropped.
+func NewBridge(logger logging.Logger, alertmanagerURL string, labelMapping map[string]string) *Bridge {
+	return &Bridge{
+		logger:       logger,
+		url:          strings.TrimSuffix(alertmanagerURL, "/") + "/api/v2/alerts",
+		labelMapping: labelMapping,
+		client:       &http.Client{Timeout: 10 * time.Second},
+		firing:       make(map[string]alert),
+	}
+}
+
+// Notify sends the alert of the state change of the alarm to Alertmanager: firing in
+// the ALARM state, resolved in the OK and INSUFFICIENT_DATA states. A state change
+// older than the one of the firing alert, delivered out of order by SNS, is ignored.
+func (b *Bridge) Notify(ctx context.Context, alarm Alarm) error {
+	changed, err := time.Parse(stateChangeTimeFormat, alarm.StateChangeTime)
+	if err != nil {
+		return fmt.Errorf("invalid StateChangeTime of alarm %s: %w", alarm.AlarmName, err)
+	}
+	a := b.toAlert(alarm)
+
+	b.mu.Lock()
+	previous, firing := b.firing[alarm.AlarmArn]
+	if firing && changed.Before(previous.StartsAt) {
+		b.mu.Unlock()
+		return nil
+	}
+	if alarm.NewStateValue == stateAlarm {
+		a.StartsAt = changed
+		b.firing[alarm.AlarmArn] = a
+	} else {
+		if !firing {
+			b.mu.Unlock()
+			return nil
+		}
+		a.StartsAt, a.EndsAt = previous.StartsAt, &changed
+		delete(b.firing, alarm.AlarmArn)
+	}
+	b.mu.Unlock()
+
+	return b.send(ctx, []alert{a})
+}
+
+// Run sends the firing alerts again every ResendInterval until the context is done.
+func (b *Bridge) Run(ctx context.Context) {
+	ticker := time.NewTicker(ResendInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		b.mu.Lock()
+		alerts := make([]alert, 0, len(b.firing))
+		for _, a := range b.firing {
+			alerts = append(alerts, a)
+		}
+		b.mu.Unlock()
+		if len(alerts) == 0 {
+			continue
+		}
+		if err := b.send(ctx, alerts); err != nil {
+			b.logger.Error(err, "Failed to send the firing alarms to Alertmanager", "alarms", len(alerts))
+		}
+	}
+}
+
+// toAlert returns the alert of the alarm, labeled with the name, account and region of
+// the alarm, and the namespace, name and dimensions of the metric of a metric alarm.
+func (b *Bridge) toAlert(alarm Alarm) alert {
+	labels := map[string]string{
+		"alertname":  alarm.AlarmName,
+		"account_id": alarm.AWSAccountID,
+	}
+	var region string
+	if parsed, err := arn.Parse(alarm.AlarmArn); err == nil {
+		region = parsed.Region
+		labels["region"] = region
+	}
+	if alarm.Trigger != nil {
+		labels["namespace"] = alarm.Trigger.Namespace
+		labels["metric_name"] = alarm.Trigger.MetricName
+		for _, dimension := range alarm.Trigger.Dimensions {
+			if ok, name := promutil.PromStringTag(dimension.Name, false); ok {
+				labels["dimension_"+name] = dimension.Value
+			}
+		}
+	}
+	mapped := make(map[string]string, len(labels))
+	for label, value := range labels {
+		if name, ok := b.labelMapping[label]; ok {
+			label = name
+		}
+		if label != "" {
+			mapped[label] = value
+		}
+	}
+
+	annotations := map[string]string{"summary": alarm.NewStateReason}
+	if alarm.AlarmDescription != "" {
+		annotations["description"] = alarm.AlarmDescription
+	}
+	return alert{
+		Labels:       mapped,
+		Annotations:  annotations,
+		GeneratorURL: fmt.Sprintf("https://console.aws.amazon.com/cloudwatch/home?region=%s#alarmsV2:alarm/%s", region, url.PathEscape(alarm.AlarmName)),
+	}
+}
+
+func (b *Bridge) send(ctx context.Context, alerts []alert) error {
+	body, err := json.Marshal(alerts)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s from Alertmanager", resp.Status)
+	}
+	return nil
+}
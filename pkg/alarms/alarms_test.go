@@ -0,0 +1,103 @@
+package alarms
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+)
+
+const alarmArn = "arn:aws:cloudwatch:eu-west-1:123456789012:alarm:queue-backlog"
+
+// alertmanager records the alerts posted to its API.
+type alertmanager struct {
+	mu     sync.Mutex
+	alerts [][]alert
+}
+
+func (a *alertmanager) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/api/v2/alerts" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	var alerts []alert
+	if err := json.NewDecoder(r.Body).Decode(&alerts); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	a.mu.Lock()
+	a.alerts = append(a.alerts, alerts)
+	a.mu.Unlock()
+}
+
+func queueAlarm(state string, changed string) Alarm {
+	return Alarm{
+		AlarmName:       "queue-backlog",
+		AlarmArn:        alarmArn,
+		AWSAccountID:    "123456789012",
+		NewStateValue:   state,
+		NewStateReason:  "Threshold Crossed: 1 datapoint [120.0] was greater than the threshold (100.0).",
+		StateChangeTime: changed,
+		Trigger: &Trigger{
+			MetricName: "ApproximateNumberOfMessagesVisible",
+			Namespace:  "AWS/SQS",
+			Dimensions: []Dimension{{Name: "QueueName", Value: "orders"}},
+		},
+	}
+}
+
+func TestBridgeNotify(t *testing.T) {
+	am := &alertmanager{}
+	srv := httptest.NewServer(am)
+	defer srv.Close()
+
+	bridge := NewBridge(logging.NewNopLogger(), srv.URL+"/", map[string]string{"dimension_QueueName": "queue", "account_id": ""})
+	ctx := context.Background()
+
+	// An OK state change of an alarm which is not firing is not sent
+	require.NoError(t, bridge.Notify(ctx, queueAlarm("OK", "2024-01-01T09:00:00.000+0000")))
+	require.NoError(t, bridge.Notify(ctx, queueAlarm("ALARM", "2024-01-01T10:00:00.000+0000")))
+	// A state change delivered out of order is ignored
+	require.NoError(t, bridge.Notify(ctx, queueAlarm("OK", "2024-01-01T09:30:00.000+0000")))
+	require.NoError(t, bridge.Notify(ctx, queueAlarm("OK", "2024-01-01T10:05:00.000+0000")))
+	require.Error(t, bridge.Notify(ctx, queueAlarm("ALARM", "yesterday")))
+
+	firing := alert{
+		Labels: map[string]string{
+			"alertname":   "queue-backlog",
+			"region":      "eu-west-1",
+			"namespace":   "AWS/SQS",
+			"metric_name": "ApproximateNumberOfMessagesVisible",
+			"queue":       "orders",
+		},
+		Annotations:  map[string]string{"summary": "Threshold Crossed: 1 datapoint [120.0] was greater than the threshold (100.0)."},
+		StartsAt:     time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+		GeneratorURL: "https://console.aws.amazon.com/cloudwatch/home?region=eu-west-1#alarmsV2:alarm/queue-backlog",
+	}
+	resolved := firing
+	endsAt := time.Date(2024, 1, 1, 10, 5, 0, 0, time.UTC)
+	resolved.EndsAt = &endsAt
+
+	require.Len(t, am.alerts, 2)
+	for i, expected := range []alert{firing, resolved} {
+		require.Len(t, am.alerts[i], 1)
+		actual := am.alerts[i][0]
+		require.Equal(t, expected.Labels, actual.Labels)
+		require.Equal(t, expected.Annotations, actual.Annotations)
+		require.Equal(t, expected.GeneratorURL, actual.GeneratorURL)
+		require.True(t, expected.StartsAt.Equal(actual.StartsAt))
+		if expected.EndsAt == nil {
+			require.Nil(t, actual.EndsAt)
+		} else {
+			require.True(t, expected.EndsAt.Equal(*actual.EndsAt))
+		}
+	}
+	require.Empty(t, bridge.firing)
+}
@@ -0,0 +1,27 @@
+package alarms
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/sns"
+)
+
+// NewSNSHandler returns the HTTP endpoint subscribed to the SNS topics of the ARNs,
+// sending the alarms they notify to the bridge.
+func NewSNSHandler(logger logging.Logger, topicARNs []string, bridge *Bridge) http.Handler {
+	return sns.NewHandler(logger, topicARNs, func(ctx context.Context, msg sns.Message) error {
+		var alarm Alarm
+		if err := json.Unmarshal([]byte(msg.Message), &alarm); err != nil || alarm.AlarmArn == "" {
+			logger.Warn("Skipping SNS notification which is not a CloudWatch alarm", "topic_arn", msg.TopicArn, "message_id", msg.MessageID)
+			return nil
+		}
+		if err := bridge.Notify(ctx, alarm); err != nil {
+			return fmt.Errorf("failed to send the alarm %s to Alertmanager: %w", alarm.AlarmName, err)
+		}
+		return nil
+	})
+}
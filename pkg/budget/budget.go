@@ -0,0 +1,241 @@
+// Package budget shares the CloudWatch API request rate quotas of the accounts
+// between the exporters of a fleet, e.g. when the jobs are split between several
+// exporters scraping the same accounts, so that together they stay under the
+// quotas instead of each of them assuming it has the whole quota.
+package budget
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/arn"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/account"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/cloudwatch"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/promutil"
+)
+
+// window is the duration the requests are counted over.
+const window = time.Second
+
+// takeTimeout bounds the calls to the store, so that a slow store doesn't stall the
+// requests waiting for the budget. A request whose call times out is let through.
+const takeTimeout = 500 * time.Millisecond
+
+// DefaultLimits are the default CloudWatch API quotas of an account and region.
+var DefaultLimits = Limits{
+	ListMetrics:         25,
+	GetMetricData:       50,
+	GetMetricStatistics: 400,
+}
+
+// Limits are the requests per second to the CloudWatch API of an account and region
+// shared by the exporters. A limit of 0 doesn't limit the requests of the API.
+type Limits struct {
+	ListMetrics         int
+	GetMetricData       int
+	GetMetricStatistics int
+}
+
+func (l Limits) of(op string) int {
+	switch op {
+	case cloudwatch.ListMetricsCall:
+		return l.ListMetrics
+	case cloudwatch.GetMetricDataCall:
+		return l.GetMetricData
+	case cloudwatch.GetMetricStatisticsCall:
+		return l.GetMetricStatistics
+	}
+	return 0
+}
+
+// Store counts the requests taken from the budgets of the fleet.
+type Store interface {
+	// Take takes a request from the budget identified by key in the window starting at start,
+	// and returns false, without taking it, when limit requests were already taken.
+	Take(ctx context.Context, key string, start time.Time, limit int) (bool, error)
+}
+
+// Budget is a cloudwatch.RequestBudget shared through a Store. When the budget of a
+// window is spent, the requests wait for the next window. When the store fails or
+// times out, the requests are let through rather than failing the scrape.
+type Budget struct {
+	logger  logging.Logger
+	store   Store
+	limits  Limits
+	now     func() time.Time
+	sleep   func(time.Duration)
+	timeout time.Duration
+
+	mu    sync.Mutex
+	usage map[usageKey]*Usage
+	// accounts are the accounts of the current credentials, by region
+	accounts map[string]string
+}
+
+type usageKey struct {
+	key string
+	api string
+}
+
+// Usage is the consumption of a budget by the exporter since it started.
+type Usage struct {
+	// Budget identifies the budget, as account/region
+	Budget string `json:"budget"`
+	API    string `json:"api"`
+	// Requests is the number of requests taken from the budget
+	Requests int64 `json:"requests"`
+	// Delayed is the number of requests which waited for a next window
+	Delayed int64 `json:"delayed"`
+	// Errors is the number of requests let through because the store failed
+	Errors      int64     `json:"errors"`
+	LastError   string    `json:"last_error,omitempty"`
+	LastErrorAt time.Time `json:"last_error_at,omitempty"`
+}
+
+// New returns a Budget of the limits shared through the store.
+func New(logger logging.Logger, store Store, limits Limits) *Budget {
+	return &Budget{
+		logger:   logger,
+		store:    store,
+		limits:   limits,
+		now:      time.Now,
+		sleep:    time.Sleep,
+		timeout:  takeTimeout,
+		usage:    map[usageKey]*Usage{},
+		accounts: map[string]string{},
+	}
+}
+
+// Usage returns the consumption of the budgets by the exporter, sorted by budget and API.
+func (b *Budget) Usage() []Usage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	usage := make([]Usage, 0, len(b.usage))
+	for _, u := range b.usage {
+		usage = append(usage, *u)
+	}
+	slices.SortFunc(usage, func(a, b Usage) int {
+		return cmp.Or(cmp.Compare(a.Budget, b.Budget), cmp.Compare(a.API, b.API))
+	})
+	return usage
+}
+
+// record updates the usage of the budget of key by a request to the API.
+func (b *Budget) record(key string, api string, update func(*Usage)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	u, ok := b.usage[usageKey{key: key, api: api}]
+	if !ok {
+		u = &Usage{Budget: key, API: api}
+		b.usage[usageKey{key: key, api: api}] = u
+	}
+	update(u)
+}
+
+// Limiter returns the limiter of the requests to the region with the role, counted in the
+// budget of the account of the role. Without role, the account of the current credentials
+// is resolved with the account client on the first request, once per region.
+func (b *Budget) Limiter(region string, role model.Role, accountClient account.Client) cloudwatch.RequestLimiter {
+	l := &limiter{budget: b, region: region, accountClient: accountClient}
+	if parsed, err := arn.Parse(role.RoleArn); err == nil {
+		l.account = parsed.AccountID
+	}
+	return l
+}
+
+// callerAccount returns the account of the current credentials in the region.
+func (b *Budget) callerAccount(ctx context.Context, region string, accountClient account.Client) (string, error) {
+	b.mu.Lock()
+	accountID, ok := b.accounts[region]
+	b.mu.Unlock()
+	if ok {
+		return accountID, nil
+	}
+	if accountClient == nil {
+		return "", errors.New("no account client")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	accountID, err := accountClient.GetAccount(ctx)
+	if err != nil {
+		return "", err
+	}
+	b.mu.Lock()
+	b.accounts[region] = accountID
+	b.mu.Unlock()
+	return accountID, nil
+}
+
+type limiter struct {
+	budget        *Budget
+	region        string
+	account       string
+	accountClient account.Client
+}
+
+// key identifies the budget of the requests, as account/region. When the account of the
+// current credentials can't be resolved, the requests are counted in a "default" budget.
+func (l *limiter) key(ctx context.Context) string {
+	accountID := l.account
+	if accountID == "" {
+		var err error
+		accountID, err = l.budget.callerAccount(ctx, l.region, l.accountClient)
+		if err != nil {
+			l.budget.logger.Debug("Failed to get the account of the budget", "region", l.region, "err", err)
+			accountID = "default"
+		}
+	}
+	return fmt.Sprintf("%s/%s", accountID, l.region)
+}
+
+// Wait takes a request to op from the budget, waiting for the next windows while it is spent.
+func (l *limiter) Wait(ctx context.Context, op string) {
+	b := l.budget
+	limit := b.limits.of(op)
+	if limit <= 0 {
+		return
+	}
+	key := l.key(ctx)
+
+	delayed := false
+	for {
+		now := b.now()
+		start := now.Truncate(window)
+		ok, err := l.take(ctx, key+"/"+op, start, limit)
+		if err != nil {
+			promutil.BudgetErrorsCounter.Inc()
+			b.logger.Debug("Failed to take a request from the budget, letting it through", "key", key, "api", op, "err", err)
+			b.record(key, op, func(u *Usage) {
+				u.Errors++
+				u.LastError = err.Error()
+				u.LastErrorAt = now
+			})
+			return
+		}
+		if ok {
+			b.record(key, op, func(u *Usage) { u.Requests++ })
+			return
+		}
+		if !delayed {
+			promutil.BudgetDelayedCounter.WithLabelValues(op).Inc()
+			b.record(key, op, func(u *Usage) { u.Delayed++ })
+			delayed = true
+		}
+		b.sleep(start.Add(window).Sub(now))
+	}
+}
+
+func (l *limiter) take(ctx context.Context, key string, start time.Time, limit int) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, l.budget.timeout)
+	defer cancel()
+	return l.budget.store.Take(ctx, key, start, limit)
+}
@@ -0,0 +1,152 @@
+package budget
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/cloudwatch"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+type memoryStore struct {
+	taken map[string]int
+	err   error
+}
+
+func (s *memoryStore) Take(_ context.Context, key string, start time.Time, limit int) (bool, error) {
+	if s.err != nil {
+		return false, s.err
+	}
+	key += "/" + start.Format(time.RFC3339)
+	if s.taken[key] >= limit {
+		return false, nil
+	}
+	s.taken[key]++
+	return true, nil
+}
+
+// accountClient returns the account of the current credentials, counting the calls.
+type accountClient struct {
+	accountID string
+	err       error
+	calls     int
+}
+
+func (c *accountClient) GetAccount(_ context.Context) (string, error) {
+	c.calls++
+	return c.accountID, c.err
+}
+
+func TestBudget(t *testing.T) {
+	store := &memoryStore{taken: map[string]int{}}
+	b := New(logging.NewNopLogger(), store, Limits{GetMetricData: 2})
+	now := time.Date(2024, 1, 1, 0, 0, 0, 250_000_000, time.UTC)
+	var slept []time.Duration
+	b.now = func() time.Time { return now }
+	b.sleep = func(d time.Duration) {
+		slept = append(slept, d)
+		now = now.Add(d)
+	}
+
+	limiter := b.Limiter("eu-west-1", model.Role{RoleArn: "arn:aws:iam::123456789012:role/yace"}, nil)
+	limiter.Wait(context.Background(), cloudwatch.GetMetricDataCall)
+	limiter.Wait(context.Background(), cloudwatch.GetMetricDataCall)
+	require.Empty(t, slept)
+
+	// The budget of the window is spent, the request waits for the next one
+	limiter.Wait(context.Background(), cloudwatch.GetMetricDataCall)
+	require.Equal(t, []time.Duration{750 * time.Millisecond}, slept)
+	require.Equal(t, map[string]int{
+		"123456789012/eu-west-1/GetMetricData/2024-01-01T00:00:00Z": 2,
+		"123456789012/eu-west-1/GetMetricData/2024-01-01T00:00:01Z": 1,
+	}, store.taken)
+
+	// Other accounts, regions and APIs have their own budgets, APIs without limit aren't counted
+	caller := &accountClient{accountID: "210987654321"}
+	b.Limiter("eu-west-1", model.Role{}, caller).Wait(context.Background(), cloudwatch.GetMetricDataCall)
+	b.Limiter("us-east-1", model.Role{RoleArn: "arn:aws:iam::123456789012:role/yace"}, nil).Wait(context.Background(), cloudwatch.GetMetricDataCall)
+	limiter.Wait(context.Background(), cloudwatch.ListMetricsCall)
+	require.Len(t, slept, 1)
+	require.Equal(t, 1, store.taken["210987654321/eu-west-1/GetMetricData/2024-01-01T00:00:01Z"])
+	require.Equal(t, 1, store.taken["123456789012/us-east-1/GetMetricData/2024-01-01T00:00:01Z"])
+}
+
+func TestBudgetStoreError(t *testing.T) {
+	store := &memoryStore{err: errors.New("unavailable")}
+	b := New(logging.NewNopLogger(), store, DefaultLimits)
+	b.sleep = func(time.Duration) { t.Fatal("waited for a failing budget") }
+
+	// The requests are let through
+	b.Limiter("eu-west-1", model.Role{}, nil).Wait(context.Background(), cloudwatch.GetMetricDataCall)
+}
+
+type blockingStore struct{}
+
+func (blockingStore) Take(ctx context.Context, _ string, _ time.Time, _ int) (bool, error) {
+	<-ctx.Done()
+	return false, ctx.Err()
+}
+
+func TestBudgetStoreTimeout(t *testing.T) {
+	b := New(logging.NewNopLogger(), blockingStore{}, DefaultLimits)
+	b.timeout = time.Millisecond
+	b.sleep = func(time.Duration) { t.Fatal("waited for a timed out budget") }
+
+	// The request is let through once the store times out
+	b.Limiter("eu-west-1", model.Role{}, nil).Wait(context.Background(), cloudwatch.GetMetricDataCall)
+	require.Equal(t, int64(1), b.Usage()[0].Errors)
+	require.Equal(t, context.DeadlineExceeded.Error(), b.Usage()[0].LastError)
+}
+
+func TestBudgetUsage(t *testing.T) {
+	store := &memoryStore{taken: map[string]int{}}
+	b := New(logging.NewNopLogger(), store, Limits{GetMetricData: 1})
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	b.now = func() time.Time { return now }
+	b.sleep = func(d time.Duration) { now = now.Add(d) }
+
+	limiter := b.Limiter("eu-west-1", model.Role{}, nil)
+	limiter.Wait(context.Background(), cloudwatch.GetMetricDataCall)
+	limiter.Wait(context.Background(), cloudwatch.GetMetricDataCall)
+	store.err = errors.New("unavailable")
+	limiter.Wait(context.Background(), cloudwatch.GetMetricDataCall)
+
+	require.Equal(t, []Usage{{
+		Budget:      "default/eu-west-1",
+		API:         cloudwatch.GetMetricDataCall,
+		Requests:    2,
+		Delayed:     1,
+		Errors:      1,
+		LastError:   "unavailable",
+		LastErrorAt: now,
+	}}, b.Usage())
+}
+
+func TestBudgetCallerAccount(t *testing.T) {
+	store := &memoryStore{taken: map[string]int{}}
+	b := New(logging.NewNopLogger(), store, Limits{GetMetricData: 10})
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	b.now = func() time.Time { return now }
+
+	// The account is resolved once per region, by the first request
+	caller := &accountClient{accountID: "123456789012"}
+	b.Limiter("eu-west-1", model.Role{}, caller).Wait(context.Background(), cloudwatch.GetMetricDataCall)
+	b.Limiter("eu-west-1", model.Role{}, caller).Wait(context.Background(), cloudwatch.GetMetricDataCall)
+	require.Equal(t, 1, caller.calls)
+	require.Equal(t, 2, store.taken["123456789012/eu-west-1/GetMetricData/2024-01-01T00:00:00Z"])
+
+	// The requests are counted in the default budget until the account is resolved
+	failing := &accountClient{err: errors.New("expired credentials")}
+	limiter := b.Limiter("us-east-1", model.Role{}, failing)
+	limiter.Wait(context.Background(), cloudwatch.GetMetricDataCall)
+	failing.accountID, failing.err = "123456789012", nil
+	limiter.Wait(context.Background(), cloudwatch.GetMetricDataCall)
+	require.Equal(t, 2, failing.calls)
+	require.Equal(t, 1, store.taken["default/us-east-1/GetMetricData/2024-01-01T00:00:00Z"])
+	require.Equal(t, 1, store.taken["123456789012/us-east-1/GetMetricData/2024-01-01T00:00:00Z"])
+}
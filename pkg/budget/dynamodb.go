@@ -0,0 +1,85 @@
+package budget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// itemTTL is how long the counters of a window are kept, they are deleted
+// afterwards by the TTL of the table.
+const itemTTL = time.Hour
+
+// DynamoDBStore counts the requests in a DynamoDB table, with a String partition key
+// named "id" and the TTL enabled on the "expires" attribute. Each request taken is an
+// update of the counter item of its budget and window.
+type DynamoDBStore struct {
+	dynamoAPI dynamodbiface.DynamoDBAPI
+	table     string
+}
+
+// NewDynamoDBStore returns a DynamoDBStore of the table, in the region of its ARN,
+// e.g. arn:aws:dynamodb:eu-west-1:123456789012:table/yace-budget.
+func NewDynamoDBStore(tableARN string, fips bool) (*DynamoDBStore, error) {
+	parsed, err := arn.Parse(tableARN)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DynamoDB table ARN %q: %w", tableARN, err)
+	}
+	table, ok := strings.CutPrefix(parsed.Resource, "table/")
+	if !ok || table == "" {
+		return nil, fmt.Errorf("invalid DynamoDB table ARN %q: not a table", tableARN)
+	}
+
+	cfg := &aws.Config{Region: aws.String(parsed.Region)}
+	if fips {
+		cfg.UseFIPSEndpoint = endpoints.FIPSEndpointStateEnabled
+	}
+	sess, err := session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+		Config:            *cfg,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the DynamoDB session: %w", err)
+	}
+
+	return &DynamoDBStore{
+		dynamoAPI: dynamodb.New(sess),
+		table:     table,
+	}, nil
+}
+
+// Take increments the counter of the window, on the condition it is below the limit.
+func (s *DynamoDBStore) Take(ctx context.Context, key string, start time.Time, limit int) (bool, error) {
+	_, err := s.dynamoAPI.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(s.table),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {S: aws.String(key + "/" + strconv.FormatInt(start.Unix(), 10))},
+		},
+		UpdateExpression:    aws.String("ADD requests :one SET expires = :expires"),
+		ConditionExpression: aws.String("attribute_not_exists(requests) OR requests < :limit"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":one":     {N: aws.String("1")},
+			":limit":   {N: aws.String(strconv.Itoa(limit))},
+			":expires": {N: aws.String(strconv.FormatInt(start.Add(itemTTL).Unix(), 10))},
+		},
+	})
+	var aerr awserr.Error
+	if errors.As(err, &aerr) && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
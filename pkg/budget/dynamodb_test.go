@@ -0,0 +1,53 @@
+package budget
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/stretchr/testify/require"
+)
+
+type mockDynamoDB struct {
+	dynamodbiface.DynamoDBAPI
+	counts map[string]int
+}
+
+func (m *mockDynamoDB) UpdateItemWithContext(_ aws.Context, input *dynamodb.UpdateItemInput, _ ...request.Option) (*dynamodb.UpdateItemOutput, error) {
+	id := aws.StringValue(input.Key["id"].S)
+	if m.counts[id] >= 2 {
+		return nil, awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "The conditional request failed", nil)
+	}
+	m.counts[id]++
+	return &dynamodb.UpdateItemOutput{}, nil
+}
+
+func TestDynamoDBStoreTake(t *testing.T) {
+	mock := &mockDynamoDB{counts: map[string]int{}}
+	store := &DynamoDBStore{dynamoAPI: mock, table: "yace-budget"}
+	start := time.Unix(1700000000, 0)
+
+	for _, expected := range []bool{true, true, false} {
+		ok, err := store.Take(context.Background(), "123456789012/eu-west-1/GetMetricData", start, 2)
+		require.NoError(t, err)
+		require.Equal(t, expected, ok)
+	}
+	require.Equal(t, map[string]int{"123456789012/eu-west-1/GetMetricData/1700000000": 2}, mock.counts)
+}
+
+func TestNewDynamoDBStore(t *testing.T) {
+	store, err := NewDynamoDBStore("arn:aws:dynamodb:eu-west-1:123456789012:table/yace-budget", false)
+	require.NoError(t, err)
+	require.Equal(t, "yace-budget", store.table)
+
+	_, err = NewDynamoDBStore("arn:aws:sqs:eu-west-1:123456789012:yace-budget", false)
+	require.ErrorContains(t, err, "not a table")
+
+	_, err = NewDynamoDBStore("yace-budget", false)
+	require.ErrorContains(t, err, "invalid DynamoDB table ARN")
+}
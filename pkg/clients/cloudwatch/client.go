@@ -8,16 +8,18 @@ import (
 	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
 )
 
+// The CloudWatch API calls, as the operations of a ConcurrencyLimiter.
 const (
-	listMetricsCall         = "ListMetrics"
-	getMetricDataCall       = "GetMetricData"
-	getMetricStatisticsCall = "GetMetricStatistics"
+	ListMetricsCall         = "ListMetrics"
+	GetMetricDataCall       = "GetMetricData"
+	GetMetricStatisticsCall = "GetMetricStatistics"
 )
 
 type Client interface {
 	// ListMetrics returns the list of metrics and dimensions for a given namespace
-	// and metric name. Results pagination is handled automatically: the caller can
-	// optionally pass a non-nil func in order to handle results pages.
+	// and metric name. An empty namespace or metric name lists the metrics of all
+	// namespaces or of all names. Results pagination is handled automatically: the
+	// caller can optionally pass a non-nil func in order to handle results pages.
 	ListMetrics(ctx context.Context, namespace string, metric *model.MetricConfig, recentlyActiveOnly bool, fn func(page []*model.Metric)) error
 
 	// GetMetricData returns the output of the GetMetricData CloudWatch API.
@@ -49,34 +51,48 @@ type MetricDataResult struct {
 }
 
 type limitedConcurrencyClient struct {
-	client  Client
-	limiter ConcurrencyLimiter
+	client         Client
+	limiter        ConcurrencyLimiter
+	requestLimiter RequestLimiter
 }
 
-func NewLimitedConcurrencyClient(client Client, limiter ConcurrencyLimiter) Client {
+// NewLimitedConcurrencyClient limits the concurrent calls of the client with limiter, and the rate of
+// their requests with the optional requestLimiter, passed to the client in the context of the calls.
+func NewLimitedConcurrencyClient(client Client, limiter ConcurrencyLimiter, requestLimiter RequestLimiter) Client {
 	return &limitedConcurrencyClient{
-		client:  client,
-		limiter: limiter,
+		client:         client,
+		limiter:        limiter,
+		requestLimiter: requestLimiter,
 	}
 }
 
+func (c limitedConcurrencyClient) withRequestLimiter(ctx context.Context) context.Context {
+	if c.requestLimiter == nil {
+		return ctx
+	}
+	return WithRequestLimiter(ctx, c.requestLimiter)
+}
+
 func (c limitedConcurrencyClient) GetMetricStatistics(ctx context.Context, logger logging.Logger, dimensions []*model.Dimension, namespace string, metric *model.MetricConfig) []*model.Datapoint {
-	c.limiter.Acquire(getMetricStatisticsCall)
+	c.limiter.Acquire(GetMetricStatisticsCall)
+	ctx = c.withRequestLimiter(ctx)
 	res := c.client.GetMetricStatistics(ctx, logger, dimensions, namespace, metric)
-	c.limiter.Release(getMetricStatisticsCall)
+	c.limiter.Release(GetMetricStatisticsCall)
 	return res
 }
 
 func (c limitedConcurrencyClient) GetMetricData(ctx context.Context, logger logging.Logger, getMetricData []*model.CloudwatchData, namespace string, length int64, delay int64, configuredRoundingPeriod *int64, addHistoricalMetrics bool) []MetricDataResult {
-	c.limiter.Acquire(getMetricDataCall)
+	c.limiter.Acquire(GetMetricDataCall)
+	ctx = c.withRequestLimiter(ctx)
 	res := c.client.GetMetricData(ctx, logger, getMetricData, namespace, length, delay, configuredRoundingPeriod, addHistoricalMetrics)
-	c.limiter.Release(getMetricDataCall)
+	c.limiter.Release(GetMetricDataCall)
 	return res
 }
 
 func (c limitedConcurrencyClient) ListMetrics(ctx context.Context, namespace string, metric *model.MetricConfig, recentlyActiveOnly bool, fn func(page []*model.Metric)) error {
-	c.limiter.Acquire(listMetricsCall)
+	c.limiter.Acquire(ListMetricsCall)
+	ctx = c.withRequestLimiter(ctx)
 	err := c.client.ListMetrics(ctx, namespace, metric, recentlyActiveOnly, fn)
-	c.limiter.Release(listMetricsCall)
+	c.limiter.Release(ListMetricsCall)
 	return err
 }
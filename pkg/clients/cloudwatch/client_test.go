@@ -0,0 +1,63 @@
+package cloudwatch
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+type countingRequestLimiter map[string]int
+
+func (l countingRequestLimiter) Wait(_ context.Context, op string) {
+	l[op]++
+}
+
+// pagingClient sends pages requests per call, waiting for the request limiter before each of them.
+type pagingClient struct {
+	pages int
+}
+
+func (c pagingClient) ListMetrics(ctx context.Context, _ string, _ *model.MetricConfig, _ bool, fn func(page []*model.Metric)) error {
+	for i := 0; i < c.pages; i++ {
+		WaitForRequest(ctx, ListMetricsCall)
+		fn(nil)
+	}
+	return nil
+}
+
+func (c pagingClient) GetMetricData(ctx context.Context, _ logging.Logger, _ []*model.CloudwatchData, _ string, _ int64, _ int64, _ *int64, _ bool) []MetricDataResult {
+	for i := 0; i < c.pages; i++ {
+		WaitForRequest(ctx, GetMetricDataCall)
+	}
+	return nil
+}
+
+func (c pagingClient) GetMetricStatistics(ctx context.Context, _ logging.Logger, _ []*model.Dimension, _ string, _ *model.MetricConfig) []*model.Datapoint {
+	WaitForRequest(ctx, GetMetricStatisticsCall)
+	return nil
+}
+
+func TestLimitedConcurrencyClientRequestLimiter(t *testing.T) {
+	requests := countingRequestLimiter{}
+	client := NewLimitedConcurrencyClient(pagingClient{pages: 3}, NewSingleLimiter(1), requests)
+
+	require.NoError(t, client.ListMetrics(context.Background(), "AWS/EC2", &model.MetricConfig{}, false, func([]*model.Metric) {}))
+	client.GetMetricData(context.Background(), logging.NewNopLogger(), nil, "AWS/EC2", 300, 300, nil, false)
+	client.GetMetricStatistics(context.Background(), logging.NewNopLogger(), nil, "AWS/EC2", &model.MetricConfig{})
+
+	// The requests are counted per page, not per call
+	require.Equal(t, countingRequestLimiter{
+		ListMetricsCall:         3,
+		GetMetricDataCall:       3,
+		GetMetricStatisticsCall: 1,
+	}, requests)
+
+	// Without request limiter, the requests aren't limited
+	client = NewLimitedConcurrencyClient(pagingClient{pages: 3}, NewSingleLimiter(1), nil)
+	require.NoError(t, client.ListMetrics(context.Background(), "AWS/EC2", &model.MetricConfig{}, false, func([]*model.Metric) {}))
+	require.Equal(t, 3, requests[ListMetricsCall])
+}
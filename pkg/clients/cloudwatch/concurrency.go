@@ -1,5 +1,12 @@
 package cloudwatch
 
+import (
+	"context"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/account"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
 // ConcurrencyConfig configures how concurrency should be limited in a Cloudwatch API client. It allows
 // one to pick between different limiter implementations: a single limit limiter, or one with a different limit per
 // API call.
@@ -18,6 +25,43 @@ type ConcurrencyConfig struct {
 
 	// GetMetricStatistics limits the number for GetMetricStatistics API concurrent API calls.
 	GetMetricStatistics int
+
+	// Shared is an optional limiter shared with other clients, acquired after the limiter of the client.
+	Shared ConcurrencyLimiter
+
+	// Budget is an optional rate limit of the requests per account and region, shared with other exporters.
+	// It is waited for before each request of a call, i.e. each page and window.
+	Budget RequestBudget
+}
+
+// RequestBudget limits the rate of the requests to the CloudWatch API of an account and region.
+type RequestBudget interface {
+	// Limiter returns the limiter of the requests to the region with the role. The account
+	// client resolves the account of the requests when the role doesn't tell it.
+	Limiter(region string, role model.Role, accountClient account.Client) RequestLimiter
+}
+
+// RequestLimiter limits the rate of the requests to the CloudWatch API. Unlike a ConcurrencyLimiter,
+// it is waited for before each request sent to the API rather than once per call, since a call can
+// send several requests to paginate its results or split its time range.
+type RequestLimiter interface {
+	// Wait blocks until a request to op can be sent.
+	Wait(ctx context.Context, op string)
+}
+
+type requestLimiterKey struct{}
+
+// WithRequestLimiter returns a copy of ctx carrying the limiter of the requests sent with it.
+func WithRequestLimiter(ctx context.Context, limiter RequestLimiter) context.Context {
+	return context.WithValue(ctx, requestLimiterKey{}, limiter)
+}
+
+// WaitForRequest waits for the RequestLimiter of ctx, if any, before sending a request to op.
+// The clients call it before each request, i.e. for each page and window of a call.
+func WaitForRequest(ctx context.Context, op string) {
+	if limiter, ok := ctx.Value(requestLimiterKey{}).(RequestLimiter); ok {
+		limiter.Wait(ctx, op)
+	}
 }
 
 // semaphore implements a simple semaphore using a channel.
@@ -36,12 +80,44 @@ func (s semaphore) Release() {
 	<-s
 }
 
-// NewLimiter creates a new ConcurrencyLimiter, according to the ConcurrencyConfig.
-func (cfg ConcurrencyConfig) NewLimiter() ConcurrencyLimiter {
+// NewLimiter creates a new ConcurrencyLimiter of a client of the region and role, according to the ConcurrencyConfig.
+func (cfg ConcurrencyConfig) NewLimiter(region string, role model.Role) ConcurrencyLimiter {
+	var limiter ConcurrencyLimiter
 	if cfg.PerAPILimitEnabled {
-		return NewPerAPICallLimiter(cfg.ListMetrics, cfg.GetMetricData, cfg.GetMetricStatistics)
+		limiter = NewPerAPICallLimiter(cfg.ListMetrics, cfg.GetMetricData, cfg.GetMetricStatistics)
+	} else {
+		limiter = NewSingleLimiter(cfg.SingleLimit)
+	}
+	if cfg.Shared != nil {
+		return &chainedLimiter{limiters: []ConcurrencyLimiter{limiter, cfg.Shared}}
+	}
+	return limiter
+}
+
+// NewRequestLimiter returns the RequestLimiter of a client of the region and role, nil without Budget.
+func (cfg ConcurrencyConfig) NewRequestLimiter(region string, role model.Role, accountClient account.Client) RequestLimiter {
+	if cfg.Budget == nil {
+		return nil
+	}
+	return cfg.Budget.Limiter(region, role, accountClient)
+}
+
+// chainedLimiter is a ConcurrencyLimiter acquiring a ticket from each of its limiters in order, and releasing
+// them in reverse order. A routine waiting for the client limiter doesn't hold a ticket of the shared one.
+type chainedLimiter struct {
+	limiters []ConcurrencyLimiter
+}
+
+func (l *chainedLimiter) Acquire(op string) {
+	for _, limiter := range l.limiters {
+		limiter.Acquire(op)
+	}
+}
+
+func (l *chainedLimiter) Release(op string) {
+	for i := len(l.limiters) - 1; i >= 0; i-- {
+		l.limiters[i].Release(op)
 	}
-	return NewSingleLimiter(cfg.SingleLimit)
 }
 
 // perAPICallLimiter is a ConcurrencyLimiter that keeps a different concurrency limiter per different API call. This allows
@@ -64,22 +140,22 @@ func NewPerAPICallLimiter(listMetrics, getMetricData, getMetricStatistics int) C
 
 func (l *perAPICallLimiter) Acquire(op string) {
 	switch op {
-	case listMetricsCall:
+	case ListMetricsCall:
 		l.listMetricsLimiter.Acquire()
-	case getMetricDataCall:
+	case GetMetricDataCall:
 		l.getMetricsDataLimiter.Acquire()
-	case getMetricStatisticsCall:
+	case GetMetricStatisticsCall:
 		l.getMetricsStatisticsLimiter.Acquire()
 	}
 }
 
 func (l *perAPICallLimiter) Release(op string) {
 	switch op {
-	case listMetricsCall:
+	case ListMetricsCall:
 		l.listMetricsLimiter.Release()
-	case getMetricDataCall:
+	case GetMetricDataCall:
 		l.getMetricsDataLimiter.Release()
-	case getMetricStatisticsCall:
+	case GetMetricStatisticsCall:
 		l.getMetricsStatisticsLimiter.Release()
 	}
 }
@@ -1,6 +1,11 @@
 package cloudwatch
 
-import "time"
+import (
+	"slices"
+	"time"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
 
 const TimeFormat = "2006-01-02T15:04:05.999999-07:00"
 
@@ -31,3 +36,105 @@ func DetermineGetMetricDataWindow(clock Clock, roundingPeriod time.Duration, len
 	endTime := now.Add(-delay)
 	return startTime, endTime
 }
+
+// MaxGetMetricDataDatapoints is the number of datapoints a GetMetricData call returns at most.
+const MaxGetMetricDataDatapoints = 100800
+
+// Window is the time range of a GetMetricData call, the start inclusive and the end exclusive.
+type Window struct {
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+// SplitGetMetricDataWindow splits the window of a GetMetricData call into consecutive
+// windows, the latest first, so that each call returns at most MaxGetMetricDataDatapoints
+// datapoints of the queries at the shortest period of their metrics. The windows are
+// multiples of the period, the earliest one taking the remainder.
+func SplitGetMetricDataWindow(startTime time.Time, endTime time.Time, period time.Duration, queries int) []Window {
+	if period <= 0 || queries <= 0 {
+		return []Window{{StartTime: startTime, EndTime: endTime}}
+	}
+	periods := max(MaxGetMetricDataDatapoints/queries, 1)
+	step := time.Duration(periods) * period
+
+	var windows []Window
+	for end := endTime; len(windows) == 0 || end.After(startTime); end = end.Add(-step) {
+		start := end.Add(-step)
+		if start.Before(startTime) {
+			start = startTime
+		}
+		windows = append(windows, Window{StartTime: start, EndTime: end})
+	}
+	return windows
+}
+
+// ShortestPeriod returns the shortest period of the metrics, zero if none.
+func ShortestPeriod(getMetricData []*model.CloudwatchData) time.Duration {
+	var shortest int64
+	for _, data := range getMetricData {
+		if data.Period > 0 && (shortest == 0 || data.Period < shortest) {
+			shortest = data.Period
+		}
+	}
+	return time.Duration(shortest) * time.Second
+}
+
+// LatestCompletePeriods returns the start of the latest complete period, by ID
+// of the metrics which only export complete periods: the periods entirely in
+// the GetMetricData window ending at endTime, and the periods which ended at
+// least their publish delay before now. Datapoints are timestamped at the start
+// of their period.
+func LatestCompletePeriods(getMetricData []*model.CloudwatchData, endTime time.Time, now time.Time) map[string]time.Time {
+	var latest map[string]time.Time
+	for _, data := range getMetricData {
+		if data.MetricID == nil {
+			continue
+		}
+		period := time.Duration(data.Period) * time.Second
+		var latestComplete time.Time
+		if data.CompletePeriodsOnly != nil && *data.CompletePeriodsOnly {
+			latestComplete = endTime.Add(-period)
+		}
+		if data.SkipIncompletePeriod != nil && *data.SkipIncompletePeriod {
+			elapsed := now.Add(-period - data.PublishDelay)
+			if latestComplete.IsZero() || elapsed.Before(latestComplete) {
+				latestComplete = elapsed
+			}
+		}
+		if latestComplete.IsZero() {
+			continue
+		}
+		if latest == nil {
+			latest = make(map[string]time.Time)
+		}
+		latest[*data.MetricID] = latestComplete
+	}
+	return latest
+}
+
+// WeightedAverageQueries returns the ids of the Sum and SampleCount queries of the
+// WeightedAverage of the query id, and the metric math expression dividing them.
+func WeightedAverageQueries(id string) (string, string, string) {
+	sumID, countID := id+"_sum", id+"_count"
+	return sumID, countID, sumID + " / " + countID
+}
+
+// GetMetricStatisticsStatistics returns the statistics to request with
+// GetMetricStatistics: the Sum and SampleCount in place of WeightedAverage.
+func GetMetricStatisticsStatistics(statistics []string) []string {
+	if !slices.Contains(statistics, model.StatisticWeightedAverage) {
+		return statistics
+	}
+	requested := make([]string, 0, len(statistics)+1)
+	for _, statistic := range statistics {
+		if statistic != model.StatisticWeightedAverage {
+			requested = append(requested, statistic)
+		}
+	}
+	for _, statistic := range []string{"Sum", "SampleCount"} {
+		if !slices.Contains(requested, statistic) {
+			requested = append(requested, statistic)
+		}
+	}
+	return requested
+}
@@ -3,6 +3,10 @@ package cloudwatch
 import (
 	"testing"
 	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
 )
 
 // StubClock stub implementation of Clock interface that allows tests
@@ -93,3 +97,66 @@ func Test_MetricWindow(t *testing.T) {
 		})
 	}
 }
+
+func Test_LatestCompletePeriods(t *testing.T) {
+	endTime := time.Date(2021, 11, 20, 8, 30, 0, 0, time.UTC)
+	now := endTime.Add(time.Minute)
+	enabled, disabled := true, false
+	id1, id2, id3, id4, id5 := "id_1", "id_2", "id_3", "id_4", "id_5"
+
+	latest := LatestCompletePeriods([]*model.CloudwatchData{
+		{MetricID: &id1, Period: 300, CompletePeriodsOnly: &enabled},
+		{MetricID: &id2, Period: 60, CompletePeriodsOnly: &disabled},
+		{MetricID: &id3, Period: 60},
+		{MetricID: &id4, Period: 60, SkipIncompletePeriod: &enabled, PublishDelay: 2 * time.Minute},
+		{MetricID: &id5, Period: 60, CompletePeriodsOnly: &enabled, SkipIncompletePeriod: &enabled, PublishDelay: 2 * time.Minute},
+	}, endTime, now)
+
+	expected := map[string]time.Time{
+		id1: time.Date(2021, 11, 20, 8, 25, 0, 0, time.UTC),
+		id4: time.Date(2021, 11, 20, 8, 28, 0, 0, time.UTC),
+		id5: time.Date(2021, 11, 20, 8, 28, 0, 0, time.UTC),
+	}
+	if len(latest) != len(expected) {
+		t.Fatalf("latest complete periods incorrect. Expected: %v, Actual: %v", expected, latest)
+	}
+	for id, expectedTime := range expected {
+		if !latest[id].Equal(expectedTime) {
+			t.Errorf("latest complete period of %s incorrect. Expected: %s, Actual: %s", id, expectedTime.Format(TimeFormat), latest[id].Format(TimeFormat))
+		}
+	}
+}
+
+func Test_GetMetricStatisticsStatistics(t *testing.T) {
+	require.Equal(t, []string{"Maximum", "p99"}, GetMetricStatisticsStatistics([]string{"Maximum", "p99"}))
+	require.Equal(t, []string{"Sum", "Maximum", "SampleCount"}, GetMetricStatisticsStatistics([]string{"Sum", model.StatisticWeightedAverage, "Maximum"}))
+}
+
+func Test_SplitGetMetricDataWindow(t *testing.T) {
+	endTime := time.Date(2021, 11, 20, 0, 0, 0, 0, time.UTC)
+
+	// 2 days of 1 minute datapoints of 30 queries fit in a call
+	windows := SplitGetMetricDataWindow(endTime.Add(-48*time.Hour), endTime, time.Minute, 30)
+	require.Equal(t, []Window{{StartTime: endTime.Add(-48 * time.Hour), EndTime: endTime}}, windows)
+
+	// 14 days of 1 minute datapoints of 10 queries take 2 calls of 7 days
+	windows = SplitGetMetricDataWindow(endTime.Add(-14*24*time.Hour), endTime, time.Minute, 10)
+	require.Equal(t, []Window{
+		{StartTime: endTime.Add(-7 * 24 * time.Hour), EndTime: endTime},
+		{StartTime: endTime.Add(-14 * 24 * time.Hour), EndTime: endTime.Add(-7 * 24 * time.Hour)},
+	}, windows)
+
+	// The earliest window takes the remainder
+	windows = SplitGetMetricDataWindow(endTime.Add(-3*time.Hour), endTime, time.Minute, 1000)
+	require.Len(t, windows, 2)
+	require.Equal(t, Window{StartTime: endTime.Add(-3 * time.Hour), EndTime: endTime.Add(-100 * time.Minute)}, windows[1])
+
+	// More queries than datapoints in a call still query a period per call
+	windows = SplitGetMetricDataWindow(endTime.Add(-2*time.Minute), endTime, time.Minute, 2*MaxGetMetricDataDatapoints)
+	require.Len(t, windows, 2)
+}
+
+func Test_ShortestPeriod(t *testing.T) {
+	require.Equal(t, time.Duration(0), ShortestPeriod(nil))
+	require.Equal(t, time.Minute, ShortestPeriod([]*model.CloudwatchData{{Period: 300}, {Period: 60}, {Period: 86400}}))
+}
@@ -0,0 +1,99 @@
+package cloudwatch
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+)
+
+// PriorityLimiter is a concurrency limit shared by classes of clients. When the limit is
+// reached, the tickets released are handed to the waiting classes in proportion to their
+// weights, so that a class with many waiting routines doesn't starve the others.
+type PriorityLimiter struct {
+	mu      sync.Mutex
+	free    int
+	now     float64
+	classes []*priorityClass
+}
+
+// priorityClass is a class of a PriorityLimiter. Its pass is the virtual time of its next
+// ticket, which advances by the inverse of its weight for each ticket granted.
+type priorityClass struct {
+	limiter *PriorityLimiter
+	name    string
+	weight  float64
+	pass    float64
+	waiting []chan struct{}
+}
+
+// NewPriorityLimiter creates a new PriorityLimiter with the given limit and weight per class.
+func NewPriorityLimiter(limit int, weights map[string]int) *PriorityLimiter {
+	l := &PriorityLimiter{free: limit}
+	for name, weight := range weights {
+		l.classes = append(l.classes, &priorityClass{limiter: l, name: name, weight: float64(max(weight, 1))})
+	}
+	// Among classes with the same pass, the heaviest is granted first.
+	slices.SortFunc(l.classes, func(a, b *priorityClass) int {
+		return cmp.Or(cmp.Compare(b.weight, a.weight), cmp.Compare(a.name, b.name))
+	})
+	return l
+}
+
+// Class returns the ConcurrencyLimiter of a class. Unknown classes have a weight of 1.
+func (l *PriorityLimiter) Class(name string) ConcurrencyLimiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, c := range l.classes {
+		if c.name == name {
+			return c
+		}
+	}
+	c := &priorityClass{limiter: l, name: name, weight: 1}
+	l.classes = append(l.classes, c)
+	return c
+}
+
+func (c *priorityClass) Acquire(_ string) {
+	l := c.limiter
+	l.mu.Lock()
+	if len(c.waiting) == 0 {
+		// A class becoming active again doesn't get credit for the time it was idle.
+		c.pass = max(c.pass, l.now)
+	}
+	if l.free > 0 {
+		l.free--
+		c.grant()
+		l.mu.Unlock()
+		return
+	}
+	ch := make(chan struct{})
+	c.waiting = append(c.waiting, ch)
+	l.mu.Unlock()
+	<-ch
+}
+
+func (c *priorityClass) Release(_ string) {
+	l := c.limiter
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var next *priorityClass
+	for _, class := range l.classes {
+		if len(class.waiting) > 0 && (next == nil || class.pass < next.pass) {
+			next = class
+		}
+	}
+	if next == nil {
+		l.free++
+		return
+	}
+	ch := next.waiting[0]
+	next.waiting = next.waiting[1:]
+	next.grant()
+	close(ch)
+}
+
+// grant advances the virtual time of the class for a ticket. It must be called with the lock held.
+func (c *priorityClass) grant() {
+	c.limiter.now = c.pass
+	c.pass += 1 / c.weight
+}
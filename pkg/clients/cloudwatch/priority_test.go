@@ -0,0 +1,82 @@
+package cloudwatch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+func TestPriorityLimiter(t *testing.T) {
+	limiter := NewPriorityLimiter(1, map[string]int{"high": 4, "low": 1})
+	high, low := limiter.Class("high"), limiter.Class("low")
+
+	// waiting returns the number of routines waiting for a ticket.
+	waiting := func() int {
+		limiter.mu.Lock()
+		defer limiter.mu.Unlock()
+		n := 0
+		for _, c := range limiter.classes {
+			n += len(c.waiting)
+		}
+		return n
+	}
+
+	low.Acquire(GetMetricDataCall)
+	granted := make(chan string)
+	enqueue := func(name string, class ConcurrencyLimiter, count int) {
+		for i := 0; i < count; i++ {
+			expected := waiting() + 1
+			go func() {
+				class.Acquire(GetMetricDataCall)
+				granted <- name
+			}()
+			require.Eventually(t, func() bool { return waiting() == expected }, time.Second, time.Millisecond)
+		}
+	}
+	enqueue("low", low, 4)
+	enqueue("high", high, 8)
+
+	var order []string
+	release := low
+	for i := 0; i < 12; i++ {
+		release.Release(GetMetricDataCall)
+		name := <-granted
+		order = append(order, name)
+		release = limiter.Class(name)
+	}
+	release.Release(GetMetricDataCall)
+
+	require.Equal(t, []string{
+		"high", "high", "high", "high", "high", "low",
+		"high", "high", "high", "low", "low", "low",
+	}, order)
+
+	// Once released, a ticket is available without waiting.
+	high.Acquire(ListMetricsCall)
+	high.Release(ListMetricsCall)
+}
+
+func TestChainedLimiter(t *testing.T) {
+	shared := NewSingleLimiter(1)
+	first := ConcurrencyConfig{SingleLimit: 1, Shared: shared}.NewLimiter("eu-west-1", model.Role{})
+	second := ConcurrencyConfig{SingleLimit: 1, Shared: shared}.NewLimiter("eu-west-1", model.Role{})
+
+	first.Acquire(GetMetricDataCall)
+	acquired := make(chan struct{})
+	go func() {
+		second.Acquire(GetMetricDataCall)
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("acquired a ticket of the shared limiter held by another client")
+	case <-time.After(10 * time.Millisecond):
+	}
+	first.Release(GetMetricDataCall)
+	<-acquired
+	second.Release(GetMetricDataCall)
+}
@@ -2,8 +2,10 @@ package v1
 
 import (
 	"context"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/cloudwatch"
 	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
 
@@ -26,9 +28,12 @@ func NewClient(logger logging.Logger, cloudwatchAPI cloudwatchiface.CloudWatchAP
 }
 
 func (c client) ListMetrics(ctx context.Context, namespace string, metric *model.MetricConfig, recentlyActiveOnly bool, fn func(page []*model.Metric)) error {
-	filter := &cloudwatch.ListMetricsInput{
-		MetricName: aws.String(metric.Name),
-		Namespace:  aws.String(namespace),
+	filter := &cloudwatch.ListMetricsInput{}
+	if namespace != "" {
+		filter.Namespace = aws.String(namespace)
+	}
+	if metric.Name != "" {
+		filter.MetricName = aws.String(metric.Name)
 	}
 	if recentlyActiveOnly {
 		filter.RecentlyActive = aws.String("PT3H")
@@ -49,7 +54,7 @@ func (c client) ListMetrics(ctx context.Context, namespace string, metric *model
 
 		fn(metricsPage)
 		return !lastPage
-	})
+	}, waitForRequest(cloudwatch_client.ListMetricsCall))
 	if err != nil {
 		promutil.CloudwatchAPIErrorCounter.Inc()
 		c.logger.Error(err, "ListMetrics error")
@@ -59,6 +64,16 @@ func (c client) ListMetrics(ctx context.Context, namespace string, metric *model
 	return nil
 }
 
+// waitForRequest waits for the request limiter of the context before each request of a call,
+// i.e. each page. The Build handlers run once per request, not on its retries.
+func waitForRequest(op string) request.Option {
+	return func(r *request.Request) {
+		r.Handlers.Build.PushFront(func(r *request.Request) {
+			cloudwatch_client.WaitForRequest(r.Context(), op)
+		})
+	}
+}
+
 func toModelMetric(page *cloudwatch.ListMetricsOutput) []*model.Metric {
 	modelMetrics := make([]*model.Metric, 0, len(page.Metrics))
 	for _, cloudwatchMetric := range page.Metrics {
@@ -87,45 +102,81 @@ func toModelDimensions(dimensions []*cloudwatch.Dimension) []*model.Dimension {
 func (c client) GetMetricData(ctx context.Context, logger logging.Logger, getMetricData []*model.CloudwatchData, namespace string, length int64, delay int64, configuredRoundingPeriod *int64, addHistoricalMetrics bool) []cloudwatch_client.MetricDataResult {
 	var resp cloudwatch.GetMetricDataOutput
 	filter := createGetMetricDataInput(getMetricData, &namespace, length, delay, configuredRoundingPeriod, logger)
-	if c.logger.IsDebugEnabled() {
-		c.logger.Debug("GetMetricData", "input", filter)
+
+	windows := cloudwatch_client.SplitGetMetricDataWindow(*filter.StartTime, *filter.EndTime, cloudwatch_client.ShortestPeriod(getMetricData), len(filter.MetricDataQueries))
+	if len(windows) > 1 {
+		logger.Debug("GetMetricData window split", "windows", len(windows))
 	}
+	for _, window := range windows {
+		input := *filter
+		input.StartTime, input.EndTime = aws.Time(window.StartTime), aws.Time(window.EndTime)
+		if c.logger.IsDebugEnabled() {
+			c.logger.Debug("GetMetricData", "input", input)
+		}
 
-	// Using the paged version of the function
-	err := c.cloudwatchAPI.GetMetricDataPagesWithContext(ctx, filter,
-		func(page *cloudwatch.GetMetricDataOutput, lastPage bool) bool {
-			promutil.CloudwatchAPICounter.Inc()
-			promutil.CloudwatchGetMetricDataAPICounter.Inc()
-			resp.MetricDataResults = append(resp.MetricDataResults, page.MetricDataResults...)
-			promutil.CloudwatchGetMetricDataAPIMetricsCounter.Add(float64(len(page.MetricDataResults)))
-			return !lastPage
-		})
+		// Using the paged version of the function
+		err := c.cloudwatchAPI.GetMetricDataPagesWithContext(ctx, &input,
+			func(page *cloudwatch.GetMetricDataOutput, lastPage bool) bool {
+				promutil.CloudwatchAPICounter.Inc()
+				promutil.CloudwatchGetMetricDataAPICounter.Inc()
+				resp.MetricDataResults = append(resp.MetricDataResults, page.MetricDataResults...)
+				promutil.CloudwatchGetMetricDataAPIMetricsCounter.Add(float64(len(page.MetricDataResults)))
+				return !lastPage
+			}, waitForRequest(cloudwatch_client.GetMetricDataCall))
+		if err != nil {
+			c.logger.Error(err, "GetMetricData error")
+			return nil
+		}
+	}
+	resp.MetricDataResults = mergeMetricDataResults(resp.MetricDataResults)
 
 	if c.logger.IsDebugEnabled() {
 		c.logger.Debug("GetMetricData", "output", resp)
 	}
 
-	if err != nil {
-		c.logger.Error(err, "GetMetricData error")
-		return nil
+	return toMetricDataResult(resp, addHistoricalMetrics, cloudwatch_client.LatestCompletePeriods(getMetricData, *filter.EndTime, time.Now()))
+}
+
+// mergeMetricDataResults merges the results of the same query returned by the pages
+// and windows of the calls, the latest first, keeping the datapoints sorted by
+// descending timestamp.
+func mergeMetricDataResults(results []*cloudwatch.MetricDataResult) []*cloudwatch.MetricDataResult {
+	merged := make([]*cloudwatch.MetricDataResult, 0, len(results))
+	indexes := make(map[string]int, len(results))
+	for _, result := range results {
+		i, ok := indexes[*result.Id]
+		if !ok {
+			indexes[*result.Id] = len(merged)
+			merged = append(merged, result)
+			continue
+		}
+		merged[i].Values = append(merged[i].Values, result.Values...)
+		merged[i].Timestamps = append(merged[i].Timestamps, result.Timestamps...)
 	}
-	return toMetricDataResult(resp, addHistoricalMetrics)
+	return merged
 }
 
-func toMetricDataResult(resp cloudwatch.GetMetricDataOutput, addHistoricalMetrics bool) []cloudwatch_client.MetricDataResult {
+// toMetricDataResult maps the datapoints of the response, sorted by descending
+// timestamp, skipping the partial periods of the metrics in latestCompletePeriods.
+func toMetricDataResult(resp cloudwatch.GetMetricDataOutput, addHistoricalMetrics bool, latestCompletePeriods map[string]time.Time) []cloudwatch_client.MetricDataResult {
 	output := make([]cloudwatch_client.MetricDataResult, 0, len(resp.MetricDataResults))
 	for _, metricDataResult := range resp.MetricDataResults {
 		mappedResult := cloudwatch_client.MetricDataResult{ID: *metricDataResult.Id}
-		if len(metricDataResult.Values) > 0 {
-			for i := 0; i < len(metricDataResult.Values); i++ {
-				mappedResult.Datapoint = metricDataResult.Values[i]
-				mappedResult.Timestamp = *metricDataResult.Timestamps[i]
-				output = append(output, mappedResult)
-				if !addHistoricalMetrics {
-					break
-				}
+		latestComplete, completeOnly := latestCompletePeriods[mappedResult.ID]
+		mapped := false
+		for i := 0; i < len(metricDataResult.Values); i++ {
+			if completeOnly && metricDataResult.Timestamps[i].After(latestComplete) {
+				continue
 			}
-		} else {
+			mappedResult.Datapoint = metricDataResult.Values[i]
+			mappedResult.Timestamp = *metricDataResult.Timestamps[i]
+			output = append(output, mappedResult)
+			mapped = true
+			if !addHistoricalMetrics {
+				break
+			}
+		}
+		if !mapped {
 			output = append(output, mappedResult)
 		}
 	}
@@ -139,7 +190,7 @@ func (c client) GetMetricStatistics(ctx context.Context, logger logging.Logger,
 		c.logger.Debug("GetMetricStatistics", "input", filter)
 	}
 
-	resp, err := c.cloudwatchAPI.GetMetricStatisticsWithContext(ctx, filter)
+	resp, err := c.cloudwatchAPI.GetMetricStatisticsWithContext(ctx, filter, waitForRequest(cloudwatch_client.GetMetricStatisticsCall))
 
 	if c.logger.IsDebugEnabled() {
 		c.logger.Debug("GetMetricStatistics", "output", resp)
@@ -34,6 +34,7 @@ func Test_toMetricDataResult(t *testing.T) {
 	type testCase struct {
 		name                      string
 		getMetricDataOutput       cloudwatch.GetMetricDataOutput
+		latestCompletePeriods     map[string]time.Time
 		expectedMetricDataResults []cloudwatch_client.MetricDataResult
 	}
 
@@ -80,11 +81,36 @@ func Test_toMetricDataResult(t *testing.T) {
 				{ID: "metric-2", Datapoint: nil, Timestamp: time.Time{}},
 			},
 		},
+		{
+			name: "partial periods skipped",
+			getMetricDataOutput: cloudwatch.GetMetricDataOutput{
+				MetricDataResults: []*cloudwatch.MetricDataResult{
+					{
+						Id:         aws.String("metric-1"),
+						Values:     []*float64{aws.Float64(1.0), aws.Float64(2.0), aws.Float64(3.0)},
+						Timestamps: []*time.Time{aws.Time(ts.Add(10 * time.Minute)), aws.Time(ts.Add(5 * time.Minute)), aws.Time(ts)},
+					},
+					{
+						Id:         aws.String("metric-2"),
+						Values:     []*float64{aws.Float64(2.0)},
+						Timestamps: []*time.Time{aws.Time(ts.Add(10 * time.Minute))},
+					},
+				},
+			},
+			latestCompletePeriods: map[string]time.Time{
+				"metric-1": ts.Add(5 * time.Minute),
+				"metric-2": ts.Add(5 * time.Minute),
+			},
+			expectedMetricDataResults: []cloudwatch_client.MetricDataResult{
+				{ID: "metric-1", Datapoint: aws.Float64(2.0), Timestamp: ts.Add(5 * time.Minute)},
+				{ID: "metric-2", Datapoint: nil, Timestamp: time.Time{}},
+			},
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			metricDataResults := toMetricDataResult(tc.getMetricDataOutput, false)
+			metricDataResults := toMetricDataResult(tc.getMetricDataOutput, false, tc.latestCompletePeriods)
 			require.Equal(t, tc.expectedMetricDataResults, metricDataResults)
 		})
 	}
@@ -21,18 +21,30 @@ func createGetMetricDataInput(getMetricData []*model.CloudwatchData, namespace *
 		if data.Period < roundingPeriod {
 			roundingPeriod = data.Period
 		}
-		metricStat := &cloudwatch.MetricStat{
-			Metric: &cloudwatch.Metric{
-				Dimensions: toCloudWatchDimensions(data.Dimensions),
-				MetricName: data.Metric,
-				Namespace:  namespace,
-			},
-			Period: &data.Period,
-			Stat:   &data.Statistics[0],
+		metricStat := func(stat string) *cloudwatch.MetricStat {
+			return &cloudwatch.MetricStat{
+				Metric: &cloudwatch.Metric{
+					Dimensions: toCloudWatchDimensions(data.Dimensions),
+					MetricName: data.Metric,
+					Namespace:  namespace,
+				},
+				Period: &data.Period,
+				Stat:   aws.String(stat),
+			}
+		}
+		if data.Statistics[0] == model.StatisticWeightedAverage {
+			// The Sum and SampleCount are queried without being returned, for the expression dividing them
+			sumID, countID, expression := cloudwatch_client.WeightedAverageQueries(*data.MetricID)
+			metricsDataQuery = append(metricsDataQuery,
+				&cloudwatch.MetricDataQuery{Id: aws.String(sumID), MetricStat: metricStat("Sum"), ReturnData: aws.Bool(false)},
+				&cloudwatch.MetricDataQuery{Id: aws.String(countID), MetricStat: metricStat("SampleCount"), ReturnData: aws.Bool(false)},
+				&cloudwatch.MetricDataQuery{Id: data.MetricID, Expression: aws.String(expression), ReturnData: aws.Bool(true)},
+			)
+			continue
 		}
 		metricsDataQuery = append(metricsDataQuery, &cloudwatch.MetricDataQuery{
 			Id:         data.MetricID,
-			MetricStat: metricStat,
+			MetricStat: metricStat(data.Statistics[0]),
 			ReturnData: aws.Bool(true),
 		})
 	}
@@ -79,7 +91,7 @@ func createGetMetricStatisticsInput(dimensions []*model.Dimension, namespace *st
 
 	var statistics []*string
 	var extendedStatistics []*string
-	for _, statistic := range metric.Statistics {
+	for _, statistic := range cloudwatch_client.GetMetricStatisticsStatistics(metric.Statistics) {
 		if promutil.Percentile.MatchString(statistic) {
 			extendedStatistics = append(extendedStatistics, aws.String(statistic))
 		} else {
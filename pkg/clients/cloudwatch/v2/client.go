@@ -2,6 +2,7 @@ package v2
 
 import (
 	"context"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
@@ -26,9 +27,12 @@ func NewClient(logger logging.Logger, cloudwatchAPI *cloudwatch.Client) cloudwat
 }
 
 func (c client) ListMetrics(ctx context.Context, namespace string, metric *model.MetricConfig, recentlyActiveOnly bool, fn func(page []*model.Metric)) error {
-	filter := &cloudwatch.ListMetricsInput{
-		MetricName: aws.String(metric.Name),
-		Namespace:  aws.String(namespace),
+	filter := &cloudwatch.ListMetricsInput{}
+	if namespace != "" {
+		filter.Namespace = aws.String(namespace)
+	}
+	if metric.Name != "" {
+		filter.MetricName = aws.String(metric.Name)
 	}
 	if recentlyActiveOnly {
 		filter.RecentlyActive = types.RecentlyActivePt3h
@@ -43,6 +47,7 @@ func (c client) ListMetrics(ctx context.Context, namespace string, metric *model
 	})
 
 	for paginator.HasMorePages() {
+		cloudwatch_client.WaitForRequest(ctx, cloudwatch_client.ListMetricsCall)
 		promutil.CloudwatchAPICounter.Inc()
 		page, err := paginator.NextPage(ctx)
 		if err != nil {
@@ -91,47 +96,84 @@ func (c client) GetMetricData(ctx context.Context, logger logging.Logger, getMet
 	filter := createGetMetricDataInput(logger, getMetricData, &namespace, length, delay, configuredRoundingPeriod)
 	var resp cloudwatch.GetMetricDataOutput
 
-	if c.logger.IsDebugEnabled() {
-		c.logger.Debug("GetMetricData", "input", filter)
+	windows := cloudwatch_client.SplitGetMetricDataWindow(*filter.StartTime, *filter.EndTime, cloudwatch_client.ShortestPeriod(getMetricData), len(filter.MetricDataQueries))
+	if len(windows) > 1 {
+		logger.Debug("GetMetricData window split", "windows", len(windows))
 	}
+	for _, window := range windows {
+		input := *filter
+		input.StartTime, input.EndTime = aws.Time(window.StartTime), aws.Time(window.EndTime)
 
-	paginator := cloudwatch.NewGetMetricDataPaginator(c.cloudwatchAPI, filter, func(options *cloudwatch.GetMetricDataPaginatorOptions) {
-		options.StopOnDuplicateToken = true
-	})
-	for paginator.HasMorePages() {
-		promutil.CloudwatchAPICounter.Inc()
-		promutil.CloudwatchGetMetricDataAPICounter.Inc()
+		if c.logger.IsDebugEnabled() {
+			c.logger.Debug("GetMetricData", "input", input)
+		}
 
-		page, err := paginator.NextPage(ctx)
-		if err != nil {
-			c.logger.Error(err, "GetMetricData error")
-			return nil
+		paginator := cloudwatch.NewGetMetricDataPaginator(c.cloudwatchAPI, &input, func(options *cloudwatch.GetMetricDataPaginatorOptions) {
+			options.StopOnDuplicateToken = true
+		})
+		for paginator.HasMorePages() {
+			cloudwatch_client.WaitForRequest(ctx, cloudwatch_client.GetMetricDataCall)
+			promutil.CloudwatchAPICounter.Inc()
+			promutil.CloudwatchGetMetricDataAPICounter.Inc()
+
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				c.logger.Error(err, "GetMetricData error")
+				return nil
+			}
+			resp.MetricDataResults = append(resp.MetricDataResults, page.MetricDataResults...)
+			promutil.CloudwatchGetMetricDataAPIMetricsCounter.Add(float64(len(page.MetricDataResults)))
 		}
-		resp.MetricDataResults = append(resp.MetricDataResults, page.MetricDataResults...)
-		promutil.CloudwatchGetMetricDataAPIMetricsCounter.Add(float64(len(page.MetricDataResults)))
 	}
+	resp.MetricDataResults = mergeMetricDataResults(resp.MetricDataResults)
 
 	if c.logger.IsDebugEnabled() {
 		c.logger.Debug("GetMetricData", "output", resp)
 	}
 
-	return toMetricDataResult(resp, addHistoricalMetrics)
+	return toMetricDataResult(resp, addHistoricalMetrics, cloudwatch_client.LatestCompletePeriods(getMetricData, *filter.EndTime, time.Now()))
 }
 
-func toMetricDataResult(resp cloudwatch.GetMetricDataOutput, addHistoricalMetrics bool) []cloudwatch_client.MetricDataResult {
+// mergeMetricDataResults merges the results of the same query returned by the pages
+// and windows of the calls, the latest first, keeping the datapoints sorted by
+// descending timestamp.
+func mergeMetricDataResults(results []types.MetricDataResult) []types.MetricDataResult {
+	merged := make([]types.MetricDataResult, 0, len(results))
+	indexes := make(map[string]int, len(results))
+	for _, result := range results {
+		i, ok := indexes[*result.Id]
+		if !ok {
+			indexes[*result.Id] = len(merged)
+			merged = append(merged, result)
+			continue
+		}
+		merged[i].Values = append(merged[i].Values, result.Values...)
+		merged[i].Timestamps = append(merged[i].Timestamps, result.Timestamps...)
+	}
+	return merged
+}
+
+// toMetricDataResult maps the datapoints of the response, sorted by descending
+// timestamp, skipping the partial periods of the metrics in latestCompletePeriods.
+func toMetricDataResult(resp cloudwatch.GetMetricDataOutput, addHistoricalMetrics bool, latestCompletePeriods map[string]time.Time) []cloudwatch_client.MetricDataResult {
 	output := make([]cloudwatch_client.MetricDataResult, 0, len(resp.MetricDataResults))
 	for _, metricDataResult := range resp.MetricDataResults {
 		mappedResult := cloudwatch_client.MetricDataResult{ID: *metricDataResult.Id}
-		if len(metricDataResult.Values) > 0 {
-			for i := 0; i < len(metricDataResult.Values); i++ {
-				mappedResult.Datapoint = &metricDataResult.Values[i]
-				mappedResult.Timestamp = metricDataResult.Timestamps[i]
-				output = append(output, mappedResult)
-				if !addHistoricalMetrics {
-					break
-				}
+		latestComplete, completeOnly := latestCompletePeriods[mappedResult.ID]
+		mapped := false
+		for i := 0; i < len(metricDataResult.Values); i++ {
+			if completeOnly && metricDataResult.Timestamps[i].After(latestComplete) {
+				continue
+			}
+			mappedResult.Datapoint = &metricDataResult.Values[i]
+			mappedResult.Timestamp = metricDataResult.Timestamps[i]
+			output = append(output, mappedResult)
+			mapped = true
+			if !addHistoricalMetrics {
+				break
 			}
-		} else {
+		}
+		if !mapped {
 			output = append(output, mappedResult)
 		}
 	}
@@ -144,6 +186,7 @@ func (c client) GetMetricStatistics(ctx context.Context, logger logging.Logger,
 		c.logger.Debug("GetMetricStatistics", "input", filter)
 	}
 
+	cloudwatch_client.WaitForRequest(ctx, cloudwatch_client.GetMetricStatisticsCall)
 	resp, err := c.cloudwatchAPI.GetMetricStatistics(ctx, filter)
 
 	if c.logger.IsDebugEnabled() {
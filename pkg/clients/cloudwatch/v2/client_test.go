@@ -19,6 +19,7 @@ func Test_toMetricDataResult(t *testing.T) {
 	type testCase struct {
 		name                      string
 		getMetricDataOutput       cloudwatch.GetMetricDataOutput
+		latestCompletePeriods     map[string]time.Time
 		expectedMetricDataResults []cloudwatch_client.MetricDataResult
 	}
 
@@ -65,12 +66,53 @@ func Test_toMetricDataResult(t *testing.T) {
 				{ID: "metric-2", Datapoint: nil, Timestamp: time.Time{}},
 			},
 		},
+		{
+			name: "partial periods skipped",
+			getMetricDataOutput: cloudwatch.GetMetricDataOutput{
+				MetricDataResults: []types.MetricDataResult{
+					{
+						Id:         aws.String("metric-1"),
+						Values:     []float64{1.0, 2.0, 3.0},
+						Timestamps: []time.Time{ts.Add(10 * time.Minute), ts.Add(5 * time.Minute), ts},
+					},
+					{
+						Id:         aws.String("metric-2"),
+						Values:     []float64{2.0},
+						Timestamps: []time.Time{ts.Add(10 * time.Minute)},
+					},
+				},
+			},
+			latestCompletePeriods: map[string]time.Time{
+				"metric-1": ts.Add(5 * time.Minute),
+				"metric-2": ts.Add(5 * time.Minute),
+			},
+			expectedMetricDataResults: []cloudwatch_client.MetricDataResult{
+				{ID: "metric-1", Datapoint: aws.Float64(2.0), Timestamp: ts.Add(5 * time.Minute)},
+				{ID: "metric-2", Datapoint: nil, Timestamp: time.Time{}},
+			},
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			metricDataResults := toMetricDataResult(tc.getMetricDataOutput, false)
+			metricDataResults := toMetricDataResult(tc.getMetricDataOutput, false, tc.latestCompletePeriods)
 			require.Equal(t, tc.expectedMetricDataResults, metricDataResults)
 		})
 	}
 }
+
+func Test_mergeMetricDataResults(t *testing.T) {
+	ts := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	// The results of the latest window come first
+	merged := mergeMetricDataResults([]types.MetricDataResult{
+		{Id: aws.String("metric-1"), Values: []float64{3.0}, Timestamps: []time.Time{ts.Add(10 * time.Minute)}},
+		{Id: aws.String("metric-2"), Values: []float64{}, Timestamps: []time.Time{}},
+		{Id: aws.String("metric-1"), Values: []float64{2.0, 1.0}, Timestamps: []time.Time{ts.Add(5 * time.Minute), ts}},
+		{Id: aws.String("metric-2"), Values: []float64{4.0}, Timestamps: []time.Time{ts}},
+	})
+	require.Equal(t, []types.MetricDataResult{
+		{Id: aws.String("metric-1"), Values: []float64{3.0, 2.0, 1.0}, Timestamps: []time.Time{ts.Add(10 * time.Minute), ts.Add(5 * time.Minute), ts}},
+		{Id: aws.String("metric-2"), Values: []float64{4.0}, Timestamps: []time.Time{ts}},
+	}, merged)
+}
@@ -22,18 +22,30 @@ func createGetMetricDataInput(logger logging.Logger, getMetricData []*model.Clou
 		if data.Period < roundingPeriod {
 			roundingPeriod = data.Period
 		}
-		metricStat := &types.MetricStat{
-			Metric: &types.Metric{
-				Dimensions: toCloudWatchDimensions(data.Dimensions),
-				MetricName: data.Metric,
-				Namespace:  namespace,
-			},
-			Period: aws.Int32(int32(data.Period)),
-			Stat:   &data.Statistics[0],
+		metricStat := func(stat string) *types.MetricStat {
+			return &types.MetricStat{
+				Metric: &types.Metric{
+					Dimensions: toCloudWatchDimensions(data.Dimensions),
+					MetricName: data.Metric,
+					Namespace:  namespace,
+				},
+				Period: aws.Int32(int32(data.Period)),
+				Stat:   aws.String(stat),
+			}
+		}
+		if data.Statistics[0] == model.StatisticWeightedAverage {
+			// The Sum and SampleCount are queried without being returned, for the expression dividing them
+			sumID, countID, expression := cloudwatch_client.WeightedAverageQueries(*data.MetricID)
+			metricsDataQuery = append(metricsDataQuery,
+				types.MetricDataQuery{Id: aws.String(sumID), MetricStat: metricStat("Sum"), ReturnData: aws.Bool(false)},
+				types.MetricDataQuery{Id: aws.String(countID), MetricStat: metricStat("SampleCount"), ReturnData: aws.Bool(false)},
+				types.MetricDataQuery{Id: data.MetricID, Expression: aws.String(expression), ReturnData: aws.Bool(true)},
+			)
+			continue
 		}
 		metricsDataQuery = append(metricsDataQuery, types.MetricDataQuery{
 			Id:         data.MetricID,
-			MetricStat: metricStat,
+			MetricStat: metricStat(data.Statistics[0]),
 			ReturnData: aws.Bool(true),
 		})
 	}
@@ -80,7 +92,7 @@ func createGetMetricStatisticsInput(logger logging.Logger, dimensions []*model.D
 
 	var statistics []types.Statistic
 	var extendedStatistics []string
-	for _, statistic := range metric.Statistics {
+	for _, statistic := range cloudwatch_client.GetMetricStatisticsStatistics(metric.Statistics) {
 		if promutil.Percentile.MatchString(statistic) {
 			extendedStatistics = append(extendedStatistics, statistic)
 		} else {
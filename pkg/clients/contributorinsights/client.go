@@ -0,0 +1,77 @@
+package contributorinsights
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+type Client interface {
+	// GetInsightRuleReport returns the report of a Contributor Insights rule
+	// over the last Length of time, with the top contributors of the job.
+	GetInsightRuleReport(ctx context.Context, ruleName string, job model.ContributorInsightsJob) (*model.ContributorInsightsReport, error)
+}
+
+// Cache caches the rule reports of the jobs for their refresh interval.
+// It is safe for concurrent use.
+type Cache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	report    *model.ContributorInsightsReport
+	expiresAt time.Time
+}
+
+func NewCache() *Cache {
+	return &Cache{
+		entries: map[string]cacheEntry{},
+	}
+}
+
+type cachingClient struct {
+	client Client
+	cache  *Cache
+	role   model.Role
+	region string
+}
+
+// NewCachingClient returns a Client which only retrieves the report
+// of a rule once per refresh interval. Failed requests are not cached.
+func NewCachingClient(client Client, cache *Cache, role model.Role, region string) Client {
+	return &cachingClient{
+		client: client,
+		cache:  cache,
+		role:   role,
+		region: region,
+	}
+}
+
+func (c cachingClient) GetInsightRuleReport(ctx context.Context, ruleName string, job model.ContributorInsightsJob) (*model.ContributorInsightsReport, error) {
+	// The same rule may be reported by several jobs with different settings
+	key := fmt.Sprintf("%s|%s|%s|%s|%s|%d|%s", c.role.RoleArn, c.role.ExternalID, c.region, ruleName, job.Length, job.MaxContributors, job.OrderBy)
+
+	c.cache.mu.Lock()
+	entry, ok := c.cache.entries[key]
+	c.cache.mu.Unlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.report, nil
+	}
+
+	report, err := c.client.GetInsightRuleReport(ctx, ruleName, job)
+	if err != nil {
+		return nil, err
+	}
+
+	c.cache.mu.Lock()
+	c.cache.entries[key] = cacheEntry{
+		report:    report,
+		expiresAt: time.Now().Add(job.RefreshInterval),
+	}
+	c.cache.mu.Unlock()
+	return report, nil
+}
@@ -0,0 +1,64 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/contributorinsights"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/promutil"
+)
+
+type client struct {
+	logger        logging.Logger
+	cloudwatchAPI cloudwatchiface.CloudWatchAPI
+}
+
+func NewClient(logger logging.Logger, cloudwatchAPI cloudwatchiface.CloudWatchAPI) contributorinsights.Client {
+	return &client{
+		logger:        logger,
+		cloudwatchAPI: cloudwatchAPI,
+	}
+}
+
+func (c client) GetInsightRuleReport(ctx context.Context, ruleName string, job model.ContributorInsightsJob) (*model.ContributorInsightsReport, error) {
+	endTime := time.Now()
+	input := &cloudwatch.GetInsightRuleReportInput{
+		RuleName:            aws.String(ruleName),
+		StartTime:           aws.Time(endTime.Add(-job.Length)),
+		EndTime:             aws.Time(endTime),
+		Period:              aws.Int64(int64(job.Length.Seconds())),
+		MaxContributorCount: aws.Int64(job.MaxContributors),
+	}
+	if job.OrderBy != "" {
+		input.OrderBy = aws.String(job.OrderBy)
+	}
+
+	promutil.CloudwatchGetInsightRuleReportAPICounter.Inc()
+	output, err := c.cloudwatchAPI.GetInsightRuleReportWithContext(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("error calling cloudwatchAPI.GetInsightRuleReport, %w", err)
+	}
+
+	report := &model.ContributorInsightsReport{
+		RuleName:             ruleName,
+		KeyLabels:            aws.StringValueSlice(output.KeyLabels),
+		AggregationStatistic: aws.StringValue(output.AggregationStatistic),
+		AggregateValue:       aws.Float64Value(output.AggregateValue),
+		UniqueContributors:   aws.Int64Value(output.ApproximateUniqueCount),
+		Contributors:         make([]model.ContributorInsightsContributor, 0, len(output.Contributors)),
+	}
+	for _, contributor := range output.Contributors {
+		report.Contributors = append(report.Contributors, model.ContributorInsightsContributor{
+			Keys:  aws.StringValueSlice(contributor.Keys),
+			Value: aws.Float64Value(contributor.ApproximateAggregateValue),
+		})
+	}
+	return report, nil
+}
@@ -0,0 +1,63 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/contributorinsights"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/promutil"
+)
+
+type client struct {
+	logger        logging.Logger
+	cloudwatchAPI *cloudwatch.Client
+}
+
+func NewClient(logger logging.Logger, cloudwatchAPI *cloudwatch.Client) contributorinsights.Client {
+	return &client{
+		logger:        logger,
+		cloudwatchAPI: cloudwatchAPI,
+	}
+}
+
+func (c client) GetInsightRuleReport(ctx context.Context, ruleName string, job model.ContributorInsightsJob) (*model.ContributorInsightsReport, error) {
+	endTime := time.Now()
+	input := &cloudwatch.GetInsightRuleReportInput{
+		RuleName:            aws.String(ruleName),
+		StartTime:           aws.Time(endTime.Add(-job.Length)),
+		EndTime:             aws.Time(endTime),
+		Period:              aws.Int32(int32(job.Length.Seconds())),
+		MaxContributorCount: aws.Int32(int32(job.MaxContributors)),
+	}
+	if job.OrderBy != "" {
+		input.OrderBy = aws.String(job.OrderBy)
+	}
+
+	promutil.CloudwatchGetInsightRuleReportAPICounter.Inc()
+	output, err := c.cloudwatchAPI.GetInsightRuleReport(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("error calling cloudwatchAPI.GetInsightRuleReport, %w", err)
+	}
+
+	report := &model.ContributorInsightsReport{
+		RuleName:             ruleName,
+		KeyLabels:            output.KeyLabels,
+		AggregationStatistic: aws.ToString(output.AggregationStatistic),
+		AggregateValue:       aws.ToFloat64(output.AggregateValue),
+		UniqueContributors:   aws.ToInt64(output.ApproximateUniqueCount),
+		Contributors:         make([]model.ContributorInsightsContributor, 0, len(output.Contributors)),
+	}
+	for _, contributor := range output.Contributors {
+		report.Contributors = append(report.Contributors, model.ContributorInsightsContributor{
+			Keys:  contributor.Keys,
+			Value: aws.ToFloat64(contributor.ApproximateAggregateValue),
+		})
+	}
+	return report, nil
+}
@@ -0,0 +1,120 @@
+package costexplorer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+// Granularity of the costs, the cost of the previous day is returned.
+const Granularity = "DAILY"
+
+const dateFormat = "2006-01-02"
+
+// Cost is the cost of a day for a metric (e.g. UnblendedCost) and a group.
+type Cost struct {
+	Start  time.Time
+	Metric string
+	Groups []model.Tag
+	Amount float64
+	Unit   string
+}
+
+type Client interface {
+	// GetCostAndUsage returns the costs of the previous, complete, day.
+	GetCostAndUsage(ctx context.Context, job model.CostExplorerJob) ([]Cost, error)
+}
+
+// TimePeriod returns the start (inclusive) and end (exclusive) dates
+// to query for the previous day, in UTC like the Cost Explorer API.
+func TimePeriod(now time.Time) (string, string) {
+	end := now.UTC()
+	start := end.AddDate(0, 0, -1)
+	return start.Format(dateFormat), end.Format(dateFormat)
+}
+
+// ParseDate parses a date returned by the Cost Explorer API.
+func ParseDate(date string) (time.Time, error) {
+	return time.Parse(dateFormat, date)
+}
+
+// GroupTags returns the groups of a result as tags, keyed by the group by keys.
+// Tag and cost category values are returned as "<key>$<value>", the prefix is removed.
+func GroupTags(groupBy []model.CostExplorerGroupBy, keys []string) []model.Tag {
+	tags := make([]model.Tag, 0, len(groupBy))
+	for i, g := range groupBy {
+		if i >= len(keys) {
+			break
+		}
+		value := keys[i]
+		if g.Type != model.CostExplorerGroupByDimension {
+			value = strings.TrimPrefix(value, g.Key+"$")
+		}
+		tags = append(tags, model.Tag{Key: g.Key, Value: value})
+	}
+	return tags
+}
+
+// Cache caches the costs of the jobs, the Cost Explorer API is billed per request
+// and costs are only updated a few times a day. It is safe for concurrent use.
+type Cache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	costs     []Cost
+	expiresAt time.Time
+}
+
+func NewCache() *Cache {
+	return &Cache{
+		entries: map[string]cacheEntry{},
+	}
+}
+
+type cachingClient struct {
+	client Client
+	cache  *Cache
+	role   model.Role
+}
+
+// NewCachingClient returns a Client which only calls the Cost Explorer API
+// once per refresh interval of the job, and once a new day is complete.
+// Failed calls are not cached.
+func NewCachingClient(client Client, cache *Cache, role model.Role) Client {
+	return &cachingClient{
+		client: client,
+		cache:  cache,
+		role:   role,
+	}
+}
+
+func (c cachingClient) GetCostAndUsage(ctx context.Context, job model.CostExplorerJob) ([]Cost, error) {
+	start, _ := TimePeriod(time.Now())
+	key := fmt.Sprintf("%s|%s|%s|%s", c.role.RoleArn, c.role.ExternalID, job.Name, start)
+
+	c.cache.mu.Lock()
+	entry, ok := c.cache.entries[key]
+	c.cache.mu.Unlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.costs, nil
+	}
+
+	costs, err := c.client.GetCostAndUsage(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+
+	c.cache.mu.Lock()
+	c.cache.entries[key] = cacheEntry{
+		costs:     costs,
+		expiresAt: time.Now().Add(job.RefreshInterval),
+	}
+	c.cache.mu.Unlock()
+	return costs, nil
+}
@@ -0,0 +1,63 @@
+package costexplorer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+func TestTimePeriod(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 1, 30, 0, 0, time.FixedZone("UTC+2", 2*60*60))
+	start, end := TimePeriod(now)
+	require.Equal(t, "2024-02-28", start)
+	require.Equal(t, "2024-02-29", end)
+}
+
+func TestGroupTags(t *testing.T) {
+	groupBy := []model.CostExplorerGroupBy{
+		{Type: model.CostExplorerGroupByDimension, Key: "SERVICE"},
+		{Type: model.CostExplorerGroupByTag, Key: "Team"},
+	}
+	tags := GroupTags(groupBy, []string{"Amazon Simple Queue Service", "Team$data"})
+	require.Equal(t, []model.Tag{
+		{Key: "SERVICE", Value: "Amazon Simple Queue Service"},
+		{Key: "Team", Value: "data"},
+	}, tags)
+
+	// Resources without the tag are returned with an empty value
+	tags = GroupTags(groupBy, []string{"AWS Lambda", "Team$"})
+	require.Equal(t, "", tags[1].Value)
+}
+
+type countingClient struct {
+	calls int
+}
+
+func (c *countingClient) GetCostAndUsage(_ context.Context, _ model.CostExplorerJob) ([]Cost, error) {
+	c.calls++
+	return []Cost{{Metric: "UnblendedCost", Amount: 42, Unit: "USD"}}, nil
+}
+
+func TestCachingClient(t *testing.T) {
+	ctx := context.Background()
+	underlying := &countingClient{}
+	cache := NewCache()
+	job := model.CostExplorerJob{Name: "daily", RefreshInterval: time.Hour}
+
+	costs, err := NewCachingClient(underlying, cache, model.Role{}).GetCostAndUsage(ctx, job)
+	require.NoError(t, err)
+	require.Len(t, costs, 1)
+
+	// A new client, as created on every scrape, uses the same cache
+	_, err = NewCachingClient(underlying, cache, model.Role{}).GetCostAndUsage(ctx, job)
+	require.NoError(t, err)
+	require.Equal(t, 1, underlying.calls)
+
+	_, err = NewCachingClient(underlying, cache, model.Role{RoleArn: "arn:aws:iam::123456789012:role/other"}).GetCostAndUsage(ctx, job)
+	require.NoError(t, err)
+	require.Equal(t, 2, underlying.calls, "costs of another role should not be served from the cache")
+}
@@ -0,0 +1,102 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/costexplorer"
+	"github.com/aws/aws-sdk-go/service/costexplorer/costexploreriface"
+
+	costexplorer_client "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/costexplorer"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/promutil"
+)
+
+type client struct {
+	logger          logging.Logger
+	costExplorerAPI costexploreriface.CostExplorerAPI
+}
+
+func NewClient(logger logging.Logger, costExplorerAPI costexploreriface.CostExplorerAPI) costexplorer_client.Client {
+	return &client{
+		logger:          logger,
+		costExplorerAPI: costExplorerAPI,
+	}
+}
+
+func (c client) GetCostAndUsage(ctx context.Context, job model.CostExplorerJob) ([]costexplorer_client.Cost, error) {
+	start, end := costexplorer_client.TimePeriod(time.Now())
+	input := &costexplorer.GetCostAndUsageInput{
+		Granularity: aws.String(costexplorer_client.Granularity),
+		Metrics:     aws.StringSlice(job.Metrics),
+		TimePeriod: &costexplorer.DateInterval{
+			Start: aws.String(start),
+			End:   aws.String(end),
+		},
+	}
+	for _, g := range job.GroupBy {
+		input.GroupBy = append(input.GroupBy, &costexplorer.GroupDefinition{
+			Type: aws.String(g.Type),
+			Key:  aws.String(g.Key),
+		})
+	}
+
+	var costs []costexplorer_client.Cost
+	pageNum := 0
+	for pageNum < 100 {
+		pageNum++
+		promutil.CostExplorerAPICounter.Inc()
+		output, err := c.costExplorerAPI.GetCostAndUsageWithContext(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("error calling costExplorerAPI.GetCostAndUsage, %w", err)
+		}
+
+		for _, result := range output.ResultsByTime {
+			if result.TimePeriod == nil {
+				continue
+			}
+			day, err := costexplorer_client.ParseDate(aws.StringValue(result.TimePeriod.Start))
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse cost explorer date, %w", err)
+			}
+			if len(job.GroupBy) == 0 {
+				costs = append(costs, toCosts(c.logger, day, nil, result.Total)...)
+				continue
+			}
+			for _, group := range result.Groups {
+				groups := costexplorer_client.GroupTags(job.GroupBy, aws.StringValueSlice(group.Keys))
+				costs = append(costs, toCosts(c.logger, day, groups, group.Metrics)...)
+			}
+		}
+
+		if output.NextPageToken == nil {
+			break
+		}
+		input.NextPageToken = output.NextPageToken
+	}
+
+	return costs, nil
+}
+
+func toCosts(logger logging.Logger, day time.Time, groups []model.Tag, metrics map[string]*costexplorer.MetricValue) []costexplorer_client.Cost {
+	costs := make([]costexplorer_client.Cost, 0, len(metrics))
+	for metric, value := range metrics {
+		amount, err := strconv.ParseFloat(aws.StringValue(value.Amount), 64)
+		if err != nil {
+			logger.Warn("Failed to parse cost amount", "metric", metric, "amount", aws.StringValue(value.Amount))
+			continue
+		}
+		costs = append(costs, costexplorer_client.Cost{
+			Start:  day,
+			Metric: metric,
+			Groups: groups,
+			Amount: amount,
+			Unit:   aws.StringValue(value.Unit),
+		})
+	}
+	return costs
+}
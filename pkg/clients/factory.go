@@ -3,7 +3,12 @@ package clients
 import (
 	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/account"
 	cloudwatch_client "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/cloudwatch"
+	contributorinsights_client "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/contributorinsights"
+	costexplorer_client "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/costexplorer"
+	logsinsights_client "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/logsinsights"
+	servicequotas_client "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/servicequotas"
 	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/tagging"
+	trustedadvisor_client "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/trustedadvisor"
 	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
 )
 
@@ -13,4 +18,9 @@ type Factory interface {
 	GetCloudwatchClient(region string, role model.Role, concurrency cloudwatch_client.ConcurrencyConfig) cloudwatch_client.Client
 	GetTaggingClient(region string, role model.Role, concurrencyLimit int) tagging.Client
 	GetAccountClient(region string, role model.Role) account.Client
+	GetCostExplorerClient(region string, role model.Role) costexplorer_client.Client
+	GetServiceQuotasClient(region string, role model.Role) servicequotas_client.Client
+	GetTrustedAdvisorClient(region string, role model.Role) trustedadvisor_client.Client
+	GetLogsInsightsClient(region string, role model.Role) logsinsights_client.Client
+	GetContributorInsightsClient(region string, role model.Role) contributorinsights_client.Client
 }
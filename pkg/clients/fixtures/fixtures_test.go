@@ -0,0 +1,87 @@
+package fixtures
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/cloudwatch"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/tagging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+type testFactory struct {
+	clients.Factory
+	cloudwatchClient cloudwatch.Client
+	taggingClient    tagging.Client
+}
+
+func (f testFactory) GetCloudwatchClient(string, model.Role, cloudwatch.ConcurrencyConfig) cloudwatch.Client {
+	return f.cloudwatchClient
+}
+
+func (f testFactory) GetTaggingClient(string, model.Role, int) tagging.Client {
+	return f.taggingClient
+}
+
+func (f testFactory) Refresh() {}
+
+func (f testFactory) Clear() {}
+
+type testCloudwatchClient struct {
+	cloudwatch.Client
+	metrics []*model.Metric
+}
+
+func (c testCloudwatchClient) ListMetrics(_ context.Context, _ string, _ *model.MetricConfig, _ bool, fn func(page []*model.Metric)) error {
+	fn(c.metrics[:1])
+	fn(c.metrics[1:])
+	return nil
+}
+
+type testTaggingClient struct {
+	err error
+}
+
+func (c testTaggingClient) GetResources(context.Context, model.DiscoveryJob, string) ([]*model.TaggedResource, error) {
+	return nil, c.err
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	role := model.Role{RoleArn: "arn:aws:iam::123456789012:role/yace"}
+	metric := &model.MetricConfig{Name: "CPUUtilization"}
+	metrics := []*model.Metric{
+		{Namespace: "AWS/EC2", MetricName: "CPUUtilization", Dimensions: []*model.Dimension{{Name: "InstanceId", Value: "i-1"}}},
+		{Namespace: "AWS/EC2", MetricName: "CPUUtilization", Dimensions: []*model.Dimension{{Name: "InstanceId", Value: "i-2"}}},
+	}
+
+	recording, err := NewRecordingFactory(logging.NewNopLogger(), testFactory{
+		cloudwatchClient: testCloudwatchClient{metrics: metrics},
+		taggingClient:    testTaggingClient{err: tagging.ErrExpectedToFindResources},
+	}, dir)
+	require.NoError(t, err)
+
+	require.NoError(t, recording.GetCloudwatchClient("eu-west-1", role, cloudwatch.ConcurrencyConfig{}).ListMetrics(ctx, "AWS/EC2", metric, false, func([]*model.Metric) {}))
+	_, err = recording.GetTaggingClient("eu-west-1", role, 1).GetResources(ctx, model.DiscoveryJob{Type: "AWS/EC2"}, "eu-west-1")
+	require.ErrorIs(t, err, tagging.ErrExpectedToFindResources)
+
+	replay, err := NewReplayFactory(dir)
+	require.NoError(t, err)
+
+	var replayed []*model.Metric
+	require.NoError(t, replay.GetCloudwatchClient("eu-west-1", role, cloudwatch.ConcurrencyConfig{}).ListMetrics(ctx, "AWS/EC2", metric, false, func(page []*model.Metric) {
+		replayed = append(replayed, page...)
+	}))
+	require.Equal(t, metrics, replayed)
+
+	_, err = replay.GetTaggingClient("eu-west-1", role, 1).GetResources(ctx, model.DiscoveryJob{Type: "AWS/EC2"}, "eu-west-1")
+	require.ErrorIs(t, err, tagging.ErrExpectedToFindResources)
+
+	err = replay.GetCloudwatchClient("us-east-1", role, cloudwatch.ConcurrencyConfig{}).ListMetrics(ctx, "AWS/EC2", metric, false, nil)
+	require.ErrorIs(t, err, ErrFixtureNotFound)
+}
@@ -0,0 +1,206 @@
+package fixtures
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/account"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/cloudwatch"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/contributorinsights"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/costexplorer"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/logsinsights"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/servicequotas"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/tagging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/trustedadvisor"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+// CachingFactory is a clients.Factory caching its clients, as the v1 and v2 factories.
+type CachingFactory interface {
+	clients.Factory
+	Refresh()
+	Clear()
+}
+
+// RecordingFactory wraps the clients of a factory, recording
+// their responses as fixtures which can be replayed later.
+type RecordingFactory struct {
+	CachingFactory
+	logger logging.Logger
+	store  *store
+}
+
+func NewRecordingFactory(logger logging.Logger, factory CachingFactory, dir string) (*RecordingFactory, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create fixtures directory: %w", err)
+	}
+	return &RecordingFactory{
+		CachingFactory: factory,
+		logger:         logger,
+		store:          newStore(dir),
+	}, nil
+}
+
+func (f *RecordingFactory) recorder(region string, role model.Role) recorder {
+	return recorder{logger: f.logger, store: f.store, region: region, role: role}
+}
+
+func (f *RecordingFactory) GetCloudwatchClient(region string, role model.Role, concurrency cloudwatch.ConcurrencyConfig) cloudwatch.Client {
+	return recordingCloudwatchClient{client: f.CachingFactory.GetCloudwatchClient(region, role, concurrency), recorder: f.recorder(region, role)}
+}
+
+func (f *RecordingFactory) GetTaggingClient(region string, role model.Role, concurrencyLimit int) tagging.Client {
+	return recordingTaggingClient{client: f.CachingFactory.GetTaggingClient(region, role, concurrencyLimit), recorder: f.recorder(region, role)}
+}
+
+func (f *RecordingFactory) GetAccountClient(region string, role model.Role) account.Client {
+	return recordingAccountClient{client: f.CachingFactory.GetAccountClient(region, role), recorder: f.recorder(region, role)}
+}
+
+func (f *RecordingFactory) GetCostExplorerClient(region string, role model.Role) costexplorer.Client {
+	return recordingCostExplorerClient{client: f.CachingFactory.GetCostExplorerClient(region, role), recorder: f.recorder(region, role)}
+}
+
+func (f *RecordingFactory) GetServiceQuotasClient(region string, role model.Role) servicequotas.Client {
+	return recordingServiceQuotasClient{client: f.CachingFactory.GetServiceQuotasClient(region, role), recorder: f.recorder(region, role)}
+}
+
+func (f *RecordingFactory) GetTrustedAdvisorClient(region string, role model.Role) trustedadvisor.Client {
+	return recordingTrustedAdvisorClient{client: f.CachingFactory.GetTrustedAdvisorClient(region, role), recorder: f.recorder(region, role)}
+}
+
+func (f *RecordingFactory) GetLogsInsightsClient(region string, role model.Role) logsinsights.Client {
+	return recordingLogsInsightsClient{client: f.CachingFactory.GetLogsInsightsClient(region, role), recorder: f.recorder(region, role)}
+}
+
+func (f *RecordingFactory) GetContributorInsightsClient(region string, role model.Role) contributorinsights.Client {
+	return recordingContributorInsightsClient{client: f.CachingFactory.GetContributorInsightsClient(region, role), recorder: f.recorder(region, role)}
+}
+
+// recorder writes the fixtures of the clients of a region and role.
+// Failing to record a call does not fail the call itself.
+type recorder struct {
+	logger logging.Logger
+	store  *store
+	region string
+	role   model.Role
+}
+
+func (r recorder) record(call string, request any, response any, err error) {
+	if writeErr := r.store.write(call, r.region, r.role, request, response, err); writeErr != nil {
+		r.logger.Error(writeErr, "Failed to record fixture", "call", call, "region", r.region)
+	}
+}
+
+type recordingCloudwatchClient struct {
+	client cloudwatch.Client
+	recorder
+}
+
+func (c recordingCloudwatchClient) ListMetrics(ctx context.Context, namespace string, metric *model.MetricConfig, recentlyActiveOnly bool, fn func(page []*model.Metric)) error {
+	var mu sync.Mutex
+	metrics := []*model.Metric{}
+	err := c.client.ListMetrics(ctx, namespace, metric, recentlyActiveOnly, func(page []*model.Metric) {
+		mu.Lock()
+		metrics = append(metrics, page...)
+		mu.Unlock()
+		if fn != nil {
+			fn(page)
+		}
+	})
+	c.record(listMetricsCall, newListMetricsRequest(namespace, metric, recentlyActiveOnly), metrics, err)
+	return err
+}
+
+func (c recordingCloudwatchClient) GetMetricData(ctx context.Context, logger logging.Logger, getMetricData []*model.CloudwatchData, namespace string, length int64, delay int64, configuredRoundingPeriod *int64, addHistoricalMetrics bool) []cloudwatch.MetricDataResult {
+	res := c.client.GetMetricData(ctx, logger, getMetricData, namespace, length, delay, configuredRoundingPeriod, addHistoricalMetrics)
+	c.record(getMetricDataCall, newGetMetricDataRequest(getMetricData, namespace, length, delay, configuredRoundingPeriod, addHistoricalMetrics), res, nil)
+	return res
+}
+
+func (c recordingCloudwatchClient) GetMetricStatistics(ctx context.Context, logger logging.Logger, dimensions []*model.Dimension, namespace string, metric *model.MetricConfig) []*model.Datapoint {
+	res := c.client.GetMetricStatistics(ctx, logger, dimensions, namespace, metric)
+	c.record(getMetricStatisticsCall, getMetricStatisticsRequest{Namespace: namespace, Dimensions: dimensions, Metric: metric}, res, nil)
+	return res
+}
+
+type recordingTaggingClient struct {
+	client tagging.Client
+	recorder
+}
+
+func (c recordingTaggingClient) GetResources(ctx context.Context, job model.DiscoveryJob, region string) ([]*model.TaggedResource, error) {
+	res, err := c.client.GetResources(ctx, job, region)
+	c.record(getResourcesCall, newGetResourcesRequest(job), res, err)
+	return res, err
+}
+
+type recordingAccountClient struct {
+	client account.Client
+	recorder
+}
+
+func (c recordingAccountClient) GetAccount(ctx context.Context) (string, error) {
+	res, err := c.client.GetAccount(ctx)
+	c.record(getAccountCall, nil, res, err)
+	return res, err
+}
+
+type recordingCostExplorerClient struct {
+	client costexplorer.Client
+	recorder
+}
+
+func (c recordingCostExplorerClient) GetCostAndUsage(ctx context.Context, job model.CostExplorerJob) ([]costexplorer.Cost, error) {
+	res, err := c.client.GetCostAndUsage(ctx, job)
+	c.record(getCostAndUsageCall, job, res, err)
+	return res, err
+}
+
+type recordingServiceQuotasClient struct {
+	client servicequotas.Client
+	recorder
+}
+
+func (c recordingServiceQuotasClient) ListServiceQuotas(ctx context.Context, serviceCode string) ([]*model.ServiceQuota, error) {
+	res, err := c.client.ListServiceQuotas(ctx, serviceCode)
+	c.record(listServiceQuotasCall, serviceCode, res, err)
+	return res, err
+}
+
+type recordingTrustedAdvisorClient struct {
+	client trustedadvisor.Client
+	recorder
+}
+
+func (c recordingTrustedAdvisorClient) DescribeChecks(ctx context.Context, job model.TrustedAdvisorJob) ([]*model.TrustedAdvisorCheck, error) {
+	res, err := c.client.DescribeChecks(ctx, job)
+	c.record(describeChecksCall, job, res, err)
+	return res, err
+}
+
+type recordingLogsInsightsClient struct {
+	client logsinsights.Client
+	recorder
+}
+
+func (c recordingLogsInsightsClient) RunQuery(ctx context.Context, job model.LogsInsightsJob) ([][]model.Tag, error) {
+	res, err := c.client.RunQuery(ctx, job)
+	c.record(runQueryCall, job, res, err)
+	return res, err
+}
+
+type recordingContributorInsightsClient struct {
+	client contributorinsights.Client
+	recorder
+}
+
+func (c recordingContributorInsightsClient) GetInsightRuleReport(ctx context.Context, ruleName string, job model.ContributorInsightsJob) (*model.ContributorInsightsReport, error) {
+	res, err := c.client.GetInsightRuleReport(ctx, ruleName, job)
+	c.record(getInsightRuleReportCall, getInsightRuleReportRequest{RuleName: ruleName, Job: job}, res, err)
+	return res, err
+}
@@ -0,0 +1,193 @@
+package fixtures
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/account"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/cloudwatch"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/contributorinsights"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/costexplorer"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/logsinsights"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/servicequotas"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/tagging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/trustedadvisor"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+// ReplayFactory produces clients serving the responses recorded by a RecordingFactory,
+// without calling AWS. Calls which were not recorded fail with ErrFixtureNotFound.
+type ReplayFactory struct {
+	store *store
+}
+
+func NewReplayFactory(dir string) (*ReplayFactory, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open fixtures directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("fixtures path %s is not a directory", dir)
+	}
+	return &ReplayFactory{
+		store: newStore(dir),
+	}, nil
+}
+
+// Refresh is a no-op, replayed clients do not hold any credentials.
+func (f *ReplayFactory) Refresh() {}
+
+// Clear is a no-op, replayed clients do not hold any credentials.
+func (f *ReplayFactory) Clear() {}
+
+func (f *ReplayFactory) replayer(region string, role model.Role) replayer {
+	return replayer{store: f.store, region: region, role: role}
+}
+
+func (f *ReplayFactory) GetCloudwatchClient(region string, role model.Role, _ cloudwatch.ConcurrencyConfig) cloudwatch.Client {
+	return replayCloudwatchClient{f.replayer(region, role)}
+}
+
+func (f *ReplayFactory) GetTaggingClient(region string, role model.Role, _ int) tagging.Client {
+	return replayTaggingClient{f.replayer(region, role)}
+}
+
+func (f *ReplayFactory) GetAccountClient(region string, role model.Role) account.Client {
+	return replayAccountClient{f.replayer(region, role)}
+}
+
+func (f *ReplayFactory) GetCostExplorerClient(region string, role model.Role) costexplorer.Client {
+	return replayCostExplorerClient{f.replayer(region, role)}
+}
+
+func (f *ReplayFactory) GetServiceQuotasClient(region string, role model.Role) servicequotas.Client {
+	return replayServiceQuotasClient{f.replayer(region, role)}
+}
+
+func (f *ReplayFactory) GetTrustedAdvisorClient(region string, role model.Role) trustedadvisor.Client {
+	return replayTrustedAdvisorClient{f.replayer(region, role)}
+}
+
+func (f *ReplayFactory) GetLogsInsightsClient(region string, role model.Role) logsinsights.Client {
+	return replayLogsInsightsClient{f.replayer(region, role)}
+}
+
+func (f *ReplayFactory) GetContributorInsightsClient(region string, role model.Role) contributorinsights.Client {
+	return replayContributorInsightsClient{f.replayer(region, role)}
+}
+
+// replayer reads the fixtures of the clients of a region and role.
+type replayer struct {
+	store  *store
+	region string
+	role   model.Role
+}
+
+func (r replayer) replay(call string, request any, response any) error {
+	return r.store.read(call, r.region, r.role, request, response)
+}
+
+type replayCloudwatchClient struct {
+	replayer
+}
+
+func (c replayCloudwatchClient) ListMetrics(_ context.Context, namespace string, metric *model.MetricConfig, recentlyActiveOnly bool, fn func(page []*model.Metric)) error {
+	var metrics []*model.Metric
+	if err := c.replay(listMetricsCall, newListMetricsRequest(namespace, metric, recentlyActiveOnly), &metrics); err != nil {
+		return err
+	}
+	if fn != nil {
+		fn(metrics)
+	}
+	return nil
+}
+
+func (c replayCloudwatchClient) GetMetricData(_ context.Context, logger logging.Logger, getMetricData []*model.CloudwatchData, namespace string, length int64, delay int64, configuredRoundingPeriod *int64, addHistoricalMetrics bool) []cloudwatch.MetricDataResult {
+	var res []cloudwatch.MetricDataResult
+	if err := c.replay(getMetricDataCall, newGetMetricDataRequest(getMetricData, namespace, length, delay, configuredRoundingPeriod, addHistoricalMetrics), &res); err != nil {
+		logger.Error(err, "Failed to replay GetMetricData", "namespace", namespace)
+		return nil
+	}
+	return res
+}
+
+func (c replayCloudwatchClient) GetMetricStatistics(_ context.Context, logger logging.Logger, dimensions []*model.Dimension, namespace string, metric *model.MetricConfig) []*model.Datapoint {
+	var res []*model.Datapoint
+	if err := c.replay(getMetricStatisticsCall, getMetricStatisticsRequest{Namespace: namespace, Dimensions: dimensions, Metric: metric}, &res); err != nil {
+		logger.Error(err, "Failed to replay GetMetricStatistics", "namespace", namespace)
+		return nil
+	}
+	return res
+}
+
+type replayTaggingClient struct {
+	replayer
+}
+
+func (c replayTaggingClient) GetResources(_ context.Context, job model.DiscoveryJob, _ string) ([]*model.TaggedResource, error) {
+	var res []*model.TaggedResource
+	err := c.replay(getResourcesCall, newGetResourcesRequest(job), &res)
+	return res, err
+}
+
+type replayAccountClient struct {
+	replayer
+}
+
+func (c replayAccountClient) GetAccount(_ context.Context) (string, error) {
+	var res string
+	err := c.replay(getAccountCall, nil, &res)
+	return res, err
+}
+
+type replayCostExplorerClient struct {
+	replayer
+}
+
+func (c replayCostExplorerClient) GetCostAndUsage(_ context.Context, job model.CostExplorerJob) ([]costexplorer.Cost, error) {
+	var res []costexplorer.Cost
+	err := c.replay(getCostAndUsageCall, job, &res)
+	return res, err
+}
+
+type replayServiceQuotasClient struct {
+	replayer
+}
+
+func (c replayServiceQuotasClient) ListServiceQuotas(_ context.Context, serviceCode string) ([]*model.ServiceQuota, error) {
+	var res []*model.ServiceQuota
+	err := c.replay(listServiceQuotasCall, serviceCode, &res)
+	return res, err
+}
+
+type replayTrustedAdvisorClient struct {
+	replayer
+}
+
+func (c replayTrustedAdvisorClient) DescribeChecks(_ context.Context, job model.TrustedAdvisorJob) ([]*model.TrustedAdvisorCheck, error) {
+	var res []*model.TrustedAdvisorCheck
+	err := c.replay(describeChecksCall, job, &res)
+	return res, err
+}
+
+type replayLogsInsightsClient struct {
+	replayer
+}
+
+func (c replayLogsInsightsClient) RunQuery(_ context.Context, job model.LogsInsightsJob) ([][]model.Tag, error) {
+	var res [][]model.Tag
+	err := c.replay(runQueryCall, job, &res)
+	return res, err
+}
+
+type replayContributorInsightsClient struct {
+	replayer
+}
+
+func (c replayContributorInsightsClient) GetInsightRuleReport(_ context.Context, ruleName string, job model.ContributorInsightsJob) (*model.ContributorInsightsReport, error) {
+	var res *model.ContributorInsightsReport
+	err := c.replay(getInsightRuleReportCall, getInsightRuleReportRequest{RuleName: ruleName, Job: job}, &res)
+	return res, err
+}
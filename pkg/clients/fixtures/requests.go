@@ -0,0 +1,108 @@
+package fixtures
+
+import (
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+// The requests identify the recorded calls. They only hold the arguments
+// the responses depend on, and none varying from one scrape to another.
+
+const (
+	getAccountCall           = "GetAccount"
+	listMetricsCall          = "ListMetrics"
+	getMetricDataCall        = "GetMetricData"
+	getMetricStatisticsCall  = "GetMetricStatistics"
+	getResourcesCall         = "GetResources"
+	getCostAndUsageCall      = "GetCostAndUsage"
+	listServiceQuotasCall    = "ListServiceQuotas"
+	describeChecksCall       = "DescribeChecks"
+	runQueryCall             = "RunQuery"
+	getInsightRuleReportCall = "GetInsightRuleReport"
+)
+
+type listMetricsRequest struct {
+	Namespace          string
+	MetricName         string
+	RecentlyActiveOnly bool
+}
+
+func newListMetricsRequest(namespace string, metric *model.MetricConfig, recentlyActiveOnly bool) listMetricsRequest {
+	metricName := ""
+	if metric != nil {
+		metricName = metric.Name
+	}
+	return listMetricsRequest{
+		Namespace:          namespace,
+		MetricName:         metricName,
+		RecentlyActiveOnly: recentlyActiveOnly,
+	}
+}
+
+type getMetricDataRequest struct {
+	Namespace            string
+	Length               int64
+	Delay                int64
+	RoundingPeriod       *int64
+	AddHistoricalMetrics bool
+	Queries              []getMetricDataQuery
+}
+
+type getMetricDataQuery struct {
+	ID         *string
+	MetricName *string
+	Dimensions []*model.Dimension
+	Statistics []string
+	Period     int64
+}
+
+func newGetMetricDataRequest(getMetricData []*model.CloudwatchData, namespace string, length int64, delay int64, configuredRoundingPeriod *int64, addHistoricalMetrics bool) getMetricDataRequest {
+	queries := make([]getMetricDataQuery, 0, len(getMetricData))
+	for _, data := range getMetricData {
+		queries = append(queries, getMetricDataQuery{
+			ID:         data.MetricID,
+			MetricName: data.Metric,
+			Dimensions: data.Dimensions,
+			Statistics: data.Statistics,
+			Period:     data.Period,
+		})
+	}
+	return getMetricDataRequest{
+		Namespace:            namespace,
+		Length:               length,
+		Delay:                delay,
+		RoundingPeriod:       configuredRoundingPeriod,
+		AddHistoricalMetrics: addHistoricalMetrics,
+		Queries:              queries,
+	}
+}
+
+type getMetricStatisticsRequest struct {
+	Namespace  string
+	Dimensions []*model.Dimension
+	Metric     *model.MetricConfig
+}
+
+type getResourcesRequest struct {
+	Type                  string
+	SearchTags            []string
+	AddResourceAttributes bool
+	InfoMetricAttributes  []string
+}
+
+func newGetResourcesRequest(job model.DiscoveryJob) getResourcesRequest {
+	searchTags := make([]string, 0, len(job.SearchTags))
+	for _, tag := range job.SearchTags {
+		searchTags = append(searchTags, tag.Key+"="+tag.Value.String())
+	}
+	return getResourcesRequest{
+		Type:                  job.Type,
+		SearchTags:            searchTags,
+		AddResourceAttributes: job.AddResourceAttributes,
+		InfoMetricAttributes:  job.InfoMetricAttributes,
+	}
+}
+
+type getInsightRuleReportRequest struct {
+	RuleName string
+	Job      model.ContributorInsightsJob
+}
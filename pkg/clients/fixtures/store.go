@@ -0,0 +1,110 @@
+// Package fixtures records the responses of the YACE clients to a directory, and
+// serves scrapes entirely from those fixtures, without AWS credentials. This
+// allows testing configuration changes and the produced metrics offline.
+package fixtures
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/tagging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+// ErrFixtureNotFound is returned when replaying a call which was not recorded.
+var ErrFixtureNotFound = errors.New("fixture not found")
+
+// fixture is the recorded response of a client call.
+type fixture struct {
+	Call     string
+	Request  json.RawMessage
+	Response json.RawMessage
+	Error    string `json:",omitempty"`
+}
+
+// store reads and writes fixtures as JSON files of a directory,
+// named after the call and a hash of its region, role and request.
+type store struct {
+	dir string
+	mu  sync.Mutex
+}
+
+func newStore(dir string) *store {
+	return &store{dir: dir}
+}
+
+func (s *store) path(call string, region string, role model.Role, request []byte) string {
+	h := sha256.New()
+	h.Write([]byte(region))
+	h.Write([]byte(role.RoleArn))
+	h.Write([]byte(role.ExternalID))
+	h.Write(request)
+	return filepath.Join(s.dir, fmt.Sprintf("%s-%s.json", call, hex.EncodeToString(h.Sum(nil))[:16]))
+}
+
+func (s *store) write(call string, region string, role model.Role, request any, response any, callErr error) error {
+	req, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s request: %w", call, err)
+	}
+	resp, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s response: %w", call, err)
+	}
+	f := fixture{Call: call, Request: req, Response: resp}
+	if callErr != nil {
+		f.Error = callErr.Error()
+	}
+	out, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return os.WriteFile(s.path(call, region, role, req), out, 0o600)
+}
+
+// read unmarshals the recorded response of a call into response,
+// and returns the recorded error of the call, if any.
+func (s *store) read(call string, region string, role model.Role, request any, response any) error {
+	req, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s request: %w", call, err)
+	}
+	path := s.path(call, region, role, req)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("%w: %s %s", ErrFixtureNotFound, call, path)
+		}
+		return err
+	}
+	var f fixture
+	if err := json.Unmarshal(content, &f); err != nil {
+		return fmt.Errorf("failed to unmarshal fixture %s: %w", path, err)
+	}
+	if err := json.Unmarshal(f.Response, response); err != nil {
+		return fmt.Errorf("failed to unmarshal fixture %s: %w", path, err)
+	}
+	return recordedError(f.Error)
+}
+
+// recordedError recreates a recorded error, keeping the errors
+// the callers check for with errors.Is.
+func recordedError(msg string) error {
+	switch msg {
+	case "":
+		return nil
+	case tagging.ErrExpectedToFindResources.Error():
+		return tagging.ErrExpectedToFindResources
+	default:
+		return errors.New(msg)
+	}
+}
@@ -0,0 +1,115 @@
+package logsinsights
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+const (
+	// PollInterval is the interval between two GetQueryResults calls.
+	PollInterval = time.Second
+
+	// QueryTimeout is the maximum duration to wait for a query to complete,
+	// the query is stopped after that.
+	QueryTimeout = 2 * time.Minute
+
+	// PtrField is the field added to every result row, identifying the log event.
+	PtrField = "@ptr"
+)
+
+// Query statuses returned by GetQueryResults.
+const (
+	StatusScheduled = "Scheduled"
+	StatusRunning   = "Running"
+	StatusComplete  = "Complete"
+)
+
+type Client interface {
+	// RunQuery runs the query of the job over the last Length of time, waits for it
+	// to complete and returns the result rows, as lists of fields.
+	RunQuery(ctx context.Context, job model.LogsInsightsJob) ([][]model.Tag, error)
+}
+
+// TimeRange returns the start and end of the query window in seconds
+// since the epoch, as expected by StartQuery.
+func TimeRange(now time.Time, length time.Duration) (int64, int64) {
+	return now.Add(-length).Unix(), now.Unix()
+}
+
+// Done returns true once the query is not scheduled or running anymore,
+// and an error if it did not complete (e.g. Failed, Cancelled or Timeout).
+func Done(status string) (bool, error) {
+	switch status {
+	case StatusScheduled, StatusRunning:
+		return false, nil
+	case StatusComplete:
+		return true, nil
+	default:
+		return true, fmt.Errorf("query did not complete, status %s", status)
+	}
+}
+
+// Cache caches the query results of the jobs for their refresh interval,
+// Logs Insights queries are billed per GB of data scanned.
+// It is safe for concurrent use.
+type Cache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	rows      [][]model.Tag
+	expiresAt time.Time
+}
+
+func NewCache() *Cache {
+	return &Cache{
+		entries: map[string]cacheEntry{},
+	}
+}
+
+type cachingClient struct {
+	client Client
+	cache  *Cache
+	role   model.Role
+	region string
+}
+
+// NewCachingClient returns a Client which only runs the query
+// of a job once per refresh interval. Failed queries are not cached.
+func NewCachingClient(client Client, cache *Cache, role model.Role, region string) Client {
+	return &cachingClient{
+		client: client,
+		cache:  cache,
+		role:   role,
+		region: region,
+	}
+}
+
+func (c cachingClient) RunQuery(ctx context.Context, job model.LogsInsightsJob) ([][]model.Tag, error) {
+	key := fmt.Sprintf("%s|%s|%s|%s", c.role.RoleArn, c.role.ExternalID, c.region, job.Name)
+
+	c.cache.mu.Lock()
+	entry, ok := c.cache.entries[key]
+	c.cache.mu.Unlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.rows, nil
+	}
+
+	rows, err := c.client.RunQuery(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+
+	c.cache.mu.Lock()
+	c.cache.entries[key] = cacheEntry{
+		rows:      rows,
+		expiresAt: time.Now().Add(job.RefreshInterval),
+	}
+	c.cache.mu.Unlock()
+	return rows, nil
+}
@@ -0,0 +1,89 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/logsinsights"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/promutil"
+)
+
+type client struct {
+	logger  logging.Logger
+	logsAPI cloudwatchlogsiface.CloudWatchLogsAPI
+}
+
+func NewClient(logger logging.Logger, logsAPI cloudwatchlogsiface.CloudWatchLogsAPI) logsinsights.Client {
+	return &client{
+		logger:  logger,
+		logsAPI: logsAPI,
+	}
+}
+
+func (c client) RunQuery(ctx context.Context, job model.LogsInsightsJob) ([][]model.Tag, error) {
+	start, end := logsinsights.TimeRange(time.Now(), job.Length)
+	promutil.LogsAPICounter.Inc()
+	query, err := c.logsAPI.StartQueryWithContext(ctx, &cloudwatchlogs.StartQueryInput{
+		LogGroupNames: aws.StringSlice(job.LogGroupNames),
+		QueryString:   aws.String(job.Query),
+		StartTime:     aws.Int64(start),
+		EndTime:       aws.Int64(end),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error calling logsAPI.StartQuery, %w", err)
+	}
+
+	ticker := time.NewTicker(logsinsights.PollInterval)
+	defer ticker.Stop()
+	deadline := time.Now().Add(logsinsights.QueryTimeout)
+	for {
+		select {
+		case <-ctx.Done():
+			c.stopQuery(query.QueryId)
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+
+		promutil.LogsAPICounter.Inc()
+		output, err := c.logsAPI.GetQueryResultsWithContext(ctx, &cloudwatchlogs.GetQueryResultsInput{QueryId: query.QueryId})
+		if err != nil {
+			return nil, fmt.Errorf("error calling logsAPI.GetQueryResults, %w", err)
+		}
+		done, err := logsinsights.Done(aws.StringValue(output.Status))
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			rows := make([][]model.Tag, 0, len(output.Results))
+			for _, result := range output.Results {
+				row := make([]model.Tag, 0, len(result))
+				for _, field := range result {
+					row = append(row, model.Tag{Key: aws.StringValue(field.Field), Value: aws.StringValue(field.Value)})
+				}
+				rows = append(rows, row)
+			}
+			return rows, nil
+		}
+
+		if time.Now().After(deadline) {
+			c.stopQuery(query.QueryId)
+			return nil, fmt.Errorf("query did not complete within %s", logsinsights.QueryTimeout)
+		}
+	}
+}
+
+// stopQuery stops a running query, so that it is not billed any further.
+func (c client) stopQuery(queryID *string) {
+	promutil.LogsAPICounter.Inc()
+	// The context of the scrape may be done already
+	if _, err := c.logsAPI.StopQuery(&cloudwatchlogs.StopQueryInput{QueryId: queryID}); err != nil {
+		c.logger.Warn("Failed to stop query", "query_id", aws.StringValue(queryID), "err", err)
+	}
+}
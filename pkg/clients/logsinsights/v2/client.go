@@ -0,0 +1,88 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/logsinsights"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/promutil"
+)
+
+type client struct {
+	logger  logging.Logger
+	logsAPI *cloudwatchlogs.Client
+}
+
+func NewClient(logger logging.Logger, logsAPI *cloudwatchlogs.Client) logsinsights.Client {
+	return &client{
+		logger:  logger,
+		logsAPI: logsAPI,
+	}
+}
+
+func (c client) RunQuery(ctx context.Context, job model.LogsInsightsJob) ([][]model.Tag, error) {
+	start, end := logsinsights.TimeRange(time.Now(), job.Length)
+	promutil.LogsAPICounter.Inc()
+	query, err := c.logsAPI.StartQuery(ctx, &cloudwatchlogs.StartQueryInput{
+		LogGroupNames: job.LogGroupNames,
+		QueryString:   aws.String(job.Query),
+		StartTime:     aws.Int64(start),
+		EndTime:       aws.Int64(end),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error calling logsAPI.StartQuery, %w", err)
+	}
+
+	ticker := time.NewTicker(logsinsights.PollInterval)
+	defer ticker.Stop()
+	deadline := time.Now().Add(logsinsights.QueryTimeout)
+	for {
+		select {
+		case <-ctx.Done():
+			c.stopQuery(query.QueryId)
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+
+		promutil.LogsAPICounter.Inc()
+		output, err := c.logsAPI.GetQueryResults(ctx, &cloudwatchlogs.GetQueryResultsInput{QueryId: query.QueryId})
+		if err != nil {
+			return nil, fmt.Errorf("error calling logsAPI.GetQueryResults, %w", err)
+		}
+		done, err := logsinsights.Done(string(output.Status))
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			rows := make([][]model.Tag, 0, len(output.Results))
+			for _, result := range output.Results {
+				row := make([]model.Tag, 0, len(result))
+				for _, field := range result {
+					row = append(row, model.Tag{Key: aws.StringValue(field.Field), Value: aws.StringValue(field.Value)})
+				}
+				rows = append(rows, row)
+			}
+			return rows, nil
+		}
+
+		if time.Now().After(deadline) {
+			c.stopQuery(query.QueryId)
+			return nil, fmt.Errorf("query did not complete within %s", logsinsights.QueryTimeout)
+		}
+	}
+}
+
+// stopQuery stops a running query, so that it is not billed any further.
+func (c client) stopQuery(queryID *string) {
+	promutil.LogsAPICounter.Inc()
+	// The context of the scrape may be done already
+	if _, err := c.logsAPI.StopQuery(context.Background(), &cloudwatchlogs.StopQueryInput{QueryId: queryID}); err != nil {
+		c.logger.Warn("Failed to stop query", "query_id", aws.StringValue(queryID), "err", err)
+	}
+}
@@ -0,0 +1,193 @@
+// Package mock provides an in-memory AWS backend implementing clients.Factory. It
+// is seeded with synthetic resources and datapoints, which allows applications
+// using YACE as a library to write end-to-end tests without AWS credentials.
+package mock
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/account"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/cloudwatch"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/contributorinsights"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/costexplorer"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/logsinsights"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/servicequotas"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/tagging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/trustedadvisor"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+// Datapoint is a raw value of a metric, aggregated by the backend
+// into the statistics requested by the CloudWatch calls.
+type Datapoint struct {
+	Timestamp time.Time
+	Value     float64
+}
+
+type series struct {
+	metric     *model.Metric
+	datapoints []Datapoint
+}
+
+// Backend is an in-memory AWS backend, holding the resources and metrics of an account.
+// It implements clients.Factory: the CloudWatch, tagging and account clients serve
+// the seeded data, other clients return empty results. It is safe for concurrent use.
+type Backend struct {
+	mu        sync.RWMutex
+	accountID string
+	resources map[string][]*model.TaggedResource
+	metrics   map[string][]*series
+}
+
+func NewBackend(accountID string) *Backend {
+	return &Backend{
+		accountID: accountID,
+		resources: map[string][]*model.TaggedResource{},
+		metrics:   map[string][]*series{},
+	}
+}
+
+// AddResources adds resources to a region. The resources namespace
+// must be set to the namespace of the discovery jobs finding them.
+func (b *Backend) AddResources(region string, resources ...*model.TaggedResource) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, r := range resources {
+		r.Region = region
+		b.resources[region] = append(b.resources[region], r)
+	}
+}
+
+// AddMetric adds datapoints to a metric of a region, creating the metric if needed.
+// Metrics are identified by their namespace, name and set of dimensions.
+func (b *Backend) AddMetric(region string, metric *model.Metric, datapoints ...Datapoint) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, s := range b.metrics[region] {
+		if s.metric.Namespace == metric.Namespace && s.metric.MetricName == metric.MetricName && dimensionsEqual(s.metric.Dimensions, metric.Dimensions) {
+			s.datapoints = append(s.datapoints, datapoints...)
+			return
+		}
+	}
+	b.metrics[region] = append(b.metrics[region], &series{metric: metric, datapoints: datapoints})
+}
+
+func (b *Backend) GetCloudwatchClient(region string, _ model.Role, _ cloudwatch.ConcurrencyConfig) cloudwatch.Client {
+	return cloudwatchClient{backend: b, region: region}
+}
+
+func (b *Backend) GetTaggingClient(region string, _ model.Role, _ int) tagging.Client {
+	return taggingClient{backend: b, region: region}
+}
+
+func (b *Backend) GetAccountClient(string, model.Role) account.Client {
+	return accountClient{backend: b}
+}
+
+func (b *Backend) GetCostExplorerClient(string, model.Role) costexplorer.Client {
+	return emptyClient{}
+}
+
+func (b *Backend) GetServiceQuotasClient(string, model.Role) servicequotas.Client {
+	return emptyClient{}
+}
+
+func (b *Backend) GetTrustedAdvisorClient(string, model.Role) trustedadvisor.Client {
+	return emptyClient{}
+}
+
+func (b *Backend) GetLogsInsightsClient(string, model.Role) logsinsights.Client {
+	return emptyClient{}
+}
+
+func (b *Backend) GetContributorInsightsClient(string, model.Role) contributorinsights.Client {
+	return emptyClient{}
+}
+
+// series returns the series of a region matching the filter.
+func (b *Backend) series(region string, filter func(*model.Metric) bool) []*series {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var out []*series
+	for _, s := range b.metrics[region] {
+		if filter(s.metric) {
+			out = append(out, &series{metric: s.metric, datapoints: slices.Clone(s.datapoints)})
+		}
+	}
+	return out
+}
+
+type taggingClient struct {
+	backend *Backend
+	region  string
+}
+
+func (c taggingClient) GetResources(_ context.Context, job model.DiscoveryJob, _ string) ([]*model.TaggedResource, error) {
+	c.backend.mu.RLock()
+	defer c.backend.mu.RUnlock()
+
+	var resources []*model.TaggedResource
+	for _, r := range c.backend.resources[c.region] {
+		if r.Namespace == job.Type && r.FilterThroughTags(job.SearchTags) {
+			resources = append(resources, r)
+		}
+	}
+
+	svc := config.SupportedServices.GetService(job.Type)
+	if svc != nil && len(svc.ResourceFilters) > 0 && len(resources) == 0 {
+		return nil, tagging.ErrExpectedToFindResources
+	}
+	return resources, nil
+}
+
+type accountClient struct {
+	backend *Backend
+}
+
+func (c accountClient) GetAccount(context.Context) (string, error) {
+	return c.backend.accountID, nil
+}
+
+// emptyClient implements the clients of the APIs the backend does not hold data for.
+type emptyClient struct{}
+
+func (emptyClient) GetCostAndUsage(context.Context, model.CostExplorerJob) ([]costexplorer.Cost, error) {
+	return nil, nil
+}
+
+func (emptyClient) ListServiceQuotas(context.Context, string) ([]*model.ServiceQuota, error) {
+	return nil, nil
+}
+
+func (emptyClient) DescribeChecks(context.Context, model.TrustedAdvisorJob) ([]*model.TrustedAdvisorCheck, error) {
+	return nil, nil
+}
+
+func (emptyClient) RunQuery(context.Context, model.LogsInsightsJob) ([][]model.Tag, error) {
+	return nil, nil
+}
+
+func (emptyClient) GetInsightRuleReport(_ context.Context, ruleName string, _ model.ContributorInsightsJob) (*model.ContributorInsightsReport, error) {
+	return &model.ContributorInsightsReport{RuleName: ruleName}, nil
+}
+
+func dimensionsEqual(a []*model.Dimension, b []*model.Dimension) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, da := range a {
+		if !slices.ContainsFunc(b, func(db *model.Dimension) bool {
+			return da.Name == db.Name && da.Value == db.Value
+		}) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,69 @@
+package mock_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	exporter "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/mock"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+const testConfig = `
+apiVersion: v1alpha1
+discovery:
+  jobs:
+    - type: AWS/EC2
+      regions:
+        - eu-west-1
+      metrics:
+        - name: CPUUtilization
+          statistics:
+            - Maximum
+          period: 300
+          length: 600
+`
+
+func TestBackendUpdateMetrics(t *testing.T) {
+	logger := logging.NewNopLogger()
+	configFile := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(configFile, []byte(testConfig), 0o600))
+	cfg := config.ScrapeConf{}
+	jobsCfg, err := cfg.Load(configFile, logger)
+	require.NoError(t, err)
+
+	backend := mock.NewBackend("123456789012")
+	backend.AddResources("eu-west-1", &model.TaggedResource{
+		ARN:       "arn:aws:ec2:eu-west-1:123456789012:instance/i-1",
+		Namespace: "AWS/EC2",
+		Tags:      []model.Tag{{Key: "Name", Value: "web"}},
+	})
+	backend.AddMetric("eu-west-1", &model.Metric{
+		Namespace:  "AWS/EC2",
+		MetricName: "CPUUtilization",
+		Dimensions: []*model.Dimension{{Name: "InstanceId", Value: "i-1"}},
+	}, mock.Datapoint{Timestamp: time.Now().Add(-6 * time.Minute), Value: 42})
+
+	registry := prometheus.NewRegistry()
+	require.NoError(t, exporter.UpdateMetrics(context.Background(), logger, jobsCfg, registry, backend))
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	var values []float64
+	for _, family := range families {
+		if family.GetName() == "aws_ec2_cpuutilization_maximum" {
+			for _, metric := range family.GetMetric() {
+				values = append(values, metric.GetGauge().GetValue())
+			}
+		}
+	}
+	require.Equal(t, []float64{42}, values)
+}
@@ -0,0 +1,201 @@
+package mock
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/cloudwatch"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/promutil"
+)
+
+// recentlyActiveDuration is how recent a datapoint of a metric
+// must be for it to be listed when recentlyActiveOnly is set.
+const recentlyActiveDuration = 3 * time.Hour
+
+type cloudwatchClient struct {
+	backend *Backend
+	region  string
+}
+
+func (c cloudwatchClient) ListMetrics(_ context.Context, namespace string, metric *model.MetricConfig, recentlyActiveOnly bool, fn func(page []*model.Metric)) error {
+	recentlyActive := time.Now().Add(-recentlyActiveDuration)
+	series := c.backend.series(c.region, func(m *model.Metric) bool {
+		return (namespace == "" || m.Namespace == namespace) && (metric == nil || metric.Name == "" || m.MetricName == metric.Name)
+	})
+
+	page := make([]*model.Metric, 0, len(series))
+	for _, s := range series {
+		if recentlyActiveOnly && !slices.ContainsFunc(s.datapoints, func(d Datapoint) bool { return d.Timestamp.After(recentlyActive) }) {
+			continue
+		}
+		page = append(page, s.metric)
+	}
+	if fn != nil && len(page) > 0 {
+		fn(page)
+	}
+	return nil
+}
+
+func (c cloudwatchClient) GetMetricData(_ context.Context, _ logging.Logger, getMetricData []*model.CloudwatchData, namespace string, length int64, delay int64, configuredRoundingPeriod *int64, addHistoricalMetrics bool) []cloudwatch.MetricDataResult {
+	roundingPeriod := model.DefaultPeriodSeconds
+	for _, data := range getMetricData {
+		if data.Period < roundingPeriod {
+			roundingPeriod = data.Period
+		}
+	}
+	if configuredRoundingPeriod != nil {
+		roundingPeriod = *configuredRoundingPeriod
+	}
+	startTime, endTime := cloudwatch.DetermineGetMetricDataWindow(
+		cloudwatch.TimeClock{},
+		time.Duration(roundingPeriod)*time.Second,
+		time.Duration(length)*time.Second,
+		time.Duration(delay)*time.Second)
+
+	output := make([]cloudwatch.MetricDataResult, 0, len(getMetricData))
+	for _, data := range getMetricData {
+		result := cloudwatch.MetricDataResult{ID: *data.MetricID}
+		buckets := c.buckets(namespace, *data.Metric, data.Dimensions, startTime, endTime, data.Period)
+		found := false
+		// Results are scanned by descending timestamps, as the real client requests them.
+		for i := len(buckets) - 1; i >= 0; i-- {
+			value, ok := statistic(buckets[i].values, data.Statistics[0])
+			if !ok {
+				continue
+			}
+			found = true
+			result.Datapoint = &value
+			result.Timestamp = buckets[i].timestamp
+			output = append(output, result)
+			if !addHistoricalMetrics {
+				break
+			}
+		}
+		if !found {
+			output = append(output, result)
+		}
+	}
+	return output
+}
+
+func (c cloudwatchClient) GetMetricStatistics(_ context.Context, _ logging.Logger, dimensions []*model.Dimension, namespace string, metric *model.MetricConfig) []*model.Datapoint {
+	endTime := time.Now().Add(-time.Duration(metric.Delay) * time.Second)
+	startTime := endTime.Add(-time.Duration(metric.Length) * time.Second)
+
+	buckets := c.buckets(namespace, metric.Name, dimensions, startTime, endTime, metric.Period)
+	datapoints := make([]*model.Datapoint, 0, len(buckets))
+	for _, b := range buckets {
+		timestamp := b.timestamp
+		datapoint := &model.Datapoint{Timestamp: &timestamp}
+		for _, s := range cloudwatch.GetMetricStatisticsStatistics(metric.Statistics) {
+			value, ok := statistic(b.values, s)
+			if !ok {
+				continue
+			}
+			switch s {
+			case "Average":
+				datapoint.Average = &value
+			case "Maximum":
+				datapoint.Maximum = &value
+			case "Minimum":
+				datapoint.Minimum = &value
+			case "SampleCount":
+				datapoint.SampleCount = &value
+			case "Sum":
+				datapoint.Sum = &value
+			default:
+				if datapoint.ExtendedStatistics == nil {
+					datapoint.ExtendedStatistics = map[string]*float64{}
+				}
+				datapoint.ExtendedStatistics[s] = &value
+			}
+		}
+		datapoints = append(datapoints, datapoint)
+	}
+	return datapoints
+}
+
+type bucket struct {
+	timestamp time.Time
+	values    []float64
+}
+
+// buckets returns the values of a metric between startTime and endTime,
+// grouped by period, sorted by ascending timestamps.
+func (c cloudwatchClient) buckets(namespace string, metricName string, dimensions []*model.Dimension, startTime time.Time, endTime time.Time, period int64) []bucket {
+	if period <= 0 {
+		period = model.DefaultPeriodSeconds
+	}
+	periodDuration := time.Duration(period) * time.Second
+
+	byTimestamp := map[time.Time]*bucket{}
+	for _, s := range c.backend.series(c.region, func(m *model.Metric) bool {
+		return m.Namespace == namespace && m.MetricName == metricName && dimensionsEqual(m.Dimensions, dimensions)
+	}) {
+		for _, d := range s.datapoints {
+			if d.Timestamp.Before(startTime) || !d.Timestamp.Before(endTime) {
+				continue
+			}
+			timestamp := startTime.Add(d.Timestamp.Sub(startTime) / periodDuration * periodDuration)
+			if _, ok := byTimestamp[timestamp]; !ok {
+				byTimestamp[timestamp] = &bucket{timestamp: timestamp}
+			}
+			byTimestamp[timestamp].values = append(byTimestamp[timestamp].values, d.Value)
+		}
+	}
+
+	buckets := make([]bucket, 0, len(byTimestamp))
+	for _, b := range byTimestamp {
+		buckets = append(buckets, *b)
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].timestamp.Before(buckets[j].timestamp)
+	})
+	return buckets
+}
+
+// statistic computes a CloudWatch statistic (e.g. Average, p99) of values.
+func statistic(values []float64, stat string) (float64, bool) {
+	if len(values) == 0 {
+		return 0, false
+	}
+	switch stat {
+	case "Average", model.StatisticWeightedAverage:
+		sum, _ := statistic(values, "Sum")
+		return sum / float64(len(values)), true
+	case "Maximum":
+		return slices.Max(values), true
+	case "Minimum":
+		return slices.Min(values), true
+	case "SampleCount":
+		return float64(len(values)), true
+	case "Sum":
+		sum := 0.0
+		for _, v := range values {
+			sum += v
+		}
+		return sum, true
+	}
+
+	if !promutil.Percentile.MatchString(stat) {
+		return 0, false
+	}
+	percentile, err := strconv.ParseFloat(stat[1:], 64)
+	if err != nil {
+		return 0, false
+	}
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	// Nearest-rank percentile
+	rank := int(math.Ceil(percentile / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1], true
+}
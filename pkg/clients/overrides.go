@@ -0,0 +1,70 @@
+package clients
+
+import (
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/account"
+	cloudwatch_client "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/cloudwatch"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/tagging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+// ClientOverrides are custom implementations of the CloudWatch, tagging and account (STS)
+// clients, e.g. wrapped with an organization middleware or running against a proxy, which
+// take precedence over the clients of a factory. A nil func keeps the client of the factory.
+//
+// The funcs are called every time a client is requested, they are expected to cache the
+// clients they create. The concurrency limits of the scrape are applied to the returned
+// CloudWatch and tagging clients.
+type ClientOverrides struct {
+	Cloudwatch func(region string, role model.Role) cloudwatch_client.Client
+	Tagging    func(region string, role model.Role) tagging.Client
+	Account    func(region string, role model.Role) account.Client
+}
+
+// OverridingFactory is a Factory producing the clients of ClientOverrides,
+// and the other clients from the factory it wraps.
+type OverridingFactory struct {
+	Factory
+	overrides ClientOverrides
+}
+
+func NewOverridingFactory(factory Factory, overrides ClientOverrides) *OverridingFactory {
+	return &OverridingFactory{
+		Factory:   factory,
+		overrides: overrides,
+	}
+}
+
+func (f *OverridingFactory) GetCloudwatchClient(region string, role model.Role, concurrency cloudwatch_client.ConcurrencyConfig) cloudwatch_client.Client {
+	if f.overrides.Cloudwatch == nil {
+		return f.Factory.GetCloudwatchClient(region, role, concurrency)
+	}
+	return cloudwatch_client.NewLimitedConcurrencyClient(f.overrides.Cloudwatch(region, role), concurrency.NewLimiter(region, role), concurrency.NewRequestLimiter(region, role, f.GetAccountClient(region, role)))
+}
+
+func (f *OverridingFactory) GetTaggingClient(region string, role model.Role, concurrencyLimit int) tagging.Client {
+	if f.overrides.Tagging == nil {
+		return f.Factory.GetTaggingClient(region, role, concurrencyLimit)
+	}
+	return tagging.NewLimitedConcurrencyClient(f.overrides.Tagging(region, role), concurrencyLimit)
+}
+
+func (f *OverridingFactory) GetAccountClient(region string, role model.Role) account.Client {
+	if f.overrides.Account == nil {
+		return f.Factory.GetAccountClient(region, role)
+	}
+	return f.overrides.Account(region, role)
+}
+
+// Refresh forwards to the wrapped factory, if it loads credentials before a scrape.
+func (f *OverridingFactory) Refresh() {
+	if r, ok := f.Factory.(interface{ Refresh() }); ok {
+		r.Refresh()
+	}
+}
+
+// Clear forwards to the wrapped factory, if it loads credentials before a scrape.
+func (f *OverridingFactory) Clear() {
+	if c, ok := f.Factory.(interface{ Clear() }); ok {
+		c.Clear()
+	}
+}
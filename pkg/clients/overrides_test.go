@@ -0,0 +1,41 @@
+package clients_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/account"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/mock"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+type testAccountClient struct {
+	accountID string
+}
+
+func (c testAccountClient) GetAccount(context.Context) (string, error) {
+	return c.accountID, nil
+}
+
+func TestOverridingFactory(t *testing.T) {
+	ctx := context.Background()
+	backend := mock.NewBackend("123456789012")
+
+	factory := clients.NewOverridingFactory(backend, clients.ClientOverrides{
+		Account: func(region string, _ model.Role) account.Client {
+			return testAccountClient{accountID: "proxied-" + region}
+		},
+	})
+
+	accountID, err := factory.GetAccountClient("eu-west-1", model.Role{}).GetAccount(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "proxied-eu-west-1", accountID)
+
+	// Clients without override are the ones of the wrapped factory
+	accountID, err = clients.NewOverridingFactory(backend, clients.ClientOverrides{}).GetAccountClient("eu-west-1", model.Role{}).GetAccount(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "123456789012", accountID)
+}
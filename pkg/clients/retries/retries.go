@@ -0,0 +1,173 @@
+// Package retries counts the retried requests to the AWS APIs by API, operation, account
+// and reason, and keeps the retries of the last hour by job, to find the jobs spending
+// the most retries, e.g. the ones throttled by a shared API limit.
+package retries
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/useragent"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/promutil"
+)
+
+const (
+	// Window is the period the retries of the jobs are kept for.
+	Window = time.Hour
+
+	// ReasonThrottled and ReasonError are the reasons of the retries, the
+	// requests failing with a throttling error or with another error.
+	ReasonThrottled = "throttled"
+	ReasonError     = "error"
+)
+
+// Recent holds the retries of the last Window of the requests of the exporter.
+var Recent = NewRecorder()
+
+type accountKey struct{}
+
+// WithAccount returns a context whose AWS API requests are made in the account.
+func WithAccount(ctx context.Context, accountID string) context.Context {
+	return context.WithValue(ctx, accountKey{}, accountID)
+}
+
+// Observe counts the retry of a request to the operation of the API made with the
+// context, of the job and account of the context.
+func Observe(ctx context.Context, api string, operation string, throttled bool) {
+	accountID, _ := ctx.Value(accountKey{}).(string)
+	reason := ReasonError
+	if throttled {
+		reason = ReasonThrottled
+	}
+	promutil.APIRetriesCounter.WithLabelValues(api, operation, accountID, reason).Inc()
+	Recent.record(time.Now(), retryKey{job: useragent.Job(ctx), api: api, operation: operation, accountID: accountID}, throttled)
+}
+
+type retryKey struct {
+	job       string
+	api       string
+	operation string
+	accountID string
+}
+
+type retryCount struct {
+	retries   int
+	throttled int
+}
+
+// bucket holds the retries of a minute.
+type bucket struct {
+	minute  time.Time
+	retries map[retryKey]retryCount
+}
+
+// Recorder keeps the retries of the last Window in buckets of a minute.
+type Recorder struct {
+	mu      sync.Mutex
+	buckets []bucket
+}
+
+// NewRecorder returns an empty Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+func (r *Recorder) record(now time.Time, key retryKey, throttled bool) {
+	minute := now.Truncate(time.Minute)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.expire(now)
+	if len(r.buckets) == 0 || r.buckets[len(r.buckets)-1].minute.Before(minute) {
+		r.buckets = append(r.buckets, bucket{minute: minute, retries: map[retryKey]retryCount{}})
+	}
+	b := r.buckets[len(r.buckets)-1]
+	count := b.retries[key]
+	count.retries++
+	if throttled {
+		count.throttled++
+	}
+	b.retries[key] = count
+}
+
+// expire removes the buckets older than the Window.
+func (r *Recorder) expire(now time.Time) {
+	oldest := now.Add(-Window)
+	i := 0
+	for i < len(r.buckets) && !r.buckets[i].minute.After(oldest) {
+		i++
+	}
+	r.buckets = slices.Delete(r.buckets, 0, i)
+}
+
+// JobRetries are the retries of the requests of a job.
+type JobRetries struct {
+	Job        string             `json:"job"`
+	Retries    int                `json:"retries"`
+	Throttled  int                `json:"throttled"`
+	Operations []OperationRetries `json:"operations"`
+}
+
+// OperationRetries are the retries of the requests of a job to an operation in an account.
+type OperationRetries struct {
+	API       string `json:"api"`
+	Operation string `json:"operation"`
+	AccountID string `json:"account_id"`
+	Retries   int    `json:"retries"`
+	Throttled int    `json:"throttled"`
+}
+
+// Top returns the retries of the last Window by job, the jobs and their
+// operations with the most retries first.
+func (r *Recorder) Top(now time.Time) []JobRetries {
+	r.mu.Lock()
+	r.expire(now)
+	totals := map[retryKey]retryCount{}
+	for _, b := range r.buckets {
+		for key, count := range b.retries {
+			total := totals[key]
+			total.retries += count.retries
+			total.throttled += count.throttled
+			totals[key] = total
+		}
+	}
+	r.mu.Unlock()
+
+	byJob := map[string]*JobRetries{}
+	for key, count := range totals {
+		job, ok := byJob[key.job]
+		if !ok {
+			job = &JobRetries{Job: key.job}
+			byJob[key.job] = job
+		}
+		job.Retries += count.retries
+		job.Throttled += count.throttled
+		job.Operations = append(job.Operations, OperationRetries{
+			API:       key.api,
+			Operation: key.operation,
+			AccountID: key.accountID,
+			Retries:   count.retries,
+			Throttled: count.throttled,
+		})
+	}
+
+	jobs := make([]JobRetries, 0, len(byJob))
+	for _, job := range byJob {
+		slices.SortFunc(job.Operations, func(a, b OperationRetries) int {
+			return cmp.Or(
+				cmp.Compare(b.Retries, a.Retries),
+				cmp.Compare(a.API, b.API),
+				cmp.Compare(a.Operation, b.Operation),
+				cmp.Compare(a.AccountID, b.AccountID),
+			)
+		})
+		jobs = append(jobs, *job)
+	}
+	slices.SortFunc(jobs, func(a, b JobRetries) int {
+		return cmp.Or(cmp.Compare(b.Retries, a.Retries), cmp.Compare(a.Job, b.Job))
+	})
+	return jobs
+}
@@ -0,0 +1,65 @@
+package retries
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/useragent"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/promutil"
+)
+
+func TestObserve(t *testing.T) {
+	ctx := WithAccount(useragent.WithJob(context.Background(), "AWS/EC2"), "123456789012")
+	Observe(ctx, "cloudwatch", "GetMetricData", true)
+	Observe(ctx, "cloudwatch", "GetMetricData", false)
+
+	require.Equal(t, 1.0, testutil.ToFloat64(promutil.APIRetriesCounter.WithLabelValues("cloudwatch", "GetMetricData", "123456789012", ReasonThrottled)))
+	require.Equal(t, 1.0, testutil.ToFloat64(promutil.APIRetriesCounter.WithLabelValues("cloudwatch", "GetMetricData", "123456789012", ReasonError)))
+	jobs := Recent.Top(time.Now())
+	require.Len(t, jobs, 1)
+	require.Equal(t, "AWS/EC2", jobs[0].Job)
+	require.Equal(t, 2, jobs[0].Retries)
+	require.Equal(t, 1, jobs[0].Throttled)
+}
+
+func TestRecorderTop(t *testing.T) {
+	r := NewRecorder()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	ec2 := retryKey{job: "AWS/EC2", api: "cloudwatch", operation: "GetMetricData", accountID: "123456789012"}
+	ec2Tagging := retryKey{job: "AWS/EC2", api: "tagging", operation: "GetResources", accountID: "123456789012"}
+	s3 := retryKey{job: "AWS/S3", api: "cloudwatch", operation: "ListMetrics", accountID: "210987654321"}
+
+	// Expired by the time of Top
+	r.record(now.Add(-90*time.Minute), s3, true)
+	r.record(now.Add(-90*time.Minute), s3, true)
+	r.record(now.Add(-90*time.Minute), s3, true)
+	r.record(now.Add(-30*time.Minute), ec2, true)
+	r.record(now.Add(-30*time.Minute), ec2Tagging, false)
+	r.record(now.Add(-10*time.Minute), s3, false)
+	r.record(now.Add(-time.Minute), ec2, true)
+	r.record(now.Add(-time.Minute), ec2, false)
+
+	require.Equal(t, []JobRetries{
+		{
+			Job:       "AWS/EC2",
+			Retries:   4,
+			Throttled: 2,
+			Operations: []OperationRetries{
+				{API: "cloudwatch", Operation: "GetMetricData", AccountID: "123456789012", Retries: 3, Throttled: 2},
+				{API: "tagging", Operation: "GetResources", AccountID: "123456789012", Retries: 1},
+			},
+		},
+		{
+			Job:        "AWS/S3",
+			Retries:    1,
+			Operations: []OperationRetries{{API: "cloudwatch", Operation: "ListMetrics", AccountID: "210987654321", Retries: 1}},
+		},
+	}, r.Top(now))
+
+	require.Empty(t, r.Top(now.Add(2*time.Hour)))
+	require.Empty(t, r.buckets)
+}
@@ -0,0 +1,31 @@
+package servicequotas
+
+import (
+	"context"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+type Client interface {
+	// ListServiceQuotas returns the quotas of a service (e.g. ec2), with their
+	// applied value if any, otherwise their default value.
+	ListServiceQuotas(ctx context.Context, serviceCode string) ([]*model.ServiceQuota, error)
+}
+
+// MergeQuotas returns the default quotas, replaced by the applied ones if present.
+func MergeQuotas(defaults []*model.ServiceQuota, applied []*model.ServiceQuota) []*model.ServiceQuota {
+	index := make(map[string]int, len(defaults))
+	quotas := make([]*model.ServiceQuota, 0, len(defaults))
+	for _, quota := range defaults {
+		index[quota.QuotaCode] = len(quotas)
+		quotas = append(quotas, quota)
+	}
+	for _, quota := range applied {
+		if i, ok := index[quota.QuotaCode]; ok {
+			quotas[i] = quota
+		} else {
+			quotas = append(quotas, quota)
+		}
+	}
+	return quotas
+}
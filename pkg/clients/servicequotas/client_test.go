@@ -0,0 +1,26 @@
+package servicequotas
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+func TestMergeQuotas(t *testing.T) {
+	defaults := []*model.ServiceQuota{
+		{ServiceCode: "ec2", QuotaCode: "L-0263D0A3", QuotaName: "EC2-VPC Elastic IPs", Value: 5},
+		{ServiceCode: "ec2", QuotaCode: "L-1216C47A", QuotaName: "Running On-Demand Standard instances", Value: 5},
+	}
+	applied := []*model.ServiceQuota{
+		{ServiceCode: "ec2", QuotaCode: "L-1216C47A", QuotaName: "Running On-Demand Standard instances", Value: 256},
+		{ServiceCode: "ec2", QuotaCode: "L-7295265B", QuotaName: "Running On-Demand X instances", Value: 64},
+	}
+
+	require.Equal(t, []*model.ServiceQuota{
+		{ServiceCode: "ec2", QuotaCode: "L-0263D0A3", QuotaName: "EC2-VPC Elastic IPs", Value: 5},
+		{ServiceCode: "ec2", QuotaCode: "L-1216C47A", QuotaName: "Running On-Demand Standard instances", Value: 256},
+		{ServiceCode: "ec2", QuotaCode: "L-7295265B", QuotaName: "Running On-Demand X instances", Value: 64},
+	}, MergeQuotas(defaults, applied))
+}
@@ -0,0 +1,83 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/servicequotas"
+	"github.com/aws/aws-sdk-go/service/servicequotas/servicequotasiface"
+
+	servicequotas_client "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/servicequotas"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/promutil"
+)
+
+type client struct {
+	logger           logging.Logger
+	serviceQuotasAPI servicequotasiface.ServiceQuotasAPI
+}
+
+func NewClient(logger logging.Logger, serviceQuotasAPI servicequotasiface.ServiceQuotasAPI) servicequotas_client.Client {
+	return &client{
+		logger:           logger,
+		serviceQuotasAPI: serviceQuotasAPI,
+	}
+}
+
+func (c client) ListServiceQuotas(ctx context.Context, serviceCode string) ([]*model.ServiceQuota, error) {
+	var defaults []*model.ServiceQuota
+	pageNum := 0
+	err := c.serviceQuotasAPI.ListAWSDefaultServiceQuotasPagesWithContext(ctx, &servicequotas.ListAWSDefaultServiceQuotasInput{
+		ServiceCode: aws.String(serviceCode),
+	}, func(page *servicequotas.ListAWSDefaultServiceQuotasOutput, _ bool) bool {
+		pageNum++
+		promutil.ServiceQuotasAPICounter.Inc()
+		for _, quota := range page.Quotas {
+			defaults = append(defaults, toModelQuota(quota))
+		}
+		return pageNum < 100
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error calling serviceQuotasAPI.ListAWSDefaultServiceQuotas, %w", err)
+	}
+
+	var applied []*model.ServiceQuota
+	pageNum = 0
+	err = c.serviceQuotasAPI.ListServiceQuotasPagesWithContext(ctx, &servicequotas.ListServiceQuotasInput{
+		ServiceCode: aws.String(serviceCode),
+	}, func(page *servicequotas.ListServiceQuotasOutput, _ bool) bool {
+		pageNum++
+		promutil.ServiceQuotasAPICounter.Inc()
+		for _, quota := range page.Quotas {
+			applied = append(applied, toModelQuota(quota))
+		}
+		return pageNum < 100
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error calling serviceQuotasAPI.ListServiceQuotas, %w", err)
+	}
+
+	return servicequotas_client.MergeQuotas(defaults, applied), nil
+}
+
+func toModelQuota(quota *servicequotas.ServiceQuota) *model.ServiceQuota {
+	q := &model.ServiceQuota{
+		ServiceCode: aws.StringValue(quota.ServiceCode),
+		QuotaCode:   aws.StringValue(quota.QuotaCode),
+		QuotaName:   aws.StringValue(quota.QuotaName),
+		Value:       aws.Float64Value(quota.Value),
+	}
+	if m := quota.UsageMetric; m != nil && m.MetricNamespace != nil && m.MetricName != nil {
+		q.UsageMetric = &model.ServiceQuotaUsageMetric{
+			Namespace:  aws.StringValue(m.MetricNamespace),
+			MetricName: aws.StringValue(m.MetricName),
+			Statistic:  aws.StringValue(m.MetricStatisticRecommendation),
+		}
+		for name, value := range m.MetricDimensions {
+			q.UsageMetric.Dimensions = append(q.UsageMetric.Dimensions, &model.Dimension{Name: name, Value: aws.StringValue(value)})
+		}
+	}
+	return q
+}
@@ -0,0 +1,89 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/servicequotas"
+	"github.com/aws/aws-sdk-go-v2/service/servicequotas/types"
+	"github.com/aws/aws-sdk-go/aws"
+
+	servicequotas_client "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/servicequotas"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/promutil"
+)
+
+type client struct {
+	logger           logging.Logger
+	serviceQuotasAPI *servicequotas.Client
+}
+
+func NewClient(logger logging.Logger, serviceQuotasAPI *servicequotas.Client) servicequotas_client.Client {
+	return &client{
+		logger:           logger,
+		serviceQuotasAPI: serviceQuotasAPI,
+	}
+}
+
+func (c client) ListServiceQuotas(ctx context.Context, serviceCode string) ([]*model.ServiceQuota, error) {
+	var defaults []*model.ServiceQuota
+	defaultsPaginator := servicequotas.NewListAWSDefaultServiceQuotasPaginator(c.serviceQuotasAPI, &servicequotas.ListAWSDefaultServiceQuotasInput{
+		ServiceCode: aws.String(serviceCode),
+	}, func(options *servicequotas.ListAWSDefaultServiceQuotasPaginatorOptions) {
+		options.StopOnDuplicateToken = true
+	})
+	pageNum := 0
+	for defaultsPaginator.HasMorePages() && pageNum < 100 {
+		pageNum++
+		promutil.ServiceQuotasAPICounter.Inc()
+		page, err := defaultsPaginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error calling serviceQuotasAPI.ListAWSDefaultServiceQuotas, %w", err)
+		}
+		for _, quota := range page.Quotas {
+			defaults = append(defaults, toModelQuota(quota))
+		}
+	}
+
+	var applied []*model.ServiceQuota
+	appliedPaginator := servicequotas.NewListServiceQuotasPaginator(c.serviceQuotasAPI, &servicequotas.ListServiceQuotasInput{
+		ServiceCode: aws.String(serviceCode),
+	}, func(options *servicequotas.ListServiceQuotasPaginatorOptions) {
+		options.StopOnDuplicateToken = true
+	})
+	pageNum = 0
+	for appliedPaginator.HasMorePages() && pageNum < 100 {
+		pageNum++
+		promutil.ServiceQuotasAPICounter.Inc()
+		page, err := appliedPaginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error calling serviceQuotasAPI.ListServiceQuotas, %w", err)
+		}
+		for _, quota := range page.Quotas {
+			applied = append(applied, toModelQuota(quota))
+		}
+	}
+
+	return servicequotas_client.MergeQuotas(defaults, applied), nil
+}
+
+func toModelQuota(quota types.ServiceQuota) *model.ServiceQuota {
+	q := &model.ServiceQuota{
+		ServiceCode: aws.StringValue(quota.ServiceCode),
+		QuotaCode:   aws.StringValue(quota.QuotaCode),
+		QuotaName:   aws.StringValue(quota.QuotaName),
+		Value:       aws.Float64Value(quota.Value),
+	}
+	if m := quota.UsageMetric; m != nil && m.MetricNamespace != nil && m.MetricName != nil {
+		q.UsageMetric = &model.ServiceQuotaUsageMetric{
+			Namespace:  aws.StringValue(m.MetricNamespace),
+			MetricName: aws.StringValue(m.MetricName),
+			Statistic:  aws.StringValue(m.MetricStatisticRecommendation),
+		}
+		for name, value := range m.MetricDimensions {
+			q.UsageMetric.Dimensions = append(q.UsageMetric.Dimensions, &model.Dimension{Name: name, Value: value})
+		}
+	}
+	return q
+}
@@ -0,0 +1,305 @@
+package tagging
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+// DefaultInfoAttributesCacheTTL is how long info metric attributes of
+// a resource are cached, they are not expected to change frequently.
+const DefaultInfoAttributesCacheTTL = time.Hour
+
+// AttributesCache caches resource attributes fetched from service describe
+// APIs, keyed by resource ARN. It is safe for concurrent use.
+type AttributesCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]attributesCacheEntry
+}
+
+type attributesCacheEntry struct {
+	attributes []model.Tag
+	expiresAt  time.Time
+}
+
+func NewAttributesCache(ttl time.Duration) *AttributesCache {
+	return &AttributesCache{
+		ttl:     ttl,
+		entries: map[string]attributesCacheEntry{},
+	}
+}
+
+// Get returns the cached attributes of the resource, if present and not expired.
+func (c *AttributesCache) Get(arn string) ([]model.Tag, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[arn]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(c.entries, arn)
+		return nil, false
+	}
+	return entry.attributes, true
+}
+
+// Set stores the attributes of the resource. Storing empty attributes is
+// allowed to avoid fetching them again for resources which have none.
+func (c *AttributesCache) Set(arn string, attributes []model.Tag) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[arn] = attributesCacheEntry{
+		attributes: attributes,
+		expiresAt:  time.Now().Add(c.ttl),
+	}
+}
+
+// SelectAttributes returns the attributes whose key is in names, in the order of names.
+func SelectAttributes(attributes []model.Tag, names []string) []model.Tag {
+	selected := make([]model.Tag, 0, len(names))
+	for _, name := range names {
+		tag := model.Tag{Key: name}
+		for _, attribute := range attributes {
+			if attribute.Key == name {
+				tag.Value = attribute.Value
+				break
+			}
+		}
+		// Always add the attribute, even if it's empty, to ensure the same labels are present on all info metrics
+		selected = append(selected, tag)
+	}
+	return selected
+}
+
+// SetInfoAttributes sets the InfoAttributes of the resources, restricted to names.
+// Attributes which are not cached are fetched with fetchFunc, which is only called
+// with the uncached resources and returns their attributes keyed by ARN.
+//
+// If fetchFunc fails, the partial result is still used but only the resources
+// which are part of it are cached. cache may be nil, in which case attributes
+// are always fetched.
+func SetInfoAttributes(cache *AttributesCache, resources []*model.TaggedResource, names []string, fetchFunc func([]*model.TaggedResource) (map[string][]model.Tag, error)) error {
+	attributesByARN := make(map[string][]model.Tag, len(resources))
+	var uncached []*model.TaggedResource
+	for _, resource := range resources {
+		if cache != nil {
+			if attributes, ok := cache.Get(resource.ARN); ok {
+				attributesByARN[resource.ARN] = attributes
+				continue
+			}
+		}
+		uncached = append(uncached, resource)
+	}
+
+	var err error
+	if len(uncached) > 0 {
+		var fetched map[string][]model.Tag
+		fetched, err = fetchFunc(uncached)
+		for _, resource := range uncached {
+			attributes, ok := fetched[resource.ARN]
+			attributesByARN[resource.ARN] = attributes
+			if cache != nil && (ok || err == nil) {
+				cache.Set(resource.ARN, attributes)
+			}
+		}
+	}
+
+	for _, resource := range resources {
+		resource.InfoAttributes = SelectAttributes(attributesByARN[resource.ARN], names)
+	}
+	return err
+}
+
+// ResourceCache caches the resources discovered by a Client, keyed by role,
+// region and the job parameters affecting the discovery. It is safe for concurrent use.
+type ResourceCache struct {
+	mu           sync.Mutex
+	ttl          time.Duration
+	maxStaleness time.Duration
+	entries      map[string]resourceCacheEntry
+}
+
+type resourceCacheEntry struct {
+	region    string
+	namespace string
+	resources []*model.TaggedResource
+	updatedAt time.Time
+	expiresAt time.Time
+}
+
+// NewResourceCache returns a cache serving the resources for ttl. When the
+// discovery fails, the resources are still served up to maxStaleness after
+// they were discovered, a maxStaleness of 0 disables this fallback.
+func NewResourceCache(ttl time.Duration, maxStaleness time.Duration) *ResourceCache {
+	return &ResourceCache{
+		ttl:          ttl,
+		maxStaleness: maxStaleness,
+		entries:      map[string]resourceCacheEntry{},
+	}
+}
+
+func (c *ResourceCache) get(key string) ([]*model.TaggedResource, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return cloneResources(entry.resources), true
+}
+
+// getStale returns the cached resources, even if expired, and their age,
+// if they are not older than the max staleness of the cache.
+func (c *ResourceCache) getStale(key string) ([]*model.TaggedResource, time.Duration, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, 0, false
+	}
+	age := time.Since(entry.updatedAt)
+	if age > c.maxStaleness {
+		return nil, 0, false
+	}
+	return cloneResources(entry.resources), age, true
+}
+
+func (c *ResourceCache) set(key string, region string, namespace string, resources []*model.TaggedResource) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	c.entries[key] = resourceCacheEntry{
+		region:    region,
+		namespace: namespace,
+		resources: cloneResources(resources),
+		updatedAt: now,
+		expiresAt: now.Add(c.ttl),
+	}
+}
+
+// Invalidate removes the resources of the namespace discovered in the region,
+// so that they are discovered again on the next call. An empty region
+// removes the resources of the namespace in all regions.
+func (c *ResourceCache) Invalidate(region string, namespace string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, entry := range c.entries {
+		if entry.namespace == namespace && (region == "" || entry.region == region) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// CachedResources describes the resources of a namespace cached for a region and role.
+type CachedResources struct {
+	Region    string    `json:"region"`
+	Namespace string    `json:"namespace"`
+	Resources int       `json:"resources"`
+	UpdatedAt time.Time `json:"updated_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Snapshot describes the cached resources, sorted by namespace and region.
+func (c *ResourceCache) Snapshot() []CachedResources {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	snapshot := make([]CachedResources, 0, len(c.entries))
+	for _, entry := range c.entries {
+		snapshot = append(snapshot, CachedResources{
+			Region:    entry.region,
+			Namespace: entry.namespace,
+			Resources: len(entry.resources),
+			UpdatedAt: entry.updatedAt,
+			ExpiresAt: entry.expiresAt,
+		})
+	}
+	slices.SortFunc(snapshot, func(a, b CachedResources) int {
+		return cmp.Or(cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.Region, b.Region), a.UpdatedAt.Compare(b.UpdatedAt))
+	})
+	return snapshot
+}
+
+// savedResourceCacheEntry is the JSON representation of a resourceCacheEntry.
+type savedResourceCacheEntry struct {
+	Region    string                  `json:"region"`
+	Namespace string                  `json:"namespace"`
+	Resources []*model.TaggedResource `json:"resources"`
+	UpdatedAt time.Time               `json:"updated_at"`
+}
+
+// Save writes the cached resources to w as JSON, for Load to restore them, e.g. after a restart.
+func (c *ResourceCache) Save(w io.Writer) error {
+	c.mu.Lock()
+	saved := make(map[string]savedResourceCacheEntry, len(c.entries))
+	for key, entry := range c.entries {
+		saved[key] = savedResourceCacheEntry{
+			Region:    entry.region,
+			Namespace: entry.namespace,
+			Resources: entry.resources,
+			UpdatedAt: entry.updatedAt,
+		}
+	}
+	c.mu.Unlock()
+	return json.NewEncoder(w).Encode(saved)
+}
+
+// Load adds the resources written by Save to the cache. They keep the time they were
+// discovered at, they expire after the ttl of the cache and are served up to its max
+// staleness from then.
+func (c *ResourceCache) Load(r io.Reader) error {
+	var saved map[string]savedResourceCacheEntry
+	if err := json.NewDecoder(r).Decode(&saved); err != nil {
+		return fmt.Errorf("failed to decode the saved resources: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, entry := range saved {
+		c.entries[key] = resourceCacheEntry{
+			region:    entry.Region,
+			namespace: entry.Namespace,
+			resources: entry.Resources,
+			updatedAt: entry.UpdatedAt,
+			expiresAt: entry.UpdatedAt.Add(c.ttl),
+		}
+	}
+	return nil
+}
+
+// resourceCacheKey builds the cache key of the resources discovered for a job.
+func resourceCacheKey(role model.Role, region string, job model.DiscoveryJob) string {
+	searchTags := make([]string, 0, len(job.SearchTags))
+	for _, st := range job.SearchTags {
+		searchTags = append(searchTags, st.Key+"="+st.Value.String())
+	}
+	return fmt.Sprintf("%s|%s|%s|%s|%v|%v|%v", role.RoleArn, role.ExternalID, region, job.Type, searchTags, job.AddResourceAttributes, job.InfoMetricAttributes)
+}
+
+// cloneResources copies the resources, so that callers modifying them
+// (e.g. adding attributes) do not alter the cached ones.
+func cloneResources(resources []*model.TaggedResource) []*model.TaggedResource {
+	cloned := make([]*model.TaggedResource, 0, len(resources))
+	for _, resource := range resources {
+		r := *resource
+		r.Tags = slices.Clone(resource.Tags)
+		r.Attributes = slices.Clone(resource.Attributes)
+		r.InfoAttributes = slices.Clone(resource.InfoAttributes)
+		cloned = append(cloned, &r)
+	}
+	return cloned
+}
@@ -0,0 +1,246 @@
+package tagging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/regexp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/promutil"
+)
+
+func TestAttributesCache(t *testing.T) {
+	cache := NewAttributesCache(time.Hour)
+
+	_, ok := cache.Get("arn:aws:sqs:us-east-1:123123123123:queue")
+	require.False(t, ok)
+
+	attributes := []model.Tag{{Key: "max_receive_count", Value: "5"}}
+	cache.Set("arn:aws:sqs:us-east-1:123123123123:queue", attributes)
+	cached, ok := cache.Get("arn:aws:sqs:us-east-1:123123123123:queue")
+	require.True(t, ok)
+	require.Equal(t, attributes, cached)
+
+	expiredCache := NewAttributesCache(-time.Second)
+	expiredCache.Set("arn:aws:sqs:us-east-1:123123123123:queue", attributes)
+	_, ok = expiredCache.Get("arn:aws:sqs:us-east-1:123123123123:queue")
+	require.False(t, ok)
+}
+
+func TestSelectAttributes(t *testing.T) {
+	attributes := []model.Tag{
+		{Key: "engine", Value: "redis"},
+		{Key: "engine_version", Value: "7.1"},
+		{Key: "node_type", Value: "cache.t4g.small"},
+	}
+
+	require.Equal(t, []model.Tag{
+		{Key: "node_type", Value: "cache.t4g.small"},
+		{Key: "engine_version", Value: "7.1"},
+		{Key: "snapshot_retention_limit", Value: ""},
+	}, SelectAttributes(attributes, []string{"node_type", "engine_version", "snapshot_retention_limit"}))
+}
+
+func TestSetInfoAttributes(t *testing.T) {
+	cache := NewAttributesCache(time.Hour)
+	cache.Set("arn:aws:dynamodb:us-east-1:123123123123:table/cached", []model.Tag{{Key: "billing_mode", Value: "PAY_PER_REQUEST"}})
+
+	resources := []*model.TaggedResource{
+		{ARN: "arn:aws:dynamodb:us-east-1:123123123123:table/cached"},
+		{ARN: "arn:aws:dynamodb:us-east-1:123123123123:table/uncached"},
+	}
+	var fetchedARNs []string
+	err := SetInfoAttributes(cache, resources, []string{"billing_mode"}, func(uncached []*model.TaggedResource) (map[string][]model.Tag, error) {
+		for _, resource := range uncached {
+			fetchedARNs = append(fetchedARNs, resource.ARN)
+		}
+		return map[string][]model.Tag{
+			"arn:aws:dynamodb:us-east-1:123123123123:table/uncached": {{Key: "billing_mode", Value: "PROVISIONED"}},
+		}, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"arn:aws:dynamodb:us-east-1:123123123123:table/uncached"}, fetchedARNs)
+	require.Equal(t, []model.Tag{{Key: "billing_mode", Value: "PAY_PER_REQUEST"}}, resources[0].InfoAttributes)
+	require.Equal(t, []model.Tag{{Key: "billing_mode", Value: "PROVISIONED"}}, resources[1].InfoAttributes)
+
+	_, ok := cache.Get("arn:aws:dynamodb:us-east-1:123123123123:table/uncached")
+	require.True(t, ok, "fetched attributes should be cached")
+
+	failed := []*model.TaggedResource{{ARN: "arn:aws:dynamodb:us-east-1:123123123123:table/failed"}}
+	err = SetInfoAttributes(cache, failed, []string{"billing_mode"}, func(_ []*model.TaggedResource) (map[string][]model.Tag, error) {
+		return nil, errors.New("access denied")
+	})
+	require.Error(t, err)
+	require.Equal(t, []model.Tag{{Key: "billing_mode", Value: ""}}, failed[0].InfoAttributes)
+
+	_, ok = cache.Get("arn:aws:dynamodb:us-east-1:123123123123:table/failed")
+	require.False(t, ok, "attributes which failed to be fetched should not be cached")
+}
+
+type countingClient struct {
+	calls int
+	err   error
+}
+
+func (c *countingClient) GetResources(_ context.Context, job model.DiscoveryJob, region string) ([]*model.TaggedResource, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return []*model.TaggedResource{{ARN: "arn:aws:sqs:us-east-1:123123123123:queue", Namespace: job.Type, Region: region}}, nil
+}
+
+func TestCachingClient(t *testing.T) {
+	ctx := context.Background()
+	job := model.DiscoveryJob{
+		Type:       "AWS/SQS",
+		SearchTags: []model.SearchTag{{Key: "env", Value: regexp.MustCompile("prod")}},
+	}
+	underlying := &countingClient{}
+	client := NewCachingClient(underlying, NewResourceCache(time.Hour, 0), model.Role{})
+
+	resources, err := client.GetResources(ctx, job, "us-east-1")
+	require.NoError(t, err)
+	require.Len(t, resources, 1)
+
+	// Modifying the returned resources must not alter the cached ones
+	resources[0].Attributes = append(resources[0].Attributes, model.Tag{Key: "k8s_cluster", Value: "production"})
+
+	resources, err = client.GetResources(ctx, job, "us-east-1")
+	require.NoError(t, err)
+	require.Empty(t, resources[0].Attributes)
+	require.Equal(t, 1, underlying.calls)
+
+	_, err = client.GetResources(ctx, job, "eu-west-1")
+	require.NoError(t, err)
+	require.Equal(t, 2, underlying.calls, "resources of another region should not be served from the cache")
+
+	job.SearchTags = []model.SearchTag{{Key: "env", Value: regexp.MustCompile("dev")}}
+	_, err = client.GetResources(ctx, job, "us-east-1")
+	require.NoError(t, err)
+	require.Equal(t, 3, underlying.calls, "resources of a job with other search tags should not be served from the cache")
+}
+
+func TestResourceCacheInvalidate(t *testing.T) {
+	ctx := context.Background()
+	sqsJob := model.DiscoveryJob{Type: "sqs"}
+	ec2Job := model.DiscoveryJob{Type: "AWS/EC2"}
+	underlying := &countingClient{}
+	cache := NewResourceCache(time.Hour, 0)
+	client := NewCachingClient(underlying, cache, model.Role{})
+
+	for _, job := range []model.DiscoveryJob{sqsJob, ec2Job} {
+		for _, region := range []string{"us-east-1", "eu-west-1"} {
+			_, err := client.GetResources(ctx, job, region)
+			require.NoError(t, err)
+		}
+	}
+	require.Equal(t, 4, underlying.calls)
+
+	cache.Invalidate("us-east-1", "AWS/SQS")
+	for _, job := range []model.DiscoveryJob{sqsJob, ec2Job} {
+		for _, region := range []string{"us-east-1", "eu-west-1"} {
+			_, err := client.GetResources(ctx, job, region)
+			require.NoError(t, err)
+		}
+	}
+	require.Equal(t, 5, underlying.calls, "only the resources of the invalidated namespace and region should be discovered again")
+
+	cache.Invalidate("", "AWS/EC2")
+	for _, region := range []string{"us-east-1", "eu-west-1"} {
+		_, err := client.GetResources(ctx, ec2Job, region)
+		require.NoError(t, err)
+	}
+	require.Equal(t, 7, underlying.calls, "the resources of the namespace should be discovered again in all regions")
+}
+
+func TestCachingClientStaleFallback(t *testing.T) {
+	ctx := context.Background()
+	job := model.DiscoveryJob{Type: "AWS/SQS"}
+	underlying := &countingClient{}
+	client := NewCachingClient(underlying, NewResourceCache(-time.Second, time.Hour), model.Role{})
+
+	_, err := client.GetResources(ctx, job, "us-east-1")
+	require.NoError(t, err)
+
+	underlying.err = errors.New("service unavailable")
+	resources, err := client.GetResources(ctx, job, "us-east-1")
+	require.NoError(t, err, "the stale resources should be served when the discovery fails")
+	require.Len(t, resources, 1)
+	require.Equal(t, 2, underlying.calls, "expired resources should be discovered again")
+	require.Equal(t, 1, testutil.CollectAndCount(promutil.StaleResourcesAgeGauge))
+
+	_, err = client.GetResources(ctx, job, "eu-west-1")
+	require.ErrorContains(t, err, "service unavailable", "no resources should be served when none were discovered")
+
+	underlying.err = ErrExpectedToFindResources
+	_, err = client.GetResources(ctx, job, "us-east-1")
+	require.ErrorIs(t, err, ErrExpectedToFindResources, "resources which are not found anymore should not be served")
+
+	underlying.err = nil
+	_, err = client.GetResources(ctx, job, "us-east-1")
+	require.NoError(t, err)
+	require.Equal(t, 0, testutil.CollectAndCount(promutil.StaleResourcesAgeGauge))
+
+	staleClient := NewCachingClient(underlying, NewResourceCache(-time.Second, 0), model.Role{})
+	_, err = staleClient.GetResources(ctx, job, "us-east-1")
+	require.NoError(t, err)
+	underlying.err = errors.New("service unavailable")
+	_, err = staleClient.GetResources(ctx, job, "us-east-1")
+	require.Error(t, err, "no stale resources should be served without a max staleness")
+}
+
+func TestResourceCacheSaveLoad(t *testing.T) {
+	ctx := context.Background()
+	job := model.DiscoveryJob{Type: "AWS/SQS"}
+	underlying := &countingClient{}
+	cache := NewResourceCache(time.Hour, 0)
+	_, err := NewCachingClient(underlying, cache, model.Role{}).GetResources(ctx, job, "us-east-1")
+	require.NoError(t, err)
+
+	var saved bytes.Buffer
+	require.NoError(t, cache.Save(&saved))
+
+	loaded := NewResourceCache(time.Hour, 0)
+	require.NoError(t, loaded.Load(&saved))
+	resources, err := NewCachingClient(underlying, loaded, model.Role{}).GetResources(ctx, job, "us-east-1")
+	require.NoError(t, err)
+	require.Len(t, resources, 1)
+	require.Equal(t, 1, underlying.calls, "the loaded resources should be served from the cache")
+
+	// The loaded resources keep their age
+	require.NoError(t, cache.Save(&saved))
+	expired := NewResourceCache(-time.Second, 0)
+	require.NoError(t, expired.Load(&saved))
+	_, err = NewCachingClient(underlying, expired, model.Role{}).GetResources(ctx, job, "us-east-1")
+	require.NoError(t, err)
+	require.Equal(t, 2, underlying.calls, "the expired loaded resources should be discovered again")
+
+	require.Error(t, loaded.Load(strings.NewReader("not json")))
+}
+
+func TestResourceCacheSnapshot(t *testing.T) {
+	ctx := context.Background()
+	cache := NewResourceCache(time.Hour, 0)
+	require.Empty(t, cache.Snapshot())
+
+	client := NewCachingClient(&countingClient{}, cache, model.Role{})
+	_, err := client.GetResources(ctx, model.DiscoveryJob{Type: "AWS/SQS"}, "us-east-1")
+	require.NoError(t, err)
+	_, err = client.GetResources(ctx, model.DiscoveryJob{Type: "AWS/SQS"}, "eu-west-1")
+	require.NoError(t, err)
+
+	snapshot := cache.Snapshot()
+	require.Len(t, snapshot, 2)
+	require.Equal(t, "eu-west-1", snapshot[0].Region)
+	require.Equal(t, "us-east-1", snapshot[1].Region)
+	require.Equal(t, 1, snapshot[0].Resources)
+	require.Equal(t, time.Hour, snapshot[0].ExpiresAt.Sub(snapshot[0].UpdatedAt))
+}
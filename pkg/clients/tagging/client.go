@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
 	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/promutil"
 )
 
 type Client interface {
@@ -31,3 +33,50 @@ func (c limitedConcurrencyClient) GetResources(ctx context.Context, job model.Di
 	<-c.sem
 	return res, err
 }
+
+type cachingClient struct {
+	client Client
+	cache  *ResourceCache
+	role   model.Role
+}
+
+// NewCachingClient returns a Client which serves the resources from the cache,
+// if present and not expired, instead of discovering them on every call.
+// Failed discoveries are not cached, the expired resources are served instead
+// if they are not older than the max staleness of the cache.
+func NewCachingClient(client Client, cache *ResourceCache, role model.Role) Client {
+	return &cachingClient{
+		client: client,
+		cache:  cache,
+		role:   role,
+	}
+}
+
+func (c cachingClient) GetResources(ctx context.Context, job model.DiscoveryJob, region string) ([]*model.TaggedResource, error) {
+	key := resourceCacheKey(c.role, region, job)
+	if resources, ok := c.cache.get(key); ok {
+		return resources, nil
+	}
+
+	namespace := job.Type
+	if svc := config.SupportedServices.GetService(job.Type); svc != nil {
+		namespace = svc.Namespace
+	}
+
+	resources, err := c.client.GetResources(ctx, job, region)
+	if err != nil {
+		// Not finding resources is not a failure of the discovery
+		if errors.Is(err, ErrExpectedToFindResources) {
+			return resources, err
+		}
+		stale, age, ok := c.cache.getStale(key)
+		if !ok {
+			return resources, err
+		}
+		promutil.StaleResourcesAgeGauge.WithLabelValues(region, namespace).Set(age.Seconds())
+		return stale, nil
+	}
+	promutil.StaleResourcesAgeGauge.DeleteLabelValues(region, namespace)
+	c.cache.set(key, region, namespace, resources)
+	return resources, nil
+}
@@ -28,6 +28,17 @@ func Test_Services_Have_Filters_In_V1_and_V2(t *testing.T) {
 			v1ResourceFuncNil := v1Filters.ResourceFunc == nil
 			v2ResourceFuncNil := v2Filters.ResourceFunc == nil
 			assert.Equal(t, v1ResourceFuncNil, v2ResourceFuncNil, "ResourceFunc is only implemented for v1 or v2 but should be implemented for both")
+
+			v1EnrichFuncNil := v1Filters.EnrichFunc == nil
+			v2EnrichFuncNil := v2Filters.EnrichFunc == nil
+			assert.Equal(t, v1EnrichFuncNil, v2EnrichFuncNil, "EnrichFunc is only implemented for v1 or v2 but should be implemented for both")
+
+			v1InfoAttributesFuncNil := v1Filters.InfoAttributesFunc == nil
+			v2InfoAttributesFuncNil := v2Filters.InfoAttributesFunc == nil
+			assert.Equal(t, v1InfoAttributesFuncNil, v2InfoAttributesFuncNil, "InfoAttributesFunc is only implemented for v1 or v2 but should be implemented for both")
+			if len(service.InfoMetricAttributes) > 0 {
+				assert.False(t, v1InfoAttributesFuncNil, "InfoMetricAttributes are supported but no InfoAttributesFunc is implemented")
+			}
 		})
 	}
 }
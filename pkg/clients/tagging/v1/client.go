@@ -8,13 +8,23 @@ import (
 	"github.com/aws/aws-sdk-go/service/apigateway/apigatewayiface"
 	"github.com/aws/aws-sdk-go/service/apigatewayv2/apigatewayv2iface"
 	"github.com/aws/aws-sdk-go/service/autoscaling/autoscalingiface"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
 	"github.com/aws/aws-sdk-go/service/databasemigrationservice/databasemigrationserviceiface"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/ecs/ecsiface"
+	"github.com/aws/aws-sdk-go/service/elasticache/elasticacheiface"
+	"github.com/aws/aws-sdk-go/service/kafka/kafkaiface"
 	"github.com/aws/aws-sdk-go/service/prometheusservice/prometheusserviceiface"
+	"github.com/aws/aws-sdk-go/service/rds/rdsiface"
+	"github.com/aws/aws-sdk-go/service/redshiftserverless/redshiftserverlessiface"
 	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
 	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi/resourcegroupstaggingapiiface"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/aws/aws-sdk-go/service/shield/shieldiface"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
 	"github.com/aws/aws-sdk-go/service/storagegateway/storagegatewayiface"
+	"github.com/aws/aws-sdk-go/service/synthetics/syntheticsiface"
 
 	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/tagging"
 	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
@@ -25,6 +35,7 @@ import (
 
 type client struct {
 	logger            logging.Logger
+	attributesCache   *tagging.AttributesCache
 	taggingAPI        resourcegroupstaggingapiiface.ResourceGroupsTaggingAPIAPI
 	autoscalingAPI    autoscalingiface.AutoScalingAPI
 	apiGatewayAPI     apigatewayiface.APIGatewayAPI
@@ -34,10 +45,21 @@ type client struct {
 	prometheusSvcAPI  prometheusserviceiface.PrometheusServiceAPI
 	storageGatewayAPI storagegatewayiface.StorageGatewayAPI
 	shieldAPI         shieldiface.ShieldAPI
+	rdsAPI            rdsiface.RDSAPI
+	ecsAPI            ecsiface.ECSAPI
+	elasticacheAPI    elasticacheiface.ElastiCacheAPI
+	sqsAPI            sqsiface.SQSAPI
+	dynamoDBAPI       dynamodbiface.DynamoDBAPI
+	logsAPI           cloudwatchlogsiface.CloudWatchLogsAPI
+	syntheticsAPI     syntheticsiface.SyntheticsAPI
+	s3API             s3iface.S3API
+	kafkaAPI          kafkaiface.KafkaAPI
+	redshiftAPI       redshiftserverlessiface.RedshiftServerlessAPI
 }
 
 func NewClient(
 	logger logging.Logger,
+	attributesCache *tagging.AttributesCache,
 	taggingAPI resourcegroupstaggingapiiface.ResourceGroupsTaggingAPIAPI,
 	autoscalingAPI autoscalingiface.AutoScalingAPI,
 	apiGatewayAPI apigatewayiface.APIGatewayAPI,
@@ -47,9 +69,20 @@ func NewClient(
 	prometheusClient prometheusserviceiface.PrometheusServiceAPI,
 	storageGatewayAPI storagegatewayiface.StorageGatewayAPI,
 	shieldAPI shieldiface.ShieldAPI,
+	rdsAPI rdsiface.RDSAPI,
+	ecsAPI ecsiface.ECSAPI,
+	elasticacheAPI elasticacheiface.ElastiCacheAPI,
+	sqsAPI sqsiface.SQSAPI,
+	dynamoDBAPI dynamodbiface.DynamoDBAPI,
+	logsAPI cloudwatchlogsiface.CloudWatchLogsAPI,
+	syntheticsAPI syntheticsiface.SyntheticsAPI,
+	s3API s3iface.S3API,
+	kafkaAPI kafkaiface.KafkaAPI,
+	redshiftAPI redshiftserverlessiface.RedshiftServerlessAPI,
 ) tagging.Client {
 	return &client{
 		logger:            logger,
+		attributesCache:   attributesCache,
 		taggingAPI:        taggingAPI,
 		autoscalingAPI:    autoscalingAPI,
 		apiGatewayAPI:     apiGatewayAPI,
@@ -59,6 +92,16 @@ func NewClient(
 		prometheusSvcAPI:  prometheusClient,
 		storageGatewayAPI: storageGatewayAPI,
 		shieldAPI:         shieldAPI,
+		rdsAPI:            rdsAPI,
+		ecsAPI:            ecsAPI,
+		elasticacheAPI:    elasticacheAPI,
+		sqsAPI:            sqsAPI,
+		dynamoDBAPI:       dynamoDBAPI,
+		logsAPI:           logsAPI,
+		syntheticsAPI:     syntheticsAPI,
+		s3API:             s3API,
+		kafkaAPI:          kafkaAPI,
+		redshiftAPI:       redshiftAPI,
 	}
 }
 
@@ -143,6 +186,31 @@ func (c client) GetResources(ctx context.Context, job model.DiscoveryJob, region
 			resources = filteredResources
 			c.logger.Debug("FilterFunc finished", "total", len(resources))
 		}
+
+		if job.AddResourceAttributes && ext.EnrichFunc != nil {
+			// Attributes are best effort, a failure should not prevent exporting metrics
+			if err := ext.EnrichFunc(ctx, c, resources); err != nil {
+				c.logger.Error(err, "failed to apply EnrichFunc", "namespace", svc.Namespace)
+			} else {
+				c.logger.Debug("EnrichFunc finished", "total", len(resources))
+			}
+		}
+
+		if len(job.InfoMetricAttributes) > 0 && ext.InfoAttributesFunc != nil {
+			err := tagging.SetInfoAttributes(c.attributesCache, resources, job.InfoMetricAttributes, func(uncached []*model.TaggedResource) (map[string][]model.Tag, error) {
+				return ext.InfoAttributesFunc(ctx, c, uncached)
+			})
+			if err != nil {
+				c.logger.Error(err, "failed to apply InfoAttributesFunc", "namespace", svc.Namespace)
+			}
+		}
+
+		if job.AddLogGroupMetrics && ext.ValuesFunc != nil {
+			// Values are best effort, like the attributes
+			if err := ext.ValuesFunc(ctx, c, resources); err != nil {
+				c.logger.Error(err, "failed to apply ValuesFunc", "namespace", svc.Namespace)
+			}
+		}
 	}
 
 	if shouldHaveDiscoveredResources && len(resources) == 0 {
@@ -2,7 +2,11 @@ package v1
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -10,23 +14,52 @@ import (
 	"github.com/aws/aws-sdk-go/service/apigateway"
 	"github.com/aws/aws-sdk-go/service/apigatewayv2"
 	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
 	"github.com/aws/aws-sdk-go/service/databasemigrationservice"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/elasticache"
+	"github.com/aws/aws-sdk-go/service/kafka"
 	"github.com/aws/aws-sdk-go/service/prometheusservice"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/aws/aws-sdk-go/service/redshiftserverless"
+	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/shield"
+	"github.com/aws/aws-sdk-go/service/sqs"
 	"github.com/aws/aws-sdk-go/service/storagegateway"
+	"github.com/aws/aws-sdk-go/service/synthetics"
 	"github.com/grafana/regexp"
 
 	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
 	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/promutil"
 )
 
+// ec2DescribeBatchSize is the max number of values allowed
+// in a single filter of an EC2 Describe* request
+const ec2DescribeBatchSize = 200
+
+// ecsDescribeServicesBatchSize is the max number of services
+// allowed in a single DescribeServices request
+const ecsDescribeServicesBatchSize = 10
+
 type ServiceFilter struct {
 	// ResourceFunc can be used to fetch additional resources
 	ResourceFunc func(context.Context, client, model.DiscoveryJob, string) ([]*model.TaggedResource, error)
 
 	// FilterFunc can be used to the input resources or to drop based on some condition
 	FilterFunc func(context.Context, client, []*model.TaggedResource) ([]*model.TaggedResource, error)
+
+	// EnrichFunc can be used to attach additional attributes to the input resources
+	EnrichFunc func(context.Context, client, []*model.TaggedResource) error
+
+	// InfoAttributesFunc can be used to fetch additional attributes of the input resources, keyed by ARN,
+	// to be exported on the info metric only. Its results are cached.
+	InfoAttributesFunc func(context.Context, client, []*model.TaggedResource) (map[string][]model.Tag, error)
+
+	// ValuesFunc can be used to set the numeric values of the input resources, exported as gauges
+	// next to the info metric. It is called for the jobs with addLogGroupMetrics.
+	ValuesFunc func(context.Context, client, []*model.TaggedResource) error
 }
 
 // ServiceFilters maps a service namespace to (optional) ServiceFilter
@@ -37,11 +70,10 @@ var ServiceFilters = map[string]ServiceFilter{
 			const maxPages = 10
 
 			var (
-				limit           int64 = 500 // max number of results per page. default=25, max=500
-				input                 = apigateway.GetRestApisInput{Limit: &limit}
-				output                = apigateway.GetRestApisOutput{}
-				pageNum         int
-				outputResources []*model.TaggedResource
+				limit   int64 = 500 // max number of results per page. default=25, max=500
+				input         = apigateway.GetRestApisInput{Limit: &limit}
+				output        = apigateway.GetRestApisOutput{}
+				pageNum int
 			)
 
 			err := client.apiGatewayAPI.GetRestApisPagesWithContext(ctx, &input, func(page *apigateway.GetRestApisOutput, _ bool) bool {
@@ -59,27 +91,16 @@ var ServiceFilters = map[string]ServiceFilter{
 				return nil, fmt.Errorf("error calling apiGatewayAPIv2.GetApis, %w", err)
 			}
 
-			for _, resource := range inputResources {
-				for i, gw := range output.Items {
-					searchString := regexp.MustCompile(fmt.Sprintf(".*restapis/%s$", *gw.Id))
-					if searchString.MatchString(resource.ARN) {
-						r := resource
-						r.ARN = strings.ReplaceAll(resource.ARN, *gw.Id, *gw.Name)
-						outputResources = append(outputResources, r)
-						output.Items = append(output.Items[:i], output.Items[i+1:]...)
-						break
-					}
-				}
-				for i, gw := range outputV2.Items {
-					searchString := regexp.MustCompile(fmt.Sprintf(".*apis/%s$", *gw.ApiId))
-					if searchString.MatchString(resource.ARN) {
-						outputResources = append(outputResources, resource)
-						outputV2.Items = append(outputV2.Items[:i], outputV2.Items[i+1:]...)
-						break
-					}
-				}
+			restAPINames := make(map[string]string, len(output.Items))
+			for _, gw := range output.Items {
+				restAPINames[aws.StringValue(gw.Id)] = aws.StringValue(gw.Name)
 			}
-			return outputResources, nil
+			apiIDs := make(map[string]struct{}, len(outputV2.Items))
+			for _, gw := range outputV2.Items {
+				apiIDs[aws.StringValue(gw.ApiId)] = struct{}{}
+			}
+
+			return apiGatewayResources(inputResources, restAPINames, apiIDs), nil
 		},
 	},
 	"AWS/AutoScaling": {
@@ -97,6 +118,9 @@ var ServiceFilters = map[string]ServiceFilter{
 							Namespace: job.Type,
 							Region:    region,
 						}
+						if job.AddCapacityMetrics {
+							resource.Values = autoScalingGroupValues(asg)
+						}
 
 						for _, t := range asg.Tags {
 							resource.Tags = append(resource.Tags, model.Tag{Key: *t.Key, Value: *t.Value})
@@ -169,6 +193,157 @@ var ServiceFilters = map[string]ServiceFilter{
 			return outputResources, nil
 		},
 	},
+	"AWS/DynamoDB": {
+		InfoAttributesFunc: func(ctx context.Context, client client, inputResources []*model.TaggedResource) (map[string][]model.Tag, error) {
+			attributes := make(map[string][]model.Tag, len(inputResources))
+			var errs []error
+			for _, resource := range inputResources {
+				parsedARN, err := arn.Parse(resource.ARN)
+				if err != nil {
+					continue
+				}
+				tableName := strings.TrimPrefix(parsedARN.Resource, "table/")
+
+				output, err := client.dynamoDBAPI.DescribeTableWithContext(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(tableName)})
+				promutil.DynamoDBAPICounter.Inc()
+				if err != nil {
+					errs = append(errs, fmt.Errorf("error calling dynamoDBAPI.DescribeTable for %s, %w", tableName, err))
+					continue
+				}
+
+				// Tables created before the billing mode and table class were introduced have no summaries
+				billingMode, tableClass := dynamodb.BillingModeProvisioned, dynamodb.TableClassStandard
+				if output.Table.BillingModeSummary != nil {
+					billingMode = aws.StringValue(output.Table.BillingModeSummary.BillingMode)
+				}
+				if output.Table.TableClassSummary != nil {
+					tableClass = aws.StringValue(output.Table.TableClassSummary.TableClass)
+				}
+				attributes[resource.ARN] = []model.Tag{
+					{Key: "billing_mode", Value: billingMode},
+					{Key: "table_class", Value: tableClass},
+				}
+			}
+			return attributes, errors.Join(errs...)
+		},
+	},
+	"AWS/EBS": {
+		EnrichFunc: func(ctx context.Context, client client, inputResources []*model.TaggedResource) error {
+			resourcesByID := make(map[string]*model.TaggedResource, len(inputResources))
+			volumeIDs := make([]*string, 0, len(inputResources))
+			for _, resource := range inputResources {
+				volumeID := ec2ResourceID(resource.ARN, "volume")
+				if volumeID == "" {
+					continue
+				}
+				resourcesByID[volumeID] = resource
+				volumeIDs = append(volumeIDs, aws.String(volumeID))
+			}
+
+			for start := 0; start < len(volumeIDs); start += ec2DescribeBatchSize {
+				end := start + ec2DescribeBatchSize
+				if end > len(volumeIDs) {
+					end = len(volumeIDs)
+				}
+
+				input := &ec2.DescribeVolumesInput{
+					Filters: []*ec2.Filter{{Name: aws.String("volume-id"), Values: volumeIDs[start:end]}},
+				}
+				pageNum := 0
+				err := client.ec2API.DescribeVolumesPagesWithContext(ctx, input, func(page *ec2.DescribeVolumesOutput, _ bool) bool {
+					pageNum++
+					promutil.Ec2APICounter.Inc()
+
+					for _, volume := range page.Volumes {
+						resource, ok := resourcesByID[aws.StringValue(volume.VolumeId)]
+						if !ok {
+							continue
+						}
+
+						attachedInstanceID, attachedDevice := "", ""
+						// Multi-Attach enabled volumes may be attached to several instances, the first attachment is used
+						if len(volume.Attachments) > 0 {
+							attachedInstanceID = aws.StringValue(volume.Attachments[0].InstanceId)
+							attachedDevice = aws.StringValue(volume.Attachments[0].Device)
+						}
+
+						resource.Attributes = []model.Tag{
+							{Key: "attached_instance_id", Value: attachedInstanceID},
+							{Key: "attached_device", Value: attachedDevice},
+						}
+					}
+					return pageNum < 100
+				})
+				if err != nil {
+					return fmt.Errorf("error calling ec2API.DescribeVolumes, %w", err)
+				}
+			}
+			return nil
+		},
+	},
+	"AWS/EC2": {
+		EnrichFunc: func(ctx context.Context, client client, inputResources []*model.TaggedResource) error {
+			resourcesByID := make(map[string]*model.TaggedResource, len(inputResources))
+			instanceIDs := make([]*string, 0, len(inputResources))
+			for _, resource := range inputResources {
+				instanceID := ec2ResourceID(resource.ARN, "instance")
+				if instanceID == "" {
+					continue
+				}
+				resourcesByID[instanceID] = resource
+				instanceIDs = append(instanceIDs, aws.String(instanceID))
+			}
+
+			for start := 0; start < len(instanceIDs); start += ec2DescribeBatchSize {
+				end := start + ec2DescribeBatchSize
+				if end > len(instanceIDs) {
+					end = len(instanceIDs)
+				}
+
+				input := &ec2.DescribeInstancesInput{
+					Filters: []*ec2.Filter{{Name: aws.String("instance-id"), Values: instanceIDs[start:end]}},
+				}
+				pageNum := 0
+				err := client.ec2API.DescribeInstancesPagesWithContext(ctx, input, func(page *ec2.DescribeInstancesOutput, _ bool) bool {
+					pageNum++
+					promutil.Ec2APICounter.Inc()
+
+					for _, reservation := range page.Reservations {
+						for _, instance := range reservation.Instances {
+							resource, ok := resourcesByID[aws.StringValue(instance.InstanceId)]
+							if !ok {
+								continue
+							}
+
+							lifecycle := aws.StringValue(instance.InstanceLifecycle)
+							if lifecycle == "" {
+								lifecycle = "on-demand"
+							}
+							autoScalingGroupName := ""
+							for _, t := range instance.Tags {
+								if aws.StringValue(t.Key) == "aws:autoscaling:groupName" {
+									autoScalingGroupName = aws.StringValue(t.Value)
+									break
+								}
+							}
+
+							resource.Attributes = []model.Tag{
+								{Key: "instance_type", Value: aws.StringValue(instance.InstanceType)},
+								{Key: "instance_lifecycle", Value: lifecycle},
+								{Key: "image_id", Value: aws.StringValue(instance.ImageId)},
+								{Key: "auto_scaling_group_name", Value: autoScalingGroupName},
+							}
+						}
+					}
+					return pageNum < 100
+				})
+				if err != nil {
+					return fmt.Errorf("error calling ec2API.DescribeInstances, %w", err)
+				}
+			}
+			return nil
+		},
+	},
 	"AWS/EC2Spot": {
 		ResourceFunc: func(ctx context.Context, client client, job model.DiscoveryJob, region string) ([]*model.TaggedResource, error) {
 			pageNum := 0
@@ -202,6 +377,117 @@ var ServiceFilters = map[string]ServiceFilter{
 			return resources, nil
 		},
 	},
+	"AWS/ECS": {
+		EnrichFunc: enrichECSServices,
+	},
+	"ECS/ContainerInsights": {
+		EnrichFunc: enrichECSServices,
+	},
+	"AWS/ElastiCache": {
+		InfoAttributesFunc: func(ctx context.Context, client client, _ []*model.TaggedResource) (map[string][]model.Tag, error) {
+			attributes := map[string][]model.Tag{}
+			pageNum := 0
+			err := client.elasticacheAPI.DescribeCacheClustersPagesWithContext(ctx, &elasticache.DescribeCacheClustersInput{}, func(page *elasticache.DescribeCacheClustersOutput, _ bool) bool {
+				pageNum++
+				promutil.ElastiCacheAPICounter.Inc()
+
+				for _, cluster := range page.CacheClusters {
+					attributes[aws.StringValue(cluster.ARN)] = []model.Tag{
+						{Key: "engine", Value: aws.StringValue(cluster.Engine)},
+						{Key: "engine_version", Value: aws.StringValue(cluster.EngineVersion)},
+						{Key: "node_type", Value: aws.StringValue(cluster.CacheNodeType)},
+					}
+				}
+				return pageNum < 100
+			})
+			if err != nil {
+				return nil, fmt.Errorf("error calling elasticacheAPI.DescribeCacheClusters, %w", err)
+			}
+			return attributes, nil
+		},
+	},
+	"AWS/Kafka": {
+		InfoAttributesFunc: func(ctx context.Context, client client, _ []*model.TaggedResource) (map[string][]model.Tag, error) {
+			attributes := map[string][]model.Tag{}
+			pageNum := 0
+			err := client.kafkaAPI.ListClustersPagesWithContext(ctx, &kafka.ListClustersInput{}, func(page *kafka.ListClustersOutput, _ bool) bool {
+				pageNum++
+				promutil.KafkaAPICounter.Inc()
+
+				for _, cluster := range page.ClusterInfoList {
+					kafkaVersion := ""
+					if cluster.CurrentBrokerSoftwareInfo != nil {
+						kafkaVersion = aws.StringValue(cluster.CurrentBrokerSoftwareInfo.KafkaVersion)
+					}
+					attributes[aws.StringValue(cluster.ClusterArn)] = kafkaClusterAttributes(aws.Int64Value(cluster.NumberOfBrokerNodes), kafkaVersion)
+				}
+				return pageNum < 100
+			})
+			if err != nil {
+				return nil, fmt.Errorf("error calling kafkaAPI.ListClusters, %w", err)
+			}
+			return attributes, nil
+		},
+	},
+	"AWS/Logs": {
+		InfoAttributesFunc: func(ctx context.Context, client client, _ []*model.TaggedResource) (map[string][]model.Tag, error) {
+			attributes := map[string][]model.Tag{}
+			err := describeLogGroups(ctx, client, func(logGroup *cloudwatchlogs.LogGroup) {
+				attributes[logGroupARN(aws.StringValue(logGroup.Arn))] = logGroupAttributes(
+					logGroup.RetentionInDays,
+					aws.StringValue(logGroup.LogGroupClass),
+				)
+			})
+			if err != nil {
+				return nil, err
+			}
+			return attributes, nil
+		},
+		ValuesFunc: func(ctx context.Context, client client, resources []*model.TaggedResource) error {
+			values := map[string][]model.ResourceValue{}
+			err := describeLogGroups(ctx, client, func(logGroup *cloudwatchlogs.LogGroup) {
+				values[logGroupARN(aws.StringValue(logGroup.Arn))] = logGroupValues(
+					aws.Int64Value(logGroup.StoredBytes),
+					aws.Int64Value(logGroup.MetricFilterCount),
+				)
+			})
+			if err != nil {
+				return err
+			}
+			for _, resource := range resources {
+				resource.Values = values[resource.ARN]
+			}
+			return nil
+		},
+	},
+	"CloudWatchSynthetics": {
+		InfoAttributesFunc: func(ctx context.Context, client client, resources []*model.TaggedResource) (map[string][]model.Tag, error) {
+			arns := canaryARNsByName(resources)
+			attributes := map[string][]model.Tag{}
+			pageNum := 0
+			err := client.syntheticsAPI.DescribeCanariesPagesWithContext(ctx, &synthetics.DescribeCanariesInput{}, func(page *synthetics.DescribeCanariesOutput, _ bool) bool {
+				pageNum++
+				promutil.SyntheticsAPICounter.Inc()
+
+				for _, canary := range page.Canaries {
+					arn, ok := arns[aws.StringValue(canary.Name)]
+					if !ok {
+						continue
+					}
+					var scheduleExpression string
+					if canary.Schedule != nil {
+						scheduleExpression = aws.StringValue(canary.Schedule.Expression)
+					}
+					attributes[arn] = canaryAttributes(aws.StringValue(canary.RuntimeVersion), scheduleExpression)
+				}
+				return pageNum < 100
+			})
+			if err != nil {
+				return nil, fmt.Errorf("error calling syntheticsAPI.DescribeCanaries, %w", err)
+			}
+			return attributes, nil
+		},
+	},
 	"AWS/Prometheus": {
 		ResourceFunc: func(ctx context.Context, client client, job model.DiscoveryJob, region string) ([]*model.TaggedResource, error) {
 			pageNum := 0
@@ -235,6 +521,167 @@ var ServiceFilters = map[string]ServiceFilter{
 			return resources, nil
 		},
 	},
+	"AWS/RDS": {
+		EnrichFunc: func(ctx context.Context, client client, inputResources []*model.TaggedResource) error {
+			resourcesByARN := make(map[string]*model.TaggedResource, len(inputResources))
+			for _, resource := range inputResources {
+				resourcesByARN[resource.ARN] = resource
+			}
+
+			pageNum := 0
+			err := client.rdsAPI.DescribeDBClustersPagesWithContext(ctx, &rds.DescribeDBClustersInput{}, func(page *rds.DescribeDBClustersOutput, _ bool) bool {
+				pageNum++
+				promutil.RdsAPICounter.Inc()
+
+				for _, cluster := range page.DBClusters {
+					if resource, ok := resourcesByARN[aws.StringValue(cluster.DBClusterArn)]; ok {
+						resource.Attributes = rdsAttributes(aws.StringValue(cluster.DBClusterIdentifier), aws.StringValue(cluster.Engine), aws.StringValue(cluster.EngineVersion))
+					}
+				}
+				return pageNum < 100
+			})
+			if err != nil {
+				return fmt.Errorf("error calling rdsAPI.DescribeDBClusters, %w", err)
+			}
+
+			pageNum = 0
+			err = client.rdsAPI.DescribeDBInstancesPagesWithContext(ctx, &rds.DescribeDBInstancesInput{}, func(page *rds.DescribeDBInstancesOutput, _ bool) bool {
+				pageNum++
+				promutil.RdsAPICounter.Inc()
+
+				for _, instance := range page.DBInstances {
+					if resource, ok := resourcesByARN[aws.StringValue(instance.DBInstanceArn)]; ok {
+						resource.Attributes = rdsAttributes(aws.StringValue(instance.DBClusterIdentifier), aws.StringValue(instance.Engine), aws.StringValue(instance.EngineVersion))
+					}
+				}
+				return pageNum < 100
+			})
+			if err != nil {
+				return fmt.Errorf("error calling rdsAPI.DescribeDBInstances, %w", err)
+			}
+			return nil
+		},
+	},
+	"AWS/Redshift-Serverless": {
+		// Append the workgroup or namespace name to the ARN, since the ARNs only contain their ID
+		FilterFunc: func(ctx context.Context, client client, inputResources []*model.TaggedResource) ([]*model.TaggedResource, error) {
+			if len(inputResources) == 0 {
+				return inputResources, nil
+			}
+
+			names := make(map[string]string)
+			pageNum := 0
+			if err := client.redshiftAPI.ListWorkgroupsPagesWithContext(ctx, &redshiftserverless.ListWorkgroupsInput{},
+				func(page *redshiftserverless.ListWorkgroupsOutput, _ bool) bool {
+					pageNum++
+					promutil.RedshiftServerlessAPICounter.Inc()
+
+					for _, workgroup := range page.Workgroups {
+						names[aws.StringValue(workgroup.WorkgroupArn)] = aws.StringValue(workgroup.WorkgroupName)
+					}
+
+					return pageNum < 100
+				},
+			); err != nil {
+				return nil, fmt.Errorf("error calling redshiftAPI.ListWorkgroups, %w", err)
+			}
+			pageNum = 0
+			if err := client.redshiftAPI.ListNamespacesPagesWithContext(ctx, &redshiftserverless.ListNamespacesInput{},
+				func(page *redshiftserverless.ListNamespacesOutput, _ bool) bool {
+					pageNum++
+					promutil.RedshiftServerlessAPICounter.Inc()
+
+					for _, namespace := range page.Namespaces {
+						names[aws.StringValue(namespace.NamespaceArn)] = aws.StringValue(namespace.NamespaceName)
+					}
+
+					return pageNum < 100
+				},
+			); err != nil {
+				return nil, fmt.Errorf("error calling redshiftAPI.ListNamespaces, %w", err)
+			}
+
+			var outputResources []*model.TaggedResource
+			for _, resource := range inputResources {
+				r := resource
+				if name, ok := names[r.ARN]; ok {
+					r.ARN = fmt.Sprintf("%s/%s", r.ARN, name)
+				}
+				outputResources = append(outputResources, r)
+			}
+			return outputResources, nil
+		},
+	},
+	"AWS/S3": {
+		InfoAttributesFunc: func(ctx context.Context, client client, inputResources []*model.TaggedResource) (map[string][]model.Tag, error) {
+			attributes := make(map[string][]model.Tag, len(inputResources))
+			var errs []error
+			for _, resource := range inputResources {
+				parsedARN, err := arn.Parse(resource.ARN)
+				if err != nil {
+					continue
+				}
+
+				var filterIDs []string
+				input := &s3.ListBucketMetricsConfigurationsInput{Bucket: aws.String(parsedARN.Resource)}
+				for {
+					output, err := client.s3API.ListBucketMetricsConfigurationsWithContext(ctx, input)
+					promutil.S3APICounter.Inc()
+					if err != nil {
+						errs = append(errs, fmt.Errorf("error calling s3API.ListBucketMetricsConfigurations for %s, %w", parsedARN.Resource, err))
+						break
+					}
+					for _, metricsConfiguration := range output.MetricsConfigurationList {
+						filterIDs = append(filterIDs, aws.StringValue(metricsConfiguration.Id))
+					}
+					if !aws.BoolValue(output.IsTruncated) {
+						attributes[resource.ARN] = s3MetricsFilterAttributes(filterIDs)
+						break
+					}
+					input.ContinuationToken = output.NextContinuationToken
+				}
+			}
+			return attributes, errors.Join(errs...)
+		},
+	},
+	"AWS/SQS": {
+		InfoAttributesFunc: func(ctx context.Context, client client, inputResources []*model.TaggedResource) (map[string][]model.Tag, error) {
+			attributes := make(map[string][]model.Tag, len(inputResources))
+			var errs []error
+			for _, resource := range inputResources {
+				parsedARN, err := arn.Parse(resource.ARN)
+				if err != nil {
+					continue
+				}
+
+				queueURL, err := client.sqsAPI.GetQueueUrlWithContext(ctx, &sqs.GetQueueUrlInput{
+					QueueName:              aws.String(parsedARN.Resource),
+					QueueOwnerAWSAccountId: aws.String(parsedARN.AccountID),
+				})
+				promutil.SqsAPICounter.Inc()
+				if err != nil {
+					errs = append(errs, fmt.Errorf("error calling sqsAPI.GetQueueUrl for %s, %w", parsedARN.Resource, err))
+					continue
+				}
+
+				output, err := client.sqsAPI.GetQueueAttributesWithContext(ctx, &sqs.GetQueueAttributesInput{
+					QueueUrl:       queueURL.QueueUrl,
+					AttributeNames: aws.StringSlice([]string{sqs.QueueAttributeNameRedrivePolicy, sqs.QueueAttributeNameFifoQueue}),
+				})
+				promutil.SqsAPICounter.Inc()
+				if err != nil {
+					errs = append(errs, fmt.Errorf("error calling sqsAPI.GetQueueAttributes for %s, %w", parsedARN.Resource, err))
+					continue
+				}
+
+				attributes[resource.ARN] = sqsAttributes(
+					aws.StringValue(output.Attributes[sqs.QueueAttributeNameRedrivePolicy]),
+					aws.StringValue(output.Attributes[sqs.QueueAttributeNameFifoQueue]),
+				)
+			}
+			return attributes, errors.Join(errs...)
+		},
+	},
 	"AWS/StorageGateway": {
 		ResourceFunc: func(ctx context.Context, client client, job model.DiscoveryJob, region string) ([]*model.TaggedResource, error) {
 			pageNum := 0
@@ -290,6 +737,9 @@ var ServiceFilters = map[string]ServiceFilter{
 							Namespace: job.Type,
 							Region:    region,
 						}
+						if job.AddResourceAttributes {
+							resource.Attributes = transitGatewayAttachmentAttributes(aws.StringValue(tgwa.ResourceType), aws.StringValue(tgwa.ResourceId))
+						}
 
 						for _, t := range tgwa.Tags {
 							resource.Tags = append(resource.Tags, model.Tag{Key: *t.Key, Value: *t.Value})
@@ -357,3 +807,261 @@ var ServiceFilters = map[string]ServiceFilter{
 		},
 	},
 }
+
+// ec2ResourceID extracts the resource id from an EC2 ARN (e.g. an instance or volume),
+// returns an empty string if the ARN does not refer to the given resource type.
+func ec2ResourceID(resourceARN string, resourceType string) string {
+	parsedARN, err := arn.Parse(resourceARN)
+	if err != nil {
+		return ""
+	}
+	resourceID, found := strings.CutPrefix(parsedARN.Resource, resourceType+"/")
+	if !found {
+		return ""
+	}
+	return resourceID
+}
+
+// autoScalingGroupValues returns the capacity of an Auto Scaling group, and the
+// on-demand distribution of its mixed instances policy, if any.
+func autoScalingGroupValues(asg *autoscaling.Group) []model.ResourceValue {
+	values := []model.ResourceValue{
+		{Name: "desired_capacity", Value: float64(aws.Int64Value(asg.DesiredCapacity))},
+		{Name: "min_size", Value: float64(aws.Int64Value(asg.MinSize))},
+		{Name: "max_size", Value: float64(aws.Int64Value(asg.MaxSize))},
+	}
+	if asg.MixedInstancesPolicy != nil && asg.MixedInstancesPolicy.InstancesDistribution != nil {
+		distribution := asg.MixedInstancesPolicy.InstancesDistribution
+		values = append(values,
+			model.ResourceValue{Name: "on_demand_base_capacity", Value: float64(aws.Int64Value(distribution.OnDemandBaseCapacity))},
+			model.ResourceValue{Name: "on_demand_percentage_above_base_capacity", Value: float64(aws.Int64Value(distribution.OnDemandPercentageAboveBaseCapacity))},
+		)
+	}
+	return values
+}
+
+// transitGatewayAttachmentAttributes returns the attributes of a transit gateway attachment, e.g. the
+// vpc type and the VPC ID, or the vpn type and the VPN connection ID.
+func transitGatewayAttachmentAttributes(resourceType, resourceID string) []model.Tag {
+	return []model.Tag{
+		{Key: "attachment_type", Value: resourceType},
+		{Key: "attachment_resource_id", Value: resourceID},
+	}
+}
+
+// rdsAttributes returns the attributes of an RDS instance or cluster. The cluster
+// attribute links Aurora instances to the cluster they belong to.
+func rdsAttributes(clusterIdentifier, engine, engineVersion string) []model.Tag {
+	return []model.Tag{
+		{Key: "cluster", Value: clusterIdentifier},
+		{Key: "engine", Value: engine},
+		{Key: "engine_version", Value: engineVersion},
+	}
+}
+
+// enrichECSServices attaches the cluster, launch type and task definition family of the service
+// to ECS service resources. It is used for both the AWS/ECS and ECS/ContainerInsights namespaces.
+func enrichECSServices(ctx context.Context, client client, inputResources []*model.TaggedResource) error {
+	for clusterName, services := range ecsServicesByCluster(inputResources) {
+		serviceNames := make([]*string, 0, len(services))
+		for serviceName := range services {
+			serviceNames = append(serviceNames, aws.String(serviceName))
+		}
+
+		for start := 0; start < len(serviceNames); start += ecsDescribeServicesBatchSize {
+			end := start + ecsDescribeServicesBatchSize
+			if end > len(serviceNames) {
+				end = len(serviceNames)
+			}
+
+			output, err := client.ecsAPI.DescribeServicesWithContext(ctx, &ecs.DescribeServicesInput{
+				Cluster:  aws.String(clusterName),
+				Services: serviceNames[start:end],
+			})
+			promutil.EcsAPICounter.Inc()
+			if err != nil {
+				return fmt.Errorf("error calling ecsAPI.DescribeServices, %w", err)
+			}
+
+			for _, service := range output.Services {
+				if resource, ok := services[aws.StringValue(service.ServiceName)]; ok {
+					resource.Attributes = ecsAttributes(clusterName, aws.StringValue(service.LaunchType), aws.StringValue(service.TaskDefinition))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// ecsServicesByCluster groups the ECS service resources by cluster name,
+// keyed by service name. Cluster resources are skipped.
+func ecsServicesByCluster(inputResources []*model.TaggedResource) map[string]map[string]*model.TaggedResource {
+	servicesByCluster := make(map[string]map[string]*model.TaggedResource)
+	for _, resource := range inputResources {
+		parsedARN, err := arn.Parse(resource.ARN)
+		if err != nil {
+			continue
+		}
+		// Only long ARNs (service/<cluster>/<service>) carry the cluster name
+		parts := strings.Split(parsedARN.Resource, "/")
+		if len(parts) != 3 || parts[0] != "service" {
+			continue
+		}
+		if servicesByCluster[parts[1]] == nil {
+			servicesByCluster[parts[1]] = make(map[string]*model.TaggedResource)
+		}
+		servicesByCluster[parts[1]][parts[2]] = resource
+	}
+	return servicesByCluster
+}
+
+// ecsTaskDefinitionFamily extracts the family from a task definition ARN
+// (arn:aws:ecs:<region>:<account>:task-definition/<family>:<revision>).
+func ecsTaskDefinitionFamily(taskDefinitionARN string) string {
+	_, familyRevision, found := strings.Cut(taskDefinitionARN, "task-definition/")
+	if !found {
+		return ""
+	}
+	family, _, _ := strings.Cut(familyRevision, ":")
+	return family
+}
+
+// ecsAttributes returns the attributes of an ECS service.
+func ecsAttributes(clusterName, launchType, taskDefinitionARN string) []model.Tag {
+	return []model.Tag{
+		{Key: "cluster", Value: clusterName},
+		{Key: "launch_type", Value: launchType},
+		{Key: "task_definition_family", Value: ecsTaskDefinitionFamily(taskDefinitionARN)},
+	}
+}
+
+// sqsAttributes returns the attributes of an SQS queue from its RedrivePolicy and FifoQueue attributes.
+func sqsAttributes(redrivePolicy string, fifoQueue string) []model.Tag {
+	var policy struct {
+		DeadLetterTargetArn string          `json:"deadLetterTargetArn"`
+		MaxReceiveCount     json.RawMessage `json:"maxReceiveCount"`
+	}
+	if redrivePolicy != "" {
+		// An invalid policy is handled like a missing one
+		_ = json.Unmarshal([]byte(redrivePolicy), &policy)
+	}
+	if fifoQueue == "" {
+		fifoQueue = "false"
+	}
+
+	return []model.Tag{
+		{Key: "dead_letter_target_arn", Value: policy.DeadLetterTargetArn},
+		// maxReceiveCount is documented as a string but returned as a number
+		{Key: "max_receive_count", Value: strings.Trim(string(policy.MaxReceiveCount), `"`)},
+		{Key: "fifo_queue", Value: fifoQueue},
+	}
+}
+
+// describeLogGroups calls fn with the log groups of the region, up to 100 pages of them.
+func describeLogGroups(ctx context.Context, client client, fn func(*cloudwatchlogs.LogGroup)) error {
+	pageNum := 0
+	err := client.logsAPI.DescribeLogGroupsPagesWithContext(ctx, &cloudwatchlogs.DescribeLogGroupsInput{}, func(page *cloudwatchlogs.DescribeLogGroupsOutput, _ bool) bool {
+		pageNum++
+		promutil.LogsAPICounter.Inc()
+
+		for _, logGroup := range page.LogGroups {
+			fn(logGroup)
+		}
+		return pageNum < 100
+	})
+	if err != nil {
+		return fmt.Errorf("error calling logsAPI.DescribeLogGroups, %w", err)
+	}
+	return nil
+}
+
+// logGroupARN returns the ARN of a log group as returned by the tagging API,
+// without the ":*" suffix of the ARNs returned by DescribeLogGroups.
+func logGroupARN(describedARN string) string {
+	return strings.TrimSuffix(describedARN, ":*")
+}
+
+// logGroupAttributes returns the attributes of a log group. The retention
+// is empty for log groups whose events never expire.
+func logGroupAttributes(retentionInDays *int64, logGroupClass string) []model.Tag {
+	retention := ""
+	if retentionInDays != nil {
+		retention = strconv.FormatInt(*retentionInDays, 10)
+	}
+	return []model.Tag{
+		{Key: "retention_in_days", Value: retention},
+		{Key: "log_group_class", Value: logGroupClass},
+	}
+}
+
+// logGroupValues returns the size and the number of metric filters of a log group.
+func logGroupValues(storedBytes int64, metricFilterCount int64) []model.ResourceValue {
+	return []model.ResourceValue{
+		{Name: "stored_bytes", Value: float64(storedBytes)},
+		{Name: "metric_filter_count", Value: float64(metricFilterCount)},
+	}
+}
+
+// canaryARNsByName maps the names of canary resources to their ARNs,
+// DescribeCanaries does not return the ARNs of the canaries.
+func canaryARNsByName(resources []*model.TaggedResource) map[string]string {
+	arns := make(map[string]string, len(resources))
+	for _, resource := range resources {
+		if _, name, found := strings.Cut(resource.ARN, ":canary:"); found {
+			arns[name] = resource.ARN
+		}
+	}
+	return arns
+}
+
+// canaryAttributes returns the attributes of a canary.
+func canaryAttributes(runtimeVersion string, scheduleExpression string) []model.Tag {
+	return []model.Tag{
+		{Key: "runtime_version", Value: runtimeVersion},
+		{Key: "schedule_expression", Value: scheduleExpression},
+	}
+}
+
+// s3MetricsFilterAttributes returns the attributes of a bucket from the ids
+// of its request metrics configurations, i.e. the FilterId dimension values.
+func s3MetricsFilterAttributes(filterIDs []string) []model.Tag {
+	slices.Sort(filterIDs)
+	return []model.Tag{
+		{Key: "metrics_filter_ids", Value: strings.Join(filterIDs, ",")},
+	}
+}
+
+var apiGatewayARNRegexp = regexp.MustCompile("/(restapis|apis)/([^/]+)(/stages/[^/]+)?$")
+
+// apiGatewayResources keeps the REST, HTTP and WebSocket APIs resources and their stages
+// which belong to existing APIs. The ids of REST APIs are replaced by their names in the
+// ARNs, as REST APIs metrics have an ApiName dimension instead of an ApiId dimension.
+func apiGatewayResources(resources []*model.TaggedResource, restAPINames map[string]string, apiIDs map[string]struct{}) []*model.TaggedResource {
+	var outputResources []*model.TaggedResource
+	for _, resource := range resources {
+		match := apiGatewayARNRegexp.FindStringSubmatchIndex(resource.ARN)
+		if match == nil {
+			continue
+		}
+		apiType, apiID := resource.ARN[match[2]:match[3]], resource.ARN[match[4]:match[5]]
+		if apiType == "apis" {
+			if _, ok := apiIDs[apiID]; ok {
+				outputResources = append(outputResources, resource)
+			}
+			continue
+		}
+		if name, ok := restAPINames[apiID]; ok {
+			resource.ARN = resource.ARN[:match[4]] + name + resource.ARN[match[5]:]
+			outputResources = append(outputResources, resource)
+		}
+	}
+	return outputResources
+}
+
+// kafkaClusterAttributes returns the attributes of a provisioned MSK cluster.
+func kafkaClusterAttributes(brokerCount int64, kafkaVersion string) []model.Tag {
+	return []model.Tag{
+		{Key: "broker_count", Value: strconv.FormatInt(brokerCount, 10)},
+		{Key: "kafka_version", Value: kafkaVersion},
+	}
+}
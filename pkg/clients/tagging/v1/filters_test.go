@@ -11,8 +11,22 @@ import (
 	"github.com/aws/aws-sdk-go/service/apigateway/apigatewayiface"
 	"github.com/aws/aws-sdk-go/service/apigatewayv2"
 	"github.com/aws/aws-sdk-go/service/apigatewayv2/apigatewayv2iface"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
 	"github.com/aws/aws-sdk-go/service/databasemigrationservice"
 	"github.com/aws/aws-sdk-go/service/databasemigrationservice/databasemigrationserviceiface"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/ecs/ecsiface"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/aws/aws-sdk-go/service/rds/rdsiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/aws/aws-sdk-go/service/synthetics"
+	"github.com/aws/aws-sdk-go/service/synthetics/syntheticsiface"
 
 	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
 	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
@@ -25,7 +39,7 @@ func TestValidServiceNames(t *testing.T) {
 			t.Fail()
 		}
 
-		if filter.FilterFunc == nil && filter.ResourceFunc == nil {
+		if filter.FilterFunc == nil && filter.ResourceFunc == nil && filter.EnrichFunc == nil && filter.InfoAttributesFunc == nil {
 			t.Errorf("no filter functions defined for service name '%s'", svc)
 			t.FailNow()
 		}
@@ -40,7 +54,7 @@ func TestApiGatewayFilterFunc(t *testing.T) {
 		outputResources []*model.TaggedResource
 	}{
 		{
-			"api gateway resources skip stages",
+			"api gateway resources and stages",
 			client{
 				apiGatewayAPI: apiGatewayClient{
 					getRestApisOutput: &apigateway.GetRestApisOutput{
@@ -95,6 +109,17 @@ func TestApiGatewayFilterFunc(t *testing.T) {
 				},
 			},
 			[]*model.TaggedResource{
+				{
+					ARN:       "arn:aws:apigateway:us-east-1::/restapis/apiname/stages/main",
+					Namespace: "apigateway",
+					Region:    "us-east-1",
+					Tags: []model.Tag{
+						{
+							Key:   "Test",
+							Value: "Value",
+						},
+					},
+				},
 				{
 					ARN:       "arn:aws:apigateway:us-east-1::/restapis/apiname",
 					Namespace: "apigateway",
@@ -159,6 +184,17 @@ func TestApiGatewayFilterFunc(t *testing.T) {
 				},
 			},
 			[]*model.TaggedResource{
+				{
+					ARN:       "arn:aws:apigateway:us-east-1::/apis/gwid9876/stages/$default",
+					Namespace: "apigateway",
+					Region:    "us-east-1",
+					Tags: []model.Tag{
+						{
+							Key:   "Test",
+							Value: "Value",
+						},
+					},
+				},
 				{
 					ARN:       "arn:aws:apigateway:us-east-1::/apis/gwid9876",
 					Namespace: "apigateway",
@@ -415,6 +451,586 @@ func TestDMSFilterFunc(t *testing.T) {
 	}
 }
 
+func TestEC2EnrichFunc(t *testing.T) {
+	tests := []struct {
+		name            string
+		iface           client
+		inputResources  []*model.TaggedResource
+		outputResources []*model.TaggedResource
+	}{
+		{
+			"empty input resources",
+			client{},
+			[]*model.TaggedResource{},
+			[]*model.TaggedResource{},
+		},
+		{
+			"on-demand and spot instances",
+			client{
+				ec2API: ec2Client{
+					describeInstancesOutput: &ec2.DescribeInstancesOutput{
+						Reservations: []*ec2.Reservation{
+							{
+								Instances: []*ec2.Instance{
+									{
+										InstanceId:   aws.String("i-0123456789abcdef0"),
+										InstanceType: aws.String("m5.large"),
+										ImageId:      aws.String("ami-0abcdef1234567890"),
+										Tags: []*ec2.Tag{
+											{Key: aws.String("aws:autoscaling:groupName"), Value: aws.String("workers")},
+										},
+									},
+									{
+										InstanceId:        aws.String("i-0fedcba9876543210"),
+										InstanceType:      aws.String("c5.xlarge"),
+										InstanceLifecycle: aws.String("spot"),
+										ImageId:           aws.String("ami-0abcdef1234567890"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			[]*model.TaggedResource{
+				{
+					ARN:       "arn:aws:ec2:us-east-1:123123123123:instance/i-0123456789abcdef0",
+					Namespace: "AWS/EC2",
+					Region:    "us-east-1",
+				},
+				{
+					ARN:       "arn:aws:ec2:us-east-1:123123123123:instance/i-0fedcba9876543210",
+					Namespace: "AWS/EC2",
+					Region:    "us-east-1",
+				},
+				{
+					ARN:       "arn:aws:ec2:us-east-1:123123123123:volume/vol-0123456789abcdef0",
+					Namespace: "AWS/EC2",
+					Region:    "us-east-1",
+				},
+			},
+			[]*model.TaggedResource{
+				{
+					ARN:       "arn:aws:ec2:us-east-1:123123123123:instance/i-0123456789abcdef0",
+					Namespace: "AWS/EC2",
+					Region:    "us-east-1",
+					Attributes: []model.Tag{
+						{Key: "instance_type", Value: "m5.large"},
+						{Key: "instance_lifecycle", Value: "on-demand"},
+						{Key: "image_id", Value: "ami-0abcdef1234567890"},
+						{Key: "auto_scaling_group_name", Value: "workers"},
+					},
+				},
+				{
+					ARN:       "arn:aws:ec2:us-east-1:123123123123:instance/i-0fedcba9876543210",
+					Namespace: "AWS/EC2",
+					Region:    "us-east-1",
+					Attributes: []model.Tag{
+						{Key: "instance_type", Value: "c5.xlarge"},
+						{Key: "instance_lifecycle", Value: "spot"},
+						{Key: "image_id", Value: "ami-0abcdef1234567890"},
+						{Key: "auto_scaling_group_name", Value: ""},
+					},
+				},
+				{
+					ARN:       "arn:aws:ec2:us-east-1:123123123123:volume/vol-0123456789abcdef0",
+					Namespace: "AWS/EC2",
+					Region:    "us-east-1",
+				},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ec2Filter := ServiceFilters["AWS/EC2"]
+
+			err := ec2Filter.EnrichFunc(context.Background(), test.iface, test.inputResources)
+			if err != nil {
+				t.Logf("Error from EnrichFunc: %v", err)
+				t.FailNow()
+			}
+			for i, resource := range test.inputResources {
+				wantResource := *test.outputResources[i]
+				if !reflect.DeepEqual(*resource, wantResource) {
+					t.Errorf("inputResources[%d] = %+v, want %+v", i, *resource, wantResource)
+				}
+			}
+		})
+	}
+}
+
+func TestRDSEnrichFunc(t *testing.T) {
+	iface := client{
+		rdsAPI: rdsClient{
+			describeDBClustersOutput: &rds.DescribeDBClustersOutput{
+				DBClusters: []*rds.DBCluster{
+					{
+						DBClusterArn:        aws.String("arn:aws:rds:us-east-1:123123123123:cluster:aurora-cluster"),
+						DBClusterIdentifier: aws.String("aurora-cluster"),
+						Engine:              aws.String("aurora-postgresql"),
+						EngineVersion:       aws.String("15.4"),
+					},
+				},
+			},
+			describeDBInstancesOutput: &rds.DescribeDBInstancesOutput{
+				DBInstances: []*rds.DBInstance{
+					{
+						DBInstanceArn:       aws.String("arn:aws:rds:us-east-1:123123123123:db:aurora-instance-1"),
+						DBClusterIdentifier: aws.String("aurora-cluster"),
+						Engine:              aws.String("aurora-postgresql"),
+						EngineVersion:       aws.String("15.4"),
+					},
+					{
+						DBInstanceArn: aws.String("arn:aws:rds:us-east-1:123123123123:db:mysql-instance"),
+						Engine:        aws.String("mysql"),
+						EngineVersion: aws.String("8.0.35"),
+					},
+				},
+			},
+		},
+	}
+	inputResources := []*model.TaggedResource{
+		{ARN: "arn:aws:rds:us-east-1:123123123123:cluster:aurora-cluster", Namespace: "AWS/RDS", Region: "us-east-1"},
+		{ARN: "arn:aws:rds:us-east-1:123123123123:db:aurora-instance-1", Namespace: "AWS/RDS", Region: "us-east-1"},
+		{ARN: "arn:aws:rds:us-east-1:123123123123:db:mysql-instance", Namespace: "AWS/RDS", Region: "us-east-1"},
+	}
+	expectedAttributes := [][]model.Tag{
+		{{Key: "cluster", Value: "aurora-cluster"}, {Key: "engine", Value: "aurora-postgresql"}, {Key: "engine_version", Value: "15.4"}},
+		{{Key: "cluster", Value: "aurora-cluster"}, {Key: "engine", Value: "aurora-postgresql"}, {Key: "engine_version", Value: "15.4"}},
+		{{Key: "cluster", Value: ""}, {Key: "engine", Value: "mysql"}, {Key: "engine_version", Value: "8.0.35"}},
+	}
+
+	err := ServiceFilters["AWS/RDS"].EnrichFunc(context.Background(), iface, inputResources)
+	if err != nil {
+		t.Fatalf("Error from EnrichFunc: %v", err)
+	}
+	for i, resource := range inputResources {
+		if !reflect.DeepEqual(resource.Attributes, expectedAttributes[i]) {
+			t.Errorf("inputResources[%d].Attributes = %+v, want %+v", i, resource.Attributes, expectedAttributes[i])
+		}
+	}
+}
+
+func TestEBSEnrichFunc(t *testing.T) {
+	iface := client{
+		ec2API: ec2Client{
+			describeVolumesOutput: &ec2.DescribeVolumesOutput{
+				Volumes: []*ec2.Volume{
+					{
+						VolumeId: aws.String("vol-0123456789abcdef0"),
+						Attachments: []*ec2.VolumeAttachment{
+							{InstanceId: aws.String("i-0123456789abcdef0"), Device: aws.String("/dev/xvda")},
+						},
+					},
+					{
+						VolumeId: aws.String("vol-0fedcba9876543210"),
+					},
+				},
+			},
+		},
+	}
+	inputResources := []*model.TaggedResource{
+		{ARN: "arn:aws:ec2:us-east-1:123123123123:volume/vol-0123456789abcdef0", Namespace: "AWS/EBS", Region: "us-east-1"},
+		{ARN: "arn:aws:ec2:us-east-1:123123123123:volume/vol-0fedcba9876543210", Namespace: "AWS/EBS", Region: "us-east-1"},
+	}
+	expectedAttributes := [][]model.Tag{
+		{{Key: "attached_instance_id", Value: "i-0123456789abcdef0"}, {Key: "attached_device", Value: "/dev/xvda"}},
+		{{Key: "attached_instance_id", Value: ""}, {Key: "attached_device", Value: ""}},
+	}
+
+	err := ServiceFilters["AWS/EBS"].EnrichFunc(context.Background(), iface, inputResources)
+	if err != nil {
+		t.Fatalf("Error from EnrichFunc: %v", err)
+	}
+	for i, resource := range inputResources {
+		if !reflect.DeepEqual(resource.Attributes, expectedAttributes[i]) {
+			t.Errorf("inputResources[%d].Attributes = %+v, want %+v", i, resource.Attributes, expectedAttributes[i])
+		}
+	}
+}
+
+func TestTransitGatewayResourceFunc(t *testing.T) {
+	iface := client{
+		ec2API: ec2Client{
+			describeTransitGatewayAttachmentsOutput: &ec2.DescribeTransitGatewayAttachmentsOutput{
+				TransitGatewayAttachments: []*ec2.TransitGatewayAttachment{
+					{
+						TransitGatewayId:           aws.String("tgw-0123456789abcdef0"),
+						TransitGatewayAttachmentId: aws.String("tgw-attach-0123456789abcdef0"),
+						ResourceType:               aws.String("vpc"),
+						ResourceId:                 aws.String("vpc-0123456789abcdef0"),
+						Tags: []*ec2.Tag{
+							{Key: aws.String("team"), Value: aws.String("network")},
+						},
+					},
+					{
+						TransitGatewayId:           aws.String("tgw-0123456789abcdef0"),
+						TransitGatewayAttachmentId: aws.String("tgw-attach-0fedcba9876543210"),
+						ResourceType:               aws.String("vpn"),
+						ResourceId:                 aws.String("vpn-0123456789abcdef0"),
+					},
+				},
+			},
+		},
+	}
+	job := model.DiscoveryJob{Type: "AWS/TransitGateway", AddResourceAttributes: true}
+	expectedResources := []*model.TaggedResource{
+		{
+			ARN:       "tgw-0123456789abcdef0/tgw-attach-0123456789abcdef0",
+			Namespace: "AWS/TransitGateway",
+			Region:    "us-east-1",
+			Tags:      []model.Tag{{Key: "team", Value: "network"}},
+			Attributes: []model.Tag{
+				{Key: "attachment_type", Value: "vpc"},
+				{Key: "attachment_resource_id", Value: "vpc-0123456789abcdef0"},
+			},
+		},
+		{
+			ARN:       "tgw-0123456789abcdef0/tgw-attach-0fedcba9876543210",
+			Namespace: "AWS/TransitGateway",
+			Region:    "us-east-1",
+			Attributes: []model.Tag{
+				{Key: "attachment_type", Value: "vpn"},
+				{Key: "attachment_resource_id", Value: "vpn-0123456789abcdef0"},
+			},
+		},
+	}
+
+	resources, err := ServiceFilters["AWS/TransitGateway"].ResourceFunc(context.Background(), iface, job, "us-east-1")
+	if err != nil {
+		t.Fatalf("Error from ResourceFunc: %v", err)
+	}
+	if !reflect.DeepEqual(resources, expectedResources) {
+		t.Errorf("resources = %+v, want %+v", resources, expectedResources)
+	}
+}
+
+func TestECSEnrichFunc(t *testing.T) {
+	iface := client{
+		ecsAPI: ecsClient{
+			describeServicesOutput: &ecs.DescribeServicesOutput{
+				Services: []*ecs.Service{
+					{
+						ServiceName:    aws.String("web"),
+						LaunchType:     aws.String("FARGATE"),
+						TaskDefinition: aws.String("arn:aws:ecs:us-east-1:123123123123:task-definition/web-app:42"),
+					},
+				},
+			},
+		},
+	}
+	inputResources := []*model.TaggedResource{
+		{ARN: "arn:aws:ecs:us-east-1:123123123123:cluster/production", Namespace: "AWS/ECS", Region: "us-east-1"},
+		{ARN: "arn:aws:ecs:us-east-1:123123123123:service/production/web", Namespace: "AWS/ECS", Region: "us-east-1"},
+	}
+	expectedAttributes := [][]model.Tag{
+		nil,
+		{{Key: "cluster", Value: "production"}, {Key: "launch_type", Value: "FARGATE"}, {Key: "task_definition_family", Value: "web-app"}},
+	}
+
+	for _, namespace := range []string{"AWS/ECS", "ECS/ContainerInsights"} {
+		t.Run(namespace, func(t *testing.T) {
+			err := ServiceFilters[namespace].EnrichFunc(context.Background(), iface, inputResources)
+			if err != nil {
+				t.Fatalf("Error from EnrichFunc: %v", err)
+			}
+			for i, resource := range inputResources {
+				if !reflect.DeepEqual(resource.Attributes, expectedAttributes[i]) {
+					t.Errorf("inputResources[%d].Attributes = %+v, want %+v", i, resource.Attributes, expectedAttributes[i])
+				}
+			}
+		})
+	}
+}
+
+type ecsClient struct {
+	ecsiface.ECSAPI
+	describeServicesOutput *ecs.DescribeServicesOutput
+}
+
+func (ecsClient ecsClient) DescribeServicesWithContext(_ aws.Context, _ *ecs.DescribeServicesInput, _ ...request.Option) (*ecs.DescribeServicesOutput, error) {
+	return ecsClient.describeServicesOutput, nil
+}
+
+func TestSQSInfoAttributesFunc(t *testing.T) {
+	iface := client{
+		sqsAPI: sqsClient{
+			queueAttributes: map[string]map[string]*string{
+				"https://sqs.us-east-1.amazonaws.com/123123123123/orders": {
+					"RedrivePolicy": aws.String(`{"deadLetterTargetArn":"arn:aws:sqs:us-east-1:123123123123:orders-dlq","maxReceiveCount":5}`),
+				},
+				"https://sqs.us-east-1.amazonaws.com/123123123123/orders-dlq": {},
+				"https://sqs.us-east-1.amazonaws.com/123123123123/events.fifo": {
+					"FifoQueue":     aws.String("true"),
+					"RedrivePolicy": aws.String(`{"deadLetterTargetArn":"arn:aws:sqs:us-east-1:123123123123:events-dlq.fifo","maxReceiveCount":"10"}`),
+				},
+			},
+		},
+	}
+	inputResources := []*model.TaggedResource{
+		{ARN: "arn:aws:sqs:us-east-1:123123123123:orders", Namespace: "AWS/SQS", Region: "us-east-1"},
+		{ARN: "arn:aws:sqs:us-east-1:123123123123:orders-dlq", Namespace: "AWS/SQS", Region: "us-east-1"},
+		{ARN: "arn:aws:sqs:us-east-1:123123123123:events.fifo", Namespace: "AWS/SQS", Region: "us-east-1"},
+	}
+	expectedAttributes := map[string][]model.Tag{
+		"arn:aws:sqs:us-east-1:123123123123:orders": {
+			{Key: "dead_letter_target_arn", Value: "arn:aws:sqs:us-east-1:123123123123:orders-dlq"},
+			{Key: "max_receive_count", Value: "5"},
+			{Key: "fifo_queue", Value: "false"},
+		},
+		"arn:aws:sqs:us-east-1:123123123123:orders-dlq": {
+			{Key: "dead_letter_target_arn", Value: ""},
+			{Key: "max_receive_count", Value: ""},
+			{Key: "fifo_queue", Value: "false"},
+		},
+		"arn:aws:sqs:us-east-1:123123123123:events.fifo": {
+			{Key: "dead_letter_target_arn", Value: "arn:aws:sqs:us-east-1:123123123123:events-dlq.fifo"},
+			{Key: "max_receive_count", Value: "10"},
+			{Key: "fifo_queue", Value: "true"},
+		},
+	}
+
+	attributes, err := ServiceFilters["AWS/SQS"].InfoAttributesFunc(context.Background(), iface, inputResources)
+	if err != nil {
+		t.Fatalf("Error from InfoAttributesFunc: %v", err)
+	}
+	if !reflect.DeepEqual(attributes, expectedAttributes) {
+		t.Errorf("attributes = %+v, want %+v", attributes, expectedAttributes)
+	}
+}
+
+func TestLogsInfoAttributesFunc(t *testing.T) {
+	iface := client{
+		logsAPI: logsClient{
+			describeLogGroupsOutput: &cloudwatchlogs.DescribeLogGroupsOutput{
+				LogGroups: []*cloudwatchlogs.LogGroup{
+					{
+						Arn:               aws.String("arn:aws:logs:us-east-1:123123123123:log-group:/aws/lambda/orders:*"),
+						LogGroupName:      aws.String("/aws/lambda/orders"),
+						RetentionInDays:   aws.Int64(14),
+						StoredBytes:       aws.Int64(1024),
+						MetricFilterCount: aws.Int64(2),
+						LogGroupClass:     aws.String("STANDARD"),
+					},
+					{
+						Arn:          aws.String("arn:aws:logs:us-east-1:123123123123:log-group:audit:*"),
+						LogGroupName: aws.String("audit"),
+					},
+				},
+			},
+		},
+	}
+	expectedAttributes := map[string][]model.Tag{
+		"arn:aws:logs:us-east-1:123123123123:log-group:/aws/lambda/orders": {
+			{Key: "retention_in_days", Value: "14"},
+			{Key: "log_group_class", Value: "STANDARD"},
+		},
+		"arn:aws:logs:us-east-1:123123123123:log-group:audit": {
+			{Key: "retention_in_days", Value: ""},
+			{Key: "log_group_class", Value: ""},
+		},
+	}
+
+	attributes, err := ServiceFilters["AWS/Logs"].InfoAttributesFunc(context.Background(), iface, nil)
+	if err != nil {
+		t.Fatalf("Error from InfoAttributesFunc: %v", err)
+	}
+	if !reflect.DeepEqual(attributes, expectedAttributes) {
+		t.Errorf("attributes = %+v, want %+v", attributes, expectedAttributes)
+	}
+
+	resources := []*model.TaggedResource{
+		{ARN: "arn:aws:logs:us-east-1:123123123123:log-group:/aws/lambda/orders"},
+		{ARN: "arn:aws:logs:us-east-1:123123123123:log-group:not-described"},
+	}
+	if err := ServiceFilters["AWS/Logs"].ValuesFunc(context.Background(), iface, resources); err != nil {
+		t.Fatalf("Error from ValuesFunc: %v", err)
+	}
+	expectedValues := []model.ResourceValue{
+		{Name: "stored_bytes", Value: 1024},
+		{Name: "metric_filter_count", Value: 2},
+	}
+	if !reflect.DeepEqual(resources[0].Values, expectedValues) {
+		t.Errorf("values = %+v, want %+v", resources[0].Values, expectedValues)
+	}
+	if resources[1].Values != nil {
+		t.Errorf("values = %+v, want none", resources[1].Values)
+	}
+}
+
+type logsClient struct {
+	cloudwatchlogsiface.CloudWatchLogsAPI
+	describeLogGroupsOutput *cloudwatchlogs.DescribeLogGroupsOutput
+}
+
+func (logsClient logsClient) DescribeLogGroupsPagesWithContext(_ aws.Context, _ *cloudwatchlogs.DescribeLogGroupsInput, fn func(*cloudwatchlogs.DescribeLogGroupsOutput, bool) bool, _ ...request.Option) error {
+	fn(logsClient.describeLogGroupsOutput, true)
+	return nil
+}
+
+func TestSyntheticsInfoAttributesFunc(t *testing.T) {
+	iface := client{
+		syntheticsAPI: syntheticsClient{
+			describeCanariesOutput: &synthetics.DescribeCanariesOutput{
+				Canaries: []*synthetics.Canary{
+					{
+						Name:           aws.String("checkout"),
+						RuntimeVersion: aws.String("syn-nodejs-puppeteer-6.2"),
+						Schedule:       &synthetics.CanaryScheduleOutput{Expression: aws.String("rate(5 minutes)")},
+					},
+					{
+						Name:           aws.String("not-discovered"),
+						RuntimeVersion: aws.String("syn-python-selenium-2.0"),
+					},
+				},
+			},
+		},
+	}
+	resources := []*model.TaggedResource{
+		{
+			ARN:       "arn:aws:synthetics:us-east-1:123123123123:canary:checkout",
+			Namespace: "CloudWatchSynthetics",
+		},
+	}
+	expectedAttributes := map[string][]model.Tag{
+		"arn:aws:synthetics:us-east-1:123123123123:canary:checkout": {
+			{Key: "runtime_version", Value: "syn-nodejs-puppeteer-6.2"},
+			{Key: "schedule_expression", Value: "rate(5 minutes)"},
+		},
+	}
+
+	attributes, err := ServiceFilters["CloudWatchSynthetics"].InfoAttributesFunc(context.Background(), iface, resources)
+	if err != nil {
+		t.Fatalf("Error from InfoAttributesFunc: %v", err)
+	}
+	if !reflect.DeepEqual(attributes, expectedAttributes) {
+		t.Errorf("attributes = %+v, want %+v", attributes, expectedAttributes)
+	}
+}
+
+type syntheticsClient struct {
+	syntheticsiface.SyntheticsAPI
+	describeCanariesOutput *synthetics.DescribeCanariesOutput
+}
+
+func (syntheticsClient syntheticsClient) DescribeCanariesPagesWithContext(_ aws.Context, _ *synthetics.DescribeCanariesInput, fn func(*synthetics.DescribeCanariesOutput, bool) bool, _ ...request.Option) error {
+	fn(syntheticsClient.describeCanariesOutput, true)
+	return nil
+}
+
+func TestS3InfoAttributesFunc(t *testing.T) {
+	iface := client{
+		s3API: s3Client{
+			metricsConfigurations: map[string][]*s3.ListBucketMetricsConfigurationsOutput{
+				"images": {
+					{
+						IsTruncated:           aws.Bool(true),
+						NextContinuationToken: aws.String("next"),
+						MetricsConfigurationList: []*s3.MetricsConfiguration{
+							{Id: aws.String("thumbnails")},
+						},
+					},
+					{
+						MetricsConfigurationList: []*s3.MetricsConfiguration{
+							{Id: aws.String("EntireBucket")},
+						},
+					},
+				},
+				"logs": {
+					{},
+				},
+			},
+		},
+	}
+	resources := []*model.TaggedResource{
+		{ARN: "arn:aws:s3:::images", Namespace: "AWS/S3"},
+		{ARN: "arn:aws:s3:::logs", Namespace: "AWS/S3"},
+	}
+	expectedAttributes := map[string][]model.Tag{
+		"arn:aws:s3:::images": {
+			{Key: "metrics_filter_ids", Value: "EntireBucket,thumbnails"},
+		},
+		"arn:aws:s3:::logs": {
+			{Key: "metrics_filter_ids", Value: ""},
+		},
+	}
+
+	attributes, err := ServiceFilters["AWS/S3"].InfoAttributesFunc(context.Background(), iface, resources)
+	if err != nil {
+		t.Fatalf("Error from InfoAttributesFunc: %v", err)
+	}
+	if !reflect.DeepEqual(attributes, expectedAttributes) {
+		t.Errorf("attributes = %+v, want %+v", attributes, expectedAttributes)
+	}
+}
+
+type s3Client struct {
+	s3iface.S3API
+	// pages of metrics configurations by bucket
+	metricsConfigurations map[string][]*s3.ListBucketMetricsConfigurationsOutput
+}
+
+func (s3Client s3Client) ListBucketMetricsConfigurationsWithContext(_ aws.Context, input *s3.ListBucketMetricsConfigurationsInput, _ ...request.Option) (*s3.ListBucketMetricsConfigurationsOutput, error) {
+	pages := s3Client.metricsConfigurations[aws.StringValue(input.Bucket)]
+	if input.ContinuationToken != nil {
+		return pages[1], nil
+	}
+	return pages[0], nil
+}
+
+type sqsClient struct {
+	sqsiface.SQSAPI
+	queueAttributes map[string]map[string]*string
+}
+
+func (sqsClient sqsClient) GetQueueUrlWithContext(_ aws.Context, input *sqs.GetQueueUrlInput, _ ...request.Option) (*sqs.GetQueueUrlOutput, error) {
+	return &sqs.GetQueueUrlOutput{
+		QueueUrl: aws.String("https://sqs.us-east-1.amazonaws.com/" + aws.StringValue(input.QueueOwnerAWSAccountId) + "/" + aws.StringValue(input.QueueName)),
+	}, nil
+}
+
+func (sqsClient sqsClient) GetQueueAttributesWithContext(_ aws.Context, input *sqs.GetQueueAttributesInput, _ ...request.Option) (*sqs.GetQueueAttributesOutput, error) {
+	return &sqs.GetQueueAttributesOutput{Attributes: sqsClient.queueAttributes[aws.StringValue(input.QueueUrl)]}, nil
+}
+
+type rdsClient struct {
+	rdsiface.RDSAPI
+	describeDBClustersOutput  *rds.DescribeDBClustersOutput
+	describeDBInstancesOutput *rds.DescribeDBInstancesOutput
+}
+
+func (rdsClient rdsClient) DescribeDBClustersPagesWithContext(_ aws.Context, _ *rds.DescribeDBClustersInput, fn func(*rds.DescribeDBClustersOutput, bool) bool, _ ...request.Option) error {
+	fn(rdsClient.describeDBClustersOutput, true)
+	return nil
+}
+
+func (rdsClient rdsClient) DescribeDBInstancesPagesWithContext(_ aws.Context, _ *rds.DescribeDBInstancesInput, fn func(*rds.DescribeDBInstancesOutput, bool) bool, _ ...request.Option) error {
+	fn(rdsClient.describeDBInstancesOutput, true)
+	return nil
+}
+
+type ec2Client struct {
+	ec2iface.EC2API
+	describeInstancesOutput                 *ec2.DescribeInstancesOutput
+	describeVolumesOutput                   *ec2.DescribeVolumesOutput
+	describeTransitGatewayAttachmentsOutput *ec2.DescribeTransitGatewayAttachmentsOutput
+}
+
+func (ec2Client ec2Client) DescribeTransitGatewayAttachmentsPagesWithContext(_ aws.Context, _ *ec2.DescribeTransitGatewayAttachmentsInput, fn func(*ec2.DescribeTransitGatewayAttachmentsOutput, bool) bool, _ ...request.Option) error {
+	fn(ec2Client.describeTransitGatewayAttachmentsOutput, true)
+	return nil
+}
+
+func (ec2Client ec2Client) DescribeVolumesPagesWithContext(_ aws.Context, _ *ec2.DescribeVolumesInput, fn func(*ec2.DescribeVolumesOutput, bool) bool, _ ...request.Option) error {
+	fn(ec2Client.describeVolumesOutput, true)
+	return nil
+}
+
+func (ec2Client ec2Client) DescribeInstancesPagesWithContext(_ aws.Context, _ *ec2.DescribeInstancesInput, fn func(*ec2.DescribeInstancesOutput, bool) bool, _ ...request.Option) error {
+	fn(ec2Client.describeInstancesOutput, true)
+	return nil
+}
+
 type dmsClient struct {
 	databasemigrationserviceiface.DatabaseMigrationServiceAPI
 	describeReplicationInstancesOutput *databasemigrationservice.DescribeReplicationInstancesOutput
@@ -9,12 +9,22 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/apigateway"
 	"github.com/aws/aws-sdk-go-v2/service/apigatewayv2"
 	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
 	"github.com/aws/aws-sdk-go-v2/service/databasemigrationservice"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/elasticache"
+	"github.com/aws/aws-sdk-go-v2/service/kafka"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftserverless"
 	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
 	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/shield"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/aws/aws-sdk-go-v2/service/storagegateway"
+	"github.com/aws/aws-sdk-go-v2/service/synthetics"
 
 	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/tagging"
 	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
@@ -25,6 +35,7 @@ import (
 
 type client struct {
 	logger            logging.Logger
+	attributesCache   *tagging.AttributesCache
 	taggingAPI        *resourcegroupstaggingapi.Client
 	autoscalingAPI    *autoscaling.Client
 	apiGatewayAPI     *apigateway.Client
@@ -34,10 +45,21 @@ type client struct {
 	prometheusSvcAPI  *amp.Client
 	storageGatewayAPI *storagegateway.Client
 	shieldAPI         *shield.Client
+	rdsAPI            *rds.Client
+	ecsAPI            *ecs.Client
+	elasticacheAPI    *elasticache.Client
+	sqsAPI            *sqs.Client
+	dynamoDBAPI       *dynamodb.Client
+	logsAPI           *cloudwatchlogs.Client
+	syntheticsAPI     *synthetics.Client
+	s3API             *s3.Client
+	kafkaAPI          *kafka.Client
+	redshiftAPI       *redshiftserverless.Client
 }
 
 func NewClient(
 	logger logging.Logger,
+	attributesCache *tagging.AttributesCache,
 	taggingAPI *resourcegroupstaggingapi.Client,
 	autoscalingAPI *autoscaling.Client,
 	apiGatewayAPI *apigateway.Client,
@@ -47,9 +69,20 @@ func NewClient(
 	prometheusClient *amp.Client,
 	storageGatewayAPI *storagegateway.Client,
 	shieldAPI *shield.Client,
+	rdsAPI *rds.Client,
+	ecsAPI *ecs.Client,
+	elasticacheAPI *elasticache.Client,
+	sqsAPI *sqs.Client,
+	dynamoDBAPI *dynamodb.Client,
+	logsAPI *cloudwatchlogs.Client,
+	syntheticsAPI *synthetics.Client,
+	s3API *s3.Client,
+	kafkaAPI *kafka.Client,
+	redshiftAPI *redshiftserverless.Client,
 ) tagging.Client {
 	return &client{
 		logger:            logger,
+		attributesCache:   attributesCache,
 		taggingAPI:        taggingAPI,
 		autoscalingAPI:    autoscalingAPI,
 		apiGatewayAPI:     apiGatewayAPI,
@@ -59,6 +92,16 @@ func NewClient(
 		prometheusSvcAPI:  prometheusClient,
 		storageGatewayAPI: storageGatewayAPI,
 		shieldAPI:         shieldAPI,
+		rdsAPI:            rdsAPI,
+		ecsAPI:            ecsAPI,
+		elasticacheAPI:    elasticacheAPI,
+		sqsAPI:            sqsAPI,
+		dynamoDBAPI:       dynamoDBAPI,
+		logsAPI:           logsAPI,
+		syntheticsAPI:     syntheticsAPI,
+		s3API:             s3API,
+		kafkaAPI:          kafkaAPI,
+		redshiftAPI:       redshiftAPI,
 	}
 }
 
@@ -146,6 +189,31 @@ func (c client) GetResources(ctx context.Context, job model.DiscoveryJob, region
 			resources = filteredResources
 			c.logger.Debug("FilterFunc finished", "total", len(resources))
 		}
+
+		if job.AddResourceAttributes && ext.EnrichFunc != nil {
+			// Attributes are best effort, a failure should not prevent exporting metrics
+			if err := ext.EnrichFunc(ctx, c, resources); err != nil {
+				c.logger.Error(err, "failed to apply EnrichFunc", "namespace", svc.Namespace)
+			} else {
+				c.logger.Debug("EnrichFunc finished", "total", len(resources))
+			}
+		}
+
+		if len(job.InfoMetricAttributes) > 0 && ext.InfoAttributesFunc != nil {
+			err := tagging.SetInfoAttributes(c.attributesCache, resources, job.InfoMetricAttributes, func(uncached []*model.TaggedResource) (map[string][]model.Tag, error) {
+				return ext.InfoAttributesFunc(ctx, c, uncached)
+			})
+			if err != nil {
+				c.logger.Error(err, "failed to apply InfoAttributesFunc", "namespace", svc.Namespace)
+			}
+		}
+
+		if job.AddLogGroupMetrics && ext.ValuesFunc != nil {
+			// Values are best effort, like the attributes
+			if err := ext.ValuesFunc(ctx, c, resources); err != nil {
+				c.logger.Error(err, "failed to apply ValuesFunc", "namespace", svc.Namespace)
+			}
+		}
 	}
 
 	if shouldHaveDiscoveredResources && len(resources) == 0 {
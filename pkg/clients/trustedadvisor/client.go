@@ -0,0 +1,85 @@
+package trustedadvisor
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+// Language of the check names, the support API requires one.
+const Language = "en"
+
+type Client interface {
+	// DescribeChecks returns the latest results of the Trusted Advisor checks
+	// of the job categories, or of all checks if none are configured.
+	DescribeChecks(ctx context.Context, job model.TrustedAdvisorJob) ([]*model.TrustedAdvisorCheck, error)
+}
+
+// FilterCategory returns true if the checks of the category should be returned.
+func FilterCategory(categories []string, category string) bool {
+	return len(categories) == 0 || slices.Contains(categories, category)
+}
+
+// Cache caches the check results of the jobs, Trusted Advisor
+// refreshes checks at most every few minutes and most of them
+// only daily. It is safe for concurrent use.
+type Cache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	checks    []*model.TrustedAdvisorCheck
+	expiresAt time.Time
+}
+
+func NewCache() *Cache {
+	return &Cache{
+		entries: map[string]cacheEntry{},
+	}
+}
+
+type cachingClient struct {
+	client Client
+	cache  *Cache
+	role   model.Role
+}
+
+// NewCachingClient returns a Client which only calls the support API
+// once per refresh interval of the job. Failed calls are not cached.
+func NewCachingClient(client Client, cache *Cache, role model.Role) Client {
+	return &cachingClient{
+		client: client,
+		cache:  cache,
+		role:   role,
+	}
+}
+
+func (c cachingClient) DescribeChecks(ctx context.Context, job model.TrustedAdvisorJob) ([]*model.TrustedAdvisorCheck, error) {
+	key := fmt.Sprintf("%s|%s|%s", c.role.RoleArn, c.role.ExternalID, strings.Join(job.Categories, ","))
+
+	c.cache.mu.Lock()
+	entry, ok := c.cache.entries[key]
+	c.cache.mu.Unlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.checks, nil
+	}
+
+	checks, err := c.client.DescribeChecks(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+
+	c.cache.mu.Lock()
+	c.cache.entries[key] = cacheEntry{
+		checks:    checks,
+		expiresAt: time.Now().Add(job.RefreshInterval),
+	}
+	c.cache.mu.Unlock()
+	return checks, nil
+}
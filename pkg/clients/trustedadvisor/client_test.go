@@ -0,0 +1,46 @@
+package trustedadvisor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+func TestFilterCategory(t *testing.T) {
+	require.True(t, FilterCategory(nil, "security"))
+	require.True(t, FilterCategory([]string{"security", "service_limits"}, "security"))
+	require.False(t, FilterCategory([]string{"service_limits"}, "security"))
+}
+
+type countingClient struct {
+	calls int
+}
+
+func (c *countingClient) DescribeChecks(_ context.Context, _ model.TrustedAdvisorJob) ([]*model.TrustedAdvisorCheck, error) {
+	c.calls++
+	return []*model.TrustedAdvisorCheck{{ID: "Pfx0RwqBli", Name: "Amazon S3 Bucket Permissions", Category: "security", Status: "ok"}}, nil
+}
+
+func TestCachingClient(t *testing.T) {
+	ctx := context.Background()
+	underlying := &countingClient{}
+	cache := NewCache()
+	job := model.TrustedAdvisorJob{RefreshInterval: time.Hour}
+
+	checks, err := NewCachingClient(underlying, cache, model.Role{}).DescribeChecks(ctx, job)
+	require.NoError(t, err)
+	require.Len(t, checks, 1)
+
+	// A new client, as created on every scrape, uses the same cache
+	_, err = NewCachingClient(underlying, cache, model.Role{}).DescribeChecks(ctx, job)
+	require.NoError(t, err)
+	require.Equal(t, 1, underlying.calls)
+
+	_, err = NewCachingClient(underlying, cache, model.Role{}).DescribeChecks(ctx, model.TrustedAdvisorJob{Categories: []string{"security"}, RefreshInterval: time.Hour})
+	require.NoError(t, err)
+	require.Equal(t, 2, underlying.calls, "checks of other categories should not be served from the cache")
+}
@@ -0,0 +1,79 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/support"
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/trustedadvisor"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/promutil"
+)
+
+type client struct {
+	logger     logging.Logger
+	supportAPI *support.Client
+}
+
+func NewClient(logger logging.Logger, supportAPI *support.Client) trustedadvisor.Client {
+	return &client{
+		logger:     logger,
+		supportAPI: supportAPI,
+	}
+}
+
+func (c client) DescribeChecks(ctx context.Context, job model.TrustedAdvisorJob) ([]*model.TrustedAdvisorCheck, error) {
+	promutil.SupportAPICounter.Inc()
+	descriptions, err := c.supportAPI.DescribeTrustedAdvisorChecks(ctx, &support.DescribeTrustedAdvisorChecksInput{
+		Language: aws.String(trustedadvisor.Language),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error calling supportAPI.DescribeTrustedAdvisorChecks, %w", err)
+	}
+
+	checks := make(map[string]*model.TrustedAdvisorCheck, len(descriptions.Checks))
+	checkIDs := make([]string, 0, len(descriptions.Checks))
+	for _, description := range descriptions.Checks {
+		category := aws.StringValue(description.Category)
+		if !trustedadvisor.FilterCategory(job.Categories, category) {
+			continue
+		}
+		checks[aws.StringValue(description.Id)] = &model.TrustedAdvisorCheck{
+			ID:       aws.StringValue(description.Id),
+			Name:     aws.StringValue(description.Name),
+			Category: category,
+		}
+		checkIDs = append(checkIDs, aws.StringValue(description.Id))
+	}
+	if len(checkIDs) == 0 {
+		return nil, nil
+	}
+
+	promutil.SupportAPICounter.Inc()
+	summaries, err := c.supportAPI.DescribeTrustedAdvisorCheckSummaries(ctx, &support.DescribeTrustedAdvisorCheckSummariesInput{
+		CheckIds: checkIDs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error calling supportAPI.DescribeTrustedAdvisorCheckSummaries, %w", err)
+	}
+
+	output := make([]*model.TrustedAdvisorCheck, 0, len(summaries.Summaries))
+	for _, summary := range summaries.Summaries {
+		check, ok := checks[aws.StringValue(summary.CheckId)]
+		if !ok {
+			continue
+		}
+		check.Status = aws.StringValue(summary.Status)
+		if resources := summary.ResourcesSummary; resources != nil {
+			check.ResourcesFlagged = resources.ResourcesFlagged
+			check.ResourcesProcessed = resources.ResourcesProcessed
+			check.ResourcesSuppressed = resources.ResourcesSuppressed
+			check.ResourcesIgnored = resources.ResourcesIgnored
+		}
+		output = append(output, check)
+	}
+	return output, nil
+}
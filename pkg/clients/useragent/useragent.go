@@ -0,0 +1,74 @@
+// Package useragent identifies the AWS API requests of the exporter, adding the
+// job making the request to the user agent, as recorded by CloudTrail, so that
+// the API volume can be attributed to the jobs.
+package useragent
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TraceHeader is the header of the trace ID of a request.
+const TraceHeader = "X-Amzn-Trace-Id"
+
+var (
+	version     = "unknown"
+	traceHeader bool
+)
+
+// Configure sets the version of the exporter in the user agent, and whether a trace
+// header is added to the requests. It must be called before creating the clients.
+func Configure(exporterVersion string, withTraceHeader bool) {
+	version = exporterVersion
+	traceHeader = withTraceHeader
+}
+
+type jobKey struct{}
+
+// WithJob returns a context whose AWS API requests are made for the job.
+func WithJob(ctx context.Context, job string) context.Context {
+	return context.WithValue(ctx, jobKey{}, job)
+}
+
+// Job returns the job the requests made with the context are made for, empty when not set.
+func Job(ctx context.Context) string {
+	job, _ := ctx.Value(jobKey{}).(string)
+	return job
+}
+
+// Value returns the user agent of the requests made with the context,
+// yace/<version>/<job>, the job being omitted when not set.
+func Value(ctx context.Context) string {
+	value := "yace/" + sanitize(version)
+	if job := Job(ctx); job != "" {
+		value += "/" + sanitize(job)
+	}
+	return value
+}
+
+// TraceID returns a new trace ID for the TraceHeader of a request, empty when disabled.
+func TraceID() string {
+	if !traceHeader {
+		return ""
+	}
+	random := make([]byte, 12)
+	_, _ = rand.Read(random)
+	return fmt.Sprintf("Root=1-%08x-%s", time.Now().Unix(), hex.EncodeToString(random))
+}
+
+// sanitize replaces the characters not allowed in a user agent product token, other
+// than the slashes of the namespaces, e.g. AWS/EC2, by underscores.
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', strings.ContainsRune("!#$%&'*+-.^_`|~/", r):
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
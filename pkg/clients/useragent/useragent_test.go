@@ -0,0 +1,28 @@
+package useragent
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValue(t *testing.T) {
+	t.Cleanup(func() { Configure("unknown", false) })
+	Configure("v0.61.2", false)
+
+	require.Equal(t, "yace/v0.61.2", Value(context.Background()))
+	require.Equal(t, "yace/v0.61.2/AWS/EC2", Value(WithJob(context.Background(), "AWS/EC2")))
+	require.Equal(t, "yace/v0.61.2/my_billing_job", Value(WithJob(context.Background(), "my billing job")))
+}
+
+func TestTraceID(t *testing.T) {
+	t.Cleanup(func() { Configure("unknown", false) })
+	require.Empty(t, TraceID())
+
+	Configure("v0.61.2", true)
+	first, second := TraceID(), TraceID()
+	require.Regexp(t, regexp.MustCompile(`^Root=1-[0-9a-f]{8}-[0-9a-f]{24}$`), first)
+	require.NotEqual(t, first, second)
+}
@@ -1,14 +1,17 @@
 package v1
 
 import (
+	"cmp"
 	"os"
 	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/apigateway"
 	"github.com/aws/aws-sdk-go/service/apigateway/apigatewayiface"
@@ -17,52 +20,102 @@ import (
 	"github.com/aws/aws-sdk-go/service/autoscaling"
 	"github.com/aws/aws-sdk-go/service/autoscaling/autoscalingiface"
 	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
+	"github.com/aws/aws-sdk-go/service/costexplorer"
+	"github.com/aws/aws-sdk-go/service/costexplorer/costexploreriface"
 	"github.com/aws/aws-sdk-go/service/databasemigrationservice"
 	"github.com/aws/aws-sdk-go/service/databasemigrationservice/databasemigrationserviceiface"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/ecs/ecsiface"
+	"github.com/aws/aws-sdk-go/service/elasticache"
+	"github.com/aws/aws-sdk-go/service/elasticache/elasticacheiface"
+	"github.com/aws/aws-sdk-go/service/kafka"
+	"github.com/aws/aws-sdk-go/service/kafka/kafkaiface"
 	"github.com/aws/aws-sdk-go/service/prometheusservice"
 	"github.com/aws/aws-sdk-go/service/prometheusservice/prometheusserviceiface"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/aws/aws-sdk-go/service/rds/rdsiface"
+	"github.com/aws/aws-sdk-go/service/redshiftserverless"
+	"github.com/aws/aws-sdk-go/service/redshiftserverless/redshiftserverlessiface"
 	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/servicequotas"
+	"github.com/aws/aws-sdk-go/service/servicequotas/servicequotasiface"
 	"github.com/aws/aws-sdk-go/service/shield"
 	"github.com/aws/aws-sdk-go/service/shield/shieldiface"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
 	"github.com/aws/aws-sdk-go/service/storagegateway"
 	"github.com/aws/aws-sdk-go/service/storagegateway/storagegatewayiface"
 	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/aws/aws-sdk-go/service/sts/stsiface"
+	"github.com/aws/aws-sdk-go/service/support"
+	"github.com/aws/aws-sdk-go/service/support/supportiface"
+	"github.com/aws/aws-sdk-go/service/synthetics"
+	"github.com/aws/aws-sdk-go/service/synthetics/syntheticsiface"
 
 	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients"
 	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/account"
 	account_v1 "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/account/v1"
 	cloudwatch_client "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/cloudwatch"
 	cloudwatch_v1 "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/cloudwatch/v1"
+	contributorinsights_client "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/contributorinsights"
+	contributorinsights_v1 "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/contributorinsights/v1"
+	costexplorer_client "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/costexplorer"
+	costexplorer_v1 "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/costexplorer/v1"
+	logsinsights_client "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/logsinsights"
+	logsinsights_v1 "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/logsinsights/v1"
+	servicequotas_client "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/servicequotas"
+	servicequotas_v1 "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/servicequotas/v1"
 	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/tagging"
 	tagging_v1 "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/tagging/v1"
+	trustedadvisor_client "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/trustedadvisor"
+	trustedadvisor_v1 "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/trustedadvisor/v1"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/useragent"
 	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
 	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
 )
 
 type CachingFactory struct {
-	stsRegion        string
-	session          *session.Session
-	endpointResolver endpoints.ResolverFunc
-	stscache         map[model.Role]stsiface.STSAPI
-	clients          map[model.Role]map[string]*cachedClients
-	cleared          bool
-	refreshed        bool
-	mu               sync.Mutex
-	fips             bool
-	logger           logging.Logger
+	stsRegion                string
+	session                  *session.Session
+	partitions               map[string]model.PartitionConfig
+	partitionSessions        map[string]*session.Session
+	endpointResolver         endpoints.ResolverFunc
+	stscache                 map[model.Role]stsiface.STSAPI
+	clients                  map[model.Role]map[string]*cachedClients
+	cleared                  bool
+	refreshed                bool
+	mu                       sync.Mutex
+	fips                     bool
+	logger                   logging.Logger
+	attributesCache          *tagging.AttributesCache
+	costCache                *costexplorer_client.Cache
+	contributorInsightsCache *contributorinsights_client.Cache
+	logsInsightsCache        *logsinsights_client.Cache
+	trustedAdvisorCache      *trustedadvisor_client.Cache
+	retries                  map[string]model.RetryConfig
 }
 
 type cachedClients struct {
 	// if we know that this job is only used for static
 	// then we don't have to construct as many cached connections
 	// later on
-	onlyStatic bool
-	cloudwatch cloudwatch_client.Client
-	tagging    tagging.Client
-	account    account.Client
+	onlyStatic          bool
+	cloudwatch          cloudwatch_client.Client
+	tagging             tagging.Client
+	account             account.Client
+	costExplorer        costexplorer_client.Client
+	serviceQuotas       servicequotas_client.Client
+	trustedAdvisor      trustedadvisor_client.Client
+	logsInsights        logsinsights_client.Client
+	contributorInsights contributorinsights_client.Client
 }
 
 // Ensure the struct properly implements the interface
@@ -129,6 +182,128 @@ func NewFactory(logger logging.Logger, jobsCfg model.JobsConfig, fips bool) *Cac
 		}
 	}
 
+	for _, inventoryJob := range jobsCfg.InventoryJobs {
+		for _, role := range inventoryJob.Roles {
+			if _, ok := stscache[role]; !ok {
+				stscache[role] = nil
+			}
+
+			if _, ok := cache[role]; !ok {
+				cache[role] = map[string]*cachedClients{}
+			}
+
+			for _, region := range inventoryJob.Regions {
+				// Inventory jobs need the tagging client, like discovery jobs
+				if cached, ok := cache[role][region]; ok {
+					cached.onlyStatic = false
+				} else {
+					cache[role][region] = &cachedClients{}
+				}
+			}
+		}
+	}
+
+	for _, costExplorerJob := range jobsCfg.CostExplorerJobs {
+		for _, role := range costExplorerJob.Roles {
+			if _, ok := stscache[role]; !ok {
+				stscache[role] = nil
+			}
+
+			if _, ok := cache[role]; !ok {
+				cache[role] = map[string]*cachedClients{}
+			}
+
+			// Only write a new region in if the region does not exist
+			if _, ok := cache[role][costExplorerJob.Region]; !ok {
+				cache[role][costExplorerJob.Region] = &cachedClients{
+					onlyStatic: true,
+				}
+			}
+		}
+	}
+
+	for _, serviceQuotaJob := range jobsCfg.ServiceQuotaJobs {
+		for _, role := range serviceQuotaJob.Roles {
+			if _, ok := stscache[role]; !ok {
+				stscache[role] = nil
+			}
+
+			if _, ok := cache[role]; !ok {
+				cache[role] = map[string]*cachedClients{}
+			}
+
+			for _, region := range serviceQuotaJob.Regions {
+				// Only write a new region in if the region does not exist
+				if _, ok := cache[role][region]; !ok {
+					cache[role][region] = &cachedClients{
+						onlyStatic: true,
+					}
+				}
+			}
+		}
+	}
+
+	for _, trustedAdvisorJob := range jobsCfg.TrustedAdvisorJobs {
+		for _, role := range trustedAdvisorJob.Roles {
+			if _, ok := stscache[role]; !ok {
+				stscache[role] = nil
+			}
+
+			if _, ok := cache[role]; !ok {
+				cache[role] = map[string]*cachedClients{}
+			}
+
+			// Only write a new region in if the region does not exist
+			if _, ok := cache[role][trustedAdvisorJob.Region]; !ok {
+				cache[role][trustedAdvisorJob.Region] = &cachedClients{
+					onlyStatic: true,
+				}
+			}
+		}
+	}
+
+	for _, logsInsightsJob := range jobsCfg.LogsInsightsJobs {
+		for _, role := range logsInsightsJob.Roles {
+			if _, ok := stscache[role]; !ok {
+				stscache[role] = nil
+			}
+
+			if _, ok := cache[role]; !ok {
+				cache[role] = map[string]*cachedClients{}
+			}
+
+			for _, region := range logsInsightsJob.Regions {
+				// Only write a new region in if the region does not exist
+				if _, ok := cache[role][region]; !ok {
+					cache[role][region] = &cachedClients{
+						onlyStatic: true,
+					}
+				}
+			}
+		}
+	}
+
+	for _, contributorInsightsJob := range jobsCfg.ContributorInsightsJobs {
+		for _, role := range contributorInsightsJob.Roles {
+			if _, ok := stscache[role]; !ok {
+				stscache[role] = nil
+			}
+
+			if _, ok := cache[role]; !ok {
+				cache[role] = map[string]*cachedClients{}
+			}
+
+			for _, region := range contributorInsightsJob.Regions {
+				// Only write a new region in if the region does not exist
+				if _, ok := cache[role][region]; !ok {
+					cache[role][region] = &cachedClients{
+						onlyStatic: true,
+					}
+				}
+			}
+		}
+	}
+
 	endpointResolver := endpoints.DefaultResolver().EndpointFor
 
 	endpointURLOverride := os.Getenv("AWS_ENDPOINT_URL")
@@ -142,15 +317,23 @@ func NewFactory(logger logging.Logger, jobsCfg model.JobsConfig, fips bool) *Cac
 	}
 
 	return &CachingFactory{
-		stsRegion:        jobsCfg.StsRegion,
-		session:          nil,
-		endpointResolver: endpointResolver,
-		stscache:         stscache,
-		clients:          cache,
-		fips:             fips,
-		cleared:          false,
-		refreshed:        false,
-		logger:           logger,
+		stsRegion:                jobsCfg.StsRegion,
+		session:                  nil,
+		partitions:               jobsCfg.Partitions,
+		partitionSessions:        map[string]*session.Session{},
+		endpointResolver:         endpointResolver,
+		stscache:                 stscache,
+		clients:                  cache,
+		fips:                     fips,
+		cleared:                  false,
+		refreshed:                false,
+		logger:                   logger,
+		attributesCache:          tagging.NewAttributesCache(tagging.DefaultInfoAttributesCacheTTL),
+		costCache:                costexplorer_client.NewCache(),
+		contributorInsightsCache: contributorinsights_client.NewCache(),
+		logsInsightsCache:        logsinsights_client.NewCache(),
+		trustedAdvisorCache:      trustedadvisor_client.NewCache(),
+		retries:                  jobsCfg.Retries,
 	}
 }
 
@@ -171,6 +354,11 @@ func (c *CachingFactory) Clear() {
 			cachedClient.account = nil
 			cachedClient.cloudwatch = nil
 			cachedClient.tagging = nil
+			cachedClient.costExplorer = nil
+			cachedClient.serviceQuotas = nil
+			cachedClient.trustedAdvisor = nil
+			cachedClient.logsInsights = nil
+			cachedClient.contributorInsights = nil
 		}
 	}
 	c.cleared = true
@@ -193,9 +381,14 @@ func (c *CachingFactory) Refresh() {
 	if c.session == nil {
 		c.session = createAWSSession(c.endpointResolver, c.logger.IsDebugEnabled())
 	}
+	for name, partition := range c.partitions {
+		if c.partitionSessions[name] == nil {
+			c.partitionSessions[name] = createPartitionSession(c.endpointResolver, partition, c.logger.IsDebugEnabled())
+		}
+	}
 
 	for role := range c.stscache {
-		c.stscache[role] = createStsSession(c.session, role, c.stsRegion, c.fips, c.logger.IsDebugEnabled())
+		c.stscache[role] = createStsSession(c.session, role, c.stsRegion, c.fips, c.logger.IsDebugEnabled(), c.retries[model.APISTS])
 	}
 
 	for role, regions := range c.clients {
@@ -204,12 +397,12 @@ func (c *CachingFactory) Refresh() {
 			// if the role is just used in static jobs, then we
 			// can skip creating other sessions and potentially running
 			// into permissions errors or taking up needless cycles
-			cachedClient.cloudwatch = createCloudWatchClient(c.logger, c.session, &region, role, c.fips)
+			cachedClient.cloudwatch = createCloudWatchClient(c.logger, c.sessionFor(region), &region, role, c.fips, c.retries[model.APICloudWatch])
 			if cachedClient.onlyStatic {
 				continue
 			}
-			cachedClient.tagging = createTaggingClient(c.logger, c.session, &region, role, c.fips)
-			cachedClient.account = createAccountClient(c.logger, c.stscache[role])
+			cachedClient.tagging = createTaggingClient(c.logger, c.attributesCache, c.sessionFor(region), &region, role, c.fips, c.retries[model.APITagging])
+			cachedClient.account = createAccountClient(c.logger, c.stsClient(role, region))
 		}
 	}
 
@@ -217,20 +410,40 @@ func (c *CachingFactory) Refresh() {
 	c.refreshed = true
 }
 
-func createCloudWatchClient(logger logging.Logger, s *session.Session, region *string, role model.Role, fips bool) cloudwatch_client.Client {
+// sessionFor returns the session of the partition of the region, the default
+// session when the partition has no credentials of its own.
+func (c *CachingFactory) sessionFor(region string) *session.Session {
+	if sess, ok := c.partitionSessions[model.PartitionOf(region)]; ok {
+		return sess
+	}
+	return c.session
+}
+
+// stsClient returns the STS client of the role in the region. The roles of the partitions
+// with credentials of their own get a client of the partition, with its STS endpoint.
+func (c *CachingFactory) stsClient(role model.Role, region string) stsiface.STSAPI {
+	partition, ok := c.partitions[model.PartitionOf(region)]
+	if !ok {
+		return c.stscache[role]
+	}
+	return createStsSession(c.sessionFor(region), role, cmp.Or(partition.StsRegion, region), c.fips, c.logger.IsDebugEnabled(), c.retries[model.APISTS])
+}
+
+func createCloudWatchClient(logger logging.Logger, s *session.Session, region *string, role model.Role, fips bool, retry model.RetryConfig) cloudwatch_client.Client {
 	return cloudwatch_v1.NewClient(
 		logger,
-		createCloudwatchSession(s, region, role, fips, logger.IsDebugEnabled()),
+		createCloudwatchSession(s, region, role, fips, logger.IsDebugEnabled(), retry),
 	)
 }
 
-func createTaggingClient(logger logging.Logger, session *session.Session, region *string, role model.Role, fips bool) tagging.Client {
+func createTaggingClient(logger logging.Logger, attributesCache *tagging.AttributesCache, session *session.Session, region *string, role model.Role, fips bool, retry model.RetryConfig) tagging.Client {
 	// The createSession function for a service which does not support FIPS does not take a fips parameter
 	// This currently applies to createTagSession(Resource Groups Tagging), ASG (EC2 autoscaling), and Prometheus (Amazon Managed Prometheus)
 	// AWS FIPS Reference: https://aws.amazon.com/compliance/fips/
 	return tagging_v1.NewClient(
 		logger,
-		createTagSession(session, region, role, logger.IsDebugEnabled()),
+		attributesCache,
+		createTagSession(session, region, role, logger.IsDebugEnabled(), retry),
 		createASGSession(session, region, role, logger.IsDebugEnabled()),
 		createAPIGatewaySession(session, region, role, fips, logger.IsDebugEnabled()),
 		createAPIGatewayV2Session(session, region, role, fips, logger.IsDebugEnabled()),
@@ -239,6 +452,16 @@ func createTaggingClient(logger logging.Logger, session *session.Session, region
 		createPrometheusSession(session, region, role, logger.IsDebugEnabled()),
 		createStorageGatewaySession(session, region, role, fips, logger.IsDebugEnabled()),
 		createShieldSession(session, region, role, fips, logger.IsDebugEnabled()),
+		createRDSSession(session, region, role, fips, logger.IsDebugEnabled()),
+		createECSSession(session, region, role, fips, logger.IsDebugEnabled()),
+		createElastiCacheSession(session, region, role, fips, logger.IsDebugEnabled()),
+		createSQSSession(session, region, role, fips, logger.IsDebugEnabled()),
+		createDynamoDBSession(session, region, role, fips, logger.IsDebugEnabled()),
+		createCloudWatchLogsSession(session, region, role, fips, logger.IsDebugEnabled()),
+		createSyntheticsSession(session, region, role, logger.IsDebugEnabled()),
+		createS3Session(session, region, role, fips, logger.IsDebugEnabled()),
+		createKafkaSession(session, region, role, fips, logger.IsDebugEnabled()),
+		createRedshiftServerlessSession(session, region, role, fips, logger.IsDebugEnabled()),
 	)
 }
 
@@ -253,10 +476,10 @@ func (c *CachingFactory) GetCloudwatchClient(region string, role model.Role, con
 		defer c.mu.Unlock()
 	}
 	if client := c.clients[role][region].cloudwatch; client != nil {
-		return cloudwatch_client.NewLimitedConcurrencyClient(client, concurrency.NewLimiter())
+		return cloudwatch_client.NewLimitedConcurrencyClient(client, concurrency.NewLimiter(region, role), concurrency.NewRequestLimiter(region, role, c.accountClient(region, role)))
 	}
-	c.clients[role][region].cloudwatch = createCloudWatchClient(c.logger, c.session, &region, role, c.fips)
-	return cloudwatch_client.NewLimitedConcurrencyClient(c.clients[role][region].cloudwatch, concurrency.NewLimiter())
+	c.clients[role][region].cloudwatch = createCloudWatchClient(c.logger, c.sessionFor(region), &region, role, c.fips, c.retries[model.APICloudWatch])
+	return cloudwatch_client.NewLimitedConcurrencyClient(c.clients[role][region].cloudwatch, concurrency.NewLimiter(region, role), concurrency.NewRequestLimiter(region, role, c.accountClient(region, role)))
 }
 
 func (c *CachingFactory) GetTaggingClient(region string, role model.Role, concurrencyLimit int) tagging.Client {
@@ -268,7 +491,7 @@ func (c *CachingFactory) GetTaggingClient(region string, role model.Role, concur
 	if client := c.clients[role][region].tagging; client != nil {
 		return tagging.NewLimitedConcurrencyClient(client, concurrencyLimit)
 	}
-	c.clients[role][region].tagging = createTaggingClient(c.logger, c.session, &region, role, c.fips)
+	c.clients[role][region].tagging = createTaggingClient(c.logger, c.attributesCache, c.sessionFor(region), &region, role, c.fips, c.retries[model.APITagging])
 	return tagging.NewLimitedConcurrencyClient(c.clients[role][region].tagging, concurrencyLimit)
 }
 
@@ -278,13 +501,100 @@ func (c *CachingFactory) GetAccountClient(region string, role model.Role) accoun
 		c.mu.Lock()
 		defer c.mu.Unlock()
 	}
+	return c.accountClient(region, role)
+}
+
+// accountClient returns the account client of the region and role, the caller holds the lock if needed.
+func (c *CachingFactory) accountClient(region string, role model.Role) account.Client {
 	if client := c.clients[role][region].account; client != nil {
 		return client
 	}
-	c.clients[role][region].account = createAccountClient(c.logger, c.stscache[role])
+	c.clients[role][region].account = createAccountClient(c.logger, c.stsClient(role, region))
 	return c.clients[role][region].account
 }
 
+func (c *CachingFactory) GetCostExplorerClient(region string, role model.Role) costexplorer_client.Client {
+	if !c.refreshed {
+		// if we have not refreshed then we need to lock in case we are accessing concurrently
+		c.mu.Lock()
+		defer c.mu.Unlock()
+	}
+	if client := c.clients[role][region].costExplorer; client != nil {
+		return costexplorer_client.NewCachingClient(client, c.costCache, role)
+	}
+	// The Cost Explorer API does not have FIPS endpoints
+	c.clients[role][region].costExplorer = costexplorer_v1.NewClient(
+		c.logger,
+		createCostExplorerSession(c.sessionFor(region), &region, role, c.logger.IsDebugEnabled()),
+	)
+	return costexplorer_client.NewCachingClient(c.clients[role][region].costExplorer, c.costCache, role)
+}
+
+func (c *CachingFactory) GetServiceQuotasClient(region string, role model.Role) servicequotas_client.Client {
+	if !c.refreshed {
+		// if we have not refreshed then we need to lock in case we are accessing concurrently
+		c.mu.Lock()
+		defer c.mu.Unlock()
+	}
+	if client := c.clients[role][region].serviceQuotas; client != nil {
+		return client
+	}
+	c.clients[role][region].serviceQuotas = servicequotas_v1.NewClient(
+		c.logger,
+		createServiceQuotasSession(c.sessionFor(region), &region, role, c.fips, c.logger.IsDebugEnabled()),
+	)
+	return c.clients[role][region].serviceQuotas
+}
+
+func (c *CachingFactory) GetTrustedAdvisorClient(region string, role model.Role) trustedadvisor_client.Client {
+	if !c.refreshed {
+		// if we have not refreshed then we need to lock in case we are accessing concurrently
+		c.mu.Lock()
+		defer c.mu.Unlock()
+	}
+	if client := c.clients[role][region].trustedAdvisor; client != nil {
+		return trustedadvisor_client.NewCachingClient(client, c.trustedAdvisorCache, role)
+	}
+	// The support API does not have FIPS endpoints
+	c.clients[role][region].trustedAdvisor = trustedadvisor_v1.NewClient(
+		c.logger,
+		createTrustedAdvisorSession(c.sessionFor(region), &region, role, c.logger.IsDebugEnabled()),
+	)
+	return trustedadvisor_client.NewCachingClient(c.clients[role][region].trustedAdvisor, c.trustedAdvisorCache, role)
+}
+
+func (c *CachingFactory) GetLogsInsightsClient(region string, role model.Role) logsinsights_client.Client {
+	if !c.refreshed {
+		// if we have not refreshed then we need to lock in case we are accessing concurrently
+		c.mu.Lock()
+		defer c.mu.Unlock()
+	}
+	if client := c.clients[role][region].logsInsights; client != nil {
+		return logsinsights_client.NewCachingClient(client, c.logsInsightsCache, role, region)
+	}
+	c.clients[role][region].logsInsights = logsinsights_v1.NewClient(
+		c.logger,
+		createCloudWatchLogsSession(c.sessionFor(region), &region, role, c.fips, c.logger.IsDebugEnabled()),
+	)
+	return logsinsights_client.NewCachingClient(c.clients[role][region].logsInsights, c.logsInsightsCache, role, region)
+}
+
+func (c *CachingFactory) GetContributorInsightsClient(region string, role model.Role) contributorinsights_client.Client {
+	if !c.refreshed {
+		// if we have not refreshed then we need to lock in case we are accessing concurrently
+		c.mu.Lock()
+		defer c.mu.Unlock()
+	}
+	if client := c.clients[role][region].contributorInsights; client != nil {
+		return contributorinsights_client.NewCachingClient(client, c.contributorInsightsCache, role, region)
+	}
+	c.clients[role][region].contributorInsights = contributorinsights_v1.NewClient(
+		c.logger,
+		createCloudwatchSession(c.sessionFor(region), &region, role, c.fips, c.logger.IsDebugEnabled(), c.retries[model.APICloudWatch]),
+	)
+	return contributorinsights_client.NewCachingClient(c.clients[role][region].contributorInsights, c.contributorInsightsCache, role, region)
+}
+
 func setExternalID(ID string) func(p *stscreds.AssumeRoleProvider) {
 	return func(p *stscreds.AssumeRoleProvider) {
 		if ID != "" {
@@ -293,15 +603,44 @@ func setExternalID(ID string) func(p *stscreds.AssumeRoleProvider) {
 	}
 }
 
+func setSessionDuration(duration time.Duration) func(p *stscreds.AssumeRoleProvider) {
+	return func(p *stscreds.AssumeRoleProvider) {
+		if duration > 0 {
+			p.Duration = duration
+		}
+	}
+}
+
 func setSTSCreds(sess *session.Session, config *aws.Config, role model.Role) *aws.Config {
 	if role.RoleArn != "" {
-		config.Credentials = stscreds.NewCredentials(
-			sess, role.RoleArn, setExternalID(role.ExternalID))
+		config.Credentials = stscreds.NewCredentialsWithClient(
+			sts.New(sess, assumeRoleConfig(role)), role.RoleArn, setExternalID(role.ExternalID), setSessionDuration(role.SessionDuration))
 	}
 	return config
 }
 
-func getAwsRetryer() aws.RequestRetryer {
+// assumeRoleConfig returns the config of the STS client assuming the role,
+// with the STS endpoint and the retries of the role.
+func assumeRoleConfig(role model.Role) *aws.Config {
+	config := &aws.Config{Retryer: newRetryer(model.APISTS, client.DefaultRetryer{NumMaxRetries: client.DefaultRetryerMaxNumRetries}, role.Retry)}
+	return withStsEndpoint(config, role.StsRegion)
+}
+
+// withStsEndpoint sets the STS endpoint of the region, or the global
+// endpoint for model.StsRegionGlobal.
+func withStsEndpoint(config *aws.Config, region string) *aws.Config {
+	switch region {
+	case "":
+		return config
+	case model.StsRegionGlobal:
+		// The legacy endpoint of us-east-1 is the global endpoint
+		return config.WithRegion(endpoints.UsEast1RegionID).WithSTSRegionalEndpoint(endpoints.LegacySTSEndpoint)
+	default:
+		return config.WithRegion(region).WithSTSRegionalEndpoint(endpoints.RegionalSTSEndpoint)
+	}
+}
+
+func getAwsRetryer() client.DefaultRetryer {
 	return client.DefaultRetryer{
 		NumMaxRetries: 5,
 		// MaxThrottleDelay and MinThrottleDelay used for throttle errors
@@ -314,10 +653,23 @@ func getAwsRetryer() aws.RequestRetryer {
 }
 
 func createAWSSession(resolver endpoints.ResolverFunc, isDebugEnabled bool) *session.Session {
+	return createPartitionSession(resolver, model.PartitionConfig{}, isDebugEnabled)
+}
+
+// createPartitionSession creates a session with the credentials of the partition, its
+// profile or static credentials, or the default credentials chain when neither is set.
+func createPartitionSession(resolver endpoints.ResolverFunc, partition model.PartitionConfig, isDebugEnabled bool) *session.Session {
 	config := aws.Config{
 		CredentialsChainVerboseErrors: aws.Bool(true),
 		EndpointResolver:              resolver,
 	}
+	if partition.AccessKeyID != "" {
+		config.Credentials = credentials.NewStaticCredentials(partition.AccessKeyID, partition.SecretAccessKey, "")
+	}
+	if partition.StsRegion != "" {
+		// The region of the STS requests assuming the roles, the clients have their own region
+		config.Region = aws.String(partition.StsRegion)
+	}
 
 	if isDebugEnabled {
 		config.LogLevel = aws.LogLevel(aws.LogDebugWithHTTPBody)
@@ -325,18 +677,35 @@ func createAWSSession(resolver endpoints.ResolverFunc, isDebugEnabled bool) *ses
 
 	sess := session.Must(session.NewSessionWithOptions(session.Options{
 		SharedConfigState: session.SharedConfigEnable,
+		Profile:           partition.Profile,
 		Config:            config,
 	}))
+	sess.Handlers.Build.PushBackNamed(userAgentHandler)
 	return sess
 }
 
-func createStsSession(sess *session.Session, role model.Role, region string, fips bool, isDebugEnabled bool) *sts.STS {
+// userAgentHandler adds the job making the request to the user agent, and a trace header if enabled.
+var userAgentHandler = request.NamedHandler{
+	Name: "yace.UserAgentHandler",
+	Fn: func(r *request.Request) {
+		request.AddToUserAgent(r, useragent.Value(r.Context()))
+		if traceID := useragent.TraceID(); traceID != "" {
+			r.HTTPRequest.Header.Set(useragent.TraceHeader, traceID)
+		}
+	},
+}
+
+func createStsSession(sess *session.Session, role model.Role, region string, fips bool, isDebugEnabled bool, retry model.RetryConfig) *sts.STS {
 	maxStsRetries := 5
-	config := &aws.Config{MaxRetries: &maxStsRetries}
+	if role.Retry != (model.RetryConfig{}) {
+		retry = role.Retry
+	}
+	config := &aws.Config{Retryer: newRetryer(model.APISTS, client.DefaultRetryer{NumMaxRetries: maxStsRetries}, retry)}
 
-	if region != "" {
-		config = config.WithRegion(region).WithSTSRegionalEndpoint(endpoints.RegionalSTSEndpoint)
+	if role.StsRegion != "" {
+		region = role.StsRegion
 	}
+	config = withStsEndpoint(config, region)
 
 	if fips {
 		config.UseFIPSEndpoint = endpoints.FIPSEndpointStateEnabled
@@ -349,8 +718,8 @@ func createStsSession(sess *session.Session, role model.Role, region string, fip
 	return sts.New(sess, setSTSCreds(sess, config, role))
 }
 
-func createCloudwatchSession(sess *session.Session, region *string, role model.Role, fips bool, isDebugEnabled bool) *cloudwatch.CloudWatch {
-	config := &aws.Config{Region: region, Retryer: getAwsRetryer()}
+func createCloudwatchSession(sess *session.Session, region *string, role model.Role, fips bool, isDebugEnabled bool, retry model.RetryConfig) *cloudwatch.CloudWatch {
+	config := &aws.Config{Region: region, Retryer: newRetryer(model.APICloudWatch, getAwsRetryer(), retry)}
 
 	if fips {
 		config.UseFIPSEndpoint = endpoints.FIPSEndpointStateEnabled
@@ -363,11 +732,11 @@ func createCloudwatchSession(sess *session.Session, region *string, role model.R
 	return cloudwatch.New(sess, setSTSCreds(sess, config, role))
 }
 
-func createTagSession(sess *session.Session, region *string, role model.Role, isDebugEnabled bool) *resourcegroupstaggingapi.ResourceGroupsTaggingAPI {
+func createTagSession(sess *session.Session, region *string, role model.Role, isDebugEnabled bool, retry model.RetryConfig) *resourcegroupstaggingapi.ResourceGroupsTaggingAPI {
 	maxResourceGroupTaggingRetries := 5
 	config := &aws.Config{
 		Region:                        region,
-		MaxRetries:                    &maxResourceGroupTaggingRetries,
+		Retryer:                       newRetryer(model.APITagging, client.DefaultRetryer{NumMaxRetries: maxResourceGroupTaggingRetries}, retry),
 		CredentialsChainVerboseErrors: aws.Bool(true),
 	}
 
@@ -484,3 +853,177 @@ func createShieldSession(sess *session.Session, region *string, role model.Role,
 
 	return shield.New(sess, setSTSCreds(sess, config, role))
 }
+
+func createRDSSession(sess *session.Session, region *string, role model.Role, fips bool, isDebugEnabled bool) rdsiface.RDSAPI {
+	maxRDSAPIRetries := 5
+	config := &aws.Config{Region: region, MaxRetries: &maxRDSAPIRetries}
+	if fips {
+		config.UseFIPSEndpoint = endpoints.FIPSEndpointStateEnabled
+	}
+
+	if isDebugEnabled {
+		config.LogLevel = aws.LogLevel(aws.LogDebugWithHTTPBody)
+	}
+
+	return rds.New(sess, setSTSCreds(sess, config, role))
+}
+
+func createECSSession(sess *session.Session, region *string, role model.Role, fips bool, isDebugEnabled bool) ecsiface.ECSAPI {
+	maxECSAPIRetries := 5
+	config := &aws.Config{Region: region, MaxRetries: &maxECSAPIRetries}
+	if fips {
+		config.UseFIPSEndpoint = endpoints.FIPSEndpointStateEnabled
+	}
+
+	if isDebugEnabled {
+		config.LogLevel = aws.LogLevel(aws.LogDebugWithHTTPBody)
+	}
+
+	return ecs.New(sess, setSTSCreds(sess, config, role))
+}
+
+func createElastiCacheSession(sess *session.Session, region *string, role model.Role, fips bool, isDebugEnabled bool) elasticacheiface.ElastiCacheAPI {
+	maxElastiCacheAPIRetries := 5
+	config := &aws.Config{Region: region, MaxRetries: &maxElastiCacheAPIRetries}
+	if fips {
+		config.UseFIPSEndpoint = endpoints.FIPSEndpointStateEnabled
+	}
+
+	if isDebugEnabled {
+		config.LogLevel = aws.LogLevel(aws.LogDebugWithHTTPBody)
+	}
+
+	return elasticache.New(sess, setSTSCreds(sess, config, role))
+}
+
+func createSQSSession(sess *session.Session, region *string, role model.Role, fips bool, isDebugEnabled bool) sqsiface.SQSAPI {
+	maxSQSAPIRetries := 5
+	config := &aws.Config{Region: region, MaxRetries: &maxSQSAPIRetries}
+	if fips {
+		config.UseFIPSEndpoint = endpoints.FIPSEndpointStateEnabled
+	}
+
+	if isDebugEnabled {
+		config.LogLevel = aws.LogLevel(aws.LogDebugWithHTTPBody)
+	}
+
+	return sqs.New(sess, setSTSCreds(sess, config, role))
+}
+
+func createDynamoDBSession(sess *session.Session, region *string, role model.Role, fips bool, isDebugEnabled bool) dynamodbiface.DynamoDBAPI {
+	maxDynamoDBAPIRetries := 5
+	config := &aws.Config{Region: region, MaxRetries: &maxDynamoDBAPIRetries}
+	if fips {
+		config.UseFIPSEndpoint = endpoints.FIPSEndpointStateEnabled
+	}
+
+	if isDebugEnabled {
+		config.LogLevel = aws.LogLevel(aws.LogDebugWithHTTPBody)
+	}
+
+	return dynamodb.New(sess, setSTSCreds(sess, config, role))
+}
+
+func createCloudWatchLogsSession(sess *session.Session, region *string, role model.Role, fips bool, isDebugEnabled bool) cloudwatchlogsiface.CloudWatchLogsAPI {
+	maxCloudWatchLogsAPIRetries := 5
+	config := &aws.Config{Region: region, MaxRetries: &maxCloudWatchLogsAPIRetries}
+	if fips {
+		config.UseFIPSEndpoint = endpoints.FIPSEndpointStateEnabled
+	}
+
+	if isDebugEnabled {
+		config.LogLevel = aws.LogLevel(aws.LogDebugWithHTTPBody)
+	}
+
+	return cloudwatchlogs.New(sess, setSTSCreds(sess, config, role))
+}
+
+func createSyntheticsSession(sess *session.Session, region *string, role model.Role, isDebugEnabled bool) syntheticsiface.SyntheticsAPI {
+	maxSyntheticsAPIRetries := 5
+	config := &aws.Config{Region: region, MaxRetries: &maxSyntheticsAPIRetries}
+
+	if isDebugEnabled {
+		config.LogLevel = aws.LogLevel(aws.LogDebugWithHTTPBody)
+	}
+
+	return synthetics.New(sess, setSTSCreds(sess, config, role))
+}
+
+func createS3Session(sess *session.Session, region *string, role model.Role, fips bool, isDebugEnabled bool) s3iface.S3API {
+	maxS3APIRetries := 5
+	config := &aws.Config{Region: region, MaxRetries: &maxS3APIRetries}
+	if fips {
+		config.UseFIPSEndpoint = endpoints.FIPSEndpointStateEnabled
+	}
+
+	if isDebugEnabled {
+		config.LogLevel = aws.LogLevel(aws.LogDebugWithHTTPBody)
+	}
+
+	return s3.New(sess, setSTSCreds(sess, config, role))
+}
+
+func createKafkaSession(sess *session.Session, region *string, role model.Role, fips bool, isDebugEnabled bool) kafkaiface.KafkaAPI {
+	maxKafkaAPIRetries := 5
+	config := &aws.Config{Region: region, MaxRetries: &maxKafkaAPIRetries}
+	if fips {
+		config.UseFIPSEndpoint = endpoints.FIPSEndpointStateEnabled
+	}
+
+	if isDebugEnabled {
+		config.LogLevel = aws.LogLevel(aws.LogDebugWithHTTPBody)
+	}
+
+	return kafka.New(sess, setSTSCreds(sess, config, role))
+}
+
+func createRedshiftServerlessSession(sess *session.Session, region *string, role model.Role, fips bool, isDebugEnabled bool) redshiftserverlessiface.RedshiftServerlessAPI {
+	maxRedshiftServerlessAPIRetries := 5
+	config := &aws.Config{Region: region, MaxRetries: &maxRedshiftServerlessAPIRetries}
+	if fips {
+		config.UseFIPSEndpoint = endpoints.FIPSEndpointStateEnabled
+	}
+
+	if isDebugEnabled {
+		config.LogLevel = aws.LogLevel(aws.LogDebugWithHTTPBody)
+	}
+
+	return redshiftserverless.New(sess, setSTSCreds(sess, config, role))
+}
+
+func createCostExplorerSession(sess *session.Session, region *string, role model.Role, isDebugEnabled bool) costexploreriface.CostExplorerAPI {
+	maxCostExplorerAPIRetries := 5
+	config := &aws.Config{Region: region, MaxRetries: &maxCostExplorerAPIRetries}
+
+	if isDebugEnabled {
+		config.LogLevel = aws.LogLevel(aws.LogDebugWithHTTPBody)
+	}
+
+	return costexplorer.New(sess, setSTSCreds(sess, config, role))
+}
+
+func createServiceQuotasSession(sess *session.Session, region *string, role model.Role, fips bool, isDebugEnabled bool) servicequotasiface.ServiceQuotasAPI {
+	maxServiceQuotasAPIRetries := 5
+	config := &aws.Config{Region: region, MaxRetries: &maxServiceQuotasAPIRetries}
+
+	if fips {
+		config.UseFIPSEndpoint = endpoints.FIPSEndpointStateEnabled
+	}
+
+	if isDebugEnabled {
+		config.LogLevel = aws.LogLevel(aws.LogDebugWithHTTPBody)
+	}
+
+	return servicequotas.New(sess, setSTSCreds(sess, config, role))
+}
+
+func createTrustedAdvisorSession(sess *session.Session, region *string, role model.Role, isDebugEnabled bool) supportiface.SupportAPI {
+	maxTrustedAdvisorAPIRetries := 5
+	config := &aws.Config{Region: region, MaxRetries: &maxTrustedAdvisorAPIRetries}
+
+	if isDebugEnabled {
+		config.LogLevel = aws.LogLevel(aws.LogDebugWithHTTPBody)
+	}
+
+	return support.New(sess, setSTSCreds(sess, config, role))
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
@@ -493,8 +494,8 @@ func TestClear(t *testing.T) {
 				clients: map[model.Role]map[string]*cachedClients{
 					{}: {
 						"us-east-1": &cachedClients{
-							cloudwatch: createCloudWatchClient(logging.NewNopLogger(), mock.Session, &region, role, false),
-							tagging:    createTaggingClient(logging.NewNopLogger(), mock.Session, &region, role, false),
+							cloudwatch: createCloudWatchClient(logging.NewNopLogger(), mock.Session, &region, role, false, model.RetryConfig{}),
+							tagging:    createTaggingClient(logging.NewNopLogger(), nil, mock.Session, &region, role, false, model.RetryConfig{}),
 							account:    createAccountClient(logging.NewNopLogger(), nil),
 							onlyStatic: true,
 						},
@@ -627,14 +628,14 @@ func TestRefresh(t *testing.T) {
 				mu:        sync.Mutex{},
 				session:   mock.Session,
 				stscache: map[model.Role]stsiface.STSAPI{
-					{}: createStsSession(mock.Session, role, "", false, false),
+					{}: createStsSession(mock.Session, role, "", false, false, model.RetryConfig{}),
 				},
 				clients: map[model.Role]map[string]*cachedClients{
 					{}: {
 						"us-east-1": &cachedClients{
-							cloudwatch: createCloudWatchClient(logging.NewNopLogger(), mock.Session, &region, role, false),
-							tagging:    createTaggingClient(logging.NewNopLogger(), mock.Session, &region, role, false),
-							account:    createAccountClient(logging.NewNopLogger(), createStsSession(mock.Session, role, "", false, false)),
+							cloudwatch: createCloudWatchClient(logging.NewNopLogger(), mock.Session, &region, role, false, model.RetryConfig{}),
+							tagging:    createTaggingClient(logging.NewNopLogger(), nil, mock.Session, &region, role, false, model.RetryConfig{}),
+							account:    createAccountClient(logging.NewNopLogger(), createStsSession(mock.Session, role, "", false, false, model.RetryConfig{})),
 						},
 					},
 				},
@@ -750,9 +751,9 @@ func testGetAWSClient(
 				clients: map[model.Role]map[string]*cachedClients{
 					{}: {
 						"us-east-1": &cachedClients{
-							cloudwatch: createCloudWatchClient(logging.NewNopLogger(), mock.Session, &region, role, false),
-							tagging:    createTaggingClient(logging.NewNopLogger(), mock.Session, &region, role, false),
-							account:    createAccountClient(logging.NewNopLogger(), createStsSession(mock.Session, role, "", false, false)),
+							cloudwatch: createCloudWatchClient(logging.NewNopLogger(), mock.Session, &region, role, false, model.RetryConfig{}),
+							tagging:    createTaggingClient(logging.NewNopLogger(), nil, mock.Session, &region, role, false, model.RetryConfig{}),
+							account:    createAccountClient(logging.NewNopLogger(), createStsSession(mock.Session, role, "", false, false, model.RetryConfig{})),
 						},
 					},
 				},
@@ -772,9 +773,9 @@ func testGetAWSClient(
 				clients: map[model.Role]map[string]*cachedClients{
 					{}: {
 						"us-east-1": &cachedClients{
-							cloudwatch: createCloudWatchClient(logging.NewNopLogger(), mock.Session, &region, role, false),
-							tagging:    createTaggingClient(logging.NewNopLogger(), mock.Session, &region, role, false),
-							account:    createAccountClient(logging.NewNopLogger(), createStsSession(mock.Session, role, "", false, false)),
+							cloudwatch: createCloudWatchClient(logging.NewNopLogger(), mock.Session, &region, role, false, model.RetryConfig{}),
+							tagging:    createTaggingClient(logging.NewNopLogger(), nil, mock.Session, &region, role, false, model.RetryConfig{}),
+							account:    createAccountClient(logging.NewNopLogger(), createStsSession(mock.Session, role, "", false, false, model.RetryConfig{})),
 						},
 					},
 				},
@@ -852,6 +853,45 @@ func TestSetExternalID(t *testing.T) {
 	}
 }
 
+func TestSetSessionDuration(t *testing.T) {
+	p := &stscreds.AssumeRoleProvider{Duration: stscreds.DefaultDuration}
+	setSessionDuration(0)(p)
+	require.Equal(t, stscreds.DefaultDuration, p.Duration)
+
+	setSessionDuration(time.Hour)(p)
+	require.Equal(t, time.Hour, p.Duration)
+}
+
+func TestWithStsEndpoint(t *testing.T) {
+	tests := []struct {
+		descrip  string
+		region   string
+		expected *aws.Config
+	}{
+		{
+			"keeps the default endpoint without region",
+			"",
+			&aws.Config{},
+		},
+		{
+			"uses the regional endpoint of the region",
+			"cn-north-1",
+			&aws.Config{Region: aws.String("cn-north-1"), STSRegionalEndpoint: endpoints.RegionalSTSEndpoint},
+		},
+		{
+			"uses the legacy global endpoint",
+			model.StsRegionGlobal,
+			&aws.Config{Region: aws.String("us-east-1"), STSRegionalEndpoint: endpoints.LegacySTSEndpoint},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.descrip, func(t *testing.T) {
+			require.Equal(t, test.expected, withStsEndpoint(&aws.Config{}, test.region))
+		})
+	}
+}
+
 func TestSetSTSCreds(t *testing.T) {
 	tests := []struct {
 		descrip        string
@@ -921,6 +961,24 @@ func TestCreateAWSSession(t *testing.T) {
 	}
 }
 
+func TestCachingFactory_PartitionSessions(t *testing.T) {
+	cache := NewFactory(logging.NewNopLogger(), model.JobsConfig{
+		Partitions: map[string]model.PartitionConfig{
+			model.PartitionChina: {AccessKeyID: "AKIDCHINA", SecretAccessKey: "secret"},
+		},
+		StaticJobs: []model.StaticJob{{
+			Regions: []string{"eu-west-1", "cn-north-1"},
+			Roles:   []model.Role{{}},
+		}},
+	}, false)
+	cache.Refresh()
+
+	require.Same(t, cache.session, cache.sessionFor("eu-west-1"))
+	credentials, err := cache.sessionFor("cn-north-1").Config.Credentials.Get()
+	require.NoError(t, err)
+	require.Equal(t, "AKIDCHINA", credentials.AccessKeyID)
+}
+
 func TestCreateStsSession(t *testing.T) {
 	tests := []struct {
 		descrip   string
@@ -966,7 +1024,7 @@ func TestCreateStsSession(t *testing.T) {
 		t.Run(test.descrip, func(t *testing.T) {
 			t.Parallel()
 			// just exercise the code path
-			iface := createStsSession(mock.Session, test.role, test.stsRegion, false, false)
+			iface := createStsSession(mock.Session, test.role, test.stsRegion, false, false, model.RetryConfig{})
 			if iface == nil {
 				t.Fail()
 			}
@@ -979,7 +1037,7 @@ func TestCreateCloudwatchSession(t *testing.T) {
 		t,
 		"Cloudwatch",
 		func(t *testing.T, s *session.Session, region *string, role model.Role, fips bool) {
-			iface := createCloudwatchSession(s, region, role, fips, false)
+			iface := createCloudwatchSession(s, region, role, fips, false, model.RetryConfig{})
 			if iface == nil {
 				t.Fail()
 			}
@@ -991,7 +1049,7 @@ func TestCreateTagSession(t *testing.T) {
 		t,
 		"Tag",
 		func(t *testing.T, s *session.Session, region *string, role model.Role, fips bool) {
-			iface := createTagSession(s, region, role, fips)
+			iface := createTagSession(s, region, role, fips, model.RetryConfig{})
 			if iface == nil {
 				t.Fail()
 			}
@@ -1116,7 +1174,7 @@ func TestSTSResolvesFIPSEnabledEndpoints(t *testing.T) {
 
 			mockSession.Config.Endpoint = nil
 
-			sess := createStsSession(mock.Session, model.Role{}, tc.region, true, false)
+			sess := createStsSession(mock.Session, model.Role{}, tc.region, true, false, model.RetryConfig{})
 			require.NotNil(t, sess)
 
 			require.True(t, called, "expected endpoint resolver to be called")
@@ -0,0 +1,37 @@
+package v1
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/request"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/retries"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+// countingRetryer counts the retries of the requests to an API.
+type countingRetryer struct {
+	client.DefaultRetryer
+	api string
+}
+
+func (r countingRetryer) RetryRules(req *request.Request) time.Duration {
+	retries.Observe(req.Context(), r.api, req.Operation.Name, req.IsErrorThrottle())
+	return r.DefaultRetryer.RetryRules(req)
+}
+
+// newRetryer returns the retryer of the requests to an API, with the configured
+// retries overriding the defaults of the client. The SDK v1 does not implement
+// the adaptive mode, the retries are always made in the standard mode.
+func newRetryer(api string, defaults client.DefaultRetryer, cfg model.RetryConfig) aws.RequestRetryer {
+	if cfg.MaxAttempts > 0 {
+		defaults.NumMaxRetries = cfg.MaxAttempts - 1
+	}
+	if cfg.MaxBackoff > 0 {
+		defaults.MaxRetryDelay = cfg.MaxBackoff
+		defaults.MaxThrottleDelay = cfg.MaxBackoff
+	}
+	return countingRetryer{DefaultRetryer: defaults, api: api}
+}
@@ -1,36 +1,62 @@
 package v2
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"os"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/aws/retry"
 	aws_config "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	"github.com/aws/aws-sdk-go-v2/service/amp"
 	"github.com/aws/aws-sdk-go-v2/service/apigateway"
 	"github.com/aws/aws-sdk-go-v2/service/apigatewayv2"
 	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
 	"github.com/aws/aws-sdk-go-v2/service/databasemigrationservice"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/elasticache"
+	"github.com/aws/aws-sdk-go-v2/service/kafka"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftserverless"
 	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/servicequotas"
 	"github.com/aws/aws-sdk-go-v2/service/shield"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/aws/aws-sdk-go-v2/service/storagegateway"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/aws-sdk-go-v2/service/support"
+	"github.com/aws/aws-sdk-go-v2/service/synthetics"
 	aws_logging "github.com/aws/smithy-go/logging"
+	"github.com/aws/smithy-go/middleware"
 
 	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients"
 	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/account"
 	account_v2 "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/account/v2"
 	cloudwatch_client "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/cloudwatch"
 	cloudwatch_v2 "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/cloudwatch/v2"
+	contributorinsights_client "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/contributorinsights"
+	contributorinsights_v2 "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/contributorinsights/v2"
+	costexplorer_client "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/costexplorer"
+	costexplorer_v2 "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/costexplorer/v2"
+	logsinsights_client "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/logsinsights"
+	logsinsights_v2 "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/logsinsights/v2"
+	servicequotas_client "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/servicequotas"
+	servicequotas_v2 "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/servicequotas/v2"
 	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/tagging"
 	tagging_v2 "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/tagging/v2"
+	trustedadvisor_client "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/trustedadvisor"
+	trustedadvisor_v2 "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/trustedadvisor/v2"
 	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
 	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
 )
@@ -38,25 +64,39 @@ import (
 type awsRegion = string
 
 type CachingFactory struct {
-	logger              logging.Logger
-	stsOptions          func(*sts.Options)
-	clients             map[model.Role]map[awsRegion]*cachedClients
-	mu                  sync.Mutex
-	refreshed           bool
-	cleared             bool
-	fipsEnabled         bool
-	endpointURLOverride string
+	logger                   logging.Logger
+	stsOptions               func(*sts.Options)
+	clients                  map[model.Role]map[awsRegion]*cachedClients
+	mu                       sync.Mutex
+	refreshed                bool
+	cleared                  bool
+	fipsEnabled              bool
+	endpointURLOverride      string
+	attributesCache          *tagging.AttributesCache
+	costCache                *costexplorer_client.Cache
+	contributorInsightsCache *contributorinsights_client.Cache
+	logsInsightsCache        *logsinsights_client.Cache
+	trustedAdvisorCache      *trustedadvisor_client.Cache
+	retries                  map[string]model.RetryConfig
 }
 
 type cachedClients struct {
 	awsConfig *aws.Config
+	// stsOptions are the options of the sts API of the partition of the region
+	// when it has credentials of its own, nil for the default ones
+	stsOptions func(*sts.Options)
 	// if we know that this job is only used for static
 	// then we don't have to construct as many cached connections
 	// later on
-	onlyStatic bool
-	cloudwatch cloudwatch_client.Client
-	tagging    tagging.Client
-	account    account.Client
+	onlyStatic          bool
+	cloudwatch          cloudwatch_client.Client
+	tagging             tagging.Client
+	account             account.Client
+	costExplorer        costexplorer_client.Client
+	serviceQuotas       servicequotas_client.Client
+	trustedAdvisor      trustedadvisor_client.Client
+	logsInsights        logsinsights_client.Client
+	contributorInsights contributorinsights_client.Client
 }
 
 // Ensure the struct properly implements the interface
@@ -82,13 +122,32 @@ func NewFactory(logger logging.Logger, jobsCfg model.JobsConfig, fips bool) (*Ca
 	endpointURLOverride := os.Getenv("AWS_ENDPOINT_URL")
 
 	options = append(options, aws_config.WithRetryMaxAttempts(5))
+	options = append(options, aws_config.WithAPIOptions([]func(*middleware.Stack) error{addUserAgent}))
 
 	c, err := aws_config.LoadDefaultConfig(context.TODO(), options...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load default aws config: %w", err)
 	}
 
-	stsOptions := createStsOptions(jobsCfg.StsRegion, logger.IsDebugEnabled(), endpointURLOverride, fips)
+	stsOptions := createStsOptions(jobsCfg.StsRegion, logger.IsDebugEnabled(), endpointURLOverride, fips, jobsCfg.Retries[model.APISTS])
+
+	partitionConfigs, err := loadPartitionConfigs(jobsCfg.Partitions, options)
+	if err != nil {
+		return nil, err
+	}
+
+	// configForRegion returns the config of the clients of the role in the region, with the
+	// credentials and the STS endpoint of the partition of the region when it has its own
+	configForRegion := func(role model.Role, region awsRegion) (*aws.Config, func(*sts.Options)) {
+		partition, ok := jobsCfg.Partitions[model.PartitionOf(region)]
+		if !ok {
+			return awsConfigForRegion(role, &c, region, stsOptions), nil
+		}
+		partitionConfig := partitionConfigs[model.PartitionOf(region)]
+		partitionStsOptions := createStsOptions(cmp.Or(partition.StsRegion, region), logger.IsDebugEnabled(), endpointURLOverride, fips, jobsCfg.Retries[model.APISTS])
+		return awsConfigForRegion(role, &partitionConfig, region, partitionStsOptions), partitionStsOptions
+	}
+
 	cache := map[model.Role]map[awsRegion]*cachedClients{}
 	for _, discoveryJob := range jobsCfg.DiscoveryJobs {
 		for _, role := range discoveryJob.Roles {
@@ -96,9 +155,10 @@ func NewFactory(logger logging.Logger, jobsCfg model.JobsConfig, fips bool) (*Ca
 				cache[role] = map[awsRegion]*cachedClients{}
 			}
 			for _, region := range discoveryJob.Regions {
-				regionConfig := awsConfigForRegion(role, &c, region, stsOptions)
+				regionConfig, regionStsOptions := configForRegion(role, region)
 				cache[role][region] = &cachedClients{
 					awsConfig:  regionConfig,
+					stsOptions: regionStsOptions,
 					onlyStatic: false,
 				}
 			}
@@ -113,9 +173,10 @@ func NewFactory(logger logging.Logger, jobsCfg model.JobsConfig, fips bool) (*Ca
 			for _, region := range staticJob.Regions {
 				// Discovery job client definitions have precedence
 				if _, exists := cache[role][region]; !exists {
-					regionConfig := awsConfigForRegion(role, &c, region, stsOptions)
+					regionConfig, regionStsOptions := configForRegion(role, region)
 					cache[role][region] = &cachedClients{
 						awsConfig:  regionConfig,
+						stsOptions: regionStsOptions,
 						onlyStatic: true,
 					}
 				}
@@ -131,9 +192,122 @@ func NewFactory(logger logging.Logger, jobsCfg model.JobsConfig, fips bool) (*Ca
 			for _, region := range customNamespaceJob.Regions {
 				// Discovery job client definitions have precedence
 				if _, exists := cache[role][region]; !exists {
-					regionConfig := awsConfigForRegion(role, &c, region, stsOptions)
+					regionConfig, regionStsOptions := configForRegion(role, region)
 					cache[role][region] = &cachedClients{
 						awsConfig:  regionConfig,
+						stsOptions: regionStsOptions,
+						onlyStatic: true,
+					}
+				}
+			}
+		}
+	}
+
+	for _, inventoryJob := range jobsCfg.InventoryJobs {
+		for _, role := range inventoryJob.Roles {
+			if _, ok := cache[role]; !ok {
+				cache[role] = map[awsRegion]*cachedClients{}
+			}
+			for _, region := range inventoryJob.Regions {
+				// Inventory jobs need the tagging client, like discovery jobs
+				if cached, exists := cache[role][region]; exists {
+					cached.onlyStatic = false
+				} else {
+					regionConfig, regionStsOptions := configForRegion(role, region)
+					cache[role][region] = &cachedClients{
+						awsConfig:  regionConfig,
+						stsOptions: regionStsOptions,
+						onlyStatic: false,
+					}
+				}
+			}
+		}
+	}
+
+	for _, costExplorerJob := range jobsCfg.CostExplorerJobs {
+		for _, role := range costExplorerJob.Roles {
+			if _, ok := cache[role]; !ok {
+				cache[role] = map[awsRegion]*cachedClients{}
+			}
+			// Discovery job client definitions have precedence
+			if _, exists := cache[role][costExplorerJob.Region]; !exists {
+				regionConfig, regionStsOptions := configForRegion(role, costExplorerJob.Region)
+				cache[role][costExplorerJob.Region] = &cachedClients{
+					awsConfig:  regionConfig,
+					stsOptions: regionStsOptions,
+					onlyStatic: true,
+				}
+			}
+		}
+	}
+
+	for _, serviceQuotaJob := range jobsCfg.ServiceQuotaJobs {
+		for _, role := range serviceQuotaJob.Roles {
+			if _, ok := cache[role]; !ok {
+				cache[role] = map[awsRegion]*cachedClients{}
+			}
+			for _, region := range serviceQuotaJob.Regions {
+				// Discovery job client definitions have precedence
+				if _, exists := cache[role][region]; !exists {
+					regionConfig, regionStsOptions := configForRegion(role, region)
+					cache[role][region] = &cachedClients{
+						awsConfig:  regionConfig,
+						stsOptions: regionStsOptions,
+						onlyStatic: true,
+					}
+				}
+			}
+		}
+	}
+
+	for _, trustedAdvisorJob := range jobsCfg.TrustedAdvisorJobs {
+		for _, role := range trustedAdvisorJob.Roles {
+			if _, ok := cache[role]; !ok {
+				cache[role] = map[awsRegion]*cachedClients{}
+			}
+			// Discovery job client definitions have precedence
+			if _, exists := cache[role][trustedAdvisorJob.Region]; !exists {
+				regionConfig, regionStsOptions := configForRegion(role, trustedAdvisorJob.Region)
+				cache[role][trustedAdvisorJob.Region] = &cachedClients{
+					awsConfig:  regionConfig,
+					stsOptions: regionStsOptions,
+					onlyStatic: true,
+				}
+			}
+		}
+	}
+
+	for _, logsInsightsJob := range jobsCfg.LogsInsightsJobs {
+		for _, role := range logsInsightsJob.Roles {
+			if _, ok := cache[role]; !ok {
+				cache[role] = map[awsRegion]*cachedClients{}
+			}
+			for _, region := range logsInsightsJob.Regions {
+				// Discovery job client definitions have precedence
+				if _, exists := cache[role][region]; !exists {
+					regionConfig, regionStsOptions := configForRegion(role, region)
+					cache[role][region] = &cachedClients{
+						awsConfig:  regionConfig,
+						stsOptions: regionStsOptions,
+						onlyStatic: true,
+					}
+				}
+			}
+		}
+	}
+
+	for _, contributorInsightsJob := range jobsCfg.ContributorInsightsJobs {
+		for _, role := range contributorInsightsJob.Roles {
+			if _, ok := cache[role]; !ok {
+				cache[role] = map[awsRegion]*cachedClients{}
+			}
+			for _, region := range contributorInsightsJob.Regions {
+				// Discovery job client definitions have precedence
+				if _, exists := cache[role][region]; !exists {
+					regionConfig, regionStsOptions := configForRegion(role, region)
+					cache[role][region] = &cachedClients{
+						awsConfig:  regionConfig,
+						stsOptions: regionStsOptions,
 						onlyStatic: true,
 					}
 				}
@@ -142,11 +316,17 @@ func NewFactory(logger logging.Logger, jobsCfg model.JobsConfig, fips bool) (*Ca
 	}
 
 	return &CachingFactory{
-		logger:              logger,
-		clients:             cache,
-		fipsEnabled:         fips,
-		stsOptions:          stsOptions,
-		endpointURLOverride: endpointURLOverride,
+		logger:                   logger,
+		clients:                  cache,
+		fipsEnabled:              fips,
+		stsOptions:               stsOptions,
+		endpointURLOverride:      endpointURLOverride,
+		attributesCache:          tagging.NewAttributesCache(tagging.DefaultInfoAttributesCacheTTL),
+		costCache:                costexplorer_client.NewCache(),
+		contributorInsightsCache: contributorinsights_client.NewCache(),
+		logsInsightsCache:        logsinsights_client.NewCache(),
+		trustedAdvisorCache:      trustedadvisor_client.NewCache(),
+		retries:                  jobsCfg.Retries,
 	}, nil
 }
 
@@ -157,10 +337,10 @@ func (c *CachingFactory) GetCloudwatchClient(region string, role model.Role, con
 		defer c.mu.Unlock()
 	}
 	if client := c.clients[role][region].cloudwatch; client != nil {
-		return cloudwatch_client.NewLimitedConcurrencyClient(client, concurrency.NewLimiter())
+		return cloudwatch_client.NewLimitedConcurrencyClient(client, concurrency.NewLimiter(region, role), concurrency.NewRequestLimiter(region, role, c.accountClient(region, role)))
 	}
 	c.clients[role][region].cloudwatch = cloudwatch_v2.NewClient(c.logger, c.createCloudwatchClient(c.clients[role][region].awsConfig))
-	return cloudwatch_client.NewLimitedConcurrencyClient(c.clients[role][region].cloudwatch, concurrency.NewLimiter())
+	return cloudwatch_client.NewLimitedConcurrencyClient(c.clients[role][region].cloudwatch, concurrency.NewLimiter(region, role), concurrency.NewRequestLimiter(region, role, c.accountClient(region, role)))
 }
 
 func (c *CachingFactory) GetTaggingClient(region string, role model.Role, concurrencyLimit int) tagging.Client {
@@ -174,6 +354,7 @@ func (c *CachingFactory) GetTaggingClient(region string, role model.Role, concur
 	}
 	c.clients[role][region].tagging = tagging_v2.NewClient(
 		c.logger,
+		c.attributesCache,
 		c.createTaggingClient(c.clients[role][region].awsConfig),
 		c.createAutoScalingClient(c.clients[role][region].awsConfig),
 		c.createAPIGatewayClient(c.clients[role][region].awsConfig),
@@ -183,6 +364,16 @@ func (c *CachingFactory) GetTaggingClient(region string, role model.Role, concur
 		c.createPrometheusClient(c.clients[role][region].awsConfig),
 		c.createStorageGatewayClient(c.clients[role][region].awsConfig),
 		c.createShieldClient(c.clients[role][region].awsConfig),
+		c.createRDSClient(c.clients[role][region].awsConfig),
+		c.createECSClient(c.clients[role][region].awsConfig),
+		c.createElastiCacheClient(c.clients[role][region].awsConfig),
+		c.createSQSClient(c.clients[role][region].awsConfig),
+		c.createDynamoDBClient(c.clients[role][region].awsConfig),
+		c.createCloudWatchLogsClient(c.clients[role][region].awsConfig),
+		c.createSyntheticsClient(c.clients[role][region].awsConfig),
+		c.createS3Client(c.clients[role][region].awsConfig),
+		c.createKafkaClient(c.clients[role][region].awsConfig),
+		c.createRedshiftServerlessClient(c.clients[role][region].awsConfig),
 	)
 	return tagging.NewLimitedConcurrencyClient(c.clients[role][region].tagging, concurrencyLimit)
 }
@@ -193,13 +384,84 @@ func (c *CachingFactory) GetAccountClient(region string, role model.Role) accoun
 		c.mu.Lock()
 		defer c.mu.Unlock()
 	}
+	return c.accountClient(region, role)
+}
+
+// accountClient returns the account client of the region and role, the caller holds the lock if needed.
+func (c *CachingFactory) accountClient(region string, role model.Role) account.Client {
 	if client := c.clients[role][region].account; client != nil {
 		return client
 	}
-	c.clients[role][region].account = account_v2.NewClient(c.logger, c.createStsClient(c.clients[role][region].awsConfig))
+	c.clients[role][region].account = account_v2.NewClient(c.logger, c.createStsClient(c.clients[role][region], role))
 	return c.clients[role][region].account
 }
 
+func (c *CachingFactory) GetCostExplorerClient(region string, role model.Role) costexplorer_client.Client {
+	if !c.refreshed {
+		// if we have not refreshed then we need to lock in case we are accessing concurrently
+		c.mu.Lock()
+		defer c.mu.Unlock()
+	}
+	if client := c.clients[role][region].costExplorer; client != nil {
+		return costexplorer_client.NewCachingClient(client, c.costCache, role)
+	}
+	c.clients[role][region].costExplorer = costexplorer_v2.NewClient(c.logger, c.createCostExplorerClient(c.clients[role][region].awsConfig))
+	return costexplorer_client.NewCachingClient(c.clients[role][region].costExplorer, c.costCache, role)
+}
+
+func (c *CachingFactory) GetServiceQuotasClient(region string, role model.Role) servicequotas_client.Client {
+	if !c.refreshed {
+		// if we have not refreshed then we need to lock in case we are accessing concurrently
+		c.mu.Lock()
+		defer c.mu.Unlock()
+	}
+	if client := c.clients[role][region].serviceQuotas; client != nil {
+		return client
+	}
+	c.clients[role][region].serviceQuotas = servicequotas_v2.NewClient(c.logger, c.createServiceQuotasClient(c.clients[role][region].awsConfig))
+	return c.clients[role][region].serviceQuotas
+}
+
+func (c *CachingFactory) GetTrustedAdvisorClient(region string, role model.Role) trustedadvisor_client.Client {
+	if !c.refreshed {
+		// if we have not refreshed then we need to lock in case we are accessing concurrently
+		c.mu.Lock()
+		defer c.mu.Unlock()
+	}
+	if client := c.clients[role][region].trustedAdvisor; client != nil {
+		return trustedadvisor_client.NewCachingClient(client, c.trustedAdvisorCache, role)
+	}
+	// The support API does not have FIPS endpoints
+	c.clients[role][region].trustedAdvisor = trustedadvisor_v2.NewClient(c.logger, c.createTrustedAdvisorClient(c.clients[role][region].awsConfig))
+	return trustedadvisor_client.NewCachingClient(c.clients[role][region].trustedAdvisor, c.trustedAdvisorCache, role)
+}
+
+func (c *CachingFactory) GetLogsInsightsClient(region string, role model.Role) logsinsights_client.Client {
+	if !c.refreshed {
+		// if we have not refreshed then we need to lock in case we are accessing concurrently
+		c.mu.Lock()
+		defer c.mu.Unlock()
+	}
+	if client := c.clients[role][region].logsInsights; client != nil {
+		return logsinsights_client.NewCachingClient(client, c.logsInsightsCache, role, region)
+	}
+	c.clients[role][region].logsInsights = logsinsights_v2.NewClient(c.logger, c.createCloudWatchLogsClient(c.clients[role][region].awsConfig))
+	return logsinsights_client.NewCachingClient(c.clients[role][region].logsInsights, c.logsInsightsCache, role, region)
+}
+
+func (c *CachingFactory) GetContributorInsightsClient(region string, role model.Role) contributorinsights_client.Client {
+	if !c.refreshed {
+		// if we have not refreshed then we need to lock in case we are accessing concurrently
+		c.mu.Lock()
+		defer c.mu.Unlock()
+	}
+	if client := c.clients[role][region].contributorInsights; client != nil {
+		return contributorinsights_client.NewCachingClient(client, c.contributorInsightsCache, role, region)
+	}
+	c.clients[role][region].contributorInsights = contributorinsights_v2.NewClient(c.logger, c.createCloudwatchClient(c.clients[role][region].awsConfig))
+	return contributorinsights_client.NewCachingClient(c.clients[role][region].contributorInsights, c.contributorInsightsCache, role, region)
+}
+
 func (c *CachingFactory) Refresh() {
 	if c.refreshed {
 		return
@@ -211,7 +473,7 @@ func (c *CachingFactory) Refresh() {
 		return
 	}
 
-	for _, regionClients := range c.clients {
+	for role, regionClients := range c.clients {
 		for _, cache := range regionClients {
 			cache.cloudwatch = cloudwatch_v2.NewClient(c.logger, c.createCloudwatchClient(cache.awsConfig))
 			if cache.onlyStatic {
@@ -220,6 +482,7 @@ func (c *CachingFactory) Refresh() {
 
 			cache.tagging = tagging_v2.NewClient(
 				c.logger,
+				c.attributesCache,
 				c.createTaggingClient(cache.awsConfig),
 				c.createAutoScalingClient(cache.awsConfig),
 				c.createAPIGatewayClient(cache.awsConfig),
@@ -229,9 +492,19 @@ func (c *CachingFactory) Refresh() {
 				c.createPrometheusClient(cache.awsConfig),
 				c.createStorageGatewayClient(cache.awsConfig),
 				c.createShieldClient(cache.awsConfig),
+				c.createRDSClient(cache.awsConfig),
+				c.createECSClient(cache.awsConfig),
+				c.createElastiCacheClient(cache.awsConfig),
+				c.createSQSClient(cache.awsConfig),
+				c.createDynamoDBClient(cache.awsConfig),
+				c.createCloudWatchLogsClient(cache.awsConfig),
+				c.createSyntheticsClient(cache.awsConfig),
+				c.createS3Client(cache.awsConfig),
+				c.createKafkaClient(cache.awsConfig),
+				c.createRedshiftServerlessClient(cache.awsConfig),
 			)
 
-			cache.account = account_v2.NewClient(c.logger, c.createStsClient(cache.awsConfig))
+			cache.account = account_v2.NewClient(c.logger, c.createStsClient(cache, role))
 		}
 	}
 
@@ -256,6 +529,11 @@ func (c *CachingFactory) Clear() {
 			cache.cloudwatch = nil
 			cache.account = nil
 			cache.tagging = nil
+			cache.costExplorer = nil
+			cache.serviceQuotas = nil
+			cache.trustedAdvisor = nil
+			cache.logsInsights = nil
+			cache.contributorInsights = nil
 		}
 	}
 
@@ -272,11 +550,10 @@ func (c *CachingFactory) createCloudwatchClient(regionConfig *aws.Config) *cloud
 			options.BaseEndpoint = aws.String(c.endpointURLOverride)
 		}
 
-		// Setting an explicit retryer will override the default settings on the config
-		options.Retryer = retry.NewStandard(func(options *retry.StandardOptions) {
-			options.MaxAttempts = 5
-			options.MaxBackoff = 3 * time.Second
-		})
+		// Setting an explicit retryer will override the default settings on the config,
+		// except for the max attempts of the config which would wrap it
+		options.Retryer = newRetryer(model.APICloudWatch, model.RetryConfig{MaxAttempts: 5, MaxBackoff: 3 * time.Second}, c.retries[model.APICloudWatch])
+		options.RetryMaxAttempts = 0
 
 		if c.fipsEnabled {
 			options.EndpointOptions.UseFIPSEndpoint = aws.FIPSEndpointStateEnabled
@@ -292,6 +569,8 @@ func (c *CachingFactory) createTaggingClient(regionConfig *aws.Config) *resource
 		if c.endpointURLOverride != "" {
 			options.BaseEndpoint = aws.String(c.endpointURLOverride)
 		}
+		options.Retryer = newRetryer(model.APITagging, model.RetryConfig{MaxAttempts: 5}, c.retries[model.APITagging])
+		options.RetryMaxAttempts = 0
 		// The FIPS setting is ignored because FIPS is not available for resource groups tagging apis
 		// If enabled the SDK will try to use non-existent FIPS URLs, https://github.com/aws/aws-sdk-go-v2/issues/2138#issuecomment-1570791988
 		// AWS FIPS Reference: https://aws.amazon.com/compliance/fips/
@@ -398,8 +677,12 @@ func (c *CachingFactory) createPrometheusClient(assumedConfig *aws.Config) *amp.
 	})
 }
 
-func (c *CachingFactory) createStsClient(awsConfig *aws.Config) *sts.Client {
-	return sts.NewFromConfig(*awsConfig, c.stsOptions)
+func (c *CachingFactory) createStsClient(cache *cachedClients, role model.Role) *sts.Client {
+	stsOptions := c.stsOptions
+	if cache.stsOptions != nil {
+		stsOptions = cache.stsOptions
+	}
+	return sts.NewFromConfig(*cache.awsConfig, stsOptions, roleStsOptions(role))
 }
 
 func (c *CachingFactory) createShieldClient(awsConfig *aws.Config) *shield.Client {
@@ -416,8 +699,186 @@ func (c *CachingFactory) createShieldClient(awsConfig *aws.Config) *shield.Clien
 	})
 }
 
-func createStsOptions(stsRegion string, isDebugLoggingEnabled bool, endpointURLOverride string, fipsEnabled bool) func(*sts.Options) {
+func (c *CachingFactory) createRDSClient(assumedConfig *aws.Config) *rds.Client {
+	return rds.NewFromConfig(*assumedConfig, func(options *rds.Options) {
+		if c.logger.IsDebugEnabled() {
+			options.ClientLogMode = aws.LogRequestWithBody | aws.LogResponseWithBody
+		}
+		if c.endpointURLOverride != "" {
+			options.BaseEndpoint = aws.String(c.endpointURLOverride)
+		}
+		if c.fipsEnabled {
+			options.EndpointOptions.UseFIPSEndpoint = aws.FIPSEndpointStateEnabled
+		}
+	})
+}
+
+func (c *CachingFactory) createECSClient(assumedConfig *aws.Config) *ecs.Client {
+	return ecs.NewFromConfig(*assumedConfig, func(options *ecs.Options) {
+		if c.logger.IsDebugEnabled() {
+			options.ClientLogMode = aws.LogRequestWithBody | aws.LogResponseWithBody
+		}
+		if c.endpointURLOverride != "" {
+			options.BaseEndpoint = aws.String(c.endpointURLOverride)
+		}
+		if c.fipsEnabled {
+			options.EndpointOptions.UseFIPSEndpoint = aws.FIPSEndpointStateEnabled
+		}
+	})
+}
+
+func (c *CachingFactory) createElastiCacheClient(assumedConfig *aws.Config) *elasticache.Client {
+	return elasticache.NewFromConfig(*assumedConfig, func(options *elasticache.Options) {
+		if c.logger.IsDebugEnabled() {
+			options.ClientLogMode = aws.LogRequestWithBody | aws.LogResponseWithBody
+		}
+		if c.endpointURLOverride != "" {
+			options.BaseEndpoint = aws.String(c.endpointURLOverride)
+		}
+		if c.fipsEnabled {
+			options.EndpointOptions.UseFIPSEndpoint = aws.FIPSEndpointStateEnabled
+		}
+	})
+}
+
+func (c *CachingFactory) createSQSClient(assumedConfig *aws.Config) *sqs.Client {
+	return sqs.NewFromConfig(*assumedConfig, func(options *sqs.Options) {
+		if c.logger.IsDebugEnabled() {
+			options.ClientLogMode = aws.LogRequestWithBody | aws.LogResponseWithBody
+		}
+		if c.endpointURLOverride != "" {
+			options.BaseEndpoint = aws.String(c.endpointURLOverride)
+		}
+		if c.fipsEnabled {
+			options.EndpointOptions.UseFIPSEndpoint = aws.FIPSEndpointStateEnabled
+		}
+	})
+}
+
+func (c *CachingFactory) createDynamoDBClient(assumedConfig *aws.Config) *dynamodb.Client {
+	return dynamodb.NewFromConfig(*assumedConfig, func(options *dynamodb.Options) {
+		if c.logger.IsDebugEnabled() {
+			options.ClientLogMode = aws.LogRequestWithBody | aws.LogResponseWithBody
+		}
+		if c.endpointURLOverride != "" {
+			options.BaseEndpoint = aws.String(c.endpointURLOverride)
+		}
+		if c.fipsEnabled {
+			options.EndpointOptions.UseFIPSEndpoint = aws.FIPSEndpointStateEnabled
+		}
+	})
+}
+
+func (c *CachingFactory) createCloudWatchLogsClient(assumedConfig *aws.Config) *cloudwatchlogs.Client {
+	return cloudwatchlogs.NewFromConfig(*assumedConfig, func(options *cloudwatchlogs.Options) {
+		if c.logger.IsDebugEnabled() {
+			options.ClientLogMode = aws.LogRequestWithBody | aws.LogResponseWithBody
+		}
+		if c.endpointURLOverride != "" {
+			options.BaseEndpoint = aws.String(c.endpointURLOverride)
+		}
+		if c.fipsEnabled {
+			options.EndpointOptions.UseFIPSEndpoint = aws.FIPSEndpointStateEnabled
+		}
+	})
+}
+
+func (c *CachingFactory) createSyntheticsClient(assumedConfig *aws.Config) *synthetics.Client {
+	return synthetics.NewFromConfig(*assumedConfig, func(options *synthetics.Options) {
+		if c.logger.IsDebugEnabled() {
+			options.ClientLogMode = aws.LogRequestWithBody | aws.LogResponseWithBody
+		}
+		if c.endpointURLOverride != "" {
+			options.BaseEndpoint = aws.String(c.endpointURLOverride)
+		}
+		// The FIPS setting is ignored because FIPS is not available for synthetics apis
+	})
+}
+
+func (c *CachingFactory) createS3Client(assumedConfig *aws.Config) *s3.Client {
+	return s3.NewFromConfig(*assumedConfig, func(options *s3.Options) {
+		if c.logger.IsDebugEnabled() {
+			options.ClientLogMode = aws.LogRequestWithBody | aws.LogResponseWithBody
+		}
+		if c.endpointURLOverride != "" {
+			options.BaseEndpoint = aws.String(c.endpointURLOverride)
+		}
+		if c.fipsEnabled {
+			options.EndpointOptions.UseFIPSEndpoint = aws.FIPSEndpointStateEnabled
+		}
+	})
+}
+
+func (c *CachingFactory) createKafkaClient(assumedConfig *aws.Config) *kafka.Client {
+	return kafka.NewFromConfig(*assumedConfig, func(options *kafka.Options) {
+		if c.logger.IsDebugEnabled() {
+			options.ClientLogMode = aws.LogRequestWithBody | aws.LogResponseWithBody
+		}
+		if c.endpointURLOverride != "" {
+			options.BaseEndpoint = aws.String(c.endpointURLOverride)
+		}
+		if c.fipsEnabled {
+			options.EndpointOptions.UseFIPSEndpoint = aws.FIPSEndpointStateEnabled
+		}
+	})
+}
+
+func (c *CachingFactory) createRedshiftServerlessClient(assumedConfig *aws.Config) *redshiftserverless.Client {
+	return redshiftserverless.NewFromConfig(*assumedConfig, func(options *redshiftserverless.Options) {
+		if c.logger.IsDebugEnabled() {
+			options.ClientLogMode = aws.LogRequestWithBody | aws.LogResponseWithBody
+		}
+		if c.endpointURLOverride != "" {
+			options.BaseEndpoint = aws.String(c.endpointURLOverride)
+		}
+		if c.fipsEnabled {
+			options.EndpointOptions.UseFIPSEndpoint = aws.FIPSEndpointStateEnabled
+		}
+	})
+}
+
+func (c *CachingFactory) createCostExplorerClient(assumedConfig *aws.Config) *costexplorer.Client {
+	return costexplorer.NewFromConfig(*assumedConfig, func(options *costexplorer.Options) {
+		if c.logger.IsDebugEnabled() {
+			options.ClientLogMode = aws.LogRequestWithBody | aws.LogResponseWithBody
+		}
+		if c.endpointURLOverride != "" {
+			options.BaseEndpoint = aws.String(c.endpointURLOverride)
+		}
+		// The Cost Explorer API does not have FIPS endpoints
+	})
+}
+
+func (c *CachingFactory) createServiceQuotasClient(assumedConfig *aws.Config) *servicequotas.Client {
+	return servicequotas.NewFromConfig(*assumedConfig, func(options *servicequotas.Options) {
+		if c.logger.IsDebugEnabled() {
+			options.ClientLogMode = aws.LogRequestWithBody | aws.LogResponseWithBody
+		}
+		if c.endpointURLOverride != "" {
+			options.BaseEndpoint = aws.String(c.endpointURLOverride)
+		}
+		if c.fipsEnabled {
+			options.EndpointOptions.UseFIPSEndpoint = aws.FIPSEndpointStateEnabled
+		}
+	})
+}
+
+func (c *CachingFactory) createTrustedAdvisorClient(assumedConfig *aws.Config) *support.Client {
+	return support.NewFromConfig(*assumedConfig, func(options *support.Options) {
+		if c.logger.IsDebugEnabled() {
+			options.ClientLogMode = aws.LogRequestWithBody | aws.LogResponseWithBody
+		}
+		if c.endpointURLOverride != "" {
+			options.BaseEndpoint = aws.String(c.endpointURLOverride)
+		}
+		// The TrustedAdvisor API does not have FIPS endpoints
+	})
+}
+
+func createStsOptions(stsRegion string, isDebugLoggingEnabled bool, endpointURLOverride string, fipsEnabled bool, retryConfig model.RetryConfig) func(*sts.Options) {
 	return func(options *sts.Options) {
+		options.Retryer = newRetryer(model.APISTS, model.RetryConfig{MaxAttempts: 5}, retryConfig)
+		options.RetryMaxAttempts = 0
 		if stsRegion != "" {
 			options.Region = stsRegion
 		}
@@ -433,6 +894,46 @@ func createStsOptions(stsRegion string, isDebugLoggingEnabled bool, endpointURLO
 	}
 }
 
+// loadPartitionConfigs loads the configs of the partitions with the options of the
+// default config, and the profile or static credentials of each partition.
+func loadPartitionConfigs(partitions map[string]model.PartitionConfig, options []func(*aws_config.LoadOptions) error) (map[string]aws.Config, error) {
+	configs := make(map[string]aws.Config, len(partitions))
+	for name, partition := range partitions {
+		partitionOptions := slices.Clone(options)
+		if partition.Profile != "" {
+			partitionOptions = append(partitionOptions, aws_config.WithSharedConfigProfile(partition.Profile))
+		}
+		if partition.AccessKeyID != "" {
+			partitionOptions = append(partitionOptions, aws_config.WithCredentialsProvider(
+				credentials.NewStaticCredentialsProvider(partition.AccessKeyID, partition.SecretAccessKey, "")))
+		}
+		c, err := aws_config.LoadDefaultConfig(context.TODO(), partitionOptions...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load aws config of partition %s: %w", name, err)
+		}
+		configs[name] = c
+	}
+	return configs, nil
+}
+
+// roleStsOptions applies the STS endpoint and the retries of the role
+// over the options of the sts API.
+func roleStsOptions(role model.Role) func(*sts.Options) {
+	return func(options *sts.Options) {
+		switch role.StsRegion {
+		case "":
+		case model.StsRegionGlobal:
+			// The pseudo region of the global endpoint
+			options.Region = "aws-global"
+		default:
+			options.Region = role.StsRegion
+		}
+		if role.Retry != (model.RetryConfig{}) {
+			options.Retryer = newRetryer(model.APISTS, model.RetryConfig{MaxAttempts: 5}, role.Retry)
+		}
+	}
+}
+
 var defaultRole = model.Role{}
 
 func awsConfigForRegion(r model.Role, c *aws.Config, region awsRegion, stsOptions func(*sts.Options)) *aws.Config {
@@ -445,11 +946,14 @@ func awsConfigForRegion(r model.Role, c *aws.Config, region awsRegion, stsOption
 
 	// based on https://pkg.go.dev/github.com/aws/aws-sdk-go-v2/credentials/stscreds#hdr-Assume_Role
 	// found via https://github.com/aws/aws-sdk-go-v2/issues/1382
-	regionalSts := sts.NewFromConfig(*c, stsOptions)
+	regionalSts := sts.NewFromConfig(*c, stsOptions, roleStsOptions(r))
 	credentials := stscreds.NewAssumeRoleProvider(regionalSts, r.RoleArn, func(options *stscreds.AssumeRoleOptions) {
 		if r.ExternalID != "" {
 			options.ExternalID = aws.String(r.ExternalID)
 		}
+		if r.SessionDuration > 0 {
+			options.Duration = r.SessionDuration
+		}
 	})
 	regionalConfig.Credentials = aws.NewCredentialsCache(credentials)
 
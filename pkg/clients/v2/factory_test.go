@@ -84,6 +84,20 @@ func TestNewFactory_initializes_clients(t *testing.T) {
 			},
 			onlyStatic: aws.Bool(true),
 		},
+		{
+			name: "from inventory config",
+			jobsCfg: model.JobsConfig{
+				StaticJobs: []model.StaticJob{{
+					Regions: []string{region1, region2, region3},
+					Roles:   []model.Role{defaultRole, role1, role2, role3},
+				}},
+				InventoryJobs: []model.InventoryJob{{
+					Regions: []string{region1, region2, region3},
+					Roles:   []model.Role{defaultRole, role1, role2, role3},
+				}},
+			},
+			onlyStatic: aws.Bool(false),
+		},
 		{
 			name: "from all configs",
 			jobsCfg: model.JobsConfig{
@@ -156,6 +170,44 @@ func TestNewFactory_respects_stsregion(t *testing.T) {
 	assert.Equal(t, stsRegion, stsOptions.Region)
 }
 
+func TestNewFactory_isolates_partitions(t *testing.T) {
+	cfg := model.JobsConfig{
+		Partitions: map[string]model.PartitionConfig{
+			model.PartitionChina: {AccessKeyID: "AKIDCHINA", SecretAccessKey: "secret"},
+		},
+		DiscoveryJobs: []model.DiscoveryJob{{
+			Regions: []string{"eu-west-1", "cn-north-1"},
+			Roles:   []model.Role{defaultRole},
+		}},
+	}
+
+	output, err := NewFactory(logging.NewNopLogger(), cfg, false)
+	require.NoError(t, err)
+	assert.Nil(t, output.clients[defaultRole]["eu-west-1"].stsOptions)
+
+	china := output.clients[defaultRole]["cn-north-1"]
+	credentials, err := china.awsConfig.Credentials.Retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "AKIDCHINA", credentials.AccessKeyID)
+	stsOptions := sts.Options{}
+	china.stsOptions(&stsOptions)
+	assert.Equal(t, "cn-north-1", stsOptions.Region)
+}
+
+func TestRoleStsOptions(t *testing.T) {
+	stsOptions := sts.Options{Region: "custom-sts-region"}
+	roleStsOptions(model.Role{RoleArn: "arn:aws:iam::123456789012:role/yace"})(&stsOptions)
+	assert.Equal(t, "custom-sts-region", stsOptions.Region)
+	assert.Nil(t, stsOptions.Retryer)
+
+	roleStsOptions(model.Role{RoleArn: "arn:aws:iam::123456789012:role/yace", StsRegion: "cn-north-1", Retry: model.RetryConfig{MaxAttempts: 2}})(&stsOptions)
+	assert.Equal(t, "cn-north-1", stsOptions.Region)
+	assert.Equal(t, 2, stsOptions.Retryer.MaxAttempts())
+
+	roleStsOptions(model.Role{RoleArn: "arn:aws:iam::123456789012:role/yace", StsRegion: model.StsRegionGlobal})(&stsOptions)
+	assert.Equal(t, "aws-global", stsOptions.Region)
+}
+
 func TestCachingFactory_Clear(t *testing.T) {
 	cache := &CachingFactory{
 		logger: logging.NewNopLogger(),
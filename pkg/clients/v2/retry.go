@@ -0,0 +1,59 @@
+package v2
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/retries"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+// countingRetryer counts the retries of the requests to an API.
+type countingRetryer struct {
+	aws.RetryerV2
+	api string
+}
+
+// GetRetryToken is called before each retry, with the context of the request.
+func (r countingRetryer) GetRetryToken(ctx context.Context, opErr error) (func(error) error, error) {
+	releaseToken, err := r.RetryerV2.GetRetryToken(ctx, opErr)
+	if err == nil {
+		throttled := retry.IsErrorThrottles(retry.DefaultThrottles).IsErrorThrottle(opErr) == aws.TrueTernary
+		retries.Observe(ctx, r.api, awsmiddleware.GetOperationName(ctx), throttled)
+	}
+	return releaseToken, err
+}
+
+// newRetryer returns the retryer of the requests to an API, with the
+// configured retries overriding the defaults of the client.
+func newRetryer(api string, defaults model.RetryConfig, cfg model.RetryConfig) aws.Retryer {
+	if cfg.Mode != "" {
+		defaults.Mode = cfg.Mode
+	}
+	if cfg.MaxAttempts > 0 {
+		defaults.MaxAttempts = cfg.MaxAttempts
+	}
+	if cfg.MaxBackoff > 0 {
+		defaults.MaxBackoff = cfg.MaxBackoff
+	}
+
+	standardOptions := func(options *retry.StandardOptions) {
+		if defaults.MaxAttempts > 0 {
+			options.MaxAttempts = defaults.MaxAttempts
+		}
+		if defaults.MaxBackoff > 0 {
+			options.MaxBackoff = defaults.MaxBackoff
+		}
+	}
+
+	var retryer aws.RetryerV2 = retry.NewStandard(standardOptions)
+	if defaults.Mode == model.RetryModeAdaptive {
+		retryer = retry.NewAdaptiveMode(func(options *retry.AdaptiveModeOptions) {
+			options.StandardOptions = append(options.StandardOptions, standardOptions)
+		})
+	}
+	return countingRetryer{RetryerV2: retryer, api: api}
+}
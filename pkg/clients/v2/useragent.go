@@ -0,0 +1,25 @@
+package v2
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/useragent"
+)
+
+// addUserAgent adds the job making the request to the user agent, after the one of the
+// SDK, and a trace header if enabled.
+func addUserAgent(stack *middleware.Stack) error {
+	return stack.Build.Add(middleware.BuildMiddlewareFunc("yace.UserAgent", func(ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler) (middleware.BuildOutput, middleware.Metadata, error) {
+		if req, ok := in.Request.(*smithyhttp.Request); ok {
+			req.Header.Set("User-Agent", strings.TrimSpace(req.Header.Get("User-Agent")+" "+useragent.Value(ctx)))
+			if traceID := useragent.TraceID(); traceID != "" {
+				req.Header.Set(useragent.TraceHeader, traceID)
+			}
+		}
+		return next.HandleBuild(ctx, in)
+	}), middleware.After)
+}
@@ -0,0 +1,30 @@
+package v2
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/useragent"
+)
+
+func TestAddUserAgent(t *testing.T) {
+	t.Cleanup(func() { useragent.Configure("unknown", false) })
+	useragent.Configure("v0.61.2", true)
+
+	stack := middleware.NewStack("GetMetricData", smithyhttp.NewStackRequest)
+	require.NoError(t, addUserAgent(stack))
+	var req *smithyhttp.Request
+	handler := middleware.DecorateHandler(middleware.HandlerFunc(func(_ context.Context, in interface{}) (interface{}, middleware.Metadata, error) {
+		req = in.(*smithyhttp.Request)
+		return nil, middleware.Metadata{}, nil
+	}), stack)
+
+	_, _, err := handler.Handle(useragent.WithJob(context.Background(), "AWS/EC2"), struct{}{})
+	require.NoError(t, err)
+	require.Equal(t, "yace/v0.61.2/AWS/EC2", req.Header.Get("User-Agent"))
+	require.NotEmpty(t, req.Header.Get(useragent.TraceHeader))
+}
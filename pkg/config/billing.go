@@ -0,0 +1,86 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+const (
+	// Billing metrics are only published in us-east-1, a few times a day.
+	billingRegion        = "us-east-1"
+	billingNamespace     = "AWS/Billing"
+	billingMetricName    = "EstimatedCharges"
+	billingDefaultPeriod = int64(6 * 60 * 60)
+	billingDefaultLength = int64(24 * 60 * 60)
+)
+
+// Billing is a built-in job exporting the estimated charges of the
+// account, per service and linked account.
+type Billing struct {
+	Roles      []Role `yaml:"roles"`
+	CustomTags []Tag  `yaml:"customTags"`
+	Period     int64  `yaml:"period"`
+	Length     int64  `yaml:"length"`
+}
+
+func (b *Billing) validateBillingJob() error {
+	if len(b.Roles) > 0 {
+		for roleIdx, role := range b.Roles {
+			if err := role.ValidateRole(roleIdx, "Billing job"); err != nil {
+				return err
+			}
+		}
+	} else {
+		return fmt.Errorf("no IAM roles configured. If the current IAM role is desired, an empty Role should be configured")
+	}
+	if b.Period < 0 || b.Length < 0 {
+		return fmt.Errorf("Billing job: Period and Length should be positive integers")
+	}
+	if b.Length != 0 && b.Length < b.period() {
+		return fmt.Errorf("Billing job: length(%d) is smaller than period(%d)", b.Length, b.period())
+	}
+	return nil
+}
+
+func (b *Billing) period() int64 {
+	if b.Period == 0 {
+		return billingDefaultPeriod
+	}
+	return b.Period
+}
+
+func (b *Billing) length() int64 {
+	if b.Length == 0 {
+		return max(billingDefaultLength, b.period())
+	}
+	return b.Length
+}
+
+// toModelJob returns the custom namespace job querying the EstimatedCharges metric.
+// As no dimensions are required, the total as well as the per service and per linked
+// account charges are exported. The statistic is a label, so that the metric is
+// exported as aws_billing_estimated_charges.
+func (b *Billing) toModelJob() model.CustomNamespaceJob {
+	job := model.CustomNamespaceJob{}
+	job.Name = "billing"
+	job.Namespace = billingNamespace
+	job.Regions = []string{billingRegion}
+	job.Roles = toModelRoles(b.Roles)
+	job.CustomTags = toModelTags(b.CustomTags)
+	job.Metrics = []*model.MetricConfig{
+		{
+			Name:                   billingMetricName,
+			Statistics:             []string{"Maximum"},
+			Period:                 b.period(),
+			Length:                 b.length(),
+			Delay:                  0,
+			NilToZero:              aws.Bool(false),
+			AddCloudwatchTimestamp: aws.Bool(false),
+			StatLabelMode:          model.StatLabelModeLabel,
+		},
+	}
+	return job
+}
@@ -1,9 +1,12 @@
 package config
 
 import (
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"os"
+	"slices"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/grafana/regexp"
@@ -14,20 +17,35 @@ import (
 )
 
 type ScrapeConf struct {
-	APIVersion      string             `yaml:"apiVersion"`
-	StsRegion       string             `yaml:"sts-region"`
-	Discovery       Discovery          `yaml:"discovery"`
-	Static          []*Static          `yaml:"static"`
-	CustomNamespace []*CustomNamespace `yaml:"customNamespace"`
+	APIVersion          string                 `yaml:"apiVersion"`
+	StsRegion           string                 `yaml:"sts-region"`
+	Discovery           Discovery              `yaml:"discovery"`
+	Static              []*Static              `yaml:"static"`
+	CustomNamespace     []*CustomNamespace     `yaml:"customNamespace"`
+	CustomNamespaces    *CustomNamespaces      `yaml:"customNamespaces"`
+	Inventory           []*Inventory           `yaml:"inventory"`
+	Billing             *Billing               `yaml:"billing"`
+	CostExplorer        []*CostExplorer        `yaml:"costExplorer"`
+	ServiceQuotas       []*ServiceQuota        `yaml:"serviceQuotas"`
+	TrustedAdvisor      *TrustedAdvisor        `yaml:"trustedAdvisor"`
+	LogsInsights        []*LogsInsights        `yaml:"logsInsights"`
+	ContributorInsights []*ContributorInsights `yaml:"contributorInsights"`
+	Hooks               []*Hook                `yaml:"hooks"`
+	Retries             map[string]*Retry      `yaml:"retries"`
+	Organization        *Organization          `yaml:"organization"`
+	Partitions          map[string]*Partition  `yaml:"partitions"`
+
+	// hash is the SHA-256 of the loaded file
+	hash string
 }
 
 type Discovery struct {
 	ExportedTagsOnMetrics ExportedTagsOnMetrics `yaml:"exportedTagsOnMetrics"`
+	ExportInfoMetrics     *bool                 `yaml:"exportInfoMetrics"`
+	DimensionsRegexps     DimensionsRegexps     `yaml:"dimensionsRegexps"`
 	Jobs                  []*Job                `yaml:"jobs"`
 }
 
-type ExportedTagsOnMetrics map[string][]string
-
 type Tag struct {
 	Key   string `yaml:"key"`
 	Value string `yaml:"value"`
@@ -41,53 +59,106 @@ type JobLevelMetricFields struct {
 	NilToZero              *bool    `yaml:"nilToZero"`
 	AddCloudwatchTimestamp *bool    `yaml:"addCloudwatchTimestamp"`
 	AddHistoricalMetrics   *bool    `yaml:"addHistoricalMetrics"`
+	CompletePeriodsOnly    *bool    `yaml:"completePeriodsOnly"`
+	StatLabelMode          string   `yaml:"statLabelMode"`
+	StatisticsAsLabel      *bool    `yaml:"statisticsAsLabel"`
 }
 
 type Job struct {
-	Regions                     []string  `yaml:"regions"`
-	Type                        string    `yaml:"type"`
-	Roles                       []Role    `yaml:"roles"`
-	SearchTags                  []Tag     `yaml:"searchTags"`
-	CustomTags                  []Tag     `yaml:"customTags"`
-	DimensionNameRequirements   []string  `yaml:"dimensionNameRequirements"`
-	Metrics                     []*Metric `yaml:"metrics"`
-	RoundingPeriod              *int64    `yaml:"roundingPeriod"`
-	RecentlyActiveOnly          bool      `yaml:"recentlyActiveOnly"`
-	IncludeContextOnInfoMetrics bool      `yaml:"includeContextOnInfoMetrics"`
+	Regions                     []string          `yaml:"regions"`
+	Type                        string            `yaml:"type"`
+	Roles                       []Role            `yaml:"roles"`
+	OrganizationAccounts        bool              `yaml:"organizationAccounts"`
+	SearchTags                  []Tag             `yaml:"searchTags"`
+	CustomTags                  []Tag             `yaml:"customTags"`
+	DimensionNameRequirements   []string          `yaml:"dimensionNameRequirements"`
+	Metrics                     []*Metric         `yaml:"metrics"`
+	RoundingPeriod              *int64            `yaml:"roundingPeriod"`
+	RecentlyActiveOnly          bool              `yaml:"recentlyActiveOnly"`
+	IncludeContextOnInfoMetrics bool              `yaml:"includeContextOnInfoMetrics"`
+	ExportInfoMetrics           *bool             `yaml:"exportInfoMetrics"`
+	AddResourceAttributes       bool              `yaml:"addResourceAttributes"`
+	AddKubernetesLabels         bool              `yaml:"addKubernetesLabels"`
+	AddCapacityMetrics          bool              `yaml:"addCapacityMetrics"`
+	AddLogGroupMetrics          bool              `yaml:"addLogGroupMetrics"`
+	InfoMetricAttributes        []string          `yaml:"infoMetricAttributes"`
+	LambdaResourceMode          string            `yaml:"lambdaResourceMode"`
+	APIGatewayGranularity       string            `yaml:"apiGatewayGranularity"`
+	ExpandElastiCacheNodes      bool              `yaml:"expandElastiCacheNodes"`
+	KafkaTopics                 []string          `yaml:"kafkaTopics"`
+	WAFRules                    []string          `yaml:"wafRules"`
+	CloudFrontBreakdowns        []string          `yaml:"cloudFrontBreakdowns"`
+	IncludeShardLevel           bool              `yaml:"includeShardLevel"`
+	MaxShards                   int               `yaml:"maxShards"`
+	LabelTransforms             map[string]string `yaml:"labelTransforms"`
+	Processors                  []Processor       `yaml:"processors"`
+	NamespaceAlias              string            `yaml:"namespaceAlias"`
+	ExportedTagsOnMetrics       []string          `yaml:"exportedTagsOnMetrics"`
+	ExcludeTagsOnMetrics        []string          `yaml:"excludeTagsOnMetrics"`
+	Priority                    string            `yaml:"priority"`
+	UnhealthyAfter              int64             `yaml:"unhealthyAfter"`
+	Schedule                    *Schedule         `yaml:"schedule"`
 	JobLevelMetricFields        `yaml:",inline"`
 }
 
 type Static struct {
-	Name       string      `yaml:"name"`
-	Regions    []string    `yaml:"regions"`
-	Roles      []Role      `yaml:"roles"`
-	Namespace  string      `yaml:"namespace"`
-	CustomTags []Tag       `yaml:"customTags"`
-	Dimensions []Dimension `yaml:"dimensions"`
-	Metrics    []*Metric   `yaml:"metrics"`
+	Name            string            `yaml:"name"`
+	Regions         []string          `yaml:"regions"`
+	Roles           []Role            `yaml:"roles"`
+	Namespace       string            `yaml:"namespace"`
+	CustomTags      []Tag             `yaml:"customTags"`
+	Dimensions      []Dimension       `yaml:"dimensions"`
+	Metrics         []*Metric         `yaml:"metrics"`
+	LabelTransforms map[string]string `yaml:"labelTransforms"`
+	Processors      []Processor       `yaml:"processors"`
+	NamespaceAlias  string            `yaml:"namespaceAlias"`
+	Priority        string            `yaml:"priority"`
+	UnhealthyAfter  int64             `yaml:"unhealthyAfter"`
+	Schedule        *Schedule         `yaml:"schedule"`
 }
 
 type CustomNamespace struct {
-	Regions                   []string  `yaml:"regions"`
-	Name                      string    `yaml:"name"`
-	Namespace                 string    `yaml:"namespace"`
-	RecentlyActiveOnly        bool      `yaml:"recentlyActiveOnly"`
-	Roles                     []Role    `yaml:"roles"`
-	Metrics                   []*Metric `yaml:"metrics"`
-	CustomTags                []Tag     `yaml:"customTags"`
-	DimensionNameRequirements []string  `yaml:"dimensionNameRequirements"`
-	RoundingPeriod            *int64    `yaml:"roundingPeriod"`
+	Regions                   []string          `yaml:"regions"`
+	Name                      string            `yaml:"name"`
+	Namespace                 string            `yaml:"namespace"`
+	RecentlyActiveOnly        bool              `yaml:"recentlyActiveOnly"`
+	Roles                     []Role            `yaml:"roles"`
+	Metrics                   []*Metric         `yaml:"metrics"`
+	CustomTags                []Tag             `yaml:"customTags"`
+	DimensionNameRequirements []string          `yaml:"dimensionNameRequirements"`
+	RoundingPeriod            *int64            `yaml:"roundingPeriod"`
+	LabelTransforms           map[string]string `yaml:"labelTransforms"`
+	Processors                []Processor       `yaml:"processors"`
+	NamespaceAlias            string            `yaml:"namespaceAlias"`
+	Priority                  string            `yaml:"priority"`
+	UnhealthyAfter            int64             `yaml:"unhealthyAfter"`
+	Schedule                  *Schedule         `yaml:"schedule"`
 	JobLevelMetricFields      `yaml:",inline"`
 }
 
+type Inventory struct {
+	Regions    []string `yaml:"regions"`
+	Roles      []Role   `yaml:"roles"`
+	Types      []string `yaml:"types"`
+	SearchTags []Tag    `yaml:"searchTags"`
+	CustomTags []Tag    `yaml:"customTags"`
+}
+
 type Metric struct {
-	Name                   string   `yaml:"name"`
-	Statistics             []string `yaml:"statistics"`
-	Period                 int64    `yaml:"period"`
-	Length                 int64    `yaml:"length"`
-	Delay                  int64    `yaml:"delay"`
-	NilToZero              *bool    `yaml:"nilToZero"`
-	AddCloudwatchTimestamp *bool    `yaml:"addCloudwatchTimestamp"`
+	Name                   string    `yaml:"name"`
+	Statistics             []string  `yaml:"statistics"`
+	Period                 int64     `yaml:"period"`
+	Length                 int64     `yaml:"length"`
+	Delay                  int64     `yaml:"delay"`
+	NilToZero              *bool     `yaml:"nilToZero"`
+	AddCloudwatchTimestamp *bool     `yaml:"addCloudwatchTimestamp"`
+	RoundingPeriod         *int64    `yaml:"roundingPeriod"`
+	TimestampAlignment     string    `yaml:"timestampAlignment"`
+	CompletePeriodsOnly    *bool     `yaml:"completePeriodsOnly"`
+	SkipIncompletePeriod   *bool     `yaml:"skipIncompletePeriod"`
+	StatLabelMode          string    `yaml:"statLabelMode"`
+	StatisticsAsLabel      *bool     `yaml:"statisticsAsLabel"`
+	HistogramBuckets       []float64 `yaml:"histogramBuckets"`
 }
 
 type Dimension struct {
@@ -96,14 +167,31 @@ type Dimension struct {
 }
 
 type Role struct {
-	RoleArn    string `yaml:"roleArn"`
-	ExternalID string `yaml:"externalId"`
+	RoleArn         string `yaml:"roleArn"`
+	ExternalID      string `yaml:"externalId"`
+	StsRegion       string `yaml:"stsRegion"`
+	SessionDuration int64  `yaml:"sessionDuration"`
+	Retry           *Retry `yaml:"retry"`
 }
 
+// Bounds of the duration of the role sessions accepted by STS, in seconds
+const (
+	minRoleSessionDuration = 900
+	maxRoleSessionDuration = 43200
+)
+
 func (r *Role) ValidateRole(roleIdx int, parent string) error {
-	if r.RoleArn == "" && r.ExternalID != "" {
+	if r.RoleArn == "" && (r.ExternalID != "" || r.StsRegion != "" || r.SessionDuration != 0 || r.Retry != nil) {
 		return fmt.Errorf("Role [%d] in %v: RoleArn should not be empty", roleIdx, parent)
 	}
+	if r.SessionDuration != 0 && (r.SessionDuration < minRoleSessionDuration || r.SessionDuration > maxRoleSessionDuration) {
+		return fmt.Errorf("Role [%d] in %v: SessionDuration should be between %d and %d seconds", roleIdx, parent, minRoleSessionDuration, maxRoleSessionDuration)
+	}
+	if r.Retry != nil {
+		if err := r.Retry.validateRetry(model.APISTS); err != nil {
+			return fmt.Errorf("Role [%d] in %v: %w", roleIdx, parent, err)
+		}
+	}
 
 	return nil
 }
@@ -113,6 +201,7 @@ func (c *ScrapeConf) Load(file string, logger logging.Logger) (model.JobsConfig,
 	if err != nil {
 		return model.JobsConfig{}, err
 	}
+	c.hash = fmt.Sprintf("%x", sha256.Sum256(yamlFile))
 	err = yaml.Unmarshal(yamlFile, c)
 	if err != nil {
 		return model.JobsConfig{}, err
@@ -121,7 +210,8 @@ func (c *ScrapeConf) Load(file string, logger logging.Logger) (model.JobsConfig,
 	logConfigErrors(yamlFile, logger)
 
 	for _, job := range c.Discovery.Jobs {
-		if len(job.Roles) == 0 {
+		// The roles of the jobs scraping the organization accounts are added when they are discovered
+		if len(job.Roles) == 0 && !job.OrganizationAccounts {
 			job.Roles = []Role{{}} // use current IAM role
 		}
 	}
@@ -132,6 +222,10 @@ func (c *ScrapeConf) Load(file string, logger logging.Logger) (model.JobsConfig,
 		}
 	}
 
+	if c.CustomNamespaces != nil && len(c.CustomNamespaces.Roles) == 0 {
+		c.CustomNamespaces.Roles = []Role{{}} // use current IAM role
+	}
+
 	for _, job := range c.Static {
 		if len(job.Roles) == 0 {
 			job.Roles = []Role{{}} // use current IAM role
@@ -141,9 +235,21 @@ func (c *ScrapeConf) Load(file string, logger logging.Logger) (model.JobsConfig,
 	return c.Validate()
 }
 
+// Hash returns the SHA-256 of the file of the last Load, in hex, identifying the config generation.
+func (c *ScrapeConf) Hash() string {
+	return c.hash
+}
+
 func (c *ScrapeConf) Validate() (model.JobsConfig, error) {
-	if c.Discovery.Jobs == nil && c.Static == nil && c.CustomNamespace == nil {
-		return model.JobsConfig{}, fmt.Errorf("At least 1 Discovery job, 1 Static or one CustomNamespace must be defined")
+	if c.Discovery.Jobs == nil && c.Static == nil && c.CustomNamespace == nil && c.CustomNamespaces == nil && c.Inventory == nil && c.Billing == nil && c.CostExplorer == nil && c.ServiceQuotas == nil && c.TrustedAdvisor == nil && c.LogsInsights == nil && c.ContributorInsights == nil {
+		return model.JobsConfig{}, fmt.Errorf("At least 1 Discovery job, 1 Static, one CustomNamespace, the CustomNamespaces discovery, one Inventory, one CostExplorer, one ServiceQuotas, one LogsInsights, one ContributorInsights, the Billing or the TrustedAdvisor job must be defined")
+	}
+
+	if err := c.Discovery.DimensionsRegexps.validateDimensionsRegexps(); err != nil {
+		return model.JobsConfig{}, err
+	}
+	if err := c.Discovery.ExportedTagsOnMetrics.validateExportedTagsOnMetrics(); err != nil {
+		return model.JobsConfig{}, err
 	}
 
 	if c.Discovery.Jobs != nil {
@@ -152,6 +258,9 @@ func (c *ScrapeConf) Validate() (model.JobsConfig, error) {
 			if err != nil {
 				return model.JobsConfig{}, err
 			}
+			if job.OrganizationAccounts && c.Organization == nil {
+				return model.JobsConfig{}, fmt.Errorf("Discovery job [%s/%d]: OrganizationAccounts requires the organization block", job.Type, idx)
+			}
 		}
 	}
 
@@ -164,6 +273,12 @@ func (c *ScrapeConf) Validate() (model.JobsConfig, error) {
 		}
 	}
 
+	if c.CustomNamespaces != nil {
+		if err := c.CustomNamespaces.validateCustomNamespacesJob(); err != nil {
+			return model.JobsConfig{}, err
+		}
+	}
+
 	if c.Static != nil {
 		for idx, job := range c.Static {
 			err := job.validateStaticJob(idx)
@@ -172,6 +287,94 @@ func (c *ScrapeConf) Validate() (model.JobsConfig, error) {
 			}
 		}
 	}
+
+	if c.Inventory != nil {
+		for idx, job := range c.Inventory {
+			err := job.validateInventoryJob(idx)
+			if err != nil {
+				return model.JobsConfig{}, err
+			}
+		}
+	}
+
+	if c.Billing != nil {
+		if err := c.Billing.validateBillingJob(); err != nil {
+			return model.JobsConfig{}, err
+		}
+	}
+
+	if c.CostExplorer != nil {
+		for idx, job := range c.CostExplorer {
+			err := job.validateCostExplorerJob(idx)
+			if err != nil {
+				return model.JobsConfig{}, err
+			}
+		}
+	}
+
+	if c.ServiceQuotas != nil {
+		for idx, job := range c.ServiceQuotas {
+			err := job.validateServiceQuotaJob(idx)
+			if err != nil {
+				return model.JobsConfig{}, err
+			}
+		}
+	}
+
+	if c.TrustedAdvisor != nil {
+		if err := c.TrustedAdvisor.validateTrustedAdvisorJob(); err != nil {
+			return model.JobsConfig{}, err
+		}
+	}
+
+	if c.LogsInsights != nil {
+		for idx, job := range c.LogsInsights {
+			err := job.validateLogsInsightsJob(idx)
+			if err != nil {
+				return model.JobsConfig{}, err
+			}
+		}
+	}
+
+	if c.ContributorInsights != nil {
+		for idx, job := range c.ContributorInsights {
+			err := job.validateContributorInsightsJob(idx)
+			if err != nil {
+				return model.JobsConfig{}, err
+			}
+		}
+	}
+
+	for idx, hook := range c.Hooks {
+		if err := hook.validateHook(idx); err != nil {
+			return model.JobsConfig{}, err
+		}
+	}
+
+	if c.Organization != nil {
+		if err := c.Organization.validateOrganization(); err != nil {
+			return model.JobsConfig{}, err
+		}
+	}
+
+	for api, retry := range c.Retries {
+		if retry == nil {
+			return model.JobsConfig{}, fmt.Errorf("Retries [%s]: should not be empty", api)
+		}
+		if err := retry.validateRetry(api); err != nil {
+			return model.JobsConfig{}, err
+		}
+	}
+
+	for name, partition := range c.Partitions {
+		if partition == nil {
+			return model.JobsConfig{}, fmt.Errorf("Partitions [%s]: should not be empty", name)
+		}
+		if err := partition.validatePartition(name); err != nil {
+			return model.JobsConfig{}, err
+		}
+	}
+
 	if c.APIVersion != "" && c.APIVersion != "v1alpha1" {
 		return model.JobsConfig{}, fmt.Errorf("unknown apiVersion value '%s'", c.APIVersion)
 	}
@@ -194,10 +397,10 @@ func (j *Job) validateDiscoveryJob(jobIdx int) error {
 				return err
 			}
 		}
-	} else {
+	} else if !j.OrganizationAccounts {
 		return fmt.Errorf("no IAM roles configured. If the current IAM role is desired, an empty Role should be configured")
 	}
-	if len(j.Regions) == 0 {
+	if len(j.Regions) == 0 && SupportedServices.GetService(j.Type).HomeRegion == "" {
 		return fmt.Errorf("Discovery job [%s/%d]: Regions should not be empty", j.Type, jobIdx)
 	}
 	if len(j.Metrics) == 0 {
@@ -209,6 +412,12 @@ func (j *Job) validateDiscoveryJob(jobIdx int) error {
 			return err
 		}
 	}
+	if err := validateExportedTags(j.ExportedTagsOnMetrics, parent+": exportedTagsOnMetrics"); err != nil {
+		return err
+	}
+	if err := validateExportedTags(j.ExcludeTagsOnMetrics, parent+": excludeTagsOnMetrics"); err != nil {
+		return err
+	}
 
 	for _, st := range j.SearchTags {
 		if _, err := regexp.Compile(st.Value); err != nil {
@@ -216,7 +425,99 @@ func (j *Job) validateDiscoveryJob(jobIdx int) error {
 		}
 	}
 
-	return nil
+	for _, attribute := range j.InfoMetricAttributes {
+		if !slices.Contains(SupportedServices.GetService(j.Type).InfoMetricAttributes, attribute) {
+			return fmt.Errorf("Discovery job [%s/%d]: info metric attribute %s is not supported for this namespace", j.Type, jobIdx, attribute)
+		}
+	}
+
+	if j.LambdaResourceMode != "" {
+		if SupportedServices.GetService(j.Type).Namespace != "AWS/Lambda" {
+			return fmt.Errorf("Discovery job [%s/%d]: lambdaResourceMode is only supported for AWS/Lambda", j.Type, jobIdx)
+		}
+		if j.LambdaResourceMode != model.LambdaResourceModeFunction && j.LambdaResourceMode != model.LambdaResourceModeAlias {
+			return fmt.Errorf("Discovery job [%s/%d]: unknown lambdaResourceMode value '%s'", j.Type, jobIdx, j.LambdaResourceMode)
+		}
+	}
+
+	if j.APIGatewayGranularity != "" {
+		if SupportedServices.GetService(j.Type).Namespace != "AWS/ApiGateway" {
+			return fmt.Errorf("Discovery job [%s/%d]: apiGatewayGranularity is only supported for AWS/ApiGateway", j.Type, jobIdx)
+		}
+		switch j.APIGatewayGranularity {
+		case model.APIGatewayGranularityAPI, model.APIGatewayGranularityStage, model.APIGatewayGranularityMethod:
+		default:
+			return fmt.Errorf("Discovery job [%s/%d]: unknown apiGatewayGranularity value '%s'", j.Type, jobIdx, j.APIGatewayGranularity)
+		}
+	}
+
+	if j.AddCapacityMetrics && SupportedServices.GetService(j.Type).Namespace != "AWS/AutoScaling" {
+		return fmt.Errorf("Discovery job [%s/%d]: addCapacityMetrics is only supported for AWS/AutoScaling", j.Type, jobIdx)
+	}
+
+	if j.AddLogGroupMetrics && SupportedServices.GetService(j.Type).Namespace != "AWS/Logs" {
+		return fmt.Errorf("Discovery job [%s/%d]: addLogGroupMetrics is only supported for AWS/Logs", j.Type, jobIdx)
+	}
+
+	if j.ExpandElastiCacheNodes && SupportedServices.GetService(j.Type).Namespace != "AWS/ElastiCache" {
+		return fmt.Errorf("Discovery job [%s/%d]: expandElastiCacheNodes is only supported for AWS/ElastiCache", j.Type, jobIdx)
+	}
+
+	if len(j.KafkaTopics) > 0 && SupportedServices.GetService(j.Type).Namespace != "AWS/Kafka" {
+		return fmt.Errorf("Discovery job [%s/%d]: kafkaTopics is only supported for AWS/Kafka", j.Type, jobIdx)
+	}
+	for _, topic := range j.KafkaTopics {
+		if _, err := regexp.Compile(topic); err != nil {
+			return fmt.Errorf("Discovery job [%s/%d]: kafka topic %s has invalid regex value: %w", j.Type, jobIdx, topic, err)
+		}
+	}
+
+	if len(j.WAFRules) > 0 && SupportedServices.GetService(j.Type).Namespace != "AWS/WAFV2" {
+		return fmt.Errorf("Discovery job [%s/%d]: wafRules is only supported for AWS/WAFV2", j.Type, jobIdx)
+	}
+	for _, rule := range j.WAFRules {
+		if _, err := regexp.Compile(rule); err != nil {
+			return fmt.Errorf("Discovery job [%s/%d]: waf rule %s has invalid regex value: %w", j.Type, jobIdx, rule, err)
+		}
+	}
+
+	if len(j.CloudFrontBreakdowns) > 0 && SupportedServices.GetService(j.Type).Namespace != "AWS/CloudFront" {
+		return fmt.Errorf("Discovery job [%s/%d]: cloudFrontBreakdowns is only supported for AWS/CloudFront", j.Type, jobIdx)
+	}
+	for _, breakdown := range j.CloudFrontBreakdowns {
+		if breakdown == "" {
+			return fmt.Errorf("Discovery job [%s/%d]: cloudFrontBreakdowns should not contain empty dimension names", j.Type, jobIdx)
+		}
+	}
+
+	if j.IncludeShardLevel && SupportedServices.GetService(j.Type).Namespace != "AWS/Kinesis" {
+		return fmt.Errorf("Discovery job [%s/%d]: includeShardLevel is only supported for AWS/Kinesis", j.Type, jobIdx)
+	}
+	if j.MaxShards != 0 && !j.IncludeShardLevel {
+		return fmt.Errorf("Discovery job [%s/%d]: maxShards requires includeShardLevel", j.Type, jobIdx)
+	}
+	if j.MaxShards < 0 {
+		return fmt.Errorf("Discovery job [%s/%d]: maxShards should be a positive integer", j.Type, jobIdx)
+	}
+
+	if err := validatePriority(j.Priority, parent); err != nil {
+		return err
+	}
+	if j.UnhealthyAfter < 0 {
+		return fmt.Errorf("%s: UnhealthyAfter should be a positive integer", parent)
+	}
+	if err := validateSchedule(j.Schedule, parent); err != nil {
+		return err
+	}
+
+	if err := validateLabelTransforms(j.LabelTransforms, parent); err != nil {
+		return err
+	}
+	if err := validateNamespaceAlias(j.NamespaceAlias, parent); err != nil {
+		return err
+	}
+
+	return validateProcessors(j.Processors, parent)
 }
 
 func (j *CustomNamespace) validateCustomNamespaceJob(jobIdx int) error {
@@ -249,7 +550,24 @@ func (j *CustomNamespace) validateCustomNamespaceJob(jobIdx int) error {
 		}
 	}
 
-	return nil
+	if err := validatePriority(j.Priority, parent); err != nil {
+		return err
+	}
+	if j.UnhealthyAfter < 0 {
+		return fmt.Errorf("%s: UnhealthyAfter should be a positive integer", parent)
+	}
+	if err := validateSchedule(j.Schedule, parent); err != nil {
+		return err
+	}
+
+	if err := validateLabelTransforms(j.LabelTransforms, parent); err != nil {
+		return err
+	}
+	if err := validateNamespaceAlias(j.NamespaceAlias, parent); err != nil {
+		return err
+	}
+
+	return validateProcessors(j.Processors, parent)
 }
 
 func (j *Static) validateStaticJob(jobIdx int) error {
@@ -277,11 +595,69 @@ func (j *Static) validateStaticJob(jobIdx int) error {
 		if err != nil {
 			return err
 		}
+		if len(metric.HistogramBuckets) > 0 {
+			return fmt.Errorf("Metric [%s/%d] in %v: HistogramBuckets is not supported by static jobs", metric.Name, metricIdx, parent)
+		}
+	}
+
+	if err := validatePriority(j.Priority, parent); err != nil {
+		return err
+	}
+	if j.UnhealthyAfter < 0 {
+		return fmt.Errorf("%s: UnhealthyAfter should be a positive integer", parent)
+	}
+	if err := validateSchedule(j.Schedule, parent); err != nil {
+		return err
+	}
+
+	if err := validateLabelTransforms(j.LabelTransforms, parent); err != nil {
+		return err
+	}
+	if err := validateNamespaceAlias(j.NamespaceAlias, parent); err != nil {
+		return err
+	}
+
+	return validateProcessors(j.Processors, parent)
+}
+
+func (j *Inventory) validateInventoryJob(jobIdx int) error {
+	if len(j.Types) == 0 {
+		return fmt.Errorf("Inventory job [%d]: Types should not be empty", jobIdx)
+	}
+	for _, t := range j.Types {
+		if SupportedServices.GetService(t) == nil {
+			return fmt.Errorf("Inventory job [%d]: Service is not in known list!: %s", jobIdx, t)
+		}
+	}
+	parent := fmt.Sprintf("Inventory job [%d]", jobIdx)
+	if len(j.Roles) > 0 {
+		for roleIdx, role := range j.Roles {
+			if err := role.ValidateRole(roleIdx, parent); err != nil {
+				return err
+			}
+		}
+	} else {
+		return fmt.Errorf("no IAM roles configured. If the current IAM role is desired, an empty Role should be configured")
+	}
+	if len(j.Regions) == 0 {
+		return fmt.Errorf("Inventory job [%d]: Regions should not be empty", jobIdx)
+	}
+
+	for _, st := range j.SearchTags {
+		if _, err := regexp.Compile(st.Value); err != nil {
+			return fmt.Errorf("Inventory job [%d]: search tag value for %s has invalid regex value %s: %w", jobIdx, st.Key, st.Value, err)
+		}
 	}
 
 	return nil
 }
 
+// highResolutionPeriods are the periods under a minute of the high-resolution metrics.
+var highResolutionPeriods = []int64{1, 5, 10, 30}
+
+// highResolutionRetentionSeconds is how long CloudWatch keeps the datapoints of the high-resolution periods.
+const highResolutionRetentionSeconds = 3 * 60 * 60
+
 func (m *Metric) validateMetric(metricIdx int, parent string, discovery *JobLevelMetricFields) error {
 	if m.Name == "" {
 		return fmt.Errorf("Metric [%s/%d] in %v: Name should not be empty", m.Name, metricIdx, parent)
@@ -334,12 +710,14 @@ func (m *Metric) validateMetric(metricIdx int, parent string, discovery *JobLeve
 		}
 	}
 
+	// The datapoints of the high-resolution periods are exported with their timestamp by
+	// default, for the scrapes to not collapse them into the datapoints of the scrape times
 	mAddCloudwatchTimestamp := m.AddCloudwatchTimestamp
 	if mAddCloudwatchTimestamp == nil {
 		if discovery != nil && discovery.AddCloudwatchTimestamp != nil {
 			mAddCloudwatchTimestamp = discovery.AddCloudwatchTimestamp
 		} else {
-			mAddCloudwatchTimestamp = aws.Bool(false)
+			mAddCloudwatchTimestamp = aws.Bool(mPeriod < 60)
 		}
 	}
 
@@ -349,16 +727,134 @@ func (m *Metric) validateMetric(metricIdx int, parent string, discovery *JobLeve
 			m.Name, metricIdx, parent, mLength, mPeriod,
 		)
 	}
+	if mPeriod < 60 {
+		if !slices.Contains(highResolutionPeriods, mPeriod) {
+			return fmt.Errorf("Metric [%s/%d] in %v: Period under 60 seconds should be one of the high-resolution periods %v, got %d", m.Name, metricIdx, parent, highResolutionPeriods, mPeriod)
+		}
+		if mLength+mDelay > highResolutionRetentionSeconds {
+			return fmt.Errorf(
+				"Metric [%s/%d] in %v: length(%d) and delay(%d) of the high-resolution period(%d) go back further than the %d seconds the high-resolution datapoints are kept",
+				m.Name, metricIdx, parent, mLength, mDelay, mPeriod, highResolutionRetentionSeconds,
+			)
+		}
+	}
+	mCompletePeriodsOnly := m.CompletePeriodsOnly
+	if mCompletePeriodsOnly == nil {
+		if discovery != nil && discovery.CompletePeriodsOnly != nil {
+			mCompletePeriodsOnly = discovery.CompletePeriodsOnly
+		} else {
+			mCompletePeriodsOnly = aws.Bool(false)
+		}
+	}
+
+	mStatLabelMode, err := statLabelMode(m.StatLabelMode, m.StatisticsAsLabel)
+	if err != nil {
+		return fmt.Errorf("Metric [%s/%d] in %v: %w", m.Name, metricIdx, parent, err)
+	}
+	if mStatLabelMode == "" {
+		if discovery != nil {
+			mStatLabelMode, err = statLabelMode(discovery.StatLabelMode, discovery.StatisticsAsLabel)
+			if err != nil {
+				return fmt.Errorf("%v: %w", parent, err)
+			}
+		}
+		if mStatLabelMode == "" {
+			mStatLabelMode = model.StatLabelModeSuffix
+		}
+	}
+	if mStatLabelMode != model.StatLabelModeSuffix && mStatLabelMode != model.StatLabelModeLabel {
+		return fmt.Errorf("Metric [%s/%d] in %v: StatLabelMode should be one of %s or %s, got '%s'", m.Name, metricIdx, parent, model.StatLabelModeSuffix, model.StatLabelModeLabel, mStatLabelMode)
+	}
+
+	if m.RoundingPeriod != nil && *m.RoundingPeriod < 1 {
+		return fmt.Errorf("Metric [%s/%d] in %v: RoundingPeriod value should be a positive integer", m.Name, metricIdx, parent)
+	}
+	switch m.TimestampAlignment {
+	case "", model.TimestampAlignmentQuery, model.TimestampAlignmentPeriod:
+	default:
+		return fmt.Errorf("Metric [%s/%d] in %v: TimestampAlignment should be one of %s or %s, got '%s'", m.Name, metricIdx, parent, model.TimestampAlignmentQuery, model.TimestampAlignmentPeriod, m.TimestampAlignment)
+	}
+
 	m.Length = mLength
 	m.Period = mPeriod
 	m.Delay = mDelay
 	m.NilToZero = mNilToZero
 	m.AddCloudwatchTimestamp = mAddCloudwatchTimestamp
+	m.CompletePeriodsOnly = mCompletePeriodsOnly
+	m.StatLabelMode = mStatLabelMode
 	m.Statistics = mStatistics
 
+	if len(m.HistogramBuckets) > 0 {
+		if !slices.IsSorted(m.HistogramBuckets) || len(slices.Compact(slices.Clone(m.HistogramBuckets))) != len(m.HistogramBuckets) {
+			return fmt.Errorf("Metric [%s/%d] in %v: HistogramBuckets should be in increasing order", m.Name, metricIdx, parent)
+		}
+		m.Statistics = withHistogramStatistics(m.Statistics, m.HistogramBuckets)
+	}
+
+	return nil
+}
+
+// statLabelMode returns the StatLabelMode set by statLabelMode or its shorthand
+// statisticsAsLabel, empty when neither is set.
+func statLabelMode(mode string, statisticsAsLabel *bool) (string, error) {
+	if statisticsAsLabel == nil {
+		return mode, nil
+	}
+	asLabelMode := model.StatLabelModeSuffix
+	if *statisticsAsLabel {
+		asLabelMode = model.StatLabelModeLabel
+	}
+	if mode != "" && mode != asLabelMode {
+		return "", fmt.Errorf("statisticsAsLabel: %t conflicts with statLabelMode: %s", *statisticsAsLabel, mode)
+	}
+	return asLabelMode, nil
+}
+
+var namespaceAliasRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// validateNamespaceAlias validates the alias replacing the namespace of a job in the
+// names of its metrics, e.g. alb for aws_alb_request_count.
+func validateNamespaceAlias(alias string, parent string) error {
+	if alias != "" && !namespaceAliasRegexp.MatchString(alias) {
+		return fmt.Errorf("%s: NamespaceAlias should only contain letters, digits and underscores, and not start with a digit, got '%s'", parent, alias)
+	}
 	return nil
 }
 
+// validatePriority validates the priority class of a job, normal when empty.
+func validatePriority(priority string, parent string) error {
+	switch priority {
+	case "", model.PriorityHigh, model.PriorityNormal, model.PriorityLow:
+		return nil
+	}
+	return fmt.Errorf("%s: Priority should be one of %s, %s or %s, got '%s'", parent, model.PriorityHigh, model.PriorityNormal, model.PriorityLow, priority)
+}
+
+// toModelPriority returns the priority class of a job, normal when empty.
+func toModelPriority(priority string) string {
+	if priority == "" {
+		return model.PriorityNormal
+	}
+	return priority
+}
+
+// withHistogramStatistics adds the statistics a histogram is synthesized from to the
+// statistics, if missing: SampleCount, Sum and the percentile rank of every bucket.
+func withHistogramStatistics(statistics []string, buckets []float64) []string {
+	required := []string{"SampleCount", "Sum"}
+	for _, bucket := range buckets {
+		required = append(required, model.HistogramBucketStatistic(bucket))
+	}
+
+	statistics = slices.Clone(statistics)
+	for _, statistic := range required {
+		if !slices.Contains(statistics, statistic) {
+			statistics = append(statistics, statistic)
+		}
+	}
+	return statistics
+}
+
 func (c *ScrapeConf) toModelConfig() model.JobsConfig {
 	jobsCfg := model.JobsConfig{}
 	jobsCfg.StsRegion = c.StsRegion
@@ -368,6 +864,9 @@ func (c *ScrapeConf) toModelConfig() model.JobsConfig {
 
 		job := model.DiscoveryJob{}
 		job.Regions = discoveryJob.Regions
+		if svc.HomeRegion != "" {
+			job.Regions = []string{svc.HomeRegion}
+		}
 		job.Type = discoveryJob.Type
 		job.DimensionNameRequirements = discoveryJob.DimensionNameRequirements
 		job.RoundingPeriod = discoveryJob.RoundingPeriod
@@ -379,20 +878,42 @@ func (c *ScrapeConf) toModelConfig() model.JobsConfig {
 		job.NilToZero = discoveryJob.NilToZero
 		job.AddCloudwatchTimestamp = discoveryJob.AddCloudwatchTimestamp
 		job.Roles = toModelRoles(discoveryJob.Roles)
+		job.OrganizationAccounts = discoveryJob.OrganizationAccounts
 		job.SearchTags = toModelSearchTags(discoveryJob.SearchTags)
 		job.CustomTags = toModelTags(discoveryJob.CustomTags)
 		job.Metrics = toModelMetricConfig(discoveryJob.Metrics)
 		job.IncludeContextOnInfoMetrics = discoveryJob.IncludeContextOnInfoMetrics
-		job.DimensionsRegexps = svc.ToModelDimensionsRegexp()
-
-		job.ExportedTagsOnMetrics = []string{}
-		if len(c.Discovery.ExportedTagsOnMetrics) > 0 {
-			if exportedTags, ok := c.Discovery.ExportedTagsOnMetrics[svc.Namespace]; ok {
-				job.ExportedTagsOnMetrics = exportedTags
-			} else if exportedTags, ok := c.Discovery.ExportedTagsOnMetrics[svc.Alias]; ok {
-				job.ExportedTagsOnMetrics = exportedTags
-			}
+		if discoveryJob.ExportInfoMetrics != nil {
+			job.DisableInfoMetrics = !*discoveryJob.ExportInfoMetrics
+		} else if c.Discovery.ExportInfoMetrics != nil {
+			job.DisableInfoMetrics = !*c.Discovery.ExportInfoMetrics
+		}
+		job.AddResourceAttributes = discoveryJob.AddResourceAttributes
+		job.AddKubernetesLabels = discoveryJob.AddKubernetesLabels
+		job.AddCapacityMetrics = discoveryJob.AddCapacityMetrics
+		job.AddLogGroupMetrics = discoveryJob.AddLogGroupMetrics
+		job.InfoMetricAttributes = discoveryJob.InfoMetricAttributes
+		job.LambdaResourceMode = discoveryJob.LambdaResourceMode
+		job.APIGatewayGranularity = discoveryJob.APIGatewayGranularity
+		job.ExpandElastiCacheNodes = discoveryJob.ExpandElastiCacheNodes
+		job.KafkaTopics = toModelRegexps(discoveryJob.KafkaTopics)
+		job.WAFRules = toModelRegexps(discoveryJob.WAFRules)
+		job.CloudFrontBreakdowns = discoveryJob.CloudFrontBreakdowns
+		job.IncludeShardLevel = discoveryJob.IncludeShardLevel
+		job.MaxShards = discoveryJob.MaxShards
+		if job.IncludeShardLevel && job.MaxShards == 0 {
+			job.MaxShards = model.DefaultMaxShards
 		}
+		job.LabelTransforms = toModelLabelTransforms(discoveryJob.LabelTransforms)
+		job.Processors = toModelProcessors(discoveryJob.Processors)
+		job.NamespaceAlias = discoveryJob.NamespaceAlias
+		job.Priority = toModelPriority(discoveryJob.Priority)
+		job.UnhealthyAfter = time.Duration(discoveryJob.UnhealthyAfter) * time.Second
+		// The schedule was validated
+		job.Schedule, _ = toModelSchedule(discoveryJob.Schedule)
+		job.DimensionsRegexps = c.Discovery.DimensionsRegexps.toModelDimensionsRegexps(svc)
+
+		job.ExportedTagsOnMetrics = c.Discovery.ExportedTagsOnMetrics.toModelExportedTags(svc, discoveryJob.ExportedTagsOnMetrics, discoveryJob.ExcludeTagsOnMetrics)
 
 		jobsCfg.DiscoveryJobs = append(jobsCfg.DiscoveryJobs, job)
 	}
@@ -406,6 +927,12 @@ func (c *ScrapeConf) toModelConfig() model.JobsConfig {
 		job.CustomTags = toModelTags(staticJob.CustomTags)
 		job.Dimensions = toModelDimensions(staticJob.Dimensions)
 		job.Metrics = toModelMetricConfig(staticJob.Metrics)
+		job.LabelTransforms = toModelLabelTransforms(staticJob.LabelTransforms)
+		job.Processors = toModelProcessors(staticJob.Processors)
+		job.NamespaceAlias = staticJob.NamespaceAlias
+		job.Priority = toModelPriority(staticJob.Priority)
+		job.UnhealthyAfter = time.Duration(staticJob.UnhealthyAfter) * time.Second
+		job.Schedule, _ = toModelSchedule(staticJob.Schedule)
 		jobsCfg.StaticJobs = append(jobsCfg.StaticJobs, job)
 	}
 
@@ -426,9 +953,77 @@ func (c *ScrapeConf) toModelConfig() model.JobsConfig {
 		job.Roles = toModelRoles(customNamespaceJob.Roles)
 		job.CustomTags = toModelTags(customNamespaceJob.CustomTags)
 		job.Metrics = toModelMetricConfig(customNamespaceJob.Metrics)
+		job.LabelTransforms = toModelLabelTransforms(customNamespaceJob.LabelTransforms)
+		job.Processors = toModelProcessors(customNamespaceJob.Processors)
+		job.NamespaceAlias = customNamespaceJob.NamespaceAlias
+		job.Priority = toModelPriority(customNamespaceJob.Priority)
+		job.UnhealthyAfter = time.Duration(customNamespaceJob.UnhealthyAfter) * time.Second
+		job.Schedule, _ = toModelSchedule(customNamespaceJob.Schedule)
 		jobsCfg.CustomNamespaceJobs = append(jobsCfg.CustomNamespaceJobs, job)
 	}
 
+	if c.Billing != nil {
+		jobsCfg.CustomNamespaceJobs = append(jobsCfg.CustomNamespaceJobs, c.Billing.toModelJob())
+	}
+
+	if c.CustomNamespaces != nil {
+		jobsCfg.CustomNamespaceDiscoveryJobs = append(jobsCfg.CustomNamespaceDiscoveryJobs, c.CustomNamespaces.toModelJob())
+	}
+
+	for _, costExplorerJob := range c.CostExplorer {
+		jobsCfg.CostExplorerJobs = append(jobsCfg.CostExplorerJobs, costExplorerJob.toModelJob())
+	}
+
+	for _, serviceQuotaJob := range c.ServiceQuotas {
+		jobsCfg.ServiceQuotaJobs = append(jobsCfg.ServiceQuotaJobs, serviceQuotaJob.toModelJob())
+	}
+
+	if c.TrustedAdvisor != nil {
+		jobsCfg.TrustedAdvisorJobs = append(jobsCfg.TrustedAdvisorJobs, c.TrustedAdvisor.toModelJob())
+	}
+
+	for _, logsInsightsJob := range c.LogsInsights {
+		jobsCfg.LogsInsightsJobs = append(jobsCfg.LogsInsightsJobs, logsInsightsJob.toModelJob())
+	}
+
+	for _, contributorInsightsJob := range c.ContributorInsights {
+		jobsCfg.ContributorInsightsJobs = append(jobsCfg.ContributorInsightsJobs, contributorInsightsJob.toModelJob())
+	}
+
+	for _, hook := range c.Hooks {
+		jobsCfg.Hooks = append(jobsCfg.Hooks, hook.toModelHook())
+	}
+
+	if c.Organization != nil {
+		jobsCfg.Organization = c.Organization.toModelOrganization()
+	}
+
+	if len(c.Retries) > 0 {
+		jobsCfg.Retries = make(map[string]model.RetryConfig, len(c.Retries))
+		for api, retry := range c.Retries {
+			jobsCfg.Retries[api] = retry.toModelRetry()
+		}
+	}
+
+	if len(c.Partitions) > 0 {
+		jobsCfg.Partitions = make(map[string]model.PartitionConfig, len(c.Partitions))
+		for name, partition := range c.Partitions {
+			jobsCfg.Partitions[name] = partition.toModelPartition()
+		}
+	}
+
+	for _, inventoryJob := range c.Inventory {
+		job := model.InventoryJob{}
+		job.Regions = inventoryJob.Regions
+		job.Roles = toModelRoles(inventoryJob.Roles)
+		job.SearchTags = toModelSearchTags(inventoryJob.SearchTags)
+		job.CustomTags = toModelTags(inventoryJob.CustomTags)
+		for _, t := range inventoryJob.Types {
+			job.Namespaces = append(job.Namespaces, SupportedServices.GetService(t).Namespace)
+		}
+		jobsCfg.InventoryJobs = append(jobsCfg.InventoryJobs, job)
+	}
+
 	return jobsCfg
 }
 
@@ -456,13 +1051,28 @@ func toModelSearchTags(tags []Tag) []model.SearchTag {
 	return ret
 }
 
+func toModelRegexps(values []string) []*regexp.Regexp {
+	ret := make([]*regexp.Regexp, 0, len(values))
+	for _, v := range values {
+		// This should never panic as long as regex validation continues to happen before model mapping
+		ret = append(ret, regexp.MustCompile(v))
+	}
+	return ret
+}
+
 func toModelRoles(roles []Role) []model.Role {
 	ret := make([]model.Role, 0, len(roles))
 	for _, r := range roles {
-		ret = append(ret, model.Role{
-			RoleArn:    r.RoleArn,
-			ExternalID: r.ExternalID,
-		})
+		role := model.Role{
+			RoleArn:         r.RoleArn,
+			ExternalID:      r.ExternalID,
+			StsRegion:       r.StsRegion,
+			SessionDuration: time.Duration(r.SessionDuration) * time.Second,
+		}
+		if r.Retry != nil {
+			role.Retry = r.Retry.toModelRetry()
+		}
+		ret = append(ret, role)
 	}
 	return ret
 }
@@ -489,6 +1099,12 @@ func toModelMetricConfig(metrics []*Metric) []*model.MetricConfig {
 			Delay:                  m.Delay,
 			NilToZero:              m.NilToZero,
 			AddCloudwatchTimestamp: m.AddCloudwatchTimestamp,
+			RoundingPeriod:         m.RoundingPeriod,
+			TimestampAlignment:     m.TimestampAlignment,
+			CompletePeriodsOnly:    m.CompletePeriodsOnly,
+			SkipIncompletePeriod:   m.SkipIncompletePeriod,
+			StatLabelMode:          m.StatLabelMode,
+			HistogramBuckets:       m.HistogramBuckets,
 		})
 	}
 	return ret
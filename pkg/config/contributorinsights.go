@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+const (
+	contributorInsightsDefaultLength          = int64(5 * 60)
+	contributorInsightsDefaultMaxContributors = int64(10)
+	// Maximum MaxContributorCount accepted by GetInsightRuleReport
+	contributorInsightsMaxContributors = int64(100)
+)
+
+type ContributorInsights struct {
+	Regions         []string `yaml:"regions"`
+	Roles           []Role   `yaml:"roles"`
+	RuleNames       []string `yaml:"ruleNames"`
+	Length          int64    `yaml:"length"`
+	RefreshInterval int64    `yaml:"refreshInterval"`
+	MaxContributors int64    `yaml:"maxContributors"`
+	OrderBy         string   `yaml:"orderBy"`
+	CustomTags      []Tag    `yaml:"customTags"`
+}
+
+func (j *ContributorInsights) validateContributorInsightsJob(jobIdx int) error {
+	parent := fmt.Sprintf("ContributorInsights job [%d]", jobIdx)
+	if len(j.Roles) > 0 {
+		for roleIdx, role := range j.Roles {
+			if err := role.ValidateRole(roleIdx, parent); err != nil {
+				return err
+			}
+		}
+	} else {
+		return fmt.Errorf("no IAM roles configured. If the current IAM role is desired, an empty Role should be configured")
+	}
+	if len(j.Regions) == 0 {
+		return fmt.Errorf("%s: Regions should not be empty", parent)
+	}
+	if len(j.RuleNames) == 0 {
+		return fmt.Errorf("%s: RuleNames should not be empty", parent)
+	}
+	if j.Length < 0 || j.RefreshInterval < 0 {
+		return fmt.Errorf("%s: Length and RefreshInterval should be positive integers", parent)
+	}
+	if j.MaxContributors < 0 || j.MaxContributors > contributorInsightsMaxContributors {
+		return fmt.Errorf("%s: MaxContributors should be between 1 and %d", parent, contributorInsightsMaxContributors)
+	}
+	if j.OrderBy != "" && j.OrderBy != "Sum" && j.OrderBy != "Maximum" {
+		return fmt.Errorf("%s: OrderBy should be Sum or Maximum, got %s", parent, j.OrderBy)
+	}
+	return nil
+}
+
+func (j *ContributorInsights) toModelJob() model.ContributorInsightsJob {
+	job := model.ContributorInsightsJob{}
+	job.Regions = j.Regions
+	job.Roles = toModelRoles(j.Roles)
+	job.RuleNames = j.RuleNames
+	length := j.Length
+	if length == 0 {
+		length = contributorInsightsDefaultLength
+	}
+	job.Length = time.Duration(length) * time.Second
+	// By default, the report is retrieved once per window
+	refreshInterval := j.RefreshInterval
+	if refreshInterval == 0 {
+		refreshInterval = length
+	}
+	job.RefreshInterval = time.Duration(refreshInterval) * time.Second
+	job.MaxContributors = j.MaxContributors
+	if job.MaxContributors == 0 {
+		job.MaxContributors = contributorInsightsDefaultMaxContributors
+	}
+	job.OrderBy = j.OrderBy
+	job.CustomTags = toModelTags(j.CustomTags)
+	return job
+}
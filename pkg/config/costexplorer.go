@@ -0,0 +1,106 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+const (
+	// The Cost Explorer API is only available in us-east-1.
+	costExplorerRegion                 = "us-east-1"
+	costExplorerDefaultMetric          = "UnblendedCost"
+	costExplorerDefaultRefreshInterval = int64(24 * 60 * 60)
+	costExplorerMaxGroupBy             = 2
+)
+
+var costExplorerMetrics = []string{
+	"AmortizedCost",
+	"BlendedCost",
+	"NetAmortizedCost",
+	"NetUnblendedCost",
+	"NormalizedUsageAmount",
+	"UnblendedCost",
+	"UsageQuantity",
+}
+
+var costExplorerGroupByTypes = []string{
+	model.CostExplorerGroupByDimension,
+	model.CostExplorerGroupByTag,
+	model.CostExplorerGroupByCostCategory,
+}
+
+type CostExplorer struct {
+	Name            string                `yaml:"name"`
+	Roles           []Role                `yaml:"roles"`
+	Metrics         []string              `yaml:"metrics"`
+	GroupBy         []CostExplorerGroupBy `yaml:"groupBy"`
+	RefreshInterval int64                 `yaml:"refreshInterval"`
+	AddTimestamp    bool                  `yaml:"addTimestamp"`
+	CustomTags      []Tag                 `yaml:"customTags"`
+}
+
+type CostExplorerGroupBy struct {
+	Type string `yaml:"type"`
+	Key  string `yaml:"key"`
+}
+
+func (j *CostExplorer) validateCostExplorerJob(jobIdx int) error {
+	if j.Name == "" {
+		return fmt.Errorf("CostExplorer job [%d]: Name should not be empty", jobIdx)
+	}
+	parent := fmt.Sprintf("CostExplorer job [%s/%d]", j.Name, jobIdx)
+	if len(j.Roles) > 0 {
+		for roleIdx, role := range j.Roles {
+			if err := role.ValidateRole(roleIdx, parent); err != nil {
+				return err
+			}
+		}
+	} else {
+		return fmt.Errorf("no IAM roles configured. If the current IAM role is desired, an empty Role should be configured")
+	}
+	for _, metric := range j.Metrics {
+		if !slices.Contains(costExplorerMetrics, metric) {
+			return fmt.Errorf("%s: unknown metric %s", parent, metric)
+		}
+	}
+	if len(j.GroupBy) > costExplorerMaxGroupBy {
+		return fmt.Errorf("%s: at most %d groupBy are supported", parent, costExplorerMaxGroupBy)
+	}
+	for _, g := range j.GroupBy {
+		if !slices.Contains(costExplorerGroupByTypes, g.Type) {
+			return fmt.Errorf("%s: unknown groupBy type %s", parent, g.Type)
+		}
+		if g.Key == "" {
+			return fmt.Errorf("%s: groupBy key should not be empty", parent)
+		}
+	}
+	if j.RefreshInterval < 0 {
+		return fmt.Errorf("%s: RefreshInterval should be a positive integer", parent)
+	}
+	return nil
+}
+
+func (j *CostExplorer) toModelJob() model.CostExplorerJob {
+	job := model.CostExplorerJob{}
+	job.Name = j.Name
+	job.Region = costExplorerRegion
+	job.Roles = toModelRoles(j.Roles)
+	job.Metrics = j.Metrics
+	if len(job.Metrics) == 0 {
+		job.Metrics = []string{costExplorerDefaultMetric}
+	}
+	for _, g := range j.GroupBy {
+		job.GroupBy = append(job.GroupBy, model.CostExplorerGroupBy{Type: g.Type, Key: g.Key})
+	}
+	refreshInterval := j.RefreshInterval
+	if refreshInterval == 0 {
+		refreshInterval = costExplorerDefaultRefreshInterval
+	}
+	job.RefreshInterval = time.Duration(refreshInterval) * time.Second
+	job.AddTimestamp = j.AddTimestamp
+	job.CustomTags = toModelTags(j.CustomTags)
+	return job
+}
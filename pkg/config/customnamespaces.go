@@ -0,0 +1,103 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/regexp"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+const customNamespacesParent = "CustomNamespaces discovery"
+
+// defaultCustomNamespacesRefreshInterval is how often the custom namespaces are discovered again.
+const defaultCustomNamespacesRefreshInterval = time.Hour
+
+// CustomNamespaces discovers the non AWS namespaces matching IncludeRegex with
+// ListMetrics, and exports all their metrics and dimensions without per
+// namespace configuration.
+type CustomNamespaces struct {
+	Regions              []string `yaml:"regions"`
+	Roles                []Role   `yaml:"roles"`
+	IncludeRegex         string   `yaml:"includeRegex"`
+	ExcludeRegex         string   `yaml:"excludeRegex"`
+	RecentlyActiveOnly   bool     `yaml:"recentlyActiveOnly"`
+	RoundingPeriod       *int64   `yaml:"roundingPeriod"`
+	RefreshInterval      int64    `yaml:"refreshInterval"`
+	CustomTags           []Tag    `yaml:"customTags"`
+	JobLevelMetricFields `yaml:",inline"`
+}
+
+func (j *CustomNamespaces) validateCustomNamespacesJob() error {
+	if len(j.Roles) > 0 {
+		for roleIdx, role := range j.Roles {
+			if err := role.ValidateRole(roleIdx, customNamespacesParent); err != nil {
+				return err
+			}
+		}
+	} else {
+		return fmt.Errorf("no IAM roles configured. If the current IAM role is desired, an empty Role should be configured")
+	}
+	if len(j.Regions) == 0 {
+		return fmt.Errorf("%s: Regions should not be empty", customNamespacesParent)
+	}
+	if j.IncludeRegex == "" {
+		return fmt.Errorf("%s: IncludeRegex should not be empty", customNamespacesParent)
+	}
+	if _, err := regexp.Compile(j.IncludeRegex); err != nil {
+		return fmt.Errorf("%s: includeRegex has invalid regex value %s: %w", customNamespacesParent, j.IncludeRegex, err)
+	}
+	if _, err := regexp.Compile(j.ExcludeRegex); err != nil {
+		return fmt.Errorf("%s: excludeRegex has invalid regex value %s: %w", customNamespacesParent, j.ExcludeRegex, err)
+	}
+	if j.RefreshInterval < 0 {
+		return fmt.Errorf("%s: RefreshInterval should be a positive integer", customNamespacesParent)
+	}
+	if _, err := j.metric(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// metric returns the settings applied to all the discovered metrics,
+// with the same defaults as the metrics of the other jobs.
+func (j *CustomNamespaces) metric() (*Metric, error) {
+	m := &Metric{Name: "*"}
+	if err := m.validateMetric(0, customNamespacesParent, &j.JobLevelMetricFields); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (j *CustomNamespaces) toModelJob() model.CustomNamespaceDiscoveryJob {
+	job := model.CustomNamespaceDiscoveryJob{}
+	job.Regions = j.Regions
+	job.Roles = toModelRoles(j.Roles)
+	job.RecentlyActiveOnly = j.RecentlyActiveOnly
+	job.RoundingPeriod = j.RoundingPeriod
+	job.CustomTags = toModelTags(j.CustomTags)
+	job.RefreshInterval = defaultCustomNamespacesRefreshInterval
+	if j.RefreshInterval > 0 {
+		job.RefreshInterval = time.Duration(j.RefreshInterval) * time.Second
+	}
+	// This should never panic as long as regex validation continues to happen before model mapping
+	job.IncludeRegex = regexp.MustCompile(j.IncludeRegex)
+	if j.ExcludeRegex != "" {
+		job.ExcludeRegex = regexp.MustCompile(j.ExcludeRegex)
+	}
+
+	m, _ := j.metric()
+	job.Metric = &model.MetricConfig{
+		Statistics:             m.Statistics,
+		Period:                 m.Period,
+		Length:                 m.Length,
+		Delay:                  m.Delay,
+		NilToZero:              m.NilToZero,
+		AddCloudwatchTimestamp: m.AddCloudwatchTimestamp,
+		AddHistoricalMetrics:   j.AddHistoricalMetrics,
+		CompletePeriodsOnly:    m.CompletePeriodsOnly,
+		StatLabelMode:          m.StatLabelMode,
+	}
+	return job
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/grafana/regexp"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+// DimensionsRegexps replaces the built-in regexps extracting the dimensions
+// from the resource ARNs, per service namespace or alias.
+type DimensionsRegexps map[string][]string
+
+func (d DimensionsRegexps) validateDimensionsRegexps() error {
+	for service, regexps := range d {
+		if SupportedServices.GetService(service) == nil {
+			return fmt.Errorf("Discovery: dimensionsRegexps service is not in known list!: %s", service)
+		}
+		if len(regexps) == 0 {
+			return fmt.Errorf("Discovery: dimensionsRegexps of %s should not be empty", service)
+		}
+		for _, expr := range regexps {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return fmt.Errorf("Discovery: dimensionsRegexps of %s has invalid regex value %s: %w", service, expr, err)
+			}
+			// The names of the groups are the dimensions, the first name is the whole match
+			if re.NumSubexp() == 0 || slices.Contains(re.SubexpNames()[1:], "") {
+				return fmt.Errorf("Discovery: dimensionsRegexps of %s should only have named groups, got %s", service, expr)
+			}
+		}
+	}
+	return nil
+}
+
+// toModelDimensionsRegexps returns the dimensions regexps of the service,
+// the configured ones replacing the built-in ones.
+func (d DimensionsRegexps) toModelDimensionsRegexps(svc *ServiceConfig) []model.DimensionsRegexp {
+	regexps, ok := d[svc.Namespace]
+	if !ok {
+		regexps, ok = d[svc.Alias]
+	}
+	if !ok {
+		return svc.ToModelDimensionsRegexp()
+	}
+
+	compiled := make([]*regexp.Regexp, 0, len(regexps))
+	for _, expr := range regexps {
+		compiled = append(compiled, regexp.MustCompile(expr))
+	}
+	return toModelDimensionsRegexps(compiled)
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/grafana/regexp"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+// ExportedTagsOnMetrics lists the resource tags exported as labels of the metrics, per
+// service namespace or alias, the entries of allServicesExportedTags being exported
+// by all the services. An entry is the key of a tag, or a regexp between slashes
+// matching the whole keys of the tags, optionally followed by -> and the name of the
+// label replacing the key, e.g. "aws:cloudformation:stack-name -> cfn_stack" or
+// "/aws:cloudformation:(.*)/ -> cfn_$1".
+type ExportedTagsOnMetrics map[string][]string
+
+// allServicesExportedTags is the key of the entries of ExportedTagsOnMetrics exported
+// by all the services, before their own entries.
+const allServicesExportedTags = "*"
+
+// exportedTagNameRegexp matches the names replacing the keys of the tags, which may
+// reference the groups of the regexp.
+var exportedTagNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_${}]+$`)
+
+func (e ExportedTagsOnMetrics) validateExportedTagsOnMetrics() error {
+	for service, entries := range e {
+		if err := validateExportedTags(entries, fmt.Sprintf("Discovery: exportedTagsOnMetrics of %s", service)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateExportedTags(entries []string, parent string) error {
+	for _, entry := range entries {
+		if _, err := parseExportedTag(entry); err != nil {
+			return fmt.Errorf("%s has invalid entry '%s': %w", parent, entry, err)
+		}
+	}
+	return nil
+}
+
+// toModelExportedTags returns the tags exported on the metrics of a job of the service:
+// the entries of all the services, then the ones of the service and the ones added by
+// the job, without the ones whose tag key or regexp is excluded by the job.
+func (e ExportedTagsOnMetrics) toModelExportedTags(svc *ServiceConfig, added []string, excluded []string) []model.ExportedTag {
+	entries := slices.Clone(e[allServicesExportedTags])
+	if serviceEntries, ok := e[svc.Namespace]; ok {
+		entries = append(entries, serviceEntries...)
+	} else {
+		entries = append(entries, e[svc.Alias]...)
+	}
+	entries = append(entries, added...)
+
+	tags := make([]model.ExportedTag, 0, len(entries))
+	seen := make(map[string]struct{}, len(entries))
+	for _, entry := range entries {
+		selector := exportedTagSelector(entry)
+		if _, ok := seen[entry]; ok || slices.ContainsFunc(excluded, func(e string) bool { return exportedTagSelector(e) == selector }) {
+			continue
+		}
+		seen[entry] = struct{}{}
+		// This should never fail as long as validation continues to happen before model mapping
+		tag, _ := parseExportedTag(entry)
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// exportedTagSelector returns the tag key or regexp of an entry, without the label name.
+func exportedTagSelector(entry string) string {
+	selector, _, _ := strings.Cut(entry, "->")
+	return strings.TrimSpace(selector)
+}
+
+func parseExportedTag(entry string) (model.ExportedTag, error) {
+	_, name, renamed := strings.Cut(entry, "->")
+	selector := exportedTagSelector(entry)
+	tag := model.ExportedTag{Key: selector}
+	if renamed {
+		tag.Name = strings.TrimSpace(name)
+		if !exportedTagNameRegexp.MatchString(tag.Name) {
+			return tag, fmt.Errorf("the label name should only contain letters, digits, underscores and group references, got '%s'", tag.Name)
+		}
+	}
+	if selector == "" {
+		return tag, fmt.Errorf("the tag key should not be empty")
+	}
+
+	if len(selector) > 2 && strings.HasPrefix(selector, "/") && strings.HasSuffix(selector, "/") {
+		re, err := regexp.Compile("^(?:" + selector[1:len(selector)-1] + ")$")
+		if err != nil {
+			return tag, err
+		}
+		tag.Key = ""
+		tag.Regexp = re
+	}
+	return tag, nil
+}
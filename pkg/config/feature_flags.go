@@ -13,6 +13,9 @@ const AwsSdkV2 = "aws-sdk-v2"
 // AlwaysReturnInfoMetrics is a feature flag used to enable the return of info metrics even when there are no corresponding CloudWatch metrics
 const AlwaysReturnInfoMetrics = "always-return-info-metrics"
 
+// ProcessorPlugins is a feature flag used to enable the experimental Go plugins processors of the jobs
+const ProcessorPlugins = "processor-plugins"
+
 // FeatureFlags is an interface all objects that can tell wether or not a feature flag is enabled can implement.
 type FeatureFlags interface {
 	// IsFeatureEnabled tells if the feature flag identified by flag is enabled.
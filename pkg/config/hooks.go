@@ -0,0 +1,57 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+const hookDefaultTimeout = int64(10)
+
+var hookEvents = []string{
+	model.HookEventDiscoveryComplete,
+	model.HookEventMetricsBuilt,
+	model.HookEventScrapeError,
+}
+
+// Hook sends the events of the scrape lifecycle as JSON, either with a
+// POST request to a webhook or to the standard input of a command.
+type Hook struct {
+	Events  []string `yaml:"events"`
+	Webhook string   `yaml:"webhook"`
+	Exec    []string `yaml:"exec"`
+	Timeout int64    `yaml:"timeout"`
+}
+
+func (h *Hook) validateHook(hookIdx int) error {
+	if len(h.Events) == 0 {
+		return fmt.Errorf("Hook [%d]: Events should not be empty", hookIdx)
+	}
+	for _, event := range h.Events {
+		if !slices.Contains(hookEvents, event) {
+			return fmt.Errorf("Hook [%d]: unknown event '%s'", hookIdx, event)
+		}
+	}
+	if (h.Webhook == "") == (len(h.Exec) == 0) {
+		return fmt.Errorf("Hook [%d]: exactly one of Webhook and Exec should be set", hookIdx)
+	}
+	if h.Timeout < 0 {
+		return fmt.Errorf("Hook [%d]: Timeout should be a positive integer", hookIdx)
+	}
+	return nil
+}
+
+func (h *Hook) toModelHook() model.HookConfig {
+	timeout := h.Timeout
+	if timeout == 0 {
+		timeout = hookDefaultTimeout
+	}
+	return model.HookConfig{
+		Events:  h.Events,
+		Webhook: h.Webhook,
+		Exec:    h.Exec,
+		Timeout: time.Duration(timeout) * time.Second,
+	}
+}
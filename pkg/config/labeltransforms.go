@@ -0,0 +1,41 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	prom_model "github.com/prometheus/common/model"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/labeltransform"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+func validateLabelTransforms(transforms map[string]string, parent string) error {
+	for label, expression := range transforms {
+		if !prom_model.LabelName(label).IsValid() {
+			return fmt.Errorf("%s: label transform %q is not a valid label name", parent, label)
+		}
+		if _, err := labeltransform.Parse(expression); err != nil {
+			return fmt.Errorf("%s: label transform %q has an invalid expression: %w", parent, label, err)
+		}
+	}
+	return nil
+}
+
+// toModelLabelTransforms sorts the transforms by label, for the labels to be built deterministically.
+func toModelLabelTransforms(transforms map[string]string) []model.LabelTransformConfig {
+	if len(transforms) == 0 {
+		return nil
+	}
+	out := make([]model.LabelTransformConfig, 0, len(transforms))
+	for label, expression := range transforms {
+		out = append(out, model.LabelTransformConfig{
+			Label:      label,
+			Expression: expression,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Label < out[j].Label
+	})
+	return out
+}
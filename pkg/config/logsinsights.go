@@ -0,0 +1,74 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+const logsInsightsDefaultLength = int64(5 * 60)
+
+type LogsInsights struct {
+	Name            string   `yaml:"name"`
+	Regions         []string `yaml:"regions"`
+	Roles           []Role   `yaml:"roles"`
+	LogGroupNames   []string `yaml:"logGroupNames"`
+	Query           string   `yaml:"query"`
+	Length          int64    `yaml:"length"`
+	RefreshInterval int64    `yaml:"refreshInterval"`
+	LabelFields     []string `yaml:"labelFields"`
+	CustomTags      []Tag    `yaml:"customTags"`
+}
+
+func (j *LogsInsights) validateLogsInsightsJob(jobIdx int) error {
+	if j.Name == "" {
+		return fmt.Errorf("LogsInsights job [%d]: Name should not be empty", jobIdx)
+	}
+	parent := fmt.Sprintf("LogsInsights job [%s/%d]", j.Name, jobIdx)
+	if len(j.Roles) > 0 {
+		for roleIdx, role := range j.Roles {
+			if err := role.ValidateRole(roleIdx, parent); err != nil {
+				return err
+			}
+		}
+	} else {
+		return fmt.Errorf("no IAM roles configured. If the current IAM role is desired, an empty Role should be configured")
+	}
+	if len(j.Regions) == 0 {
+		return fmt.Errorf("%s: Regions should not be empty", parent)
+	}
+	if len(j.LogGroupNames) == 0 {
+		return fmt.Errorf("%s: LogGroupNames should not be empty", parent)
+	}
+	if j.Query == "" {
+		return fmt.Errorf("%s: Query should not be empty", parent)
+	}
+	if j.Length < 0 || j.RefreshInterval < 0 {
+		return fmt.Errorf("%s: Length and RefreshInterval should be positive integers", parent)
+	}
+	return nil
+}
+
+func (j *LogsInsights) toModelJob() model.LogsInsightsJob {
+	job := model.LogsInsightsJob{}
+	job.Name = j.Name
+	job.Regions = j.Regions
+	job.Roles = toModelRoles(j.Roles)
+	job.LogGroupNames = j.LogGroupNames
+	job.Query = j.Query
+	length := j.Length
+	if length == 0 {
+		length = logsInsightsDefaultLength
+	}
+	job.Length = time.Duration(length) * time.Second
+	// By default, the query is run once per window
+	refreshInterval := j.RefreshInterval
+	if refreshInterval == 0 {
+		refreshInterval = length
+	}
+	job.RefreshInterval = time.Duration(refreshInterval) * time.Second
+	job.LabelFields = j.LabelFields
+	job.CustomTags = toModelTags(j.CustomTags)
+	return job
+}
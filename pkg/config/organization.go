@@ -0,0 +1,60 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/regexp"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+// defaultOrganizationRefreshInterval is how often the accounts of the organization are re-evaluated.
+const defaultOrganizationRefreshInterval = time.Hour
+
+// Organization selects the member accounts of the AWS Organization scraped
+// by the discovery jobs with organizationAccounts.
+type Organization struct {
+	RoleName                   string   `yaml:"roleName"`
+	ExternalID                 string   `yaml:"externalId"`
+	ManagementRoleArn          string   `yaml:"managementRoleArn"`
+	RefreshInterval            int64    `yaml:"refreshInterval"`
+	IncludeAccounts            []string `yaml:"includeAccounts"`
+	ExcludeAccounts            []string `yaml:"excludeAccounts"`
+	IncludeOrganizationalUnits []string `yaml:"includeOrganizationalUnits"`
+	ExcludeOrganizationalUnits []string `yaml:"excludeOrganizationalUnits"`
+	AccountTags                []Tag    `yaml:"accountTags"`
+}
+
+func (o *Organization) validateOrganization() error {
+	if o.RoleName == "" {
+		return fmt.Errorf("Organization: RoleName should not be empty")
+	}
+	if o.RefreshInterval < 0 {
+		return fmt.Errorf("Organization: RefreshInterval should be a positive integer")
+	}
+	for _, tag := range o.AccountTags {
+		if _, err := regexp.Compile(tag.Value); err != nil {
+			return fmt.Errorf("Organization: account tag value for %s has invalid regex value %s: %w", tag.Key, tag.Value, err)
+		}
+	}
+	return nil
+}
+
+func (o *Organization) toModelOrganization() *model.OrganizationConfig {
+	refreshInterval := defaultOrganizationRefreshInterval
+	if o.RefreshInterval > 0 {
+		refreshInterval = time.Duration(o.RefreshInterval) * time.Second
+	}
+	return &model.OrganizationConfig{
+		RoleName:                   o.RoleName,
+		ExternalID:                 o.ExternalID,
+		ManagementRoleArn:          o.ManagementRoleArn,
+		RefreshInterval:            refreshInterval,
+		IncludeAccounts:            o.IncludeAccounts,
+		ExcludeAccounts:            o.ExcludeAccounts,
+		IncludeOrganizationalUnits: o.IncludeOrganizationalUnits,
+		ExcludeOrganizationalUnits: o.ExcludeOrganizationalUnits,
+		AccountTags:                toModelSearchTags(o.AccountTags),
+	}
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+var partitions = []string{
+	model.PartitionAWS,
+	model.PartitionChina,
+	model.PartitionGovCloud,
+}
+
+// Partition is the source of the credentials of the regions of a partition, e.g. the
+// keys of an aws-cn account, which cannot be used in the regions of the other partitions.
+type Partition struct {
+	Profile         string `yaml:"profile"`
+	AccessKeyID     string `yaml:"accessKeyId"`
+	SecretAccessKey string `yaml:"secretAccessKey"`
+	StsRegion       string `yaml:"stsRegion"`
+}
+
+func (p *Partition) validatePartition(name string) error {
+	if !slices.Contains(partitions, name) {
+		return fmt.Errorf("Partitions: unknown partition '%s'", name)
+	}
+	if (p.AccessKeyID == "") != (p.SecretAccessKey == "") {
+		return fmt.Errorf("Partitions [%s]: AccessKeyID and SecretAccessKey should be set together", name)
+	}
+	if p.Profile != "" && p.AccessKeyID != "" {
+		return fmt.Errorf("Partitions [%s]: Profile and AccessKeyID should not be set together", name)
+	}
+	if p.StsRegion != "" && model.PartitionOf(p.StsRegion) != name {
+		return fmt.Errorf("Partitions [%s]: StsRegion '%s' should be a region of the partition", name, p.StsRegion)
+	}
+	return nil
+}
+
+func (p *Partition) toModelPartition() model.PartitionConfig {
+	return model.PartitionConfig{
+		Profile:         p.Profile,
+		AccessKeyID:     p.AccessKeyID,
+		SecretAccessKey: p.SecretAccessKey,
+		StsRegion:       p.StsRegion,
+	}
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+const processorDefaultSymbol = "Process"
+
+// Processor is a Go plugin transforming the metrics of a job, only
+// applied when the processor-plugins feature flag is enabled.
+type Processor struct {
+	Plugin string `yaml:"plugin"`
+	Symbol string `yaml:"symbol"`
+}
+
+func validateProcessors(processors []Processor, parent string) error {
+	for idx, processor := range processors {
+		if processor.Plugin == "" {
+			return fmt.Errorf("%s: Processor [%d]: Plugin should not be empty", parent, idx)
+		}
+	}
+	return nil
+}
+
+func toModelProcessors(processors []Processor) []model.ProcessorConfig {
+	if len(processors) == 0 {
+		return nil
+	}
+	out := make([]model.ProcessorConfig, 0, len(processors))
+	for _, processor := range processors {
+		symbol := processor.Symbol
+		if symbol == "" {
+			symbol = processorDefaultSymbol
+		}
+		out = append(out, model.ProcessorConfig{
+			Plugin: processor.Plugin,
+			Symbol: symbol,
+		})
+	}
+	return out
+}
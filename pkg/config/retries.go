@@ -0,0 +1,51 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+var retryAPIs = []string{
+	model.APICloudWatch,
+	model.APITagging,
+	model.APISTS,
+}
+
+var retryModes = []string{
+	model.RetryModeStandard,
+	model.RetryModeAdaptive,
+}
+
+// Retry overrides the retries of the requests to an API.
+type Retry struct {
+	Mode        string `yaml:"mode"`
+	MaxAttempts int    `yaml:"maxAttempts"`
+	MaxBackoff  int64  `yaml:"maxBackoff"`
+}
+
+func (r *Retry) validateRetry(api string) error {
+	if !slices.Contains(retryAPIs, api) {
+		return fmt.Errorf("Retries: unknown API '%s'", api)
+	}
+	if r.Mode != "" && !slices.Contains(retryModes, r.Mode) {
+		return fmt.Errorf("Retries [%s]: unknown mode '%s'", api, r.Mode)
+	}
+	if r.MaxAttempts < 0 {
+		return fmt.Errorf("Retries [%s]: MaxAttempts should be a positive integer", api)
+	}
+	if r.MaxBackoff < 0 {
+		return fmt.Errorf("Retries [%s]: MaxBackoff should be a positive integer", api)
+	}
+	return nil
+}
+
+func (r *Retry) toModelRetry() model.RetryConfig {
+	return model.RetryConfig{
+		Mode:        r.Mode,
+		MaxAttempts: r.MaxAttempts,
+		MaxBackoff:  time.Duration(r.MaxBackoff) * time.Second,
+	}
+}
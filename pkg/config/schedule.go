@@ -0,0 +1,133 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+// Schedule restricts the scrapes of a job to its active windows, outside of its
+// blackout windows, e.g. not to scrape the jobs of a dev account at night.
+type Schedule struct {
+	Timezone  string           `yaml:"timezone"`
+	Active    []ScheduleWindow `yaml:"active"`
+	Blackouts []ScheduleWindow `yaml:"blackouts"`
+}
+
+// ScheduleWindow is a window from start to end, as HH:MM, on some days of the week.
+type ScheduleWindow struct {
+	Days  []string `yaml:"days"`
+	Start string   `yaml:"start"`
+	End   string   `yaml:"end"`
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+func validateSchedule(schedule *Schedule, parent string) error {
+	if schedule == nil {
+		return nil
+	}
+	_, err := toModelSchedule(schedule)
+	if err != nil {
+		return fmt.Errorf("%s: schedule: %w", parent, err)
+	}
+	return nil
+}
+
+// toModelSchedule returns the schedule in the timezone, UTC by default.
+func toModelSchedule(schedule *Schedule) (*model.Schedule, error) {
+	if schedule == nil {
+		return nil, nil
+	}
+	if len(schedule.Active) == 0 && len(schedule.Blackouts) == 0 {
+		return nil, fmt.Errorf("should have active or blackouts windows")
+	}
+	location := time.UTC
+	if schedule.Timezone != "" {
+		var err error
+		if location, err = time.LoadLocation(schedule.Timezone); err != nil {
+			return nil, fmt.Errorf("unknown timezone '%s'", schedule.Timezone)
+		}
+	}
+	active, err := toModelScheduleWindows(schedule.Active)
+	if err != nil {
+		return nil, fmt.Errorf("active: %w", err)
+	}
+	blackouts, err := toModelScheduleWindows(schedule.Blackouts)
+	if err != nil {
+		return nil, fmt.Errorf("blackouts: %w", err)
+	}
+	return &model.Schedule{Location: location, Active: active, Blackouts: blackouts}, nil
+}
+
+func toModelScheduleWindows(windows []ScheduleWindow) ([]model.ScheduleWindow, error) {
+	out := make([]model.ScheduleWindow, 0, len(windows))
+	for idx, window := range windows {
+		start, err := parseTimeOfDay(window.Start, false)
+		if err != nil {
+			return nil, fmt.Errorf("window [%d]: start %w", idx, err)
+		}
+		end, err := parseTimeOfDay(window.End, true)
+		if err != nil {
+			return nil, fmt.Errorf("window [%d]: end %w", idx, err)
+		}
+		if start == end {
+			return nil, fmt.Errorf("window [%d]: start and end should be different", idx)
+		}
+		days, err := parseWeekdays(window.Days)
+		if err != nil {
+			return nil, fmt.Errorf("window [%d]: %w", idx, err)
+		}
+		out = append(out, model.ScheduleWindow{Days: days, Start: start, End: end})
+	}
+	return out, nil
+}
+
+// parseTimeOfDay parses a HH:MM time of the day as the duration since midnight,
+// 24:00 being allowed as the end of a window.
+func parseTimeOfDay(value string, end bool) (time.Duration, error) {
+	if end && value == "24:00" {
+		return 24 * time.Hour, nil
+	}
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("'%s' should be a HH:MM time", value)
+	}
+	return time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute, nil
+}
+
+// parseWeekdays parses days of the week, as their first three letters, e.g. Mon,
+// or ranges of them, e.g. Mon-Fri.
+func parseWeekdays(values []string) ([]time.Weekday, error) {
+	var days []time.Weekday
+	for _, value := range values {
+		from, to, isRange := strings.Cut(value, "-")
+		first, ok := weekdays[strings.ToLower(from)]
+		if !ok {
+			return nil, fmt.Errorf("unknown day '%s'", value)
+		}
+		last := first
+		if isRange {
+			if last, ok = weekdays[strings.ToLower(to)]; !ok {
+				return nil, fmt.Errorf("unknown day '%s'", value)
+			}
+		}
+		for day := first; ; day = (day + 1) % 7 {
+			days = append(days, day)
+			if day == last {
+				break
+			}
+		}
+	}
+	return days, nil
+}
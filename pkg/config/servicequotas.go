@@ -0,0 +1,43 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+type ServiceQuota struct {
+	Regions      []string `yaml:"regions"`
+	Roles        []Role   `yaml:"roles"`
+	ServiceCodes []string `yaml:"serviceCodes"`
+	CustomTags   []Tag    `yaml:"customTags"`
+}
+
+func (j *ServiceQuota) validateServiceQuotaJob(jobIdx int) error {
+	if len(j.ServiceCodes) == 0 {
+		return fmt.Errorf("ServiceQuota job [%d]: ServiceCodes should not be empty", jobIdx)
+	}
+	parent := fmt.Sprintf("ServiceQuota job [%d]", jobIdx)
+	if len(j.Roles) > 0 {
+		for roleIdx, role := range j.Roles {
+			if err := role.ValidateRole(roleIdx, parent); err != nil {
+				return err
+			}
+		}
+	} else {
+		return fmt.Errorf("no IAM roles configured. If the current IAM role is desired, an empty Role should be configured")
+	}
+	if len(j.Regions) == 0 {
+		return fmt.Errorf("ServiceQuota job [%d]: Regions should not be empty", jobIdx)
+	}
+	return nil
+}
+
+func (j *ServiceQuota) toModelJob() model.ServiceQuotaJob {
+	job := model.ServiceQuotaJob{}
+	job.Regions = j.Regions
+	job.Roles = toModelRoles(j.Roles)
+	job.ServiceCodes = j.ServiceCodes
+	job.CustomTags = toModelTags(j.CustomTags)
+	return job
+}
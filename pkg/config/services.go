@@ -2,6 +2,7 @@ package config
 
 import (
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/grafana/regexp"
@@ -24,21 +25,51 @@ type ServiceConfig struct {
 	// extract dimensions names from a resource ARN. The regex should
 	// use named groups that correspond to AWS dimensions names.
 	// In cases where the dimension name has a space, it should be
-	// replaced with an underscore (`_`).
+	// replaced with an underscore (`_`), and an underscore of the
+	// dimension name with a double underscore (`__`).
 	DimensionRegexps []*regexp.Regexp
+	// InfoMetricAttributes is an optional list of attributes which
+	// can be requested by discovery jobs to be added on the info
+	// metric. They are fetched from the describe APIs of the service.
+	InfoMetricAttributes []string
+	// PublishDelay is how long after the end of a period CloudWatch
+	// has received all its datapoints, when longer than the default
+	// publish delay of the AWS namespaces.
+	PublishDelay time.Duration
+	// HomeRegion is the region the metrics and the resources of a global
+	// service are in. The discovery jobs of the namespace are routed to it,
+	// whatever their regions.
+	HomeRegion string
 }
 
 func (sc ServiceConfig) ToModelDimensionsRegexp() []model.DimensionsRegexp {
+	return toModelDimensionsRegexps(sc.DimensionRegexps)
+}
+
+var dimensionNameReplacer = strings.NewReplacer("__", "_", "_", " ")
+
+// groupDimensionNames are the dimensions whose names cannot be written as regex
+// group names, not even with underscores, by group name.
+var groupDimensionNames = map[string]string{
+	"SESConfigurationSet": "ses:configuration-set",
+}
+
+func toModelDimensionsRegexps(regexps []*regexp.Regexp) []model.DimensionsRegexp {
 	dr := []model.DimensionsRegexp{}
 
-	for _, regexp := range sc.DimensionRegexps {
+	for _, regexp := range regexps {
 		names := regexp.SubexpNames()
 		dimensionNames := make([]string, 0, len(names)-1)
 
 		// skip first name, it's always an empty string
 		for i := 1; i < len(names); i++ {
-			// in the regex names we use underscores where AWS dimensions have spaces
-			dimensionNames = append(dimensionNames, strings.ReplaceAll(names[i], "_", " "))
+			if name, ok := groupDimensionNames[names[i]]; ok {
+				dimensionNames = append(dimensionNames, name)
+				continue
+			}
+			// in the regex names we use underscores where AWS dimensions have spaces,
+			// and double underscores where they have underscores
+			dimensionNames = append(dimensionNames, dimensionNameReplacer.Replace(names[i]))
 		}
 
 		dr = append(dr, model.DimensionsRegexp{
@@ -61,6 +92,15 @@ func (sc serviceConfigs) GetService(serviceType string) *ServiceConfig {
 	return nil
 }
 
+// PublishDelay returns how long after the end of a period the
+// metrics of the namespace have received all their datapoints.
+func (sc serviceConfigs) PublishDelay(namespace string) time.Duration {
+	if svc := sc.GetService(namespace); svc != nil && svc.PublishDelay > 0 {
+		return svc.PublishDelay
+	}
+	return model.DefaultPublishDelay
+}
+
 var SupportedServices = serviceConfigs{
 	{
 		Namespace: "CWAgent",
@@ -70,6 +110,12 @@ var SupportedServices = serviceConfigs{
 		Namespace: "AWS/Usage",
 		Alias:     "usage",
 	},
+	{
+		// Application Signals publishes metrics per service and environment, and per operation
+		// or dependency of the services. There are no taggable resources to associate them with.
+		Namespace: "ApplicationSignals",
+		Alias:     "application-signals",
+	},
 	{
 		Namespace: "AWS/CertificateManager",
 		Alias:     "acm",
@@ -150,6 +196,7 @@ var SupportedServices = serviceConfigs{
 			aws.String("mq"),
 		},
 		DimensionRegexps: []*regexp.Regexp{
+			// Per queue and per topic metrics, with the Queue, Topic and VirtualHost dimensions, are associated to the broker
 			regexp.MustCompile("broker:(?P<Broker>[^:]+)"),
 		},
 	},
@@ -190,6 +237,8 @@ var SupportedServices = serviceConfigs{
 	{
 		Namespace: "AWS/Billing",
 		Alias:     "billing",
+		// The estimated charges are computed several times a day
+		PublishDelay: 6 * time.Hour,
 	},
 	{
 		Namespace: "AWS/Cassandra",
@@ -208,6 +257,20 @@ var SupportedServices = serviceConfigs{
 			regexp.MustCompile("distribution/(?P<DistributionId>[^/]+)"),
 		},
 	},
+	{
+		Namespace: "CloudWatchSynthetics",
+		Alias:     "synthetics",
+		ResourceFilters: []*string{
+			aws.String("synthetics:canary"),
+		},
+		DimensionRegexps: []*regexp.Regexp{
+			regexp.MustCompile(":canary:(?P<CanaryName>[^/]+)"),
+		},
+		InfoMetricAttributes: []string{
+			"runtime_version",
+			"schedule_expression",
+		},
+	},
 	{
 		Namespace: "AWS/Cognito",
 		Alias:     "cognito-idp",
@@ -218,6 +281,18 @@ var SupportedServices = serviceConfigs{
 			regexp.MustCompile("userpool/(?P<UserPool>[^/]+)"),
 		},
 	},
+	{
+		Namespace: "AWS/Connect",
+		Alias:     "connect",
+		ResourceFilters: []*string{
+			aws.String("connect:instance"),
+		},
+		DimensionRegexps: []*regexp.Regexp{
+			// Queue level metrics have InstanceId, MetricGroup and QueueName dimensions,
+			// queue ARNs have the queue id and not its name, so they are associated to the instance.
+			regexp.MustCompile(":instance/(?P<InstanceId>[^/]+)$"),
+		},
+	},
 	{
 		Namespace: "AWS/DataSync",
 		Alias:     "datasync",
@@ -284,6 +359,10 @@ var SupportedServices = serviceConfigs{
 		DimensionRegexps: []*regexp.Regexp{
 			regexp.MustCompile(":table/(?P<TableName>[^/]+)"),
 		},
+		InfoMetricAttributes: []string{
+			"billing_mode",
+			"table_class",
+		},
 	},
 	{
 		Namespace: "AWS/EBS",
@@ -306,6 +385,11 @@ var SupportedServices = serviceConfigs{
 			regexp.MustCompile("cluster:(?P<CacheClusterId>[^/]+)"),
 			regexp.MustCompile("serverlesscache:(?P<clusterId>[^/]+)"),
 		},
+		InfoMetricAttributes: []string{
+			"engine",
+			"engine_version",
+			"node_type",
+		},
 	},
 	{
 		Namespace: "AWS/MemoryDB",
@@ -346,6 +430,30 @@ var SupportedServices = serviceConfigs{
 			regexp.MustCompile(":service/(?P<ClusterName>[^/]+)/(?P<ServiceName>[^/]+)$"),
 		},
 	},
+	{
+		// Pod, node and service level metrics have a ClusterName dimension,
+		// and are associated with their cluster.
+		Namespace: "ContainerInsights",
+		Alias:     "eks-containerinsights",
+		ResourceFilters: []*string{
+			aws.String("eks:cluster"),
+		},
+		DimensionRegexps: []*regexp.Regexp{
+			regexp.MustCompile(":cluster/(?P<ClusterName>[^/]+)$"),
+		},
+	},
+	{
+		// Prometheus metrics scraped by the CloudWatch agent, on both EKS and ECS clusters
+		Namespace: "ContainerInsights/Prometheus",
+		Alias:     "containerinsights-prometheus",
+		ResourceFilters: []*string{
+			aws.String("eks:cluster"),
+			aws.String("ecs:cluster"),
+		},
+		DimensionRegexps: []*regexp.Regexp{
+			regexp.MustCompile(":cluster/(?P<ClusterName>[^/]+)$"),
+		},
+	},
 	{
 		Namespace: "ECS/ContainerInsights",
 		Alias:     "ecs-containerinsights",
@@ -360,6 +468,18 @@ var SupportedServices = serviceConfigs{
 			regexp.MustCompile(":service/(?P<ClusterName>[^/]+)/(?P<ServiceName>[^/]+)$"),
 		},
 	},
+	{
+		// Control plane metrics of the API server, scheduler and etcd of the clusters. Fargate
+		// usage is published in AWS/Usage, and the metrics of Fargate pods in ContainerInsights.
+		Namespace: "AWS/EKS",
+		Alias:     "eks",
+		ResourceFilters: []*string{
+			aws.String("eks:cluster"),
+		},
+		DimensionRegexps: []*regexp.Regexp{
+			regexp.MustCompile(":cluster/(?P<ClusterName>[^/]+)$"),
+		},
+	},
 	{
 		Namespace: "AWS/EFS",
 		Alias:     "efs",
@@ -438,6 +558,8 @@ var SupportedServices = serviceConfigs{
 		},
 		DimensionRegexps: []*regexp.Regexp{
 			regexp.MustCompile(":fleet/(?P<FleetId>[^/]+)"),
+			regexp.MustCompile(":gamesessionqueue/(?P<QueueName>[^/]+)"),
+			regexp.MustCompile(":matchmakingconfiguration/(?P<ConfigurationName>[^/]+)"),
 		},
 	},
 	{
@@ -446,6 +568,7 @@ var SupportedServices = serviceConfigs{
 		ResourceFilters: []*string{
 			aws.String("globalaccelerator"),
 		},
+		HomeRegion: "us-west-2",
 		DimensionRegexps: []*regexp.Regexp{
 			regexp.MustCompile("accelerator/(?P<Accelerator>[^/]+)$"),
 			regexp.MustCompile("accelerator/(?P<Accelerator>[^/]+)/listener/(?P<Listener>[^/]+)$"),
@@ -483,6 +606,10 @@ var SupportedServices = serviceConfigs{
 		DimensionRegexps: []*regexp.Regexp{
 			regexp.MustCompile(":cluster/(?P<Cluster_Name>[^/]+)"),
 		},
+		InfoMetricAttributes: []string{
+			"broker_count",
+			"kafka_version",
+		},
 	},
 	{
 		Namespace: "AWS/KafkaConnect",
@@ -524,6 +651,41 @@ var SupportedServices = serviceConfigs{
 			regexp.MustCompile(":function:(?P<FunctionName>[^/]+)"),
 		},
 	},
+	{
+		// Lambda Insights uses lower case dimension names
+		Namespace: "LambdaInsights",
+		Alias:     "lambda-insights",
+		ResourceFilters: []*string{
+			aws.String("lambda:function"),
+		},
+		DimensionRegexps: []*regexp.Regexp{
+			regexp.MustCompile(":function:(?P<function__name>[^/]+)"),
+		},
+	},
+	{
+		Namespace: "AWS/Logs",
+		Alias:     "logs",
+		ResourceFilters: []*string{
+			aws.String("logs:log-group"),
+		},
+		DimensionRegexps: []*regexp.Regexp{
+			regexp.MustCompile(":log-group:(?P<LogGroupName>[^:]+)"),
+		},
+		InfoMetricAttributes: []string{
+			"retention_in_days",
+			"log_group_class",
+		},
+	},
+	{
+		Namespace: "AWS/IVS",
+		Alias:     "ivs",
+		ResourceFilters: []*string{
+			aws.String("ivs:channel"),
+		},
+		DimensionRegexps: []*regexp.Regexp{
+			regexp.MustCompile(":channel/(?P<Channel>[^/]+)$"),
+		},
+	},
 	{
 		Namespace: "AWS/MediaConnect",
 		Alias:     "mediaconnect",
@@ -558,6 +720,19 @@ var SupportedServices = serviceConfigs{
 			regexp.MustCompile(":channel:(?P<ChannelId>.+)$"),
 		},
 	},
+	{
+		// Only MediaPackage v2 resources are associated, the ARNs of
+		// v1 channels do not contain the id used as Channel dimension.
+		Namespace: "AWS/MediaPackage",
+		Alias:     "mediapackage",
+		ResourceFilters: []*string{
+			aws.String("mediapackagev2"),
+		},
+		DimensionRegexps: []*regexp.Regexp{
+			regexp.MustCompile(":channelGroup/(?P<ChannelGroup>[^/]+)/channel/(?P<Channel>[^/]+)$"),
+			regexp.MustCompile(":channelGroup/(?P<ChannelGroup>[^/]+)/channel/(?P<Channel>[^/]+)/originEndpoint/(?P<OriginEndpoint>[^/]+)$"),
+		},
+	},
 	{
 		Namespace: "AWS/MediaTailor",
 		Alias:     "mediatailor",
@@ -612,6 +787,29 @@ var SupportedServices = serviceConfigs{
 			regexp.MustCompile(":loadbalancer/(?P<LoadBalancer>.+)$"),
 		},
 	},
+	{
+		Namespace: "AWS/NetworkManager",
+		Alias:     "networkmanager",
+		ResourceFilters: []*string{
+			aws.String("networkmanager:core-network"),
+			aws.String("networkmanager:attachment"),
+		},
+		DimensionRegexps: []*regexp.Regexp{
+			regexp.MustCompile(":attachment/(?P<Attachment>[^/]+)$"),
+			regexp.MustCompile(":core-network/(?P<CoreNetwork>[^/]+)$"),
+		},
+		HomeRegion: "us-west-2",
+	},
+	{
+		Namespace: "AWS/Pinpoint",
+		Alias:     "pinpoint",
+		ResourceFilters: []*string{
+			aws.String("mobiletargeting:apps"),
+		},
+		DimensionRegexps: []*regexp.Regexp{
+			regexp.MustCompile(":apps/(?P<ApplicationId>[^/]+)$"),
+		},
+	},
 	{
 		Namespace: "AWS/PrivateLinkEndpoints",
 		Alias:     "vpc-endpoint",
@@ -668,6 +866,19 @@ var SupportedServices = serviceConfigs{
 			regexp.MustCompile(":cluster:(?P<ClusterIdentifier>[^/]+)"),
 		},
 	},
+	{
+		Namespace: "AWS/Redshift-Serverless",
+		Alias:     "redshift-serverless",
+		ResourceFilters: []*string{
+			aws.String("redshift-serverless:workgroup"),
+			aws.String("redshift-serverless:namespace"),
+		},
+		// Workgroup and namespace ARNs only contain their ID, the name is appended to the ARN during discovery
+		DimensionRegexps: []*regexp.Regexp{
+			regexp.MustCompile(":workgroup/[^/]+/(?P<Workgroup>[^/]+)$"),
+			regexp.MustCompile(":namespace/[^/]+/(?P<Namespace>[^/]+)$"),
+		},
+	},
 	{
 		Namespace: "AWS/Route53Resolver",
 		Alias:     "route53-resolver",
@@ -676,9 +887,12 @@ var SupportedServices = serviceConfigs{
 		},
 		DimensionRegexps: []*regexp.Regexp{
 			regexp.MustCompile(":resolver-endpoint/(?P<EndpointId>[^/]+)"),
+			regexp.MustCompile(":firewall-rule-group/(?P<FirewallRuleGroupId>[^/]+)"),
+			regexp.MustCompile(":firewall-domain-list/(?P<FirewallDomainListId>[^/]+)"),
 		},
 	},
 	{
+		// Route 53 is a global service, its resources and metrics are in us-east-1
 		Namespace: "AWS/Route53",
 		Alias:     "route53",
 		ResourceFilters: []*string{
@@ -686,6 +900,7 @@ var SupportedServices = serviceConfigs{
 		},
 		DimensionRegexps: []*regexp.Regexp{
 			regexp.MustCompile(":healthcheck/(?P<HealthCheckId>[^/]+)"),
+			regexp.MustCompile(":hostedzone/(?P<HostedZoneId>[^/]+)"),
 		},
 	},
 	{
@@ -697,10 +912,28 @@ var SupportedServices = serviceConfigs{
 		DimensionRegexps: []*regexp.Regexp{
 			regexp.MustCompile("(?P<BucketName>[^:]+)$"),
 		},
+		InfoMetricAttributes: []string{
+			"metrics_filter_ids",
+		},
+	},
+	{
+		// Storage Lens metrics are published once a day in the home region of the dashboard,
+		// and may cover the buckets of all the accounts and regions of an organization:
+		// they are not associated with the resources discovered by the job.
+		Namespace: "AWS/S3/Storage-Lens",
+		Alias:     "s3-storage-lens",
 	},
 	{
 		Namespace: "AWS/SES",
 		Alias:     "ses",
+		ResourceFilters: []*string{
+			aws.String("ses:configuration-set"),
+		},
+		// The metrics of a configuration set have the ses:configuration-set dimension,
+		// the account level ones have none
+		DimensionRegexps: []*regexp.Regexp{
+			regexp.MustCompile(":configuration-set/(?P<SESConfigurationSet>[^/]+)$"),
+		},
 	},
 	{
 		Namespace: "AWS/States",
@@ -709,7 +942,8 @@ var SupportedServices = serviceConfigs{
 			aws.String("states"),
 		},
 		DimensionRegexps: []*regexp.Regexp{
-			regexp.MustCompile("(?P<StateMachineArn>.*)"),
+			regexp.MustCompile("(?P<StateMachineArn>.*:stateMachine:[^:]+)$"),
+			regexp.MustCompile("(?P<ActivityArn>.*:activity:[^:]+)$"),
 		},
 	},
 	{
@@ -731,6 +965,11 @@ var SupportedServices = serviceConfigs{
 		DimensionRegexps: []*regexp.Regexp{
 			regexp.MustCompile("(?P<QueueName>[^:]+)$"),
 		},
+		InfoMetricAttributes: []string{
+			"dead_letter_target_arn",
+			"max_receive_count",
+			"fifo_queue",
+		},
 	},
 	{
 		Namespace: "AWS/StorageGateway",
@@ -744,6 +983,16 @@ var SupportedServices = serviceConfigs{
 			regexp.MustCompile("^(?P<GatewayId>[^:/]+)/(?P<GatewayName>[^:]+)$"),
 		},
 	},
+	{
+		Namespace: "AWS/Transfer",
+		Alias:     "transfer",
+		ResourceFilters: []*string{
+			aws.String("transfer:server"),
+		},
+		DimensionRegexps: []*regexp.Regexp{
+			regexp.MustCompile(":server/(?P<ServerId>[^/]+)$"),
+		},
+	},
 	{
 		Namespace: "AWS/TransitGateway",
 		Alias:     "tgw",
@@ -786,7 +1035,10 @@ var SupportedServices = serviceConfigs{
 			aws.String("wafv2"),
 		},
 		DimensionRegexps: []*regexp.Regexp{
-			regexp.MustCompile("/webacl/(?P<WebACL>[^/]+)"),
+			// Regional web ACLs have a Region dimension, CloudFront ones are global
+			// and their metrics are in us-east-1, without the Region dimension
+			regexp.MustCompile(":wafv2:(?P<Region>[^:]+):[^:]*:regional/webacl/(?P<WebACL>[^/]+)"),
+			regexp.MustCompile(":global/webacl/(?P<WebACL>[^/]+)"),
 		},
 	},
 	{
@@ -816,9 +1068,11 @@ var SupportedServices = serviceConfigs{
 		Alias:     "sagemaker",
 		ResourceFilters: []*string{
 			aws.String("sagemaker:endpoint"),
+			aws.String("sagemaker:inference-component"),
 		},
 		DimensionRegexps: []*regexp.Regexp{
 			regexp.MustCompile(":endpoint/(?P<EndpointName>[^/]+)$"),
+			regexp.MustCompile(":inference-component/(?P<InferenceComponentName>[^/]+)$"),
 		},
 	},
 	{
@@ -837,6 +1091,9 @@ var SupportedServices = serviceConfigs{
 		ResourceFilters: []*string{
 			aws.String("sagemaker:training-job"),
 		},
+		DimensionRegexps: []*regexp.Regexp{
+			regexp.MustCompile(":training-job/(?P<Host>[^/]+)$"),
+		},
 	},
 	{
 		Namespace: "/aws/sagemaker/ProcessingJobs",
@@ -844,6 +1101,9 @@ var SupportedServices = serviceConfigs{
 		ResourceFilters: []*string{
 			aws.String("sagemaker:processing-job"),
 		},
+		DimensionRegexps: []*regexp.Regexp{
+			regexp.MustCompile(":processing-job/(?P<Host>[^/]+)$"),
+		},
 	},
 	{
 		Namespace: "/aws/sagemaker/TransformJobs",
@@ -851,6 +1111,9 @@ var SupportedServices = serviceConfigs{
 		ResourceFilters: []*string{
 			aws.String("sagemaker:transform-job"),
 		},
+		DimensionRegexps: []*regexp.Regexp{
+			regexp.MustCompile(":transform-job/(?P<Host>[^/]+)$"),
+		},
 	},
 	{
 		Namespace: "/aws/sagemaker/InferenceRecommendationsJobs",
@@ -862,6 +1125,16 @@ var SupportedServices = serviceConfigs{
 			regexp.MustCompile(":inference-recommendations-job/(?P<JobName>[^/]+)"),
 		},
 	},
+	{
+		Namespace: "/aws/sagemaker/InferenceComponents",
+		Alias:     "sagemaker-inference-components",
+		ResourceFilters: []*string{
+			aws.String("sagemaker:inference-component"),
+		},
+		DimensionRegexps: []*regexp.Regexp{
+			regexp.MustCompile(":inference-component/(?P<InferenceComponentName>[^/]+)$"),
+		},
+	},
 	{
 		Namespace: "AWS/Sagemaker/ModelBuildingPipeline",
 		Alias:     "sagemaker-model-building-pipeline",
@@ -883,6 +1156,8 @@ var SupportedServices = serviceConfigs{
 		},
 	},
 	{
+		// Bedrock metrics have a ModelId dimension, referring to foundation models
+		// which are not taggable, so all metrics are exported without resources.
 		Namespace: "AWS/Bedrock",
 		Alias:     "bedrock",
 	},
@@ -894,6 +1169,8 @@ var SupportedServices = serviceConfigs{
 		},
 		DimensionRegexps: []*regexp.Regexp{
 			regexp.MustCompile(":rule/(?P<EventBusName>[^/]+)/(?P<RuleName>[^/]+)$"),
+			// The rules of the default event bus have no bus name in their ARN
+			regexp.MustCompile(":rule/(?P<RuleName>[^/]+)$"),
 		},
 	},
 }
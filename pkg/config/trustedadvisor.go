@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+const (
+	// The support API is only available in us-east-1.
+	trustedAdvisorRegion                 = "us-east-1"
+	trustedAdvisorDefaultRefreshInterval = int64(60 * 60)
+)
+
+// TrustedAdvisor is a built-in job exporting the results
+// of the Trusted Advisor checks of the account.
+type TrustedAdvisor struct {
+	Roles           []Role   `yaml:"roles"`
+	Categories      []string `yaml:"categories"`
+	RefreshInterval int64    `yaml:"refreshInterval"`
+	CustomTags      []Tag    `yaml:"customTags"`
+}
+
+func (t *TrustedAdvisor) validateTrustedAdvisorJob() error {
+	if len(t.Roles) > 0 {
+		for roleIdx, role := range t.Roles {
+			if err := role.ValidateRole(roleIdx, "TrustedAdvisor job"); err != nil {
+				return err
+			}
+		}
+	} else {
+		return fmt.Errorf("no IAM roles configured. If the current IAM role is desired, an empty Role should be configured")
+	}
+	for _, category := range t.Categories {
+		if category == "" {
+			return fmt.Errorf("TrustedAdvisor job: Categories should not contain empty values")
+		}
+	}
+	if t.RefreshInterval < 0 {
+		return fmt.Errorf("TrustedAdvisor job: RefreshInterval should be a positive integer")
+	}
+	return nil
+}
+
+func (t *TrustedAdvisor) toModelJob() model.TrustedAdvisorJob {
+	job := model.TrustedAdvisorJob{}
+	job.Region = trustedAdvisorRegion
+	job.Roles = toModelRoles(t.Roles)
+	job.Categories = t.Categories
+	refreshInterval := t.RefreshInterval
+	if refreshInterval == 0 {
+		refreshInterval = trustedAdvisorDefaultRefreshInterval
+	}
+	job.RefreshInterval = time.Duration(refreshInterval) * time.Second
+	job.CustomTags = toModelTags(t.CustomTags)
+	return job
+}
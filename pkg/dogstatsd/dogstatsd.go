@@ -0,0 +1,134 @@
+// Package dogstatsd sends the metrics of the scrapes to a DogStatsD server,
+// e.g. a Datadog agent, over UDP or a Unix domain socket.
+package dogstatsd
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/promutil"
+)
+
+const (
+	// Recommended maximum payload sizes of the DogStatsD clients, to avoid fragmentation.
+	maxUDPPacketSize = 1432
+	maxUDSPacketSize = 8192
+)
+
+var tagReplacer = strings.NewReplacer(",", "_", "|", "_", "\n", "_")
+
+// Sink sends metrics as DogStatsD gauges, with their labels as tags.
+type Sink struct {
+	conn          net.Conn
+	maxPacketSize int
+	tagMapping    map[string]string
+}
+
+// NewSink connects to a DogStatsD server at an address such as udp://localhost:8125
+// or unix:///var/run/datadog/dsd.socket. The tag mapping renames the labels of the
+// metrics to tags, a label mapped to an empty name is not sent.
+func NewSink(address string, tagMapping map[string]string) (*Sink, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DogStatsD address %q: %w", address, err)
+	}
+
+	var conn net.Conn
+	var maxPacketSize int
+	switch u.Scheme {
+	case "udp":
+		conn, err = net.Dial("udp", u.Host)
+		maxPacketSize = maxUDPPacketSize
+	case "unix":
+		conn, err = net.Dial("unixgram", u.Path)
+		maxPacketSize = maxUDSPacketSize
+	default:
+		return nil, fmt.Errorf("invalid DogStatsD address %q: scheme should be udp or unix", address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to DogStatsD at %s: %w", address, err)
+	}
+
+	return &Sink{
+		conn:          conn,
+		maxPacketSize: maxPacketSize,
+		tagMapping:    tagMapping,
+	}, nil
+}
+
+// Send sends the metrics, batched in as few packets as possible. Metrics
+// without a value, e.g. missing datapoints exported as NaN, are skipped.
+func (s *Sink) Send(metrics []*promutil.PrometheusMetric) error {
+	packet := make([]byte, 0, s.maxPacketSize)
+	for _, metric := range metrics {
+		if metric.Value == nil || math.IsNaN(*metric.Value) {
+			continue
+		}
+		line := s.format(metric)
+		if len(packet) > 0 && len(packet)+len(line)+1 > s.maxPacketSize {
+			if _, err := s.conn.Write(packet); err != nil {
+				return err
+			}
+			packet = packet[:0]
+		}
+		if len(packet) > 0 {
+			packet = append(packet, '\n')
+		}
+		packet = append(packet, line...)
+	}
+	if len(packet) > 0 {
+		if _, err := s.conn.Write(packet); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// format formats a metric as name:value|g|#tag:value,...|T<timestamp>.
+func (s *Sink) format(metric *promutil.PrometheusMetric) string {
+	sb := strings.Builder{}
+	sb.WriteString(*metric.Name)
+	sb.WriteByte(':')
+	sb.WriteString(strconv.FormatFloat(*metric.Value, 'f', -1, 64))
+	sb.WriteString("|g")
+
+	tags := s.tags(metric.Labels)
+	if len(tags) > 0 {
+		sb.WriteString("|#")
+		sb.WriteString(strings.Join(tags, ","))
+	}
+	if metric.IncludeTimestamp {
+		sb.WriteString("|T")
+		sb.WriteString(strconv.FormatInt(metric.Timestamp.Unix(), 10))
+	}
+	return sb.String()
+}
+
+func (s *Sink) tags(labels map[string]string) []string {
+	tags := make([]string, 0, len(labels))
+	for label, value := range labels {
+		if value == "" {
+			continue
+		}
+		name := label
+		if mapped, ok := s.tagMapping[label]; ok {
+			if mapped == "" {
+				continue
+			}
+			name = mapped
+		}
+		tags = append(tags, name+":"+tagReplacer.Replace(value))
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// Close closes the connection to the DogStatsD server.
+func (s *Sink) Close() error {
+	return s.conn.Close()
+}
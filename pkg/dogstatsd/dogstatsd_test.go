@@ -0,0 +1,93 @@
+package dogstatsd
+
+import (
+	"math"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/promutil"
+)
+
+func TestSinkSend(t *testing.T) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer server.Close()
+
+	sink, err := NewSink("udp://"+server.LocalAddr().String(), map[string]string{
+		"dimension_QueueName": "queue",
+		"name":                "",
+	})
+	require.NoError(t, err)
+	defer sink.Close()
+
+	err = sink.Send([]*promutil.PrometheusMetric{
+		{
+			Name:   aws.String("aws_sqs_number_of_messages_sent_sum"),
+			Labels: map[string]string{"name": "arn:aws:sqs:eu-west-1:123456789012:orders", "dimension_QueueName": "orders", "region": "eu-west-1", "tag_team": ""},
+			Value:  aws.Float64(12),
+		},
+		{
+			Name:   aws.String("aws_sqs_approximate_age_of_oldest_message_maximum"),
+			Labels: map[string]string{"dimension_QueueName": "orders"},
+			Value:  aws.Float64(math.NaN()),
+		},
+		{
+			Name:             aws.String("aws_ec2_cpuutilization_average"),
+			Labels:           map[string]string{"tag_app": "web,api"},
+			Value:            aws.Float64(0.5),
+			IncludeTimestamp: true,
+			Timestamp:        time.Unix(1704067200, 0),
+		},
+	})
+	require.NoError(t, err)
+
+	buf := make([]byte, maxUDPPacketSize)
+	require.NoError(t, server.SetReadDeadline(time.Now().Add(5*time.Second)))
+	n, _, err := server.ReadFrom(buf)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"aws_sqs_number_of_messages_sent_sum:12|g|#queue:orders,region:eu-west-1",
+		"aws_ec2_cpuutilization_average:0.5|g|#tag_app:web_api|T1704067200",
+	}, strings.Split(string(buf[:n]), "\n"))
+}
+
+func TestSinkSendSplitsPackets(t *testing.T) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer server.Close()
+
+	sink, err := NewSink("udp://"+server.LocalAddr().String(), nil)
+	require.NoError(t, err)
+	defer sink.Close()
+
+	metrics := make([]*promutil.PrometheusMetric, 0, 100)
+	for i := 0; i < 100; i++ {
+		metrics = append(metrics, &promutil.PrometheusMetric{
+			Name:   aws.String("aws_sqs_number_of_messages_sent_sum"),
+			Labels: map[string]string{"dimension_QueueName": strings.Repeat("q", 20)},
+			Value:  aws.Float64(float64(i)),
+		})
+	}
+	require.NoError(t, sink.Send(metrics))
+
+	lines := 0
+	buf := make([]byte, maxUDPPacketSize)
+	for lines < len(metrics) {
+		require.NoError(t, server.SetReadDeadline(time.Now().Add(5*time.Second)))
+		n, _, err := server.ReadFrom(buf)
+		require.NoError(t, err)
+		require.LessOrEqual(t, n, maxUDPPacketSize)
+		lines += len(strings.Split(string(buf[:n]), "\n"))
+	}
+	require.Equal(t, len(metrics), lines)
+}
+
+func TestNewSinkInvalidAddress(t *testing.T) {
+	_, err := NewSink("tcp://localhost:8125", nil)
+	require.ErrorContains(t, err, "scheme should be udp or unix")
+}
@@ -0,0 +1,190 @@
+// Package emf ingests CloudWatch Embedded Metric Format (EMF) logs from Kinesis
+// data streams and Firehose delivery streams, and exposes the metrics they embed
+// with the same names and labels as the metrics polled from CloudWatch.
+package emf
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+// Sample holds the values of a metric embedded in an EMF log event.
+type Sample struct {
+	// LogGroup is the log group of the event, when received through
+	// a CloudWatch Logs subscription filter.
+	LogGroup   string
+	AccountID  string
+	Namespace  string
+	Metric     string
+	Dimensions []*model.Dimension
+	Values     []float64
+	Timestamp  time.Time
+}
+
+// subscriptionPayload is the gzipped payload of a CloudWatch Logs subscription filter.
+type subscriptionPayload struct {
+	MessageType string `json:"messageType"`
+	Owner       string `json:"owner"`
+	LogGroup    string `json:"logGroup"`
+	LogEvents   []struct {
+		Timestamp int64  `json:"timestamp"`
+		Message   string `json:"message"`
+	} `json:"logEvents"`
+}
+
+// metadata is the _aws object of an EMF log event.
+type metadata struct {
+	Timestamp         int64 `json:"Timestamp"`
+	CloudWatchMetrics []struct {
+		Namespace  string     `json:"Namespace"`
+		Dimensions [][]string `json:"Dimensions"`
+		Metrics    []struct {
+			Name string `json:"Name"`
+		} `json:"Metrics"`
+	} `json:"CloudWatchMetrics"`
+}
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// ParseRecord parses the data of a stream record: either the gzipped payload
+// of a CloudWatch Logs subscription filter, or EMF log events, one per line.
+// Log events which are not in the EMF, e.g. plain Lambda logs, are skipped.
+func ParseRecord(data []byte) ([]Sample, error) {
+	if !bytes.HasPrefix(data, gzipMagic) {
+		return parseLines(data, "", "")
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress the record: %w", err)
+	}
+	decompressed, err := io.ReadAll(gz)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress the record: %w", err)
+	}
+
+	var payload subscriptionPayload
+	if err := json.Unmarshal(decompressed, &payload); err != nil {
+		return nil, fmt.Errorf("failed to parse the CloudWatch Logs subscription payload: %w", err)
+	}
+	if payload.MessageType != "DATA_MESSAGE" {
+		return nil, nil
+	}
+
+	var samples []Sample
+	for _, event := range payload.LogEvents {
+		parsed, err := parseEvent([]byte(event.Message), payload.LogGroup, payload.Owner)
+		if err != nil {
+			return samples, err
+		}
+		samples = append(samples, parsed...)
+	}
+	return samples, nil
+}
+
+func parseLines(data []byte, logGroup string, accountID string) ([]Sample, error) {
+	var samples []Sample
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		parsed, err := parseEvent(line, logGroup, accountID)
+		if err != nil {
+			return samples, err
+		}
+		samples = append(samples, parsed...)
+	}
+	return samples, scanner.Err()
+}
+
+// parseEvent parses an EMF log event, returning a sample per metric
+// and dimension set of its metric directives.
+func parseEvent(message []byte, logGroup string, accountID string) ([]Sample, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(message, &fields); err != nil {
+		// Not a JSON object, hence not in the EMF
+		return nil, nil
+	}
+	rawMetadata, ok := fields["_aws"]
+	if !ok {
+		return nil, nil
+	}
+	var meta metadata
+	if err := json.Unmarshal(rawMetadata, &meta); err != nil {
+		return nil, fmt.Errorf("invalid EMF metadata: %w", err)
+	}
+	timestamp := time.UnixMilli(meta.Timestamp)
+
+	var samples []Sample
+	for _, directive := range meta.CloudWatchMetrics {
+		dimensionSets := directive.Dimensions
+		if len(dimensionSets) == 0 {
+			dimensionSets = [][]string{{}}
+		}
+		for _, metric := range directive.Metrics {
+			values, err := metricValues(fields[metric.Name])
+			if err != nil {
+				return samples, fmt.Errorf("invalid value of EMF metric %s: %w", metric.Name, err)
+			}
+			if len(values) == 0 {
+				continue
+			}
+			for _, dimensionSet := range dimensionSets {
+				dimensions, err := dimensionValues(fields, dimensionSet)
+				if err != nil {
+					return samples, fmt.Errorf("invalid dimension of EMF metric %s: %w", metric.Name, err)
+				}
+				samples = append(samples, Sample{
+					LogGroup:   logGroup,
+					AccountID:  accountID,
+					Namespace:  directive.Namespace,
+					Metric:     metric.Name,
+					Dimensions: dimensions,
+					Values:     values,
+					Timestamp:  timestamp,
+				})
+			}
+		}
+	}
+	return samples, nil
+}
+
+// metricValues returns the values of a metric, which is either a number or an array of numbers.
+func metricValues(raw json.RawMessage) ([]float64, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	var value float64
+	if err := json.Unmarshal(raw, &value); err == nil {
+		return []float64{value}, nil
+	}
+	var values []float64
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func dimensionValues(fields map[string]json.RawMessage, names []string) ([]*model.Dimension, error) {
+	dimensions := make([]*model.Dimension, 0, len(names))
+	for _, name := range names {
+		var value string
+		if raw, ok := fields[name]; ok {
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return nil, fmt.Errorf("%s is not a string: %w", name, err)
+			}
+		}
+		dimensions = append(dimensions, &model.Dimension{Name: name, Value: value})
+	}
+	return dimensions, nil
+}
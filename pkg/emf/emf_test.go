@@ -0,0 +1,64 @@
+package emf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+const emfEvent = `{"_aws":{"Timestamp":1704067230000,"CloudWatchMetrics":[{"Namespace":"Checkout","Dimensions":[["Service"],["Service","Operation"]],"Metrics":[{"Name":"Latency","Unit":"Milliseconds"},{"Name":"Errors"}]}]},"Service":"payments","Operation":"Charge","Latency":[12,30],"Errors":1,"RequestId":"abc"}`
+
+func TestParseRecord(t *testing.T) {
+	samples, err := ParseRecord([]byte(emfEvent + "\nSTART RequestId: abc Version: $LATEST\n"))
+	require.NoError(t, err)
+
+	timestamp := time.UnixMilli(1704067230000)
+	service := &model.Dimension{Name: "Service", Value: "payments"}
+	operation := &model.Dimension{Name: "Operation", Value: "Charge"}
+	require.Equal(t, []Sample{
+		{Namespace: "Checkout", Metric: "Latency", Dimensions: []*model.Dimension{service}, Values: []float64{12, 30}, Timestamp: timestamp},
+		{Namespace: "Checkout", Metric: "Latency", Dimensions: []*model.Dimension{service, operation}, Values: []float64{12, 30}, Timestamp: timestamp},
+		{Namespace: "Checkout", Metric: "Errors", Dimensions: []*model.Dimension{service}, Values: []float64{1}, Timestamp: timestamp},
+		{Namespace: "Checkout", Metric: "Errors", Dimensions: []*model.Dimension{service, operation}, Values: []float64{1}, Timestamp: timestamp},
+	}, samples)
+}
+
+func TestParseRecordSubscription(t *testing.T) {
+	payload := `{"messageType":"DATA_MESSAGE","owner":"123456789012","logGroup":"/aws/lambda/checkout","logEvents":[` +
+		`{"id":"1","timestamp":1704067230000,"message":"REPORT RequestId: abc Duration: 12.00 ms"},` +
+		`{"id":"2","timestamp":1704067230000,"message":"{\"_aws\":{\"Timestamp\":1704067230000,\"CloudWatchMetrics\":[{\"Namespace\":\"Checkout\",\"Dimensions\":[],\"Metrics\":[{\"Name\":\"Orders\"}]}]},\"Orders\":3}"}` +
+		`]}`
+	buf := bytes.Buffer{}
+	gz := gzip.NewWriter(&buf)
+	_, err := gz.Write([]byte(payload))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	samples, err := ParseRecord(buf.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, []Sample{{
+		LogGroup:   "/aws/lambda/checkout",
+		AccountID:  "123456789012",
+		Namespace:  "Checkout",
+		Metric:     "Orders",
+		Dimensions: []*model.Dimension{},
+		Values:     []float64{3},
+		Timestamp:  time.UnixMilli(1704067230000),
+	}}, samples)
+}
+
+func TestParseRecordErrors(t *testing.T) {
+	_, err := ParseRecord([]byte(`{"_aws":{"Timestamp":1704067230000,"CloudWatchMetrics":[{"Namespace":"Checkout","Metrics":[{"Name":"Latency"}]}]},"Latency":"fast"}`))
+	require.ErrorContains(t, err, "invalid value of EMF metric Latency")
+
+	_, err = ParseRecord([]byte(`{"_aws":{"Timestamp":1704067230000,"CloudWatchMetrics":[{"Namespace":"Checkout","Dimensions":[["Service"]],"Metrics":[{"Name":"Latency"}]}]},"Service":1,"Latency":1}`))
+	require.ErrorContains(t, err, "invalid dimension of EMF metric Latency")
+
+	_, err = ParseRecord([]byte{0x1f, 0x8b, 0x00})
+	require.ErrorContains(t, err, "failed to decompress the record")
+}
@@ -0,0 +1,97 @@
+package emf
+
+import (
+	"compress/gzip"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/arn"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+)
+
+// maxFirehoseRequestSize is the maximum size of the body of the requests, compressed
+// or not. The buffer of the delivery streams to HTTP endpoints is at most 64 MiB.
+const maxFirehoseRequestSize = 64 << 20
+
+// firehoseRequest is the body of the requests of a Firehose
+// delivery stream to an HTTP endpoint destination.
+type firehoseRequest struct {
+	RequestID string `json:"requestId"`
+	Records   []struct {
+		Data []byte `json:"data"`
+	} `json:"records"`
+}
+
+type firehoseResponse struct {
+	RequestID    string `json:"requestId"`
+	Timestamp    int64  `json:"timestamp"`
+	ErrorMessage string `json:"errorMessage,omitempty"`
+}
+
+// NewFirehoseHandler returns the HTTP endpoint destination of Firehose delivery
+// streams, adding the records they deliver to the store. Requests must have the
+// access key configured on the delivery streams. The region of the records is
+// the region of the delivery stream.
+func NewFirehoseHandler(logger logging.Logger, accessKey string, store *Store) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get("X-Amz-Firehose-Request-Id")
+		if r.Method != http.MethodPost {
+			writeFirehoseResponse(w, http.StatusMethodNotAllowed, requestID, "method not allowed")
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("X-Amz-Firehose-Access-Key")), []byte(accessKey)) != 1 {
+			writeFirehoseResponse(w, http.StatusUnauthorized, requestID, "invalid access key")
+			return
+		}
+
+		body := io.ReadCloser(http.MaxBytesReader(w, r.Body, maxFirehoseRequestSize))
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			gz, err := gzip.NewReader(body)
+			if err != nil {
+				writeFirehoseResponse(w, http.StatusBadRequest, requestID, err.Error())
+				return
+			}
+			defer gz.Close()
+			body = http.MaxBytesReader(w, gz, maxFirehoseRequestSize)
+		}
+
+		var req firehoseRequest
+		if err := json.NewDecoder(body).Decode(&req); err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				writeFirehoseResponse(w, http.StatusRequestEntityTooLarge, requestID, err.Error())
+				return
+			}
+			writeFirehoseResponse(w, http.StatusBadRequest, requestID, "invalid request body: "+err.Error())
+			return
+		}
+
+		var region string
+		if source, err := arn.Parse(r.Header.Get("X-Amz-Firehose-Source-Arn")); err == nil {
+			region = source.Region
+		}
+		for _, record := range req.Records {
+			samples, err := ParseRecord(record.Data)
+			if err != nil {
+				logger.Warn("Skipping invalid EMF record", "request_id", req.RequestID, "err", err)
+			}
+			store.Add(region, samples)
+		}
+		writeFirehoseResponse(w, http.StatusOK, req.RequestID, "")
+	})
+}
+
+func writeFirehoseResponse(w http.ResponseWriter, status int, requestID string, errorMessage string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(firehoseResponse{
+		RequestID:    requestID,
+		Timestamp:    time.Now().UnixMilli(),
+		ErrorMessage: errorMessage,
+	})
+}
@@ -0,0 +1,73 @@
+package emf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+)
+
+func TestFirehoseHandler(t *testing.T) {
+	store := NewStore(time.Hour)
+	handler := NewFirehoseHandler(logging.NewNopLogger(), "secret", store)
+
+	record, err := json.Marshal(map[string]interface{}{
+		"requestId": "request-1",
+		"timestamp": 1704067230000,
+		"records":   []map[string][]byte{{"data": []byte(emfEvent)}},
+	})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/emf/firehose", strings.NewReader(string(record)))
+	req.Header.Set("X-Amz-Firehose-Request-Id", "request-1")
+	req.Header.Set("X-Amz-Firehose-Access-Key", "wrong")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+	require.Empty(t, store.Results())
+
+	req = httptest.NewRequest(http.MethodPost, "/emf/firehose", strings.NewReader(string(record)))
+	req.Header.Set("X-Amz-Firehose-Request-Id", "request-1")
+	req.Header.Set("X-Amz-Firehose-Access-Key", "secret")
+	req.Header.Set("X-Amz-Firehose-Source-Arn", "arn:aws:firehose:eu-west-1:123456789012:deliverystream/emf")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp firehoseResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Equal(t, "request-1", resp.RequestID)
+	require.Empty(t, resp.ErrorMessage)
+
+	results := store.Results()
+	require.Len(t, results, 1)
+	require.Equal(t, "eu-west-1", results[0].Context.Region)
+	require.Len(t, results[0].Data, 4)
+}
+
+func TestFirehoseHandlerRequestTooLarge(t *testing.T) {
+	handler := NewFirehoseHandler(logging.NewNopLogger(), "secret", NewStore(time.Hour))
+
+	// The limit applies to the decompressed body too
+	var body bytes.Buffer
+	gz := gzip.NewWriter(&body)
+	_, err := gz.Write(bytes.Repeat([]byte(" "), maxFirehoseRequestSize+1))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/emf/firehose", &body)
+	req.Header.Set("X-Amz-Firehose-Request-Id", "request-1")
+	req.Header.Set("X-Amz-Firehose-Access-Key", "secret")
+	req.Header.Set("Content-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
@@ -0,0 +1,201 @@
+package emf
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/kinesis"
+	"github.com/aws/aws-sdk-go/service/kinesis/kinesisiface"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+)
+
+const (
+	// getRecordsInterval keeps the consumer below the limit of 5 GetRecords calls per second and shard.
+	getRecordsInterval = time.Second
+	listShardsInterval = time.Minute
+	errorBackoff       = 5 * time.Second
+)
+
+// KinesisConsumer reads the records of all the shards of a Kinesis data stream
+// into a Store. It does not checkpoint: records are read from the latest ones
+// on startup, the metrics are exported for their latest minute only.
+type KinesisConsumer struct {
+	logger     logging.Logger
+	kinesisAPI kinesisiface.KinesisAPI
+	store      *Store
+	region     string
+	streamName string
+}
+
+// NewKinesisConsumer returns a consumer of the stream, e.g.
+// arn:aws:kinesis:eu-west-1:123456789012:stream/emf-logs.
+func NewKinesisConsumer(logger logging.Logger, streamARN string, fips bool, store *Store) (*KinesisConsumer, error) {
+	parsed, err := arn.Parse(streamARN)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Kinesis stream ARN %q: %w", streamARN, err)
+	}
+	streamName, ok := strings.CutPrefix(parsed.Resource, "stream/")
+	if parsed.Service != "kinesis" || !ok {
+		return nil, fmt.Errorf("invalid Kinesis stream ARN %q: not a Kinesis data stream", streamARN)
+	}
+
+	cfg := aws.Config{Region: aws.String(parsed.Region)}
+	if fips {
+		cfg.UseFIPSEndpoint = endpoints.FIPSEndpointStateEnabled
+	}
+	sess, err := session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+		Config:            cfg,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the Kinesis session: %w", err)
+	}
+
+	return &KinesisConsumer{
+		logger:     logger.With("stream", streamName),
+		kinesisAPI: kinesis.New(sess),
+		store:      store,
+		region:     parsed.Region,
+		streamName: streamName,
+	}, nil
+}
+
+// Run consumes the stream until the context is done. The shards are listed
+// periodically to consume the shards created when the stream is resharded.
+func (c *KinesisConsumer) Run(ctx context.Context) {
+	consumed := map[string]bool{}
+	iteratorType := kinesis.ShardIteratorTypeLatest
+	for {
+		shards, err := c.listShards(ctx)
+		if err != nil {
+			c.logger.Error(err, "Failed to list the shards of the EMF stream")
+		}
+		for _, shard := range shards {
+			if consumed[shard] {
+				continue
+			}
+			consumed[shard] = true
+			go c.consumeShard(ctx, shard, iteratorType)
+		}
+		if err == nil {
+			// Shards created after the startup are consumed from their first record
+			iteratorType = kinesis.ShardIteratorTypeTrimHorizon
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(listShardsInterval):
+		}
+	}
+}
+
+func (c *KinesisConsumer) listShards(ctx context.Context) ([]string, error) {
+	var shards []string
+	input := &kinesis.ListShardsInput{StreamName: aws.String(c.streamName)}
+	for {
+		out, err := c.kinesisAPI.ListShardsWithContext(ctx, input)
+		if err != nil {
+			return shards, err
+		}
+		for _, shard := range out.Shards {
+			// Closed shards have an ending sequence number, they are consumed until then
+			shards = append(shards, aws.StringValue(shard.ShardId))
+		}
+		if out.NextToken == nil {
+			return shards, nil
+		}
+		input = &kinesis.ListShardsInput{NextToken: out.NextToken}
+	}
+}
+
+func (c *KinesisConsumer) consumeShard(ctx context.Context, shard string, iteratorType string) {
+	logger := c.logger.With("shard", shard)
+	iterator, ok := c.waitShardIterator(ctx, logger, shard, iteratorType, nil)
+	if !ok {
+		return
+	}
+
+	// The sequence number of the last record read, to resume after it with
+	// a new iterator when the current one fails, e.g. once expired
+	var lastSequenceNumber *string
+	for iterator != nil {
+		out, err := c.kinesisAPI.GetRecordsWithContext(ctx, &kinesis.GetRecordsInput{ShardIterator: iterator})
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			logger.Error(err, "Failed to get the records of the EMF stream shard")
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(errorBackoff):
+			}
+			if lastSequenceNumber != nil {
+				iterator, ok = c.waitShardIterator(ctx, logger, shard, kinesis.ShardIteratorTypeAfterSequenceNumber, lastSequenceNumber)
+			} else {
+				iterator, ok = c.waitShardIterator(ctx, logger, shard, iteratorType, nil)
+			}
+			if !ok {
+				return
+			}
+			continue
+		}
+
+		for _, record := range out.Records {
+			samples, err := ParseRecord(record.Data)
+			if err != nil {
+				logger.Warn("Skipping invalid EMF record", "sequence_number", aws.StringValue(record.SequenceNumber), "err", err)
+			}
+			c.store.Add(c.region, samples)
+			lastSequenceNumber = record.SequenceNumber
+		}
+		// The iterator is nil once a closed shard is fully consumed
+		iterator = out.NextShardIterator
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(getRecordsInterval):
+		}
+	}
+	logger.Debug("EMF stream shard closed")
+}
+
+// waitShardIterator gets an iterator of the shard, retrying until the context is done.
+// It returns false if the context is done first.
+func (c *KinesisConsumer) waitShardIterator(ctx context.Context, logger logging.Logger, shard string, iteratorType string, sequenceNumber *string) (*string, bool) {
+	iterator, err := c.shardIterator(ctx, shard, iteratorType, sequenceNumber)
+	for err != nil {
+		logger.Error(err, "Failed to get the iterator of the EMF stream shard")
+		select {
+		case <-ctx.Done():
+			return nil, false
+		case <-time.After(errorBackoff):
+		}
+		iterator, err = c.shardIterator(ctx, shard, iteratorType, sequenceNumber)
+	}
+	return iterator, true
+}
+
+// shardIterator returns an iterator of the shard. The sequence number is
+// only used by the AT_SEQUENCE_NUMBER and AFTER_SEQUENCE_NUMBER types.
+func (c *KinesisConsumer) shardIterator(ctx context.Context, shard string, iteratorType string, sequenceNumber *string) (*string, error) {
+	out, err := c.kinesisAPI.GetShardIteratorWithContext(ctx, &kinesis.GetShardIteratorInput{
+		StreamName:             aws.String(c.streamName),
+		ShardId:                aws.String(shard),
+		ShardIteratorType:      aws.String(iteratorType),
+		StartingSequenceNumber: sequenceNumber,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.ShardIterator, nil
+}
@@ -0,0 +1,184 @@
+package emf
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/promutil"
+)
+
+// DefaultSeriesTTL is how long a metric is exported after its last EMF log event.
+const DefaultSeriesTTL = 5 * time.Minute
+
+// period is the resolution the values are aggregated at, the
+// standard resolution of the metrics CloudWatch extracts from EMF logs.
+const period = time.Minute
+
+// statistics are the statistics exported for each metric.
+var statistics = []string{"Sum", "SampleCount", "Minimum", "Maximum", "Average"}
+
+// Store aggregates the samples of the EMF log events into the statistics
+// of their latest minute. It is safe for concurrent use.
+type Store struct {
+	mu     sync.Mutex
+	ttl    time.Duration
+	series map[string]*series
+}
+
+type series struct {
+	region     string
+	accountID  string
+	logGroup   string
+	namespace  string
+	metric     string
+	dimensions []*model.Dimension
+
+	start     time.Time
+	sum       float64
+	count     float64
+	minimum   float64
+	maximum   float64
+	updatedAt time.Time
+}
+
+func NewStore(ttl time.Duration) *Store {
+	return &Store{
+		ttl:    ttl,
+		series: map[string]*series{},
+	}
+}
+
+// Add adds the samples of log events received in the region. Samples older
+// than the minute already aggregated for their metric are dropped.
+func (s *Store) Add(region string, samples []Sample) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for _, sample := range samples {
+		key := seriesKey(region, sample)
+		start := sample.Timestamp.Truncate(period)
+		ser, ok := s.series[key]
+		if !ok {
+			ser = &series{
+				region:     region,
+				accountID:  sample.AccountID,
+				logGroup:   sample.LogGroup,
+				namespace:  sample.Namespace,
+				metric:     sample.Metric,
+				dimensions: sample.Dimensions,
+			}
+			s.series[key] = ser
+		}
+		if start.Before(ser.start) {
+			continue
+		}
+		if start.After(ser.start) {
+			ser.start = start
+			ser.sum, ser.count = 0, 0
+			ser.minimum, ser.maximum = math.Inf(1), math.Inf(-1)
+		}
+		for _, value := range sample.Values {
+			ser.sum += value
+			ser.count++
+			ser.minimum = math.Min(ser.minimum, value)
+			ser.maximum = math.Max(ser.maximum, value)
+		}
+		ser.updatedAt = now
+	}
+}
+
+func seriesKey(region string, sample Sample) string {
+	sb := strings.Builder{}
+	for _, part := range []string{region, sample.AccountID, sample.LogGroup, sample.Namespace, sample.Metric} {
+		sb.WriteString(part)
+		sb.WriteByte('|')
+	}
+	for _, dimension := range sample.Dimensions {
+		sb.WriteString(dimension.Name)
+		sb.WriteByte('=')
+		sb.WriteString(dimension.Value)
+		sb.WriteByte('|')
+	}
+	return sb.String()
+}
+
+// Results returns the aggregated metrics, one result per region and account,
+// and removes the metrics which received no log event for the TTL of the store.
+func (s *Store) Results() []model.CloudwatchMetricResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	type contextKey struct{ region, accountID string }
+
+	now := time.Now()
+	byContext := map[contextKey][]*model.CloudwatchData{}
+	for key, ser := range s.series {
+		if now.Sub(ser.updatedAt) > s.ttl {
+			delete(s.series, key)
+			continue
+		}
+		if ser.count == 0 {
+			continue
+		}
+		ctx := contextKey{region: ser.region, accountID: ser.accountID}
+		byContext[ctx] = append(byContext[ctx], ser.data())
+	}
+
+	results := make([]model.CloudwatchMetricResult, 0, len(byContext))
+	for ctx, data := range byContext {
+		results = append(results, model.CloudwatchMetricResult{
+			Context: &model.ScrapeContext{Region: ctx.region, AccountID: ctx.accountID},
+			Data:    data,
+		})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Context.Region != results[j].Context.Region {
+			return results[i].Context.Region < results[j].Context.Region
+		}
+		return results[i].Context.AccountID < results[j].Context.AccountID
+	})
+	return results
+}
+
+func (ser *series) data() *model.CloudwatchData {
+	id := ser.logGroup
+	if id == "" {
+		id = "global"
+	}
+	timestamp := ser.start
+	return &model.CloudwatchData{
+		ID:         aws.String(id),
+		Metric:     aws.String(ser.metric),
+		Namespace:  aws.String(ser.namespace),
+		Statistics: statistics,
+		Points: []*model.Datapoint{{
+			Sum:         aws.Float64(ser.sum),
+			SampleCount: aws.Float64(ser.count),
+			Minimum:     aws.Float64(ser.minimum),
+			Maximum:     aws.Float64(ser.maximum),
+			Average:     aws.Float64(ser.sum / ser.count),
+			Timestamp:   &timestamp,
+		}},
+		NilToZero:  aws.Bool(false),
+		Dimensions: ser.dimensions,
+		Period:     int64(period.Seconds()),
+	}
+}
+
+// Metrics builds the Prometheus metrics of the aggregated
+// metrics, named as if they were polled from CloudWatch.
+func (s *Store) Metrics(labelsSnakeCase bool, logger logging.Logger) ([]*promutil.PrometheusMetric, error) {
+	metrics, observedMetricLabels, err := promutil.BuildMetrics(s.Results(), labelsSnakeCase, logger)
+	if err != nil {
+		return nil, err
+	}
+	return promutil.EnsureLabelConsistencyAndRemoveDuplicates(metrics, observedMetricLabels), nil
+}
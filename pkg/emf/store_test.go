@@ -0,0 +1,60 @@
+package emf
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+func TestStoreMetrics(t *testing.T) {
+	store := NewStore(time.Hour)
+	minute := time.Unix(1704067200, 0)
+	sample := func(at time.Time, values ...float64) Sample {
+		return Sample{
+			LogGroup:   "/aws/lambda/checkout",
+			AccountID:  "123456789012",
+			Namespace:  "Checkout",
+			Metric:     "Latency",
+			Dimensions: []*model.Dimension{{Name: "Service", Value: "payments"}},
+			Values:     values,
+			Timestamp:  at,
+		}
+	}
+
+	store.Add("eu-west-1", []Sample{sample(minute.Add(-time.Second), 1000)})
+	store.Add("eu-west-1", []Sample{sample(minute.Add(10*time.Second), 10, 30), sample(minute.Add(50*time.Second), 20)})
+	// Samples of a previous minute are dropped once the next minute is aggregated
+	store.Add("eu-west-1", []Sample{sample(minute.Add(-time.Second), 1000)})
+
+	metrics, err := store.Metrics(false, logging.NewNopLogger())
+	require.NoError(t, err)
+
+	values := map[string]float64{}
+	for _, metric := range metrics {
+		require.Equal(t, map[string]string{
+			"name":              "/aws/lambda/checkout",
+			"dimension_Service": "payments",
+			"region":            "eu-west-1",
+			"account_id":        "123456789012",
+		}, metric.Labels)
+		values[*metric.Name] = *metric.Value
+	}
+	require.Equal(t, map[string]float64{
+		"aws_checkout_latency_sum":          60,
+		"aws_checkout_latency_sample_count": 3,
+		"aws_checkout_latency_minimum":      10,
+		"aws_checkout_latency_maximum":      30,
+		"aws_checkout_latency_average":      20,
+	}, values)
+}
+
+func TestStoreExpiresSeries(t *testing.T) {
+	store := NewStore(-time.Second)
+	store.Add("eu-west-1", []Sample{{Namespace: "Checkout", Metric: "Orders", Values: []float64{1}, Timestamp: time.Now()}})
+	require.Empty(t, store.Results())
+	require.Empty(t, store.series)
+}
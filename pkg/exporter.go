@@ -29,7 +29,31 @@ var Metrics = []prometheus.Collector{
 	promutil.Ec2APICounter,
 	promutil.DmsAPICounter,
 	promutil.StoragegatewayAPICounter,
+	promutil.RdsAPICounter,
+	promutil.EcsAPICounter,
+	promutil.ElastiCacheAPICounter,
+	promutil.SqsAPICounter,
+	promutil.DynamoDBAPICounter,
+	promutil.CostExplorerAPICounter,
+	promutil.ServiceQuotasAPICounter,
+	promutil.SupportAPICounter,
+	promutil.LogsAPICounter,
+	promutil.CloudwatchGetInsightRuleReportAPICounter,
+	promutil.SyntheticsAPICounter,
+	promutil.S3APICounter,
+	promutil.KafkaAPICounter,
+	promutil.RedshiftServerlessAPICounter,
 	promutil.DuplicateMetricsFilteredCounter,
+	promutil.StaleResourcesAgeGauge,
+	promutil.APIRetriesCounter,
+	promutil.BudgetDelayedCounter,
+	promutil.BudgetErrorsCounter,
+	promutil.ResourcesAddedCounter,
+	promutil.ResourcesRemovedCounter,
+	promutil.ResourcesDiscoveredGauge,
+	promutil.AccountUpGauge,
+	promutil.AccountLastSuccessGauge,
+	promutil.AccountLastErrorGauge,
 }
 
 const (
@@ -58,6 +82,7 @@ type options struct {
 	taggingAPIConcurrency int
 	featureFlags          featureFlagsMap
 	cloudwatchConcurrency cloudwatch.ConcurrencyConfig
+	hooks                 hookList
 }
 
 // IsFeatureEnabled implements the FeatureFlags interface, allowing us to inject the options-configure feature flags in the rest of the code.
@@ -117,6 +142,24 @@ func CloudWatchPerAPILimitConcurrency(listMetrics, getMetricData, getMetricStati
 	}
 }
 
+// CloudWatchSharedLimiter limits the concurrent requests to the CloudWatch API with a limiter
+// shared with other scrapes, in addition to the limit of each client.
+func CloudWatchSharedLimiter(limiter cloudwatch.ConcurrencyLimiter) OptionsFunc {
+	return func(o *options) error {
+		o.cloudwatchConcurrency.Shared = limiter
+		return nil
+	}
+}
+
+// CloudWatchRequestBudget limits the rate of the requests to the CloudWatch API of each
+// account and region with a budget shared with other exporters.
+func CloudWatchRequestBudget(budget cloudwatch.RequestBudget) OptionsFunc {
+	return func(o *options) error {
+		o.cloudwatchConcurrency.Budget = budget
+		return nil
+	}
+}
+
 func TaggingAPIConcurrency(maxConcurrency int) OptionsFunc {
 	return func(o *options) error {
 		if maxConcurrency <= 0 {
@@ -170,17 +213,39 @@ func UpdateMetrics(
 	factory clients.Factory,
 	optFuncs ...OptionsFunc,
 ) error {
+	collector, err := CollectMetrics(ctx, logger, jobsCfg, factory, optFuncs...)
+	if err != nil {
+		return err
+	}
+	registry.MustRegister(collector)
+	return nil
+}
+
+// CollectMetrics scrapes metrics from AWS on demand, as UpdateMetrics, but returns
+// them as a prometheus.Collector instead of registering them to a registry.
+func CollectMetrics(
+	ctx context.Context,
+	logger logging.Logger,
+	jobsCfg model.JobsConfig,
+	factory clients.Factory,
+	optFuncs ...OptionsFunc,
+) (*promutil.PrometheusCollector, error) {
 	options := defaultOptions()
 	for _, f := range optFuncs {
 		if err := f(&options); err != nil {
-			return err
+			return nil, err
 		}
 	}
 
 	// add feature flags to context passed down to all other layers
 	ctx = config.CtxWithFlags(ctx, options.featureFlags)
 
-	tagsData, cloudwatchData := job.ScrapeAwsData(
+	hooks := append(configuredHooks(logger, jobsCfg.Hooks), options.hooks...)
+	if hooks.hasScrapeErrorHooks() {
+		logger = errorHookLogger{Logger: logger, ctx: ctx, hooks: hooks}
+	}
+
+	tagsData, cloudwatchData, unmatchedData, jobOutcomes := job.ScrapeAwsData(
 		ctx,
 		logger,
 		jobsCfg,
@@ -189,15 +254,69 @@ func UpdateMetrics(
 		options.cloudwatchConcurrency,
 		options.taggingAPIConcurrency,
 	)
+	hooks.onDiscoveryComplete(ctx, tagsData)
+	hooks.onUnmatched(ctx, unmatchedData)
+	hooks.onJobOutcomes(ctx, jobOutcomes)
 
 	metrics, observedMetricLabels, err := promutil.BuildMetrics(cloudwatchData, options.labelsSnakeCase, logger)
 	if err != nil {
 		logger.Error(err, "Error migrating cloudwatch metrics to prometheus metrics")
-		return nil
+		return promutil.NewPrometheusCollector(nil), nil
 	}
 	metrics, observedMetricLabels = promutil.BuildNamespaceInfoMetrics(tagsData, metrics, observedMetricLabels, options.labelsSnakeCase, logger)
+
+	if len(jobsCfg.InventoryJobs) > 0 {
+		inventoryData := job.ScrapeInventory(ctx, logger, jobsCfg, factory, options.taggingAPIConcurrency)
+		metrics, observedMetricLabels = promutil.BuildInventoryMetrics(inventoryData, metrics, observedMetricLabels, options.labelsSnakeCase, logger)
+	}
+	if len(jobsCfg.ServiceQuotaJobs) > 0 {
+		quotaData := job.ScrapeServiceQuotas(ctx, logger, jobsCfg, factory, options.cloudwatchConcurrency)
+		metrics, observedMetricLabels = promutil.BuildServiceQuotaMetrics(quotaData, metrics, observedMetricLabels, options.labelsSnakeCase, logger)
+	}
+	if len(jobsCfg.TrustedAdvisorJobs) > 0 {
+		checkData := job.ScrapeTrustedAdvisor(ctx, logger, jobsCfg, factory)
+		metrics, observedMetricLabels = promutil.BuildTrustedAdvisorMetrics(checkData, metrics, observedMetricLabels, options.labelsSnakeCase, logger)
+	}
+	if len(jobsCfg.LogsInsightsJobs) > 0 {
+		queryData := job.ScrapeLogsInsights(ctx, logger, jobsCfg, factory)
+		metrics, observedMetricLabels = promutil.BuildLogsInsightsMetrics(queryData, metrics, observedMetricLabels, options.labelsSnakeCase, logger)
+	}
+	if len(jobsCfg.ContributorInsightsJobs) > 0 {
+		reportData := job.ScrapeContributorInsights(ctx, logger, jobsCfg, factory)
+		metrics, observedMetricLabels = promutil.BuildContributorInsightsMetrics(reportData, metrics, observedMetricLabels, options.labelsSnakeCase, logger)
+	}
 	metrics = promutil.EnsureLabelConsistencyAndRemoveDuplicates(metrics, observedMetricLabels)
+	metrics = hooks.onMetricsBuilt(ctx, metrics)
 
-	registry.MustRegister(promutil.NewPrometheusCollector(metrics))
-	return nil
+	return promutil.NewPrometheusCollector(metrics), nil
+}
+
+// PlanScrape discovers the resources and lists the metrics of the discovery, static and custom
+// namespace jobs, as CollectMetrics does, and returns the CloudWatch API usage of a scrape
+// per job, region and account, without requesting any datapoint.
+func PlanScrape(
+	ctx context.Context,
+	logger logging.Logger,
+	jobsCfg model.JobsConfig,
+	factory clients.Factory,
+	optFuncs ...OptionsFunc,
+) ([]model.JobPlan, error) {
+	options := defaultOptions()
+	for _, f := range optFuncs {
+		if err := f(&options); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx = config.CtxWithFlags(ctx, options.featureFlags)
+
+	return job.PlanAwsData(
+		ctx,
+		logger,
+		jobsCfg,
+		factory,
+		options.metricsPerQuery,
+		options.cloudwatchConcurrency,
+		options.taggingAPIConcurrency,
+	), nil
 }
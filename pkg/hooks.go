@@ -0,0 +1,153 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/hooks"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/promutil"
+)
+
+// Hooks are called at the points of the scrape lifecycle, for custom filtering,
+// auditing or side-channel exports. Any of the funcs may be nil.
+type Hooks struct {
+	// OnDiscoveryComplete is called with the resources found by the discovery jobs.
+	OnDiscoveryComplete func(ctx context.Context, resources []model.TaggedResourceResult)
+
+	// OnUnmatched is called with the resources of the discovery jobs which no metric was
+	// associated with, and the metrics which no resource was associated with.
+	OnUnmatched func(ctx context.Context, unmatched []model.UnmatchedResult)
+
+	// OnJobOutcomes is called with the outcome of the discovery, static and custom namespace
+	// jobs in each region and with each role.
+	OnJobOutcomes func(ctx context.Context, outcomes []model.JobOutcome)
+
+	// OnMetricsBuilt is called with the metrics of the scrape before they are exported,
+	// and returns the metrics to export, allowing to filter or modify them.
+	OnMetricsBuilt func(ctx context.Context, metrics []*promutil.PrometheusMetric) []*promutil.PrometheusMetric
+
+	// OnScrapeError is called for every error logged by the scrape jobs.
+	OnScrapeError func(ctx context.Context, err error)
+}
+
+// WithHooks subscribes hooks to the scrape lifecycle. It can be used multiple times,
+// the hooks are called in order, after the ones of the hooks configuration.
+func WithHooks(hooks Hooks) OptionsFunc {
+	return func(o *options) error {
+		o.hooks = append(o.hooks, hooks)
+		return nil
+	}
+}
+
+type hookList []Hooks
+
+// configuredHooks sends the events of the scrape to the hooks configuration.
+// Events are sent asynchronously, not to delay the scrape.
+func configuredHooks(logger logging.Logger, configs []model.HookConfig) hookList {
+	list := make(hookList, 0, len(configs))
+	for _, cfg := range configs {
+		notifier := hooks.NewNotifier(logger, cfg)
+		h := Hooks{}
+		if notifier.Subscribed(model.HookEventDiscoveryComplete) {
+			h.OnDiscoveryComplete = func(ctx context.Context, resources []model.TaggedResourceResult) {
+				count := 0
+				for _, r := range resources {
+					count += len(r.Data)
+				}
+				go notifier.Notify(context.WithoutCancel(ctx), hooks.Event{Event: model.HookEventDiscoveryComplete, Timestamp: time.Now(), Resources: count})
+			}
+		}
+		if notifier.Subscribed(model.HookEventMetricsBuilt) {
+			h.OnMetricsBuilt = func(ctx context.Context, metrics []*promutil.PrometheusMetric) []*promutil.PrometheusMetric {
+				go notifier.Notify(context.WithoutCancel(ctx), hooks.Event{Event: model.HookEventMetricsBuilt, Timestamp: time.Now(), Metrics: len(metrics)})
+				return metrics
+			}
+		}
+		if notifier.Subscribed(model.HookEventScrapeError) {
+			h.OnScrapeError = func(ctx context.Context, err error) {
+				go notifier.Notify(context.WithoutCancel(ctx), hooks.Event{Event: model.HookEventScrapeError, Timestamp: time.Now(), Error: err.Error()})
+			}
+		}
+		list = append(list, h)
+	}
+	return list
+}
+
+func (l hookList) onDiscoveryComplete(ctx context.Context, resources []model.TaggedResourceResult) {
+	for _, h := range l {
+		if h.OnDiscoveryComplete != nil {
+			h.OnDiscoveryComplete(ctx, resources)
+		}
+	}
+}
+
+func (l hookList) onUnmatched(ctx context.Context, unmatched []model.UnmatchedResult) {
+	for _, h := range l {
+		if h.OnUnmatched != nil {
+			h.OnUnmatched(ctx, unmatched)
+		}
+	}
+}
+
+func (l hookList) onJobOutcomes(ctx context.Context, outcomes []model.JobOutcome) {
+	for _, h := range l {
+		if h.OnJobOutcomes != nil {
+			h.OnJobOutcomes(ctx, outcomes)
+		}
+	}
+}
+
+func (l hookList) onMetricsBuilt(ctx context.Context, metrics []*promutil.PrometheusMetric) []*promutil.PrometheusMetric {
+	for _, h := range l {
+		if h.OnMetricsBuilt != nil {
+			metrics = h.OnMetricsBuilt(ctx, metrics)
+		}
+	}
+	return metrics
+}
+
+func (l hookList) onScrapeError(ctx context.Context, err error) {
+	for _, h := range l {
+		if h.OnScrapeError != nil {
+			h.OnScrapeError(ctx, err)
+		}
+	}
+}
+
+func (l hookList) hasScrapeErrorHooks() bool {
+	for _, h := range l {
+		if h.OnScrapeError != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// errorHookLogger calls the OnScrapeError hooks for the errors it logs.
+type errorHookLogger struct {
+	logging.Logger
+	ctx   context.Context
+	hooks hookList
+}
+
+func (l errorHookLogger) Error(err error, message string, keyvals ...interface{}) {
+	l.Logger.Error(err, message, keyvals...)
+	if err == nil {
+		err = errors.New(message)
+	} else {
+		err = fmt.Errorf("%s: %w", message, err)
+	}
+	l.hooks.onScrapeError(l.ctx, err)
+}
+
+func (l errorHookLogger) With(keyvals ...interface{}) logging.Logger {
+	return errorHookLogger{
+		Logger: l.Logger.With(keyvals...),
+		ctx:    l.ctx,
+		hooks:  l.hooks,
+	}
+}
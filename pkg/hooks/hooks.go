@@ -0,0 +1,103 @@
+// Package hooks sends the events of the scrape lifecycle to the
+// webhooks and commands of the hooks configuration.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"slices"
+	"time"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+// Event is the JSON payload sent to the hooks.
+type Event struct {
+	Event     string    `json:"event"`
+	Timestamp time.Time `json:"timestamp"`
+
+	// Resources is the number of discovered resources, for discoveryComplete events.
+	Resources int `json:"resources,omitempty"`
+
+	// Metrics is the number of built metrics, for metricsBuilt events.
+	Metrics int `json:"metrics,omitempty"`
+
+	// Error is the message of the error, for scrapeError events.
+	Error string `json:"error,omitempty"`
+}
+
+// Notifier sends the events it is subscribed to, to the webhook or the command of a hook.
+type Notifier struct {
+	logger logging.Logger
+	hook   model.HookConfig
+	client *http.Client
+}
+
+func NewNotifier(logger logging.Logger, hook model.HookConfig) *Notifier {
+	return &Notifier{
+		logger: logger,
+		hook:   hook,
+		client: &http.Client{Timeout: hook.Timeout},
+	}
+}
+
+// Subscribed returns true if the hook is subscribed to the event.
+func (n *Notifier) Subscribed(event string) bool {
+	return slices.Contains(n.hook.Events, event)
+}
+
+// Notify sends the event, if the hook is subscribed to it. Failures are logged,
+// they do not fail the scrape. It does not return before the event is sent.
+func (n *Notifier) Notify(ctx context.Context, event Event) {
+	if !n.Subscribed(event.Event) {
+		return
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		n.logger.Error(err, "Failed to marshal hook event", "event", event.Event)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, n.hook.Timeout)
+	defer cancel()
+
+	if n.hook.Webhook != "" {
+		err = n.post(ctx, payload)
+	} else {
+		err = n.exec(ctx, payload)
+	}
+	if err != nil {
+		n.logger.Error(err, "Failed to send hook event", "event", event.Event)
+	}
+}
+
+func (n *Notifier) post(ctx context.Context, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.hook.Webhook, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s returned status %d", n.hook.Webhook, resp.StatusCode)
+	}
+	return nil
+}
+
+func (n *Notifier) exec(ctx context.Context, payload []byte) error {
+	cmd := exec.CommandContext(ctx, n.hook.Exec[0], n.hook.Exec[1:]...)
+	cmd.Stdin = bytes.NewReader(payload)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("command %s failed: %w: %s", n.hook.Exec[0], err, out)
+	}
+	return nil
+}
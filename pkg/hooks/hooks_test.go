@@ -0,0 +1,38 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+func TestNotifierWebhook(t *testing.T) {
+	var received []Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event Event
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		received = append(received, event)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	notifier := NewNotifier(logging.NewNopLogger(), model.HookConfig{
+		Events:  []string{model.HookEventScrapeError},
+		Webhook: server.URL,
+		Timeout: time.Second,
+	})
+
+	now := time.Now().UTC().Truncate(time.Second)
+	notifier.Notify(context.Background(), Event{Event: model.HookEventMetricsBuilt, Timestamp: now, Metrics: 3})
+	notifier.Notify(context.Background(), Event{Event: model.HookEventScrapeError, Timestamp: now, Error: "GetMetricData error"})
+
+	require.Equal(t, []Event{{Event: model.HookEventScrapeError, Timestamp: now, Error: "GetMetricData error"}}, received)
+}
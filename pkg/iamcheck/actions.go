@@ -0,0 +1,186 @@
+// Package iamcheck checks the IAM permissions of the roles of the jobs with
+// iam:SimulatePrincipalPolicy, reporting the permissions the jobs need but are
+// missing, the ones granted but not needed, and a least-privilege policy.
+package iamcheck
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+// getAccountActions are needed by every job, for the account label.
+var getAccountActions = []string{"sts:GetCallerIdentity"}
+
+// discoveryResourceActions are the actions discovering the resources of the namespaces
+// besides the Resource Groups Tagging API.
+var discoveryResourceActions = map[string][]string{
+	"AWS/ApiGateway":     {"apigateway:GET"},
+	"AWS/AutoScaling":    {"autoscaling:DescribeAutoScalingGroups"},
+	"AWS/DDoSProtection": {"shield:ListProtections"},
+	"AWS/DMS":            {"dms:DescribeReplicationInstances", "dms:DescribeReplicationTasks"},
+	"AWS/EC2Spot":        {"ec2:DescribeSpotFleetRequests"},
+	"AWS/Prometheus":     {"aps:ListWorkspaces"},
+	"AWS/StorageGateway": {"storagegateway:ListGateways", "storagegateway:ListTagsForResource"},
+	"AWS/TransitGateway": {"ec2:DescribeTransitGatewayAttachments"},
+}
+
+// resourceAttributesActions are the actions of the namespaces for addResourceAttributes.
+var resourceAttributesActions = map[string][]string{
+	"AWS/EBS":               {"ec2:DescribeVolumes"},
+	"AWS/EC2":               {"ec2:DescribeInstances"},
+	"AWS/ECS":               {"ecs:DescribeServices"},
+	"AWS/RDS":               {"rds:DescribeDBClusters", "rds:DescribeDBInstances"},
+	"ECS/ContainerInsights": {"ecs:DescribeServices"},
+}
+
+// infoMetricAttributesActions are the actions of the namespaces for infoMetricAttributes.
+var infoMetricAttributesActions = map[string][]string{
+	"AWS/DynamoDB":         {"dynamodb:DescribeTable"},
+	"AWS/ElastiCache":      {"elasticache:DescribeCacheClusters"},
+	"AWS/Kafka":            {"kafka:ListClusters"},
+	"AWS/Logs":             {"logs:DescribeLogGroups"},
+	"AWS/S3":               {"s3:GetMetricsConfiguration"},
+	"AWS/SQS":              {"sqs:GetQueueUrl", "sqs:GetQueueAttributes"},
+	"CloudWatchSynthetics": {"synthetics:DescribeCanaries"},
+}
+
+var (
+	listMetricsActions         = []string{"cloudwatch:ListMetrics"}
+	getMetricDataActions       = []string{"cloudwatch:ListMetrics", "cloudwatch:GetMetricData"}
+	getMetricStatisticsActions = []string{"cloudwatch:GetMetricStatistics"}
+	taggingActions             = []string{"tag:GetResources"}
+	costExplorerActions        = []string{"ce:GetCostAndUsage"}
+	serviceQuotasActions       = []string{"servicequotas:ListServiceQuotas", "servicequotas:ListAWSDefaultServiceQuotas", "cloudwatch:GetMetricStatistics"}
+	trustedAdvisorActions      = []string{"support:DescribeTrustedAdvisorChecks", "support:DescribeTrustedAdvisorCheckSummaries"}
+	logsInsightsActions        = []string{"logs:StartQuery", "logs:GetQueryResults", "logs:StopQuery"}
+	contributorInsightsActions = []string{"cloudwatch:GetInsightRuleReport"}
+	organizationActions        = []string{"organizations:ListAccounts", "organizations:ListParents", "organizations:ListTagsForResource"}
+)
+
+// Requirement is the set of actions the jobs need a role to be allowed.
+type Requirement struct {
+	// Role is the role of the jobs, the zero Role for the current credentials
+	Role    model.Role
+	Actions []string
+}
+
+// Requirements returns the actions needed by the jobs per role, sorted by role ARN. The
+// Organizations actions are needed by the management role of the organization block.
+func Requirements(jobsCfg model.JobsConfig) []Requirement {
+	actions := map[model.Role]map[string]struct{}{}
+	add := func(roles []model.Role, actionLists ...[]string) {
+		actionLists = append(actionLists, getAccountActions)
+		for _, role := range roles {
+			if actions[role] == nil {
+				actions[role] = map[string]struct{}{}
+			}
+			for _, list := range actionLists {
+				for _, action := range list {
+					actions[role][action] = struct{}{}
+				}
+			}
+		}
+	}
+
+	for _, job := range jobsCfg.DiscoveryJobs {
+		jobActions := [][]string{taggingActions, getMetricDataActions}
+		if svc := config.SupportedServices.GetService(job.Type); svc != nil {
+			jobActions = append(jobActions, discoveryResourceActions[svc.Namespace])
+			if job.AddResourceAttributes {
+				jobActions = append(jobActions, resourceAttributesActions[svc.Namespace])
+			}
+			if len(job.InfoMetricAttributes) > 0 || job.AddLogGroupMetrics {
+				jobActions = append(jobActions, infoMetricAttributesActions[svc.Namespace])
+			}
+		}
+		add(job.Roles, jobActions...)
+	}
+	for _, job := range jobsCfg.StaticJobs {
+		jobActions := [][]string{getMetricStatisticsActions}
+		if hasDimensionWildcard(job.Dimensions) {
+			jobActions = append(jobActions, listMetricsActions)
+		}
+		add(job.Roles, jobActions...)
+	}
+	for _, job := range jobsCfg.CustomNamespaceJobs {
+		add(job.Roles, getMetricDataActions)
+	}
+	for _, job := range jobsCfg.CustomNamespaceDiscoveryJobs {
+		add(job.Roles, getMetricDataActions)
+	}
+	for _, job := range jobsCfg.InventoryJobs {
+		add(job.Roles, taggingActions)
+	}
+	for _, job := range jobsCfg.CostExplorerJobs {
+		add(job.Roles, costExplorerActions)
+	}
+	for _, job := range jobsCfg.ServiceQuotaJobs {
+		add(job.Roles, serviceQuotasActions)
+	}
+	for _, job := range jobsCfg.TrustedAdvisorJobs {
+		add(job.Roles, trustedAdvisorActions)
+	}
+	for _, job := range jobsCfg.LogsInsightsJobs {
+		add(job.Roles, logsInsightsActions)
+	}
+	for _, job := range jobsCfg.ContributorInsightsJobs {
+		add(job.Roles, contributorInsightsActions)
+	}
+	if jobsCfg.Organization != nil {
+		add([]model.Role{{RoleArn: jobsCfg.Organization.ManagementRoleArn}}, organizationActions)
+	}
+
+	requirements := make([]Requirement, 0, len(actions))
+	for role, roleActions := range actions {
+		requirements = append(requirements, Requirement{Role: role, Actions: sortedActions(roleActions)})
+	}
+	slices.SortFunc(requirements, func(a, b Requirement) int {
+		return cmp.Or(cmp.Compare(a.Role.RoleArn, b.Role.RoleArn), cmp.Compare(a.Role.ExternalID, b.Role.ExternalID))
+	})
+	return requirements
+}
+
+// KnownActions returns all the actions the jobs may need, sorted. The ones a role is
+// allowed but none of its jobs need are reported as unused.
+func KnownActions() []string {
+	actions := map[string]struct{}{}
+	for _, list := range [][]string{
+		getAccountActions, listMetricsActions, getMetricDataActions, getMetricStatisticsActions, taggingActions,
+		costExplorerActions, serviceQuotasActions, trustedAdvisorActions, logsInsightsActions,
+		contributorInsightsActions, organizationActions,
+	} {
+		for _, action := range list {
+			actions[action] = struct{}{}
+		}
+	}
+	for _, namespaceActions := range []map[string][]string{discoveryResourceActions, resourceAttributesActions, infoMetricAttributesActions} {
+		for _, list := range namespaceActions {
+			for _, action := range list {
+				actions[action] = struct{}{}
+			}
+		}
+	}
+	return sortedActions(actions)
+}
+
+func sortedActions(actions map[string]struct{}) []string {
+	sorted := make([]string, 0, len(actions))
+	for action := range actions {
+		sorted = append(sorted, action)
+	}
+	slices.Sort(sorted)
+	return sorted
+}
+
+// hasDimensionWildcard returns whether the dimensions of a static job are expanded with ListMetrics.
+func hasDimensionWildcard(dimensions []model.Dimension) bool {
+	for _, dimension := range dimensions {
+		if dimension.Value == "*" {
+			return true
+		}
+	}
+	return false
+}
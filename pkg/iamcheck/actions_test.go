@@ -0,0 +1,63 @@
+package iamcheck
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+func TestRequirements(t *testing.T) {
+	role := model.Role{RoleArn: "arn:aws:iam::123456789012:role/yace"}
+	requirements := Requirements(model.JobsConfig{
+		DiscoveryJobs: []model.DiscoveryJob{
+			{Type: "AWS/EC2", Roles: []model.Role{{}}, AddResourceAttributes: true},
+			{Type: "AWS/SQS", Roles: []model.Role{role}, InfoMetricAttributes: []string{"fifo_queue"}},
+		},
+		StaticJobs: []model.StaticJob{
+			{Roles: []model.Role{role}, Dimensions: []model.Dimension{{Name: "Currency", Value: "*"}}},
+		},
+		CostExplorerJobs: []model.CostExplorerJob{{Roles: []model.Role{{}}}},
+		Organization:     &model.OrganizationConfig{RoleName: "yace"},
+	})
+
+	require.Equal(t, []Requirement{
+		{
+			Role: model.Role{},
+			Actions: []string{
+				"ce:GetCostAndUsage",
+				"cloudwatch:GetMetricData",
+				"cloudwatch:ListMetrics",
+				"ec2:DescribeInstances",
+				"organizations:ListAccounts",
+				"organizations:ListParents",
+				"organizations:ListTagsForResource",
+				"sts:GetCallerIdentity",
+				"tag:GetResources",
+			},
+		},
+		{
+			Role: role,
+			Actions: []string{
+				"cloudwatch:GetMetricData",
+				"cloudwatch:GetMetricStatistics",
+				"cloudwatch:ListMetrics",
+				"sqs:GetQueueAttributes",
+				"sqs:GetQueueUrl",
+				"sts:GetCallerIdentity",
+				"tag:GetResources",
+			},
+		},
+	}, requirements)
+}
+
+func TestKnownActions(t *testing.T) {
+	known := KnownActions()
+	require.IsNonDecreasing(t, known)
+	for _, requirement := range Requirements(model.JobsConfig{
+		DiscoveryJobs: []model.DiscoveryJob{{Type: "AWS/RDS", Roles: []model.Role{{}}, AddResourceAttributes: true}},
+	}) {
+		require.Subset(t, known, requirement.Actions)
+	}
+}
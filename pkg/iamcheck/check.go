@@ -0,0 +1,183 @@
+package iamcheck
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+// defaultRegion is the region of the STS API when none is configured, the IAM API is global.
+const defaultRegion = "us-east-1"
+
+// PolicyDocument is an IAM policy document.
+type PolicyDocument struct {
+	Version   string      `json:"Version"`
+	Statement []Statement `json:"Statement"`
+}
+
+// Statement is a statement of a PolicyDocument.
+type Statement struct {
+	Effect   string   `json:"Effect"`
+	Action   []string `json:"Action"`
+	Resource string   `json:"Resource"`
+}
+
+// Report is the outcome of the check of a role.
+type Report struct {
+	// RoleArn is the role of the jobs, empty for the current credentials
+	RoleArn string `json:"role_arn,omitempty"`
+	// Principal is the IAM principal whose policies were simulated
+	Principal string `json:"principal,omitempty"`
+	// Missing are the actions the jobs need but the principal isn't allowed
+	Missing []string `json:"missing"`
+	// Unused are the actions the principal is allowed but none of its jobs need
+	Unused []string `json:"unused"`
+	// Policy allows the actions the jobs need, and only them
+	Policy PolicyDocument `json:"suggested_policy"`
+	// Error is why the role couldn't be checked, e.g. missing iam:SimulatePrincipalPolicy
+	Error string `json:"error,omitempty"`
+}
+
+// Checker simulates the policies of the roles with their own credentials, which must
+// be allowed iam:SimulatePrincipalPolicy on themselves, and iam:GetRole for the role of
+// the current credentials.
+type Checker struct {
+	apis func(role model.Role) (iamiface.IAMAPI, stsiface.STSAPI)
+}
+
+// NewChecker returns a Checker assuming the roles from the current credentials,
+// calling STS in stsRegion if not empty.
+func NewChecker(stsRegion string, fips bool) (*Checker, error) {
+	sess, err := session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the IAM session: %w", err)
+	}
+
+	return &Checker{
+		apis: func(role model.Role) (iamiface.IAMAPI, stsiface.STSAPI) {
+			awsCfg := &aws.Config{}
+			if stsRegion != "" {
+				awsCfg.Region = aws.String(stsRegion)
+			} else if aws.StringValue(sess.Config.Region) == "" {
+				awsCfg.Region = aws.String(defaultRegion)
+			}
+			if fips {
+				awsCfg.UseFIPSEndpoint = endpoints.FIPSEndpointStateEnabled
+			}
+			if role.RoleArn != "" {
+				awsCfg.Credentials = stscreds.NewCredentials(sess, role.RoleArn, func(p *stscreds.AssumeRoleProvider) {
+					if role.ExternalID != "" {
+						p.ExternalID = aws.String(role.ExternalID)
+					}
+				})
+			}
+			return iam.New(sess, awsCfg), sts.New(sess, awsCfg)
+		},
+	}, nil
+}
+
+// Check simulates the policies of the roles of the requirements.
+func (c *Checker) Check(ctx context.Context, requirements []Requirement) []Report {
+	known := KnownActions()
+	reports := make([]Report, 0, len(requirements))
+	for _, requirement := range requirements {
+		report := Report{
+			RoleArn: requirement.Role.RoleArn,
+			Missing: []string{},
+			Unused:  []string{},
+			Policy: PolicyDocument{
+				Version:   "2012-10-17",
+				Statement: []Statement{{Effect: "Allow", Action: requirement.Actions, Resource: "*"}},
+			},
+		}
+
+		iamAPI, stsAPI := c.apis(requirement.Role)
+		allowed, principal, err := simulate(ctx, iamAPI, stsAPI, requirement.Role, known)
+		report.Principal = principal
+		if err != nil {
+			report.Error = err.Error()
+			reports = append(reports, report)
+			continue
+		}
+		for _, action := range requirement.Actions {
+			if !allowed[action] {
+				report.Missing = append(report.Missing, action)
+			}
+		}
+		for _, action := range known {
+			if allowed[action] && !slices.Contains(requirement.Actions, action) {
+				report.Unused = append(report.Unused, action)
+			}
+		}
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+// simulate returns which of the actions the principal of the role is allowed, and the principal.
+func simulate(ctx context.Context, iamAPI iamiface.IAMAPI, stsAPI stsiface.STSAPI, role model.Role, actions []string) (map[string]bool, string, error) {
+	principal, err := principalARN(ctx, iamAPI, stsAPI, role)
+	if err != nil {
+		return nil, principal, err
+	}
+
+	allowed := map[string]bool{}
+	err = iamAPI.SimulatePrincipalPolicyPagesWithContext(ctx, &iam.SimulatePrincipalPolicyInput{
+		PolicySourceArn: aws.String(principal),
+		ActionNames:     aws.StringSlice(actions),
+	}, func(page *iam.SimulatePolicyResponse, _ bool) bool {
+		for _, result := range page.EvaluationResults {
+			allowed[aws.StringValue(result.EvalActionName)] = aws.StringValue(result.EvalDecision) == iam.PolicyEvaluationDecisionTypeAllowed
+		}
+		return true
+	})
+	if err != nil {
+		return nil, principal, fmt.Errorf("failed to simulate the policies of %s: %w", principal, err)
+	}
+	return allowed, principal, nil
+}
+
+// principalARN returns the ARN of the IAM role or user of the role, for the current
+// credentials the role of the assumed role session or the user.
+func principalARN(ctx context.Context, iamAPI iamiface.IAMAPI, stsAPI stsiface.STSAPI, role model.Role) (string, error) {
+	if role.RoleArn != "" {
+		return role.RoleArn, nil
+	}
+
+	identity, err := stsAPI.GetCallerIdentityWithContext(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get the caller identity: %w", err)
+	}
+	callerARN := aws.StringValue(identity.Arn)
+	parsed, err := arn.Parse(callerARN)
+	if err != nil {
+		return callerARN, fmt.Errorf("invalid caller ARN %q: %w", callerARN, err)
+	}
+	// arn:aws:sts::123456789012:assumed-role/name/session is a session of the role name,
+	// whose ARN has a path the session ARN doesn't have
+	sessionRole, ok := strings.CutPrefix(parsed.Resource, "assumed-role/")
+	if parsed.Service != "sts" || !ok {
+		return callerARN, nil
+	}
+	roleName, _, _ := strings.Cut(sessionRole, "/")
+	output, err := iamAPI.GetRoleWithContext(ctx, &iam.GetRoleInput{RoleName: aws.String(roleName)})
+	if err != nil {
+		return callerARN, fmt.Errorf("failed to get the role %s: %w", roleName, err)
+	}
+	return aws.StringValue(output.Role.Arn), nil
+}
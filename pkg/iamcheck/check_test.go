@@ -0,0 +1,97 @@
+package iamcheck
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+type iamAPI struct {
+	iamiface.IAMAPI
+	allowed map[string][]string
+	err     error
+}
+
+func (i *iamAPI) SimulatePrincipalPolicyPagesWithContext(_ aws.Context, input *iam.SimulatePrincipalPolicyInput, fn func(*iam.SimulatePolicyResponse, bool) bool, _ ...request.Option) error {
+	if i.err != nil {
+		return i.err
+	}
+	allowed := i.allowed[aws.StringValue(input.PolicySourceArn)]
+	page := &iam.SimulatePolicyResponse{}
+	for _, action := range aws.StringValueSlice(input.ActionNames) {
+		decision := iam.PolicyEvaluationDecisionTypeImplicitDeny
+		for _, a := range allowed {
+			if a == action {
+				decision = iam.PolicyEvaluationDecisionTypeAllowed
+			}
+		}
+		page.EvaluationResults = append(page.EvaluationResults, &iam.EvaluationResult{
+			EvalActionName: aws.String(action),
+			EvalDecision:   aws.String(decision),
+		})
+	}
+	fn(page, true)
+	return nil
+}
+
+func (i *iamAPI) GetRoleWithContext(_ aws.Context, input *iam.GetRoleInput, _ ...request.Option) (*iam.GetRoleOutput, error) {
+	return &iam.GetRoleOutput{Role: &iam.Role{Arn: aws.String("arn:aws:iam::111111111111:role/exporters/" + aws.StringValue(input.RoleName))}}, nil
+}
+
+type stsAPI struct {
+	stsiface.STSAPI
+	arn string
+}
+
+func (s *stsAPI) GetCallerIdentityWithContext(_ aws.Context, _ *sts.GetCallerIdentityInput, _ ...request.Option) (*sts.GetCallerIdentityOutput, error) {
+	return &sts.GetCallerIdentityOutput{Arn: aws.String(s.arn)}, nil
+}
+
+func TestCheck(t *testing.T) {
+	role := model.Role{RoleArn: "arn:aws:iam::123456789012:role/yace"}
+	fakeIAM := &iamAPI{allowed: map[string][]string{
+		"arn:aws:iam::111111111111:role/exporters/yace-task": {"sts:GetCallerIdentity", "tag:GetResources", "cloudwatch:ListMetrics", "cloudwatch:GetMetricData", "ec2:DescribeInstances"},
+		role.RoleArn: {"sts:GetCallerIdentity", "cloudwatch:GetMetricStatistics"},
+	}}
+	checker := &Checker{apis: func(model.Role) (iamiface.IAMAPI, stsiface.STSAPI) {
+		return fakeIAM, &stsAPI{arn: "arn:aws:sts::111111111111:assumed-role/yace-task/session"}
+	}}
+
+	reports := checker.Check(context.Background(), []Requirement{
+		{Role: model.Role{}, Actions: []string{"cloudwatch:GetMetricData", "cloudwatch:ListMetrics", "sts:GetCallerIdentity", "tag:GetResources"}},
+		{Role: role, Actions: []string{"cloudwatch:GetMetricStatistics", "cloudwatch:ListMetrics", "sts:GetCallerIdentity"}},
+	})
+
+	require.Len(t, reports, 2)
+	require.Equal(t, "arn:aws:iam::111111111111:role/exporters/yace-task", reports[0].Principal)
+	require.Empty(t, reports[0].Missing)
+	require.Equal(t, []string{"ec2:DescribeInstances"}, reports[0].Unused)
+	require.Equal(t, []string{"cloudwatch:GetMetricData", "cloudwatch:ListMetrics", "sts:GetCallerIdentity", "tag:GetResources"}, reports[0].Policy.Statement[0].Action)
+
+	require.Equal(t, role.RoleArn, reports[1].RoleArn)
+	require.Equal(t, []string{"cloudwatch:ListMetrics"}, reports[1].Missing)
+	require.Empty(t, reports[1].Unused)
+	require.Empty(t, reports[1].Error)
+}
+
+func TestCheckError(t *testing.T) {
+	checker := &Checker{apis: func(model.Role) (iamiface.IAMAPI, stsiface.STSAPI) {
+		return &iamAPI{err: errors.New("AccessDenied")}, &stsAPI{arn: "arn:aws:iam::111111111111:user/yace"}
+	}}
+
+	reports := checker.Check(context.Background(), []Requirement{{Actions: []string{"sts:GetCallerIdentity"}}})
+	require.Len(t, reports, 1)
+	require.Equal(t, "arn:aws:iam::111111111111:user/yace", reports[0].Principal)
+	require.Contains(t, reports[0].Error, "AccessDenied")
+	require.Empty(t, reports[0].Missing)
+}
@@ -0,0 +1,31 @@
+package job
+
+import (
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+// filterAPIGatewayMetrics only keeps the AWS/ApiGateway metrics with the dimensions of the
+// given model.APIGatewayGranularity. The granularity of a metric is the finest of its dimensions:
+// method for Method, Resource or Route, stage for Stage, api otherwise.
+func filterAPIGatewayMetrics(granularity string, metrics []*model.Metric) []*model.Metric {
+	filtered := make([]*model.Metric, 0, len(metrics))
+	for _, metric := range metrics {
+		if apiGatewayMetricGranularity(metric) == granularity {
+			filtered = append(filtered, metric)
+		}
+	}
+	return filtered
+}
+
+func apiGatewayMetricGranularity(metric *model.Metric) string {
+	granularity := model.APIGatewayGranularityAPI
+	for _, dimension := range metric.Dimensions {
+		switch dimension.Name {
+		case "Method", "Resource", "Route":
+			return model.APIGatewayGranularityMethod
+		case "Stage":
+			granularity = model.APIGatewayGranularityStage
+		}
+	}
+	return granularity
+}
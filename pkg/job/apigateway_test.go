@@ -0,0 +1,90 @@
+package job
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+func TestFilterAPIGatewayMetrics(t *testing.T) {
+	restAPIMetric := &model.Metric{
+		MetricName: "Count",
+		Namespace:  "AWS/ApiGateway",
+		Dimensions: []*model.Dimension{
+			{Name: "ApiName", Value: "my-api"},
+		},
+	}
+	restStageMetric := &model.Metric{
+		MetricName: "Count",
+		Namespace:  "AWS/ApiGateway",
+		Dimensions: []*model.Dimension{
+			{Name: "ApiName", Value: "my-api"},
+			{Name: "Stage", Value: "prod"},
+		},
+	}
+	restMethodMetric := &model.Metric{
+		MetricName: "Count",
+		Namespace:  "AWS/ApiGateway",
+		Dimensions: []*model.Dimension{
+			{Name: "ApiName", Value: "my-api"},
+			{Name: "Method", Value: "GET"},
+			{Name: "Resource", Value: "/orders"},
+			{Name: "Stage", Value: "prod"},
+		},
+	}
+	httpAPIMetric := &model.Metric{
+		MetricName: "Count",
+		Namespace:  "AWS/ApiGateway",
+		Dimensions: []*model.Dimension{
+			{Name: "ApiId", Value: "a1b2c3"},
+		},
+	}
+	httpStageMetric := &model.Metric{
+		MetricName: "Count",
+		Namespace:  "AWS/ApiGateway",
+		Dimensions: []*model.Dimension{
+			{Name: "ApiId", Value: "a1b2c3"},
+			{Name: "Stage", Value: "$default"},
+		},
+	}
+	httpRouteMetric := &model.Metric{
+		MetricName: "Count",
+		Namespace:  "AWS/ApiGateway",
+		Dimensions: []*model.Dimension{
+			{Name: "ApiId", Value: "a1b2c3"},
+			{Name: "Route", Value: "GET /orders"},
+			{Name: "Stage", Value: "$default"},
+		},
+	}
+	metrics := []*model.Metric{restAPIMetric, restStageMetric, restMethodMetric, httpAPIMetric, httpStageMetric, httpRouteMetric}
+
+	testCases := []struct {
+		name        string
+		granularity string
+		expected    []*model.Metric
+	}{
+		{
+			name:        "api granularity keeps API level metrics",
+			granularity: model.APIGatewayGranularityAPI,
+			expected:    []*model.Metric{restAPIMetric, httpAPIMetric},
+		},
+		{
+			name:        "stage granularity keeps stage level metrics",
+			granularity: model.APIGatewayGranularityStage,
+			expected:    []*model.Metric{restStageMetric, httpStageMetric},
+		},
+		{
+			name:        "method granularity keeps method and route level metrics",
+			granularity: model.APIGatewayGranularityMethod,
+			expected:    []*model.Metric{restMethodMetric, httpRouteMetric},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, filterAPIGatewayMetrics(tc.granularity, metrics))
+		})
+	}
+}
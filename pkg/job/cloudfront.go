@@ -0,0 +1,25 @@
+package job
+
+import (
+	"slices"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+// cloudFrontTotalDimensions are the dimensions of the distribution totals.
+var cloudFrontTotalDimensions = []string{"DistributionId", "Region"}
+
+// filterCloudFrontMetrics only keeps the AWS/CloudFront distribution totals and
+// the breakdown series whose other dimensions are all in the given breakdowns.
+func filterCloudFrontMetrics(breakdowns []string, metrics []*model.Metric) []*model.Metric {
+	filtered := make([]*model.Metric, 0, len(metrics))
+	for _, metric := range metrics {
+		if slices.ContainsFunc(metric.Dimensions, func(dimension *model.Dimension) bool {
+			return !slices.Contains(cloudFrontTotalDimensions, dimension.Name) && !slices.Contains(breakdowns, dimension.Name)
+		}) {
+			continue
+		}
+		filtered = append(filtered, metric)
+	}
+	return filtered
+}
@@ -0,0 +1,58 @@
+package job
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+func TestFilterCloudFrontMetrics(t *testing.T) {
+	totalMetric := &model.Metric{
+		MetricName: "Requests",
+		Namespace:  "AWS/CloudFront",
+		Dimensions: []*model.Dimension{
+			{Name: "DistributionId", Value: "E1A2B3C4D5E6F7"},
+			{Name: "Region", Value: "Global"},
+		},
+	}
+	countryMetric := &model.Metric{
+		MetricName: "Requests",
+		Namespace:  "AWS/CloudFront",
+		Dimensions: []*model.Dimension{
+			{Name: "Country", Value: "NL"},
+			{Name: "DistributionId", Value: "E1A2B3C4D5E6F7"},
+			{Name: "Region", Value: "Global"},
+		},
+	}
+	cacheBehaviorMetric := &model.Metric{
+		MetricName: "Requests",
+		Namespace:  "AWS/CloudFront",
+		Dimensions: []*model.Dimension{
+			{Name: "CacheBehavior", Value: "/api/*"},
+			{Name: "DistributionId", Value: "E1A2B3C4D5E6F7"},
+			{Name: "Region", Value: "Global"},
+		},
+	}
+	countryCacheBehaviorMetric := &model.Metric{
+		MetricName: "Requests",
+		Namespace:  "AWS/CloudFront",
+		Dimensions: []*model.Dimension{
+			{Name: "CacheBehavior", Value: "/api/*"},
+			{Name: "Country", Value: "NL"},
+			{Name: "DistributionId", Value: "E1A2B3C4D5E6F7"},
+			{Name: "Region", Value: "Global"},
+		},
+	}
+	metrics := []*model.Metric{totalMetric, countryMetric, cacheBehaviorMetric, countryCacheBehaviorMetric}
+
+	require.Equal(t,
+		[]*model.Metric{totalMetric, countryMetric},
+		filterCloudFrontMetrics([]string{"Country"}, metrics),
+	)
+	require.Equal(t,
+		metrics,
+		filterCloudFrontMetrics([]string{"Country", "CacheBehavior"}, metrics),
+	)
+}
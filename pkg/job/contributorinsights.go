@@ -0,0 +1,72 @@
+package job
+
+import (
+	"context"
+	"sync"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/retries"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/useragent"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+// ScrapeContributorInsights retrieves the reports of the rules of the Contributor
+// Insights jobs. The reports are cached by the clients for the refresh interval of the jobs.
+func ScrapeContributorInsights(
+	ctx context.Context,
+	logger logging.Logger,
+	jobsCfg model.JobsConfig,
+	factory clients.Factory,
+) []model.ContributorInsightsResult {
+	mux := &sync.Mutex{}
+	reportData := make([]model.ContributorInsightsResult, 0)
+	var wg sync.WaitGroup
+
+	for _, contributorInsightsJob := range jobsCfg.ContributorInsightsJobs {
+		for _, role := range contributorInsightsJob.Roles {
+			for _, region := range contributorInsightsJob.Regions {
+				wg.Add(1)
+				go func(contributorInsightsJob model.ContributorInsightsJob, region string, role model.Role) {
+					defer wg.Done()
+					ctx := useragent.WithJob(ctx, "contributor-insights")
+					jobLogger := logger.With("contributor_insights", true, "region", region, "arn", role.RoleArn)
+					accountID, err := factory.GetAccountClient(region, role).GetAccount(ctx)
+					if err != nil {
+						jobLogger.Error(err, "Couldn't get account Id")
+						return
+					}
+					jobLogger = jobLogger.With("account", accountID)
+					ctx = retries.WithAccount(ctx, accountID)
+
+					client := factory.GetContributorInsightsClient(region, role)
+					reports := make([]*model.ContributorInsightsReport, 0, len(contributorInsightsJob.RuleNames))
+					for _, ruleName := range contributorInsightsJob.RuleNames {
+						report, err := client.GetInsightRuleReport(ctx, ruleName, contributorInsightsJob)
+						if err != nil {
+							jobLogger.Error(err, "Couldn't get Contributor Insights rule report", "rule_name", ruleName)
+							continue
+						}
+						reports = append(reports, report)
+					}
+					if len(reports) == 0 {
+						return
+					}
+
+					mux.Lock()
+					reportData = append(reportData, model.ContributorInsightsResult{
+						Context: &model.ScrapeContext{
+							Region:     region,
+							AccountID:  accountID,
+							CustomTags: contributorInsightsJob.CustomTags,
+						},
+						Data: reports,
+					})
+					mux.Unlock()
+				}(contributorInsightsJob, region, role)
+			}
+		}
+	}
+	wg.Wait()
+	return reportData
+}
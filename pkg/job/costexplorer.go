@@ -0,0 +1,59 @@
+package job
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/costexplorer"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+// costExplorerNamespace is the namespace the costs are exported
+// with, e.g. as aws_costexplorer_unblended_cost_sum.
+const costExplorerNamespace = "AWS/CostExplorer"
+
+func runCostExplorerJob(
+	ctx context.Context,
+	logger logging.Logger,
+	job model.CostExplorerJob,
+	clientCostExplorer costexplorer.Client,
+) ([]*model.CloudwatchData, error) {
+	costs, err := clientCostExplorer.GetCostAndUsage(ctx, job)
+	if err != nil {
+		logger.Error(err, "Couldn't get cost and usage")
+		return nil, &scrapeError{reason: reasonGetCostAndUsage, err: err}
+	}
+
+	cw := make([]*model.CloudwatchData, 0, len(costs))
+	for _, cost := range costs {
+		data := &model.CloudwatchData{
+			ID:                      aws.String(job.Name),
+			MetricID:                aws.String(cost.Metric),
+			Metric:                  aws.String(cost.Metric),
+			Namespace:               aws.String(costExplorerNamespace),
+			Statistics:              []string{"Sum"},
+			GetMetricDataPoint:      aws.Float64(cost.Amount),
+			GetMetricDataTimestamps: cost.Start,
+			NilToZero:               aws.Bool(false),
+			AddCloudwatchTimestamp:  aws.Bool(job.AddTimestamp),
+			AddHistoricalMetrics:    aws.Bool(false),
+			Tags:                    []model.Tag{},
+			Attributes:              []model.Tag{{Key: "unit", Value: cost.Unit}},
+			Dimensions:              []*model.Dimension{},
+		}
+
+		// Groups are in the order of the job groupBy. Tags are exported
+		// as tag_<key> labels, like resource tags, the others as dimensions.
+		for i, group := range cost.Groups {
+			if i < len(job.GroupBy) && job.GroupBy[i].Type == model.CostExplorerGroupByTag {
+				data.Tags = append(data.Tags, group)
+			} else {
+				data.Dimensions = append(data.Dimensions, &model.Dimension{Name: group.Key, Value: group.Value})
+			}
+		}
+		cw = append(cw, data)
+	}
+	return cw, nil
+}
@@ -3,56 +3,71 @@ package job
 import (
 	"context"
 	"fmt"
-	"math"
 	"math/rand"
 	"sync"
 
 	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/cloudwatch"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
 	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
 	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
 )
 
+// runCustomNamespaceJob returns the datapoints of the metrics of the job, and an error
+// when some metrics could not be listed or some GetMetricData requests failed.
 func runCustomNamespaceJob(
 	ctx context.Context,
 	logger logging.Logger,
 	job model.CustomNamespaceJob,
 	clientCloudwatch cloudwatch.Client,
 	metricsPerQuery int,
-) []*model.CloudwatchData {
+) ([]*model.CloudwatchData, error) {
+	getMetricDatas, listErr := getMetricDataForQueriesForCustomNamespace(ctx, job, clientCloudwatch, logger)
+	cw, err := getCustomNamespaceMetricData(ctx, logger, job, clientCloudwatch, getMetricDatas, metricsPerQuery)
+	if listErr != nil {
+		return cw, &scrapeError{reason: reasonGetMetricData, err: listErr}
+	}
+	return cw, err
+}
+
+// getCustomNamespaceMetricData fetches the datapoints of the given metrics,
+// listed beforehand, with partitioned GetMetricData calls.
+func getCustomNamespaceMetricData(
+	ctx context.Context,
+	logger logging.Logger,
+	job model.CustomNamespaceJob,
+	clientCloudwatch cloudwatch.Client,
+	getMetricDatas []*model.CloudwatchData,
+	metricsPerQuery int,
+) ([]*model.CloudwatchData, error) {
 	cw := []*model.CloudwatchData{}
 
 	mux := &sync.Mutex{}
 	var wg sync.WaitGroup
+	failed := 0
 
-	getMetricDatas := getMetricDataForQueriesForCustomNamespace(ctx, job, clientCloudwatch, logger)
 	metricDataLength := len(getMetricDatas)
 	if metricDataLength == 0 {
 		logger.Debug("No metrics data found")
-		return cw
+		return cw, nil
 	}
 
-	maxMetricCount := metricsPerQuery
 	length := getMetricDataInputLength(job.Metrics)
-	partition := int(math.Ceil(float64(metricDataLength) / float64(maxMetricCount)))
-	logger.Debug("GetMetricData partitions", "total", partition)
+	partitions := partitionGetMetricData(getMetricDatas, metricsPerQuery, job.RoundingPeriod)
+	logger.Debug("GetMetricData partitions", "total", len(partitions))
 
-	wg.Add(partition)
+	wg.Add(len(partitions))
 
 	var addHistoricalMetrics bool
 	if job.AddHistoricalMetrics != nil {
 		addHistoricalMetrics = *job.AddHistoricalMetrics
 	}
 
-	for i := 0; i < metricDataLength; i += maxMetricCount {
-		go func(i int) {
+	for _, partition := range partitions {
+		go func(partition getMetricDataPartition) {
 			defer wg.Done()
 
-			end := i + maxMetricCount
-			if end > metricDataLength {
-				end = metricDataLength
-			}
-			input := getMetricDatas[i:end]
-			data := clientCloudwatch.GetMetricData(ctx, logger, input, job.Namespace, length, job.Delay, job.RoundingPeriod, addHistoricalMetrics)
+			input := partition.data
+			data := clientCloudwatch.GetMetricData(ctx, logger, input, job.Namespace, length, job.Delay, partition.roundingPeriod, addHistoricalMetrics)
 
 			if data != nil {
 				output := make([]*model.CloudwatchData, 0)
@@ -67,12 +82,21 @@ func runCustomNamespaceJob(
 				mux.Lock()
 				cw = append(cw, output...)
 				mux.Unlock()
+			} else {
+				logger.Warn("GetMetricData partition empty result", "size", len(input))
+				mux.Lock()
+				failed++
+				mux.Unlock()
 			}
-		}(i)
+		}(partition)
 	}
 
 	wg.Wait()
-	return cw
+	if failed > 0 {
+		// The clients only return no result when the request failed
+		return cw, &scrapeError{reason: reasonGetMetricData, err: fmt.Errorf("%d of %d GetMetricData requests failed", failed, len(partitions))}
+	}
+	return cw, nil
 }
 
 func findGetMetricDataByIDForCustomNamespace(getMetricDatas []*model.CloudwatchData, value string) (*model.CloudwatchData, error) {
@@ -84,14 +108,17 @@ func findGetMetricDataByIDForCustomNamespace(getMetricDatas []*model.CloudwatchD
 	return nil, fmt.Errorf("metric with id %s not found", value)
 }
 
+// getMetricDataForQueriesForCustomNamespace lists the metrics of the job. The metrics
+// listed are returned along with the first ListMetrics error, if any.
 func getMetricDataForQueriesForCustomNamespace(
 	ctx context.Context,
 	customNamespaceJob model.CustomNamespaceJob,
 	clientCloudwatch cloudwatch.Client,
 	logger logging.Logger,
-) []*model.CloudwatchData {
+) ([]*model.CloudwatchData, error) {
 	mux := &sync.Mutex{}
 	var getMetricDatas []*model.CloudwatchData
+	var listErr error
 
 	var wg sync.WaitGroup
 	wg.Add(len(customNamespaceJob.Metrics))
@@ -111,20 +138,7 @@ func getMetricDataForQueriesForCustomNamespace(
 						continue
 					}
 
-					for _, stats := range metric.Statistics {
-						id := fmt.Sprintf("id_%d", rand.Int())
-						data = append(data, &model.CloudwatchData{
-							ID:                     &customNamespaceJob.Name,
-							MetricID:               &id,
-							Metric:                 &metric.Name,
-							Namespace:              &customNamespaceJob.Namespace,
-							Statistics:             []string{stats},
-							NilToZero:              metric.NilToZero,
-							AddCloudwatchTimestamp: metric.AddCloudwatchTimestamp,
-							Dimensions:             cwMetric.Dimensions,
-							Period:                 metric.Period,
-						})
-					}
+					data = append(data, customNamespaceMetricDatas(&customNamespaceJob, metric, cwMetric)...)
 				}
 
 				mux.Lock()
@@ -133,11 +147,42 @@ func getMetricDataForQueriesForCustomNamespace(
 			})
 			if err != nil {
 				logger.Error(err, "Failed to get full metric list", "metric_name", metric.Name, "namespace", customNamespaceJob.Namespace)
+				mux.Lock()
+				if listErr == nil {
+					listErr = err
+				}
+				mux.Unlock()
 				return
 			}
 		}(metric)
 	}
 
 	wg.Wait()
-	return getMetricDatas
+	return getMetricDatas, listErr
+}
+
+// customNamespaceMetricDatas returns the data to query for a listed metric, one per statistic.
+func customNamespaceMetricDatas(job *model.CustomNamespaceJob, metric *model.MetricConfig, cwMetric *model.Metric) []*model.CloudwatchData {
+	data := make([]*model.CloudwatchData, 0, len(metric.Statistics))
+	for _, stats := range metric.Statistics {
+		id := fmt.Sprintf("id_%d", rand.Int())
+		data = append(data, &model.CloudwatchData{
+			ID:                     &job.Name,
+			MetricID:               &id,
+			Metric:                 &metric.Name,
+			Namespace:              &job.Namespace,
+			Statistics:             []string{stats},
+			NilToZero:              metric.NilToZero,
+			AddCloudwatchTimestamp: metric.AddCloudwatchTimestamp,
+			Dimensions:             cwMetric.Dimensions,
+			Period:                 metric.Period,
+			RoundingPeriod:         metric.WindowRoundingPeriod(),
+			CompletePeriodsOnly:    metric.CompletePeriodsOnly,
+			SkipIncompletePeriod:   metric.SkipIncompletePeriod,
+			PublishDelay:           config.SupportedServices.PublishDelay(job.Namespace),
+			StatLabelMode:          metric.StatLabelMode,
+			HistogramBuckets:       metric.HistogramBuckets,
+		})
+	}
+	return data
 }
@@ -0,0 +1,170 @@
+package job
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/cloudwatch"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+// customNamespacesKey identifies the discoveries of a job in an account and region.
+type customNamespacesKey struct {
+	accountID          string
+	region             string
+	includeRegex       string
+	excludeRegex       string
+	recentlyActiveOnly bool
+}
+
+type customNamespacesEntry struct {
+	namespaces []string
+	expiresAt  time.Time
+}
+
+// customNamespaces keeps the namespaces discovered by the jobs across the scrapes,
+// for the refresh interval of the jobs.
+type customNamespaces struct {
+	mu      sync.Mutex
+	entries map[customNamespacesKey]customNamespacesEntry
+}
+
+func newCustomNamespaces() *customNamespaces {
+	return &customNamespaces{entries: map[customNamespacesKey]customNamespacesEntry{}}
+}
+
+// discoveredNamespaces are the custom namespaces discovered by the jobs of all the scrapes.
+var discoveredNamespaces = newCustomNamespaces()
+
+func newCustomNamespacesKey(job model.CustomNamespaceDiscoveryJob, accountID string, region string) customNamespacesKey {
+	key := customNamespacesKey{
+		accountID:          accountID,
+		region:             region,
+		includeRegex:       job.IncludeRegex.String(),
+		recentlyActiveOnly: job.RecentlyActiveOnly,
+	}
+	if job.ExcludeRegex != nil {
+		key.excludeRegex = job.ExcludeRegex.String()
+	}
+	return key
+}
+
+// get returns the namespaces of the last discovery, unless older than the refresh interval.
+func (c *customNamespaces) get(key customNamespacesKey) ([]string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.namespaces, true
+}
+
+func (c *customNamespaces) set(key customNamespacesKey, namespaces []string, refreshInterval time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = customNamespacesEntry{namespaces: namespaces, expiresAt: time.Now().Add(refreshInterval)}
+}
+
+// runCustomNamespaceDiscoveryJob queries the metrics of the matching custom namespaces.
+// The namespaces are discovered by listing the metrics of all namespaces with a single
+// ListMetrics call, once per refresh interval of the job. In between, the metrics of
+// the discovered namespaces are listed one namespace at a time. The error is the one
+// of the listing, or the first of the GetMetricData requests of the namespaces.
+func runCustomNamespaceDiscoveryJob(
+	ctx context.Context,
+	logger logging.Logger,
+	job model.CustomNamespaceDiscoveryJob,
+	accountID string,
+	region string,
+	clientCloudwatch cloudwatch.Client,
+	metricsPerQuery int,
+) ([]*model.CloudwatchData, error) {
+	key := newCustomNamespacesKey(job, accountID, region)
+	metricsByNamespace := map[string][]*model.Metric{}
+	var err error
+	known, cached := discoveredNamespaces.get(key)
+	if cached {
+		for _, namespace := range known {
+			err = clientCloudwatch.ListMetrics(ctx, namespace, &model.MetricConfig{}, job.RecentlyActiveOnly, func(page []*model.Metric) {
+				metricsByNamespace[namespace] = append(metricsByNamespace[namespace], page...)
+			})
+			if err != nil {
+				break
+			}
+		}
+	} else {
+		err = clientCloudwatch.ListMetrics(ctx, "", &model.MetricConfig{}, job.RecentlyActiveOnly, func(page []*model.Metric) {
+			for _, metric := range page {
+				if isDiscoveredCustomNamespace(job, metric.Namespace) {
+					metricsByNamespace[metric.Namespace] = append(metricsByNamespace[metric.Namespace], metric)
+				}
+			}
+		})
+	}
+	if err != nil {
+		logger.Error(err, "Failed to list metrics of custom namespaces")
+		return nil, &scrapeError{reason: reasonGetMetricData, err: err}
+	}
+
+	namespaces := make([]string, 0, len(metricsByNamespace))
+	for namespace := range metricsByNamespace {
+		namespaces = append(namespaces, namespace)
+	}
+	slices.Sort(namespaces)
+	if !cached {
+		logger.Debug("Discovered custom namespaces", "namespaces", namespaces)
+		discoveredNamespaces.set(key, namespaces, job.RefreshInterval)
+	}
+
+	cw := []*model.CloudwatchData{}
+	var firstErr error
+	for _, namespace := range namespaces {
+		customNamespaceJob := model.CustomNamespaceJob{
+			Name:           namespace,
+			Namespace:      namespace,
+			Metrics:        []*model.MetricConfig{job.Metric},
+			RoundingPeriod: job.RoundingPeriod,
+			JobLevelMetricFields: model.JobLevelMetricFields{
+				AddHistoricalMetrics: job.Metric.AddHistoricalMetrics,
+			},
+		}
+
+		// Metric configs are shared by all the series with the same metric name
+		metricConfigs := map[string]*model.MetricConfig{}
+		var getMetricDatas []*model.CloudwatchData
+		for _, cwMetric := range metricsByNamespace[namespace] {
+			metricConfig, ok := metricConfigs[cwMetric.MetricName]
+			if !ok {
+				c := *job.Metric
+				c.Name = cwMetric.MetricName
+				metricConfig = &c
+				metricConfigs[cwMetric.MetricName] = metricConfig
+			}
+			getMetricDatas = append(getMetricDatas, customNamespaceMetricDatas(&customNamespaceJob, metricConfig, cwMetric)...)
+		}
+
+		data, err := getCustomNamespaceMetricData(ctx, logger.With("custom_metric_namespace", namespace), customNamespaceJob, clientCloudwatch, getMetricDatas, metricsPerQuery)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		cw = append(cw, data...)
+	}
+	return cw, firstErr
+}
+
+// isDiscoveredCustomNamespace returns true if the metrics of the namespace are exported by the
+// job. The namespaces of AWS services, which are configured with discovery jobs, are ignored.
+func isDiscoveredCustomNamespace(job model.CustomNamespaceDiscoveryJob, namespace string) bool {
+	if strings.HasPrefix(namespace, "AWS/") {
+		return false
+	}
+	if job.ExcludeRegex != nil && job.ExcludeRegex.MatchString(namespace) {
+		return false
+	}
+	return job.IncludeRegex.MatchString(namespace)
+}
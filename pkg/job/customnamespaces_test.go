@@ -0,0 +1,102 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/regexp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+func TestIsDiscoveredCustomNamespace(t *testing.T) {
+	job := model.CustomNamespaceDiscoveryJob{
+		IncludeRegex: regexp.MustCompile("^MyCompany/|^AWS/"),
+		ExcludeRegex: regexp.MustCompile("^MyCompany/Legacy"),
+	}
+
+	testCases := []struct {
+		namespace string
+		expected  bool
+	}{
+		{namespace: "MyCompany/Orders", expected: true},
+		{namespace: "MyCompany/Legacy/Billing", expected: false},
+		{namespace: "OtherCompany/Orders", expected: false},
+		{namespace: "AWS/EC2", expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.namespace, func(t *testing.T) {
+			require.Equal(t, tc.expected, isDiscoveredCustomNamespace(job, tc.namespace))
+		})
+	}
+}
+
+func TestRunCustomNamespaceDiscoveryJobErrors(t *testing.T) {
+	job := model.CustomNamespaceDiscoveryJob{
+		IncludeRegex: regexp.MustCompile("^MyCompany/"),
+		Metric:       &model.MetricConfig{Statistics: []string{"Sum"}, Period: 300, Length: 300},
+	}
+
+	// The GetMetricData requests of the staticClient fail
+	client := staticClient{metrics: []*model.Metric{
+		{Namespace: "MyCompany/Orders", MetricName: "Placed", Dimensions: []*model.Dimension{}},
+	}}
+	metrics, err := runCustomNamespaceDiscoveryJob(context.Background(), logging.NewNopLogger(), job, "123456789012", "us-east-1", client, 500)
+	require.EqualError(t, err, "get_metric_data: 1 of 1 GetMetricData requests failed")
+	require.Empty(t, metrics)
+
+	client = staticClient{listErr: errors.New("throttled")}
+	metrics, err = runCustomNamespaceDiscoveryJob(context.Background(), logging.NewNopLogger(), job, "123456789012", "us-east-1", client, 500)
+	require.EqualError(t, err, "get_metric_data: throttled")
+	require.Empty(t, metrics)
+}
+
+// namespacesClient records the namespaces of the ListMetrics calls.
+type namespacesClient struct {
+	staticClient
+	listed *[]string
+}
+
+func (c namespacesClient) ListMetrics(ctx context.Context, namespace string, metric *model.MetricConfig, recentlyActiveOnly bool, fn func(page []*model.Metric)) error {
+	*c.listed = append(*c.listed, namespace)
+	var page []*model.Metric
+	for _, m := range c.metrics {
+		if namespace == "" || m.Namespace == namespace {
+			page = append(page, m)
+		}
+	}
+	return c.staticClient.ListMetrics(ctx, namespace, metric, recentlyActiveOnly, func(_ []*model.Metric) { fn(page) })
+}
+
+func TestRunCustomNamespaceDiscoveryJobRefreshInterval(t *testing.T) {
+	job := model.CustomNamespaceDiscoveryJob{
+		IncludeRegex:    regexp.MustCompile("^MyCompany/"),
+		Metric:          &model.MetricConfig{Statistics: []string{"Sum"}, Period: 300, Length: 300},
+		RefreshInterval: time.Hour,
+	}
+	var listed []string
+	client := namespacesClient{listed: &listed, staticClient: staticClient{metrics: []*model.Metric{
+		{Namespace: "MyCompany/Orders", MetricName: "Placed", Dimensions: []*model.Dimension{}},
+		{Namespace: "MyCompany/Payments", MetricName: "Settled", Dimensions: []*model.Dimension{}},
+		{Namespace: "OtherCompany/Orders", MetricName: "Placed", Dimensions: []*model.Dimension{}},
+	}}}
+
+	// The first scrape discovers the namespaces, the next ones list the discovered namespaces
+	for i := 0; i < 2; i++ {
+		_, err := runCustomNamespaceDiscoveryJob(context.Background(), logging.NewNopLogger(), job, "123456789012", "eu-west-1", client, 500)
+		require.Error(t, err)
+	}
+	require.Equal(t, []string{"", "MyCompany/Orders", "MyCompany/Payments"}, listed)
+
+	// Once the refresh interval elapsed, the namespaces are discovered again
+	discoveredNamespaces.set(newCustomNamespacesKey(job, "123456789012", "eu-west-1"), nil, -time.Second)
+	listed = nil
+	_, err := runCustomNamespaceDiscoveryJob(context.Background(), logging.NewNopLogger(), job, "123456789012", "eu-west-1", client, 500)
+	require.Error(t, err)
+	require.Equal(t, []string{""}, listed)
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"math"
 	"math/rand"
 	"strings"
 	"sync"
@@ -32,7 +31,7 @@ func runDiscoveryJob(
 	clientCloudwatch cloudwatch.Client,
 	metricsPerQuery int,
 	cloudwatchConcurrency cloudwatch.ConcurrencyConfig,
-) ([]*model.TaggedResource, []*model.CloudwatchData) {
+) ([]*model.TaggedResource, []*model.CloudwatchData, *model.UnmatchedResult, error) {
 	logger.Debug("Get tagged resources")
 
 	cw := []*model.CloudwatchData{}
@@ -41,27 +40,38 @@ func runDiscoveryJob(
 	if err != nil {
 		if errors.Is(err, tagging.ErrExpectedToFindResources) {
 			logger.Error(err, "No tagged resources made it through filtering")
-		} else {
-			logger.Error(err, "Couldn't describe resources")
+			return resources, cw, nil, nil
 		}
-		return resources, cw
+		logger.Error(err, "Couldn't describe resources")
+		return resources, cw, nil, &scrapeError{reason: reasonGetResources, err: err}
 	}
 
 	if len(resources) == 0 {
 		logger.Debug("No tagged resources", "region", region, "namespace", job.Type)
 	}
 
+	if job.AddKubernetesLabels {
+		for _, resource := range resources {
+			resource.Attributes = append(resource.Attributes, resource.KubernetesTags()...)
+		}
+	}
+
 	svc := config.SupportedServices.GetService(job.Type)
-	getMetricDatas := getMetricDataForQueries(ctx, logger, job, svc, clientCloudwatch, resources)
+	getMetricDatas, skippedMetrics := getMetricDataForQueries(ctx, logger, job, svc, clientCloudwatch, resources)
+	unmatched := &model.UnmatchedResult{
+		Namespace: svc.Namespace,
+		Resources: unmatchedResources(resources, getMetricDatas),
+		Metrics:   skippedMetrics,
+	}
 	metricDataLength := len(getMetricDatas)
 	if metricDataLength == 0 {
 		logger.Info("No metrics data found")
-		return resources, cw
+		return resources, cw, unmatched, nil
 	}
 
-	maxMetricCount := metricsPerQuery
 	length := getMetricDataInputLength(job.Metrics)
-	partitionSize := int(math.Ceil(float64(metricDataLength) / float64(maxMetricCount)))
+	partitions := partitionGetMetricData(getMetricDatas, metricsPerQuery, job.RoundingPeriod)
+	partitionSize := len(partitions)
 	logger.Debug("GetMetricData partitions", "size", partitionSize)
 
 	g, _ := errgroup.WithContext(ctx)
@@ -71,32 +81,30 @@ func runDiscoveryJob(
 	mu := sync.Mutex{}
 	getMetricDataOutput := make([][]cloudwatch.MetricDataResult, 0, partitionSize)
 	count := 0
+	failed := 0
 
 	var addHistoricalMetrics bool
 	if job.AddHistoricalMetrics != nil {
 		addHistoricalMetrics = *job.AddHistoricalMetrics
 	}
 
-	for i := 0; i < metricDataLength; i += maxMetricCount {
-		start := i
-		end := i + maxMetricCount
-		if end > metricDataLength {
-			end = metricDataLength
-		}
+	for _, partition := range partitions {
 		partitionNum := count
 		count++
 
 		g.Go(func() error {
-			logger.Debug("GetMetricData partition", "start", start, "end", end, "partitionNum", partitionNum)
+			logger.Debug("GetMetricData partition", "size", len(partition.data), "partitionNum", partitionNum)
 
-			input := getMetricDatas[start:end]
-			data := clientCloudwatch.GetMetricData(ctx, logger, input, svc.Namespace, length, job.Delay, job.RoundingPeriod, addHistoricalMetrics)
+			data := clientCloudwatch.GetMetricData(ctx, logger, partition.data, svc.Namespace, length, job.Delay, partition.roundingPeriod, addHistoricalMetrics)
 			if data != nil {
 				mu.Lock()
 				getMetricDataOutput = append(getMetricDataOutput, data)
 				mu.Unlock()
 			} else {
-				logger.Warn("GetMetricData partition empty result", "start", start, "end", end, "partitionNum", partitionNum)
+				logger.Warn("GetMetricData partition empty result", "size", len(partition.data), "partitionNum", partitionNum)
+				mu.Lock()
+				failed++
+				mu.Unlock()
 			}
 
 			return nil
@@ -105,7 +113,7 @@ func runDiscoveryJob(
 
 	if err = g.Wait(); err != nil {
 		logger.Error(err, "GetMetricData work group error")
-		return nil, nil
+		return nil, nil, unmatched, &scrapeError{reason: reasonGetMetricData, err: err}
 	}
 
 	mapResultsToMetricDatas(getMetricDataOutput, getMetricDatas, getMetricDatas, addHistoricalMetrics, logger)
@@ -115,7 +123,11 @@ func runDiscoveryJob(
 	getMetricDatas = compact(getMetricDatas, func(m *model.CloudwatchData) bool {
 		return m.MetricID == nil
 	})
-	return resources, getMetricDatas
+	if failed > 0 {
+		// The clients only return no result when the request failed
+		return resources, getMetricDatas, unmatched, &scrapeError{reason: reasonGetMetricData, err: fmt.Errorf("%d of %d GetMetricData requests failed", failed, partitionSize)}
+	}
+	return resources, getMetricDatas, unmatched, nil
 }
 
 // mapResultsToMetricDatas walks over all CW GetMetricData results, and map each one with the corresponding model.CloudwatchData.
@@ -201,6 +213,8 @@ func getMetricDataInputLength(metrics []*model.MetricConfig) int64 {
 	return length
 }
 
+// getMetricDataForQueries returns the queries of the metrics of the resources,
+// and the metrics skipped for not matching any resource.
 func getMetricDataForQueries(
 	ctx context.Context,
 	logger logging.Logger,
@@ -208,17 +222,18 @@ func getMetricDataForQueries(
 	svc *config.ServiceConfig,
 	clientCloudwatch cloudwatch.Client,
 	resources []*model.TaggedResource,
-) []*model.CloudwatchData {
+) ([]*model.CloudwatchData, []*model.Metric) {
 	mux := &sync.Mutex{}
 	var getMetricDatas []*model.CloudwatchData
 
 	var assoc resourceAssociator
-	if len(svc.DimensionRegexps) > 0 && len(resources) > 0 {
+	if len(discoveryJob.DimensionsRegexps) > 0 && len(resources) > 0 {
 		assoc = maxdimassociator.NewAssociator(logger, discoveryJob.DimensionsRegexps, resources)
 	} else {
 		// If we don't have dimension regex's and resources there's nothing to associate but metrics shouldn't be skipped
 		assoc = nopAssociator{}
 	}
+	recorder := &recordingAssociator{resourceAssociator: assoc}
 
 	var wg sync.WaitGroup
 	wg.Add(len(discoveryJob.Metrics))
@@ -228,6 +243,11 @@ func getMetricDataForQueries(
 		addHistoricalMetrics = *discoveryJob.AddHistoricalMetrics
 	}
 
+	var shards *kinesisShardFilter
+	if svc.Namespace == "AWS/Kinesis" {
+		shards = newKinesisShardFilter(discoveryJob.IncludeShardLevel, discoveryJob.MaxShards)
+	}
+
 	// For every metric of the job call the ListMetrics API
 	// to fetch the existing combinations of dimensions and
 	// value of dimensions with data.
@@ -236,7 +256,35 @@ func getMetricDataForQueries(
 			defer wg.Done()
 
 			err := clientCloudwatch.ListMetrics(ctx, svc.Namespace, metric, discoveryJob.RecentlyActiveOnly, func(page []*model.Metric) {
-				data := getFilteredMetricDatas(logger, discoveryJob.Type, discoveryJob.ExportedTagsOnMetrics, page, discoveryJob.DimensionNameRequirements, addHistoricalMetrics, metric, assoc)
+				if discoveryJob.LambdaResourceMode != "" {
+					page = filterLambdaMetrics(discoveryJob.LambdaResourceMode, page)
+				}
+				if discoveryJob.APIGatewayGranularity != "" {
+					page = filterAPIGatewayMetrics(discoveryJob.APIGatewayGranularity, page)
+				}
+				if discoveryJob.ExpandElastiCacheNodes {
+					page = filterElastiCacheNodeMetrics(page)
+				}
+				if len(discoveryJob.KafkaTopics) > 0 {
+					page = filterKafkaTopicMetrics(discoveryJob.KafkaTopics, page)
+				}
+				if len(discoveryJob.WAFRules) > 0 {
+					page = filterWAFRuleMetrics(discoveryJob.WAFRules, page)
+				}
+				if len(discoveryJob.CloudFrontBreakdowns) > 0 {
+					page = filterCloudFrontMetrics(discoveryJob.CloudFrontBreakdowns, page)
+				}
+				if shards != nil {
+					page = shards.filter(page)
+				}
+
+				data := getFilteredMetricDatas(logger, discoveryJob.Type, discoveryJob.ExportedTagsOnMetrics, page, discoveryJob.DimensionNameRequirements, addHistoricalMetrics, metric, recorder)
+				if discoveryJob.LambdaResourceMode == model.LambdaResourceModeAlias {
+					addLambdaAliasAttribute(data)
+				}
+				if discoveryJob.ExpandElastiCacheNodes {
+					addElastiCacheNodeAttribute(data)
+				}
 
 				mux.Lock()
 				getMetricDatas = append(getMetricDatas, data...)
@@ -250,7 +298,7 @@ func getMetricDataForQueries(
 	}
 
 	wg.Wait()
-	return getMetricDatas
+	return getMetricDatas, recorder.skipped
 }
 
 type nopAssociator struct{}
@@ -262,7 +310,7 @@ func (ns nopAssociator) AssociateMetricToResource(_ *model.Metric) (*model.Tagge
 func getFilteredMetricDatas(
 	logger logging.Logger,
 	namespace string,
-	tagsOnMetrics []string,
+	tagsOnMetrics []model.ExportedTag,
 	metricsList []*model.Metric,
 	dimensionNameList []string,
 	addHistoricalMetrics bool,
@@ -309,8 +357,16 @@ func getFilteredMetricDatas(
 				AddCloudwatchTimestamp: m.AddCloudwatchTimestamp,
 				AddHistoricalMetrics:   &addHistoricalMetrics,
 				Tags:                   metricTags,
+				Attributes:             resource.Attributes,
 				Dimensions:             cwMetric.Dimensions,
 				Period:                 m.Period,
+				RoundingPeriod:         m.WindowRoundingPeriod(),
+				CompletePeriodsOnly:    m.CompletePeriodsOnly,
+				SkipIncompletePeriod:   m.SkipIncompletePeriod,
+				PublishDelay:           config.SupportedServices.PublishDelay(namespace),
+				StatLabelMode:          m.StatLabelMode,
+				HistogramBuckets:       m.HistogramBuckets,
+				ResourceTags:           resource.Tags,
 			})
 		}
 	}
@@ -22,7 +22,7 @@ func Test_getFilteredMetricDatas(t *testing.T) {
 		accountID                 string
 		namespace                 string
 		customTags                []model.Tag
-		tagsOnMetrics             []string
+		tagsOnMetrics             []model.ExportedTag
 		dimensionRegexps          []model.DimensionsRegexp
 		dimensionNameRequirements []string
 		resources                 []*model.TaggedResource
@@ -41,9 +41,9 @@ func Test_getFilteredMetricDatas(t *testing.T) {
 				accountID:  "123123123123",
 				namespace:  "efs",
 				customTags: nil,
-				tagsOnMetrics: []string{
-					"Value1",
-					"Value2",
+				tagsOnMetrics: []model.ExportedTag{
+					{Key: "Value1"},
+					{Key: "Value2"},
 				},
 				dimensionRegexps: config.SupportedServices.GetService("AWS/EFS").ToModelDimensionsRegexp(),
 				resources: []*model.TaggedResource{
@@ -129,9 +129,9 @@ func Test_getFilteredMetricDatas(t *testing.T) {
 				accountID:  "123123123123",
 				namespace:  "ec2",
 				customTags: nil,
-				tagsOnMetrics: []string{
-					"Value1",
-					"Value2",
+				tagsOnMetrics: []model.ExportedTag{
+					{Key: "Value1"},
+					{Key: "Value2"},
 				},
 				dimensionRegexps: config.SupportedServices.GetService("AWS/EC2").ToModelDimensionsRegexp(),
 				resources: []*model.TaggedResource{
@@ -209,9 +209,9 @@ func Test_getFilteredMetricDatas(t *testing.T) {
 				accountID:  "123123123123",
 				namespace:  "kafka",
 				customTags: nil,
-				tagsOnMetrics: []string{
-					"Value1",
-					"Value2",
+				tagsOnMetrics: []model.ExportedTag{
+					{Key: "Value1"},
+					{Key: "Value2"},
 				},
 				dimensionRegexps: config.SupportedServices.GetService("AWS/Kafka").ToModelDimensionsRegexp(),
 				resources: []*model.TaggedResource{
@@ -0,0 +1,39 @@
+package job
+
+import (
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+const elastiCacheNodeDimension = "CacheNodeId"
+
+// filterElastiCacheNodeMetrics only keeps the AWS/ElastiCache node level metrics,
+// i.e. the ones with a CacheNodeId dimension in addition to the CacheClusterId one.
+func filterElastiCacheNodeMetrics(metrics []*model.Metric) []*model.Metric {
+	filtered := make([]*model.Metric, 0, len(metrics))
+	for _, metric := range metrics {
+		if elastiCacheNodeID(metric.Dimensions) != "" {
+			filtered = append(filtered, metric)
+		}
+	}
+	return filtered
+}
+
+// addElastiCacheNodeAttribute adds the node attribute to the given data,
+// taken from the CacheNodeId dimension.
+func addElastiCacheNodeAttribute(datas []*model.CloudwatchData) {
+	for _, data := range datas {
+		// Copy the attributes since they are shared between all series of the same resource
+		attributes := make([]model.Tag, 0, len(data.Attributes)+1)
+		attributes = append(attributes, data.Attributes...)
+		data.Attributes = append(attributes, model.Tag{Key: "node", Value: elastiCacheNodeID(data.Dimensions)})
+	}
+}
+
+func elastiCacheNodeID(dimensions []*model.Dimension) string {
+	for _, dimension := range dimensions {
+		if dimension.Name == elastiCacheNodeDimension {
+			return dimension.Value
+		}
+	}
+	return ""
+}
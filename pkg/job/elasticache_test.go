@@ -0,0 +1,55 @@
+package job
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+func TestFilterElastiCacheNodeMetrics(t *testing.T) {
+	clusterMetric := &model.Metric{
+		MetricName: "CPUUtilization",
+		Namespace:  "AWS/ElastiCache",
+		Dimensions: []*model.Dimension{
+			{Name: "CacheClusterId", Value: "my-memcached"},
+		},
+	}
+	nodeMetric := &model.Metric{
+		MetricName: "CPUUtilization",
+		Namespace:  "AWS/ElastiCache",
+		Dimensions: []*model.Dimension{
+			{Name: "CacheClusterId", Value: "my-memcached"},
+			{Name: "CacheNodeId", Value: "0001"},
+		},
+	}
+
+	require.Equal(t, []*model.Metric{nodeMetric}, filterElastiCacheNodeMetrics([]*model.Metric{clusterMetric, nodeMetric}))
+}
+
+func TestAddElastiCacheNodeAttribute(t *testing.T) {
+	resourceAttributes := []model.Tag{{Key: "engine", Value: "memcached"}}
+	datas := []*model.CloudwatchData{
+		{
+			Dimensions: []*model.Dimension{
+				{Name: "CacheClusterId", Value: "my-memcached"},
+				{Name: "CacheNodeId", Value: "0001"},
+			},
+			Attributes: resourceAttributes,
+		},
+		{
+			Dimensions: []*model.Dimension{
+				{Name: "CacheClusterId", Value: "my-memcached"},
+				{Name: "CacheNodeId", Value: "0002"},
+			},
+			Attributes: resourceAttributes,
+		},
+	}
+
+	addElastiCacheNodeAttribute(datas)
+
+	require.Equal(t, []model.Tag{{Key: "engine", Value: "memcached"}, {Key: "node", Value: "0001"}}, datas[0].Attributes)
+	require.Equal(t, []model.Tag{{Key: "engine", Value: "memcached"}, {Key: "node", Value: "0002"}}, datas[1].Attributes)
+	require.Equal(t, []model.Tag{{Key: "engine", Value: "memcached"}}, resourceAttributes)
+}
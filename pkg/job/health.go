@@ -0,0 +1,146 @@
+package job
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/arn"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/promutil"
+)
+
+// Steps of a scrape reported as reason of the yace_account_last_error_timestamp_seconds metric
+const (
+	reasonGetAccount      = "get_account"
+	reasonGetResources    = "get_resources"
+	reasonGetMetricData   = "get_metric_data"
+	reasonGetCostAndUsage = "get_cost_and_usage"
+)
+
+// scrapeError is the error of a step of the scrape of an account.
+type scrapeError struct {
+	reason string
+	err    error
+}
+
+func (e *scrapeError) Error() string {
+	return e.reason + ": " + e.err.Error()
+}
+
+func (e *scrapeError) Unwrap() error {
+	return e.err
+}
+
+type accountRegion struct {
+	accountID string
+	region    string
+}
+
+// accountHealth collects the outcome of the jobs of a scrape per account and region.
+// The account and region are up when all their jobs succeeded.
+type accountHealth struct {
+	mu     sync.Mutex
+	errors map[accountRegion]error
+}
+
+func newAccountHealth() *accountHealth {
+	return &accountHealth{errors: map[accountRegion]error{}}
+}
+
+// record adds the outcome of a job, err being nil on success. When the account
+// cannot be retrieved, the account of the role is used instead.
+func (h *accountHealth) record(accountID string, role model.Role, region string, err error) {
+	if accountID == "" {
+		accountID = roleAccountID(role)
+	}
+	key := accountRegion{accountID: accountID, region: region}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if previous, ok := h.errors[key]; !ok || previous == nil {
+		h.errors[key] = err
+	}
+}
+
+// report sets the health metrics of the accounts and regions of the scrape.
+func (h *accountHealth) report(now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for key, err := range h.errors {
+		reason, code := errorReason(err)
+		promutil.ObserveAccountScrape(key.accountID, key.region, reason, code, now)
+	}
+}
+
+// jobOutcomes collects the outcomes of the discovery, static and custom namespace jobs of a scrape.
+type jobOutcomes struct {
+	mu       sync.Mutex
+	outcomes []model.JobOutcome
+}
+
+// record adds the outcome of a job in a region with a role, err being nil on success. When
+// the account cannot be retrieved, the account of the role is used instead.
+func (o *jobOutcomes) record(job string, unhealthyAfter time.Duration, accountID string, role model.Role, region string, err error) {
+	if accountID == "" {
+		accountID = roleAccountID(role)
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.outcomes = append(o.outcomes, model.JobOutcome{
+		Job:            job,
+		Region:         region,
+		RoleArn:        role.RoleArn,
+		AccountID:      accountID,
+		Err:            err,
+		UnhealthyAfter: unhealthyAfter,
+	})
+}
+
+// recordPaused adds the outcome of a job in a region with a role which was not
+// scraped, being outside of its schedule.
+func (o *jobOutcomes) recordPaused(job string, unhealthyAfter time.Duration, role model.Role, region string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.outcomes = append(o.outcomes, model.JobOutcome{
+		Job:            job,
+		Region:         region,
+		RoleArn:        role.RoleArn,
+		AccountID:      roleAccountID(role),
+		Paused:         true,
+		UnhealthyAfter: unhealthyAfter,
+	})
+}
+
+// roleAccountID returns the account of the role ARN, empty for the current IAM role.
+func roleAccountID(role model.Role) string {
+	parsed, err := arn.Parse(role.RoleArn)
+	if err != nil {
+		return ""
+	}
+	return parsed.AccountID
+}
+
+// errorReason returns the failing step and the AWS error code of err, if any.
+func errorReason(err error) (string, string) {
+	if err == nil {
+		return "", ""
+	}
+	reason := "unknown"
+	var scrapeErr *scrapeError
+	if errors.As(err, &scrapeErr) {
+		reason = scrapeErr.reason
+	}
+
+	// Error codes of the SDK v1 and of the SDK v2
+	var v1Err interface{ Code() string }
+	if errors.As(err, &v1Err) {
+		return reason, v1Err.Code()
+	}
+	var v2Err interface{ ErrorCode() string }
+	if errors.As(err, &v2Err) {
+		return reason, v2Err.ErrorCode()
+	}
+	return reason, ""
+}
@@ -0,0 +1,84 @@
+package job
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/promutil"
+)
+
+type codeError struct{ code string }
+
+func (e codeError) Error() string { return e.code }
+func (e codeError) Code() string  { return e.code }
+
+func TestErrorReason(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		err    error
+		reason string
+		code   string
+	}{
+		{name: "success"},
+		{name: "unknown step", err: errors.New("boom"), reason: "unknown"},
+		{name: "step without code", err: &scrapeError{reason: reasonGetMetricData, err: errors.New("boom")}, reason: reasonGetMetricData},
+		{name: "wrapped code", err: &scrapeError{reason: reasonGetAccount, err: fmt.Errorf("assume role: %w", codeError{code: "AccessDenied"})}, reason: reasonGetAccount, code: "AccessDenied"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			reason, code := errorReason(tc.err)
+			require.Equal(t, tc.reason, reason)
+			require.Equal(t, tc.code, code)
+		})
+	}
+}
+
+func TestAccountHealth(t *testing.T) {
+	promutil.AccountUpGauge.Reset()
+	promutil.AccountLastSuccessGauge.Reset()
+	promutil.AccountLastErrorGauge.Reset()
+
+	broken := model.Role{RoleArn: "arn:aws:iam::222222222222:role/yace"}
+	health := newAccountHealth()
+	health.record("111111111111", model.Role{}, "eu-west-1", nil)
+	health.record("111111111111", model.Role{}, "us-east-1", nil)
+	health.record("111111111111", model.Role{}, "us-east-1", &scrapeError{reason: reasonGetResources, err: codeError{code: "AccessDeniedException"}})
+	health.record("111111111111", model.Role{}, "us-east-1", nil)
+	health.record("", broken, "eu-west-1", &scrapeError{reason: reasonGetAccount, err: codeError{code: "AccessDenied"}})
+	health.report(time.Unix(1700000000, 0))
+
+	require.NoError(t, testutil.CollectAndCompare(promutil.AccountUpGauge, strings.NewReader(`
+# HELP yace_account_up Whether the last scrape of the account and region succeeded.
+# TYPE yace_account_up gauge
+yace_account_up{account_id="111111111111",region="eu-west-1"} 1
+yace_account_up{account_id="111111111111",region="us-east-1"} 0
+yace_account_up{account_id="222222222222",region="eu-west-1"} 0
+`)))
+	require.NoError(t, testutil.CollectAndCompare(promutil.AccountLastErrorGauge, strings.NewReader(`
+# HELP yace_account_last_error_timestamp_seconds Timestamp of the last failed scrape of the account and region, with the failing step and the AWS error code.
+# TYPE yace_account_last_error_timestamp_seconds gauge
+yace_account_last_error_timestamp_seconds{account_id="111111111111",code="AccessDeniedException",reason="get_resources",region="us-east-1"} 1.7e+09
+yace_account_last_error_timestamp_seconds{account_id="222222222222",code="AccessDenied",reason="get_account",region="eu-west-1"} 1.7e+09
+`)))
+
+	// A new error of the account replaces the previous one, a success keeps it
+	health = newAccountHealth()
+	health.record("111111111111", model.Role{}, "us-east-1", &scrapeError{reason: reasonGetMetricData, err: errors.New("1 of 2 GetMetricData requests failed")})
+	health.record("222222222222", broken, "eu-west-1", nil)
+	health.report(time.Unix(1700000060, 0))
+
+	require.NoError(t, testutil.CollectAndCompare(promutil.AccountLastErrorGauge, strings.NewReader(`
+# HELP yace_account_last_error_timestamp_seconds Timestamp of the last failed scrape of the account and region, with the failing step and the AWS error code.
+# TYPE yace_account_last_error_timestamp_seconds gauge
+yace_account_last_error_timestamp_seconds{account_id="111111111111",code="",reason="get_metric_data",region="us-east-1"} 1.70000006e+09
+yace_account_last_error_timestamp_seconds{account_id="222222222222",code="AccessDenied",reason="get_account",region="eu-west-1"} 1.7e+09
+`)))
+	require.Equal(t, float64(1), testutil.ToFloat64(promutil.AccountUpGauge.WithLabelValues("222222222222", "eu-west-1")))
+	require.Equal(t, float64(1700000060), testutil.ToFloat64(promutil.AccountLastSuccessGauge.WithLabelValues("222222222222", "eu-west-1")))
+}
@@ -0,0 +1,93 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/retries"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/tagging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/useragent"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+// ScrapeInventory discovers the resources of the inventory jobs. Unlike
+// discovery jobs, no CloudWatch API is queried.
+func ScrapeInventory(
+	ctx context.Context,
+	logger logging.Logger,
+	jobsCfg model.JobsConfig,
+	factory clients.Factory,
+	taggingAPIConcurrency int,
+) []model.TaggedResourceResult {
+	mux := &sync.Mutex{}
+	inventoryData := make([]model.TaggedResourceResult, 0)
+	var wg sync.WaitGroup
+
+	for _, inventoryJob := range jobsCfg.InventoryJobs {
+		for _, role := range inventoryJob.Roles {
+			for _, region := range inventoryJob.Regions {
+				wg.Add(1)
+				go func(inventoryJob model.InventoryJob, region string, role model.Role) {
+					defer wg.Done()
+					ctx := useragent.WithJob(ctx, "inventory")
+					jobLogger := logger.With("inventory", true, "region", region, "arn", role.RoleArn)
+					accountID, err := factory.GetAccountClient(region, role).GetAccount(ctx)
+					if err != nil {
+						jobLogger.Error(err, "Couldn't get account Id")
+						return
+					}
+					jobLogger = jobLogger.With("account", accountID)
+					ctx = retries.WithAccount(ctx, accountID)
+
+					resources := runInventoryJob(ctx, jobLogger, inventoryJob, region, factory.GetTaggingClient(region, role, taggingAPIConcurrency))
+					if len(resources) == 0 {
+						return
+					}
+
+					mux.Lock()
+					inventoryData = append(inventoryData, model.TaggedResourceResult{
+						Context: &model.ScrapeContext{
+							Region:     region,
+							AccountID:  accountID,
+							CustomTags: inventoryJob.CustomTags,
+						},
+						Data: resources,
+					})
+					mux.Unlock()
+				}(inventoryJob, region, role)
+			}
+		}
+	}
+	wg.Wait()
+	return inventoryData
+}
+
+func runInventoryJob(
+	ctx context.Context,
+	logger logging.Logger,
+	job model.InventoryJob,
+	region string,
+	clientTag tagging.Client,
+) []*model.TaggedResource {
+	resources := []*model.TaggedResource{}
+	for _, namespace := range job.Namespaces {
+		discoveryJob := model.DiscoveryJob{
+			Type:       namespace,
+			SearchTags: job.SearchTags,
+		}
+		namespaceResources, err := clientTag.GetResources(ctx, discoveryJob, region)
+		if err != nil {
+			if errors.Is(err, tagging.ErrExpectedToFindResources) {
+				logger.Debug("No tagged resources", "namespace", namespace)
+			} else {
+				logger.Error(err, "Couldn't describe resources", "namespace", namespace)
+			}
+			continue
+		}
+		resources = append(resources, namespaceResources...)
+	}
+	return resources
+}
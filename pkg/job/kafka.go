@@ -0,0 +1,34 @@
+package job
+
+import (
+	"slices"
+
+	"github.com/grafana/regexp"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+const kafkaTopicDimension = "Topic"
+
+// filterKafkaTopicMetrics drops the AWS/Kafka topic level metrics whose
+// Topic dimension does not match any of the given regexps. Metrics without
+// a Topic dimension are always kept.
+func filterKafkaTopicMetrics(topics []*regexp.Regexp, metrics []*model.Metric) []*model.Metric {
+	filtered := make([]*model.Metric, 0, len(metrics))
+	for _, metric := range metrics {
+		if topic, ok := kafkaTopic(metric); ok && !slices.ContainsFunc(topics, func(r *regexp.Regexp) bool { return r.MatchString(topic) }) {
+			continue
+		}
+		filtered = append(filtered, metric)
+	}
+	return filtered
+}
+
+func kafkaTopic(metric *model.Metric) (string, bool) {
+	for _, dimension := range metric.Dimensions {
+		if dimension.Name == kafkaTopicDimension {
+			return dimension.Value, true
+		}
+	}
+	return "", false
+}
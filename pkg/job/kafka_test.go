@@ -0,0 +1,52 @@
+package job
+
+import (
+	"testing"
+
+	"github.com/grafana/regexp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+func TestFilterKafkaTopicMetrics(t *testing.T) {
+	clusterMetric := &model.Metric{
+		MetricName: "GlobalTopicCount",
+		Namespace:  "AWS/Kafka",
+		Dimensions: []*model.Dimension{
+			{Name: "Cluster Name", Value: "my-cluster"},
+		},
+	}
+	brokerMetric := &model.Metric{
+		MetricName: "BytesInPerSec",
+		Namespace:  "AWS/Kafka",
+		Dimensions: []*model.Dimension{
+			{Name: "Cluster Name", Value: "my-cluster"},
+			{Name: "Broker ID", Value: "1"},
+		},
+	}
+	ordersTopicMetric := &model.Metric{
+		MetricName: "BytesInPerSec",
+		Namespace:  "AWS/Kafka",
+		Dimensions: []*model.Dimension{
+			{Name: "Cluster Name", Value: "my-cluster"},
+			{Name: "Broker ID", Value: "1"},
+			{Name: "Topic", Value: "orders"},
+		},
+	}
+	internalTopicMetric := &model.Metric{
+		MetricName: "BytesInPerSec",
+		Namespace:  "AWS/Kafka",
+		Dimensions: []*model.Dimension{
+			{Name: "Cluster Name", Value: "my-cluster"},
+			{Name: "Broker ID", Value: "1"},
+			{Name: "Topic", Value: "__consumer_offsets"},
+		},
+	}
+	metrics := []*model.Metric{clusterMetric, brokerMetric, ordersTopicMetric, internalTopicMetric}
+
+	require.Equal(t,
+		[]*model.Metric{clusterMetric, brokerMetric, ordersTopicMetric},
+		filterKafkaTopicMetrics([]*regexp.Regexp{regexp.MustCompile("^[^_]")}, metrics),
+	)
+}
@@ -0,0 +1,75 @@
+package job
+
+import (
+	"sync"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+const (
+	kinesisStreamDimension = "StreamName"
+	kinesisShardDimension  = "ShardId"
+)
+
+// kinesisShardFilter filters the AWS/Kinesis shard level metrics, i.e. the ones
+// with a ShardId dimension in addition to the StreamName one. They are dropped
+// unless included, and then capped to maxShards shards per stream, picked in
+// the order ListMetrics returns them. The picked shards are shared by all the
+// metrics of the job, so that the same shards are exported for every metric.
+// Metrics without a ShardId dimension are always kept.
+type kinesisShardFilter struct {
+	includeShardLevel bool
+	maxShards         int
+
+	mu     sync.Mutex
+	shards map[string]map[string]struct{}
+}
+
+func newKinesisShardFilter(includeShardLevel bool, maxShards int) *kinesisShardFilter {
+	return &kinesisShardFilter{
+		includeShardLevel: includeShardLevel,
+		maxShards:         maxShards,
+		shards:            make(map[string]map[string]struct{}),
+	}
+}
+
+func (f *kinesisShardFilter) filter(metrics []*model.Metric) []*model.Metric {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	filtered := make([]*model.Metric, 0, len(metrics))
+	for _, metric := range metrics {
+		shard := kinesisDimension(metric.Dimensions, kinesisShardDimension)
+		if shard == "" {
+			filtered = append(filtered, metric)
+			continue
+		}
+		if !f.includeShardLevel {
+			continue
+		}
+
+		stream := kinesisDimension(metric.Dimensions, kinesisStreamDimension)
+		streamShards, ok := f.shards[stream]
+		if !ok {
+			streamShards = make(map[string]struct{})
+			f.shards[stream] = streamShards
+		}
+		if _, ok := streamShards[shard]; !ok {
+			if len(streamShards) >= f.maxShards {
+				continue
+			}
+			streamShards[shard] = struct{}{}
+		}
+		filtered = append(filtered, metric)
+	}
+	return filtered
+}
+
+func kinesisDimension(dimensions []*model.Dimension, name string) string {
+	for _, dimension := range dimensions {
+		if dimension.Name == name {
+			return dimension.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,63 @@
+package job
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+func TestKinesisShardFilter(t *testing.T) {
+	streamMetric := &model.Metric{
+		MetricName: "IncomingBytes",
+		Namespace:  "AWS/Kinesis",
+		Dimensions: []*model.Dimension{
+			{Name: "StreamName", Value: "orders"},
+		},
+	}
+	shardMetric := func(stream, shard string) *model.Metric {
+		return &model.Metric{
+			MetricName: "IncomingBytes",
+			Namespace:  "AWS/Kinesis",
+			Dimensions: []*model.Dimension{
+				{Name: "ShardId", Value: shard},
+				{Name: "StreamName", Value: stream},
+			},
+		}
+	}
+	ordersShard0 := shardMetric("orders", "shardId-000000000000")
+	ordersShard1 := shardMetric("orders", "shardId-000000000001")
+	ordersShard2 := shardMetric("orders", "shardId-000000000002")
+	paymentsShard0 := shardMetric("payments", "shardId-000000000000")
+
+	t.Run("shard level metrics are dropped by default", func(t *testing.T) {
+		f := newKinesisShardFilter(false, 0)
+		require.Equal(t,
+			[]*model.Metric{streamMetric},
+			f.filter([]*model.Metric{streamMetric, ordersShard0, ordersShard1, paymentsShard0}),
+		)
+	})
+
+	t.Run("shard level metrics are capped per stream", func(t *testing.T) {
+		f := newKinesisShardFilter(true, 2)
+		require.Equal(t,
+			[]*model.Metric{streamMetric, ordersShard0, ordersShard1, paymentsShard0},
+			f.filter([]*model.Metric{streamMetric, ordersShard0, ordersShard1, ordersShard2, paymentsShard0}),
+		)
+	})
+
+	t.Run("picked shards are kept across pages and metrics", func(t *testing.T) {
+		f := newKinesisShardFilter(true, 1)
+		require.Equal(t, []*model.Metric{ordersShard1}, f.filter([]*model.Metric{ordersShard1}))
+
+		ordersShard0Records := shardMetric("orders", "shardId-000000000000")
+		ordersShard0Records.MetricName = "IncomingRecords"
+		ordersShard1Records := shardMetric("orders", "shardId-000000000001")
+		ordersShard1Records.MetricName = "IncomingRecords"
+		require.Equal(t,
+			[]*model.Metric{ordersShard1Records},
+			f.filter([]*model.Metric{ordersShard0Records, ordersShard1Records}),
+		)
+	})
+}
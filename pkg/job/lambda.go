@@ -0,0 +1,78 @@
+package job
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+const (
+	lambdaResourceDimension        = "Resource"
+	lambdaExecutedVersionDimension = "ExecutedVersion"
+)
+
+// filterLambdaMetrics drops the AWS/Lambda metrics which are not exported
+// with the given model.LambdaResourceMode:
+//   - function: only metrics without the Resource dimension are kept.
+//   - alias: metrics with a Resource dimension are only kept if it refers
+//     to an alias (e.g. "my-function:live") and not to a version. Metrics
+//     with the ExecutedVersion dimension are always dropped.
+func filterLambdaMetrics(mode string, metrics []*model.Metric) []*model.Metric {
+	filtered := make([]*model.Metric, 0, len(metrics))
+	for _, metric := range metrics {
+		qualifier, hasResource := "", false
+		hasExecutedVersion := false
+		for _, dimension := range metric.Dimensions {
+			switch dimension.Name {
+			case lambdaResourceDimension:
+				hasResource = true
+				_, qualifier, _ = strings.Cut(dimension.Value, ":")
+			case lambdaExecutedVersionDimension:
+				hasExecutedVersion = true
+			}
+		}
+
+		switch mode {
+		case model.LambdaResourceModeFunction:
+			if hasResource || hasExecutedVersion {
+				continue
+			}
+		case model.LambdaResourceModeAlias:
+			if hasExecutedVersion || (hasResource && !isLambdaAlias(qualifier)) {
+				continue
+			}
+		}
+		filtered = append(filtered, metric)
+	}
+	return filtered
+}
+
+// addLambdaAliasAttribute adds the alias attribute to the given data, taken from
+// the Resource dimension. Function level series get an empty alias.
+func addLambdaAliasAttribute(datas []*model.CloudwatchData) {
+	for _, data := range datas {
+		alias := ""
+		for _, dimension := range data.Dimensions {
+			if dimension.Name == lambdaResourceDimension {
+				_, alias, _ = strings.Cut(dimension.Value, ":")
+				break
+			}
+		}
+
+		// Copy the attributes since they are shared between all series of the same resource
+		attributes := make([]model.Tag, 0, len(data.Attributes)+1)
+		attributes = append(attributes, data.Attributes...)
+		data.Attributes = append(attributes, model.Tag{Key: "alias", Value: alias})
+	}
+}
+
+// isLambdaAlias returns true if the qualifier of a function
+// refers to an alias, rather than to a (numbered or $LATEST) version.
+func isLambdaAlias(qualifier string) bool {
+	if qualifier == "" || qualifier == "$LATEST" {
+		return false
+	}
+	_, err := strconv.Atoi(qualifier)
+	return err != nil
+}
@@ -0,0 +1,101 @@
+package job
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+func TestFilterLambdaMetrics(t *testing.T) {
+	functionMetric := &model.Metric{
+		MetricName: "Invocations",
+		Namespace:  "AWS/Lambda",
+		Dimensions: []*model.Dimension{
+			{Name: "FunctionName", Value: "my-function"},
+		},
+	}
+	aliasMetric := &model.Metric{
+		MetricName: "Invocations",
+		Namespace:  "AWS/Lambda",
+		Dimensions: []*model.Dimension{
+			{Name: "FunctionName", Value: "my-function"},
+			{Name: "Resource", Value: "my-function:live"},
+		},
+	}
+	versionMetric := &model.Metric{
+		MetricName: "Invocations",
+		Namespace:  "AWS/Lambda",
+		Dimensions: []*model.Dimension{
+			{Name: "FunctionName", Value: "my-function"},
+			{Name: "Resource", Value: "my-function:42"},
+		},
+	}
+	latestMetric := &model.Metric{
+		MetricName: "Invocations",
+		Namespace:  "AWS/Lambda",
+		Dimensions: []*model.Dimension{
+			{Name: "FunctionName", Value: "my-function"},
+			{Name: "Resource", Value: "my-function:$LATEST"},
+		},
+	}
+	executedVersionMetric := &model.Metric{
+		MetricName: "Invocations",
+		Namespace:  "AWS/Lambda",
+		Dimensions: []*model.Dimension{
+			{Name: "FunctionName", Value: "my-function"},
+			{Name: "Resource", Value: "my-function:live"},
+			{Name: "ExecutedVersion", Value: "42"},
+		},
+	}
+	metrics := []*model.Metric{functionMetric, aliasMetric, versionMetric, latestMetric, executedVersionMetric}
+
+	testCases := []struct {
+		name     string
+		mode     string
+		expected []*model.Metric
+	}{
+		{
+			name:     "function mode keeps function level metrics",
+			mode:     model.LambdaResourceModeFunction,
+			expected: []*model.Metric{functionMetric},
+		},
+		{
+			name:     "alias mode keeps function and alias level metrics",
+			mode:     model.LambdaResourceModeAlias,
+			expected: []*model.Metric{functionMetric, aliasMetric},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, filterLambdaMetrics(tc.mode, metrics))
+		})
+	}
+}
+
+func TestAddLambdaAliasAttribute(t *testing.T) {
+	resourceAttributes := []model.Tag{{Key: "runtime", Value: "go1.x"}}
+	datas := []*model.CloudwatchData{
+		{
+			Dimensions: []*model.Dimension{
+				{Name: "FunctionName", Value: "my-function"},
+			},
+			Attributes: resourceAttributes,
+		},
+		{
+			Dimensions: []*model.Dimension{
+				{Name: "FunctionName", Value: "my-function"},
+				{Name: "Resource", Value: "my-function:live"},
+			},
+			Attributes: resourceAttributes,
+		},
+	}
+
+	addLambdaAliasAttribute(datas)
+
+	require.Equal(t, []model.Tag{{Key: "runtime", Value: "go1.x"}, {Key: "alias", Value: ""}}, datas[0].Attributes)
+	require.Equal(t, []model.Tag{{Key: "runtime", Value: "go1.x"}, {Key: "alias", Value: "live"}}, datas[1].Attributes)
+	require.Equal(t, []model.Tag{{Key: "runtime", Value: "go1.x"}}, resourceAttributes)
+}
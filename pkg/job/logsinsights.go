@@ -0,0 +1,102 @@
+package job
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/logsinsights"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/retries"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/useragent"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+// ScrapeLogsInsights runs the queries of the Logs Insights jobs. The results
+// are cached by the clients for the refresh interval of the jobs.
+func ScrapeLogsInsights(
+	ctx context.Context,
+	logger logging.Logger,
+	jobsCfg model.JobsConfig,
+	factory clients.Factory,
+) []model.LogsInsightsResult {
+	mux := &sync.Mutex{}
+	queryData := make([]model.LogsInsightsResult, 0)
+	var wg sync.WaitGroup
+
+	for _, logsInsightsJob := range jobsCfg.LogsInsightsJobs {
+		for _, role := range logsInsightsJob.Roles {
+			for _, region := range logsInsightsJob.Regions {
+				wg.Add(1)
+				go func(logsInsightsJob model.LogsInsightsJob, region string, role model.Role) {
+					defer wg.Done()
+					ctx := useragent.WithJob(ctx, logsInsightsJob.Name)
+					jobLogger := logger.With("logs_insights_job", logsInsightsJob.Name, "region", region, "arn", role.RoleArn)
+					accountID, err := factory.GetAccountClient(region, role).GetAccount(ctx)
+					if err != nil {
+						jobLogger.Error(err, "Couldn't get account Id")
+						return
+					}
+					jobLogger = jobLogger.With("account", accountID)
+					ctx = retries.WithAccount(ctx, accountID)
+
+					rows, err := factory.GetLogsInsightsClient(region, role).RunQuery(ctx, logsInsightsJob)
+					if err != nil {
+						jobLogger.Error(err, "Couldn't run Logs Insights query")
+						return
+					}
+					values := logsInsightsValues(logsInsightsJob, rows)
+					if len(values) == 0 {
+						return
+					}
+
+					mux.Lock()
+					queryData = append(queryData, model.LogsInsightsResult{
+						Context: &model.ScrapeContext{
+							Region:     region,
+							AccountID:  accountID,
+							CustomTags: logsInsightsJob.CustomTags,
+						},
+						Data: values,
+					})
+					mux.Unlock()
+				}(logsInsightsJob, region, role)
+			}
+		}
+	}
+	wg.Wait()
+	return queryData
+}
+
+// logsInsightsValues maps the result rows of a query to values. The numeric fields
+// of a row are its values, the other fields and the label fields are their labels.
+func logsInsightsValues(job model.LogsInsightsJob, rows [][]model.Tag) []*model.LogsInsightsValue {
+	values := make([]*model.LogsInsightsValue, 0, len(rows))
+	for _, row := range rows {
+		labels := make([]model.Tag, 0, len(row))
+		rowValues := make([]*model.LogsInsightsValue, 0, len(row))
+		for _, field := range row {
+			if field.Key == logsinsights.PtrField {
+				continue
+			}
+			if !slices.Contains(job.LabelFields, field.Key) {
+				if value, err := strconv.ParseFloat(field.Value, 64); err == nil {
+					rowValues = append(rowValues, &model.LogsInsightsValue{
+						JobName: job.Name,
+						Field:   field.Key,
+						Value:   value,
+					})
+					continue
+				}
+			}
+			labels = append(labels, field)
+		}
+		for _, value := range rowValues {
+			value.Labels = labels
+		}
+		values = append(values, rowValues...)
+	}
+	return values
+}
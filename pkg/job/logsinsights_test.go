@@ -0,0 +1,38 @@
+package job
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+func TestLogsInsightsValues(t *testing.T) {
+	job := model.LogsInsightsJob{
+		Name:        "errors",
+		LabelFields: []string{"status"},
+	}
+	rows := [][]model.Tag{
+		{
+			{Key: "service", Value: "orders"},
+			{Key: "status", Value: "500"},
+			{Key: "errors", Value: "12"},
+			{Key: "p99", Value: "0.25"},
+		},
+		{
+			{Key: "service", Value: "payments"},
+			{Key: "status", Value: "503"},
+			{Key: "errors", Value: "1"},
+			{Key: "@ptr", Value: "CmAKJgoiMTIzNDU2Nzg5MDEyOi9hd3MvbGFtYmRhL29yZGVycxAA"},
+		},
+	}
+
+	values := logsInsightsValues(job, rows)
+	ordersLabels := []model.Tag{{Key: "service", Value: "orders"}, {Key: "status", Value: "500"}}
+	require.Equal(t, []*model.LogsInsightsValue{
+		{JobName: "errors", Field: "errors", Value: 12, Labels: ordersLabels},
+		{JobName: "errors", Field: "p99", Value: 0.25, Labels: ordersLabels},
+		{JobName: "errors", Field: "errors", Value: 1, Labels: []model.Tag{{Key: "service", Value: "payments"}, {Key: "status", Value: "503"}}},
+	}, values)
+}
@@ -15,6 +15,18 @@ import (
 
 var amazonMQBrokerSuffix = regexp.MustCompile("-[0-9]+$")
 
+var sagemakerNamespaces = []string{
+	"AWS/SageMaker",
+	"/aws/sagemaker/Endpoints",
+	"/aws/sagemaker/InferenceComponents",
+}
+
+var sagemakerJobNamespaces = []string{
+	"/aws/sagemaker/TrainingJobs",
+	"/aws/sagemaker/ProcessingJobs",
+	"/aws/sagemaker/TransformJobs",
+}
+
 // Associator implements a "best effort" algorithm to automatically map the output
 // of the ListMetrics API to the list of resources retrieved from the Tagging API.
 // The core logic is based on a manually maintained list of regexes that extract
@@ -208,10 +220,18 @@ func buildLabelsMap(cwMetric *model.Metric, regexpMapping *dimensionsRegexpMappi
 				}
 			}
 
-			// AWS Sagemaker endpoint name may have upper case characters
+			// AWS Sagemaker endpoint and inference component names may have upper case characters
 			// Resource ARN is only in lower case, hence transforming
-			// endpoint name value to be able to match the resource ARN
-			if cwMetric.Namespace == "AWS/SageMaker" && name == "EndpointName" {
+			// the name value to be able to match the resource ARN
+			if slices.Contains(sagemakerNamespaces, cwMetric.Namespace) && (name == "EndpointName" || name == "InferenceComponentName") {
+				value = strings.ToLower(value)
+			}
+
+			// The "Host" dimension of Sagemaker jobs is made of the job name
+			// and of the instance name, e.g. "my-training-job/algo-1",
+			// only the job name is part of the resource ARN
+			if slices.Contains(sagemakerJobNamespaces, cwMetric.Namespace) && name == "Host" {
+				value, _, _ = strings.Cut(value, "/")
 				value = strings.ToLower(value)
 			}
 
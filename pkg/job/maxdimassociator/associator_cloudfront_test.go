@@ -0,0 +1,99 @@
+package maxdimassociator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+var cloudFrontDistribution = &model.TaggedResource{
+	ARN:       "arn:aws:cloudfront::123456789012:distribution/E1A2B3C4D5E6F7",
+	Namespace: "AWS/CloudFront",
+}
+
+var cloudFrontResources = []*model.TaggedResource{
+	cloudFrontDistribution,
+}
+
+func TestAssociatorCloudFront(t *testing.T) {
+	type args struct {
+		dimensionRegexps []model.DimensionsRegexp
+		resources        []*model.TaggedResource
+		metric           *model.Metric
+	}
+
+	type testCase struct {
+		name             string
+		args             args
+		expectedSkip     bool
+		expectedResource *model.TaggedResource
+	}
+
+	testcases := []testCase{
+		{
+			name: "should match distribution metric",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/CloudFront").ToModelDimensionsRegexp(),
+				resources:        cloudFrontResources,
+				metric: &model.Metric{
+					MetricName: "Requests",
+					Namespace:  "AWS/CloudFront",
+					Dimensions: []*model.Dimension{
+						{Name: "DistributionId", Value: "E1A2B3C4D5E6F7"},
+						{Name: "Region", Value: "Global"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: cloudFrontDistribution,
+		},
+		{
+			name: "should match breakdown metric to its distribution",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/CloudFront").ToModelDimensionsRegexp(),
+				resources:        cloudFrontResources,
+				metric: &model.Metric{
+					MetricName: "Requests",
+					Namespace:  "AWS/CloudFront",
+					Dimensions: []*model.Dimension{
+						{Name: "Country", Value: "NL"},
+						{Name: "DistributionId", Value: "E1A2B3C4D5E6F7"},
+						{Name: "Region", Value: "Global"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: cloudFrontDistribution,
+		},
+		{
+			name: "should skip metric of an undiscovered distribution",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/CloudFront").ToModelDimensionsRegexp(),
+				resources:        cloudFrontResources,
+				metric: &model.Metric{
+					MetricName: "Requests",
+					Namespace:  "AWS/CloudFront",
+					Dimensions: []*model.Dimension{
+						{Name: "DistributionId", Value: "E7F6E5D4C3B2A1"},
+						{Name: "Region", Value: "Global"},
+					},
+				},
+			},
+			expectedSkip:     true,
+			expectedResource: nil,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			associator := NewAssociator(logging.NewNopLogger(), tc.args.dimensionRegexps, tc.args.resources)
+			res, skip := associator.AssociateMetricToResource(tc.args.metric)
+			require.Equal(t, tc.expectedSkip, skip)
+			require.Equal(t, tc.expectedResource, res)
+		})
+	}
+}
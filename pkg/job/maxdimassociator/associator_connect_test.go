@@ -0,0 +1,98 @@
+package maxdimassociator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+var connectInstance = &model.TaggedResource{
+	ARN:       "arn:aws:connect:us-east-1:123456789012:instance/a1b2c3d4-5678-90ab-cdef-111111111111",
+	Namespace: "AWS/Connect",
+}
+
+var connectResources = []*model.TaggedResource{connectInstance}
+
+func TestAssociatorConnect(t *testing.T) {
+	type args struct {
+		dimensionRegexps []model.DimensionsRegexp
+		resources        []*model.TaggedResource
+		metric           *model.Metric
+	}
+
+	type testCase struct {
+		name             string
+		args             args
+		expectedSkip     bool
+		expectedResource *model.TaggedResource
+	}
+
+	testcases := []testCase{
+		{
+			name: "should match instance metric",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/Connect").ToModelDimensionsRegexp(),
+				resources:        connectResources,
+				metric: &model.Metric{
+					MetricName: "ConcurrentCalls",
+					Namespace:  "AWS/Connect",
+					Dimensions: []*model.Dimension{
+						{Name: "InstanceId", Value: "a1b2c3d4-5678-90ab-cdef-111111111111"},
+						{Name: "MetricGroup", Value: "VoiceCalls"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: connectInstance,
+		},
+		{
+			name: "should match queue metric to its instance",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/Connect").ToModelDimensionsRegexp(),
+				resources:        connectResources,
+				metric: &model.Metric{
+					MetricName: "QueueSize",
+					Namespace:  "AWS/Connect",
+					Dimensions: []*model.Dimension{
+						{Name: "InstanceId", Value: "a1b2c3d4-5678-90ab-cdef-111111111111"},
+						{Name: "MetricGroup", Value: "Queue"},
+						{Name: "QueueName", Value: "BasicQueue"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: connectInstance,
+		},
+		{
+			name: "should skip metric of another instance",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/Connect").ToModelDimensionsRegexp(),
+				resources:        connectResources,
+				metric: &model.Metric{
+					MetricName: "QueueSize",
+					Namespace:  "AWS/Connect",
+					Dimensions: []*model.Dimension{
+						{Name: "InstanceId", Value: "a1b2c3d4-5678-90ab-cdef-222222222222"},
+						{Name: "MetricGroup", Value: "Queue"},
+						{Name: "QueueName", Value: "BasicQueue"},
+					},
+				},
+			},
+			expectedSkip:     true,
+			expectedResource: nil,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			associator := NewAssociator(logging.NewNopLogger(), tc.args.dimensionRegexps, tc.args.resources)
+			res, skip := associator.AssociateMetricToResource(tc.args.metric)
+			require.Equal(t, tc.expectedSkip, skip)
+			require.Equal(t, tc.expectedResource, res)
+		})
+	}
+}
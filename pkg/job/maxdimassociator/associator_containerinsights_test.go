@@ -0,0 +1,135 @@
+package maxdimassociator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+var eksCluster = &model.TaggedResource{
+	ARN:       "arn:aws:eks:us-east-1:123456789012:cluster/production",
+	Namespace: "ContainerInsights",
+}
+
+var ecsClusterPrometheus = &model.TaggedResource{
+	ARN:       "arn:aws:ecs:us-east-1:123456789012:cluster/sampleCluster",
+	Namespace: "ContainerInsights/Prometheus",
+}
+
+func TestAssociatorContainerInsights(t *testing.T) {
+	type args struct {
+		dimensionRegexps []model.DimensionsRegexp
+		resources        []*model.TaggedResource
+		metric           *model.Metric
+	}
+
+	type testCase struct {
+		name             string
+		args             args
+		expectedSkip     bool
+		expectedResource *model.TaggedResource
+	}
+
+	testcases := []testCase{
+		{
+			name: "cluster metric should match with ClusterName dimension",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("ContainerInsights").ToModelDimensionsRegexp(),
+				resources:        []*model.TaggedResource{eksCluster},
+				metric: &model.Metric{
+					MetricName: "cluster_node_count",
+					Namespace:  "ContainerInsights",
+					Dimensions: []*model.Dimension{
+						{Name: "ClusterName", Value: "production"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: eksCluster,
+		},
+		{
+			name: "pod metric should match its cluster",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("ContainerInsights").ToModelDimensionsRegexp(),
+				resources:        []*model.TaggedResource{eksCluster},
+				metric: &model.Metric{
+					MetricName: "pod_cpu_utilization",
+					Namespace:  "ContainerInsights",
+					Dimensions: []*model.Dimension{
+						{Name: "ClusterName", Value: "production"},
+						{Name: "Namespace", Value: "default"},
+						{Name: "PodName", Value: "checkout"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: eksCluster,
+		},
+		{
+			name: "node metric should match its cluster",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("ContainerInsights").ToModelDimensionsRegexp(),
+				resources:        []*model.TaggedResource{eksCluster},
+				metric: &model.Metric{
+					MetricName: "node_memory_utilization",
+					Namespace:  "ContainerInsights",
+					Dimensions: []*model.Dimension{
+						{Name: "ClusterName", Value: "production"},
+						{Name: "InstanceId", Value: "i-0123456789abcdef0"},
+						{Name: "NodeName", Value: "ip-10-0-0-1.ec2.internal"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: eksCluster,
+		},
+		{
+			name: "should skip metric of another cluster",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("ContainerInsights").ToModelDimensionsRegexp(),
+				resources:        []*model.TaggedResource{eksCluster},
+				metric: &model.Metric{
+					MetricName: "pod_cpu_utilization",
+					Namespace:  "ContainerInsights",
+					Dimensions: []*model.Dimension{
+						{Name: "ClusterName", Value: "staging"},
+						{Name: "Namespace", Value: "default"},
+						{Name: "PodName", Value: "checkout"},
+					},
+				},
+			},
+			expectedSkip:     true,
+			expectedResource: nil,
+		},
+		{
+			name: "prometheus metric should match ECS cluster",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("ContainerInsights/Prometheus").ToModelDimensionsRegexp(),
+				resources:        []*model.TaggedResource{eksCluster, ecsClusterPrometheus},
+				metric: &model.Metric{
+					MetricName: "nginx_ingress_controller_requests",
+					Namespace:  "ContainerInsights/Prometheus",
+					Dimensions: []*model.Dimension{
+						{Name: "ClusterName", Value: "sampleCluster"},
+						{Name: "TaskDefinitionFamily", Value: "nginx"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: ecsClusterPrometheus,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			associator := NewAssociator(logging.NewNopLogger(), tc.args.dimensionRegexps, tc.args.resources)
+			res, skip := associator.AssociateMetricToResource(tc.args.metric)
+			require.Equal(t, tc.expectedSkip, skip)
+			require.Equal(t, tc.expectedResource, res)
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package maxdimassociator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+var dynamoDBOrdersTable = &model.TaggedResource{
+	ARN:       "arn:aws:dynamodb:eu-west-1:123456789012:table/orders",
+	Namespace: "AWS/DynamoDB",
+}
+
+var dynamoDBOrdersArchiveTable = &model.TaggedResource{
+	ARN:       "arn:aws:dynamodb:eu-west-1:123456789012:table/orders-archive",
+	Namespace: "AWS/DynamoDB",
+}
+
+func TestAssociatorDynamoDB(t *testing.T) {
+	type args struct {
+		dimensionRegexps []model.DimensionsRegexp
+		resources        []*model.TaggedResource
+		metric           *model.Metric
+	}
+
+	type testCase struct {
+		name             string
+		args             args
+		expectedSkip     bool
+		expectedResource *model.TaggedResource
+	}
+
+	testcases := []testCase{
+		{
+			name: "should match table metric",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/DynamoDB").ToModelDimensionsRegexp(),
+				resources:        []*model.TaggedResource{dynamoDBOrdersTable, dynamoDBOrdersArchiveTable},
+				metric: &model.Metric{
+					MetricName: "ConsumedReadCapacityUnits",
+					Namespace:  "AWS/DynamoDB",
+					Dimensions: []*model.Dimension{
+						{Name: "TableName", Value: "orders"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: dynamoDBOrdersTable,
+		},
+		{
+			name: "should match global secondary index metric to its table",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/DynamoDB").ToModelDimensionsRegexp(),
+				resources:        []*model.TaggedResource{dynamoDBOrdersTable, dynamoDBOrdersArchiveTable},
+				metric: &model.Metric{
+					MetricName: "WriteThrottleEvents",
+					Namespace:  "AWS/DynamoDB",
+					Dimensions: []*model.Dimension{
+						{Name: "TableName", Value: "orders-archive"},
+						{Name: "GlobalSecondaryIndexName", Value: "by-customer"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: dynamoDBOrdersArchiveTable,
+		},
+		{
+			name: "should match global secondary index operation metric to its table",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/DynamoDB").ToModelDimensionsRegexp(),
+				resources:        []*model.TaggedResource{dynamoDBOrdersTable},
+				metric: &model.Metric{
+					MetricName: "ThrottledRequests",
+					Namespace:  "AWS/DynamoDB",
+					Dimensions: []*model.Dimension{
+						{Name: "GlobalSecondaryIndexName", Value: "by-customer"},
+						{Name: "Operation", Value: "Query"},
+						{Name: "TableName", Value: "orders"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: dynamoDBOrdersTable,
+		},
+		{
+			name: "should match stream metric to its table",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/DynamoDB").ToModelDimensionsRegexp(),
+				resources:        []*model.TaggedResource{dynamoDBOrdersTable},
+				metric: &model.Metric{
+					MetricName: "ReturnedRecordsCount",
+					Namespace:  "AWS/DynamoDB",
+					Dimensions: []*model.Dimension{
+						{Name: "Operation", Value: "GetRecords"},
+						{Name: "StreamLabel", Value: "2024-01-01T00:00:00.000"},
+						{Name: "TableName", Value: "orders"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: dynamoDBOrdersTable,
+		},
+		{
+			name: "should skip global secondary index metric of an undiscovered table",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/DynamoDB").ToModelDimensionsRegexp(),
+				resources:        []*model.TaggedResource{dynamoDBOrdersTable},
+				metric: &model.Metric{
+					MetricName: "WriteThrottleEvents",
+					Namespace:  "AWS/DynamoDB",
+					Dimensions: []*model.Dimension{
+						{Name: "TableName", Value: "payments"},
+						{Name: "GlobalSecondaryIndexName", Value: "by-customer"},
+					},
+				},
+			},
+			expectedSkip:     true,
+			expectedResource: nil,
+		},
+		{
+			name: "should not skip account level metric",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/DynamoDB").ToModelDimensionsRegexp(),
+				resources:        []*model.TaggedResource{dynamoDBOrdersTable},
+				metric: &model.Metric{
+					MetricName: "AccountProvisionedReadCapacityUtilization",
+					Namespace:  "AWS/DynamoDB",
+					Dimensions: []*model.Dimension{},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: nil,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			associator := NewAssociator(logging.NewNopLogger(), tc.args.dimensionRegexps, tc.args.resources)
+			res, skip := associator.AssociateMetricToResource(tc.args.metric)
+			require.Equal(t, tc.expectedSkip, skip)
+			require.Equal(t, tc.expectedResource, res)
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package maxdimassociator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+var eksControlPlaneCluster = &model.TaggedResource{
+	ARN:       "arn:aws:eks:eu-west-1:123456789012:cluster/production",
+	Namespace: "AWS/EKS",
+}
+
+var eksControlPlaneResources = []*model.TaggedResource{eksControlPlaneCluster}
+
+func TestAssociatorEKS(t *testing.T) {
+	type args struct {
+		dimensionRegexps []model.DimensionsRegexp
+		resources        []*model.TaggedResource
+		metric           *model.Metric
+	}
+
+	type testCase struct {
+		name             string
+		args             args
+		expectedSkip     bool
+		expectedResource *model.TaggedResource
+	}
+
+	testcases := []testCase{
+		{
+			name: "should match with ClusterName dimension",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/EKS").ToModelDimensionsRegexp(),
+				resources:        eksControlPlaneResources,
+				metric: &model.Metric{
+					MetricName: "apiserver_request_total",
+					Namespace:  "AWS/EKS",
+					Dimensions: []*model.Dimension{
+						{Name: "ClusterName", Value: "production"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: eksControlPlaneCluster,
+		},
+		{
+			name: "should skip with unmatched ClusterName dimension",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/EKS").ToModelDimensionsRegexp(),
+				resources:        eksControlPlaneResources,
+				metric: &model.Metric{
+					MetricName: "scheduler_pending_pods",
+					Namespace:  "AWS/EKS",
+					Dimensions: []*model.Dimension{
+						{Name: "ClusterName", Value: "staging"},
+					},
+				},
+			},
+			expectedSkip:     true,
+			expectedResource: nil,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			associator := NewAssociator(logging.NewNopLogger(), tc.args.dimensionRegexps, tc.args.resources)
+			res, skip := associator.AssociateMetricToResource(tc.args.metric)
+			require.Equal(t, tc.expectedSkip, skip)
+			require.Equal(t, tc.expectedResource, res)
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package maxdimassociator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+var emrServerlessApplication = &model.TaggedResource{
+	ARN:       "arn:aws:emr-serverless:eu-west-1:123456789012:/applications/00f1a2b3c4d5e6f7",
+	Namespace: "AWS/EMRServerless",
+}
+
+var emrServerlessResources = []*model.TaggedResource{
+	emrServerlessApplication,
+}
+
+func TestAssociatorEMRServerless(t *testing.T) {
+	type args struct {
+		dimensionRegexps []model.DimensionsRegexp
+		resources        []*model.TaggedResource
+		metric           *model.Metric
+	}
+
+	type testCase struct {
+		name             string
+		args             args
+		expectedSkip     bool
+		expectedResource *model.TaggedResource
+	}
+
+	testcases := []testCase{
+		{
+			name: "should match application metric",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/EMRServerless").ToModelDimensionsRegexp(),
+				resources:        emrServerlessResources,
+				metric: &model.Metric{
+					MetricName: "RunningJobs",
+					Namespace:  "AWS/EMRServerless",
+					Dimensions: []*model.Dimension{
+						{Name: "ApplicationId", Value: "00f1a2b3c4d5e6f7"},
+						{Name: "ApplicationName", Value: "spark-etl"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: emrServerlessApplication,
+		},
+		{
+			name: "should match worker metric to its application",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/EMRServerless").ToModelDimensionsRegexp(),
+				resources:        emrServerlessResources,
+				metric: &model.Metric{
+					MetricName: "CPUAllocated",
+					Namespace:  "AWS/EMRServerless",
+					Dimensions: []*model.Dimension{
+						{Name: "ApplicationId", Value: "00f1a2b3c4d5e6f7"},
+						{Name: "ApplicationName", Value: "spark-etl"},
+						{Name: "CapacityAllocationType", Value: "OnDemandCapacity"},
+						{Name: "WorkerType", Value: "SPARK_EXECUTOR"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: emrServerlessApplication,
+		},
+		{
+			name: "should match job metric to its application",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/EMRServerless").ToModelDimensionsRegexp(),
+				resources:        emrServerlessResources,
+				metric: &model.Metric{
+					MetricName: "RunningExecutors",
+					Namespace:  "AWS/EMRServerless",
+					Dimensions: []*model.Dimension{
+						{Name: "ApplicationId", Value: "00f1a2b3c4d5e6f7"},
+						{Name: "ApplicationName", Value: "spark-etl"},
+						{Name: "JobId", Value: "00f9e8d7c6b5a4"},
+						{Name: "JobName", Value: "nightly"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: emrServerlessApplication,
+		},
+		{
+			name: "should skip metric of an undiscovered application",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/EMRServerless").ToModelDimensionsRegexp(),
+				resources:        emrServerlessResources,
+				metric: &model.Metric{
+					MetricName: "RunningJobs",
+					Namespace:  "AWS/EMRServerless",
+					Dimensions: []*model.Dimension{
+						{Name: "ApplicationId", Value: "00a9b8c7d6e5f4a3"},
+						{Name: "ApplicationName", Value: "hive-reports"},
+					},
+				},
+			},
+			expectedSkip:     true,
+			expectedResource: nil,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			associator := NewAssociator(logging.NewNopLogger(), tc.args.dimensionRegexps, tc.args.resources)
+			res, skip := associator.AssociateMetricToResource(tc.args.metric)
+			require.Equal(t, tc.expectedSkip, skip)
+			require.Equal(t, tc.expectedResource, res)
+		})
+	}
+}
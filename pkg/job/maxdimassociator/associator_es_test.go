@@ -0,0 +1,100 @@
+package maxdimassociator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+var esDomain = &model.TaggedResource{
+	ARN:       "arn:aws:es:eu-west-1:123456789012:domain/search",
+	Namespace: "AWS/ES",
+}
+
+var esResources = []*model.TaggedResource{
+	esDomain,
+}
+
+func TestAssociatorES(t *testing.T) {
+	type args struct {
+		dimensionRegexps []model.DimensionsRegexp
+		resources        []*model.TaggedResource
+		metric           *model.Metric
+	}
+
+	type testCase struct {
+		name             string
+		args             args
+		expectedSkip     bool
+		expectedResource *model.TaggedResource
+	}
+
+	testcases := []testCase{
+		{
+			name: "should match domain metric",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/ES").ToModelDimensionsRegexp(),
+				resources:        esResources,
+				metric: &model.Metric{
+					MetricName: "CPUUtilization",
+					Namespace:  "AWS/ES",
+					Dimensions: []*model.Dimension{
+						{Name: "ClientId", Value: "123456789012"},
+						{Name: "DomainName", Value: "search"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: esDomain,
+		},
+		{
+			name: "should match node level metric to its domain",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/ES").ToModelDimensionsRegexp(),
+				resources:        esResources,
+				metric: &model.Metric{
+					MetricName: "CPUUtilization",
+					Namespace:  "AWS/ES",
+					Dimensions: []*model.Dimension{
+						{Name: "ClientId", Value: "123456789012"},
+						{Name: "DomainName", Value: "search"},
+						{Name: "NodeId", Value: "kT3Wbn5lQkqzXwFJYcjA0Q"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: esDomain,
+		},
+		{
+			name: "should skip metric of an undiscovered domain",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/ES").ToModelDimensionsRegexp(),
+				resources:        esResources,
+				metric: &model.Metric{
+					MetricName: "CPUUtilization",
+					Namespace:  "AWS/ES",
+					Dimensions: []*model.Dimension{
+						{Name: "ClientId", Value: "123456789012"},
+						{Name: "DomainName", Value: "logs"},
+						{Name: "NodeId", Value: "kT3Wbn5lQkqzXwFJYcjA0Q"},
+					},
+				},
+			},
+			expectedSkip:     true,
+			expectedResource: nil,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			associator := NewAssociator(logging.NewNopLogger(), tc.args.dimensionRegexps, tc.args.resources)
+			res, skip := associator.AssociateMetricToResource(tc.args.metric)
+			require.Equal(t, tc.expectedSkip, skip)
+			require.Equal(t, tc.expectedResource, res)
+		})
+	}
+}
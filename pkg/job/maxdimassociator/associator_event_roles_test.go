@@ -15,8 +15,14 @@ var eventRule0 = &model.TaggedResource{
 	Namespace: "AWS/Events",
 }
 
+var eventRule1 = &model.TaggedResource{
+	ARN:       "arn:aws:events:eu-central-1:112246171613:rule/default-bus-rule-name",
+	Namespace: "AWS/Events",
+}
+
 var eventRuleResources = []*model.TaggedResource{
 	eventRule0,
+	eventRule1,
 }
 
 func TestAssociatorEventRule(t *testing.T) {
@@ -51,6 +57,39 @@ func TestAssociatorEventRule(t *testing.T) {
 			expectedSkip:     false,
 			expectedResource: eventRule0,
 		},
+		{
+			name: "rule of the default event bus should match",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/Events").ToModelDimensionsRegexp(),
+				resources:        eventRuleResources,
+				metric: &model.Metric{
+					MetricName: "FailedInvocations",
+					Namespace:  "AWS/Events",
+					Dimensions: []*model.Dimension{
+						{Name: "RuleName", Value: "default-bus-rule-name"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: eventRule1,
+		},
+		{
+			name: "rule of another event bus should not match",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/Events").ToModelDimensionsRegexp(),
+				resources:        eventRuleResources,
+				metric: &model.Metric{
+					MetricName: "FailedInvocations",
+					Namespace:  "AWS/Events",
+					Dimensions: []*model.Dimension{
+						{Name: "EventBusName", Value: "other-event-bus-name"},
+						{Name: "RuleName", Value: "rule-name"},
+					},
+				},
+			},
+			expectedSkip:     true,
+			expectedResource: nil,
+		},
 	}
 
 	for _, tc := range testcases {
@@ -0,0 +1,103 @@
+package maxdimassociator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+var gameLiftFleet = &model.TaggedResource{
+	ARN:       "arn:aws:gamelift:us-east-1:123456789012:fleet/fleet-2222bbbb-33cc-44dd-55ee-6666ffff77aa",
+	Namespace: "AWS/GameLift",
+}
+
+var gameLiftQueue = &model.TaggedResource{
+	ARN:       "arn:aws:gamelift:us-east-1:123456789012:gamesessionqueue/my-queue",
+	Namespace: "AWS/GameLift",
+}
+
+var gameLiftMatchmakingConfiguration = &model.TaggedResource{
+	ARN:       "arn:aws:gamelift:us-east-1:123456789012:matchmakingconfiguration/my-matchmaking",
+	Namespace: "AWS/GameLift",
+}
+
+var gameLiftResources = []*model.TaggedResource{gameLiftFleet, gameLiftQueue, gameLiftMatchmakingConfiguration}
+
+func TestAssociatorGameLift(t *testing.T) {
+	type args struct {
+		dimensionRegexps []model.DimensionsRegexp
+		resources        []*model.TaggedResource
+		metric           *model.Metric
+	}
+
+	type testCase struct {
+		name             string
+		args             args
+		expectedSkip     bool
+		expectedResource *model.TaggedResource
+	}
+
+	testcases := []testCase{
+		{
+			name: "should match fleet with FleetId dimension",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/GameLift").ToModelDimensionsRegexp(),
+				resources:        gameLiftResources,
+				metric: &model.Metric{
+					MetricName: "ActiveInstances",
+					Namespace:  "AWS/GameLift",
+					Dimensions: []*model.Dimension{
+						{Name: "FleetId", Value: "fleet-2222bbbb-33cc-44dd-55ee-6666ffff77aa"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: gameLiftFleet,
+		},
+		{
+			name: "should match game session queue with QueueName dimension",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/GameLift").ToModelDimensionsRegexp(),
+				resources:        gameLiftResources,
+				metric: &model.Metric{
+					MetricName: "QueueDepth",
+					Namespace:  "AWS/GameLift",
+					Dimensions: []*model.Dimension{
+						{Name: "QueueName", Value: "my-queue"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: gameLiftQueue,
+		},
+		{
+			name: "should match matchmaking configuration with ConfigurationName dimension",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/GameLift").ToModelDimensionsRegexp(),
+				resources:        gameLiftResources,
+				metric: &model.Metric{
+					MetricName: "PlayersStarted",
+					Namespace:  "AWS/GameLift",
+					Dimensions: []*model.Dimension{
+						{Name: "ConfigurationName", Value: "my-matchmaking"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: gameLiftMatchmakingConfiguration,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			associator := NewAssociator(logging.NewNopLogger(), tc.args.dimensionRegexps, tc.args.resources)
+			res, skip := associator.AssociateMetricToResource(tc.args.metric)
+			require.Equal(t, tc.expectedSkip, skip)
+			require.Equal(t, tc.expectedResource, res)
+		})
+	}
+}
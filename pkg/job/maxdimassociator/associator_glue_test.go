@@ -0,0 +1,120 @@
+package maxdimassociator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+var glueJob = &model.TaggedResource{
+	ARN:       "arn:aws:glue:eu-west-1:123456789012:job/daily-export",
+	Namespace: "Glue",
+}
+
+var glueResources = []*model.TaggedResource{
+	glueJob,
+}
+
+func TestAssociatorGlue(t *testing.T) {
+	type args struct {
+		dimensionRegexps []model.DimensionsRegexp
+		resources        []*model.TaggedResource
+		metric           *model.Metric
+	}
+
+	type testCase struct {
+		name             string
+		args             args
+		expectedSkip     bool
+		expectedResource *model.TaggedResource
+	}
+
+	testcases := []testCase{
+		{
+			name: "should match job level metric",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("Glue").ToModelDimensionsRegexp(),
+				resources:        glueResources,
+				metric: &model.Metric{
+					MetricName: "glue.driver.ExecutorAllocationManager.executors.numberAllExecutors",
+					Namespace:  "Glue",
+					Dimensions: []*model.Dimension{
+						{Name: "JobName", Value: "daily-export"},
+						{Name: "JobRunId", Value: "ALL"},
+						{Name: "Type", Value: "gauge"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: glueJob,
+		},
+		{
+			name: "should match job run metric to its job",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("Glue").ToModelDimensionsRegexp(),
+				resources:        glueResources,
+				metric: &model.Metric{
+					MetricName: "glue.driver.aggregate.elapsedTime",
+					Namespace:  "Glue",
+					Dimensions: []*model.Dimension{
+						{Name: "JobName", Value: "daily-export"},
+						{Name: "JobRunId", Value: "jr_0123456789abcdef"},
+						{Name: "Type", Value: "count"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: glueJob,
+		},
+		{
+			name: "should match observability metric to its job",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("Glue").ToModelDimensionsRegexp(),
+				resources:        glueResources,
+				metric: &model.Metric{
+					MetricName: "glue.driver.workerUtilization",
+					Namespace:  "Glue",
+					Dimensions: []*model.Dimension{
+						{Name: "JobName", Value: "daily-export"},
+						{Name: "JobRunId", Value: "ALL"},
+						{Name: "ObservabilityGroup", Value: "resource_utilization"},
+						{Name: "Type", Value: "gauge"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: glueJob,
+		},
+		{
+			name: "should skip metric of an undiscovered job",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("Glue").ToModelDimensionsRegexp(),
+				resources:        glueResources,
+				metric: &model.Metric{
+					MetricName: "glue.driver.aggregate.elapsedTime",
+					Namespace:  "Glue",
+					Dimensions: []*model.Dimension{
+						{Name: "JobName", Value: "hourly-import"},
+						{Name: "JobRunId", Value: "ALL"},
+						{Name: "Type", Value: "count"},
+					},
+				},
+			},
+			expectedSkip:     true,
+			expectedResource: nil,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			associator := NewAssociator(logging.NewNopLogger(), tc.args.dimensionRegexps, tc.args.resources)
+			res, skip := associator.AssociateMetricToResource(tc.args.metric)
+			require.Equal(t, tc.expectedSkip, skip)
+			require.Equal(t, tc.expectedResource, res)
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package maxdimassociator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+var ivsChannel = &model.TaggedResource{
+	ARN:       "arn:aws:ivs:us-west-2:123456789012:channel/abcdABCDefgh",
+	Namespace: "AWS/IVS",
+}
+
+var ivsResources = []*model.TaggedResource{ivsChannel}
+
+func TestAssociatorIVS(t *testing.T) {
+	type args struct {
+		dimensionRegexps []model.DimensionsRegexp
+		resources        []*model.TaggedResource
+		metric           *model.Metric
+	}
+
+	type testCase struct {
+		name             string
+		args             args
+		expectedSkip     bool
+		expectedResource *model.TaggedResource
+	}
+
+	testcases := []testCase{
+		{
+			name: "should match with Channel dimension",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/IVS").ToModelDimensionsRegexp(),
+				resources:        ivsResources,
+				metric: &model.Metric{
+					MetricName: "ConcurrentViews",
+					Namespace:  "AWS/IVS",
+					Dimensions: []*model.Dimension{
+						{Name: "Channel", Value: "abcdABCDefgh"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: ivsChannel,
+		},
+		{
+			name: "should skip with unknown Channel dimension",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/IVS").ToModelDimensionsRegexp(),
+				resources:        ivsResources,
+				metric: &model.Metric{
+					MetricName: "ConcurrentViews",
+					Namespace:  "AWS/IVS",
+					Dimensions: []*model.Dimension{
+						{Name: "Channel", Value: "ijklIJKLmnop"},
+					},
+				},
+			},
+			expectedSkip:     true,
+			expectedResource: nil,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			associator := NewAssociator(logging.NewNopLogger(), tc.args.dimensionRegexps, tc.args.resources)
+			res, skip := associator.AssociateMetricToResource(tc.args.metric)
+			require.Equal(t, tc.expectedSkip, skip)
+			require.Equal(t, tc.expectedResource, res)
+		})
+	}
+}
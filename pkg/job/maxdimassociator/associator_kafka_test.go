@@ -0,0 +1,113 @@
+package maxdimassociator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+var kafkaCluster = &model.TaggedResource{
+	ARN:       "arn:aws:kafka:us-east-1:123456789012:cluster/my-cluster/a1b2c3d4-e5f6-7890-abcd-ef1234567890-2",
+	Namespace: "AWS/Kafka",
+}
+
+var kafkaResources = []*model.TaggedResource{kafkaCluster}
+
+func TestAssociatorKafka(t *testing.T) {
+	type args struct {
+		dimensionRegexps []model.DimensionsRegexp
+		resources        []*model.TaggedResource
+		metric           *model.Metric
+	}
+
+	type testCase struct {
+		name             string
+		args             args
+		expectedSkip     bool
+		expectedResource *model.TaggedResource
+	}
+
+	testcases := []testCase{
+		{
+			name: "should match with Cluster Name dimension",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/Kafka").ToModelDimensionsRegexp(),
+				resources:        kafkaResources,
+				metric: &model.Metric{
+					MetricName: "GlobalTopicCount",
+					Namespace:  "AWS/Kafka",
+					Dimensions: []*model.Dimension{
+						{Name: "Cluster Name", Value: "my-cluster"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: kafkaCluster,
+		},
+		{
+			name: "should match broker level metric with Cluster Name and Broker ID dimensions",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/Kafka").ToModelDimensionsRegexp(),
+				resources:        kafkaResources,
+				metric: &model.Metric{
+					MetricName: "BytesInPerSec",
+					Namespace:  "AWS/Kafka",
+					Dimensions: []*model.Dimension{
+						{Name: "Cluster Name", Value: "my-cluster"},
+						{Name: "Broker ID", Value: "1"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: kafkaCluster,
+		},
+		{
+			name: "should match topic level metric with Cluster Name, Broker ID and Topic dimensions",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/Kafka").ToModelDimensionsRegexp(),
+				resources:        kafkaResources,
+				metric: &model.Metric{
+					MetricName: "BytesInPerSec",
+					Namespace:  "AWS/Kafka",
+					Dimensions: []*model.Dimension{
+						{Name: "Cluster Name", Value: "my-cluster"},
+						{Name: "Broker ID", Value: "1"},
+						{Name: "Topic", Value: "orders"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: kafkaCluster,
+		},
+		{
+			name: "should skip metric of an unknown cluster",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/Kafka").ToModelDimensionsRegexp(),
+				resources:        kafkaResources,
+				metric: &model.Metric{
+					MetricName: "BytesInPerSec",
+					Namespace:  "AWS/Kafka",
+					Dimensions: []*model.Dimension{
+						{Name: "Cluster Name", Value: "other-cluster"},
+						{Name: "Broker ID", Value: "1"},
+					},
+				},
+			},
+			expectedSkip:     true,
+			expectedResource: nil,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			associator := NewAssociator(logging.NewNopLogger(), tc.args.dimensionRegexps, tc.args.resources)
+			res, skip := associator.AssociateMetricToResource(tc.args.metric)
+			require.Equal(t, tc.expectedSkip, skip)
+			require.Equal(t, tc.expectedResource, res)
+		})
+	}
+}
@@ -106,3 +106,79 @@ func TestAssociatorLambda(t *testing.T) {
 		})
 	}
 }
+
+func TestAssociatorLambdaInsights(t *testing.T) {
+	type args struct {
+		dimensionRegexps []model.DimensionsRegexp
+		resources        []*model.TaggedResource
+		metric           *model.Metric
+	}
+
+	type testCase struct {
+		name             string
+		args             args
+		expectedSkip     bool
+		expectedResource *model.TaggedResource
+	}
+
+	testcases := []testCase{
+		{
+			name: "should match with function_name dimension",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("LambdaInsights").ToModelDimensionsRegexp(),
+				resources:        lambdaResources,
+				metric: &model.Metric{
+					MetricName: "memory_utilization",
+					Namespace:  "LambdaInsights",
+					Dimensions: []*model.Dimension{
+						{Name: "function_name", Value: "lambdaFunction"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: lambdaFunction,
+		},
+		{
+			name: "should match with function_name and version dimensions",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("LambdaInsights").ToModelDimensionsRegexp(),
+				resources:        lambdaResources,
+				metric: &model.Metric{
+					MetricName: "memory_utilization",
+					Namespace:  "LambdaInsights",
+					Dimensions: []*model.Dimension{
+						{Name: "function_name", Value: "lambdaFunction"},
+						{Name: "version", Value: "$LATEST"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: lambdaFunction,
+		},
+		{
+			name: "should skip with unmatched function_name dimension",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("LambdaInsights").ToModelDimensionsRegexp(),
+				resources:        lambdaResources,
+				metric: &model.Metric{
+					MetricName: "memory_utilization",
+					Namespace:  "LambdaInsights",
+					Dimensions: []*model.Dimension{
+						{Name: "function_name", Value: "anotherLambdaFunction"},
+					},
+				},
+			},
+			expectedSkip:     true,
+			expectedResource: nil,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			associator := NewAssociator(logging.NewNopLogger(), tc.args.dimensionRegexps, tc.args.resources)
+			res, skip := associator.AssociateMetricToResource(tc.args.metric)
+			require.Equal(t, tc.expectedSkip, skip)
+			require.Equal(t, tc.expectedResource, res)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package maxdimassociator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+var mediaPackageChannel = &model.TaggedResource{
+	ARN:       "arn:aws:mediapackagev2:us-east-1:123456789012:channelGroup/my-group/channel/my-channel",
+	Namespace: "AWS/MediaPackage",
+}
+
+var mediaPackageOriginEndpoint = &model.TaggedResource{
+	ARN:       "arn:aws:mediapackagev2:us-east-1:123456789012:channelGroup/my-group/channel/my-channel/originEndpoint/my-endpoint",
+	Namespace: "AWS/MediaPackage",
+}
+
+var mediaPackageResources = []*model.TaggedResource{mediaPackageChannel, mediaPackageOriginEndpoint}
+
+func TestAssociatorMediaPackage(t *testing.T) {
+	type args struct {
+		dimensionRegexps []model.DimensionsRegexp
+		resources        []*model.TaggedResource
+		metric           *model.Metric
+	}
+
+	type testCase struct {
+		name             string
+		args             args
+		expectedSkip     bool
+		expectedResource *model.TaggedResource
+	}
+
+	testcases := []testCase{
+		{
+			name: "should match channel with ChannelGroup and Channel dimensions",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/MediaPackage").ToModelDimensionsRegexp(),
+				resources:        mediaPackageResources,
+				metric: &model.Metric{
+					MetricName: "IngressBytes",
+					Namespace:  "AWS/MediaPackage",
+					Dimensions: []*model.Dimension{
+						{Name: "ChannelGroup", Value: "my-group"},
+						{Name: "Channel", Value: "my-channel"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: mediaPackageChannel,
+		},
+		{
+			name: "should match origin endpoint with ChannelGroup, Channel and OriginEndpoint dimensions",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/MediaPackage").ToModelDimensionsRegexp(),
+				resources:        mediaPackageResources,
+				metric: &model.Metric{
+					MetricName: "EgressBytes",
+					Namespace:  "AWS/MediaPackage",
+					Dimensions: []*model.Dimension{
+						{Name: "ChannelGroup", Value: "my-group"},
+						{Name: "Channel", Value: "my-channel"},
+						{Name: "OriginEndpoint", Value: "my-endpoint"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: mediaPackageOriginEndpoint,
+		},
+		{
+			name: "should not skip v1 channel with Channel dimension only",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/MediaPackage").ToModelDimensionsRegexp(),
+				resources:        mediaPackageResources,
+				metric: &model.Metric{
+					MetricName: "IngressBytes",
+					Namespace:  "AWS/MediaPackage",
+					Dimensions: []*model.Dimension{
+						{Name: "Channel", Value: "my-v1-channel"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: nil,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			associator := NewAssociator(logging.NewNopLogger(), tc.args.dimensionRegexps, tc.args.resources)
+			res, skip := associator.AssociateMetricToResource(tc.args.metric)
+			require.Equal(t, tc.expectedSkip, skip)
+			require.Equal(t, tc.expectedResource, res)
+		})
+	}
+}
@@ -71,6 +71,58 @@ func TestAssociatorMQ(t *testing.T) {
 			expectedSkip:     false,
 			expectedResource: activeMQBroker,
 		},
+		{
+			name: "should match ActiveMQ per queue metric to the broker",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/AmazonMQ").ToModelDimensionsRegexp(),
+				resources:        []*model.TaggedResource{rabbitMQBroker, activeMQBroker},
+				metric: &model.Metric{
+					MetricName: "QueueSize",
+					Namespace:  "AWS/AmazonMQ",
+					Dimensions: []*model.Dimension{
+						{Name: "Broker", Value: "activemq-broker"},
+						{Name: "Queue", Value: "orders"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: activeMQBroker,
+		},
+		{
+			name: "should match ActiveMQ per topic metric to the broker",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/AmazonMQ").ToModelDimensionsRegexp(),
+				resources:        []*model.TaggedResource{rabbitMQBroker, activeMQBroker},
+				metric: &model.Metric{
+					MetricName: "ConsumerCount",
+					Namespace:  "AWS/AmazonMQ",
+					Dimensions: []*model.Dimension{
+						{Name: "Broker", Value: "activemq-broker"},
+						{Name: "Topic", Value: "events"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: activeMQBroker,
+		},
+		{
+			name: "should match RabbitMQ per queue metric to the broker",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/AmazonMQ").ToModelDimensionsRegexp(),
+				resources:        []*model.TaggedResource{rabbitMQBroker, activeMQBroker},
+				metric: &model.Metric{
+					MetricName: "MessageCount",
+					Namespace:  "AWS/AmazonMQ",
+					Dimensions: []*model.Dimension{
+						{Name: "Broker", Value: "rabbitmq-broker"},
+						{Name: "Queue", Value: "orders"},
+						{Name: "VirtualHost", Value: "/"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: rabbitMQBroker,
+		},
 	}
 
 	for _, tc := range testcases {
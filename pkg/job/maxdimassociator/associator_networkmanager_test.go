@@ -0,0 +1,103 @@
+package maxdimassociator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+var networkManagerCoreNetwork = &model.TaggedResource{
+	ARN:       "arn:aws:networkmanager::123456789012:core-network/core-network-0123456789abcdef0",
+	Namespace: "AWS/NetworkManager",
+}
+
+var networkManagerAttachment = &model.TaggedResource{
+	ARN:       "arn:aws:networkmanager::123456789012:attachment/attachment-0123456789abcdef0",
+	Namespace: "AWS/NetworkManager",
+}
+
+var networkManagerResources = []*model.TaggedResource{networkManagerCoreNetwork, networkManagerAttachment}
+
+func TestAssociatorNetworkManager(t *testing.T) {
+	type args struct {
+		dimensionRegexps []model.DimensionsRegexp
+		resources        []*model.TaggedResource
+		metric           *model.Metric
+	}
+
+	type testCase struct {
+		name             string
+		args             args
+		expectedSkip     bool
+		expectedResource *model.TaggedResource
+	}
+
+	testcases := []testCase{
+		{
+			name: "should match with CoreNetwork dimension",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/NetworkManager").ToModelDimensionsRegexp(),
+				resources:        networkManagerResources,
+				metric: &model.Metric{
+					MetricName: "BytesDropCountBlackhole",
+					Namespace:  "AWS/NetworkManager",
+					Dimensions: []*model.Dimension{
+						{Name: "CoreNetwork", Value: "core-network-0123456789abcdef0"},
+						{Name: "EdgeLocation", Value: "eu-west-1"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: networkManagerCoreNetwork,
+		},
+		{
+			name: "should match with Attachment dimension",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/NetworkManager").ToModelDimensionsRegexp(),
+				resources:        networkManagerResources,
+				metric: &model.Metric{
+					MetricName: "BytesIn",
+					Namespace:  "AWS/NetworkManager",
+					Dimensions: []*model.Dimension{
+						{Name: "Attachment", Value: "attachment-0123456789abcdef0"},
+						{Name: "CoreNetwork", Value: "core-network-0123456789abcdef0"},
+						{Name: "EdgeLocation", Value: "eu-west-1"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: networkManagerAttachment,
+		},
+		{
+			name: "should fall back to the core network with unmatched Attachment dimension",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/NetworkManager").ToModelDimensionsRegexp(),
+				resources:        networkManagerResources,
+				metric: &model.Metric{
+					MetricName: "BytesIn",
+					Namespace:  "AWS/NetworkManager",
+					Dimensions: []*model.Dimension{
+						{Name: "Attachment", Value: "attachment-0fedcba9876543210"},
+						{Name: "CoreNetwork", Value: "core-network-0123456789abcdef0"},
+						{Name: "EdgeLocation", Value: "eu-west-1"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: networkManagerCoreNetwork,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			associator := NewAssociator(logging.NewNopLogger(), tc.args.dimensionRegexps, tc.args.resources)
+			res, skip := associator.AssociateMetricToResource(tc.args.metric)
+			require.Equal(t, tc.expectedSkip, skip)
+			require.Equal(t, tc.expectedResource, res)
+		})
+	}
+}
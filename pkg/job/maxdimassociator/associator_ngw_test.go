@@ -0,0 +1,77 @@
+package maxdimassociator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+var natGateway = &model.TaggedResource{
+	ARN:       "arn:aws:ec2:eu-west-1:123456789012:natgateway/nat-0123456789abcdef0",
+	Namespace: "AWS/NATGateway",
+}
+
+var natGatewayResources = []*model.TaggedResource{natGateway}
+
+func TestAssociatorNATGateway(t *testing.T) {
+	type args struct {
+		dimensionRegexps []model.DimensionsRegexp
+		resources        []*model.TaggedResource
+		metric           *model.Metric
+	}
+
+	type testCase struct {
+		name             string
+		args             args
+		expectedSkip     bool
+		expectedResource *model.TaggedResource
+	}
+
+	testcases := []testCase{
+		{
+			name: "should match with NatGatewayId dimension",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/NATGateway").ToModelDimensionsRegexp(),
+				resources:        natGatewayResources,
+				metric: &model.Metric{
+					MetricName: "BytesOutToDestination",
+					Namespace:  "AWS/NATGateway",
+					Dimensions: []*model.Dimension{
+						{Name: "NatGatewayId", Value: "nat-0123456789abcdef0"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: natGateway,
+		},
+		{
+			name: "should skip with unmatched NatGatewayId dimension",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/NATGateway").ToModelDimensionsRegexp(),
+				resources:        natGatewayResources,
+				metric: &model.Metric{
+					MetricName: "ActiveConnectionCount",
+					Namespace:  "AWS/NATGateway",
+					Dimensions: []*model.Dimension{
+						{Name: "NatGatewayId", Value: "nat-0fedcba9876543210"},
+					},
+				},
+			},
+			expectedSkip:     true,
+			expectedResource: nil,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			associator := NewAssociator(logging.NewNopLogger(), tc.args.dimensionRegexps, tc.args.resources)
+			res, skip := associator.AssociateMetricToResource(tc.args.metric)
+			require.Equal(t, tc.expectedSkip, skip)
+			require.Equal(t, tc.expectedResource, res)
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package maxdimassociator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+var pinpointApp = &model.TaggedResource{
+	ARN:       "arn:aws:mobiletargeting:eu-west-1:123456789012:apps/0123456789abcdef0123456789abcdef",
+	Namespace: "AWS/Pinpoint",
+}
+
+var pinpointResources = []*model.TaggedResource{
+	pinpointApp,
+}
+
+func TestAssociatorPinpoint(t *testing.T) {
+	type args struct {
+		dimensionRegexps []model.DimensionsRegexp
+		resources        []*model.TaggedResource
+		metric           *model.Metric
+	}
+
+	type testCase struct {
+		name             string
+		args             args
+		expectedSkip     bool
+		expectedResource *model.TaggedResource
+	}
+
+	testcases := []testCase{
+		{
+			name: "should match application metric",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/Pinpoint").ToModelDimensionsRegexp(),
+				resources:        pinpointResources,
+				metric: &model.Metric{
+					MetricName: "TotalEndpoints",
+					Namespace:  "AWS/Pinpoint",
+					Dimensions: []*model.Dimension{
+						{Name: "ApplicationId", Value: "0123456789abcdef0123456789abcdef"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: pinpointApp,
+		},
+		{
+			name: "should match channel delivery metric to its application",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/Pinpoint").ToModelDimensionsRegexp(),
+				resources:        pinpointResources,
+				metric: &model.Metric{
+					MetricName: "DirectSendMessagePermanentFailure",
+					Namespace:  "AWS/Pinpoint",
+					Dimensions: []*model.Dimension{
+						{Name: "ApplicationId", Value: "0123456789abcdef0123456789abcdef"},
+						{Name: "Channel", Value: "SMS"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: pinpointApp,
+		},
+		{
+			name: "should skip metric of an undiscovered application",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/Pinpoint").ToModelDimensionsRegexp(),
+				resources:        pinpointResources,
+				metric: &model.Metric{
+					MetricName: "TotalEndpoints",
+					Namespace:  "AWS/Pinpoint",
+					Dimensions: []*model.Dimension{
+						{Name: "ApplicationId", Value: "fedcba9876543210fedcba9876543210"},
+					},
+				},
+			},
+			expectedSkip:     true,
+			expectedResource: nil,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			associator := NewAssociator(logging.NewNopLogger(), tc.args.dimensionRegexps, tc.args.resources)
+			res, skip := associator.AssociateMetricToResource(tc.args.metric)
+			require.Equal(t, tc.expectedSkip, skip)
+			require.Equal(t, tc.expectedResource, res)
+		})
+	}
+}
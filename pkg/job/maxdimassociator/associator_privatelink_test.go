@@ -0,0 +1,184 @@
+package maxdimassociator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+var vpcEndpoint = &model.TaggedResource{
+	ARN:       "arn:aws:ec2:eu-west-1:123456789012:vpc-endpoint/vpce-0123456789abcdef0",
+	Namespace: "AWS/PrivateLinkEndpoints",
+}
+
+var vpcEndpointService = &model.TaggedResource{
+	ARN:       "arn:aws:ec2:eu-west-1:123456789012:vpc-endpoint-service/vpce-svc-0123456789abcdef0",
+	Namespace: "AWS/PrivateLinkServices",
+}
+
+func TestAssociatorPrivateLinkEndpoints(t *testing.T) {
+	type args struct {
+		dimensionRegexps []model.DimensionsRegexp
+		resources        []*model.TaggedResource
+		metric           *model.Metric
+	}
+
+	type testCase struct {
+		name             string
+		args             args
+		expectedSkip     bool
+		expectedResource *model.TaggedResource
+	}
+
+	testcases := []testCase{
+		{
+			name: "should match with VPC Endpoint Id dimension",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/PrivateLinkEndpoints").ToModelDimensionsRegexp(),
+				resources:        []*model.TaggedResource{vpcEndpoint},
+				metric: &model.Metric{
+					MetricName: "BytesProcessed",
+					Namespace:  "AWS/PrivateLinkEndpoints",
+					Dimensions: []*model.Dimension{
+						{Name: "Endpoint Type", Value: "Interface"},
+						{Name: "Service Name", Value: "com.amazonaws.eu-west-1.s3"},
+						{Name: "VPC Endpoint Id", Value: "vpce-0123456789abcdef0"},
+						{Name: "VPC Id", Value: "vpc-0123456789abcdef0"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: vpcEndpoint,
+		},
+		{
+			name: "should match subnet metric with VPC Endpoint Id dimension",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/PrivateLinkEndpoints").ToModelDimensionsRegexp(),
+				resources:        []*model.TaggedResource{vpcEndpoint},
+				metric: &model.Metric{
+					MetricName: "BytesProcessed",
+					Namespace:  "AWS/PrivateLinkEndpoints",
+					Dimensions: []*model.Dimension{
+						{Name: "Endpoint Type", Value: "Interface"},
+						{Name: "Service Name", Value: "com.amazonaws.eu-west-1.s3"},
+						{Name: "VPC Endpoint Id", Value: "vpce-0123456789abcdef0"},
+						{Name: "VPC Id", Value: "vpc-0123456789abcdef0"},
+						{Name: "Subnet Id", Value: "subnet-0123456789abcdef0"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: vpcEndpoint,
+		},
+		{
+			name: "should skip with unmatched VPC Endpoint Id dimension",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/PrivateLinkEndpoints").ToModelDimensionsRegexp(),
+				resources:        []*model.TaggedResource{vpcEndpoint},
+				metric: &model.Metric{
+					MetricName: "ActiveConnections",
+					Namespace:  "AWS/PrivateLinkEndpoints",
+					Dimensions: []*model.Dimension{
+						{Name: "Endpoint Type", Value: "Interface"},
+						{Name: "Service Name", Value: "com.amazonaws.eu-west-1.s3"},
+						{Name: "VPC Endpoint Id", Value: "vpce-0fedcba9876543210"},
+						{Name: "VPC Id", Value: "vpc-0123456789abcdef0"},
+					},
+				},
+			},
+			expectedSkip:     true,
+			expectedResource: nil,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			associator := NewAssociator(logging.NewNopLogger(), tc.args.dimensionRegexps, tc.args.resources)
+			res, skip := associator.AssociateMetricToResource(tc.args.metric)
+			require.Equal(t, tc.expectedSkip, skip)
+			require.Equal(t, tc.expectedResource, res)
+		})
+	}
+}
+
+func TestAssociatorPrivateLinkServices(t *testing.T) {
+	type args struct {
+		dimensionRegexps []model.DimensionsRegexp
+		resources        []*model.TaggedResource
+		metric           *model.Metric
+	}
+
+	type testCase struct {
+		name             string
+		args             args
+		expectedSkip     bool
+		expectedResource *model.TaggedResource
+	}
+
+	testcases := []testCase{
+		{
+			name: "should match with Service Id dimension",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/PrivateLinkServices").ToModelDimensionsRegexp(),
+				resources:        []*model.TaggedResource{vpcEndpointService},
+				metric: &model.Metric{
+					MetricName: "EndpointsCount",
+					Namespace:  "AWS/PrivateLinkServices",
+					Dimensions: []*model.Dimension{
+						{Name: "Service Id", Value: "vpce-svc-0123456789abcdef0"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: vpcEndpointService,
+		},
+		{
+			name: "should match load balancer metric with Service Id dimension",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/PrivateLinkServices").ToModelDimensionsRegexp(),
+				resources:        []*model.TaggedResource{vpcEndpointService},
+				metric: &model.Metric{
+					MetricName: "BytesProcessed",
+					Namespace:  "AWS/PrivateLinkServices",
+					Dimensions: []*model.Dimension{
+						{Name: "Az", Value: "eu-west-1a"},
+						{Name: "Load Balancer Arn", Value: "arn:aws:elasticloadbalancing:eu-west-1:123456789012:loadbalancer/net/my-nlb/0123456789abcdef"},
+						{Name: "Service Id", Value: "vpce-svc-0123456789abcdef0"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: vpcEndpointService,
+		},
+		{
+			name: "should match endpoint metric with Service Id dimension",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/PrivateLinkServices").ToModelDimensionsRegexp(),
+				resources:        []*model.TaggedResource{vpcEndpointService},
+				metric: &model.Metric{
+					MetricName: "ActiveConnections",
+					Namespace:  "AWS/PrivateLinkServices",
+					Dimensions: []*model.Dimension{
+						{Name: "Service Id", Value: "vpce-svc-0123456789abcdef0"},
+						{Name: "VPC Endpoint Id", Value: "vpce-0fedcba9876543210"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: vpcEndpointService,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			associator := NewAssociator(logging.NewNopLogger(), tc.args.dimensionRegexps, tc.args.resources)
+			res, skip := associator.AssociateMetricToResource(tc.args.metric)
+			require.Equal(t, tc.expectedSkip, skip)
+			require.Equal(t, tc.expectedResource, res)
+		})
+	}
+}
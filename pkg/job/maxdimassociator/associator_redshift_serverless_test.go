@@ -0,0 +1,118 @@
+package maxdimassociator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+var redshiftServerlessWorkgroup = &model.TaggedResource{
+	ARN:       "arn:aws:redshift-serverless:eu-west-1:123456789012:workgroup/3b4a1c2d-5e6f-4a7b-8c9d-0e1f2a3b4c5d/analytics",
+	Namespace: "AWS/Redshift-Serverless",
+}
+
+var redshiftServerlessNamespace = &model.TaggedResource{
+	ARN:       "arn:aws:redshift-serverless:eu-west-1:123456789012:namespace/9f8e7d6c-5b4a-4392-8170-6f5e4d3c2b1a/analytics",
+	Namespace: "AWS/Redshift-Serverless",
+}
+
+var redshiftServerlessResources = []*model.TaggedResource{
+	redshiftServerlessWorkgroup,
+	redshiftServerlessNamespace,
+}
+
+func TestAssociatorRedshiftServerless(t *testing.T) {
+	type args struct {
+		dimensionRegexps []model.DimensionsRegexp
+		resources        []*model.TaggedResource
+		metric           *model.Metric
+	}
+
+	type testCase struct {
+		name             string
+		args             args
+		expectedSkip     bool
+		expectedResource *model.TaggedResource
+	}
+
+	testcases := []testCase{
+		{
+			name: "should match workgroup metric",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/Redshift-Serverless").ToModelDimensionsRegexp(),
+				resources:        redshiftServerlessResources,
+				metric: &model.Metric{
+					MetricName: "ComputeCapacity",
+					Namespace:  "AWS/Redshift-Serverless",
+					Dimensions: []*model.Dimension{
+						{Name: "Workgroup", Value: "analytics"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: redshiftServerlessWorkgroup,
+		},
+		{
+			name: "should match workgroup database metric",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/Redshift-Serverless").ToModelDimensionsRegexp(),
+				resources:        redshiftServerlessResources,
+				metric: &model.Metric{
+					MetricName: "DatabaseConnections",
+					Namespace:  "AWS/Redshift-Serverless",
+					Dimensions: []*model.Dimension{
+						{Name: "DatabaseName", Value: "dev"},
+						{Name: "Workgroup", Value: "analytics"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: redshiftServerlessWorkgroup,
+		},
+		{
+			name: "should match namespace metric",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/Redshift-Serverless").ToModelDimensionsRegexp(),
+				resources:        redshiftServerlessResources,
+				metric: &model.Metric{
+					MetricName: "DataStorage",
+					Namespace:  "AWS/Redshift-Serverless",
+					Dimensions: []*model.Dimension{
+						{Name: "Namespace", Value: "analytics"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: redshiftServerlessNamespace,
+		},
+		{
+			name: "should skip metric of an undiscovered workgroup",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/Redshift-Serverless").ToModelDimensionsRegexp(),
+				resources:        redshiftServerlessResources,
+				metric: &model.Metric{
+					MetricName: "ComputeSeconds",
+					Namespace:  "AWS/Redshift-Serverless",
+					Dimensions: []*model.Dimension{
+						{Name: "Workgroup", Value: "reporting"},
+					},
+				},
+			},
+			expectedSkip:     true,
+			expectedResource: nil,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			associator := NewAssociator(logging.NewNopLogger(), tc.args.dimensionRegexps, tc.args.resources)
+			res, skip := associator.AssociateMetricToResource(tc.args.metric)
+			require.Equal(t, tc.expectedSkip, skip)
+			require.Equal(t, tc.expectedResource, res)
+		})
+	}
+}
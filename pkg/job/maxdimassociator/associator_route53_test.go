@@ -0,0 +1,160 @@
+package maxdimassociator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+var route53HealthCheck = &model.TaggedResource{
+	ARN:       "arn:aws:route53:::healthcheck/abcdef12-3456-7890-abcd-ef1234567890",
+	Namespace: "AWS/Route53",
+}
+
+var route53HostedZone = &model.TaggedResource{
+	ARN:       "arn:aws:route53:::hostedzone/Z0123456789ABCDEFGHIJ",
+	Namespace: "AWS/Route53",
+}
+
+var route53Resources = []*model.TaggedResource{route53HealthCheck, route53HostedZone}
+
+var route53ResolverEndpoint = &model.TaggedResource{
+	ARN:       "arn:aws:route53resolver:us-east-1:123456789012:resolver-endpoint/rslvr-in-0123456789abcdef0",
+	Namespace: "AWS/Route53Resolver",
+}
+
+var route53ResolverFirewallRuleGroup = &model.TaggedResource{
+	ARN:       "arn:aws:route53resolver:us-east-1:123456789012:firewall-rule-group/rslvr-frg-0123456789abcdef",
+	Namespace: "AWS/Route53Resolver",
+}
+
+var route53ResolverResources = []*model.TaggedResource{route53ResolverEndpoint, route53ResolverFirewallRuleGroup}
+
+func TestAssociatorRoute53(t *testing.T) {
+	type args struct {
+		dimensionRegexps []model.DimensionsRegexp
+		resources        []*model.TaggedResource
+		metric           *model.Metric
+	}
+
+	type testCase struct {
+		name             string
+		args             args
+		expectedSkip     bool
+		expectedResource *model.TaggedResource
+	}
+
+	testcases := []testCase{
+		{
+			name: "should match health check with HealthCheckId dimension",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/Route53").ToModelDimensionsRegexp(),
+				resources:        route53Resources,
+				metric: &model.Metric{
+					MetricName: "HealthCheckStatus",
+					Namespace:  "AWS/Route53",
+					Dimensions: []*model.Dimension{
+						{Name: "HealthCheckId", Value: "abcdef12-3456-7890-abcd-ef1234567890"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: route53HealthCheck,
+		},
+		{
+			name: "should match health check with HealthCheckId and Region dimensions",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/Route53").ToModelDimensionsRegexp(),
+				resources:        route53Resources,
+				metric: &model.Metric{
+					MetricName: "TimeToFirstByte",
+					Namespace:  "AWS/Route53",
+					Dimensions: []*model.Dimension{
+						{Name: "HealthCheckId", Value: "abcdef12-3456-7890-abcd-ef1234567890"},
+						{Name: "Region", Value: "us-east-1"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: route53HealthCheck,
+		},
+		{
+			name: "should match hosted zone with HostedZoneId dimension",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/Route53").ToModelDimensionsRegexp(),
+				resources:        route53Resources,
+				metric: &model.Metric{
+					MetricName: "DNSQueries",
+					Namespace:  "AWS/Route53",
+					Dimensions: []*model.Dimension{
+						{Name: "HostedZoneId", Value: "Z0123456789ABCDEFGHIJ"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: route53HostedZone,
+		},
+		{
+			name: "should skip with unmatched HealthCheckId dimension",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/Route53").ToModelDimensionsRegexp(),
+				resources:        route53Resources,
+				metric: &model.Metric{
+					MetricName: "HealthCheckStatus",
+					Namespace:  "AWS/Route53",
+					Dimensions: []*model.Dimension{
+						{Name: "HealthCheckId", Value: "00000000-0000-0000-0000-000000000000"},
+					},
+				},
+			},
+			expectedSkip:     true,
+			expectedResource: nil,
+		},
+		{
+			name: "should match resolver endpoint with EndpointId and RniId dimensions",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/Route53Resolver").ToModelDimensionsRegexp(),
+				resources:        route53ResolverResources,
+				metric: &model.Metric{
+					MetricName: "InboundQueryVolume",
+					Namespace:  "AWS/Route53Resolver",
+					Dimensions: []*model.Dimension{
+						{Name: "EndpointId", Value: "rslvr-in-0123456789abcdef0"},
+						{Name: "RniId", Value: "rni-0123456789abcdef0"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: route53ResolverEndpoint,
+		},
+		{
+			name: "should match firewall rule group with FirewallRuleGroupId dimension",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/Route53Resolver").ToModelDimensionsRegexp(),
+				resources:        route53ResolverResources,
+				metric: &model.Metric{
+					MetricName: "FirewallRuleGroupQueryVolume",
+					Namespace:  "AWS/Route53Resolver",
+					Dimensions: []*model.Dimension{
+						{Name: "FirewallRuleGroupId", Value: "rslvr-frg-0123456789abcdef"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: route53ResolverFirewallRuleGroup,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			associator := NewAssociator(logging.NewNopLogger(), tc.args.dimensionRegexps, tc.args.resources)
+			res, skip := associator.AssociateMetricToResource(tc.args.metric)
+			require.Equal(t, tc.expectedSkip, skip)
+			require.Equal(t, tc.expectedResource, res)
+		})
+	}
+}
@@ -35,7 +35,7 @@ func TestAssociatorSagemakerProcessingJob(t *testing.T) {
 
 	testcases := []testCase{
 		{
-			name: "1 dimension should not match but not skip",
+			name: "1 dimension should match the job name of the Host dimension",
 			args: args{
 				dimensionRegexps: config.SupportedServices.GetService("/aws/sagemaker/ProcessingJobs").ToModelDimensionsRegexp(),
 				resources:        sagemakerProcessingJobResources,
@@ -48,6 +48,22 @@ func TestAssociatorSagemakerProcessingJob(t *testing.T) {
 				},
 			},
 			expectedSkip:     false,
+			expectedResource: sagemakerProcessingJobOne,
+		},
+		{
+			name: "1 dimension should not match other job",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("/aws/sagemaker/ProcessingJobs").ToModelDimensionsRegexp(),
+				resources:        sagemakerProcessingJobResources,
+				metric: &model.Metric{
+					MetricName: "CPUUtilization",
+					Namespace:  "/aws/sagemaker/ProcessingJobs",
+					Dimensions: []*model.Dimension{
+						{Name: "Host", Value: "example-processing-job-two/algo-1"},
+					},
+				},
+			},
+			expectedSkip:     true,
 			expectedResource: nil,
 		},
 	}
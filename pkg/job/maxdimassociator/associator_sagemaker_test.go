@@ -25,10 +25,16 @@ var sagemakerEndpointInvocationUpper = &model.TaggedResource{
 	Namespace: "AWS/SageMaker",
 }
 
+var sagemakerInferenceComponentOne = &model.TaggedResource{
+	ARN:       "arn:aws:sagemaker:us-west-2:123456789012:inference-component/example-inference-component-one",
+	Namespace: "AWS/SageMaker",
+}
+
 var sagemakerInvocationResources = []*model.TaggedResource{
 	sagemakerEndpointInvocationOne,
 	sagemakerEndpointInvocationTwo,
 	sagemakerEndpointInvocationUpper,
+	sagemakerInferenceComponentOne,
 }
 
 func TestAssociatorSagemaker(t *testing.T) {
@@ -115,6 +121,22 @@ func TestAssociatorSagemaker(t *testing.T) {
 			expectedSkip:     false,
 			expectedResource: sagemakerEndpointInvocationUpper,
 		},
+		{
+			name: "inference component should match",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/SageMaker").ToModelDimensionsRegexp(),
+				resources:        sagemakerInvocationResources,
+				metric: &model.Metric{
+					MetricName: "Invocations",
+					Namespace:  "AWS/SageMaker",
+					Dimensions: []*model.Dimension{
+						{Name: "InferenceComponentName", Value: "Example-Inference-Component-One"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: sagemakerInferenceComponentOne,
+		},
 	}
 
 	for _, tc := range testcases {
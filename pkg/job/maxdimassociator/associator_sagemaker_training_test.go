@@ -35,7 +35,7 @@ func TestAssociatorSagemakerTrainingJob(t *testing.T) {
 
 	testcases := []testCase{
 		{
-			name: "1 dimension should not skip",
+			name: "1 dimension should match the job name of the Host dimension",
 			args: args{
 				dimensionRegexps: config.SupportedServices.GetService("/aws/sagemaker/TrainingJobs").ToModelDimensionsRegexp(),
 				resources:        sagemakerTrainingJobResources,
@@ -48,6 +48,22 @@ func TestAssociatorSagemakerTrainingJob(t *testing.T) {
 				},
 			},
 			expectedSkip:     false,
+			expectedResource: sagemakerTrainingJobOne,
+		},
+		{
+			name: "1 dimension should not match other job",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("/aws/sagemaker/TrainingJobs").ToModelDimensionsRegexp(),
+				resources:        sagemakerTrainingJobResources,
+				metric: &model.Metric{
+					MetricName: "CPUUtilization",
+					Namespace:  "/aws/sagemaker/TrainingJobs",
+					Dimensions: []*model.Dimension{
+						{Name: "Host", Value: "example-training-job-two/algo-1"},
+					},
+				},
+			},
+			expectedSkip:     true,
 			expectedResource: nil,
 		},
 	}
@@ -35,7 +35,7 @@ func TestAssociatorSagemakerTransformJob(t *testing.T) {
 
 	testcases := []testCase{
 		{
-			name: "1 dimension should not match but not skip",
+			name: "1 dimension should match the job name of the Host dimension",
 			args: args{
 				dimensionRegexps: config.SupportedServices.GetService("/aws/sagemaker/TransformJobs").ToModelDimensionsRegexp(),
 				resources:        sagemakerTransformJobResources,
@@ -48,6 +48,22 @@ func TestAssociatorSagemakerTransformJob(t *testing.T) {
 				},
 			},
 			expectedSkip:     false,
+			expectedResource: sagemakerTransformJobOne,
+		},
+		{
+			name: "1 dimension should not match other job",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("/aws/sagemaker/TransformJobs").ToModelDimensionsRegexp(),
+				resources:        sagemakerTransformJobResources,
+				metric: &model.Metric{
+					MetricName: "CPUUtilization",
+					Namespace:  "/aws/sagemaker/TransformJobs",
+					Dimensions: []*model.Dimension{
+						{Name: "Host", Value: "example-transform-job-two/algo-1"},
+					},
+				},
+			},
+			expectedSkip:     true,
 			expectedResource: nil,
 		},
 	}
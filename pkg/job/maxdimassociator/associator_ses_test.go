@@ -0,0 +1,93 @@
+package maxdimassociator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+var sesConfigurationSet = &model.TaggedResource{
+	ARN:       "arn:aws:ses:eu-west-1:123456789012:configuration-set/transactional",
+	Namespace: "AWS/SES",
+}
+
+var sesResources = []*model.TaggedResource{
+	sesConfigurationSet,
+}
+
+func TestAssociatorSES(t *testing.T) {
+	type args struct {
+		dimensionRegexps []model.DimensionsRegexp
+		resources        []*model.TaggedResource
+		metric           *model.Metric
+	}
+
+	type testCase struct {
+		name             string
+		args             args
+		expectedSkip     bool
+		expectedResource *model.TaggedResource
+	}
+
+	testcases := []testCase{
+		{
+			name: "should match configuration set metric",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/SES").ToModelDimensionsRegexp(),
+				resources:        sesResources,
+				metric: &model.Metric{
+					MetricName: "Reputation.BounceRate",
+					Namespace:  "AWS/SES",
+					Dimensions: []*model.Dimension{
+						{Name: "ses:configuration-set", Value: "transactional"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: sesConfigurationSet,
+		},
+		{
+			name: "should skip metric of an undiscovered configuration set",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/SES").ToModelDimensionsRegexp(),
+				resources:        sesResources,
+				metric: &model.Metric{
+					MetricName: "Delivery",
+					Namespace:  "AWS/SES",
+					Dimensions: []*model.Dimension{
+						{Name: "ses:configuration-set", Value: "marketing"},
+					},
+				},
+			},
+			expectedSkip:     true,
+			expectedResource: nil,
+		},
+		{
+			name: "should not skip account level metric",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/SES").ToModelDimensionsRegexp(),
+				resources:        sesResources,
+				metric: &model.Metric{
+					MetricName: "Send",
+					Namespace:  "AWS/SES",
+					Dimensions: []*model.Dimension{},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: nil,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			associator := NewAssociator(logging.NewNopLogger(), tc.args.dimensionRegexps, tc.args.resources)
+			res, skip := associator.AssociateMetricToResource(tc.args.metric)
+			require.Equal(t, tc.expectedSkip, skip)
+			require.Equal(t, tc.expectedResource, res)
+		})
+	}
+}
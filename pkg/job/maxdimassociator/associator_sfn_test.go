@@ -0,0 +1,114 @@
+package maxdimassociator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+var sfnStateMachine = &model.TaggedResource{
+	ARN:       "arn:aws:states:eu-west-1:123456789012:stateMachine:order-processing",
+	Namespace: "AWS/States",
+}
+
+var sfnActivity = &model.TaggedResource{
+	ARN:       "arn:aws:states:eu-west-1:123456789012:activity:manual-approval",
+	Namespace: "AWS/States",
+}
+
+var sfnResources = []*model.TaggedResource{sfnStateMachine, sfnActivity}
+
+func TestAssociatorStepFunctions(t *testing.T) {
+	type args struct {
+		dimensionRegexps []model.DimensionsRegexp
+		resources        []*model.TaggedResource
+		metric           *model.Metric
+	}
+
+	type testCase struct {
+		name             string
+		args             args
+		expectedSkip     bool
+		expectedResource *model.TaggedResource
+	}
+
+	testcases := []testCase{
+		{
+			name: "should match with StateMachineArn dimension",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/States").ToModelDimensionsRegexp(),
+				resources:        sfnResources,
+				metric: &model.Metric{
+					MetricName: "ExecutionsFailed",
+					Namespace:  "AWS/States",
+					Dimensions: []*model.Dimension{
+						{Name: "StateMachineArn", Value: "arn:aws:states:eu-west-1:123456789012:stateMachine:order-processing"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: sfnStateMachine,
+		},
+		{
+			name: "should match with ActivityArn dimension",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/States").ToModelDimensionsRegexp(),
+				resources:        sfnResources,
+				metric: &model.Metric{
+					MetricName: "ActivitiesTimedOut",
+					Namespace:  "AWS/States",
+					Dimensions: []*model.Dimension{
+						{Name: "ActivityArn", Value: "arn:aws:states:eu-west-1:123456789012:activity:manual-approval"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: sfnActivity,
+		},
+		{
+			name: "should skip with unmatched StateMachineArn dimension",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/States").ToModelDimensionsRegexp(),
+				resources:        sfnResources,
+				metric: &model.Metric{
+					MetricName: "ExecutionsFailed",
+					Namespace:  "AWS/States",
+					Dimensions: []*model.Dimension{
+						{Name: "StateMachineArn", Value: "arn:aws:states:eu-west-1:123456789012:stateMachine:other"},
+					},
+				},
+			},
+			expectedSkip:     true,
+			expectedResource: nil,
+		},
+		{
+			name: "should not skip with service integration dimension",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/States").ToModelDimensionsRegexp(),
+				resources:        sfnResources,
+				metric: &model.Metric{
+					MetricName: "ServiceIntegrationsFailed",
+					Namespace:  "AWS/States",
+					Dimensions: []*model.Dimension{
+						{Name: "ServiceIntegrationResourceArn", Value: "arn:aws:states:eu-west-1:123456789012:sqs:sendMessage"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: nil,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			associator := NewAssociator(logging.NewNopLogger(), tc.args.dimensionRegexps, tc.args.resources)
+			res, skip := associator.AssociateMetricToResource(tc.args.metric)
+			require.Equal(t, tc.expectedSkip, skip)
+			require.Equal(t, tc.expectedResource, res)
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package maxdimassociator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+var snsTopic = &model.TaggedResource{
+	ARN:       "arn:aws:sns:eu-west-1:123456789012:alerts",
+	Namespace: "AWS/SNS",
+}
+
+var snsFIFOTopic = &model.TaggedResource{
+	ARN:       "arn:aws:sns:eu-west-1:123456789012:orders.fifo",
+	Namespace: "AWS/SNS",
+}
+
+var snsResources = []*model.TaggedResource{
+	snsTopic,
+	snsFIFOTopic,
+}
+
+func TestAssociatorSNS(t *testing.T) {
+	type args struct {
+		dimensionRegexps []model.DimensionsRegexp
+		resources        []*model.TaggedResource
+		metric           *model.Metric
+	}
+
+	type testCase struct {
+		name             string
+		args             args
+		expectedSkip     bool
+		expectedResource *model.TaggedResource
+	}
+
+	testcases := []testCase{
+		{
+			name: "should match topic delivery metric",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/SNS").ToModelDimensionsRegexp(),
+				resources:        snsResources,
+				metric: &model.Metric{
+					MetricName: "NumberOfNotificationsFailed",
+					Namespace:  "AWS/SNS",
+					Dimensions: []*model.Dimension{
+						{Name: "TopicName", Value: "alerts"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: snsTopic,
+		},
+		{
+			name: "should match fifo topic metric",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/SNS").ToModelDimensionsRegexp(),
+				resources:        snsResources,
+				metric: &model.Metric{
+					MetricName: "NumberOfMessagesPublished",
+					Namespace:  "AWS/SNS",
+					Dimensions: []*model.Dimension{
+						{Name: "TopicName", Value: "orders.fifo"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: snsFIFOTopic,
+		},
+		{
+			name: "should skip metric of an undiscovered topic",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/SNS").ToModelDimensionsRegexp(),
+				resources:        snsResources,
+				metric: &model.Metric{
+					MetricName: "NumberOfNotificationsDelivered",
+					Namespace:  "AWS/SNS",
+					Dimensions: []*model.Dimension{
+						{Name: "TopicName", Value: "payments"},
+					},
+				},
+			},
+			expectedSkip:     true,
+			expectedResource: nil,
+		},
+		{
+			name: "should not skip account level sms metric",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/SNS").ToModelDimensionsRegexp(),
+				resources:        snsResources,
+				metric: &model.Metric{
+					MetricName: "SMSSuccessRate",
+					Namespace:  "AWS/SNS",
+					Dimensions: []*model.Dimension{
+						{Name: "Country", Value: "NL"},
+						{Name: "SMSType", Value: "Transactional"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: nil,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			associator := NewAssociator(logging.NewNopLogger(), tc.args.dimensionRegexps, tc.args.resources)
+			res, skip := associator.AssociateMetricToResource(tc.args.metric)
+			require.Equal(t, tc.expectedSkip, skip)
+			require.Equal(t, tc.expectedResource, res)
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package maxdimassociator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+var transitGateway = &model.TaggedResource{
+	ARN:       "arn:aws:ec2:eu-west-1:123456789012:transit-gateway/tgw-0123456789abcdef0",
+	Namespace: "AWS/TransitGateway",
+}
+
+var transitGatewayVPCAttachment = &model.TaggedResource{
+	ARN:       "tgw-0123456789abcdef0/tgw-attach-0123456789abcdef0",
+	Namespace: "AWS/TransitGateway",
+	Attributes: []model.Tag{
+		{Key: "attachment_type", Value: "vpc"},
+		{Key: "attachment_resource_id", Value: "vpc-0123456789abcdef0"},
+	},
+}
+
+var transitGatewayResources = []*model.TaggedResource{transitGateway, transitGatewayVPCAttachment}
+
+func TestAssociatorTransitGateway(t *testing.T) {
+	type args struct {
+		dimensionRegexps []model.DimensionsRegexp
+		resources        []*model.TaggedResource
+		metric           *model.Metric
+	}
+
+	type testCase struct {
+		name             string
+		args             args
+		expectedSkip     bool
+		expectedResource *model.TaggedResource
+	}
+
+	testcases := []testCase{
+		{
+			name: "should match with TransitGateway dimension",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/TransitGateway").ToModelDimensionsRegexp(),
+				resources:        transitGatewayResources,
+				metric: &model.Metric{
+					MetricName: "BytesIn",
+					Namespace:  "AWS/TransitGateway",
+					Dimensions: []*model.Dimension{
+						{Name: "TransitGateway", Value: "tgw-0123456789abcdef0"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: transitGateway,
+		},
+		{
+			name: "should match with TransitGateway and TransitGatewayAttachment dimensions",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/TransitGateway").ToModelDimensionsRegexp(),
+				resources:        transitGatewayResources,
+				metric: &model.Metric{
+					MetricName: "PacketDropCountBlackhole",
+					Namespace:  "AWS/TransitGateway",
+					Dimensions: []*model.Dimension{
+						{Name: "TransitGateway", Value: "tgw-0123456789abcdef0"},
+						{Name: "TransitGatewayAttachment", Value: "tgw-attach-0123456789abcdef0"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: transitGatewayVPCAttachment,
+		},
+		{
+			name: "should fall back to the transit gateway with unmatched TransitGatewayAttachment dimension",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/TransitGateway").ToModelDimensionsRegexp(),
+				resources:        transitGatewayResources,
+				metric: &model.Metric{
+					MetricName: "BytesIn",
+					Namespace:  "AWS/TransitGateway",
+					Dimensions: []*model.Dimension{
+						{Name: "TransitGateway", Value: "tgw-0123456789abcdef0"},
+						{Name: "TransitGatewayAttachment", Value: "tgw-attach-0fedcba9876543210"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: transitGateway,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			associator := NewAssociator(logging.NewNopLogger(), tc.args.dimensionRegexps, tc.args.resources)
+			res, skip := associator.AssociateMetricToResource(tc.args.metric)
+			require.Equal(t, tc.expectedSkip, skip)
+			require.Equal(t, tc.expectedResource, res)
+		})
+	}
+}
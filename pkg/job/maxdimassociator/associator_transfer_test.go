@@ -0,0 +1,77 @@
+package maxdimassociator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+var transferServer = &model.TaggedResource{
+	ARN:       "arn:aws:transfer:us-east-1:123456789012:server/s-01234567890abcdef",
+	Namespace: "AWS/Transfer",
+}
+
+var transferResources = []*model.TaggedResource{transferServer}
+
+func TestAssociatorTransfer(t *testing.T) {
+	type args struct {
+		dimensionRegexps []model.DimensionsRegexp
+		resources        []*model.TaggedResource
+		metric           *model.Metric
+	}
+
+	type testCase struct {
+		name             string
+		args             args
+		expectedSkip     bool
+		expectedResource *model.TaggedResource
+	}
+
+	testcases := []testCase{
+		{
+			name: "should match with ServerId dimension",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/Transfer").ToModelDimensionsRegexp(),
+				resources:        transferResources,
+				metric: &model.Metric{
+					MetricName: "BytesIn",
+					Namespace:  "AWS/Transfer",
+					Dimensions: []*model.Dimension{
+						{Name: "ServerId", Value: "s-01234567890abcdef"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: transferServer,
+		},
+		{
+			name: "should skip with unknown ServerId dimension",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/Transfer").ToModelDimensionsRegexp(),
+				resources:        transferResources,
+				metric: &model.Metric{
+					MetricName: "BytesIn",
+					Namespace:  "AWS/Transfer",
+					Dimensions: []*model.Dimension{
+						{Name: "ServerId", Value: "s-fedcba09876543210"},
+					},
+				},
+			},
+			expectedSkip:     true,
+			expectedResource: nil,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			associator := NewAssociator(logging.NewNopLogger(), tc.args.dimensionRegexps, tc.args.resources)
+			res, skip := associator.AssociateMetricToResource(tc.args.metric)
+			require.Equal(t, tc.expectedSkip, skip)
+			require.Equal(t, tc.expectedResource, res)
+		})
+	}
+}
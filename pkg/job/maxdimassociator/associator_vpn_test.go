@@ -0,0 +1,95 @@
+package maxdimassociator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+var vpnConnection = &model.TaggedResource{
+	ARN:       "arn:aws:ec2:eu-west-1:123456789012:vpn-connection/vpn-0123456789abcdef0",
+	Namespace: "AWS/VPN",
+}
+
+var vpnResources = []*model.TaggedResource{vpnConnection}
+
+func TestAssociatorVPN(t *testing.T) {
+	type args struct {
+		dimensionRegexps []model.DimensionsRegexp
+		resources        []*model.TaggedResource
+		metric           *model.Metric
+	}
+
+	type testCase struct {
+		name             string
+		args             args
+		expectedSkip     bool
+		expectedResource *model.TaggedResource
+	}
+
+	testcases := []testCase{
+		{
+			name: "should match with VpnId dimension",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/VPN").ToModelDimensionsRegexp(),
+				resources:        vpnResources,
+				metric: &model.Metric{
+					MetricName: "TunnelState",
+					Namespace:  "AWS/VPN",
+					Dimensions: []*model.Dimension{
+						{Name: "VpnId", Value: "vpn-0123456789abcdef0"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: vpnConnection,
+		},
+		{
+			name: "should match tunnel metric with VpnId and TunnelIpAddress dimensions",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/VPN").ToModelDimensionsRegexp(),
+				resources:        vpnResources,
+				metric: &model.Metric{
+					MetricName: "TunnelDataIn",
+					Namespace:  "AWS/VPN",
+					Dimensions: []*model.Dimension{
+						{Name: "TunnelIpAddress", Value: "203.0.113.10"},
+						{Name: "VpnId", Value: "vpn-0123456789abcdef0"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: vpnConnection,
+		},
+		{
+			name: "should skip tunnel metric of an undiscovered connection",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/VPN").ToModelDimensionsRegexp(),
+				resources:        vpnResources,
+				metric: &model.Metric{
+					MetricName: "TunnelDataIn",
+					Namespace:  "AWS/VPN",
+					Dimensions: []*model.Dimension{
+						{Name: "TunnelIpAddress", Value: "203.0.113.10"},
+						{Name: "VpnId", Value: "vpn-0fedcba9876543210"},
+					},
+				},
+			},
+			expectedSkip:     true,
+			expectedResource: nil,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			associator := NewAssociator(logging.NewNopLogger(), tc.args.dimensionRegexps, tc.args.resources)
+			res, skip := associator.AssociateMetricToResource(tc.args.metric)
+			require.Equal(t, tc.expectedSkip, skip)
+			require.Equal(t, tc.expectedResource, res)
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package maxdimassociator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+var wafv2RegionalWebACL = &model.TaggedResource{
+	ARN:       "arn:aws:wafv2:us-east-1:123456789012:regional/webacl/my-web-acl/a1b2c3d4-5678-90ab-cdef-EXAMPLE11111",
+	Namespace: "AWS/WAFV2",
+}
+
+var wafv2CloudFrontWebACL = &model.TaggedResource{
+	ARN:       "arn:aws:wafv2:us-east-1:123456789012:global/webacl/my-web-acl/a1b2c3d4-5678-90ab-cdef-EXAMPLE22222",
+	Namespace: "AWS/WAFV2",
+}
+
+var wafv2Resources = []*model.TaggedResource{wafv2RegionalWebACL, wafv2CloudFrontWebACL}
+
+func TestAssociatorWAFV2(t *testing.T) {
+	type args struct {
+		dimensionRegexps []model.DimensionsRegexp
+		resources        []*model.TaggedResource
+		metric           *model.Metric
+	}
+
+	type testCase struct {
+		name             string
+		args             args
+		expectedSkip     bool
+		expectedResource *model.TaggedResource
+	}
+
+	testcases := []testCase{
+		{
+			name: "should match regional web ACL rule metric",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/WAFV2").ToModelDimensionsRegexp(),
+				resources:        wafv2Resources,
+				metric: &model.Metric{
+					MetricName: "BlockedRequests",
+					Namespace:  "AWS/WAFV2",
+					Dimensions: []*model.Dimension{
+						{Name: "Region", Value: "us-east-1"},
+						{Name: "Rule", Value: "rate-limit"},
+						{Name: "WebACL", Value: "my-web-acl"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: wafv2RegionalWebACL,
+		},
+		{
+			name: "should match CloudFront web ACL rule metric of the same name",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/WAFV2").ToModelDimensionsRegexp(),
+				resources:        wafv2Resources,
+				metric: &model.Metric{
+					MetricName: "BlockedRequests",
+					Namespace:  "AWS/WAFV2",
+					Dimensions: []*model.Dimension{
+						{Name: "Rule", Value: "ALL"},
+						{Name: "WebACL", Value: "my-web-acl"},
+					},
+				},
+			},
+			expectedSkip:     false,
+			expectedResource: wafv2CloudFrontWebACL,
+		},
+		{
+			name: "should skip metric of undiscovered web ACL",
+			args: args{
+				dimensionRegexps: config.SupportedServices.GetService("AWS/WAFV2").ToModelDimensionsRegexp(),
+				resources:        wafv2Resources,
+				metric: &model.Metric{
+					MetricName: "BlockedRequests",
+					Namespace:  "AWS/WAFV2",
+					Dimensions: []*model.Dimension{
+						{Name: "Region", Value: "us-east-1"},
+						{Name: "Rule", Value: "ALL"},
+						{Name: "WebACL", Value: "other-web-acl"},
+					},
+				},
+			},
+			expectedSkip:     true,
+			expectedResource: nil,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			associator := NewAssociator(logging.NewNopLogger(), tc.args.dimensionRegexps, tc.args.resources)
+			res, skip := associator.AssociateMetricToResource(tc.args.metric)
+			require.Equal(t, tc.expectedSkip, skip)
+			require.Equal(t, tc.expectedResource, res)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package job
+
+import (
+	"slices"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+// getMetricDataPartition is a batch of metrics queried with a single GetMetricData call.
+type getMetricDataPartition struct {
+	data           []*model.CloudwatchData
+	roundingPeriod *int64
+}
+
+// partitionGetMetricData splits the metrics into batches of at most maxMetricCount
+// queries sharing the same rounding period, since the window of a GetMetricData
+// call applies to all its metrics. Metrics without a rounding period of their
+// own are snapped to the rounding period of the job. The statistics of a metric
+// are kept in the same batch, to be computed over the same window.
+func partitionGetMetricData(getMetricDatas []*model.CloudwatchData, maxMetricCount int, jobRoundingPeriod *int64) []getMetricDataPartition {
+	var roundingPeriods []*int64
+	groups := map[int64][]*model.CloudwatchData{}
+	for _, data := range getMetricDatas {
+		roundingPeriod := jobRoundingPeriod
+		if data.RoundingPeriod != nil {
+			roundingPeriod = data.RoundingPeriod
+		}
+		// Zero is not a valid rounding period, it stands for the default one of the query
+		var key int64
+		if roundingPeriod != nil {
+			key = *roundingPeriod
+		}
+		if _, ok := groups[key]; !ok {
+			roundingPeriods = append(roundingPeriods, roundingPeriod)
+		}
+		groups[key] = append(groups[key], data)
+	}
+
+	var partitions []getMetricDataPartition
+	for _, roundingPeriod := range roundingPeriods {
+		var key int64
+		if roundingPeriod != nil {
+			key = *roundingPeriod
+		}
+		group := groups[key]
+		for start := 0; start < len(group); {
+			end, queries := start, 0
+			for end < len(group) && (end == start || queries+queryCount(group[end]) <= maxMetricCount) {
+				queries += queryCount(group[end])
+				end++
+			}
+			if end < len(group) {
+				split := end
+				for split > start && sameMetric(group[split-1], group[split]) {
+					split--
+				}
+				// A metric with more statistics than fit in a batch is split anyway
+				if split > start {
+					end = split
+				}
+			}
+			partitions = append(partitions, getMetricDataPartition{
+				data:           group[start:end],
+				roundingPeriod: roundingPeriod,
+			})
+			start = end
+		}
+	}
+	return partitions
+}
+
+// sameMetric returns whether the data query the statistics of the same metric.
+func sameMetric(a, b *model.CloudwatchData) bool {
+	if a.ID == nil || b.ID == nil || a.Metric == nil || b.Metric == nil {
+		return false
+	}
+	return *a.ID == *b.ID && *a.Metric == *b.Metric && slices.EqualFunc(a.Dimensions, b.Dimensions, func(x, y *model.Dimension) bool {
+		return *x == *y
+	})
+}
+
+// queryCount returns the number of GetMetricData queries of the data: a WeightedAverage
+// queries the Sum and the SampleCount, and the expression dividing them.
+func queryCount(data *model.CloudwatchData) int {
+	if len(data.Statistics) > 0 && data.Statistics[0] == model.StatisticWeightedAverage {
+		return 3
+	}
+	return 1
+}
@@ -0,0 +1,99 @@
+package job
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+func TestPartitionGetMetricData(t *testing.T) {
+	a := &model.CloudwatchData{MetricID: aws.String("a")}
+	b := &model.CloudwatchData{MetricID: aws.String("b"), RoundingPeriod: aws.Int64(300)}
+	c := &model.CloudwatchData{MetricID: aws.String("c")}
+	d := &model.CloudwatchData{MetricID: aws.String("d"), RoundingPeriod: aws.Int64(60)}
+	e := &model.CloudwatchData{MetricID: aws.String("e")}
+
+	testCases := []struct {
+		name              string
+		jobRoundingPeriod *int64
+		maxMetricCount    int
+		expected          []getMetricDataPartition
+	}{
+		{
+			name:           "without job rounding period",
+			maxMetricCount: 2,
+			expected: []getMetricDataPartition{
+				{data: []*model.CloudwatchData{a, c}},
+				{data: []*model.CloudwatchData{e}},
+				{data: []*model.CloudwatchData{b}, roundingPeriod: aws.Int64(300)},
+				{data: []*model.CloudwatchData{d}, roundingPeriod: aws.Int64(60)},
+			},
+		},
+		{
+			name:              "with job rounding period",
+			jobRoundingPeriod: aws.Int64(60),
+			maxMetricCount:    500,
+			expected: []getMetricDataPartition{
+				{data: []*model.CloudwatchData{a, c, d, e}, roundingPeriod: aws.Int64(60)},
+				{data: []*model.CloudwatchData{b}, roundingPeriod: aws.Int64(300)},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			partitions := partitionGetMetricData([]*model.CloudwatchData{a, b, c, d, e}, tc.maxMetricCount, tc.jobRoundingPeriod)
+			require.Equal(t, tc.expected, partitions)
+		})
+	}
+}
+
+func TestPartitionGetMetricDataKeepsStatistics(t *testing.T) {
+	dimensions := []*model.Dimension{{Name: "LoadBalancer", Value: "app/my-alb/0123456789abcdef"}}
+	newData := func(metric string, statistic string) *model.CloudwatchData {
+		return &model.CloudwatchData{
+			ID:         aws.String("arn:aws:elasticloadbalancing:eu-west-1:123456789012:loadbalancer/app/my-alb/0123456789abcdef"),
+			MetricID:   aws.String(metric + statistic),
+			Metric:     aws.String(metric),
+			Statistics: []string{statistic},
+			Dimensions: dimensions,
+		}
+	}
+	requests := newData("RequestCount", "Sum")
+	p50 := newData("TargetResponseTime", "p50")
+	p90 := newData("TargetResponseTime", "p90")
+	p99 := newData("TargetResponseTime", "p99")
+
+	partitions := partitionGetMetricData([]*model.CloudwatchData{requests, p50, p90, p99}, 3, nil)
+	require.Equal(t, []getMetricDataPartition{
+		{data: []*model.CloudwatchData{requests}},
+		{data: []*model.CloudwatchData{p50, p90, p99}},
+	}, partitions)
+
+	partitions = partitionGetMetricData([]*model.CloudwatchData{p50, p90, p99}, 2, nil)
+	require.Equal(t, []getMetricDataPartition{
+		{data: []*model.CloudwatchData{p50, p90}},
+		{data: []*model.CloudwatchData{p99}},
+	}, partitions, "a metric with more statistics than fit in a batch should be split")
+}
+
+func TestWindowRoundingPeriod(t *testing.T) {
+	require.Nil(t, (&model.MetricConfig{Period: 300}).WindowRoundingPeriod())
+	require.Equal(t, aws.Int64(300), (&model.MetricConfig{Period: 300, TimestampAlignment: model.TimestampAlignmentPeriod}).WindowRoundingPeriod())
+	require.Equal(t, aws.Int64(60), (&model.MetricConfig{Period: 300, RoundingPeriod: aws.Int64(60), TimestampAlignment: model.TimestampAlignmentPeriod}).WindowRoundingPeriod())
+}
+
+func TestPartitionGetMetricDataCountsWeightedAverageQueries(t *testing.T) {
+	a := &model.CloudwatchData{MetricID: aws.String("a"), Statistics: []string{model.StatisticWeightedAverage}}
+	b := &model.CloudwatchData{MetricID: aws.String("b"), Statistics: []string{"Sum"}}
+	c := &model.CloudwatchData{MetricID: aws.String("c"), Statistics: []string{model.StatisticWeightedAverage}}
+
+	partitions := partitionGetMetricData([]*model.CloudwatchData{a, b, c}, 4, nil)
+	require.Equal(t, []getMetricDataPartition{
+		{data: []*model.CloudwatchData{a, b}},
+		{data: []*model.CloudwatchData{c}},
+	}, partitions, "a WeightedAverage should count as its three queries")
+}
@@ -0,0 +1,146 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/cloudwatch"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/tagging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+// planningClient counts the ListMetrics requests, one per page of results.
+type planningClient struct {
+	cloudwatch.Client
+	listMetricsRequests atomic.Int64
+}
+
+func (c *planningClient) ListMetrics(ctx context.Context, namespace string, metric *model.MetricConfig, recentlyActiveOnly bool, fn func(page []*model.Metric)) error {
+	return c.Client.ListMetrics(ctx, namespace, metric, recentlyActiveOnly, func(page []*model.Metric) {
+		c.listMetricsRequests.Add(1)
+		if fn != nil {
+			fn(page)
+		}
+	})
+}
+
+// PlanAwsData discovers the resources and lists the metrics of the discovery, static
+// and custom namespace jobs as a scrape does, and returns the CloudWatch API usage of
+// the scrape per job, region and account. No datapoint is requested.
+func PlanAwsData(
+	ctx context.Context,
+	logger logging.Logger,
+	jobsCfg model.JobsConfig,
+	factory clients.Factory,
+	metricsPerQuery int,
+	cloudwatchConcurrency cloudwatch.ConcurrencyConfig,
+	taggingAPIConcurrency int,
+) []model.JobPlan {
+	mux := &sync.Mutex{}
+	plans := make([]model.JobPlan, 0)
+	var wg sync.WaitGroup
+
+	// plan runs fn with the account and the counting client of the region and role,
+	// and adds the returned plan.
+	plan := func(jobLogger logging.Logger, region string, role model.Role, fn func(logging.Logger, *planningClient) (model.JobPlan, bool)) {
+		defer wg.Done()
+		accountID, err := factory.GetAccountClient(region, role).GetAccount(ctx)
+		if err != nil {
+			jobLogger.Error(err, "Couldn't get account Id")
+			return
+		}
+		jobLogger = jobLogger.With("account", accountID)
+
+		client := &planningClient{Client: factory.GetCloudwatchClient(region, role, cloudwatchConcurrency)}
+		jobPlan, ok := fn(jobLogger, client)
+		if !ok {
+			return
+		}
+		jobPlan.Context = &model.ScrapeContext{Region: region, AccountID: accountID}
+		jobPlan.ListMetricsRequests = int(client.listMetricsRequests.Load())
+		mux.Lock()
+		plans = append(plans, jobPlan)
+		mux.Unlock()
+	}
+
+	for _, discoveryJob := range jobsCfg.DiscoveryJobs {
+		for _, role := range discoveryJob.Roles {
+			for _, region := range discoveryJob.Regions {
+				wg.Add(1)
+				jobLogger := logger.With("job_type", discoveryJob.Type, "region", region, "arn", role.RoleArn)
+				go plan(jobLogger, region, role, func(jobLogger logging.Logger, client *planningClient) (model.JobPlan, bool) {
+					svc := config.SupportedServices.GetService(discoveryJob.Type)
+					jobPlan := model.JobPlan{Job: discoveryJob.Type, Namespace: svc.Namespace}
+
+					resources, err := factory.GetTaggingClient(region, role, taggingAPIConcurrency).GetResources(ctx, discoveryJob, region)
+					if err != nil && !errors.Is(err, tagging.ErrExpectedToFindResources) {
+						jobLogger.Error(err, "Couldn't describe resources")
+						return jobPlan, false
+					}
+					jobPlan.Resources = len(resources)
+					if len(resources) == 0 {
+						return jobPlan, true
+					}
+
+					getMetricDatas, _ := getMetricDataForQueries(ctx, jobLogger, discoveryJob, svc, client, resources)
+					jobPlan.GetMetricDataQueries = queriesCount(getMetricDatas)
+					jobPlan.GetMetricDataRequests = len(partitionGetMetricData(getMetricDatas, metricsPerQuery, discoveryJob.RoundingPeriod))
+					return jobPlan, true
+				})
+			}
+		}
+	}
+
+	for _, staticJob := range jobsCfg.StaticJobs {
+		for _, role := range staticJob.Roles {
+			for _, region := range staticJob.Regions {
+				wg.Add(1)
+				jobLogger := logger.With("static_job_name", staticJob.Name, "region", region, "arn", role.RoleArn)
+				go plan(jobLogger, region, role, func(jobLogger logging.Logger, client *planningClient) (model.JobPlan, bool) {
+					jobPlan := model.JobPlan{Job: staticJob.Name, Namespace: staticJob.Namespace}
+					for _, metric := range staticJob.Metrics {
+						// Errors are logged, the metric is planned without requests
+						expanded, _ := expandStaticDimensions(ctx, jobLogger, staticJob, metric, client)
+						jobPlan.GetMetricStatisticsRequests += len(expanded)
+					}
+					return jobPlan, true
+				})
+			}
+		}
+	}
+
+	for _, customNamespaceJob := range jobsCfg.CustomNamespaceJobs {
+		for _, role := range customNamespaceJob.Roles {
+			for _, region := range customNamespaceJob.Regions {
+				wg.Add(1)
+				jobLogger := logger.With("custom_metric_namespace", customNamespaceJob.Namespace, "region", region, "arn", role.RoleArn)
+				go plan(jobLogger, region, role, func(jobLogger logging.Logger, client *planningClient) (model.JobPlan, bool) {
+					getMetricDatas, _ := getMetricDataForQueriesForCustomNamespace(ctx, customNamespaceJob, client, jobLogger)
+					return model.JobPlan{
+						Job:                   customNamespaceJob.Name,
+						Namespace:             customNamespaceJob.Namespace,
+						GetMetricDataQueries:  queriesCount(getMetricDatas),
+						GetMetricDataRequests: len(partitionGetMetricData(getMetricDatas, metricsPerQuery, customNamespaceJob.RoundingPeriod)),
+					}, true
+				})
+			}
+		}
+	}
+
+	wg.Wait()
+	return plans
+}
+
+// queriesCount returns the number of GetMetricData queries of the data.
+func queriesCount(getMetricDatas []*model.CloudwatchData) int {
+	queries := 0
+	for _, data := range getMetricDatas {
+		queries += queryCount(data)
+	}
+	return queries
+}
@@ -0,0 +1,85 @@
+package job
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/promutil"
+)
+
+// resourceChangesKey identifies the discoveries of a job in an account and region.
+type resourceChangesKey struct {
+	job        string
+	namespace  string
+	accountID  string
+	region     string
+	searchTags string
+}
+
+// resourceChanges keeps the resources of the last discovery of the discovery jobs
+// across the scrapes, to count the resources added and removed between discoveries.
+type resourceChanges struct {
+	mu        sync.Mutex
+	resources map[resourceChangesKey]map[string]struct{}
+}
+
+func newResourceChanges() *resourceChanges {
+	return &resourceChanges{resources: map[resourceChangesKey]map[string]struct{}{}}
+}
+
+// discoveredResources are the resources of the discovery jobs of all the scrapes.
+var discoveredResources = newResourceChanges()
+
+// observe records the resources of a discovery of the job, and counts the ones added
+// and removed since its previous discovery in the account and region. The first
+// discovery is the baseline, counting none.
+func (c *resourceChanges) observe(job model.DiscoveryJob, namespace string, accountID string, region string, resources []*model.TaggedResource) {
+	searchTags := make([]string, 0, len(job.SearchTags))
+	for _, st := range job.SearchTags {
+		searchTags = append(searchTags, st.Key+"="+st.Value.String())
+	}
+	key := resourceChangesKey{job: job.Type, namespace: namespace, accountID: accountID, region: region, searchTags: fmt.Sprint(searchTags)}
+
+	current := make(map[string]struct{}, len(resources))
+	for _, resource := range resources {
+		current[resource.ARN] = struct{}{}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	previous, ok := c.resources[key]
+	c.resources[key] = current
+	if ok {
+		added, removed := 0, 0
+		for arn := range current {
+			if _, ok := previous[arn]; !ok {
+				added++
+			}
+		}
+		for arn := range previous {
+			if _, ok := current[arn]; !ok {
+				removed++
+			}
+		}
+		promutil.ResourcesAddedCounter.WithLabelValues(key.job, key.namespace, key.accountID, key.region).Add(float64(added))
+		promutil.ResourcesRemovedCounter.WithLabelValues(key.job, key.namespace, key.accountID, key.region).Add(float64(removed))
+	}
+
+	// Jobs of the same type only differing by their search tags share the labels
+	discovered := 0
+	for other, otherResources := range c.resources {
+		if other.job == key.job && other.namespace == key.namespace && other.accountID == key.accountID && other.region == key.region {
+			discovered += len(otherResources)
+		}
+	}
+	promutil.ResourcesDiscoveredGauge.WithLabelValues(key.job, key.namespace, key.accountID, key.region).Set(float64(discovered))
+}
+
+// resourcesDiscovered returns whether the resources of a discovery job were found,
+// the job having failed after their discovery if err is not nil.
+func resourcesDiscovered(err error) bool {
+	var scrapeErr *scrapeError
+	return err == nil || (errors.As(err, &scrapeErr) && scrapeErr.reason != reasonGetResources)
+}
@@ -0,0 +1,57 @@
+package job
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/grafana/regexp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/promutil"
+)
+
+func TestResourceChanges(t *testing.T) {
+	promutil.ResourcesAddedCounter.Reset()
+	promutil.ResourcesRemovedCounter.Reset()
+	promutil.ResourcesDiscoveredGauge.Reset()
+
+	resources := func(arns ...string) []*model.TaggedResource {
+		result := make([]*model.TaggedResource, 0, len(arns))
+		for _, arn := range arns {
+			result = append(result, &model.TaggedResource{ARN: arn})
+		}
+		return result
+	}
+	queues := model.DiscoveryJob{Type: "AWS/SQS"}
+	taggedQueues := model.DiscoveryJob{Type: "AWS/SQS", SearchTags: []model.SearchTag{{Key: "team", Value: regexp.MustCompile("payments")}}}
+	labels := []string{"AWS/SQS", "AWS/SQS", "111111111111", "eu-west-1"}
+
+	changes := newResourceChanges()
+	changes.observe(queues, "AWS/SQS", "111111111111", "eu-west-1", resources("queue-a", "queue-b"))
+	// The first discovery is the baseline
+	require.Equal(t, float64(0), testutil.ToFloat64(promutil.ResourcesAddedCounter.WithLabelValues(labels...)))
+	require.Equal(t, float64(2), testutil.ToFloat64(promutil.ResourcesDiscoveredGauge.WithLabelValues(labels...)))
+
+	changes.observe(queues, "AWS/SQS", "111111111111", "eu-west-1", resources("queue-b", "queue-c", "queue-d"))
+	require.Equal(t, float64(2), testutil.ToFloat64(promutil.ResourcesAddedCounter.WithLabelValues(labels...)))
+	require.Equal(t, float64(1), testutil.ToFloat64(promutil.ResourcesRemovedCounter.WithLabelValues(labels...)))
+	require.Equal(t, float64(3), testutil.ToFloat64(promutil.ResourcesDiscoveredGauge.WithLabelValues(labels...)))
+
+	// A job of the same type with other search tags has its own baseline and shares the gauge
+	changes.observe(taggedQueues, "AWS/SQS", "111111111111", "eu-west-1", resources("queue-e"))
+	require.Equal(t, float64(2), testutil.ToFloat64(promutil.ResourcesAddedCounter.WithLabelValues(labels...)))
+	require.Equal(t, float64(4), testutil.ToFloat64(promutil.ResourcesDiscoveredGauge.WithLabelValues(labels...)))
+
+	changes.observe(queues, "AWS/SQS", "111111111111", "eu-west-1", nil)
+	require.Equal(t, float64(4), testutil.ToFloat64(promutil.ResourcesRemovedCounter.WithLabelValues(labels...)))
+	require.Equal(t, float64(1), testutil.ToFloat64(promutil.ResourcesDiscoveredGauge.WithLabelValues(labels...)))
+}
+
+func TestResourcesDiscovered(t *testing.T) {
+	require.True(t, resourcesDiscovered(nil))
+	require.True(t, resourcesDiscovered(&scrapeError{reason: reasonGetMetricData, err: errors.New("boom")}))
+	require.False(t, resourcesDiscovered(&scrapeError{reason: reasonGetResources, err: errors.New("boom")}))
+	require.False(t, resourcesDiscovered(errors.New("boom")))
+}
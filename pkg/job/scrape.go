@@ -3,9 +3,12 @@ package job
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients"
 	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/cloudwatch"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/retries"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/useragent"
 	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
 	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
 	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
@@ -19,10 +22,14 @@ func ScrapeAwsData(
 	metricsPerQuery int,
 	cloudwatchConcurrency cloudwatch.ConcurrencyConfig,
 	taggingAPIConcurrency int,
-) ([]model.TaggedResourceResult, []model.CloudwatchMetricResult) {
+) ([]model.TaggedResourceResult, []model.CloudwatchMetricResult, []model.UnmatchedResult, []model.JobOutcome) {
 	mux := &sync.Mutex{}
 	cwData := make([]model.CloudwatchMetricResult, 0)
 	awsInfoData := make([]model.TaggedResourceResult, 0)
+	unmatchedData := make([]model.UnmatchedResult, 0)
+	health := newAccountHealth()
+	outcomes := &jobOutcomes{}
+	now := time.Now()
 	var wg sync.WaitGroup
 
 	for _, discoveryJob := range jobsCfg.DiscoveryJobs {
@@ -31,17 +38,37 @@ func ScrapeAwsData(
 				wg.Add(1)
 				go func(discoveryJob model.DiscoveryJob, region string, role model.Role) {
 					defer wg.Done()
+					ctx := useragent.WithJob(ctx, discoveryJob.Type)
 					jobLogger := logger.With("job_type", discoveryJob.Type, "region", region, "arn", role.RoleArn)
+					if !discoveryJob.Schedule.Scraped(now) {
+						jobLogger.Debug("Skipping job outside of its schedule")
+						outcomes.recordPaused(discoveryJob.Type, discoveryJob.UnhealthyAfter, role, region)
+						return
+					}
 					accountID, err := factory.GetAccountClient(region, role).GetAccount(ctx)
 					if err != nil {
 						jobLogger.Error(err, "Couldn't get account Id")
+						health.record("", role, region, &scrapeError{reason: reasonGetAccount, err: err})
+						outcomes.record(discoveryJob.Type, discoveryJob.UnhealthyAfter, "", role, region, err)
 						return
 					}
 					jobLogger = jobLogger.With("account", accountID)
+					ctx = retries.WithAccount(ctx, accountID)
 
-					resources, metrics := runDiscoveryJob(ctx, jobLogger, discoveryJob, region, factory.GetTaggingClient(region, role, taggingAPIConcurrency), factory.GetCloudwatchClient(region, role, cloudwatchConcurrency), metricsPerQuery, cloudwatchConcurrency)
+					resources, metrics, unmatched, err := runDiscoveryJob(ctx, jobLogger, discoveryJob, region, factory.GetTaggingClient(region, role, taggingAPIConcurrency), factory.GetCloudwatchClient(region, role, cloudwatchConcurrency), metricsPerQuery, cloudwatchConcurrency)
+					health.record(accountID, role, region, err)
+					outcomes.record(discoveryJob.Type, discoveryJob.UnhealthyAfter, accountID, role, region, err)
+					if resourcesDiscovered(err) {
+						discoveredResources.observe(discoveryJob, config.SupportedServices.GetService(discoveryJob.Type).Namespace, accountID, region, resources)
+					}
+					if unmatched != nil && (len(unmatched.Resources) > 0 || len(unmatched.Metrics) > 0) {
+						unmatched.Context = &model.ScrapeContext{Region: region, AccountID: accountID}
+						mux.Lock()
+						unmatchedData = append(unmatchedData, *unmatched)
+						mux.Unlock()
+					}
 					addDataToOutput := len(metrics) != 0
-					if config.FlagsFromCtx(ctx).IsFeatureEnabled(config.AlwaysReturnInfoMetrics) {
+					if !discoveryJob.DisableInfoMetrics && config.FlagsFromCtx(ctx).IsFeatureEnabled(config.AlwaysReturnInfoMetrics) {
 						addDataToOutput = addDataToOutput || len(resources) != 0
 					}
 					if addDataToOutput {
@@ -51,18 +78,24 @@ func ScrapeAwsData(
 							CustomTags: discoveryJob.CustomTags,
 						}
 						metricResult := model.CloudwatchMetricResult{
-							Context: sc,
-							Data:    metrics,
+							Context:         sc,
+							Data:            metrics,
+							LabelTransforms: discoveryJob.LabelTransforms,
+							Processors:      jobProcessors(ctx, jobLogger, discoveryJob.Processors),
+							NamespaceAlias:  discoveryJob.NamespaceAlias,
 						}
 						resourceResult := model.TaggedResourceResult{
-							Data: resources,
+							Data:           resources,
+							NamespaceAlias: discoveryJob.NamespaceAlias,
 						}
 						if discoveryJob.IncludeContextOnInfoMetrics {
 							resourceResult.Context = sc
 						}
 
 						mux.Lock()
-						awsInfoData = append(awsInfoData, resourceResult)
+						if !discoveryJob.DisableInfoMetrics {
+							awsInfoData = append(awsInfoData, resourceResult)
+						}
 						cwData = append(cwData, metricResult)
 						mux.Unlock()
 					}
@@ -77,22 +110,36 @@ func ScrapeAwsData(
 				wg.Add(1)
 				go func(staticJob model.StaticJob, region string, role model.Role) {
 					defer wg.Done()
+					ctx := useragent.WithJob(ctx, staticJob.Name)
 					jobLogger := logger.With("static_job_name", staticJob.Name, "region", region, "arn", role.RoleArn)
+					if !staticJob.Schedule.Scraped(now) {
+						jobLogger.Debug("Skipping job outside of its schedule")
+						outcomes.recordPaused(staticJob.Name, staticJob.UnhealthyAfter, role, region)
+						return
+					}
 					accountID, err := factory.GetAccountClient(region, role).GetAccount(ctx)
 					if err != nil {
 						jobLogger.Error(err, "Couldn't get account Id")
+						health.record("", role, region, &scrapeError{reason: reasonGetAccount, err: err})
+						outcomes.record(staticJob.Name, staticJob.UnhealthyAfter, "", role, region, err)
 						return
 					}
 					jobLogger = jobLogger.With("account", accountID)
+					ctx = retries.WithAccount(ctx, accountID)
 
-					metrics := runStaticJob(ctx, jobLogger, staticJob, factory.GetCloudwatchClient(region, role, cloudwatchConcurrency))
+					metrics, err := runStaticJob(ctx, jobLogger, staticJob, factory.GetCloudwatchClient(region, role, cloudwatchConcurrency))
+					health.record(accountID, role, region, err)
+					outcomes.record(staticJob.Name, staticJob.UnhealthyAfter, accountID, role, region, err)
 					metricResult := model.CloudwatchMetricResult{
 						Context: &model.ScrapeContext{
 							Region:     region,
 							AccountID:  accountID,
 							CustomTags: staticJob.CustomTags,
 						},
-						Data: metrics,
+						Data:            metrics,
+						LabelTransforms: staticJob.LabelTransforms,
+						Processors:      jobProcessors(ctx, jobLogger, staticJob.Processors),
+						NamespaceAlias:  staticJob.NamespaceAlias,
 					}
 					mux.Lock()
 					cwData = append(cwData, metricResult)
@@ -108,22 +155,36 @@ func ScrapeAwsData(
 				wg.Add(1)
 				go func(customNamespaceJob model.CustomNamespaceJob, region string, role model.Role) {
 					defer wg.Done()
+					ctx := useragent.WithJob(ctx, customNamespaceJob.Name)
 					jobLogger := logger.With("custom_metric_namespace", customNamespaceJob.Namespace, "region", region, "arn", role.RoleArn)
+					if !customNamespaceJob.Schedule.Scraped(now) {
+						jobLogger.Debug("Skipping job outside of its schedule")
+						outcomes.recordPaused(customNamespaceJob.Name, customNamespaceJob.UnhealthyAfter, role, region)
+						return
+					}
 					accountID, err := factory.GetAccountClient(region, role).GetAccount(ctx)
 					if err != nil {
 						jobLogger.Error(err, "Couldn't get account Id")
+						health.record("", role, region, &scrapeError{reason: reasonGetAccount, err: err})
+						outcomes.record(customNamespaceJob.Name, customNamespaceJob.UnhealthyAfter, "", role, region, err)
 						return
 					}
 					jobLogger = jobLogger.With("account", accountID)
+					ctx = retries.WithAccount(ctx, accountID)
 
-					metrics := runCustomNamespaceJob(ctx, jobLogger, customNamespaceJob, factory.GetCloudwatchClient(region, role, cloudwatchConcurrency), metricsPerQuery)
+					metrics, err := runCustomNamespaceJob(ctx, jobLogger, customNamespaceJob, factory.GetCloudwatchClient(region, role, cloudwatchConcurrency), metricsPerQuery)
+					health.record(accountID, role, region, err)
+					outcomes.record(customNamespaceJob.Name, customNamespaceJob.UnhealthyAfter, accountID, role, region, err)
 					metricResult := model.CloudwatchMetricResult{
 						Context: &model.ScrapeContext{
 							Region:     region,
 							AccountID:  accountID,
 							CustomTags: customNamespaceJob.CustomTags,
 						},
-						Data: metrics,
+						Data:            metrics,
+						LabelTransforms: customNamespaceJob.LabelTransforms,
+						Processors:      jobProcessors(ctx, jobLogger, customNamespaceJob.Processors),
+						NamespaceAlias:  customNamespaceJob.NamespaceAlias,
 					}
 					mux.Lock()
 					cwData = append(cwData, metricResult)
@@ -132,6 +193,87 @@ func ScrapeAwsData(
 			}
 		}
 	}
+
+	for _, customNamespaceDiscoveryJob := range jobsCfg.CustomNamespaceDiscoveryJobs {
+		for _, role := range customNamespaceDiscoveryJob.Roles {
+			for _, region := range customNamespaceDiscoveryJob.Regions {
+				wg.Add(1)
+				go func(customNamespaceDiscoveryJob model.CustomNamespaceDiscoveryJob, region string, role model.Role) {
+					defer wg.Done()
+					ctx := useragent.WithJob(ctx, "custom-namespaces-discovery")
+					jobLogger := logger.With("custom_namespaces_discovery", true, "region", region, "arn", role.RoleArn)
+					accountID, err := factory.GetAccountClient(region, role).GetAccount(ctx)
+					if err != nil {
+						jobLogger.Error(err, "Couldn't get account Id")
+						health.record("", role, region, &scrapeError{reason: reasonGetAccount, err: err})
+						return
+					}
+					jobLogger = jobLogger.With("account", accountID)
+					ctx = retries.WithAccount(ctx, accountID)
+
+					metrics, err := runCustomNamespaceDiscoveryJob(ctx, jobLogger, customNamespaceDiscoveryJob, accountID, region, factory.GetCloudwatchClient(region, role, cloudwatchConcurrency), metricsPerQuery)
+					health.record(accountID, role, region, err)
+					metricResult := model.CloudwatchMetricResult{
+						Context: &model.ScrapeContext{
+							Region:     region,
+							AccountID:  accountID,
+							CustomTags: customNamespaceDiscoveryJob.CustomTags,
+						},
+						Data: metrics,
+					}
+					mux.Lock()
+					cwData = append(cwData, metricResult)
+					mux.Unlock()
+				}(customNamespaceDiscoveryJob, region, role)
+			}
+		}
+	}
+
+	for _, costExplorerJob := range jobsCfg.CostExplorerJobs {
+		for _, role := range costExplorerJob.Roles {
+			wg.Add(1)
+			go func(costExplorerJob model.CostExplorerJob, role model.Role) {
+				defer wg.Done()
+				ctx := useragent.WithJob(ctx, costExplorerJob.Name)
+				jobLogger := logger.With("cost_explorer_job_name", costExplorerJob.Name, "arn", role.RoleArn)
+				accountID, err := factory.GetAccountClient(costExplorerJob.Region, role).GetAccount(ctx)
+				if err != nil {
+					jobLogger.Error(err, "Couldn't get account Id")
+					health.record("", role, costExplorerJob.Region, &scrapeError{reason: reasonGetAccount, err: err})
+					return
+				}
+				jobLogger = jobLogger.With("account", accountID)
+				ctx = retries.WithAccount(ctx, accountID)
+
+				metrics, err := runCostExplorerJob(ctx, jobLogger, costExplorerJob, factory.GetCostExplorerClient(costExplorerJob.Region, role))
+				health.record(accountID, role, costExplorerJob.Region, err)
+				metricResult := model.CloudwatchMetricResult{
+					Context: &model.ScrapeContext{
+						Region:     costExplorerJob.Region,
+						AccountID:  accountID,
+						CustomTags: costExplorerJob.CustomTags,
+					},
+					Data: metrics,
+				}
+				mux.Lock()
+				cwData = append(cwData, metricResult)
+				mux.Unlock()
+			}(costExplorerJob, role)
+		}
+	}
 	wg.Wait()
-	return awsInfoData, cwData
+	health.report(time.Now())
+	return awsInfoData, cwData, unmatchedData, outcomes.outcomes
+}
+
+// jobProcessors returns the processors of a job, if the processor-plugins feature flag is enabled.
+func jobProcessors(ctx context.Context, logger logging.Logger, processors []model.ProcessorConfig) []model.ProcessorConfig {
+	if len(processors) == 0 {
+		return nil
+	}
+	if !config.FlagsFromCtx(ctx).IsFeatureEnabled(config.ProcessorPlugins) {
+		logger.Warn("Ignoring the processors of the job, the processor-plugins feature flag is not enabled")
+		return nil
+	}
+	return processors
 }
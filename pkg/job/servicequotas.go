@@ -0,0 +1,143 @@
+package job
+
+import (
+	"context"
+	"sync"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/cloudwatch"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/retries"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/servicequotas"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/useragent"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+const (
+	// Usage metrics of AWS/Usage are published every minute, but
+	// may be a few minutes late.
+	serviceQuotaUsagePeriod = int64(300)
+	serviceQuotaUsageLength = int64(900)
+
+	defaultServiceQuotaUsageStatistic = "Maximum"
+)
+
+// ScrapeServiceQuotas lists the quotas of the service quota jobs, and
+// retrieves their usage from CloudWatch when a usage metric is available.
+func ScrapeServiceQuotas(
+	ctx context.Context,
+	logger logging.Logger,
+	jobsCfg model.JobsConfig,
+	factory clients.Factory,
+	cloudwatchConcurrency cloudwatch.ConcurrencyConfig,
+) []model.ServiceQuotaResult {
+	mux := &sync.Mutex{}
+	quotaData := make([]model.ServiceQuotaResult, 0)
+	var wg sync.WaitGroup
+
+	for _, serviceQuotaJob := range jobsCfg.ServiceQuotaJobs {
+		for _, role := range serviceQuotaJob.Roles {
+			for _, region := range serviceQuotaJob.Regions {
+				wg.Add(1)
+				go func(serviceQuotaJob model.ServiceQuotaJob, region string, role model.Role) {
+					defer wg.Done()
+					ctx := useragent.WithJob(ctx, "service-quotas")
+					jobLogger := logger.With("service_quotas", true, "region", region, "arn", role.RoleArn)
+					accountID, err := factory.GetAccountClient(region, role).GetAccount(ctx)
+					if err != nil {
+						jobLogger.Error(err, "Couldn't get account Id")
+						return
+					}
+					jobLogger = jobLogger.With("account", accountID)
+					ctx = retries.WithAccount(ctx, accountID)
+
+					quotas := runServiceQuotaJob(ctx, jobLogger, serviceQuotaJob, factory.GetServiceQuotasClient(region, role), factory.GetCloudwatchClient(region, role, cloudwatchConcurrency))
+					if len(quotas) == 0 {
+						return
+					}
+
+					mux.Lock()
+					quotaData = append(quotaData, model.ServiceQuotaResult{
+						Context: &model.ScrapeContext{
+							Region:     region,
+							AccountID:  accountID,
+							CustomTags: serviceQuotaJob.CustomTags,
+						},
+						Data: quotas,
+					})
+					mux.Unlock()
+				}(serviceQuotaJob, region, role)
+			}
+		}
+	}
+	wg.Wait()
+	return quotaData
+}
+
+func runServiceQuotaJob(
+	ctx context.Context,
+	logger logging.Logger,
+	job model.ServiceQuotaJob,
+	clientQuotas servicequotas.Client,
+	clientCloudwatch cloudwatch.Client,
+) []*model.ServiceQuota {
+	quotas := []*model.ServiceQuota{}
+	for _, serviceCode := range job.ServiceCodes {
+		serviceQuotas, err := clientQuotas.ListServiceQuotas(ctx, serviceCode)
+		if err != nil {
+			logger.Error(err, "Couldn't list service quotas", "service_code", serviceCode)
+			continue
+		}
+		quotas = append(quotas, serviceQuotas...)
+	}
+
+	for _, quota := range quotas {
+		if quota.UsageMetric == nil {
+			continue
+		}
+		statistic := quota.UsageMetric.Statistic
+		if statistic == "" {
+			statistic = defaultServiceQuotaUsageStatistic
+		}
+		datapoints := clientCloudwatch.GetMetricStatistics(ctx, logger, quota.UsageMetric.Dimensions, quota.UsageMetric.Namespace, &model.MetricConfig{
+			Name:       quota.UsageMetric.MetricName,
+			Statistics: []string{statistic},
+			Period:     serviceQuotaUsagePeriod,
+			Length:     serviceQuotaUsageLength,
+		})
+		quota.Usage = latestDatapointValue(datapoints, statistic)
+	}
+	return quotas
+}
+
+// latestDatapointValue returns the value of the statistic
+// of the most recent datapoint, or nil if there is none.
+func latestDatapointValue(datapoints []*model.Datapoint, statistic string) *float64 {
+	var latest *model.Datapoint
+	for _, datapoint := range datapoints {
+		if datapoint.Timestamp == nil {
+			continue
+		}
+		if latest == nil || datapoint.Timestamp.After(*latest.Timestamp) {
+			latest = datapoint
+		}
+	}
+	if latest == nil {
+		return nil
+	}
+
+	switch statistic {
+	case "Maximum":
+		return latest.Maximum
+	case "Minimum":
+		return latest.Minimum
+	case "Sum":
+		return latest.Sum
+	case "SampleCount":
+		return latest.SampleCount
+	case "Average":
+		return latest.Average
+	default:
+		return latest.ExtendedStatistics[statistic]
+	}
+}
@@ -2,6 +2,7 @@ package job
 
 import (
 	"context"
+	"fmt"
 	"sync"
 
 	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/cloudwatch"
@@ -9,15 +10,23 @@ import (
 	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
 )
 
+// staticDimensionWildcard is the dimension value of static jobs
+// expanded into all the values observed with ListMetrics.
+const staticDimensionWildcard = "*"
+
+// runStaticJob returns the datapoints of the metrics of the job, and an error when
+// some metrics could not be listed or some GetMetricStatistics requests failed.
 func runStaticJob(
 	ctx context.Context,
 	logger logging.Logger,
 	resource model.StaticJob,
 	clientCloudwatch cloudwatch.Client,
-) []*model.CloudwatchData {
+) ([]*model.CloudwatchData, error) {
 	cw := []*model.CloudwatchData{}
 	mux := &sync.Mutex{}
 	var wg sync.WaitGroup
+	var listErr error
+	requests, failed := 0, 0
 
 	for j := range resource.Metrics {
 		metric := resource.Metrics[j]
@@ -25,28 +34,110 @@ func runStaticJob(
 		go func() {
 			defer wg.Done()
 
-			id := resource.Name
-			data := model.CloudwatchData{
-				ID:                     &id,
-				Metric:                 &metric.Name,
-				Namespace:              &resource.Namespace,
-				Statistics:             metric.Statistics,
-				NilToZero:              metric.NilToZero,
-				AddCloudwatchTimestamp: metric.AddCloudwatchTimestamp,
-				Dimensions:             createStaticDimensions(resource.Dimensions),
+			expanded, err := expandStaticDimensions(ctx, logger, resource, metric, clientCloudwatch)
+			if err != nil {
+				mux.Lock()
+				if listErr == nil {
+					listErr = err
+				}
+				mux.Unlock()
+				return
 			}
 
-			data.Points = clientCloudwatch.GetMetricStatistics(ctx, logger, data.Dimensions, resource.Namespace, metric)
+			for _, dimensions := range expanded {
+				id := resource.Name
+				data := model.CloudwatchData{
+					ID:                     &id,
+					Metric:                 &metric.Name,
+					Namespace:              &resource.Namespace,
+					Statistics:             metric.Statistics,
+					NilToZero:              metric.NilToZero,
+					AddCloudwatchTimestamp: metric.AddCloudwatchTimestamp,
+					Dimensions:             dimensions,
+					StatLabelMode:          metric.StatLabelMode,
+				}
+
+				data.Points = clientCloudwatch.GetMetricStatistics(ctx, logger, data.Dimensions, resource.Namespace, metric)
 
-			if data.Points != nil {
 				mux.Lock()
-				cw = append(cw, &data)
+				requests++
+				// The client only returns no points when the request failed
+				if data.Points != nil {
+					cw = append(cw, &data)
+				} else {
+					failed++
+				}
 				mux.Unlock()
 			}
 		}()
 	}
 	wg.Wait()
-	return cw
+
+	if listErr != nil {
+		return cw, &scrapeError{reason: reasonGetMetricData, err: listErr}
+	}
+	if failed > 0 {
+		return cw, &scrapeError{reason: reasonGetMetricData, err: fmt.Errorf("%d of %d GetMetricStatistics requests failed", failed, requests)}
+	}
+	return cw, nil
+}
+
+// expandStaticDimensions returns the dimension sets to query for a metric of a static job.
+// Dimensions with the "*" value are expanded into all the values observed with ListMetrics.
+func expandStaticDimensions(
+	ctx context.Context,
+	logger logging.Logger,
+	resource model.StaticJob,
+	metric *model.MetricConfig,
+	clientCloudwatch cloudwatch.Client,
+) ([][]*model.Dimension, error) {
+	if !hasStaticDimensionWildcard(resource.Dimensions) {
+		return [][]*model.Dimension{createStaticDimensions(resource.Dimensions)}, nil
+	}
+
+	var expanded [][]*model.Dimension
+	err := clientCloudwatch.ListMetrics(ctx, resource.Namespace, metric, false, func(page []*model.Metric) {
+		for _, cwMetric := range page {
+			if staticDimensionsMatch(resource.Dimensions, cwMetric.Dimensions) {
+				expanded = append(expanded, cwMetric.Dimensions)
+			}
+		}
+	})
+	if err != nil {
+		logger.Error(err, "Failed to list metrics to expand static dimensions", "namespace", resource.Namespace, "metric", metric.Name)
+		return nil, err
+	}
+	return expanded, nil
+}
+
+func hasStaticDimensionWildcard(dimensions []model.Dimension) bool {
+	for _, d := range dimensions {
+		if d.Value == staticDimensionWildcard {
+			return true
+		}
+	}
+	return false
+}
+
+// staticDimensionsMatch returns true when dimensions have exactly the names of
+// the static dimensions, and the same values for the ones not set to "*".
+func staticDimensionsMatch(static []model.Dimension, dimensions []*model.Dimension) bool {
+	if len(static) != len(dimensions) {
+		return false
+	}
+	for _, s := range static {
+		foundMatch := false
+		for _, d := range dimensions {
+			if d.Name == s.Name && (s.Value == staticDimensionWildcard || d.Value == s.Value) {
+				foundMatch = true
+				break
+			}
+		}
+		if !foundMatch {
+			return false
+		}
+	}
+	return true
 }
 
 func createStaticDimensions(dimensions []model.Dimension) []*model.Dimension {
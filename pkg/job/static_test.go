@@ -0,0 +1,130 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/cloudwatch"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+// staticClient returns datapoints for the metrics of the points map, and fails the other requests.
+type staticClient struct {
+	listErr error
+	metrics []*model.Metric
+	points  map[string][]*model.Datapoint
+}
+
+func (c staticClient) ListMetrics(_ context.Context, _ string, _ *model.MetricConfig, _ bool, fn func(page []*model.Metric)) error {
+	if c.listErr != nil {
+		return c.listErr
+	}
+	fn(c.metrics)
+	return nil
+}
+
+func (c staticClient) GetMetricData(_ context.Context, _ logging.Logger, _ []*model.CloudwatchData, _ string, _ int64, _ int64, _ *int64, _ bool) []cloudwatch.MetricDataResult {
+	return nil
+}
+
+func (c staticClient) GetMetricStatistics(_ context.Context, _ logging.Logger, _ []*model.Dimension, _ string, metric *model.MetricConfig) []*model.Datapoint {
+	return c.points[metric.Name]
+}
+
+func TestRunStaticJob(t *testing.T) {
+	job := model.StaticJob{
+		Name:       "checkout",
+		Namespace:  "Checkout",
+		Dimensions: []model.Dimension{{Name: "Service", Value: "checkout"}},
+		Metrics: []*model.MetricConfig{
+			{Name: "Orders", Statistics: []string{"Sum"}},
+			{Name: "Errors", Statistics: []string{"Sum"}},
+		},
+	}
+	sum := 1.0
+
+	t.Run("all requests succeeded", func(t *testing.T) {
+		client := staticClient{points: map[string][]*model.Datapoint{
+			"Orders": {{Sum: &sum}},
+			"Errors": {},
+		}}
+		metrics, err := runStaticJob(context.Background(), logging.NewNopLogger(), job, client)
+		require.NoError(t, err)
+		require.Len(t, metrics, 2)
+	})
+
+	t.Run("failed request", func(t *testing.T) {
+		client := staticClient{points: map[string][]*model.Datapoint{
+			"Orders": {{Sum: &sum}},
+		}}
+		metrics, err := runStaticJob(context.Background(), logging.NewNopLogger(), job, client)
+		require.EqualError(t, err, "get_metric_data: 1 of 2 GetMetricStatistics requests failed")
+		require.Len(t, metrics, 1)
+		require.Equal(t, "Orders", *metrics[0].Metric)
+	})
+
+	t.Run("failed listing of the wildcard dimensions", func(t *testing.T) {
+		wildcardJob := job
+		wildcardJob.Dimensions = []model.Dimension{{Name: "Service", Value: "*"}}
+		client := staticClient{listErr: errors.New("throttled")}
+		metrics, err := runStaticJob(context.Background(), logging.NewNopLogger(), wildcardJob, client)
+		require.EqualError(t, err, "get_metric_data: throttled")
+		require.Empty(t, metrics)
+	})
+}
+
+func TestStaticDimensionsMatch(t *testing.T) {
+	static := []model.Dimension{
+		{Name: "Service", Value: "checkout"},
+		{Name: "Host", Value: "*"},
+	}
+
+	testCases := []struct {
+		name       string
+		dimensions []*model.Dimension
+		match      bool
+	}{
+		{
+			name: "wildcard value",
+			dimensions: []*model.Dimension{
+				{Name: "Host", Value: "host-1"},
+				{Name: "Service", Value: "checkout"},
+			},
+			match: true,
+		},
+		{
+			name: "different static value",
+			dimensions: []*model.Dimension{
+				{Name: "Service", Value: "payment"},
+				{Name: "Host", Value: "host-1"},
+			},
+			match: false,
+		},
+		{
+			name: "missing dimension",
+			dimensions: []*model.Dimension{
+				{Name: "Service", Value: "checkout"},
+			},
+			match: false,
+		},
+		{
+			name: "extra dimension",
+			dimensions: []*model.Dimension{
+				{Name: "Service", Value: "checkout"},
+				{Name: "Host", Value: "host-1"},
+				{Name: "Region", Value: "eu-west-1"},
+			},
+			match: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.match, staticDimensionsMatch(static, tc.dimensions))
+		})
+	}
+}
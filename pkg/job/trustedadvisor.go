@@ -0,0 +1,67 @@
+package job
+
+import (
+	"context"
+	"sync"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/retries"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/useragent"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+// ScrapeTrustedAdvisor retrieves the results of the Trusted Advisor checks. The
+// results are cached by the clients for the refresh interval of the jobs.
+func ScrapeTrustedAdvisor(
+	ctx context.Context,
+	logger logging.Logger,
+	jobsCfg model.JobsConfig,
+	factory clients.Factory,
+) []model.TrustedAdvisorResult {
+	mux := &sync.Mutex{}
+	checkData := make([]model.TrustedAdvisorResult, 0)
+	var wg sync.WaitGroup
+
+	for _, trustedAdvisorJob := range jobsCfg.TrustedAdvisorJobs {
+		for _, role := range trustedAdvisorJob.Roles {
+			wg.Add(1)
+			go func(trustedAdvisorJob model.TrustedAdvisorJob, role model.Role) {
+				defer wg.Done()
+				ctx := useragent.WithJob(ctx, "trusted-advisor")
+				region := trustedAdvisorJob.Region
+				jobLogger := logger.With("trusted_advisor", true, "region", region, "arn", role.RoleArn)
+				accountID, err := factory.GetAccountClient(region, role).GetAccount(ctx)
+				if err != nil {
+					jobLogger.Error(err, "Couldn't get account Id")
+					return
+				}
+				jobLogger = jobLogger.With("account", accountID)
+				ctx = retries.WithAccount(ctx, accountID)
+
+				checks, err := factory.GetTrustedAdvisorClient(region, role).DescribeChecks(ctx, trustedAdvisorJob)
+				if err != nil {
+					// The support API is only available with a Business or Enterprise support plan
+					jobLogger.Error(err, "Couldn't describe Trusted Advisor checks")
+					return
+				}
+				if len(checks) == 0 {
+					return
+				}
+
+				mux.Lock()
+				checkData = append(checkData, model.TrustedAdvisorResult{
+					Context: &model.ScrapeContext{
+						Region:     region,
+						AccountID:  accountID,
+						CustomTags: trustedAdvisorJob.CustomTags,
+					},
+					Data: checks,
+				})
+				mux.Unlock()
+			}(trustedAdvisorJob, role)
+		}
+	}
+	wg.Wait()
+	return checkData
+}
@@ -0,0 +1,49 @@
+package job
+
+import (
+	"sync"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+// maxUnmatched caps the unmatched resources and metrics kept per job, region and account.
+const maxUnmatched = 100
+
+// recordingAssociator records the metrics skipped by the associator for not matching any resource.
+type recordingAssociator struct {
+	resourceAssociator
+	mu      sync.Mutex
+	skipped []*model.Metric
+}
+
+func (r *recordingAssociator) AssociateMetricToResource(cwMetric *model.Metric) (*model.TaggedResource, bool) {
+	resource, skip := r.resourceAssociator.AssociateMetricToResource(cwMetric)
+	if skip {
+		r.mu.Lock()
+		if len(r.skipped) < maxUnmatched {
+			r.skipped = append(r.skipped, cwMetric)
+		}
+		r.mu.Unlock()
+	}
+	return resource, skip
+}
+
+// unmatchedResources returns the ARNs of the resources which no metric was associated with.
+func unmatchedResources(resources []*model.TaggedResource, datas []*model.CloudwatchData) []string {
+	matched := make(map[string]struct{}, len(datas))
+	for _, data := range datas {
+		matched[*data.ID] = struct{}{}
+	}
+
+	var unmatched []string
+	for _, resource := range resources {
+		if _, ok := matched[resource.ARN]; ok {
+			continue
+		}
+		unmatched = append(unmatched, resource.ARN)
+		if len(unmatched) == maxUnmatched {
+			break
+		}
+	}
+	return unmatched
+}
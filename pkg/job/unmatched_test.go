@@ -0,0 +1,32 @@
+package job
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+type fixedAssociator map[string]*model.TaggedResource
+
+func (a fixedAssociator) AssociateMetricToResource(cwMetric *model.Metric) (*model.TaggedResource, bool) {
+	resource, ok := a[cwMetric.MetricName]
+	return resource, !ok
+}
+
+func TestUnmatched(t *testing.T) {
+	queue := &model.TaggedResource{ARN: "arn:aws:sqs:eu-west-1:111111111111:queue"}
+	recorder := &recordingAssociator{resourceAssociator: fixedAssociator{"NumberOfMessagesSent": queue}}
+
+	resource, skip := recorder.AssociateMetricToResource(&model.Metric{MetricName: "NumberOfMessagesSent"})
+	require.False(t, skip)
+	require.Equal(t, queue, resource)
+	_, skip = recorder.AssociateMetricToResource(&model.Metric{MetricName: "NumberOfMessagesDeleted"})
+	require.True(t, skip)
+	require.Equal(t, []*model.Metric{{MetricName: "NumberOfMessagesDeleted"}}, recorder.skipped)
+
+	resources := []*model.TaggedResource{queue, {ARN: "arn:aws:sqs:eu-west-1:111111111111:idle"}}
+	datas := []*model.CloudwatchData{{ID: &queue.ARN}}
+	require.Equal(t, []string{"arn:aws:sqs:eu-west-1:111111111111:idle"}, unmatchedResources(resources, datas))
+}
@@ -0,0 +1,38 @@
+package job
+
+import (
+	"slices"
+
+	"github.com/grafana/regexp"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+const (
+	wafRuleDimension = "Rule"
+	// wafRuleAll is the Rule dimension value of the web ACL totals
+	wafRuleAll = "ALL"
+)
+
+// filterWAFRuleMetrics drops the AWS/WAFV2 rule level metrics whose Rule
+// dimension does not match any of the given regexps. The metrics of the ALL
+// rule and metrics without a Rule dimension are always kept.
+func filterWAFRuleMetrics(rules []*regexp.Regexp, metrics []*model.Metric) []*model.Metric {
+	filtered := make([]*model.Metric, 0, len(metrics))
+	for _, metric := range metrics {
+		if rule, ok := wafRule(metric); ok && rule != wafRuleAll && !slices.ContainsFunc(rules, func(r *regexp.Regexp) bool { return r.MatchString(rule) }) {
+			continue
+		}
+		filtered = append(filtered, metric)
+	}
+	return filtered
+}
+
+func wafRule(metric *model.Metric) (string, bool) {
+	for _, dimension := range metric.Dimensions {
+		if dimension.Name == wafRuleDimension {
+			return dimension.Value, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,55 @@
+package job
+
+import (
+	"testing"
+
+	"github.com/grafana/regexp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+func TestFilterWAFRuleMetrics(t *testing.T) {
+	webACLMetric := &model.Metric{
+		MetricName: "BlockedRequests",
+		Namespace:  "AWS/WAFV2",
+		Dimensions: []*model.Dimension{
+			{Name: "Region", Value: "eu-west-1"},
+			{Name: "Rule", Value: "ALL"},
+			{Name: "WebACL", Value: "my-web-acl"},
+		},
+	}
+	rateLimitRuleMetric := &model.Metric{
+		MetricName: "BlockedRequests",
+		Namespace:  "AWS/WAFV2",
+		Dimensions: []*model.Dimension{
+			{Name: "Region", Value: "eu-west-1"},
+			{Name: "Rule", Value: "rate-limit"},
+			{Name: "WebACL", Value: "my-web-acl"},
+		},
+	}
+	managedRuleMetric := &model.Metric{
+		MetricName: "BlockedRequests",
+		Namespace:  "AWS/WAFV2",
+		Dimensions: []*model.Dimension{
+			{Name: "Region", Value: "eu-west-1"},
+			{Name: "Rule", Value: "AWS-AWSManagedRulesCommonRuleSet"},
+			{Name: "WebACL", Value: "my-web-acl"},
+		},
+	}
+	countryMetric := &model.Metric{
+		MetricName: "BlockedRequests",
+		Namespace:  "AWS/WAFV2",
+		Dimensions: []*model.Dimension{
+			{Name: "Country", Value: "NL"},
+			{Name: "Region", Value: "eu-west-1"},
+			{Name: "WebACL", Value: "my-web-acl"},
+		},
+	}
+	metrics := []*model.Metric{webACLMetric, rateLimitRuleMetric, managedRuleMetric, countryMetric}
+
+	require.Equal(t,
+		[]*model.Metric{webACLMetric, rateLimitRuleMetric, countryMetric},
+		filterWAFRuleMetrics([]*regexp.Regexp{regexp.MustCompile("^rate-")}, metrics),
+	)
+}
@@ -0,0 +1,66 @@
+package labeltransform
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// consoleURL returns the link to the graph of the metric in the CloudWatch console.
+func consoleURL(env *Env) string {
+	if env.Region == "" {
+		return ""
+	}
+	metric := []string{env.Namespace, env.Metric}
+	for _, dimension := range env.Dimensions {
+		metric = append(metric, dimension.Name, dimension.Value)
+	}
+
+	// The console encodes the graph in the fragment of the URL with jsurl
+	graph := strings.Builder{}
+	graph.WriteString("~(metrics~(~(")
+	for _, value := range metric {
+		graph.WriteString("~'")
+		graph.WriteString(jsurlEncode(value))
+	}
+	graph.WriteString("))~stat~'")
+	graph.WriteString(jsurlEncode(env.Statistic))
+	if env.Period > 0 {
+		graph.WriteString("~period~")
+		graph.WriteString(strconv.FormatInt(env.Period, 10))
+	}
+	graph.WriteString("~region~'")
+	graph.WriteString(jsurlEncode(env.Region))
+	graph.WriteString(")")
+
+	return fmt.Sprintf("https://%s.%s/cloudwatch/home?region=%s#metricsV2:graph=%s", env.Region, consoleDomain(env.Region), env.Region, graph.String())
+}
+
+func consoleDomain(region string) string {
+	switch {
+	case strings.HasPrefix(region, "cn-"):
+		return "console.amazonaws.cn"
+	case strings.HasPrefix(region, "us-gov-"):
+		return "console.amazonaws-us-gov.com"
+	default:
+		return "console.aws.amazon.com"
+	}
+}
+
+func jsurlEncode(s string) string {
+	sb := strings.Builder{}
+	for _, r := range s {
+		switch {
+		case r == '-' || r == '_' || r == '.' ||
+			(r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z'):
+			sb.WriteRune(r)
+		case r == '$':
+			sb.WriteByte('!')
+		case r < 0x100:
+			fmt.Fprintf(&sb, "*%02x", r)
+		default:
+			fmt.Fprintf(&sb, "**%04x", r)
+		}
+	}
+	return sb.String()
+}
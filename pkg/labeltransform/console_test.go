@@ -0,0 +1,52 @@
+package labeltransform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+func TestConsoleURL(t *testing.T) {
+	expr, err := Parse(`metric.consoleUrl`)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name     string
+		env      *Env
+		expected string
+	}{
+		{
+			name: "metric with dimensions",
+			env: &Env{
+				Region:     "eu-west-1",
+				Namespace:  "AWS/SQS",
+				Metric:     "NumberOfMessagesSent",
+				Statistic:  "Sum",
+				Period:     300,
+				Dimensions: []*model.Dimension{{Name: "QueueName", Value: "orders"}},
+			},
+			expected: "https://eu-west-1.console.aws.amazon.com/cloudwatch/home?region=eu-west-1#metricsV2:graph=~(metrics~(~(~'AWS*2fSQS~'NumberOfMessagesSent~'QueueName~'orders))~stat~'Sum~period~300~region~'eu-west-1)",
+		},
+		{
+			name: "china region",
+			env: &Env{
+				Region:    "cn-north-1",
+				Namespace: "AWS/EC2",
+				Metric:    "CPUUtilization",
+				Statistic: "p99",
+			},
+			expected: "https://cn-north-1.console.amazonaws.cn/cloudwatch/home?region=cn-north-1#metricsV2:graph=~(metrics~(~(~'AWS*2fEC2~'CPUUtilization))~stat~'p99~region~'cn-north-1)",
+		},
+		{
+			name:     "without region",
+			env:      &Env{Namespace: "AWS/EC2", Metric: "CPUUtilization"},
+			expected: "",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, expr.Eval(tc.env))
+		})
+	}
+}
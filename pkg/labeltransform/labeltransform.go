@@ -0,0 +1,351 @@
+// Package labeltransform implements the small expression language of the
+// labelTransforms job configuration, computing labels from the resource,
+// the dimensions and the labels of a metric, e.g.
+//
+//	resource.tags["app"] || arn.resource
+package labeltransform
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/arn"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+// Env is the data an expression is evaluated against.
+type Env struct {
+	// ARN of the resource, or the name of a static job.
+	ARN        string
+	Tags       []model.Tag
+	Dimensions []*model.Dimension
+
+	// Labels are the labels the exporter built for the metric.
+	Labels map[string]string
+
+	Region    string
+	Namespace string
+	Metric    string
+	Statistic string
+	Period    int64
+}
+
+// Expression is a parsed expression.
+type Expression struct {
+	source string
+	root   node
+}
+
+// String returns the source of the expression.
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Eval evaluates the expression. Missing tags, dimensions
+// and labels evaluate to the empty string.
+func (e *Expression) Eval(env *Env) string {
+	return e.root.eval(env)
+}
+
+type node interface {
+	eval(env *Env) string
+}
+
+type literal string
+
+func (l literal) eval(_ *Env) string {
+	return string(l)
+}
+
+type variable func(env *Env) string
+
+func (v variable) eval(env *Env) string {
+	return v(env)
+}
+
+// or evaluates to its first non-empty operand.
+type or []node
+
+func (o or) eval(env *Env) string {
+	for _, n := range o {
+		if value := n.eval(env); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+type concat []node
+
+func (c concat) eval(env *Env) string {
+	sb := strings.Builder{}
+	for _, n := range c {
+		sb.WriteString(n.eval(env))
+	}
+	return sb.String()
+}
+
+type call struct {
+	fn   func(args []string) string
+	args []node
+}
+
+func (c call) eval(env *Env) string {
+	args := make([]string, 0, len(c.args))
+	for _, arg := range c.args {
+		args = append(args, arg.eval(env))
+	}
+	return c.fn(args)
+}
+
+type function struct {
+	arity int
+	fn    func(args []string) string
+}
+
+var functions = map[string]function{
+	"lower":      {1, func(args []string) string { return strings.ToLower(args[0]) }},
+	"upper":      {1, func(args []string) string { return strings.ToUpper(args[0]) }},
+	"trimPrefix": {2, func(args []string) string { return strings.TrimPrefix(args[0], args[1]) }},
+	"trimSuffix": {2, func(args []string) string { return strings.TrimSuffix(args[0], args[1]) }},
+	"replace":    {3, func(args []string) string { return strings.ReplaceAll(args[0], args[1], args[2]) }},
+}
+
+// fields are the variables accessed with a dot, e.g. arn.resource.
+var fields = map[string]map[string]variable{
+	"resource": {
+		"arn": func(env *Env) string { return env.ARN },
+	},
+	"arn": {
+		"partition":  arnField(func(a arn.ARN) string { return a.Partition }),
+		"service":    arnField(func(a arn.ARN) string { return a.Service }),
+		"region":     arnField(func(a arn.ARN) string { return a.Region }),
+		"account":    arnField(func(a arn.ARN) string { return a.AccountID }),
+		"resource":   arnField(func(a arn.ARN) string { return a.Resource }),
+		"resourceId": arnField(resourceID),
+	},
+	"metric": {
+		"namespace":  func(env *Env) string { return env.Namespace },
+		"name":       func(env *Env) string { return env.Metric },
+		"statistic":  func(env *Env) string { return env.Statistic },
+		"consoleUrl": consoleURL,
+	},
+}
+
+// indexes are the variables accessed with a key, e.g. dimensions["QueueName"].
+var indexes = map[string]func(key string) variable{
+	"resource.tags": func(key string) variable {
+		return func(env *Env) string {
+			for _, tag := range env.Tags {
+				if tag.Key == key {
+					return tag.Value
+				}
+			}
+			return ""
+		}
+	},
+	"dimensions": func(key string) variable {
+		return func(env *Env) string {
+			for _, dimension := range env.Dimensions {
+				if dimension.Name == key {
+					return dimension.Value
+				}
+			}
+			return ""
+		}
+	},
+	"labels": func(key string) variable {
+		return func(env *Env) string { return env.Labels[key] }
+	},
+}
+
+func arnField(field func(a arn.ARN) string) variable {
+	return func(env *Env) string {
+		parsed, err := arn.Parse(env.ARN)
+		if err != nil {
+			return ""
+		}
+		return field(parsed)
+	}
+}
+
+// resourceID returns the last part of the resource of an ARN,
+// e.g. my-cluster for cluster:my-cluster or service/my-cluster/my-service.
+func resourceID(a arn.ARN) string {
+	if i := strings.LastIndexAny(a.Resource, ":/"); i >= 0 {
+		return a.Resource[i+1:]
+	}
+	return a.Resource
+}
+
+// Parse parses an expression. Expressions are made of string literals,
+// variables and function calls, concatenated with + and falling back to
+// the next operand when empty with ||.
+func Parse(source string) (*Expression, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", tok.value, tok.pos)
+	}
+	return &Expression{source: source, root: root}, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) expect(kind tokenKind, value string) error {
+	if tok := p.next(); tok.kind != kind || tok.value != value {
+		return fmt.Errorf("expected %q at position %d", value, tok.pos)
+	}
+	return nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	operands := []node{}
+	for {
+		operand, err := p.parseConcat()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+		if tok := p.peek(); tok.kind != tokenOperator || tok.value != "||" {
+			break
+		}
+		p.next()
+	}
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return or(operands), nil
+}
+
+func (p *parser) parseConcat() (node, error) {
+	operands := []node{}
+	for {
+		operand, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+		if tok := p.peek(); tok.kind != tokenOperator || tok.value != "+" {
+			break
+		}
+		p.next()
+	}
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return concat(operands), nil
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenString:
+		return literal(tok.value), nil
+	case tokenPunct:
+		if tok.value != "(" {
+			break
+		}
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenPunct, ")"); err != nil {
+			return nil, err
+		}
+		return n, nil
+	case tokenIdent:
+		if next := p.peek(); next.kind == tokenPunct && next.value == "(" {
+			return p.parseCall(tok)
+		}
+		return p.parseVariable(tok)
+	case tokenEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %q at position %d", tok.value, tok.pos)
+}
+
+func (p *parser) parseCall(name token) (node, error) {
+	fn, ok := functions[name.value]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q at position %d", name.value, name.pos)
+	}
+	p.next()
+	args := []node{}
+	for {
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if tok := p.peek(); tok.kind != tokenPunct || tok.value != "," {
+			break
+		}
+		p.next()
+	}
+	if err := p.expect(tokenPunct, ")"); err != nil {
+		return nil, err
+	}
+	if len(args) != fn.arity {
+		return nil, fmt.Errorf("function %q expects %d arguments, got %d", name.value, fn.arity, len(args))
+	}
+	return call{fn: fn.fn, args: args}, nil
+}
+
+func (p *parser) parseVariable(name token) (node, error) {
+	path := name.value
+	for {
+		tok := p.peek()
+		switch {
+		case tok.kind == tokenPunct && tok.value == ".":
+			p.next()
+			field := p.next()
+			if field.kind != tokenIdent {
+				return nil, fmt.Errorf("expected a field name at position %d", field.pos)
+			}
+			if v, ok := fields[path][field.value]; ok {
+				return v, nil
+			}
+			path += "." + field.value
+		case tok.kind == tokenPunct && tok.value == "[":
+			p.next()
+			key := p.next()
+			if key.kind != tokenString {
+				return nil, fmt.Errorf("expected a string key at position %d", key.pos)
+			}
+			if err := p.expect(tokenPunct, "]"); err != nil {
+				return nil, err
+			}
+			index, ok := indexes[path]
+			if !ok {
+				return nil, fmt.Errorf("%s cannot be indexed", path)
+			}
+			return index(key.value), nil
+		default:
+			return nil, fmt.Errorf("unknown variable %q at position %d", path, name.pos)
+		}
+	}
+}
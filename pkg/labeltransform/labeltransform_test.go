@@ -0,0 +1,70 @@
+package labeltransform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+func TestEval(t *testing.T) {
+	env := &Env{
+		ARN:        "arn:aws:ecs:eu-west-1:123456789012:service/my-cluster/my-service",
+		Tags:       []model.Tag{{Key: "app", Value: "checkout"}, {Key: "team", Value: "Payments"}},
+		Dimensions: []*model.Dimension{{Name: "ClusterName", Value: "my-cluster"}},
+		Labels:     map[string]string{"region": "eu-west-1"},
+		Namespace:  "AWS/ECS",
+		Metric:     "CPUUtilization",
+		Statistic:  "Average",
+	}
+
+	for _, tc := range []struct {
+		expression string
+		expected   string
+	}{
+		{expression: `resource.tags["app"] || arn.resource`, expected: "checkout"},
+		{expression: `resource.tags["missing"] || arn.resource`, expected: "service/my-cluster/my-service"},
+		{expression: `resource.tags["missing"]`, expected: ""},
+		{expression: `arn.resourceId`, expected: "my-service"},
+		{expression: `arn.account + "/" + arn.region`, expected: "123456789012/eu-west-1"},
+		{expression: `lower(resource.tags["team"])`, expected: "payments"},
+		{expression: `replace(dimensions["ClusterName"], "-", "_")`, expected: "my_cluster"},
+		{expression: `(labels["missing"] || "default") + ":" + metric.statistic`, expected: "default:Average"},
+		{expression: `trimPrefix(metric.namespace, "AWS/")`, expected: "ECS"},
+	} {
+		t.Run(tc.expression, func(t *testing.T) {
+			expr, err := Parse(tc.expression)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, expr.Eval(env))
+		})
+	}
+}
+
+func TestEvalWithoutARN(t *testing.T) {
+	expr, err := Parse(`arn.resource || resource.arn`)
+	require.NoError(t, err)
+	require.Equal(t, "global", expr.Eval(&Env{ARN: "global"}))
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, tc := range []struct {
+		expression string
+		errorMsg   string
+	}{
+		{expression: ``, errorMsg: "unexpected end of expression"},
+		{expression: `resource.name`, errorMsg: `unknown variable "resource.name"`},
+		{expression: `metric["name"]`, errorMsg: "metric cannot be indexed"},
+		{expression: `dimensions[name]`, errorMsg: "expected a string key"},
+		{expression: `upper("a", "b")`, errorMsg: `function "upper" expects 1 arguments, got 2`},
+		{expression: `split(arn.resource)`, errorMsg: `unknown function "split"`},
+		{expression: `"unterminated`, errorMsg: "unterminated string"},
+		{expression: `arn.region arn.account`, errorMsg: "unexpected \"arn\""},
+		{expression: `arn.region || `, errorMsg: "unexpected end of expression"},
+	} {
+		t.Run(tc.expression, func(t *testing.T) {
+			_, err := Parse(tc.expression)
+			require.ErrorContains(t, err, tc.errorMsg)
+		})
+	}
+}
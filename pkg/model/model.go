@@ -1,6 +1,9 @@
 package model
 
 import (
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/grafana/regexp"
@@ -12,37 +15,188 @@ const (
 	DefaultDelaySeconds  = int64(300)
 )
 
+// DefaultPublishDelay is how long after the end of a period CloudWatch
+// usually has received all its datapoints.
+const DefaultPublishDelay = 2 * time.Minute
+
+const (
+	// TimestampAlignmentQuery snaps the GetMetricData windows to the rounding period
+	// of the job, or else to the smallest period of the metrics queried together.
+	TimestampAlignmentQuery = "query"
+	// TimestampAlignmentPeriod snaps the GetMetricData windows to the period of the metric.
+	TimestampAlignmentPeriod = "period"
+)
+
+const (
+	// StatLabelModeSuffix appends the statistic to the name of the exported metrics.
+	StatLabelModeSuffix = "suffix"
+	// StatLabelModeLabel exports the statistics of a metric as a single family
+	// with a stat label.
+	StatLabelModeLabel = "label"
+)
+
+// StatisticWeightedAverage is the Sum divided by the SampleCount of a metric, averaging
+// all the samples of its datapoints. It is computed by the exporter from the Sum and
+// SampleCount, which are queried together.
+const StatisticWeightedAverage = "WeightedAverage"
+
+// Priority classes of the jobs. In decoupled mode, each class is scraped on its own
+// and the classes share the CloudWatch API concurrency by weight, so that high
+// priority jobs are not delayed by the low priority ones.
+const (
+	PriorityHigh   = "high"
+	PriorityNormal = "normal"
+	PriorityLow    = "low"
+)
+
+// DefaultMaxShards is the number of shards per Kinesis stream whose shard
+// level series are exported, when includeShardLevel is set without maxShards.
+const DefaultMaxShards = 10
+
+const (
+	// LambdaResourceModeFunction only exports function level series, the
+	// per version and per alias series are collapsed into them.
+	LambdaResourceModeFunction = "function"
+
+	// LambdaResourceModeAlias exports function level and alias level series,
+	// the latter with an alias label. Per version series are dropped.
+	LambdaResourceModeAlias = "alias"
+)
+
+const (
+	// APIGatewayGranularityAPI only exports API level series, i.e. with the ApiName
+	// dimension for REST APIs and the ApiId dimension for HTTP and WebSocket APIs.
+	APIGatewayGranularityAPI = "api"
+
+	// APIGatewayGranularityStage only exports stage level series, with the Stage dimension.
+	APIGatewayGranularityStage = "stage"
+
+	// APIGatewayGranularityMethod only exports method level series, with the Method and
+	// Resource dimensions for REST APIs, and the Route dimension for HTTP and WebSocket APIs.
+	APIGatewayGranularityMethod = "method"
+)
+
 type JobsConfig struct {
-	StsRegion           string
-	DiscoveryJobs       []DiscoveryJob
-	StaticJobs          []StaticJob
-	CustomNamespaceJobs []CustomNamespaceJob
+	StsRegion                    string
+	DiscoveryJobs                []DiscoveryJob
+	StaticJobs                   []StaticJob
+	CustomNamespaceJobs          []CustomNamespaceJob
+	CustomNamespaceDiscoveryJobs []CustomNamespaceDiscoveryJob
+	InventoryJobs                []InventoryJob
+	CostExplorerJobs             []CostExplorerJob
+	ServiceQuotaJobs             []ServiceQuotaJob
+	TrustedAdvisorJobs           []TrustedAdvisorJob
+	LogsInsightsJobs             []LogsInsightsJob
+	ContributorInsightsJobs      []ContributorInsightsJob
+	Hooks                        []HookConfig
+	Retries                      map[string]RetryConfig
+	Organization                 *OrganizationConfig
+	// Partitions are the credentials of the regions of the partitions, by partition.
+	// The regions of the other partitions use the default credentials.
+	Partitions map[string]PartitionConfig
+}
+
+// Partitions of the AWS regions.
+const (
+	PartitionAWS      = "aws"
+	PartitionChina    = "aws-cn"
+	PartitionGovCloud = "aws-us-gov"
+)
+
+// PartitionOf returns the partition of a region.
+func PartitionOf(region string) string {
+	switch {
+	case strings.HasPrefix(region, "cn-"):
+		return PartitionChina
+	case strings.HasPrefix(region, "us-gov-"):
+		return PartitionGovCloud
+	default:
+		return PartitionAWS
+	}
+}
+
+// PartitionConfig is the source of the credentials of the clients of the regions of a
+// partition, isolated from the clients of the other partitions.
+type PartitionConfig struct {
+	// Profile is the profile of the shared config and credentials files.
+	Profile string
+	// AccessKeyID and SecretAccessKey are static credentials, replacing the profile.
+	AccessKeyID     string
+	SecretAccessKey string
+	// StsRegion is the region of the STS endpoint of the partition, the region
+	// of the clients when empty.
+	StsRegion string
+}
+
+// OrganizationConfig selects the member accounts of the AWS Organization
+// scraped by the discovery jobs with OrganizationAccounts.
+type OrganizationConfig struct {
+	// RoleName is the name of the role assumed in every account.
+	RoleName   string
+	ExternalID string
+	// ManagementRoleArn is the role assumed to list the accounts
+	// of the organization, the current role when empty.
+	ManagementRoleArn string
+	RefreshInterval   time.Duration
+
+	IncludeAccounts            []string
+	ExcludeAccounts            []string
+	IncludeOrganizationalUnits []string
+	ExcludeOrganizationalUnits []string
+	AccountTags                []SearchTag
 }
 
 type DiscoveryJob struct {
 	Regions                     []string
 	Type                        string
 	Roles                       []Role
+	OrganizationAccounts        bool
 	SearchTags                  []SearchTag
 	CustomTags                  []Tag
 	DimensionNameRequirements   []string
 	Metrics                     []*MetricConfig
 	RoundingPeriod              *int64
 	RecentlyActiveOnly          bool
-	ExportedTagsOnMetrics       []string
+	ExportedTagsOnMetrics       []ExportedTag
 	IncludeContextOnInfoMetrics bool
+	DisableInfoMetrics          bool
+	AddResourceAttributes       bool
+	AddKubernetesLabels         bool
+	AddCapacityMetrics          bool
+	AddLogGroupMetrics          bool
+	InfoMetricAttributes        []string
+	LambdaResourceMode          string
+	APIGatewayGranularity       string
+	ExpandElastiCacheNodes      bool
+	KafkaTopics                 []*regexp.Regexp
+	WAFRules                    []*regexp.Regexp
+	CloudFrontBreakdowns        []string
+	IncludeShardLevel           bool
+	MaxShards                   int
+	LabelTransforms             []LabelTransformConfig
+	Processors                  []ProcessorConfig
+	NamespaceAlias              string
 	DimensionsRegexps           []DimensionsRegexp
+	Priority                    string
+	UnhealthyAfter              time.Duration
+	Schedule                    *Schedule
 	JobLevelMetricFields
 }
 
 type StaticJob struct {
-	Name       string
-	Regions    []string
-	Roles      []Role
-	Namespace  string
-	CustomTags []Tag
-	Dimensions []Dimension
-	Metrics    []*MetricConfig
+	Name            string
+	Regions         []string
+	Roles           []Role
+	Namespace       string
+	CustomTags      []Tag
+	Dimensions      []Dimension
+	Metrics         []*MetricConfig
+	LabelTransforms []LabelTransformConfig
+	Processors      []ProcessorConfig
+	NamespaceAlias  string
+	Priority        string
+	UnhealthyAfter  time.Duration
+	Schedule        *Schedule
 }
 
 type CustomNamespaceJob struct {
@@ -56,9 +210,197 @@ type CustomNamespaceJob struct {
 	DimensionNameRequirements []string
 	AddHistoricalMetrics      *bool
 	RoundingPeriod            *int64
+	LabelTransforms           []LabelTransformConfig
+	Processors                []ProcessorConfig
+	NamespaceAlias            string
+	Priority                  string
+	UnhealthyAfter            time.Duration
+	Schedule                  *Schedule
 	JobLevelMetricFields
 }
 
+// Schedule restricts the scrapes of a job to its Active windows, outside of its
+// Blackouts windows. A nil Schedule scrapes the job all the time.
+type Schedule struct {
+	Location *time.Location
+	// Active are the windows the job is scraped in, all the time when empty.
+	Active []ScheduleWindow
+	// Blackouts are the windows the job is not scraped in, even in an active window.
+	Blackouts []ScheduleWindow
+}
+
+// ScheduleWindow is a window of time of the day, on some days of the week.
+type ScheduleWindow struct {
+	// Days are the days the window starts on, all the days when empty.
+	Days []time.Weekday
+	// Start and End are the times of the day the window starts and ends at, as the
+	// duration since midnight. A window ending before its start ends the next day.
+	Start time.Duration
+	End   time.Duration
+}
+
+// Scraped returns whether the job of the schedule is scraped at the time.
+func (s *Schedule) Scraped(t time.Time) bool {
+	if s == nil {
+		return true
+	}
+	t = t.In(s.Location)
+	contains := func(w ScheduleWindow) bool { return w.contains(t) }
+	if len(s.Active) > 0 && !slices.ContainsFunc(s.Active, contains) {
+		return false
+	}
+	return !slices.ContainsFunc(s.Blackouts, contains)
+}
+
+func (w ScheduleWindow) contains(t time.Time) bool {
+	sinceMidnight := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	startsOn := func(day time.Weekday) bool { return len(w.Days) == 0 || slices.Contains(w.Days, day) }
+	if w.Start < w.End {
+		return sinceMidnight >= w.Start && sinceMidnight < w.End && startsOn(t.Weekday())
+	}
+	// The window ends the next day, e.g. from 22:00 to 06:00
+	if sinceMidnight >= w.Start {
+		return startsOn(t.Weekday())
+	}
+	return sinceMidnight < w.End && startsOn((t.Weekday()+6)%7)
+}
+
+// CustomNamespaceDiscoveryJob exports all the metrics of the non AWS namespaces
+// matching IncludeRegex and not matching ExcludeRegex. The settings of Metric
+// (statistics, period, length...) are applied to all of them. The namespaces
+// are discovered again every RefreshInterval.
+type CustomNamespaceDiscoveryJob struct {
+	Regions            []string
+	Roles              []Role
+	IncludeRegex       *regexp.Regexp
+	ExcludeRegex       *regexp.Regexp
+	RecentlyActiveOnly bool
+	RoundingPeriod     *int64
+	CustomTags         []Tag
+	Metric             *MetricConfig
+	RefreshInterval    time.Duration
+}
+
+// InventoryJob discovers the resources of the given namespaces,
+// without querying CloudWatch.
+type InventoryJob struct {
+	Regions    []string
+	Roles      []Role
+	Namespaces []string
+	SearchTags []SearchTag
+	CustomTags []Tag
+}
+
+const (
+	CostExplorerGroupByDimension    = "DIMENSION"
+	CostExplorerGroupByTag          = "TAG"
+	CostExplorerGroupByCostCategory = "COST_CATEGORY"
+)
+
+// CostExplorerJob queries the daily cost of the account
+// from the Cost Explorer API.
+type CostExplorerJob struct {
+	Name            string
+	Region          string
+	Roles           []Role
+	Metrics         []string
+	GroupBy         []CostExplorerGroupBy
+	RefreshInterval time.Duration
+	AddTimestamp    bool
+	CustomTags      []Tag
+}
+
+type CostExplorerGroupBy struct {
+	Type string
+	Key  string
+}
+
+// ServiceQuotaJob exports the quotas of the given services
+// (e.g. ec2), with their usage if published to CloudWatch.
+type ServiceQuotaJob struct {
+	Regions      []string
+	Roles        []Role
+	ServiceCodes []string
+	CustomTags   []Tag
+}
+
+// TrustedAdvisorJob exports the results of the Trusted Advisor checks,
+// which require a Business or Enterprise support plan.
+type TrustedAdvisorJob struct {
+	Region          string
+	Roles           []Role
+	Categories      []string
+	RefreshInterval time.Duration
+	CustomTags      []Tag
+}
+
+// LogsInsightsJob periodically runs a CloudWatch Logs Insights query,
+// whose numeric result fields are exported as metrics.
+type LogsInsightsJob struct {
+	Name            string
+	Regions         []string
+	Roles           []Role
+	LogGroupNames   []string
+	Query           string
+	Length          time.Duration
+	RefreshInterval time.Duration
+	LabelFields     []string
+	CustomTags      []Tag
+}
+
+const (
+	// HookEventDiscoveryComplete is sent once the resources of the discovery jobs are discovered.
+	HookEventDiscoveryComplete = "discoveryComplete"
+
+	// HookEventMetricsBuilt is sent once the metrics of a scrape are built, before they are exported.
+	HookEventMetricsBuilt = "metricsBuilt"
+
+	// HookEventScrapeError is sent for every error logged by the scrape jobs.
+	HookEventScrapeError = "scrapeError"
+)
+
+// HookConfig sends the events of the scrape lifecycle
+// to a webhook, or to the standard input of a command.
+type HookConfig struct {
+	Events  []string
+	Webhook string
+	Exec    []string
+	Timeout time.Duration
+}
+
+// APIs whose retries can be configured.
+const (
+	APICloudWatch = "cloudwatch"
+	APITagging    = "tagging"
+	APISTS        = "sts"
+)
+
+const (
+	RetryModeStandard = "standard"
+	RetryModeAdaptive = "adaptive"
+)
+
+// RetryConfig overrides the retries of the requests to an API,
+// zero values keep the defaults of the client.
+type RetryConfig struct {
+	Mode        string
+	MaxAttempts int
+	MaxBackoff  time.Duration
+}
+
+// ContributorInsightsJob periodically retrieves the reports of
+// Contributor Insights rules, with their top contributors.
+type ContributorInsightsJob struct {
+	Regions         []string
+	Roles           []Role
+	RuleNames       []string
+	Length          time.Duration
+	RefreshInterval time.Duration
+	MaxContributors int64
+	OrderBy         string
+	CustomTags      []Tag
+}
+
 type JobLevelMetricFields struct {
 	Statistics             []string
 	Period                 int64
@@ -69,9 +411,19 @@ type JobLevelMetricFields struct {
 	AddCloudwatchTimestamp *bool
 }
 
+// StsRegionGlobal is the StsRegion of the roles assumed with the legacy global STS endpoint.
+const StsRegionGlobal = "global"
+
 type Role struct {
 	RoleArn    string
 	ExternalID string
+	// StsRegion is the region of the STS endpoint assuming the role,
+	// StsRegionGlobal for the global endpoint. The default endpoint when empty.
+	StsRegion string
+	// SessionDuration is the duration of the role sessions, the default of the SDK when zero.
+	SessionDuration time.Duration
+	// Retry replaces the retries of the sts API for the role when not zero.
+	Retry RetryConfig
 }
 
 type MetricConfig struct {
@@ -83,6 +435,33 @@ type MetricConfig struct {
 	NilToZero              *bool
 	AddHistoricalMetrics   *bool
 	AddCloudwatchTimestamp *bool
+	RoundingPeriod         *int64
+	TimestampAlignment     string
+	CompletePeriodsOnly    *bool
+	SkipIncompletePeriod   *bool
+	StatLabelMode          string
+	// HistogramBuckets are the upper bounds of the buckets of the histogram
+	// synthesized from the percentile ranks of the metric, none without histogram.
+	HistogramBuckets []float64
+}
+
+// HistogramBucketStatistic returns the percentile rank statistic of the samples
+// lower than or equal to the upper bound of a histogram bucket, e.g. PR(:0.5).
+func HistogramBucketStatistic(bucket float64) string {
+	return "PR(:" + strconv.FormatFloat(bucket, 'f', -1, 64) + ")"
+}
+
+// WindowRoundingPeriod returns the period the GetMetricData windows of the
+// metric are snapped to, nil to use the rounding period of the query.
+func (m *MetricConfig) WindowRoundingPeriod() *int64 {
+	if m.RoundingPeriod != nil {
+		return m.RoundingPeriod
+	}
+	if m.TimestampAlignment == TimestampAlignmentPeriod {
+		period := m.Period
+		return &period
+	}
+	return nil
 }
 
 type DimensionsRegexp struct {
@@ -97,11 +476,28 @@ type Tag struct {
 	Value string
 }
 
+// ResourceValue is a numeric property of a resource, e.g. the desired
+// capacity of an Auto Scaling group.
+type ResourceValue struct {
+	Name  string
+	Value float64
+}
+
 type SearchTag struct {
 	Key   string
 	Value *regexp.Regexp
 }
 
+// ExportedTag selects resource tags exported as labels of the metrics.
+type ExportedTag struct {
+	// Key is the key of the tag, when Regexp is nil.
+	Key string
+	// Regexp matches the keys of the tags, when not nil.
+	Regexp *regexp.Regexp
+	// Name replaces the key of the tags if not empty, expanding the groups of Regexp.
+	Name string
+}
+
 type Dimension struct {
 	Name  string
 	Value string
@@ -141,11 +537,154 @@ type Datapoint struct {
 type CloudwatchMetricResult struct {
 	Context *ScrapeContext
 	Data    []*CloudwatchData
+
+	// LabelTransforms add labels to the metrics built from Data.
+	LabelTransforms []LabelTransformConfig
+
+	// Processors transform the metrics built from Data, in order.
+	Processors []ProcessorConfig
+
+	// NamespaceAlias replaces the namespace in the names of the metrics, if not empty.
+	NamespaceAlias string
+}
+
+// LabelTransformConfig sets the Label of the metrics of a job
+// to the value of an expression of the labeltransform package.
+type LabelTransformConfig struct {
+	Label      string
+	Expression string
+}
+
+// ProcessorConfig is a Go plugin transforming the metrics of a job,
+// with the func exported by the plugin under the Symbol name.
+type ProcessorConfig struct {
+	Plugin string
+	Symbol string
 }
 
 type TaggedResourceResult struct {
 	Context *ScrapeContext
 	Data    []*TaggedResource
+
+	// NamespaceAlias replaces the namespace in the name of the info metrics, if not empty.
+	NamespaceAlias string
+}
+
+// UnmatchedResult lists the resources of a discovery job which no metric
+// was associated with, and the metrics which no resource was associated with.
+type UnmatchedResult struct {
+	Context   *ScrapeContext
+	Namespace string
+	// Resources are the ARNs of the resources without metrics.
+	Resources []string
+	// Metrics are the metrics skipped for not matching any resource.
+	Metrics []*Metric
+}
+
+// JobOutcome is the outcome of the scrape of a discovery, static or custom namespace
+// job in a region with a role.
+type JobOutcome struct {
+	// Job is the type of a discovery job, or the name of a static or custom namespace job.
+	Job       string
+	Region    string
+	RoleArn   string
+	AccountID string
+	// Err is nil when the scrape succeeded.
+	Err error
+	// Paused is true when the job was not scraped, being outside of its Schedule.
+	Paused bool
+	// UnhealthyAfter is how long the job may fail before the exporter is unhealthy, 0 when it is not checked.
+	UnhealthyAfter time.Duration
+}
+
+// JobPlan is the CloudWatch API usage of a scrape of a job in a region and
+// account, planned without requesting any datapoint.
+type JobPlan struct {
+	Context *ScrapeContext
+	// Job is the type of a discovery job, or the name of a static or custom namespace job.
+	Job                         string
+	Namespace                   string
+	Resources                   int
+	ListMetricsRequests         int
+	GetMetricDataQueries        int
+	GetMetricDataRequests       int
+	GetMetricStatisticsRequests int
+}
+
+type ServiceQuotaResult struct {
+	Context *ScrapeContext
+	Data    []*ServiceQuota
+}
+
+// ServiceQuota is the value of a quota of a service,
+// and its usage if the quota has a usage metric.
+type ServiceQuota struct {
+	ServiceCode string
+	QuotaCode   string
+	QuotaName   string
+	Value       float64
+	Usage       *float64
+	UsageMetric *ServiceQuotaUsageMetric
+}
+
+// ServiceQuotaUsageMetric is the CloudWatch metric tracking the usage of a quota.
+type ServiceQuotaUsageMetric struct {
+	Namespace  string
+	MetricName string
+	Statistic  string
+	Dimensions []*Dimension
+}
+
+type TrustedAdvisorResult struct {
+	Context *ScrapeContext
+	Data    []*TrustedAdvisorCheck
+}
+
+// TrustedAdvisorCheck is the latest result of a Trusted Advisor check.
+type TrustedAdvisorCheck struct {
+	ID                  string
+	Name                string
+	Category            string
+	Status              string
+	ResourcesFlagged    int64
+	ResourcesProcessed  int64
+	ResourcesSuppressed int64
+	ResourcesIgnored    int64
+}
+
+type LogsInsightsResult struct {
+	Context *ScrapeContext
+	Data    []*LogsInsightsValue
+}
+
+// LogsInsightsValue is a numeric field of a row of a Logs Insights query
+// result, labelled with the other fields of the row.
+type LogsInsightsValue struct {
+	JobName string
+	Field   string
+	Value   float64
+	Labels  []Tag
+}
+
+type ContributorInsightsResult struct {
+	Context *ScrapeContext
+	Data    []*ContributorInsightsReport
+}
+
+// ContributorInsightsReport is the report of a Contributor Insights rule.
+// The values of the contributors keys are in the order of the KeyLabels.
+type ContributorInsightsReport struct {
+	RuleName             string
+	KeyLabels            []string
+	AggregationStatistic string
+	AggregateValue       float64
+	UniqueContributors   int64
+	Contributors         []ContributorInsightsContributor
+}
+
+type ContributorInsightsContributor struct {
+	Keys  []string
+	Value float64
 }
 
 type ScrapeContext struct {
@@ -169,8 +708,32 @@ type CloudwatchData struct {
 	AddCloudwatchTimestamp  *bool
 	AddHistoricalMetrics    *bool
 	Tags                    []Tag
+	Attributes              []Tag
 	Dimensions              []*Dimension
 	Period                  int64
+
+	// CompletePeriodsOnly skips the datapoints of the periods not entirely
+	// in the GetMetricData window, which aggregate partial data.
+	CompletePeriodsOnly *bool
+
+	// SkipIncompletePeriod skips the datapoints of the periods which did not
+	// end at least PublishDelay ago, whose datapoints may still be received.
+	SkipIncompletePeriod *bool
+	PublishDelay         time.Duration
+
+	// StatLabelMode is how the statistic is set on the exported metric,
+	// StatLabelModeSuffix when empty.
+	StatLabelMode string
+
+	// HistogramBuckets are the buckets of the histogram the statistic is part of, if any.
+	HistogramBuckets []float64
+
+	// RoundingPeriod is the period the GetMetricData window of the metric
+	// is snapped to, nil to use the rounding period of the job.
+	RoundingPeriod *int64
+
+	// ResourceTags are all the tags of the resource, for the label transforms.
+	ResourceTags []Tag
 }
 
 // TaggedResource is an AWS resource with tags
@@ -186,6 +749,18 @@ type TaggedResource struct {
 
 	// Tags is a set of tags associated to the resource
 	Tags []Tag
+
+	// Attributes is a set of additional properties of the resource
+	// (e.g. the EC2 instance type) retrieved via service specific APIs
+	Attributes []Tag
+
+	// InfoAttributes is a set of additional properties of the resource
+	// retrieved via service specific APIs, only exported on the info metric
+	InfoAttributes []Tag
+
+	// Values is a set of numeric properties of the resource retrieved via
+	// service specific APIs, exported as gauges next to the info metric
+	Values []ResourceValue
 }
 
 // filterThroughTags returns true if all filterTags match
@@ -216,28 +791,124 @@ func (r TaggedResource) FilterThroughTags(filterTags []SearchTag) bool {
 // MetricTags returns a list of tags built from the tags of
 // TaggedResource, if exportedTags is not empty.
 //
-// Returned tags have as key the key from exportedTags, and
-// as value the value from the corresponding tag of the resource,
-// if it exists (otherwise an empty string).
-func (r TaggedResource) MetricTags(exportedTags []string) []Tag {
+// An exported tag with a key is always returned, with the value of the
+// corresponding tag of the resource if it exists (otherwise an empty
+// string), and one with a regexp for each matching tag of the resource,
+// in the order of their keys. The returned tags are keyed by the Name of
+// the exported tag if set, and the first one wins when several have the
+// same key.
+func (r TaggedResource) MetricTags(exportedTags []ExportedTag) []Tag {
 	if len(exportedTags) == 0 {
 		return []Tag{}
 	}
 
 	tags := make([]Tag, 0, len(exportedTags))
-	for _, tagName := range exportedTags {
-		tag := Tag{
-			Key: tagName,
+	add := func(tag Tag) {
+		for _, existing := range tags {
+			if existing.Key == tag.Key {
+				return
+			}
 		}
-		for _, resourceTag := range r.Tags {
-			if resourceTag.Key == tagName {
-				tag.Value = resourceTag.Value
-				break
+		tags = append(tags, tag)
+	}
+	var sortedTags []Tag
+	for _, exportedTag := range exportedTags {
+		if exportedTag.Regexp == nil {
+			tag := Tag{Key: exportedTag.Key}
+			if exportedTag.Name != "" {
+				tag.Key = exportedTag.Name
+			}
+			for _, resourceTag := range r.Tags {
+				if resourceTag.Key == exportedTag.Key {
+					tag.Value = resourceTag.Value
+					break
+				}
 			}
+
+			// Always add the tag, even if it's empty, to ensure the same labels are present on all metrics for a single service
+			add(tag)
+			continue
 		}
 
-		// Always add the tag, even if it's empty, to ensure the same labels are present on all metrics for a single service
-		tags = append(tags, tag)
+		if sortedTags == nil {
+			sortedTags = slices.Clone(r.Tags)
+			slices.SortFunc(sortedTags, func(a, b Tag) int { return strings.Compare(a.Key, b.Key) })
+		}
+		for _, resourceTag := range sortedTags {
+			match := exportedTag.Regexp.FindStringSubmatchIndex(resourceTag.Key)
+			if match == nil {
+				continue
+			}
+			key := resourceTag.Key
+			if exportedTag.Name != "" {
+				key = string(exportedTag.Regexp.ExpandString(nil, exportedTag.Name, resourceTag.Key, match))
+			}
+			add(Tag{Key: key, Value: resourceTag.Value})
+		}
 	}
 	return tags
 }
+
+// kubernetesClusterTags are the well-known tags which hold the name of the
+// Kubernetes cluster owning a resource, in order of precedence.
+var kubernetesClusterTags = []string{
+	"elbv2.k8s.aws/cluster",  // AWS Load Balancer Controller
+	"eks:eks-cluster-name",   // EKS managed node groups and Karpenter nodes
+	"aws:eks:cluster-name",   // EKS managed resources
+	"karpenter.sh/discovery", // Karpenter
+	"KubernetesCluster",      // legacy in-tree cloud provider
+}
+
+// kubernetesServiceTags are the well-known tags which hold the owning
+// Kubernetes object as "<namespace>/<name>", in order of precedence.
+var kubernetesServiceTags = []string{
+	"service.k8s.aws/stack",      // AWS Load Balancer Controller, NLB for a Service
+	"ingress.k8s.aws/stack",      // AWS Load Balancer Controller, ALB for an Ingress
+	"kubernetes.io/service-name", // legacy in-tree cloud provider
+}
+
+// KubernetesTags returns the k8s_cluster, k8s_namespace and k8s_service tags
+// of the TaggedResource, derived from the well-known tags set by Kubernetes
+// controllers (AWS Load Balancer Controller, EBS CSI driver, Karpenter, ...).
+//
+// All three tags are always returned, with an empty value if they cannot be derived.
+func (r TaggedResource) KubernetesTags() []Tag {
+	tags := make(map[string]string, len(r.Tags))
+	for _, tag := range r.Tags {
+		tags[tag.Key] = tag.Value
+	}
+
+	var cluster, namespace, service string
+	for _, key := range kubernetesClusterTags {
+		if value := tags[key]; value != "" {
+			cluster = value
+			break
+		}
+	}
+	if cluster == "" {
+		for key := range tags {
+			if name, found := strings.CutPrefix(key, "kubernetes.io/cluster/"); found && name != "" {
+				cluster = name
+				break
+			}
+		}
+	}
+
+	for _, key := range kubernetesServiceTags {
+		// Ingress groups are tagged with the group name only, which does not identify an owner
+		if ns, name, found := strings.Cut(tags[key], "/"); found {
+			namespace, service = ns, name
+			break
+		}
+	}
+	if namespace == "" {
+		// EBS CSI driver volumes
+		namespace = tags["kubernetes.io/created-for/pvc/namespace"]
+	}
+
+	return []Tag{
+		{Key: "k8s_cluster", Value: cluster},
+		{Key: "k8s_namespace", Value: namespace},
+		{Key: "k8s_service", Value: service},
+	}
+}
@@ -2,6 +2,7 @@ package model
 
 import (
 	"testing"
+	"time"
 
 	"github.com/grafana/regexp"
 	"github.com/stretchr/testify/require"
@@ -175,7 +176,7 @@ func Test_MetricTags(t *testing.T) {
 	testCases := []struct {
 		testName     string
 		resourceTags []Tag
-		exportedTags []string
+		exportedTags []ExportedTag
 		result       []Tag
 	}{
 		{
@@ -186,7 +187,7 @@ func Test_MetricTags(t *testing.T) {
 					Value: "v1",
 				},
 			},
-			exportedTags: []string{},
+			exportedTags: []ExportedTag{},
 			result:       []Tag{},
 		},
 		{
@@ -197,7 +198,7 @@ func Test_MetricTags(t *testing.T) {
 					Value: "v1",
 				},
 			},
-			exportedTags: []string{"k1"},
+			exportedTags: []ExportedTag{{Key: "k1"}},
 			result: []Tag{
 				{
 					Key:   "k1",
@@ -213,7 +214,7 @@ func Test_MetricTags(t *testing.T) {
 					Value: "v1",
 				},
 			},
-			exportedTags: []string{"k1", "k2"},
+			exportedTags: []ExportedTag{{Key: "k1"}, {Key: "k2"}},
 			result: []Tag{
 				{
 					Key:   "k1",
@@ -225,10 +226,74 @@ func Test_MetricTags(t *testing.T) {
 				},
 			},
 		},
+		{
+			testName: "renamed exported tag",
+			resourceTags: []Tag{
+				{
+					Key:   "aws:cloudformation:stack-name",
+					Value: "my-stack",
+				},
+			},
+			exportedTags: []ExportedTag{{Key: "aws:cloudformation:stack-name", Name: "cfn_stack"}},
+			result: []Tag{
+				{
+					Key:   "cfn_stack",
+					Value: "my-stack",
+				},
+			},
+		},
+		{
+			testName: "exported tags matching a regexp",
+			resourceTags: []Tag{
+				{
+					Key:   "aws:cloudformation:stack-name",
+					Value: "my-stack",
+				},
+				{
+					Key:   "Name",
+					Value: "my-name",
+				},
+				{
+					Key:   "aws:cloudformation:logical-id",
+					Value: "MyResource",
+				},
+			},
+			exportedTags: []ExportedTag{{Regexp: regexp.MustCompile("^(?:aws:cloudformation:(.*))$"), Name: "cfn_$1"}},
+			result: []Tag{
+				{
+					Key:   "cfn_logical-id",
+					Value: "MyResource",
+				},
+				{
+					Key:   "cfn_stack-name",
+					Value: "my-stack",
+				},
+			},
+		},
+		{
+			testName: "colliding exported tags",
+			resourceTags: []Tag{
+				{
+					Key:   "team",
+					Value: "v1",
+				},
+				{
+					Key:   "Team",
+					Value: "v2",
+				},
+			},
+			exportedTags: []ExportedTag{{Key: "Team", Name: "team"}, {Regexp: regexp.MustCompile("^(?:team)$")}},
+			result: []Tag{
+				{
+					Key:   "team",
+					Value: "v2",
+				},
+			},
+		},
 		{
 			testName:     "resource without tags",
 			resourceTags: []Tag{},
-			exportedTags: []string{"k1"},
+			exportedTags: []ExportedTag{{Key: "k1"}},
 			result: []Tag{
 				{
 					Key:   "k1",
@@ -251,3 +316,134 @@ func Test_MetricTags(t *testing.T) {
 		})
 	}
 }
+
+func Test_KubernetesTags(t *testing.T) {
+	testCases := []struct {
+		testName     string
+		resourceTags []Tag
+		result       []Tag
+	}{
+		{
+			testName:     "no kubernetes tags",
+			resourceTags: []Tag{{Key: "Name", Value: "jenkins"}},
+			result: []Tag{
+				{Key: "k8s_cluster", Value: ""},
+				{Key: "k8s_namespace", Value: ""},
+				{Key: "k8s_service", Value: ""},
+			},
+		},
+		{
+			testName: "load balancer controller NLB",
+			resourceTags: []Tag{
+				{Key: "elbv2.k8s.aws/cluster", Value: "production"},
+				{Key: "service.k8s.aws/stack", Value: "ingress-nginx/ingress-nginx-controller"},
+				{Key: "service.k8s.aws/resource", Value: "LoadBalancer"},
+			},
+			result: []Tag{
+				{Key: "k8s_cluster", Value: "production"},
+				{Key: "k8s_namespace", Value: "ingress-nginx"},
+				{Key: "k8s_service", Value: "ingress-nginx-controller"},
+			},
+		},
+		{
+			testName: "load balancer controller ALB for an ingress group",
+			resourceTags: []Tag{
+				{Key: "elbv2.k8s.aws/cluster", Value: "production"},
+				{Key: "ingress.k8s.aws/stack", Value: "public"},
+			},
+			result: []Tag{
+				{Key: "k8s_cluster", Value: "production"},
+				{Key: "k8s_namespace", Value: ""},
+				{Key: "k8s_service", Value: ""},
+			},
+		},
+		{
+			testName: "EBS CSI volume",
+			resourceTags: []Tag{
+				{Key: "kubernetes.io/cluster/production", Value: "owned"},
+				{Key: "kubernetes.io/created-for/pvc/namespace", Value: "monitoring"},
+				{Key: "kubernetes.io/created-for/pvc/name", Value: "prometheus-data"},
+			},
+			result: []Tag{
+				{Key: "k8s_cluster", Value: "production"},
+				{Key: "k8s_namespace", Value: "monitoring"},
+				{Key: "k8s_service", Value: ""},
+			},
+		},
+		{
+			testName: "karpenter node",
+			resourceTags: []Tag{
+				{Key: "karpenter.sh/nodepool", Value: "default"},
+				{Key: "eks:eks-cluster-name", Value: "production"},
+				{Key: "kubernetes.io/cluster/production", Value: "owned"},
+			},
+			result: []Tag{
+				{Key: "k8s_cluster", Value: "production"},
+				{Key: "k8s_namespace", Value: ""},
+				{Key: "k8s_service", Value: ""},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			res := TaggedResource{
+				ARN:       "aws::arn",
+				Namespace: "AWS/Service",
+				Region:    "us-east-1",
+				Tags:      tc.resourceTags,
+			}
+
+			require.Equal(t, tc.result, res.KubernetesTags())
+		})
+	}
+}
+
+func Test_ScheduleScraped(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	schedule := &Schedule{
+		Location: berlin,
+		Active: []ScheduleWindow{
+			{Days: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}, Start: 7 * time.Hour, End: 20 * time.Hour},
+		},
+		Blackouts: []ScheduleWindow{
+			{Days: []time.Weekday{time.Wednesday}, Start: 12 * time.Hour, End: 13 * time.Hour},
+		},
+	}
+	overnight := &Schedule{
+		Location:  time.UTC,
+		Blackouts: []ScheduleWindow{{Days: []time.Weekday{time.Friday}, Start: 22 * time.Hour, End: 6 * time.Hour}},
+	}
+
+	for _, tc := range []struct {
+		name     string
+		schedule *Schedule
+		time     time.Time
+		scraped  bool
+	}{
+		{name: "no schedule", time: time.Date(2024, 1, 6, 3, 0, 0, 0, time.UTC), scraped: true},
+		// Monday 1st of January 2024, 08:00 in Berlin
+		{name: "active window", schedule: schedule, time: time.Date(2024, 1, 1, 7, 0, 0, 0, time.UTC), scraped: true},
+		{name: "before the active window", schedule: schedule, time: time.Date(2024, 1, 1, 5, 59, 0, 0, time.UTC), scraped: false},
+		{name: "end of the active window", schedule: schedule, time: time.Date(2024, 1, 1, 19, 0, 0, 0, time.UTC), scraped: false},
+		{name: "weekend", schedule: schedule, time: time.Date(2024, 1, 6, 10, 0, 0, 0, time.UTC), scraped: false},
+		{name: "blackout", schedule: schedule, time: time.Date(2024, 1, 3, 11, 30, 0, 0, time.UTC), scraped: false},
+		{name: "blackout on another day", schedule: schedule, time: time.Date(2024, 1, 4, 11, 30, 0, 0, time.UTC), scraped: true},
+		{name: "overnight blackout start", schedule: overnight, time: time.Date(2024, 1, 5, 23, 0, 0, 0, time.UTC), scraped: false},
+		{name: "overnight blackout end", schedule: overnight, time: time.Date(2024, 1, 6, 5, 0, 0, 0, time.UTC), scraped: false},
+		{name: "after the overnight blackout", schedule: overnight, time: time.Date(2024, 1, 6, 6, 0, 0, 0, time.UTC), scraped: true},
+		{name: "overnight blackout of another day", schedule: overnight, time: time.Date(2024, 1, 5, 5, 0, 0, 0, time.UTC), scraped: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.scraped, tc.schedule.Scraped(tc.time))
+		})
+	}
+}
+
+func Test_PartitionOf(t *testing.T) {
+	require.Equal(t, PartitionAWS, PartitionOf("eu-west-1"))
+	require.Equal(t, PartitionAWS, PartitionOf("us-east-1"))
+	require.Equal(t, PartitionChina, PartitionOf("cn-north-1"))
+	require.Equal(t, PartitionGovCloud, PartitionOf("us-gov-west-1"))
+}
@@ -0,0 +1,232 @@
+// Package organizations discovers the member accounts of an AWS Organization,
+// for the discovery jobs with organizationAccounts to assume the same role in
+// every account selected by the OU, tag and account filters.
+package organizations
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/organizations"
+	"github.com/aws/aws-sdk-go/service/organizations/organizationsiface"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+// defaultRegion is the region of the Organizations API
+// when none is configured, the API is global.
+const defaultRegion = "us-east-1"
+
+// Account is an active member account of the organization.
+type Account struct {
+	ID        string
+	Name      string
+	Partition string
+	// OrganizationalUnits are the IDs of the OUs the account is in, directly or not
+	OrganizationalUnits []string
+	Tags                map[string]string
+}
+
+// Discoverer lists the accounts of the organization selected by the configuration.
+type Discoverer struct {
+	logger           logging.Logger
+	cfg              model.OrganizationConfig
+	organizationsAPI organizationsiface.OrganizationsAPI
+}
+
+// NewDiscoverer returns a Discoverer calling the Organizations API
+// with the management role of the configuration, if any.
+func NewDiscoverer(logger logging.Logger, cfg model.OrganizationConfig, fips bool) (*Discoverer, error) {
+	sess, err := session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the Organizations session: %w", err)
+	}
+
+	awsCfg := &aws.Config{}
+	if aws.StringValue(sess.Config.Region) == "" {
+		awsCfg.Region = aws.String(defaultRegion)
+	}
+	if fips {
+		awsCfg.UseFIPSEndpoint = endpoints.FIPSEndpointStateEnabled
+	}
+	if cfg.ManagementRoleArn != "" {
+		awsCfg.Credentials = stscreds.NewCredentials(sess, cfg.ManagementRoleArn)
+	}
+
+	return &Discoverer{
+		logger:           logger,
+		cfg:              cfg,
+		organizationsAPI: organizations.New(sess, awsCfg),
+	}, nil
+}
+
+// Roles returns the roles to assume in the selected accounts, sorted by account ID.
+func (d *Discoverer) Roles(ctx context.Context) ([]model.Role, error) {
+	accounts, err := d.Accounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := make([]model.Role, 0, len(accounts))
+	for _, account := range accounts {
+		roles = append(roles, model.Role{
+			RoleArn:    fmt.Sprintf("arn:%s:iam::%s:role/%s", account.Partition, account.ID, d.cfg.RoleName),
+			ExternalID: d.cfg.ExternalID,
+		})
+	}
+	return roles, nil
+}
+
+// Accounts returns the active accounts selected by the configuration, sorted by ID.
+// The OUs and the tags of the accounts are only listed when they are filtered on.
+func (d *Discoverer) Accounts(ctx context.Context) ([]Account, error) {
+	var accounts []Account
+	err := d.organizationsAPI.ListAccountsPagesWithContext(ctx, &organizations.ListAccountsInput{}, func(page *organizations.ListAccountsOutput, _ bool) bool {
+		for _, account := range page.Accounts {
+			if aws.StringValue(account.Status) != organizations.AccountStatusActive {
+				continue
+			}
+			partition := "aws"
+			if parsed, err := arn.Parse(aws.StringValue(account.Arn)); err == nil {
+				partition = parsed.Partition
+			}
+			accounts = append(accounts, Account{
+				ID:        aws.StringValue(account.Id),
+				Name:      aws.StringValue(account.Name),
+				Partition: partition,
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the accounts of the organization: %w", err)
+	}
+
+	// Parents of the OUs, shared by the accounts of the same OUs
+	parents := map[string]string{}
+	selected := make([]Account, 0, len(accounts))
+	for _, account := range accounts {
+		if len(d.cfg.IncludeOrganizationalUnits) > 0 || len(d.cfg.ExcludeOrganizationalUnits) > 0 {
+			account.OrganizationalUnits, err = d.organizationalUnits(ctx, account.ID, parents)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if len(d.cfg.AccountTags) > 0 {
+			account.Tags, err = d.tags(ctx, account.ID)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if !Selected(account, d.cfg) {
+			d.logger.Debug("Skipping organization account", "account_id", account.ID, "account_name", account.Name)
+			continue
+		}
+		selected = append(selected, account)
+	}
+
+	sort.Slice(selected, func(i, j int) bool {
+		return selected[i].ID < selected[j].ID
+	})
+	return selected, nil
+}
+
+// organizationalUnits returns the OUs of the account, from its parent up to the root.
+func (d *Discoverer) organizationalUnits(ctx context.Context, accountID string, parents map[string]string) ([]string, error) {
+	var ous []string
+	child := accountID
+	for {
+		parent, ok := parents[child]
+		if !ok {
+			out, err := d.organizationsAPI.ListParentsWithContext(ctx, &organizations.ListParentsInput{ChildId: aws.String(child)})
+			if err != nil {
+				return nil, fmt.Errorf("failed to list the parents of %s: %w", child, err)
+			}
+			// Accounts and OUs have exactly one parent
+			for _, p := range out.Parents {
+				if aws.StringValue(p.Type) == organizations.ParentTypeOrganizationalUnit {
+					parent = aws.StringValue(p.Id)
+				}
+			}
+			if child != accountID {
+				parents[child] = parent
+			}
+		}
+		if parent == "" {
+			// The parent is the root
+			return ous, nil
+		}
+		ous = append(ous, parent)
+		child = parent
+	}
+}
+
+func (d *Discoverer) tags(ctx context.Context, accountID string) (map[string]string, error) {
+	tags := map[string]string{}
+	err := d.organizationsAPI.ListTagsForResourcePagesWithContext(ctx, &organizations.ListTagsForResourceInput{ResourceId: aws.String(accountID)}, func(page *organizations.ListTagsForResourceOutput, _ bool) bool {
+		for _, tag := range page.Tags {
+			tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the tags of account %s: %w", accountID, err)
+	}
+	return tags, nil
+}
+
+// Selected returns whether the account is scraped. The exclusions take precedence.
+// When accounts or OUs are included, the account must be one of them or in one
+// of them. The account must also have all the account tags.
+func Selected(account Account, cfg model.OrganizationConfig) bool {
+	if slices.Contains(cfg.ExcludeAccounts, account.ID) {
+		return false
+	}
+	for _, ou := range account.OrganizationalUnits {
+		if slices.Contains(cfg.ExcludeOrganizationalUnits, ou) {
+			return false
+		}
+	}
+
+	if len(cfg.IncludeAccounts) > 0 || len(cfg.IncludeOrganizationalUnits) > 0 {
+		included := slices.Contains(cfg.IncludeAccounts, account.ID)
+		for _, ou := range account.OrganizationalUnits {
+			included = included || slices.Contains(cfg.IncludeOrganizationalUnits, ou)
+		}
+		if !included {
+			return false
+		}
+	}
+
+	for _, tag := range cfg.AccountTags {
+		value, ok := account.Tags[tag.Key]
+		if !ok || !tag.Value.MatchString(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// ExpandRoles returns the jobs config with the roles added to the
+// discovery jobs with OrganizationAccounts.
+func ExpandRoles(jobsCfg model.JobsConfig, roles []model.Role) model.JobsConfig {
+	jobs := make([]model.DiscoveryJob, 0, len(jobsCfg.DiscoveryJobs))
+	for _, job := range jobsCfg.DiscoveryJobs {
+		if job.OrganizationAccounts {
+			job.Roles = append(slices.Clone(job.Roles), roles...)
+		}
+		jobs = append(jobs, job)
+	}
+	jobsCfg.DiscoveryJobs = jobs
+	return jobsCfg
+}
@@ -0,0 +1,131 @@
+package organizations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/organizations"
+	"github.com/aws/aws-sdk-go/service/organizations/organizationsiface"
+	"github.com/grafana/regexp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+func TestSelected(t *testing.T) {
+	account := Account{
+		ID:                  "111111111111",
+		OrganizationalUnits: []string{"ou-prod-apps", "ou-prod"},
+		Tags:                map[string]string{"environment": "production"},
+	}
+
+	for _, tc := range []struct {
+		name     string
+		cfg      model.OrganizationConfig
+		selected bool
+	}{
+		{name: "no filter", selected: true},
+		{name: "included account", cfg: model.OrganizationConfig{IncludeAccounts: []string{"111111111111"}}, selected: true},
+		{name: "other included account", cfg: model.OrganizationConfig{IncludeAccounts: []string{"222222222222"}}, selected: false},
+		{name: "included parent OU", cfg: model.OrganizationConfig{IncludeOrganizationalUnits: []string{"ou-prod"}}, selected: true},
+		{name: "included account or OU", cfg: model.OrganizationConfig{IncludeAccounts: []string{"222222222222"}, IncludeOrganizationalUnits: []string{"ou-prod-apps"}}, selected: true},
+		{name: "excluded account", cfg: model.OrganizationConfig{ExcludeAccounts: []string{"111111111111"}}, selected: false},
+		{name: "excluded OU wins", cfg: model.OrganizationConfig{IncludeAccounts: []string{"111111111111"}, ExcludeOrganizationalUnits: []string{"ou-prod-apps"}}, selected: false},
+		{name: "matching account tag", cfg: model.OrganizationConfig{AccountTags: []model.SearchTag{{Key: "environment", Value: regexp.MustCompile("prod.*")}}}, selected: true},
+		{name: "other account tag", cfg: model.OrganizationConfig{AccountTags: []model.SearchTag{{Key: "environment", Value: regexp.MustCompile("^staging$")}}}, selected: false},
+		{name: "missing account tag", cfg: model.OrganizationConfig{AccountTags: []model.SearchTag{{Key: "team", Value: regexp.MustCompile(".*")}}}, selected: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.selected, Selected(account, tc.cfg))
+		})
+	}
+}
+
+type organizationsAPI struct {
+	organizationsiface.OrganizationsAPI
+	accounts []*organizations.Account
+	parents  map[string]*organizations.Parent
+	tags     map[string][]*organizations.Tag
+	calls    map[string]int
+}
+
+func (o *organizationsAPI) ListAccountsPagesWithContext(_ aws.Context, _ *organizations.ListAccountsInput, fn func(*organizations.ListAccountsOutput, bool) bool, _ ...request.Option) error {
+	fn(&organizations.ListAccountsOutput{Accounts: o.accounts}, true)
+	return nil
+}
+
+func (o *organizationsAPI) ListParentsWithContext(_ aws.Context, input *organizations.ListParentsInput, _ ...request.Option) (*organizations.ListParentsOutput, error) {
+	o.calls[aws.StringValue(input.ChildId)]++
+	return &organizations.ListParentsOutput{Parents: []*organizations.Parent{o.parents[aws.StringValue(input.ChildId)]}}, nil
+}
+
+func (o *organizationsAPI) ListTagsForResourcePagesWithContext(_ aws.Context, input *organizations.ListTagsForResourceInput, fn func(*organizations.ListTagsForResourceOutput, bool) bool, _ ...request.Option) error {
+	fn(&organizations.ListTagsForResourceOutput{Tags: o.tags[aws.StringValue(input.ResourceId)]}, true)
+	return nil
+}
+
+func TestDiscovererRoles(t *testing.T) {
+	ou := func(id string) *organizations.Parent {
+		return &organizations.Parent{Id: aws.String(id), Type: aws.String(organizations.ParentTypeOrganizationalUnit)}
+	}
+	api := &organizationsAPI{
+		accounts: []*organizations.Account{
+			{Id: aws.String("333333333333"), Arn: aws.String("arn:aws:organizations::999999999999:account/o-1/333333333333"), Status: aws.String(organizations.AccountStatusActive)},
+			{Id: aws.String("111111111111"), Arn: aws.String("arn:aws:organizations::999999999999:account/o-1/111111111111"), Status: aws.String(organizations.AccountStatusActive)},
+			{Id: aws.String("222222222222"), Arn: aws.String("arn:aws:organizations::999999999999:account/o-1/222222222222"), Status: aws.String(organizations.AccountStatusSuspended)},
+			{Id: aws.String("444444444444"), Arn: aws.String("arn:aws:organizations::999999999999:account/o-1/444444444444"), Status: aws.String(organizations.AccountStatusActive)},
+		},
+		parents: map[string]*organizations.Parent{
+			"111111111111": ou("ou-prod-apps"),
+			"333333333333": ou("ou-prod-apps"),
+			"444444444444": ou("ou-sandbox"),
+			"ou-prod-apps": ou("ou-prod"),
+			"ou-prod":      {Id: aws.String("r-root"), Type: aws.String(organizations.ParentTypeRoot)},
+			"ou-sandbox":   {Id: aws.String("r-root"), Type: aws.String(organizations.ParentTypeRoot)},
+		},
+		tags: map[string][]*organizations.Tag{
+			"111111111111": {{Key: aws.String("monitoring"), Value: aws.String("enabled")}},
+			"333333333333": {{Key: aws.String("monitoring"), Value: aws.String("enabled")}},
+			"444444444444": {{Key: aws.String("monitoring"), Value: aws.String("enabled")}},
+		},
+		calls: map[string]int{},
+	}
+	d := &Discoverer{
+		logger: logging.NewNopLogger(),
+		cfg: model.OrganizationConfig{
+			RoleName:                   "yace",
+			ExternalID:                 "external-id",
+			IncludeOrganizationalUnits: []string{"ou-prod"},
+			AccountTags:                []model.SearchTag{{Key: "monitoring", Value: regexp.MustCompile("^enabled$")}},
+		},
+		organizationsAPI: api,
+	}
+
+	roles, err := d.Roles(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []model.Role{
+		{RoleArn: "arn:aws:iam::111111111111:role/yace", ExternalID: "external-id"},
+		{RoleArn: "arn:aws:iam::333333333333:role/yace", ExternalID: "external-id"},
+	}, roles)
+	require.Equal(t, 1, api.calls["ou-prod-apps"], "the parents of an OU should be listed once")
+}
+
+func TestExpandRoles(t *testing.T) {
+	jobsCfg := model.JobsConfig{
+		DiscoveryJobs: []model.DiscoveryJob{
+			{Type: "AWS/EC2", Roles: []model.Role{{}}},
+			{Type: "AWS/SQS", OrganizationAccounts: true},
+			{Type: "AWS/RDS", OrganizationAccounts: true, Roles: []model.Role{{RoleArn: "arn:aws:iam::999999999999:role/yace"}}},
+		},
+	}
+	roles := []model.Role{{RoleArn: "arn:aws:iam::111111111111:role/yace"}}
+
+	expanded := ExpandRoles(jobsCfg, roles)
+	require.Equal(t, []model.Role{{}}, expanded.DiscoveryJobs[0].Roles)
+	require.Equal(t, roles, expanded.DiscoveryJobs[1].Roles)
+	require.Equal(t, []model.Role{{RoleArn: "arn:aws:iam::999999999999:role/yace"}, {RoleArn: "arn:aws:iam::111111111111:role/yace"}}, expanded.DiscoveryJobs[2].Roles)
+	require.Empty(t, jobsCfg.DiscoveryJobs[1].Roles, "the jobs config should not be modified")
+}
@@ -0,0 +1,142 @@
+package promutil
+
+import (
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+
+	prom_model "github.com/prometheus/common/model"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+// isHistogramStatistic returns true if the statistic is one the histogram
+// of the metric is synthesized from, rather than exported as a gauge.
+func isHistogramStatistic(cwd *model.CloudwatchData, statistic string) bool {
+	if len(cwd.HistogramBuckets) == 0 {
+		return false
+	}
+	if statistic == "SampleCount" || statistic == "Sum" {
+		return true
+	}
+	return slices.ContainsFunc(cwd.HistogramBuckets, func(bucket float64) bool {
+		return model.HistogramBucketStatistic(bucket) == statistic
+	})
+}
+
+type histogramKey struct {
+	name      string
+	signature uint64
+}
+
+// histogram holds the statistics of a metric of a resource.
+type histogram struct {
+	data      *model.CloudwatchData
+	labels    map[string]string
+	values    map[string]*float64
+	timestamp time.Time
+}
+
+// histogramBuilder groups the statistics of the metrics with histogram
+// buckets per metric and resource, and builds their histograms.
+type histogramBuilder struct {
+	histograms     map[histogramKey]*histogram
+	keys           []histogramKey
+	namespaceAlias string
+}
+
+func newHistogramBuilder(namespaceAlias string) *histogramBuilder {
+	return &histogramBuilder{histograms: map[histogramKey]*histogram{}, namespaceAlias: namespaceAlias}
+}
+
+func (b *histogramBuilder) add(cwd *model.CloudwatchData, statistic string, labelsSnakeCase bool, logger logging.Logger) error {
+	value, timestamp, err := getDatapoint(cwd, statistic)
+	if err != nil {
+		return err
+	}
+
+	labels := createPrometheusLabels(cwd, labelsSnakeCase, logger)
+	key := histogramKey{name: metricBaseName(cwd, b.namespaceAlias), signature: prom_model.LabelsToSignature(labels)}
+	h, ok := b.histograms[key]
+	if !ok {
+		h = &histogram{data: cwd, labels: labels, values: map[string]*float64{}}
+		b.histograms[key] = h
+		b.keys = append(b.keys, key)
+	}
+	h.values[statistic] = value
+	if statistic == "SampleCount" {
+		h.timestamp = timestamp
+	}
+	return nil
+}
+
+// metrics returns the _bucket, _sum and _count series of the histograms. The
+// count of a bucket is the SampleCount times the percentile rank of its upper
+// bound. Histograms without SampleCount, or missing a statistic while having
+// samples, are skipped.
+func (b *histogramBuilder) metrics(contextLabels map[string]string, transforms []labelTransform, logger logging.Logger) []*PrometheusMetric {
+	var output []*PrometheusMetric
+	for _, key := range b.keys {
+		h := b.histograms[key]
+		count := h.values["SampleCount"]
+		if count == nil {
+			continue
+		}
+
+		sum := h.values["Sum"]
+		if sum == nil && *count > 0 {
+			logger.Debug("Skipping histogram without Sum", "metric", key.name)
+			continue
+		}
+		buckets := make([]float64, 0, len(h.data.HistogramBuckets))
+		for _, bucket := range h.data.HistogramBuckets {
+			rank := h.values[model.HistogramBucketStatistic(bucket)]
+			if rank == nil && *count > 0 {
+				break
+			}
+			var value float64
+			if rank != nil {
+				value = math.Round(*count * *rank / 100)
+			}
+			buckets = append(buckets, value)
+		}
+		if len(buckets) != len(h.data.HistogramBuckets) {
+			logger.Debug("Skipping histogram with a missing bucket", "metric", key.name)
+			continue
+		}
+
+		labels := maps.Clone(h.labels)
+		maps.Copy(labels, contextLabels)
+		applyLabelTransforms(transforms, h.data, "", contextLabels["region"], labels)
+
+		includeTimestamp := h.data.AddCloudwatchTimestamp != nil && *h.data.AddCloudwatchTimestamp
+		series := func(suffix string, value float64, extraLabels ...string) *PrometheusMetric {
+			seriesLabels := maps.Clone(labels)
+			for i := 0; i < len(extraLabels); i += 2 {
+				seriesLabels[extraLabels[i]] = extraLabels[i+1]
+			}
+			name := key.name + suffix
+			return &PrometheusMetric{
+				Name:             &name,
+				Labels:           seriesLabels,
+				Value:            &value,
+				Timestamp:        h.timestamp,
+				IncludeTimestamp: includeTimestamp,
+			}
+		}
+
+		for i, bucket := range h.data.HistogramBuckets {
+			output = append(output, series("_bucket", buckets[i], "le", strconv.FormatFloat(bucket, 'g', -1, 64)))
+		}
+		output = append(output, series("_bucket", *count, "le", "+Inf"))
+		var sumValue float64
+		if sum != nil {
+			sumValue = *sum
+		}
+		output = append(output, series("_sum", sumValue), series("_count", *count))
+	}
+	return output
+}
@@ -0,0 +1,54 @@
+package promutil
+
+import (
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/labeltransform"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+type labelTransform struct {
+	label      string
+	expression *labeltransform.Expression
+}
+
+func compileLabelTransforms(configs []model.LabelTransformConfig, logger logging.Logger) []labelTransform {
+	transforms := make([]labelTransform, 0, len(configs))
+	for _, cfg := range configs {
+		expression, err := labeltransform.Parse(cfg.Expression)
+		if err != nil {
+			// Expressions are validated with the configuration
+			logger.Error(err, "Failed to parse label transform", "label", cfg.Label)
+			continue
+		}
+		transforms = append(transforms, labelTransform{label: cfg.Label, expression: expression})
+	}
+	return transforms
+}
+
+// applyLabelTransforms sets the labels of the transforms evaluating to a non-empty value.
+// All the transforms are evaluated against the labels built by the exporter.
+func applyLabelTransforms(transforms []labelTransform, cwd *model.CloudwatchData, statistic string, region string, labels map[string]string) {
+	if len(transforms) == 0 {
+		return
+	}
+	env := &labeltransform.Env{
+		ARN:        *cwd.ID,
+		Tags:       cwd.ResourceTags,
+		Dimensions: cwd.Dimensions,
+		Labels:     labels,
+		Region:     region,
+		Namespace:  *cwd.Namespace,
+		Metric:     *cwd.Metric,
+		Statistic:  statistic,
+		Period:     cwd.Period,
+	}
+	values := make([]string, len(transforms))
+	for i, transform := range transforms {
+		values[i] = transform.expression.Eval(env)
+	}
+	for i, transform := range transforms {
+		if values[i] != "" {
+			labels[transform.label] = values[i]
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package promutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+func TestBuildMetricsWithLabelTransforms(t *testing.T) {
+	data := []model.CloudwatchMetricResult{{
+		Context: &model.ScrapeContext{Region: "eu-west-1", AccountID: "123456789012"},
+		Data: []*model.CloudwatchData{
+			{
+				Metric:                  aws.String("NumberOfMessagesSent"),
+				Namespace:               aws.String("AWS/SQS"),
+				Statistics:              []string{"Sum"},
+				Dimensions:              []*model.Dimension{{Name: "QueueName", Value: "orders"}},
+				GetMetricDataPoint:      aws.Float64(1),
+				GetMetricDataTimestamps: time.Now(),
+				ID:                      aws.String("arn:aws:sqs:eu-west-1:123456789012:orders"),
+				ResourceTags:            []model.Tag{{Key: "app", Value: "checkout"}},
+			},
+			{
+				Metric:                  aws.String("NumberOfMessagesSent"),
+				Namespace:               aws.String("AWS/SQS"),
+				Statistics:              []string{"Sum"},
+				Dimensions:              []*model.Dimension{{Name: "QueueName", Value: "payments"}},
+				GetMetricDataPoint:      aws.Float64(2),
+				GetMetricDataTimestamps: time.Now(),
+				ID:                      aws.String("arn:aws:sqs:eu-west-1:123456789012:payments"),
+			},
+		},
+		LabelTransforms: []model.LabelTransformConfig{
+			{Label: "region", Expression: `upper(labels["region"])`},
+			{Label: "service", Expression: `resource.tags["app"] || arn.resource`},
+		},
+	}}
+
+	metrics, labels, err := BuildMetrics(data, false, logging.NewNopLogger())
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+
+	require.Equal(t, "checkout", metrics[0].Labels["service"])
+	require.Equal(t, "payments", metrics[1].Labels["service"])
+	require.Equal(t, "EU-WEST-1", metrics[0].Labels["region"])
+	require.Contains(t, labels["aws_sqs_number_of_messages_sent_sum"], "service")
+}
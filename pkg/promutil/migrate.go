@@ -5,6 +5,7 @@ import (
 	"maps"
 	"math"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -22,16 +23,9 @@ func BuildNamespaceInfoMetrics(tagData []model.TaggedResourceResult, metrics []*
 	for _, tagResult := range tagData {
 		contextLabels := contextToLabels(tagResult.Context, labelsSnakeCase, logger)
 		for _, d := range tagResult.Data {
-			sb := strings.Builder{}
-			promNs := PromString(strings.ToLower(d.Namespace))
-			if !strings.HasPrefix(promNs, "aws") {
-				sb.WriteString("aws_")
-			}
-			sb.WriteString(promNs)
-			sb.WriteString("_info")
-			metricName := sb.String()
+			metricName := metricNamespace(d.Namespace, tagResult.NamespaceAlias) + "_info"
 
-			promLabels := make(map[string]string, len(d.Tags)+len(contextLabels)+1)
+			promLabels := make(map[string]string, len(d.Tags)+len(d.Attributes)+len(d.InfoAttributes)+len(contextLabels)+1)
 			maps.Copy(promLabels, contextLabels)
 			promLabels["name"] = d.ARN
 			for _, tag := range d.Tags {
@@ -45,26 +39,302 @@ func BuildNamespaceInfoMetrics(tagData []model.TaggedResourceResult, metrics []*
 				promLabels[labelName] = tag.Value
 			}
 
+			for _, attribute := range d.Attributes {
+				promLabels[attribute.Key] = attribute.Value
+			}
+
+			for _, attribute := range d.InfoAttributes {
+				promLabels[attribute.Key] = attribute.Value
+			}
+
 			observedMetricLabels = recordLabelsForMetric(metricName, promLabels, observedMetricLabels)
 			metrics = append(metrics, &PrometheusMetric{
 				Name:   &metricName,
 				Labels: promLabels,
 				Value:  aws.Float64(0),
 			})
+
+			// The values are joined with the info metric on the name label
+			for _, value := range d.Values {
+				valueName := metricNamespace(d.Namespace, tagResult.NamespaceAlias) + "_" + value.Name
+				valueLabels := make(map[string]string, len(contextLabels)+1)
+				maps.Copy(valueLabels, contextLabels)
+				valueLabels["name"] = d.ARN
+
+				observedMetricLabels = recordLabelsForMetric(valueName, valueLabels, observedMetricLabels)
+				metrics = append(metrics, &PrometheusMetric{
+					Name:   &valueName,
+					Labels: valueLabels,
+					Value:  aws.Float64(value.Value),
+				})
+			}
+		}
+	}
+
+	return metrics, observedMetricLabels
+}
+
+const inventoryMetricName = "aws_resource_info"
+
+// BuildInventoryMetrics builds a single aws_resource_info metric
+// for the resources of all the namespaces, labelled with their tags.
+func BuildInventoryMetrics(inventoryData []model.TaggedResourceResult, metrics []*PrometheusMetric, observedMetricLabels map[string]model.LabelSet, labelsSnakeCase bool, logger logging.Logger) ([]*PrometheusMetric, map[string]model.LabelSet) {
+	for _, inventoryResult := range inventoryData {
+		contextLabels := contextToLabels(inventoryResult.Context, labelsSnakeCase, logger)
+		for _, d := range inventoryResult.Data {
+			metricName := inventoryMetricName
+			promLabels := make(map[string]string, len(d.Tags)+len(contextLabels)+3)
+			maps.Copy(promLabels, contextLabels)
+			promLabels["arn"] = d.ARN
+			promLabels["namespace"] = d.Namespace
+			promLabels["region"] = d.Region
+			for _, tag := range d.Tags {
+				ok, promTag := PromStringTag(tag.Key, labelsSnakeCase)
+				if !ok {
+					logger.Warn("tag name is an invalid prometheus label name", "tag", tag.Key)
+					continue
+				}
+				promLabels["tag_"+promTag] = tag.Value
+			}
+
+			observedMetricLabels = recordLabelsForMetric(metricName, promLabels, observedMetricLabels)
+			metrics = append(metrics, &PrometheusMetric{
+				Name:   &metricName,
+				Labels: promLabels,
+				Value:  aws.Float64(1),
+			})
+		}
+	}
+
+	return metrics, observedMetricLabels
+}
+
+const (
+	quotaLimitMetricName = "aws_quota_limit"
+	quotaUsageMetricName = "aws_quota_usage"
+)
+
+// BuildServiceQuotaMetrics builds the aws_quota_limit metric for every quota,
+// and the aws_quota_usage metric for the quotas with a known usage.
+func BuildServiceQuotaMetrics(quotaData []model.ServiceQuotaResult, metrics []*PrometheusMetric, observedMetricLabels map[string]model.LabelSet, labelsSnakeCase bool, logger logging.Logger) ([]*PrometheusMetric, map[string]model.LabelSet) {
+	for _, quotaResult := range quotaData {
+		contextLabels := contextToLabels(quotaResult.Context, labelsSnakeCase, logger)
+		for _, d := range quotaResult.Data {
+			promLabels := make(map[string]string, len(contextLabels)+3)
+			maps.Copy(promLabels, contextLabels)
+			promLabels["service_code"] = d.ServiceCode
+			promLabels["quota_code"] = d.QuotaCode
+			promLabels["quota_name"] = d.QuotaName
+
+			limitMetricName := quotaLimitMetricName
+			observedMetricLabels = recordLabelsForMetric(limitMetricName, promLabels, observedMetricLabels)
+			metrics = append(metrics, &PrometheusMetric{
+				Name:   &limitMetricName,
+				Labels: promLabels,
+				Value:  aws.Float64(d.Value),
+			})
+
+			if d.Usage == nil {
+				continue
+			}
+			usageMetricName := quotaUsageMetricName
+			observedMetricLabels = recordLabelsForMetric(usageMetricName, promLabels, observedMetricLabels)
+			metrics = append(metrics, &PrometheusMetric{
+				Name:   &usageMetricName,
+				Labels: promLabels,
+				Value:  d.Usage,
+			})
+		}
+	}
+
+	return metrics, observedMetricLabels
+}
+
+const trustedAdvisorCheckStatusMetricName = "aws_trustedadvisor_check_status"
+
+// TrustedAdvisorCheckStatuses are the statuses of a Trusted Advisor check,
+// exported as a state set.
+var TrustedAdvisorCheckStatuses = []string{"ok", "warning", "error", "not_available"}
+
+// BuildTrustedAdvisorMetrics builds the aws_trustedadvisor_check_status metric, set to 1 for
+// the current status of the check and 0 for the others, and the number of resources of the checks.
+func BuildTrustedAdvisorMetrics(checkData []model.TrustedAdvisorResult, metrics []*PrometheusMetric, observedMetricLabels map[string]model.LabelSet, labelsSnakeCase bool, logger logging.Logger) ([]*PrometheusMetric, map[string]model.LabelSet) {
+	for _, checkResult := range checkData {
+		contextLabels := contextToLabels(checkResult.Context, labelsSnakeCase, logger)
+		for _, d := range checkResult.Data {
+			promLabels := make(map[string]string, len(contextLabels)+3)
+			maps.Copy(promLabels, contextLabels)
+			promLabels["check_id"] = d.ID
+			promLabels["check_name"] = d.Name
+			promLabels["category"] = d.Category
+
+			for _, status := range TrustedAdvisorCheckStatuses {
+				statusLabels := maps.Clone(promLabels)
+				statusLabels["status"] = status
+				value := 0.0
+				if d.Status == status {
+					value = 1
+				}
+
+				metricName := trustedAdvisorCheckStatusMetricName
+				observedMetricLabels = recordLabelsForMetric(metricName, statusLabels, observedMetricLabels)
+				metrics = append(metrics, &PrometheusMetric{
+					Name:   &metricName,
+					Labels: statusLabels,
+					Value:  aws.Float64(value),
+				})
+			}
+
+			resources := []struct {
+				name  string
+				value int64
+			}{
+				{name: "aws_trustedadvisor_flagged_resources", value: d.ResourcesFlagged},
+				{name: "aws_trustedadvisor_processed_resources", value: d.ResourcesProcessed},
+				{name: "aws_trustedadvisor_suppressed_resources", value: d.ResourcesSuppressed},
+				{name: "aws_trustedadvisor_ignored_resources", value: d.ResourcesIgnored},
+			}
+			for _, r := range resources {
+				metricName := r.name
+				observedMetricLabels = recordLabelsForMetric(metricName, promLabels, observedMetricLabels)
+				metrics = append(metrics, &PrometheusMetric{
+					Name:   &metricName,
+					Labels: promLabels,
+					Value:  aws.Float64(float64(r.value)),
+				})
+			}
+		}
+	}
+
+	return metrics, observedMetricLabels
+}
+
+// BuildLogsInsightsMetrics builds an aws_logs_insights_<field> metric per numeric field
+// of the query results, labelled with the name of the job and the other fields of the row.
+func BuildLogsInsightsMetrics(queryData []model.LogsInsightsResult, metrics []*PrometheusMetric, observedMetricLabels map[string]model.LabelSet, labelsSnakeCase bool, logger logging.Logger) ([]*PrometheusMetric, map[string]model.LabelSet) {
+	for _, queryResult := range queryData {
+		contextLabels := contextToLabels(queryResult.Context, labelsSnakeCase, logger)
+		for _, d := range queryResult.Data {
+			metricName := "aws_logs_insights_" + PromString(d.Field)
+			if !prom_model.IsValidMetricName(prom_model.LabelValue(metricName)) {
+				logger.Warn("field name is an invalid prometheus metric name, use an alias in the query", "field", d.Field)
+				continue
+			}
+
+			promLabels := make(map[string]string, len(d.Labels)+len(contextLabels)+1)
+			maps.Copy(promLabels, contextLabels)
+			promLabels["name"] = d.JobName
+			for _, label := range d.Labels {
+				ok, promLabel := PromStringTag(label.Key, labelsSnakeCase)
+				if !ok {
+					logger.Warn("field name is an invalid prometheus label name", "field", label.Key)
+					continue
+				}
+				promLabels["field_"+promLabel] = label.Value
+			}
+
+			observedMetricLabels = recordLabelsForMetric(metricName, promLabels, observedMetricLabels)
+			metrics = append(metrics, &PrometheusMetric{
+				Name:   &metricName,
+				Labels: promLabels,
+				Value:  aws.Float64(d.Value),
+			})
+		}
+	}
+
+	return metrics, observedMetricLabels
+}
+
+// BuildContributorInsightsMetrics builds the unique contributors count and the aggregate value
+// of each Contributor Insights rule report, and the value of each of its top contributors,
+// labelled with its keys.
+func BuildContributorInsightsMetrics(reportData []model.ContributorInsightsResult, metrics []*PrometheusMetric, observedMetricLabels map[string]model.LabelSet, labelsSnakeCase bool, logger logging.Logger) ([]*PrometheusMetric, map[string]model.LabelSet) {
+	for _, reportResult := range reportData {
+		contextLabels := contextToLabels(reportResult.Context, labelsSnakeCase, logger)
+		for _, report := range reportResult.Data {
+			ruleLabels := make(map[string]string, len(contextLabels)+1)
+			maps.Copy(ruleLabels, contextLabels)
+			ruleLabels["rule_name"] = report.RuleName
+
+			uniqueContributorsMetricName := "aws_contributor_insights_unique_contributors"
+			observedMetricLabels = recordLabelsForMetric(uniqueContributorsMetricName, ruleLabels, observedMetricLabels)
+			metrics = append(metrics, &PrometheusMetric{
+				Name:   &uniqueContributorsMetricName,
+				Labels: ruleLabels,
+				Value:  aws.Float64(float64(report.UniqueContributors)),
+			})
+
+			aggregateLabels := make(map[string]string, len(ruleLabels)+1)
+			maps.Copy(aggregateLabels, ruleLabels)
+			aggregateLabels["statistic"] = report.AggregationStatistic
+
+			aggregateMetricName := "aws_contributor_insights_aggregate_value"
+			observedMetricLabels = recordLabelsForMetric(aggregateMetricName, aggregateLabels, observedMetricLabels)
+			metrics = append(metrics, &PrometheusMetric{
+				Name:   &aggregateMetricName,
+				Labels: aggregateLabels,
+				Value:  aws.Float64(report.AggregateValue),
+			})
+
+			keyLabels := contributorKeyLabels(report.KeyLabels, labelsSnakeCase)
+			for _, contributor := range report.Contributors {
+				if len(contributor.Keys) != len(keyLabels) {
+					logger.Warn("contributor keys do not match the rule keys", "rule_name", report.RuleName)
+					continue
+				}
+				contributorLabels := make(map[string]string, len(aggregateLabels)+len(keyLabels))
+				maps.Copy(contributorLabels, aggregateLabels)
+				for i, key := range contributor.Keys {
+					contributorLabels[keyLabels[i]] = key
+				}
+
+				contributorMetricName := "aws_contributor_insights_contributor_value"
+				observedMetricLabels = recordLabelsForMetric(contributorMetricName, contributorLabels, observedMetricLabels)
+				metrics = append(metrics, &PrometheusMetric{
+					Name:   &contributorMetricName,
+					Labels: contributorLabels,
+					Value:  aws.Float64(contributor.Value),
+				})
+			}
 		}
 	}
 
 	return metrics, observedMetricLabels
 }
 
+// contributorKeyLabels returns the key_<name> label names of the keys of a rule. Keys of rules
+// on JSON logs are paths like $.requestParameters.instanceId, the $. prefix is dropped. Keys which
+// are still not valid label names fall back to their position, e.g. key_0.
+func contributorKeyLabels(keys []string, labelsSnakeCase bool) []string {
+	labels := make([]string, 0, len(keys))
+	for i, key := range keys {
+		ok, promLabel := PromStringTag(strings.TrimPrefix(key, "$."), labelsSnakeCase)
+		if !ok {
+			promLabel = strconv.Itoa(i)
+		}
+		labels = append(labels, "key_"+promLabel)
+	}
+	return labels
+}
+
 func BuildMetrics(results []model.CloudwatchMetricResult, labelsSnakeCase bool, logger logging.Logger) ([]*PrometheusMetric, map[string]model.LabelSet, error) {
 	output := make([]*PrometheusMetric, 0)
 	observedMetricLabels := make(map[string]model.LabelSet)
 
 	for _, result := range results {
 		contextLabels := contextToLabels(result.Context, labelsSnakeCase, logger)
+		transforms := compileLabelTransforms(result.LabelTransforms, logger)
+		resultMetrics := make([]*PrometheusMetric, 0, len(result.Data))
+		histograms := newHistogramBuilder(result.NamespaceAlias)
 		for _, metric := range result.Data {
 			for _, statistic := range metric.Statistics {
+				if isHistogramStatistic(metric, statistic) {
+					if err := histograms.add(metric, statistic, labelsSnakeCase, logger); err != nil {
+						return nil, nil, err
+					}
+					continue
+				}
+
 				var includeTimestamp bool
 				if metric.AddCloudwatchTimestamp != nil {
 					includeTimestamp = *metric.AddCloudwatchTimestamp
@@ -81,23 +351,19 @@ func BuildMetrics(results []model.CloudwatchMetricResult, labelsSnakeCase bool,
 					}
 				}
 
-				sb := strings.Builder{}
-				promNs := PromString(strings.ToLower(*metric.Namespace))
-				if !strings.HasPrefix(promNs, "aws") {
-					sb.WriteString("aws_")
+				name := metricBaseName(metric, result.NamespaceAlias)
+				if metric.StatLabelMode != model.StatLabelModeLabel {
+					name += "_" + PromString(statistic)
 				}
-				sb.WriteString(promNs)
-				sb.WriteString("_")
-				sb.WriteString(PromString(*metric.Metric))
-				sb.WriteString("_")
-				sb.WriteString(PromString(statistic))
-				name := sb.String()
 
 				if exportedDatapoint != nil {
 					promLabels := createPrometheusLabels(metric, labelsSnakeCase, logger)
+					if metric.StatLabelMode == model.StatLabelModeLabel {
+						promLabels["stat"] = statistic
+					}
 					maps.Copy(promLabels, contextLabels)
-					observedMetricLabels = recordLabelsForMetric(name, promLabels, observedMetricLabels)
-					output = append(output, &PrometheusMetric{
+					applyLabelTransforms(transforms, metric, statistic, contextLabels["region"], promLabels)
+					resultMetrics = append(resultMetrics, &PrometheusMetric{
 						Name:             &name,
 						Labels:           promLabels,
 						Value:            exportedDatapoint,
@@ -107,16 +373,44 @@ func BuildMetrics(results []model.CloudwatchMetricResult, labelsSnakeCase bool,
 				}
 			}
 		}
+
+		resultMetrics = append(resultMetrics, histograms.metrics(contextLabels, transforms, logger)...)
+		resultMetrics = applyProcessors(result.Processors, resultMetrics, logger)
+		for _, metric := range resultMetrics {
+			observedMetricLabels = recordLabelsForMetric(*metric.Name, metric.Labels, observedMetricLabels)
+		}
+		output = append(output, resultMetrics...)
 	}
 
 	return output, observedMetricLabels, nil
 }
 
+// metricBaseName returns the name of the exported metric without the statistic,
+// e.g. aws_sqs_number_of_messages_sent.
+func metricBaseName(cwd *model.CloudwatchData, namespaceAlias string) string {
+	return metricNamespace(*cwd.Namespace, namespaceAlias) + "_" + PromString(*cwd.Metric)
+}
+
+// metricNamespace returns the prefix of the names of the metrics of the namespace,
+// e.g. aws_sqs, or aws_alb with the alias alb of AWS/ApplicationELB.
+func metricNamespace(namespace string, namespaceAlias string) string {
+	if namespaceAlias != "" {
+		namespace = namespaceAlias
+	}
+	promNs := PromString(strings.ToLower(namespace))
+	if !strings.HasPrefix(promNs, "aws") {
+		return "aws_" + promNs
+	}
+	return promNs
+}
+
 func getDatapoint(cwd *model.CloudwatchData, statistic string) (*float64, time.Time, error) {
 	if cwd.GetMetricDataPoint != nil {
 		return cwd.GetMetricDataPoint, cwd.GetMetricDataTimestamps, nil
 	}
 	var averageDataPoints []*model.Datapoint
+	var weightedSum, weightedCount float64
+	var weightedTimestamp time.Time
 
 	// sorting by timestamps so we can consistently export the most updated datapoint
 	// assuming Timestamp field in cloudwatch.Datapoint struct is never nil
@@ -142,6 +436,15 @@ func getDatapoint(cwd *model.CloudwatchData, statistic string) (*float64, time.T
 			if datapoint.Average != nil {
 				averageDataPoints = append(averageDataPoints, datapoint)
 			}
+		case statistic == model.StatisticWeightedAverage:
+			// Weighted by the samples of every datapoint, unlike Average
+			if datapoint.Sum != nil && datapoint.SampleCount != nil {
+				weightedSum += *datapoint.Sum
+				weightedCount += *datapoint.SampleCount
+				if datapoint.Timestamp.After(weightedTimestamp) {
+					weightedTimestamp = *datapoint.Timestamp
+				}
+			}
 		case Percentile.MatchString(statistic):
 			if data, ok := datapoint.ExtendedStatistics[statistic]; ok {
 				return data, *datapoint.Timestamp, nil
@@ -151,6 +454,10 @@ func getDatapoint(cwd *model.CloudwatchData, statistic string) (*float64, time.T
 		}
 	}
 
+	if weightedCount > 0 {
+		average := weightedSum / weightedCount
+		return &average, weightedTimestamp, nil
+	}
 	if len(averageDataPoints) > 0 {
 		var total float64
 		var timestamp time.Time
@@ -195,9 +502,19 @@ func createPrometheusLabels(cwd *model.CloudwatchData, labelsSnakeCase bool, log
 			logger.Warn("metric tag name is an invalid prometheus label name", "tag", tag.Key)
 			continue
 		}
+		// The first of the tags whose keys sanitize to the same label name wins
+		if _, ok := labels["tag_"+promTag]; ok {
+			logger.Debug("metric tag name collides with another tag once sanitized", "tag", tag.Key)
+			continue
+		}
 		labels["tag_"+promTag] = tag.Value
 	}
 
+	// Attribute keys are defined by the exporter and are already valid label names
+	for _, attribute := range cwd.Attributes {
+		labels[attribute.Key] = attribute.Value
+	}
+
 	return labels
 }
 
@@ -63,6 +63,38 @@ func TestBuildNamespaceInfoMetrics(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "namespace alias",
+			resources: []model.TaggedResourceResult{
+				{
+					Data: []*model.TaggedResource{
+						{
+							ARN:       "arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/app/my-alb/0123456789abcdef",
+							Namespace: "AWS/ApplicationELB",
+							Region:    "us-east-1",
+						},
+					},
+					NamespaceAlias: "alb",
+				},
+			},
+			metrics:              []*PrometheusMetric{},
+			observedMetricLabels: map[string]model.LabelSet{},
+			labelsSnakeCase:      false,
+			expectedMetrics: []*PrometheusMetric{
+				{
+					Name: aws.String("aws_alb_info"),
+					Labels: map[string]string{
+						"name": "arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/app/my-alb/0123456789abcdef",
+					},
+					Value: aws.Float64(0),
+				},
+			},
+			expectedLabels: map[string]model.LabelSet{
+				"aws_alb_info": map[string]struct{}{
+					"name": {},
+				},
+			},
+		},
 		{
 			name: "label snake case",
 			resources: []model.TaggedResourceResult{
@@ -222,6 +254,77 @@ func TestBuildNamespaceInfoMetrics(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "resource values",
+			resources: []model.TaggedResourceResult{
+				{
+					Context: &model.ScrapeContext{
+						Region:    "us-east-2",
+						AccountID: "12345",
+					},
+					Data: []*model.TaggedResource{
+						{
+							ARN:       "arn:aws:autoscaling:us-east-2:123456789012:autoScalingGroup:0123:autoScalingGroupName/workers",
+							Namespace: "AWS/AutoScaling",
+							Region:    "us-east-2",
+							Values: []model.ResourceValue{
+								{Name: "desired_capacity", Value: 3},
+								{Name: "max_size", Value: 10},
+							},
+						},
+					},
+				},
+			},
+			metrics:              []*PrometheusMetric{},
+			observedMetricLabels: map[string]model.LabelSet{},
+			labelsSnakeCase:      false,
+			expectedMetrics: []*PrometheusMetric{
+				{
+					Name: aws.String("aws_autoscaling_info"),
+					Labels: map[string]string{
+						"name":       "arn:aws:autoscaling:us-east-2:123456789012:autoScalingGroup:0123:autoScalingGroupName/workers",
+						"account_id": "12345",
+						"region":     "us-east-2",
+					},
+					Value: aws.Float64(0),
+				},
+				{
+					Name: aws.String("aws_autoscaling_desired_capacity"),
+					Labels: map[string]string{
+						"name":       "arn:aws:autoscaling:us-east-2:123456789012:autoScalingGroup:0123:autoScalingGroupName/workers",
+						"account_id": "12345",
+						"region":     "us-east-2",
+					},
+					Value: aws.Float64(3),
+				},
+				{
+					Name: aws.String("aws_autoscaling_max_size"),
+					Labels: map[string]string{
+						"name":       "arn:aws:autoscaling:us-east-2:123456789012:autoScalingGroup:0123:autoScalingGroupName/workers",
+						"account_id": "12345",
+						"region":     "us-east-2",
+					},
+					Value: aws.Float64(10),
+				},
+			},
+			expectedLabels: map[string]model.LabelSet{
+				"aws_autoscaling_info": map[string]struct{}{
+					"name":       {},
+					"account_id": {},
+					"region":     {},
+				},
+				"aws_autoscaling_desired_capacity": map[string]struct{}{
+					"name":       {},
+					"account_id": {},
+					"region":     {},
+				},
+				"aws_autoscaling_max_size": map[string]struct{}{
+					"name":       {},
+					"account_id": {},
+					"region":     {},
+				},
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -233,6 +336,284 @@ func TestBuildNamespaceInfoMetrics(t *testing.T) {
 	}
 }
 
+func TestBuildInventoryMetrics(t *testing.T) {
+	inventoryData := []model.TaggedResourceResult{
+		{
+			Context: &model.ScrapeContext{
+				Region:    "us-east-1",
+				AccountID: "12345",
+			},
+			Data: []*model.TaggedResource{
+				{
+					ARN:       "arn:aws:elasticache:us-east-1:123456789012:cluster:redis-cluster",
+					Namespace: "AWS/ElastiCache",
+					Region:    "us-east-1",
+					Tags:      []model.Tag{{Key: "CostCenter", Value: "42"}},
+				},
+				{
+					ARN:       "arn:aws:sqs:us-east-1:123456789012:queue",
+					Namespace: "AWS/SQS",
+					Region:    "us-east-1",
+				},
+			},
+		},
+	}
+
+	metrics, labels := BuildInventoryMetrics(inventoryData, []*PrometheusMetric{}, map[string]model.LabelSet{}, true, logging.NewNopLogger())
+	require.Equal(t, []*PrometheusMetric{
+		{
+			Name: aws.String("aws_resource_info"),
+			Labels: map[string]string{
+				"arn":             "arn:aws:elasticache:us-east-1:123456789012:cluster:redis-cluster",
+				"namespace":       "AWS/ElastiCache",
+				"region":          "us-east-1",
+				"account_id":      "12345",
+				"tag_cost_center": "42",
+			},
+			Value: aws.Float64(1),
+		},
+		{
+			Name: aws.String("aws_resource_info"),
+			Labels: map[string]string{
+				"arn":        "arn:aws:sqs:us-east-1:123456789012:queue",
+				"namespace":  "AWS/SQS",
+				"region":     "us-east-1",
+				"account_id": "12345",
+			},
+			Value: aws.Float64(1),
+		},
+	}, metrics)
+	require.Equal(t, map[string]model.LabelSet{
+		"aws_resource_info": map[string]struct{}{
+			"arn":             {},
+			"namespace":       {},
+			"region":          {},
+			"account_id":      {},
+			"tag_cost_center": {},
+		},
+	}, labels)
+}
+
+func TestBuildServiceQuotaMetrics(t *testing.T) {
+	quotaData := []model.ServiceQuotaResult{
+		{
+			Context: &model.ScrapeContext{
+				Region:    "us-east-1",
+				AccountID: "12345",
+			},
+			Data: []*model.ServiceQuota{
+				{
+					ServiceCode: "ec2",
+					QuotaCode:   "L-0263D0A3",
+					QuotaName:   "EC2-VPC Elastic IPs",
+					Value:       5,
+					Usage:       aws.Float64(3),
+				},
+				{
+					ServiceCode: "ec2",
+					QuotaCode:   "L-0E3CBAB9",
+					QuotaName:   "Network interfaces per Region",
+					Value:       5000,
+				},
+			},
+		},
+	}
+
+	eipLabels := map[string]string{
+		"account_id":   "12345",
+		"region":       "us-east-1",
+		"service_code": "ec2",
+		"quota_code":   "L-0263D0A3",
+		"quota_name":   "EC2-VPC Elastic IPs",
+	}
+	metrics, labels := BuildServiceQuotaMetrics(quotaData, []*PrometheusMetric{}, map[string]model.LabelSet{}, true, logging.NewNopLogger())
+	require.Equal(t, []*PrometheusMetric{
+		{
+			Name:   aws.String("aws_quota_limit"),
+			Labels: eipLabels,
+			Value:  aws.Float64(5),
+		},
+		{
+			Name:   aws.String("aws_quota_usage"),
+			Labels: eipLabels,
+			Value:  aws.Float64(3),
+		},
+		{
+			Name: aws.String("aws_quota_limit"),
+			Labels: map[string]string{
+				"account_id":   "12345",
+				"region":       "us-east-1",
+				"service_code": "ec2",
+				"quota_code":   "L-0E3CBAB9",
+				"quota_name":   "Network interfaces per Region",
+			},
+			Value: aws.Float64(5000),
+		},
+	}, metrics)
+	expectedLabels := model.LabelSet{"account_id": {}, "region": {}, "service_code": {}, "quota_code": {}, "quota_name": {}}
+	require.Equal(t, map[string]model.LabelSet{
+		"aws_quota_limit": expectedLabels,
+		"aws_quota_usage": expectedLabels,
+	}, labels)
+}
+
+func TestBuildTrustedAdvisorMetrics(t *testing.T) {
+	checkData := []model.TrustedAdvisorResult{
+		{
+			Context: &model.ScrapeContext{
+				Region:    "us-east-1",
+				AccountID: "12345",
+			},
+			Data: []*model.TrustedAdvisorCheck{
+				{
+					ID:                 "Pfx0RwqBli",
+					Name:               "Amazon S3 Bucket Permissions",
+					Category:           "security",
+					Status:             "warning",
+					ResourcesFlagged:   2,
+					ResourcesProcessed: 10,
+				},
+			},
+		},
+	}
+
+	metrics, labels := BuildTrustedAdvisorMetrics(checkData, []*PrometheusMetric{}, map[string]model.LabelSet{}, true, logging.NewNopLogger())
+	require.Len(t, metrics, len(TrustedAdvisorCheckStatuses)+4)
+
+	statuses := map[string]float64{}
+	for _, metric := range metrics[:len(TrustedAdvisorCheckStatuses)] {
+		require.Equal(t, "aws_trustedadvisor_check_status", *metric.Name)
+		require.Equal(t, "Pfx0RwqBli", metric.Labels["check_id"])
+		statuses[metric.Labels["status"]] = *metric.Value
+	}
+	require.Equal(t, map[string]float64{"ok": 0, "warning": 1, "error": 0, "not_available": 0}, statuses)
+
+	checkLabels := map[string]string{
+		"account_id": "12345",
+		"region":     "us-east-1",
+		"check_id":   "Pfx0RwqBli",
+		"check_name": "Amazon S3 Bucket Permissions",
+		"category":   "security",
+	}
+	require.Equal(t, &PrometheusMetric{
+		Name:   aws.String("aws_trustedadvisor_flagged_resources"),
+		Labels: checkLabels,
+		Value:  aws.Float64(2),
+	}, metrics[len(TrustedAdvisorCheckStatuses)])
+	require.Equal(t, &PrometheusMetric{
+		Name:   aws.String("aws_trustedadvisor_processed_resources"),
+		Labels: checkLabels,
+		Value:  aws.Float64(10),
+	}, metrics[len(TrustedAdvisorCheckStatuses)+1])
+	require.Contains(t, labels["aws_trustedadvisor_check_status"], "status")
+	require.NotContains(t, labels["aws_trustedadvisor_flagged_resources"], "status")
+}
+
+func TestBuildLogsInsightsMetrics(t *testing.T) {
+	queryData := []model.LogsInsightsResult{
+		{
+			Context: &model.ScrapeContext{
+				Region:    "us-east-1",
+				AccountID: "12345",
+			},
+			Data: []*model.LogsInsightsValue{
+				{
+					JobName: "errors",
+					Field:   "errorCount",
+					Value:   12,
+					Labels:  []model.Tag{{Key: "serviceName", Value: "orders"}},
+				},
+				{
+					JobName: "errors",
+					Field:   "count(*)",
+					Value:   12,
+				},
+			},
+		},
+	}
+
+	metrics, labels := BuildLogsInsightsMetrics(queryData, []*PrometheusMetric{}, map[string]model.LabelSet{}, true, logging.NewNopLogger())
+	require.Equal(t, []*PrometheusMetric{
+		{
+			Name: aws.String("aws_logs_insights_error_count"),
+			Labels: map[string]string{
+				"account_id":         "12345",
+				"region":             "us-east-1",
+				"name":               "errors",
+				"field_service_name": "orders",
+			},
+			Value: aws.Float64(12),
+		},
+	}, metrics)
+	require.Equal(t, map[string]model.LabelSet{
+		"aws_logs_insights_error_count": {"account_id": {}, "region": {}, "name": {}, "field_service_name": {}},
+	}, labels)
+}
+
+func TestBuildContributorInsightsMetrics(t *testing.T) {
+	reportData := []model.ContributorInsightsResult{
+		{
+			Context: &model.ScrapeContext{
+				Region:    "us-east-1",
+				AccountID: "12345",
+			},
+			Data: []*model.ContributorInsightsReport{
+				{
+					RuleName:             "top-talkers",
+					KeyLabels:            []string{"srcAddr", "$.dstPort"},
+					AggregationStatistic: "Sum",
+					AggregateValue:       300,
+					UniqueContributors:   42,
+					Contributors: []model.ContributorInsightsContributor{
+						{Keys: []string{"10.0.0.1", "443"}, Value: 200},
+						{Keys: []string{"10.0.0.2"}, Value: 100},
+					},
+				},
+			},
+		},
+	}
+
+	metrics, labels := BuildContributorInsightsMetrics(reportData, []*PrometheusMetric{}, map[string]model.LabelSet{}, true, logging.NewNopLogger())
+	require.Equal(t, []*PrometheusMetric{
+		{
+			Name: aws.String("aws_contributor_insights_unique_contributors"),
+			Labels: map[string]string{
+				"account_id": "12345",
+				"region":     "us-east-1",
+				"rule_name":  "top-talkers",
+			},
+			Value: aws.Float64(42),
+		},
+		{
+			Name: aws.String("aws_contributor_insights_aggregate_value"),
+			Labels: map[string]string{
+				"account_id": "12345",
+				"region":     "us-east-1",
+				"rule_name":  "top-talkers",
+				"statistic":  "Sum",
+			},
+			Value: aws.Float64(300),
+		},
+		{
+			Name: aws.String("aws_contributor_insights_contributor_value"),
+			Labels: map[string]string{
+				"account_id":   "12345",
+				"region":       "us-east-1",
+				"rule_name":    "top-talkers",
+				"statistic":    "Sum",
+				"key_src_addr": "10.0.0.1",
+				"key_dst_port": "443",
+			},
+			Value: aws.Float64(200),
+		},
+	}, metrics)
+	require.Equal(t, map[string]model.LabelSet{
+		"aws_contributor_insights_unique_contributors": {"account_id": {}, "region": {}, "rule_name": {}},
+		"aws_contributor_insights_aggregate_value":     {"account_id": {}, "region": {}, "rule_name": {}, "statistic": {}},
+		"aws_contributor_insights_contributor_value":   {"account_id": {}, "region": {}, "rule_name": {}, "statistic": {}, "key_src_addr": {}, "key_dst_port": {}},
+	}, labels)
+}
+
 func TestBuildMetrics(t *testing.T) {
 	ts := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
 
@@ -631,6 +1012,261 @@ func TestBuildMetrics(t *testing.T) {
 			},
 			expectedErr: nil,
 		},
+		{
+			name: "statistics as label",
+			data: []model.CloudwatchMetricResult{{
+				Context: &model.ScrapeContext{
+					Region:    "us-east-1",
+					AccountID: "123456789012",
+				},
+				Data: []*model.CloudwatchData{
+					{
+						Metric:                  aws.String("TargetResponseTime"),
+						Namespace:               aws.String("AWS/ApplicationELB"),
+						Statistics:              []string{"p50"},
+						NilToZero:               aws.Bool(false),
+						GetMetricDataPoint:      aws.Float64(0.1),
+						GetMetricDataTimestamps: ts,
+						ID:                      aws.String("arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/app/my-alb/0123456789abcdef"),
+						StatLabelMode:           model.StatLabelModeLabel,
+					},
+					{
+						Metric:                  aws.String("TargetResponseTime"),
+						Namespace:               aws.String("AWS/ApplicationELB"),
+						Statistics:              []string{"p99"},
+						NilToZero:               aws.Bool(false),
+						GetMetricDataPoint:      aws.Float64(0.5),
+						GetMetricDataTimestamps: ts,
+						ID:                      aws.String("arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/app/my-alb/0123456789abcdef"),
+						StatLabelMode:           model.StatLabelModeLabel,
+					},
+				},
+			}},
+			expectedMetrics: []*PrometheusMetric{
+				{
+					Name:      aws.String("aws_applicationelb_target_response_time"),
+					Value:     aws.Float64(0.1),
+					Timestamp: ts,
+					Labels: map[string]string{
+						"account_id": "123456789012",
+						"name":       "arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/app/my-alb/0123456789abcdef",
+						"region":     "us-east-1",
+						"stat":       "p50",
+					},
+				},
+				{
+					Name:      aws.String("aws_applicationelb_target_response_time"),
+					Value:     aws.Float64(0.5),
+					Timestamp: ts,
+					Labels: map[string]string{
+						"account_id": "123456789012",
+						"name":       "arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/app/my-alb/0123456789abcdef",
+						"region":     "us-east-1",
+						"stat":       "p99",
+					},
+				},
+			},
+			expectedLabels: map[string]model.LabelSet{
+				"aws_applicationelb_target_response_time": {
+					"account_id": {},
+					"name":       {},
+					"region":     {},
+					"stat":       {},
+				},
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "namespace alias",
+			data: []model.CloudwatchMetricResult{{
+				Context: &model.ScrapeContext{
+					Region:    "us-east-1",
+					AccountID: "123456789012",
+				},
+				Data: []*model.CloudwatchData{
+					{
+						Metric:                  aws.String("RequestCount"),
+						Namespace:               aws.String("AWS/ApplicationELB"),
+						Statistics:              []string{"Sum"},
+						NilToZero:               aws.Bool(false),
+						GetMetricDataPoint:      aws.Float64(42),
+						GetMetricDataTimestamps: ts,
+						ID:                      aws.String("arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/app/my-alb/0123456789abcdef"),
+					},
+				},
+				NamespaceAlias: "alb",
+			}},
+			expectedMetrics: []*PrometheusMetric{
+				{
+					Name:      aws.String("aws_alb_request_count_sum"),
+					Value:     aws.Float64(42),
+					Timestamp: ts,
+					Labels: map[string]string{
+						"account_id": "123456789012",
+						"name":       "arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/app/my-alb/0123456789abcdef",
+						"region":     "us-east-1",
+					},
+				},
+			},
+			expectedLabels: map[string]model.LabelSet{
+				"aws_alb_request_count_sum": {
+					"account_id": {},
+					"name":       {},
+					"region":     {},
+				},
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "histogram from percentile ranks",
+			data: []model.CloudwatchMetricResult{{
+				Context: &model.ScrapeContext{
+					Region:    "us-east-1",
+					AccountID: "123456789012",
+				},
+				Data: []*model.CloudwatchData{
+					{
+						Metric:                  aws.String("TargetResponseTime"),
+						Namespace:               aws.String("AWS/ApplicationELB"),
+						Statistics:              []string{"p99"},
+						NilToZero:               aws.Bool(false),
+						GetMetricDataPoint:      aws.Float64(0.8),
+						GetMetricDataTimestamps: ts,
+						ID:                      aws.String("arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/app/my-alb/0123456789abcdef"),
+						HistogramBuckets:        []float64{0.1, 0.5},
+					},
+					{
+						Metric:                  aws.String("TargetResponseTime"),
+						Namespace:               aws.String("AWS/ApplicationELB"),
+						Statistics:              []string{"SampleCount"},
+						NilToZero:               aws.Bool(false),
+						GetMetricDataPoint:      aws.Float64(200),
+						GetMetricDataTimestamps: ts,
+						ID:                      aws.String("arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/app/my-alb/0123456789abcdef"),
+						HistogramBuckets:        []float64{0.1, 0.5},
+					},
+					{
+						Metric:                  aws.String("TargetResponseTime"),
+						Namespace:               aws.String("AWS/ApplicationELB"),
+						Statistics:              []string{"Sum"},
+						NilToZero:               aws.Bool(false),
+						GetMetricDataPoint:      aws.Float64(30),
+						GetMetricDataTimestamps: ts,
+						ID:                      aws.String("arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/app/my-alb/0123456789abcdef"),
+						HistogramBuckets:        []float64{0.1, 0.5},
+					},
+					{
+						Metric:                  aws.String("TargetResponseTime"),
+						Namespace:               aws.String("AWS/ApplicationELB"),
+						Statistics:              []string{"PR(:0.1)"},
+						NilToZero:               aws.Bool(false),
+						GetMetricDataPoint:      aws.Float64(50),
+						GetMetricDataTimestamps: ts,
+						ID:                      aws.String("arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/app/my-alb/0123456789abcdef"),
+						HistogramBuckets:        []float64{0.1, 0.5},
+					},
+					{
+						Metric:                  aws.String("TargetResponseTime"),
+						Namespace:               aws.String("AWS/ApplicationELB"),
+						Statistics:              []string{"PR(:0.5)"},
+						NilToZero:               aws.Bool(false),
+						GetMetricDataPoint:      aws.Float64(90),
+						GetMetricDataTimestamps: ts,
+						ID:                      aws.String("arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/app/my-alb/0123456789abcdef"),
+						HistogramBuckets:        []float64{0.1, 0.5},
+					},
+				},
+			}},
+			expectedMetrics: []*PrometheusMetric{
+				{
+					Name:      aws.String("aws_applicationelb_target_response_time_p99"),
+					Value:     aws.Float64(0.8),
+					Timestamp: ts,
+					Labels: map[string]string{
+						"account_id": "123456789012",
+						"name":       "arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/app/my-alb/0123456789abcdef",
+						"region":     "us-east-1",
+					},
+				},
+				{
+					Name:      aws.String("aws_applicationelb_target_response_time_bucket"),
+					Value:     aws.Float64(100),
+					Timestamp: ts,
+					Labels: map[string]string{
+						"account_id": "123456789012",
+						"le":         "0.1",
+						"name":       "arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/app/my-alb/0123456789abcdef",
+						"region":     "us-east-1",
+					},
+				},
+				{
+					Name:      aws.String("aws_applicationelb_target_response_time_bucket"),
+					Value:     aws.Float64(180),
+					Timestamp: ts,
+					Labels: map[string]string{
+						"account_id": "123456789012",
+						"le":         "0.5",
+						"name":       "arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/app/my-alb/0123456789abcdef",
+						"region":     "us-east-1",
+					},
+				},
+				{
+					Name:      aws.String("aws_applicationelb_target_response_time_bucket"),
+					Value:     aws.Float64(200),
+					Timestamp: ts,
+					Labels: map[string]string{
+						"account_id": "123456789012",
+						"le":         "+Inf",
+						"name":       "arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/app/my-alb/0123456789abcdef",
+						"region":     "us-east-1",
+					},
+				},
+				{
+					Name:      aws.String("aws_applicationelb_target_response_time_sum"),
+					Value:     aws.Float64(30),
+					Timestamp: ts,
+					Labels: map[string]string{
+						"account_id": "123456789012",
+						"name":       "arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/app/my-alb/0123456789abcdef",
+						"region":     "us-east-1",
+					},
+				},
+				{
+					Name:      aws.String("aws_applicationelb_target_response_time_count"),
+					Value:     aws.Float64(200),
+					Timestamp: ts,
+					Labels: map[string]string{
+						"account_id": "123456789012",
+						"name":       "arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/app/my-alb/0123456789abcdef",
+						"region":     "us-east-1",
+					},
+				},
+			},
+			expectedLabels: map[string]model.LabelSet{
+				"aws_applicationelb_target_response_time_p99": {
+					"account_id": {},
+					"name":       {},
+					"region":     {},
+				},
+				"aws_applicationelb_target_response_time_bucket": {
+					"account_id": {},
+					"le":         {},
+					"name":       {},
+					"region":     {},
+				},
+				"aws_applicationelb_target_response_time_sum": {
+					"account_id": {},
+					"name":       {},
+					"region":     {},
+				},
+				"aws_applicationelb_target_response_time_count": {
+					"account_id": {},
+					"name":       {},
+					"region":     {},
+				},
+			},
+			expectedErr: nil,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -659,6 +1295,27 @@ func replaceNaNValues(metrics []*PrometheusMetric) []*PrometheusMetric {
 	return metrics
 }
 
+func TestGetDatapointWeightedAverage(t *testing.T) {
+	older := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(5 * time.Minute)
+	cwd := &model.CloudwatchData{
+		Metric: aws.String("TargetResponseTime"),
+		Points: []*model.Datapoint{
+			{Timestamp: &older, Average: aws.Float64(1), Sum: aws.Float64(1), SampleCount: aws.Float64(1)},
+			{Timestamp: &newer, Average: aws.Float64(0.1), Sum: aws.Float64(9.9), SampleCount: aws.Float64(99)},
+		},
+	}
+
+	average, _, err := getDatapoint(cwd, "Average")
+	require.NoError(t, err)
+	require.InDelta(t, 0.55, *average, 1e-9)
+
+	weighted, timestamp, err := getDatapoint(cwd, model.StatisticWeightedAverage)
+	require.NoError(t, err)
+	require.InDelta(t, 0.109, *weighted, 1e-9)
+	require.Equal(t, newer, timestamp)
+}
+
 // TestSortByTimeStamp validates that sortByTimestamp() sorts in descending order.
 func TestSortByTimeStamp(t *testing.T) {
 	ts := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
@@ -0,0 +1,58 @@
+package promutil
+
+import (
+	"fmt"
+	"plugin"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+// Processor transforms the metrics of a job: it may rename, drop or derive
+// metrics. Go plugins export it as a func, under the symbol of their configuration.
+type Processor func(metrics []*PrometheusMetric) []*PrometheusMetric
+
+// loadProcessor is a variable to allow stubbing the plugins in tests.
+var loadProcessor = loadPluginProcessor
+
+// loadPluginProcessor opens a Go plugin, which must be built with the same Go
+// version and YACE module version as the exporter. Plugins are only opened once.
+func loadPluginProcessor(cfg model.ProcessorConfig) (Processor, error) {
+	p, err := plugin.Open(cfg.Plugin)
+	if err != nil {
+		return nil, err
+	}
+	symbol, err := p.Lookup(cfg.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	process, ok := symbol.(func([]*PrometheusMetric) []*PrometheusMetric)
+	if !ok {
+		return nil, fmt.Errorf("symbol %s of plugin %s is a %T, not a processor func", cfg.Symbol, cfg.Plugin, symbol)
+	}
+	return process, nil
+}
+
+// applyProcessors transforms the metrics with the processors, in order. A processor failing
+// to load, or panicking, is skipped: the metrics are passed unchanged to the next one.
+func applyProcessors(processors []model.ProcessorConfig, metrics []*PrometheusMetric, logger logging.Logger) []*PrometheusMetric {
+	for _, cfg := range processors {
+		process, err := loadProcessor(cfg)
+		if err != nil {
+			logger.Error(err, "Failed to load processor", "plugin", cfg.Plugin)
+			continue
+		}
+		metrics = runProcessor(process, metrics, cfg, logger)
+	}
+	return metrics
+}
+
+func runProcessor(process Processor, metrics []*PrometheusMetric, cfg model.ProcessorConfig, logger logging.Logger) (out []*PrometheusMetric) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error(fmt.Errorf("%v", r), "Processor panicked", "plugin", cfg.Plugin)
+			out = metrics
+		}
+	}()
+	return process(metrics)
+}
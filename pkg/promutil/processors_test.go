@@ -0,0 +1,88 @@
+package promutil
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+func TestApplyProcessors(t *testing.T) {
+	processors := map[string]Processor{
+		"rename.so": func(metrics []*PrometheusMetric) []*PrometheusMetric {
+			for _, metric := range metrics {
+				metric.Name = aws.String("renamed_" + *metric.Name)
+			}
+			return metrics
+		},
+		"drop.so": func(metrics []*PrometheusMetric) []*PrometheusMetric {
+			return metrics[:0]
+		},
+		"panic.so": func(_ []*PrometheusMetric) []*PrometheusMetric {
+			panic("boom")
+		},
+	}
+	loadProcessor = func(cfg model.ProcessorConfig) (Processor, error) {
+		if process, ok := processors[cfg.Plugin]; ok {
+			return process, nil
+		}
+		return nil, errors.New("plugin not found")
+	}
+	t.Cleanup(func() { loadProcessor = loadPluginProcessor })
+
+	for _, tc := range []struct {
+		name          string
+		processors    []model.ProcessorConfig
+		expectedNames []string
+	}{
+		{
+			name:          "no processors",
+			expectedNames: []string{"aws_sqs_number_of_messages_sent_sum"},
+		},
+		{
+			name:          "rename",
+			processors:    []model.ProcessorConfig{{Plugin: "rename.so", Symbol: "Process"}},
+			expectedNames: []string{"renamed_aws_sqs_number_of_messages_sent_sum"},
+		},
+		{
+			name:          "drop",
+			processors:    []model.ProcessorConfig{{Plugin: "rename.so", Symbol: "Process"}, {Plugin: "drop.so", Symbol: "Process"}},
+			expectedNames: []string{},
+		},
+		{
+			name:          "failing processors are skipped",
+			processors:    []model.ProcessorConfig{{Plugin: "missing.so", Symbol: "Process"}, {Plugin: "panic.so", Symbol: "Process"}},
+			expectedNames: []string{"aws_sqs_number_of_messages_sent_sum"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			data := []model.CloudwatchMetricResult{{
+				Context: &model.ScrapeContext{Region: "us-east-1", AccountID: "123456789012"},
+				Data: []*model.CloudwatchData{{
+					Metric:                  aws.String("NumberOfMessagesSent"),
+					Namespace:               aws.String("AWS/SQS"),
+					Statistics:              []string{"Sum"},
+					Dimensions:              []*model.Dimension{{Name: "QueueName", Value: "queue"}},
+					GetMetricDataPoint:      aws.Float64(1),
+					GetMetricDataTimestamps: time.Now(),
+					ID:                      aws.String("arn:aws:sqs:us-east-1:123456789012:queue"),
+				}},
+				Processors: tc.processors,
+			}}
+
+			metrics, _, err := BuildMetrics(data, false, logging.NewNopLogger())
+			require.NoError(t, err)
+
+			names := make([]string, 0, len(metrics))
+			for _, metric := range metrics {
+				names = append(names, *metric.Name)
+			}
+			require.Equal(t, tc.expectedNames, names)
+		})
+	}
+}
@@ -1,12 +1,20 @@
 package promutil
 
 import (
+	"fmt"
+	"slices"
+	"sort"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/grafana/regexp"
 	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
 	"github.com/prometheus/common/model"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
 )
 
 var (
@@ -68,12 +76,121 @@ var (
 		Name: "yace_cloudwatch_dmsapi_requests_total",
 		Help: "Help is not implemented yet.",
 	})
+	RdsAPICounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "yace_cloudwatch_rdsapi_requests_total",
+		Help: "Help is not implemented yet.",
+	})
+	EcsAPICounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "yace_cloudwatch_ecsapi_requests_total",
+		Help: "Help is not implemented yet.",
+	})
+	ElastiCacheAPICounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "yace_cloudwatch_elasticacheapi_requests_total",
+		Help: "Help is not implemented yet.",
+	})
+	SqsAPICounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "yace_cloudwatch_sqsapi_requests_total",
+		Help: "Help is not implemented yet.",
+	})
+	DynamoDBAPICounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "yace_cloudwatch_dynamodbapi_requests_total",
+		Help: "Help is not implemented yet.",
+	})
+	CostExplorerAPICounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "yace_cloudwatch_costexplorerapi_requests_total",
+		Help: "Help is not implemented yet.",
+	})
+	ServiceQuotasAPICounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "yace_cloudwatch_servicequotasapi_requests_total",
+		Help: "Help is not implemented yet.",
+	})
+	SupportAPICounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "yace_cloudwatch_supportapi_requests_total",
+		Help: "Help is not implemented yet.",
+	})
+	LogsAPICounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "yace_cloudwatch_logsapi_requests_total",
+		Help: "Help is not implemented yet.",
+	})
+	CloudwatchGetInsightRuleReportAPICounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "yace_cloudwatch_getinsightrulereport_requests_total",
+		Help: "Help is not implemented yet.",
+	})
+	SyntheticsAPICounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "yace_cloudwatch_syntheticsapi_requests_total",
+		Help: "Help is not implemented yet.",
+	})
+	S3APICounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "yace_cloudwatch_s3api_requests_total",
+		Help: "Help is not implemented yet.",
+	})
+	KafkaAPICounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "yace_cloudwatch_kafkaapi_requests_total",
+		Help: "Help is not implemented yet.",
+	})
+	RedshiftServerlessAPICounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "yace_cloudwatch_redshiftserverlessapi_requests_total",
+		Help: "Help is not implemented yet.",
+	})
 	DuplicateMetricsFilteredCounter = prometheus.NewCounter(prometheus.CounterOpts{
 		Name: "yace_cloudwatch_duplicate_metrics_filtered",
 		Help: "Help is not implemented yet.",
 	})
+	StaleResourcesAgeGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "yace_cloudwatch_stale_resources_age_seconds",
+		Help: "Age of the cached resources served because their discovery failed.",
+	}, []string{"region", "namespace"})
+	APIRetriesCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "yace_cloudwatch_api_retries_total",
+		Help: "Number of retried requests to the AWS APIs, by operation, account and reason: throttled or error.",
+	}, []string{"api", "operation", "account_id", "reason"})
+	BudgetDelayedCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "yace_cloudwatch_budget_delayed_requests_total",
+		Help: "Number of requests to the CloudWatch API delayed as the shared budget of their account and region was spent.",
+	}, []string{"api"})
+	BudgetErrorsCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "yace_cloudwatch_budget_errors_total",
+		Help: "Number of requests to the CloudWatch API let through as the shared budget could not be checked.",
+	})
+	ResourcesAddedCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "yace_resources_added_total",
+		Help: "Number of resources found by the discovery of a job which its previous discovery did not find.",
+	}, []string{"job", "namespace", "account_id", "region"})
+	ResourcesRemovedCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "yace_resources_removed_total",
+		Help: "Number of resources found by the previous discovery of a job which its last discovery did not find.",
+	}, []string{"job", "namespace", "account_id", "region"})
+	ResourcesDiscoveredGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "yace_resources_discovered",
+		Help: "Number of resources found by the last discovery of a job.",
+	}, []string{"job", "namespace", "account_id", "region"})
+	AccountUpGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "yace_account_up",
+		Help: "Whether the last scrape of the account and region succeeded.",
+	}, []string{"account_id", "region"})
+	AccountLastSuccessGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "yace_account_last_success_timestamp_seconds",
+		Help: "Timestamp of the last successful scrape of the account and region.",
+	}, []string{"account_id", "region"})
+	AccountLastErrorGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "yace_account_last_error_timestamp_seconds",
+		Help: "Timestamp of the last failed scrape of the account and region, with the failing step and the AWS error code.",
+	}, []string{"account_id", "region", "reason", "code"})
 )
 
+// ObserveAccountScrape sets the health metrics of the account and region after a scrape.
+// An empty reason is a success. The last error is kept until another error replaces it.
+func ObserveAccountScrape(accountID, region, reason, code string, timestamp time.Time) {
+	if reason == "" {
+		AccountUpGauge.WithLabelValues(accountID, region).Set(1)
+		AccountLastSuccessGauge.WithLabelValues(accountID, region).Set(float64(timestamp.Unix()))
+		return
+	}
+	AccountUpGauge.WithLabelValues(accountID, region).Set(0)
+	AccountLastErrorGauge.DeletePartialMatch(prometheus.Labels{"account_id": accountID, "region": region})
+	AccountLastErrorGauge.WithLabelValues(accountID, region, reason, code).Set(float64(timestamp.Unix()))
+}
+
 var replacer = strings.NewReplacer(
 	" ", "_",
 	",", "_",
@@ -104,12 +221,24 @@ type PrometheusCollector struct {
 	metrics []*PrometheusMetric
 }
 
+// NewPrometheusCollector sorts a copy of the metrics by name, for Encode
+// to write the metrics of a family together. The metrics slice of the
+// caller is left as is.
 func NewPrometheusCollector(metrics []*PrometheusMetric) *PrometheusCollector {
+	metrics = slices.Clone(metrics)
+	sort.SliceStable(metrics, func(i, j int) bool {
+		return *metrics[i].Name < *metrics[j].Name
+	})
 	return &PrometheusCollector{
 		metrics: metrics,
 	}
 }
 
+// Metrics returns the metrics of the collector, sorted by name.
+func (p *PrometheusCollector) Metrics() []*PrometheusMetric {
+	return p.metrics
+}
+
 func (p *PrometheusCollector) Describe(_ chan<- *prometheus.Desc) {
 	// The exporter produces a dynamic set of metrics and the docs for prometheus.Collector Describe say
 	// 	Sending no descriptor at all marks the Collector as “unchecked”,
@@ -124,10 +253,98 @@ func (p *PrometheusCollector) Collect(metrics chan<- prometheus.Metric) {
 	}
 }
 
+// Encode writes the metrics with enc one family at a time, instead of gathering
+// all of them in memory first as a prometheus.Registry does. The metrics are
+// checked as the registry would: the families with an invalid name or one of
+// the reserved names, and the metrics with an invalid label or already written
+// with the same labels and timestamp, are logged and skipped.
+func (p *PrometheusCollector) Encode(enc expfmt.Encoder, reserved map[string]bool, logger logging.Logger) error {
+	help := metricHelp
+	for start := 0; start < len(p.metrics); {
+		name := *p.metrics[start].Name
+		end := start + 1
+		for end < len(p.metrics) && *p.metrics[end].Name == name {
+			end++
+		}
+		metrics := p.metrics[start:end]
+		start = end
+
+		if !model.IsValidMetricName(model.LabelValue(name)) {
+			logger.Warn("metric name is an invalid prometheus metric name", "metric", name)
+			continue
+		}
+		if reserved[name] {
+			logger.Warn("metric name collides with an exporter metric, skipping it", "metric", name)
+			continue
+		}
+
+		family := &dto.MetricFamily{
+			Name:   &name,
+			Help:   &help,
+			Type:   dto.MetricType_GAUGE.Enum(),
+			Metric: make([]*dto.Metric, 0, len(metrics)),
+		}
+		written := make(map[string]struct{}, len(metrics))
+		for _, metric := range metrics {
+			if label, ok := invalidLabel(metric.Labels); ok {
+				logger.Warn("label is invalid, skipping the metric", "metric", name, "label", label)
+				continue
+			}
+			key := fmt.Sprintf("%d-%d", model.LabelsToSignature(metric.Labels), metric.Timestamp.UnixMilli())
+			if _, ok := written[key]; ok {
+				DuplicateMetricsFilteredCounter.Inc()
+				continue
+			}
+			written[key] = struct{}{}
+			family.Metric = append(family.Metric, metricToDTO(metric))
+		}
+		if len(family.Metric) == 0 {
+			continue
+		}
+		if err := enc.Encode(family); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// invalidLabel returns the first label with a name or a value the registry
+// would reject.
+func invalidLabel(labels map[string]string) (string, bool) {
+	for name, value := range labels {
+		if !model.LabelName(name).IsValid() || strings.HasPrefix(name, model.ReservedLabelPrefix) || !utf8.ValidString(value) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func metricToDTO(metric *PrometheusMetric) *dto.Metric {
+	labels := make([]*dto.LabelPair, 0, len(metric.Labels))
+	for name, value := range metric.Labels {
+		labels = append(labels, &dto.LabelPair{Name: &name, Value: &value})
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		return *labels[i].Name < *labels[j].Name
+	})
+
+	out := &dto.Metric{
+		Label: labels,
+		Gauge: &dto.Gauge{Value: metric.Value},
+	}
+	if metric.IncludeTimestamp {
+		timestampMs := metric.Timestamp.UnixMilli()
+		out.TimestampMs = &timestampMs
+	}
+	return out
+}
+
+const metricHelp = "Help is not implemented yet."
+
 func createMetric(metric *PrometheusMetric) prometheus.Metric {
 	gauge := prometheus.NewGauge(prometheus.GaugeOpts{
 		Name:        *metric.Name,
-		Help:        "Help is not implemented yet.",
+		Help:        metricHelp,
 		ConstLabels: metric.Labels,
 	})
 
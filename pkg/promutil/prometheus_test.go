@@ -1,9 +1,17 @@
 package promutil
 
 import (
+	"bytes"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
 )
 
 func TestSplitString(t *testing.T) {
@@ -116,3 +124,95 @@ func TestPromStringTag(t *testing.T) {
 		})
 	}
 }
+
+func TestPrometheusCollectorEncode(t *testing.T) {
+	ts := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	collector := NewPrometheusCollector([]*PrometheusMetric{
+		{
+			Name:   aws.String("aws_sqs_number_of_messages_sent_sum"),
+			Labels: map[string]string{"name": "arn:aws:sqs:eu-west-1:123456789012:orders", "region": "eu-west-1"},
+			Value:  aws.Float64(1),
+		},
+		{
+			Name:             aws.String("aws_ec2_cpuutilization_average"),
+			Labels:           map[string]string{"name": "arn:aws:ec2:eu-west-1:123456789012:instance/i-1", "region": "eu-west-1"},
+			Value:            aws.Float64(2.5),
+			IncludeTimestamp: true,
+			Timestamp:        ts,
+		},
+		{
+			Name:   aws.String("aws_sqs_number_of_messages_sent_sum"),
+			Labels: map[string]string{"name": "arn:aws:sqs:eu-west-1:123456789012:payments", "region": "eu-west-1"},
+			Value:  aws.Float64(3),
+		},
+	})
+
+	// Encoding the metrics directly must match encoding them gathered by a registry
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	expected := bytes.Buffer{}
+	enc := expfmt.NewEncoder(&expected, expfmt.FmtText)
+	for _, family := range families {
+		require.NoError(t, enc.Encode(family))
+	}
+
+	actual := bytes.Buffer{}
+	require.NoError(t, collector.Encode(expfmt.NewEncoder(&actual, expfmt.FmtText), nil, logging.NewNopLogger()))
+	require.Equal(t, expected.String(), actual.String())
+}
+
+func TestPrometheusCollectorEncodeSkipsInvalidMetrics(t *testing.T) {
+	collector := NewPrometheusCollector([]*PrometheusMetric{
+		{
+			Name:   aws.String("aws_sqs_number_of_messages_sent_sum"),
+			Labels: map[string]string{"name": "orders"},
+			Value:  aws.Float64(1),
+		},
+		{
+			Name:   aws.String("aws_sqs_number_of_messages_sent_sum"),
+			Labels: map[string]string{"name": "orders"},
+			Value:  aws.Float64(2),
+		},
+		{
+			Name:   aws.String("aws_sqs_number_of_messages_sent_sum"),
+			Labels: map[string]string{"invalid-label": "payments"},
+			Value:  aws.Float64(3),
+		},
+		{
+			Name:   aws.String("aws_sqs_number_of_messages_sent_sum"),
+			Labels: map[string]string{"__name": "payments"},
+			Value:  aws.Float64(4),
+		},
+		{
+			Name:   aws.String("invalid-metric"),
+			Labels: map[string]string{},
+			Value:  aws.Float64(5),
+		},
+		{
+			Name:   aws.String("yace_cloudwatch_requests_total"),
+			Labels: map[string]string{},
+			Value:  aws.Float64(6),
+		},
+	})
+
+	actual := bytes.Buffer{}
+	reserved := map[string]bool{"yace_cloudwatch_requests_total": true}
+	require.NoError(t, collector.Encode(expfmt.NewEncoder(&actual, expfmt.FmtText), reserved, logging.NewNopLogger()))
+	require.Equal(t, `# HELP aws_sqs_number_of_messages_sent_sum Help is not implemented yet.
+# TYPE aws_sqs_number_of_messages_sent_sum gauge
+aws_sqs_number_of_messages_sent_sum{name="orders"} 1
+`, actual.String())
+}
+
+func TestNewPrometheusCollectorKeepsMetricsOrder(t *testing.T) {
+	metrics := []*PrometheusMetric{
+		{Name: aws.String("aws_sqs_number_of_messages_sent_sum"), Value: aws.Float64(1)},
+		{Name: aws.String("aws_ec2_cpuutilization_average"), Value: aws.Float64(2)},
+	}
+
+	collector := NewPrometheusCollector(metrics)
+	require.Equal(t, "aws_ec2_cpuutilization_average", *collector.Metrics()[0].Name)
+	require.Equal(t, "aws_sqs_number_of_messages_sent_sum", *metrics[0].Name)
+}
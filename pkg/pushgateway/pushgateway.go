@@ -0,0 +1,78 @@
+// Package pushgateway pushes the metrics of a scrape to a Prometheus Pushgateway,
+// for the exporter to run in batch contexts where it cannot be scraped.
+package pushgateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/promutil"
+)
+
+// groupingLabels group the metrics by region and account, so that pushes of
+// jobs scraping different regions or accounts do not replace each other.
+var groupingLabels = []string{"region", "account_id"}
+
+type groupKey struct {
+	region    string
+	accountID string
+}
+
+// Push replaces the metrics of the job in the Pushgateway, one group per region and
+// account. The collectors, e.g. the exporter metrics, and the metrics without a region
+// nor an account are pushed to the group of the job itself.
+func Push(ctx context.Context, url string, job string, metrics []*promutil.PrometheusMetric, collectors ...prometheus.Collector) error {
+	groups := make(map[groupKey][]*promutil.PrometheusMetric)
+	for _, metric := range metrics {
+		key := groupKey{region: metric.Labels["region"], accountID: metric.Labels["account_id"]}
+		groups[key] = append(groups[key], withoutGroupingLabels(metric))
+	}
+
+	keys := make([]groupKey, 0, len(groups)+1)
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	if _, ok := groups[groupKey{}]; !ok {
+		keys = append(keys, groupKey{})
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].region != keys[j].region {
+			return keys[i].region < keys[j].region
+		}
+		return keys[i].accountID < keys[j].accountID
+	})
+
+	var errs []error
+	for _, key := range keys {
+		pusher := push.New(url, job).Collector(promutil.NewPrometheusCollector(groups[key]))
+		if key == (groupKey{}) {
+			for _, collector := range collectors {
+				pusher = pusher.Collector(collector)
+			}
+		} else {
+			pusher = pusher.Grouping("region", key.region).Grouping("account_id", key.accountID)
+		}
+		if err := pusher.PushContext(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to push group region=%q account_id=%q: %w", key.region, key.accountID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// withoutGroupingLabels returns a copy of the metric without the grouping
+// labels, which the Pushgateway adds back from the group.
+func withoutGroupingLabels(metric *promutil.PrometheusMetric) *promutil.PrometheusMetric {
+	labels := maps.Clone(metric.Labels)
+	for _, name := range groupingLabels {
+		delete(labels, name)
+	}
+	out := *metric
+	out.Labels = labels
+	return &out
+}
@@ -0,0 +1,102 @@
+package pushgateway
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/promutil"
+)
+
+func TestPush(t *testing.T) {
+	mux := sync.Mutex{}
+	pushed := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPut, r.Method)
+
+		// Convert the pushed metrics to the text format for the assertions
+		// The body is buffered once, as the decoder buffers it again for every family
+		dec := expfmt.NewDecoder(bufio.NewReader(r.Body), expfmt.ResponseFormat(r.Header))
+		text := bytes.Buffer{}
+		enc := expfmt.NewEncoder(&text, expfmt.FmtText)
+		for {
+			family := &dto.MetricFamily{}
+			if err := dec.Decode(family); err != nil {
+				require.ErrorIs(t, err, io.EOF)
+				break
+			}
+			require.NoError(t, enc.Encode(family))
+		}
+		mux.Lock()
+		pushed[groupOf(r.URL.Path)] = text.String()
+		mux.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "yace_cloudwatch_requests_total", Help: "Test counter"})
+	metrics := []*promutil.PrometheusMetric{
+		{
+			Name:   aws.String("aws_sqs_number_of_messages_sent_sum"),
+			Labels: map[string]string{"name": "orders", "region": "eu-west-1", "account_id": "123456789012"},
+			Value:  aws.Float64(1),
+		},
+		{
+			Name:   aws.String("aws_sqs_number_of_messages_sent_sum"),
+			Labels: map[string]string{"name": "payments", "region": "us-east-1", "account_id": "123456789012"},
+			Value:  aws.Float64(2),
+		},
+		{
+			Name:   aws.String("aws_sqs_info"),
+			Labels: map[string]string{"name": "orders"},
+			Value:  aws.Float64(0),
+		},
+	}
+
+	require.NoError(t, Push(context.Background(), server.URL, "yace", metrics, counter))
+
+	require.Len(t, pushed, 3)
+	require.Contains(t, pushed["account_id=123456789012,job=yace,region=eu-west-1"], `aws_sqs_number_of_messages_sent_sum{name="orders"} 1`)
+	require.Contains(t, pushed["account_id=123456789012,job=yace,region=us-east-1"], `aws_sqs_number_of_messages_sent_sum{name="payments"} 2`)
+	require.Contains(t, pushed["job=yace"], `aws_sqs_info{name="orders"} 0`)
+	require.Contains(t, pushed["job=yace"], "yace_cloudwatch_requests_total 0")
+
+	// The metrics keep their grouping labels
+	require.Equal(t, "eu-west-1", metrics[0].Labels["region"])
+}
+
+// groupOf returns the labels of the group of a push path, /metrics/job/<job>/<label>/<value>...,
+// sorted since the order of the grouping labels is not deterministic.
+func groupOf(path string) string {
+	parts := strings.Split(strings.TrimPrefix(path, "/metrics/"), "/")
+	labels := make([]string, 0, len(parts)/2)
+	for i := 0; i+1 < len(parts); i += 2 {
+		labels = append(labels, parts[i]+"="+parts[i+1])
+	}
+	sort.Strings(labels)
+	return strings.Join(labels, ",")
+}
+
+func TestPushError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	err := Push(context.Background(), server.URL, "yace", nil)
+	require.Error(t, err)
+	require.True(t, strings.Contains(err.Error(), "unexpected status code 400"))
+}
@@ -0,0 +1,235 @@
+// Package sns receives the messages of SNS topics on an HTTP endpoint subscribed to
+// them, confirming the subscriptions and checking the signatures of the messages.
+package sns
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	_ "crypto/sha1" // Hash of the signatures of SignatureVersion 1
+	_ "crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/regexp"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+)
+
+// snsHostRegexp matches the hosts of the SNS endpoints serving the signing certificates
+// and the subscription confirmations, e.g. sns.eu-west-1.amazonaws.com.
+var snsHostRegexp = regexp.MustCompile(`^sns\.[a-z0-9-]+\.amazonaws\.com(\.cn)?$`)
+
+// Message is the body of the requests of SNS to the HTTP endpoints subscribed to a topic.
+type Message struct {
+	Type             string `json:"Type"`
+	MessageID        string `json:"MessageId"`
+	Token            string `json:"Token"`
+	TopicArn         string `json:"TopicArn"`
+	Subject          string `json:"Subject"`
+	Message          string `json:"Message"`
+	Timestamp        string `json:"Timestamp"`
+	SignatureVersion string `json:"SignatureVersion"`
+	Signature        string `json:"Signature"`
+	SigningCertURL   string `json:"SigningCertURL"`
+	SubscribeURL     string `json:"SubscribeURL"`
+}
+
+// NotifyFunc handles a notification of a topic. The notifications failing with an
+// error are answered with a server error, for SNS to retry their delivery.
+type NotifyFunc func(ctx context.Context, msg Message) error
+
+// handler receives the messages published to the SNS topics.
+type handler struct {
+	logger    logging.Logger
+	topicARNs []string
+	notify    NotifyFunc
+	client    *http.Client
+
+	mu           sync.Mutex
+	certificates map[string]*x509.Certificate
+}
+
+// NewHandler returns the HTTP endpoint subscribed to the SNS topics of the ARNs,
+// calling notify with their notifications. The subscriptions are confirmed, and the
+// messages of other topics or without a valid SNS signature are rejected.
+func NewHandler(logger logging.Logger, topicARNs []string, notify NotifyFunc) http.Handler {
+	return &handler{
+		logger:       logger,
+		topicARNs:    topicARNs,
+		notify:       notify,
+		client:       &http.Client{Timeout: 10 * time.Second},
+		certificates: make(map[string]*x509.Certificate),
+	}
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var msg Message
+	if err := json.NewDecoder(io.LimitReader(r.Body, 256*1024)).Decode(&msg); err != nil {
+		http.Error(w, "invalid SNS message: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !slices.Contains(h.topicARNs, msg.TopicArn) {
+		http.Error(w, "unknown topic", http.StatusForbidden)
+		return
+	}
+	if err := h.verify(r.Context(), msg); err != nil {
+		h.logger.Warn("Rejecting SNS message with an invalid signature", "topic_arn", msg.TopicArn, "err", err)
+		http.Error(w, "invalid signature", http.StatusForbidden)
+		return
+	}
+
+	switch msg.Type {
+	case "SubscriptionConfirmation":
+		if err := h.confirm(r.Context(), msg.SubscribeURL); err != nil {
+			h.logger.Error(err, "Failed to confirm the SNS subscription", "topic_arn", msg.TopicArn)
+			http.Error(w, "failed to confirm the subscription", http.StatusBadGateway)
+			return
+		}
+		h.logger.Info("Confirmed the SNS subscription", "topic_arn", msg.TopicArn)
+	case "Notification":
+		// SNS retries the deliveries failing with a server error
+		if err := h.notify(r.Context(), msg); err != nil {
+			h.logger.Error(err, "Failed to handle the SNS notification", "topic_arn", msg.TopicArn, "message_id", msg.MessageID)
+			http.Error(w, "failed to handle the notification", http.StatusBadGateway)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// verify checks the signature of the message with the signing certificate of SNS.
+func (h *handler) verify(ctx context.Context, msg Message) error {
+	var hash crypto.Hash
+	switch msg.SignatureVersion {
+	case "1":
+		hash = crypto.SHA1
+	case "2":
+		hash = crypto.SHA256
+	default:
+		return fmt.Errorf("unsupported signature version %q", msg.SignatureVersion)
+	}
+	signature, err := base64.StdEncoding.DecodeString(msg.Signature)
+	if err != nil {
+		return err
+	}
+	cert, err := h.certificate(ctx, msg.SigningCertURL)
+	if err != nil {
+		return err
+	}
+	key, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return errors.New("the signing certificate has no RSA public key")
+	}
+
+	digest := hash.New()
+	digest.Write([]byte(stringToSign(msg)))
+	return rsa.VerifyPKCS1v15(key, hash, digest.Sum(nil), signature)
+}
+
+// stringToSign returns the fields of the message signed by SNS, in order.
+func stringToSign(msg Message) string {
+	fields := [][2]string{{"Message", msg.Message}, {"MessageId", msg.MessageID}}
+	if msg.Type == "Notification" {
+		if msg.Subject != "" {
+			fields = append(fields, [2]string{"Subject", msg.Subject})
+		}
+		fields = append(fields, [2]string{"Timestamp", msg.Timestamp})
+	} else {
+		fields = append(fields, [2]string{"SubscribeURL", msg.SubscribeURL}, [2]string{"Timestamp", msg.Timestamp}, [2]string{"Token", msg.Token})
+	}
+	fields = append(fields, [2]string{"TopicArn", msg.TopicArn}, [2]string{"Type", msg.Type})
+
+	var sb strings.Builder
+	for _, field := range fields {
+		sb.WriteString(field[0] + "\n" + field[1] + "\n")
+	}
+	return sb.String()
+}
+
+// certificate returns the signing certificate of the URL, cached once fetched. The
+// fetch is done without holding the lock, so concurrent messages signed with
+// a new certificate may fetch it more than once.
+func (h *handler) certificate(ctx context.Context, certURL string) (*x509.Certificate, error) {
+	h.mu.Lock()
+	cert, ok := h.certificates[certURL]
+	h.mu.Unlock()
+	if ok {
+		return cert, nil
+	}
+	if err := checkSNSURL(certURL); err != nil {
+		return nil, err
+	}
+	body, err := h.get(ctx, certURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the signing certificate: %w", err)
+	}
+	block, _ := pem.Decode(body)
+	if block == nil {
+		return nil, errors.New("the signing certificate is not PEM encoded")
+	}
+	cert, err = x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.certificates[certURL] = cert
+	return cert, nil
+}
+
+// confirm confirms the subscription to the topic by visiting the SubscribeURL.
+func (h *handler) confirm(ctx context.Context, subscribeURL string) error {
+	if err := checkSNSURL(subscribeURL); err != nil {
+		return err
+	}
+	_, err := h.get(ctx, subscribeURL)
+	return err
+}
+
+func (h *handler) get(ctx context.Context, rawURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return body, nil
+}
+
+// checkSNSURL checks that the URL is an HTTPS URL of SNS, not to request other hosts.
+func checkSNSURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "https" || !snsHostRegexp.MatchString(u.Host) {
+		return fmt.Errorf("%s is not an SNS URL", rawURL)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package sns
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+)
+
+const (
+	topicArn = "arn:aws:sns:eu-west-1:123456789012:alarms"
+	certURL  = "https://sns.eu-west-1.amazonaws.com/SimpleNotificationService-0123456789.pem"
+)
+
+func TestHandler(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "sns.amazonaws.com"}, NotAfter: time.Now().Add(time.Hour)}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	var notified []string
+	h := NewHandler(logging.NewNopLogger(), []string{topicArn}, func(_ context.Context, msg Message) error {
+		if msg.Message == "fail" {
+			return errors.New("failed")
+		}
+		notified = append(notified, msg.Message)
+		return nil
+	}).(*handler)
+	h.certificates[certURL] = cert
+
+	notification := func(topic string, message string) Message {
+		msg := Message{
+			Type:             "Notification",
+			MessageID:        "5a0b9a0c-0000-0000-0000-000000000000",
+			TopicArn:         topic,
+			Subject:          `ALARM: "queue-backlog" in EU (Ireland)`,
+			Message:          message,
+			Timestamp:        "2024-01-01T10:00:01.000Z",
+			SignatureVersion: "2",
+			SigningCertURL:   certURL,
+		}
+		digest := sha256.Sum256([]byte(stringToSign(msg)))
+		signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+		msg.Signature = base64.StdEncoding.EncodeToString(signature)
+		return msg
+	}
+	post := func(msg Message) int {
+		body, err := json.Marshal(msg)
+		require.NoError(t, err)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/sns", strings.NewReader(string(body))))
+		return rec.Code
+	}
+
+	require.Equal(t, http.StatusOK, post(notification(topicArn, `{"AlarmName":"queue-backlog"}`)))
+	require.Equal(t, http.StatusBadGateway, post(notification(topicArn, "fail")))
+	require.Equal(t, http.StatusForbidden, post(notification("arn:aws:sns:eu-west-1:123456789012:other", "other")))
+	tampered := notification(topicArn, `{"NewStateValue":"OK"}`)
+	tampered.Message = `{"NewStateValue":"ALARM"}`
+	require.Equal(t, http.StatusForbidden, post(tampered))
+	require.Equal(t, []string{`{"AlarmName":"queue-backlog"}`}, notified)
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestCertificateFetchedWithoutLock(t *testing.T) {
+	fetching := make(chan struct{})
+	h := NewHandler(logging.NewNopLogger(), []string{topicArn}, nil).(*handler)
+	h.client = &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		close(fetching)
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	})}
+	cached := &x509.Certificate{}
+	h.certificates[certURL] = cached
+
+	ctx, cancel := context.WithCancel(context.Background())
+	fetched := make(chan error)
+	go func() {
+		_, err := h.certificate(ctx, "https://sns.eu-west-1.amazonaws.com/SimpleNotificationService-9876543210.pem")
+		fetched <- err
+	}()
+	<-fetching
+
+	// The cached certificate is served while the other one is being fetched
+	cert, err := h.certificate(context.Background(), certURL)
+	require.NoError(t, err)
+	require.Same(t, cached, cert)
+
+	cancel()
+	require.Error(t, <-fetched)
+}
+
+func TestCheckSNSURL(t *testing.T) {
+	require.NoError(t, checkSNSURL(certURL))
+	require.NoError(t, checkSNSURL("https://sns.cn-north-1.amazonaws.com.cn/?Action=ConfirmSubscription"))
+	require.Error(t, checkSNSURL("http://sns.eu-west-1.amazonaws.com/cert.pem"))
+	require.Error(t, checkSNSURL("https://sns.eu-west-1.amazonaws.com.example.com/cert.pem"))
+	require.Error(t, checkSNSURL("https://169.254.169.254/latest/meta-data"))
+}
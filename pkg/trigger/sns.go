@@ -0,0 +1,28 @@
+package trigger
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/sns"
+)
+
+// NewSNSHandler returns the HTTP endpoint subscribed to the SNS topics of the ARNs,
+// the targets of EventBridge rules, sending an Event to events for the namespaces of
+// the resources of each notified EventBridge event. A notification which cannot be
+// sent before SNS times out fails, for SNS to retry it.
+func NewSNSHandler(logger logging.Logger, topicARNs []string, events chan<- Event) http.Handler {
+	return sns.NewHandler(logger, topicARNs, func(ctx context.Context, msg sns.Message) error {
+		for _, event := range parseEvents(logger, []string{msg.Message}) {
+			logger.Debug("Received trigger", "region", event.Region, "namespaces", strings.Join(event.Namespaces, ","), "topic_arn", msg.TopicArn)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case events <- event:
+			}
+		}
+		return nil
+	})
+}
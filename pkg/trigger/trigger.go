@@ -0,0 +1,212 @@
+// Package trigger listens to an SQS queue fed with EventBridge events, e.g. an
+// instance launched or a deployment finished, and reports the namespaces whose
+// resources changed, for their discovery to be refreshed without waiting for
+// the next scrape.
+package trigger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+)
+
+const (
+	waitTimeSeconds     = 20
+	maxMessages         = 10
+	receiveErrorBackoff = 5 * time.Second
+)
+
+// Event reports that resources of the namespaces changed in the region.
+type Event struct {
+	Region     string
+	Namespaces []string
+}
+
+// eventBridgeEvent holds the fields of an EventBridge event used to find
+// the namespaces of the changed resources.
+type eventBridgeEvent struct {
+	Source    string   `json:"source"`
+	Region    string   `json:"region"`
+	Resources []string `json:"resources"`
+}
+
+// Listener receives the EventBridge events sent to an SQS queue.
+type Listener struct {
+	logger   logging.Logger
+	sqsAPI   sqsiface.SQSAPI
+	queueURL string
+}
+
+// NewListener returns a Listener of the queue, in the region of its URL,
+// e.g. https://sqs.eu-west-1.amazonaws.com/123456789012/yace-triggers.
+func NewListener(logger logging.Logger, queueURL string, fips bool) (*Listener, error) {
+	region, err := queueRegion(queueURL)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &aws.Config{Region: aws.String(region)}
+	if fips {
+		cfg.UseFIPSEndpoint = endpoints.FIPSEndpointStateEnabled
+	}
+	sess, err := session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+		Config:            *cfg,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the SQS session: %w", err)
+	}
+
+	return &Listener{
+		logger:   logger,
+		sqsAPI:   sqs.New(sess),
+		queueURL: queueURL,
+	}, nil
+}
+
+// queueRegion returns the region of an SQS queue URL, either
+// https://sqs.<region>.amazonaws.com/... or the legacy https://<region>.queue.amazonaws.com/...
+func queueRegion(queueURL string) (string, error) {
+	u, err := url.Parse(queueURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid SQS queue URL %q: %w", queueURL, err)
+	}
+	parts := strings.Split(u.Hostname(), ".")
+	switch {
+	case len(parts) > 2 && parts[0] == "sqs":
+		return parts[1], nil
+	case len(parts) > 2 && parts[1] == "queue":
+		return parts[0], nil
+	}
+	return "", fmt.Errorf("invalid SQS queue URL %q: cannot find the region in the host", queueURL)
+}
+
+// Run receives the messages of the queue until the context is done, sending an
+// Event per region to events for each batch of messages. Messages are deleted
+// once handled, including the ones which are not valid EventBridge events.
+func (l *Listener) Run(ctx context.Context, events chan<- Event) {
+	for ctx.Err() == nil {
+		out, err := l.sqsAPI.ReceiveMessageWithContext(ctx, &sqs.ReceiveMessageInput{
+			QueueUrl:            aws.String(l.queueURL),
+			MaxNumberOfMessages: aws.Int64(maxMessages),
+			WaitTimeSeconds:     aws.Int64(waitTimeSeconds),
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			l.logger.Error(err, "Failed to receive the trigger messages", "queue_url", l.queueURL)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(receiveErrorBackoff):
+			}
+			continue
+		}
+
+		bodies := make([]string, 0, len(out.Messages))
+		for _, message := range out.Messages {
+			bodies = append(bodies, aws.StringValue(message.Body))
+			if _, err := l.sqsAPI.DeleteMessageWithContext(ctx, &sqs.DeleteMessageInput{
+				QueueUrl:      aws.String(l.queueURL),
+				ReceiptHandle: message.ReceiptHandle,
+			}); err != nil {
+				l.logger.Warn("Failed to delete a trigger message", "queue_url", l.queueURL, "err", err)
+			}
+		}
+
+		for _, event := range parseEvents(l.logger, bodies) {
+			l.logger.Debug("Received trigger", "region", event.Region, "namespaces", strings.Join(event.Namespaces, ","))
+			select {
+			case <-ctx.Done():
+				return
+			case events <- event:
+			}
+		}
+	}
+}
+
+// parseEvents parses the messages as EventBridge events, and merges the
+// namespaces of their resources into one Event per region.
+func parseEvents(logger logging.Logger, bodies []string) []Event {
+	namespacesByRegion := map[string]map[string]struct{}{}
+	for _, body := range bodies {
+		var event eventBridgeEvent
+		if err := json.Unmarshal([]byte(body), &event); err != nil {
+			logger.Warn("Skipping a trigger message which is not an EventBridge event", "err", err)
+			continue
+		}
+		namespaces := eventNamespaces(event)
+		if len(namespaces) == 0 {
+			logger.Debug("Skipping an EventBridge event without supported resources", "source", event.Source)
+			continue
+		}
+		if _, ok := namespacesByRegion[event.Region]; !ok {
+			namespacesByRegion[event.Region] = map[string]struct{}{}
+		}
+		for _, namespace := range namespaces {
+			namespacesByRegion[event.Region][namespace] = struct{}{}
+		}
+	}
+
+	events := make([]Event, 0, len(namespacesByRegion))
+	for region, namespaces := range namespacesByRegion {
+		event := Event{Region: region, Namespaces: make([]string, 0, len(namespaces))}
+		for namespace := range namespaces {
+			event.Namespaces = append(event.Namespaces, namespace)
+		}
+		sort.Strings(event.Namespaces)
+		events = append(events, event)
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].Region < events[j].Region })
+	return events
+}
+
+// eventNamespaces returns the namespaces whose resource filters match the
+// resources of the event. Events without resources, e.g. from aws.codedeploy,
+// match all the namespaces of the service of their source.
+func eventNamespaces(event eventBridgeEvent) []string {
+	var namespaces []string
+	for _, svc := range config.SupportedServices {
+		if serviceMatches(svc, event) {
+			namespaces = append(namespaces, svc.Namespace)
+		}
+	}
+	return namespaces
+}
+
+func serviceMatches(svc config.ServiceConfig, event eventBridgeEvent) bool {
+	for _, filter := range svc.ResourceFilters {
+		filterService, filterResource, _ := strings.Cut(aws.StringValue(filter), ":")
+		if len(event.Resources) == 0 {
+			if event.Source == "aws."+filterService {
+				return true
+			}
+			continue
+		}
+		for _, resource := range event.Resources {
+			parsed, err := arn.Parse(resource)
+			if err != nil || parsed.Service != filterService {
+				continue
+			}
+			if filterResource == "" || parsed.Resource == filterResource || strings.HasPrefix(parsed.Resource, filterResource+"/") || strings.HasPrefix(parsed.Resource, filterResource+":") {
+				return true
+			}
+		}
+	}
+	return false
+}
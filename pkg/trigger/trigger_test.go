@@ -0,0 +1,93 @@
+package trigger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+)
+
+func TestQueueRegion(t *testing.T) {
+	for _, tc := range []struct {
+		queueURL string
+		region   string
+		errorMsg string
+	}{
+		{queueURL: "https://sqs.eu-west-1.amazonaws.com/123456789012/yace-triggers", region: "eu-west-1"},
+		{queueURL: "https://us-east-1.queue.amazonaws.com/123456789012/yace-triggers", region: "us-east-1"},
+		{queueURL: "https://sqs.cn-north-1.amazonaws.com.cn/123456789012/yace-triggers", region: "cn-north-1"},
+		{queueURL: "http://localhost:4566/000000000000/yace-triggers", errorMsg: "cannot find the region"},
+	} {
+		t.Run(tc.queueURL, func(t *testing.T) {
+			region, err := queueRegion(tc.queueURL)
+			if tc.errorMsg != "" {
+				require.ErrorContains(t, err, tc.errorMsg)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.region, region)
+		})
+	}
+}
+
+func TestParseEvents(t *testing.T) {
+	events := parseEvents(logging.NewNopLogger(), []string{
+		`{"source":"aws.ec2","detail-type":"EC2 Instance State-change Notification","region":"eu-west-1","resources":["arn:aws:ec2:eu-west-1:123456789012:instance/i-0123456789abcdef0"]}`,
+		`{"source":"aws.ecs","detail-type":"ECS Deployment State Change","region":"eu-west-1","resources":["arn:aws:ecs:eu-west-1:123456789012:service/my-cluster/my-service"]}`,
+		`{"source":"aws.sqs","region":"us-east-1","resources":[]}`,
+		`{"source":"aws.ec2","region":"us-east-1","resources":["arn:aws:ec2:us-east-1:123456789012:vpc/vpc-0123456789abcdef0"]}`,
+		`{"source":"custom.app","region":"us-east-1"}`,
+		`not json`,
+	})
+	require.Equal(t, []Event{
+		{Region: "eu-west-1", Namespaces: []string{"AWS/EC2", "AWS/ECS", "ECS/ContainerInsights"}},
+		{Region: "us-east-1", Namespaces: []string{"AWS/SQS"}},
+	}, events)
+}
+
+type sqsAPI struct {
+	sqsiface.SQSAPI
+	cancel   context.CancelFunc
+	messages []*sqs.Message
+	deleted  []string
+}
+
+func (s *sqsAPI) ReceiveMessageWithContext(_ aws.Context, _ *sqs.ReceiveMessageInput, _ ...request.Option) (*sqs.ReceiveMessageOutput, error) {
+	messages := s.messages
+	s.messages = nil
+	if len(messages) == 0 {
+		s.cancel()
+	}
+	return &sqs.ReceiveMessageOutput{Messages: messages}, nil
+}
+
+func (s *sqsAPI) DeleteMessageWithContext(_ aws.Context, input *sqs.DeleteMessageInput, _ ...request.Option) (*sqs.DeleteMessageOutput, error) {
+	s.deleted = append(s.deleted, aws.StringValue(input.ReceiptHandle))
+	return &sqs.DeleteMessageOutput{}, nil
+}
+
+func TestListenerRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	api := &sqsAPI{
+		cancel: cancel,
+		messages: []*sqs.Message{
+			{ReceiptHandle: aws.String("1"), Body: aws.String(`{"source":"aws.lambda","region":"eu-west-1","resources":["arn:aws:lambda:eu-west-1:123456789012:function:my-function"]}`)},
+			{ReceiptHandle: aws.String("2"), Body: aws.String(`not json`)},
+		},
+	}
+	l := &Listener{logger: logging.NewNopLogger(), sqsAPI: api, queueURL: "https://sqs.eu-west-1.amazonaws.com/123456789012/yace-triggers"}
+
+	events := make(chan Event, 1)
+	l.Run(ctx, events)
+
+	require.Equal(t, Event{Region: "eu-west-1", Namespaces: []string{"AWS/Lambda", "LambdaInsights"}}, <-events)
+	require.Equal(t, []string{"1", "2"}, api.deleted, "all the messages should be deleted, including the invalid ones")
+}
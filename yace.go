@@ -0,0 +1,179 @@
+// Package yace is the supported API to embed YACE in an application. It exposes
+// the exporter as a prometheus.Collector, scraping AWS on every collection, and
+// is configured with functional options instead of mirroring the internal wiring.
+package yace
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	exporter "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/tagging"
+	v1 "github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/v1"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/config"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+// Config is the configuration of the jobs, as loaded by LoadConfig.
+type Config = model.JobsConfig
+
+// LoadConfig loads and validates a YAML configuration file.
+func LoadConfig(file string, logger logging.Logger) (Config, error) {
+	cfg := config.ScrapeConf{}
+	return cfg.Load(file, logger)
+}
+
+// Option configures a Collector.
+type Option func(*Collector) error
+
+// WithLogger sets the logger of the collector. Logs are discarded by default.
+func WithLogger(logger logging.Logger) Option {
+	return func(c *Collector) error {
+		c.logger = logger
+		return nil
+	}
+}
+
+// WithClientFactory sets the factory of the AWS clients. The default factory uses
+// the AWS SDK v1 and the default credentials chain. When the factory implements
+// Refresh and Clear, as the v1 and v2 factories, they are called around every scrape.
+func WithClientFactory(factory clients.Factory) Option {
+	return func(c *Collector) error {
+		c.factory = factory
+		return nil
+	}
+}
+
+// WithClientOverrides injects custom implementations of the CloudWatch, tagging and
+// account clients, taking precedence over the ones of the client factory.
+func WithClientOverrides(overrides clients.ClientOverrides) Option {
+	return func(c *Collector) error {
+		c.overrides = &overrides
+		return nil
+	}
+}
+
+// WithResourceCache caches the discovered resources, so that they are refreshed
+// when the cache expires instead of on every scrape.
+func WithResourceCache(cache *tagging.ResourceCache) Option {
+	return func(c *Collector) error {
+		c.resourceCache = cache
+		return nil
+	}
+}
+
+// WithScrapeTimeout sets the timeout of every scrape. There is no timeout by default.
+func WithScrapeTimeout(timeout time.Duration) Option {
+	return func(c *Collector) error {
+		c.scrapeTimeout = timeout
+		return nil
+	}
+}
+
+// WithExporterOptions sets the options of the scrapes, as the metrics per query
+// or the API concurrency, see the OptionsFunc of the exporter package.
+func WithExporterOptions(optFuncs ...exporter.OptionsFunc) Option {
+	return func(c *Collector) error {
+		c.exporterOptions = append(c.exporterOptions, optFuncs...)
+		return nil
+	}
+}
+
+// refresher is implemented by the factories loading credentials before a scrape.
+type refresher interface {
+	Refresh()
+	Clear()
+}
+
+// Collector is a prometheus.Collector scraping AWS on every collection.
+// Concurrent collections are serialized, so that AWS is not scraped twice at once.
+type Collector struct {
+	cfg             Config
+	logger          logging.Logger
+	factory         clients.Factory
+	overrides       *clients.ClientOverrides
+	resourceCache   *tagging.ResourceCache
+	scrapeTimeout   time.Duration
+	exporterOptions []exporter.OptionsFunc
+
+	mu sync.Mutex
+}
+
+func NewCollector(cfg Config, opts ...Option) (*Collector, error) {
+	c := &Collector{
+		cfg:    cfg,
+		logger: logging.NewNopLogger(),
+	}
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+	if c.factory == nil {
+		c.factory = v1.NewFactory(c.logger, cfg, false)
+	}
+	if c.overrides != nil {
+		c.factory = clients.NewOverridingFactory(c.factory, *c.overrides)
+	}
+	if c.resourceCache != nil {
+		c.factory = resourceCachingFactory{Factory: c.factory, resourceCache: c.resourceCache}
+	}
+	return c, nil
+}
+
+// Describe sends no descriptors: the collector is unchecked, as
+// the scraped metrics depend on the resources found in AWS.
+func (c *Collector) Describe(chan<- *prometheus.Desc) {}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ctx := context.Background()
+	if c.scrapeTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.scrapeTimeout)
+		defer cancel()
+	}
+
+	if r, ok := c.factory.(refresher); ok {
+		r.Refresh()
+		defer r.Clear()
+	}
+
+	collector, err := exporter.CollectMetrics(ctx, c.logger, c.cfg, c.factory, c.exporterOptions...)
+	if err != nil {
+		c.logger.Error(err, "Failed to scrape metrics")
+		return
+	}
+	collector.Collect(ch)
+}
+
+// resourceCachingFactory wraps the tagging clients of a factory with a resource cache.
+type resourceCachingFactory struct {
+	clients.Factory
+	resourceCache *tagging.ResourceCache
+}
+
+func (f resourceCachingFactory) GetTaggingClient(region string, role model.Role, concurrencyLimit int) tagging.Client {
+	return tagging.NewCachingClient(f.Factory.GetTaggingClient(region, role, concurrencyLimit), f.resourceCache, role)
+}
+
+// Refresh forwards to the wrapped factory, if it loads credentials before a scrape.
+func (f resourceCachingFactory) Refresh() {
+	if r, ok := f.Factory.(refresher); ok {
+		r.Refresh()
+	}
+}
+
+// Clear forwards to the wrapped factory, if it loads credentials before a scrape.
+func (f resourceCachingFactory) Clear() {
+	if r, ok := f.Factory.(refresher); ok {
+		r.Clear()
+	}
+}
@@ -0,0 +1,75 @@
+package yace_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	yace "github.com/nerdswords/yet-another-cloudwatch-exporter"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/mock"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/clients/tagging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/logging"
+	"github.com/nerdswords/yet-another-cloudwatch-exporter/pkg/model"
+)
+
+const testConfig = `
+apiVersion: v1alpha1
+discovery:
+  jobs:
+    - type: AWS/SQS
+      regions:
+        - eu-west-1
+      metrics:
+        - name: NumberOfMessagesSent
+          statistics:
+            - Sum
+          period: 300
+          length: 600
+`
+
+func TestCollector(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(configFile, []byte(testConfig), 0o600))
+	cfg, err := yace.LoadConfig(configFile, logging.NewNopLogger())
+	require.NoError(t, err)
+
+	backend := mock.NewBackend("123456789012")
+	backend.AddResources("eu-west-1", &model.TaggedResource{
+		ARN:       "arn:aws:sqs:eu-west-1:123456789012:orders",
+		Namespace: "AWS/SQS",
+	})
+	backend.AddMetric("eu-west-1", &model.Metric{
+		Namespace:  "AWS/SQS",
+		MetricName: "NumberOfMessagesSent",
+		Dimensions: []*model.Dimension{{Name: "QueueName", Value: "orders"}},
+	}, mock.Datapoint{Timestamp: time.Now().Add(-6 * time.Minute), Value: 3})
+
+	collector, err := yace.NewCollector(cfg,
+		yace.WithClientFactory(backend),
+		yace.WithResourceCache(tagging.NewResourceCache(time.Hour, 0)),
+		yace.WithScrapeTimeout(time.Minute),
+	)
+	require.NoError(t, err)
+
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(collector))
+
+	// Every gather scrapes again, serving the same metrics
+	for i := 0; i < 2; i++ {
+		families, err := registry.Gather()
+		require.NoError(t, err)
+		var values []float64
+		for _, family := range families {
+			if family.GetName() == "aws_sqs_number_of_messages_sent_sum" {
+				for _, metric := range family.GetMetric() {
+					values = append(values, metric.GetGauge().GetValue())
+				}
+			}
+		}
+		require.Equal(t, []float64{3}, values)
+	}
+}